@@ -803,17 +803,21 @@ type callMsg struct {
 	ethereum.CallMsg
 }
 
-func (m callMsg) From() common.Address         { return m.CallMsg.From }
-func (m callMsg) Nonce() uint64                { return 0 }
-func (m callMsg) IsFake() bool                 { return true }
-func (m callMsg) To() *common.Address          { return m.CallMsg.To }
-func (m callMsg) GasPrice() *big.Int           { return m.CallMsg.GasPrice }
-func (m callMsg) GasFeeCap() *big.Int          { return m.CallMsg.GasFeeCap }
-func (m callMsg) GasTipCap() *big.Int          { return m.CallMsg.GasTipCap }
-func (m callMsg) Gas() uint64                  { return m.CallMsg.Gas }
-func (m callMsg) Value() *big.Int              { return m.CallMsg.Value }
-func (m callMsg) Data() []byte                 { return m.CallMsg.Data }
-func (m callMsg) AccessList() types.AccessList { return m.CallMsg.AccessList }
+func (m callMsg) From() common.Address                   { return m.CallMsg.From }
+func (m callMsg) Nonce() uint64                          { return 0 }
+func (m callMsg) IsFake() bool                           { return true }
+func (m callMsg) IsSystem() bool                         { return false }
+func (m callMsg) FeePayer() *common.Address              { return nil }
+func (m callMsg) NonceKey() uint64                       { return 0 }
+func (m callMsg) AuthList() []types.SetCodeAuthorization { return nil }
+func (m callMsg) To() *common.Address                    { return m.CallMsg.To }
+func (m callMsg) GasPrice() *big.Int                     { return m.CallMsg.GasPrice }
+func (m callMsg) GasFeeCap() *big.Int                    { return m.CallMsg.GasFeeCap }
+func (m callMsg) GasTipCap() *big.Int                    { return m.CallMsg.GasTipCap }
+func (m callMsg) Gas() uint64                            { return m.CallMsg.Gas }
+func (m callMsg) Value() *big.Int                        { return m.CallMsg.Value }
+func (m callMsg) Data() []byte                           { return m.CallMsg.Data }
+func (m callMsg) AccessList() types.AccessList           { return m.CallMsg.AccessList }
 
 // filterBackend implements filters.Backend to support filtering for logs without
 // taking bloom-bits acceleration structures into account.
@@ -860,6 +864,10 @@ func (fb *filterBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*ty
 	return logs, nil
 }
 
+func (fb *filterBackend) GetPoolTransaction(hash common.Hash) *types.Transaction {
+	return nil
+}
+
 func (fb *filterBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return nullSubscription()
 }
@@ -880,6 +888,10 @@ func (fb *filterBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event
 	return nullSubscription()
 }
 
+func (fb *filterBackend) SubscribeFinalizedEvent(ch chan<- core.FinalizedEvent) event.Subscription {
+	return fb.bc.SubscribeFinalizedEvent(ch)
+}
+
 func (fb *filterBackend) BloomStatus() (uint64, uint64) { return 4096, 0 }
 
 func (fb *filterBackend) ServiceFilter(ctx context.Context, ms *bloombits.MatcherSession) {
@@ -37,6 +37,8 @@ import (
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
 	"github.com/scroll-tech/go-ethereum/trie"
 )
 
@@ -57,6 +59,8 @@ type ExecutionResult struct {
 	Rejected    []*rejectedTx         `json:"rejected,omitempty"`
 	Difficulty  *math.HexOrDecimal256 `json:"currentDifficulty" gencodec:"required"`
 	GasUsed     math.HexOrDecimal64   `json:"gasUsed"`
+
+	WithdrawTrieRoot *common.Hash `json:"withdrawTrieRoot,omitempty"` // Root of the L2 to L1 messages, if the message queue is deployed
 }
 
 type ommer struct {
@@ -149,6 +153,19 @@ func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig *params.ChainConfig,
 	}
 
 	for i, tx := range txs {
+		// System transactions must lead the block, and the transaction types of
+		// the rollup forks are rejected before their fork, as in StateProcessor
+		if err := types.VerifySystemTxs(chainConfig, vmContext.BlockNumber, append(includedTxs[:len(includedTxs):len(includedTxs)], tx)); err != nil {
+			log.Warn("rejected tx", "index", i, "hash", tx.Hash(), "error", err)
+			rejectedTxs = append(rejectedTxs, &rejectedTx{i, err.Error()})
+			continue
+		}
+		if tx.Type() == types.NonceKeyTxType && !chainConfig.Scroll.IsNonceKey(vmContext.BlockNumber) {
+			err := fmt.Errorf("%w: nonce key transaction %v", core.ErrTxTypeNotSupported, tx.Hash().Hex())
+			log.Warn("rejected tx", "index", i, "hash", tx.Hash(), "error", err)
+			rejectedTxs = append(rejectedTxs, &rejectedTx{i, err.Error()})
+			continue
+		}
 		msg, err := tx.AsMessage(signer, pre.Env.BaseFee)
 		if err != nil {
 			log.Warn("rejected tx", "index", i, "hash", tx.Hash(), "error", err)
@@ -174,6 +191,15 @@ func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig *params.ChainConfig,
 			rejectedTxs = append(rejectedTxs, &rejectedTx{i, err.Error()})
 			continue
 		}
+		// No L1 state is available to the L1SLOAD precompile, the transactions
+		// reading it can't be executed
+		if err := evm.L1StateErr(); err != nil {
+			statedb.RevertToSnapshot(snapshot)
+			gaspool.AddGas(msgResult.UsedGas)
+			log.Info("rejected tx", "index", i, "hash", tx.Hash(), "from", msg.From(), "error", err)
+			rejectedTxs = append(rejectedTxs, &rejectedTx{i, err.Error()})
+			continue
+		}
 		includedTxs = append(includedTxs, tx)
 		if hashError != nil {
 			return nil, nil, NewError(ErrorMissingBlockhash, hashError)
@@ -199,6 +225,10 @@ func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig *params.ChainConfig,
 			}
 			receipt.TxHash = tx.Hash()
 			receipt.GasUsed = msgResult.UsedGas
+			if chainConfig.Scroll.FeeVaultEnabled() {
+				receipt.L1Fee = msgResult.L1Fee
+			}
+			receipt.FeePayer = msg.FeePayer()
 
 			// If the transaction created a contract, store the creation address in the receipt.
 			if msg.To() == nil {
@@ -259,6 +289,10 @@ func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig *params.ChainConfig,
 		Difficulty:  (*math.HexOrDecimal256)(vmContext.Difficulty),
 		GasUsed:     (math.HexOrDecimal64)(gasUsed),
 	}
+	if statedb.Exist(rcfg.L2MessageQueueAddress) {
+		root := withdrawtrie.ReadWTRSlot(rcfg.L2MessageQueueAddress, statedb)
+		execRs.WithdrawTrieRoot = &root
+	}
 	return statedb, execRs, nil
 }
 
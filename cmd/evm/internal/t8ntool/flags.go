@@ -143,6 +143,12 @@ var (
 		Usage: "ChainID to use",
 		Value: 1,
 	}
+	ChainConfigFlag = cli.StringFlag{
+		Name: "state.chainconfig",
+		Usage: "File name of a chain config to use instead of the --state.fork ruleset. " +
+			"Its scroll section enables the rollup forks, the fee vault and the L1 fee.",
+		Value: "",
+	}
 	ForknameFlag = cli.StringFlag{
 		Name: "state.fork",
 		Usage: fmt.Sprintf("Name of ruleset to use."+
@@ -184,14 +184,27 @@ func Transition(ctx *cli.Context) error {
 	}
 	// Construct the chainconfig
 	var chainConfig *params.ChainConfig
-	if cConf, extraEips, err := tests.GetChainConfig(ctx.String(ForknameFlag.Name)); err != nil {
+	if ctx.IsSet(ChainConfigFlag.Name) {
+		chainConfig = new(params.ChainConfig)
+		if err := readFile(ctx.String(ChainConfigFlag.Name), "chain config", chainConfig); err != nil {
+			return err
+		}
+		if err := chainConfig.CheckConfigForkOrder(); err != nil {
+			return NewError(ErrorConfig, fmt.Errorf("invalid chain configuration: %v", err))
+		}
+		if err := vm.CheckGasSchedules(chainConfig); err != nil {
+			return NewError(ErrorConfig, fmt.Errorf("invalid chain configuration: %v", err))
+		}
+	} else if cConf, extraEips, err := tests.GetChainConfig(ctx.String(ForknameFlag.Name)); err != nil {
 		return NewError(ErrorConfig, fmt.Errorf("failed constructing chain configuration: %v", err))
 	} else {
 		chainConfig = cConf
 		vmConfig.ExtraEips = extraEips
 	}
-	// Set the chain id
-	chainConfig.ChainID = big.NewInt(ctx.Int64(ChainIDFlag.Name))
+	// Set the chain id, unless configured by the chain config
+	if chainConfig.ChainID == nil || ctx.IsSet(ChainIDFlag.Name) || !ctx.IsSet(ChainConfigFlag.Name) {
+		chainConfig.ChainID = big.NewInt(ctx.Int64(ChainIDFlag.Name))
+	}
 
 	var txsWithKeys []*txWithKey
 	if txStr != stdinSelector {
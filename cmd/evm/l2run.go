@@ -0,0 +1,291 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/consensus"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/eth"
+	"github.com/scroll-tech/go-ethereum/eth/catalyst"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/l1origin"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+var (
+	L2RunStateFlag = cli.StringFlag{
+		Name:  "state",
+		Usage: "Chain database directory holding the parent block and its state",
+	}
+	L2RunWitnessFlag = cli.StringFlag{
+		Name:  "witness",
+		Usage: "File name of the witness holding the chain config, the parent header and the pre-state",
+	}
+)
+
+var l2RunCommand = cli.Command{
+	Action:    l2RunCmd,
+	Name:      "l2-run",
+	Usage:     "executes an L2 execution payload offline and reports its roots and receipts",
+	ArgsUsage: "<payload.json>",
+	Flags: []cli.Flag{
+		L2RunStateFlag,
+		L2RunWitnessFlag,
+	},
+	Description: `
+The l2-run command executes an execution payload, as accepted by consensus_newBlock,
+against either a chain database (--state) or a witness (--witness), and prints the
+resulting roots and receipts along with the payload fields they disagree with. It
+exits with an error if there are any.
+
+The witness is a JSON object with the "chainConfig", the "parent" header, optionally
+the "ancestors" headers served to BLOCKHASH, and the "alloc" of the accounts the
+payload touches. As the witness state may be partial, the state root is only
+compared when executing against a chain database.`,
+}
+
+// l2Witness is the data an execution payload is executed against without a
+// chain database.
+type l2Witness struct {
+	ChainConfig *params.ChainConfig `json:"chainConfig"`
+	Parent      *types.Header       `json:"parent"`
+	Ancestors   []*types.Header     `json:"ancestors,omitempty"`
+	Alloc       core.GenesisAlloc   `json:"alloc"`
+}
+
+// L2RunResult is the outcome of executing an execution payload.
+type L2RunResult struct {
+	BlockHash    common.Hash    `json:"blockHash"`
+	StateRoot    common.Hash    `json:"stateRoot"`
+	ReceiptsRoot common.Hash    `json:"receiptsRoot"`
+	LogsBloom    types.Bloom    `json:"logsBloom"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Receipts     types.Receipts `json:"receipts"`
+	Mismatches   []string       `json:"mismatches,omitempty"` // Payload fields disagreeing with the execution
+}
+
+// l2Chain serves the headers of the ancestors of the executed payload. The
+// author of the payload is always given explicitly, so no engine is needed.
+type l2Chain struct {
+	db      ethdb.Reader
+	headers map[common.Hash]*types.Header
+}
+
+func (c *l2Chain) Engine() consensus.Engine { return nil }
+
+func (c *l2Chain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header, ok := c.headers[hash]; ok {
+		return header
+	}
+	if c.db != nil {
+		return rawdb.ReadHeader(c.db, hash, number)
+	}
+	return nil
+}
+
+func l2RunCmd(ctx *cli.Context) error {
+	if len(ctx.Args().First()) == 0 {
+		return errors.New("path-to-payload argument required")
+	}
+	if ctx.IsSet(L2RunStateFlag.Name) == ctx.IsSet(L2RunWitnessFlag.Name) {
+		return fmt.Errorf("exactly one of --%s and --%s required", L2RunStateFlag.Name, L2RunWitnessFlag.Name)
+	}
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger.Verbosity(log.Lvl(ctx.GlobalInt(VerbosityFlag.Name)))
+	log.Root().SetHandler(glogger)
+
+	var payload eth.ExecutableL2Data
+	if err := readJSONFile(ctx.Args().First(), &payload); err != nil {
+		return err
+	}
+	var (
+		config  *params.ChainConfig
+		parent  *types.Header
+		statedb *state.StateDB
+		chain   = &l2Chain{headers: make(map[common.Hash]*types.Header)}
+		err     error
+	)
+	if dir := ctx.String(L2RunStateFlag.Name); dir != "" {
+		db, err := openChainDatabase(dir)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if config = rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0)); config == nil {
+			return errors.New("chain config not found in database")
+		}
+		number := rawdb.ReadHeaderNumber(db, payload.ParentHash)
+		if number == nil {
+			return fmt.Errorf("parent %x not found in database", payload.ParentHash)
+		}
+		parent = rawdb.ReadHeader(db, payload.ParentHash, *number)
+		sdb := state.NewDatabaseWithConfig(db, &trie.Config{Zktrie: config.Scroll.ZktrieEnabled()})
+		if statedb, err = state.New(parent.Root, sdb, nil); err != nil {
+			return fmt.Errorf("parent state unavailable: %v", err)
+		}
+		chain.db = db
+	} else {
+		var witness l2Witness
+		if err := readJSONFile(ctx.String(L2RunWitnessFlag.Name), &witness); err != nil {
+			return err
+		}
+		if witness.ChainConfig == nil || witness.Parent == nil {
+			return errors.New("witness without chain config or parent header")
+		}
+		if witness.Parent.Hash() != payload.ParentHash {
+			return fmt.Errorf("witness parent %x not the payload parent %x", witness.Parent.Hash(), payload.ParentHash)
+		}
+		config, parent = witness.ChainConfig, witness.Parent
+		for _, header := range witness.Ancestors {
+			chain.headers[header.Hash()] = header
+		}
+		sdb := state.NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Zktrie: config.Scroll.ZktrieEnabled()})
+		if statedb, err = state.New(common.Hash{}, sdb, nil); err != nil {
+			return err
+		}
+		for addr, account := range witness.Alloc {
+			statedb.SetCode(addr, account.Code)
+			statedb.SetNonce(addr, account.Nonce)
+			statedb.SetBalance(addr, account.Balance)
+			for key, value := range account.Storage {
+				statedb.SetState(addr, key, value)
+			}
+		}
+	}
+	chain.headers[parent.Hash()] = parent
+
+	vmConfig := vm.Config{}
+	if ctx.GlobalBool(MachineFlag.Name) {
+		vmConfig.Tracer = vm.NewJSONLogger(&vm.LogConfig{
+			EnableMemory:     !ctx.GlobalBool(DisableMemoryFlag.Name),
+			DisableStack:     ctx.GlobalBool(DisableStackFlag.Name),
+			DisableStorage:   ctx.GlobalBool(DisableStorageFlag.Name),
+			EnableReturnData: !ctx.GlobalBool(DisableReturnDataFlag.Name),
+		}, os.Stderr)
+		vmConfig.Debug = true
+	}
+	result, err := executeL2Payload(config, chain, parent, statedb, &payload, vmConfig, ctx.IsSet(L2RunStateFlag.Name))
+	if err != nil {
+		return err
+	}
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+
+	if len(result.Mismatches) > 0 {
+		return fmt.Errorf("payload mismatches execution: %s", strings.Join(result.Mismatches, ", "))
+	}
+	return nil
+}
+
+// executeL2Payload executes an execution payload on top of the given parent
+// state, comparing the outcome with the payload. The state root is only
+// compared if the state is complete.
+func executeL2Payload(config *params.ChainConfig, chain core.ChainContext, parent *types.Header, statedb *state.StateDB, payload *eth.ExecutableL2Data, vmConfig vm.Config, fullState bool) (*L2RunResult, error) {
+	block, err := catalyst.PayloadToBlock(config, parent, payload)
+	if err != nil {
+		return nil, err
+	}
+	header := block.Header()
+	if err := types.VerifySystemTxs(config, header.Number, block.Transactions()); err != nil {
+		return nil, err
+	}
+	// The parent body isn't at hand, so the origin is only checked against the
+	// header
+	if err := l1origin.VerifyOrigin(header, block.Transactions(), nil); err != nil {
+		return nil, err
+	}
+	if err := l1origin.VerifyTime(config, header, block.Transactions()); err != nil {
+		return nil, err
+	}
+	var (
+		gp       = new(core.GasPool).AddGas(header.GasLimit)
+		usedGas  uint64
+		receipts = make(types.Receipts, 0, len(block.Transactions()))
+	)
+	for i, tx := range block.Transactions() {
+		statedb.Prepare(tx.Hash(), i)
+		receipt, err := core.ApplyTransaction(config, chain, &header.Coinbase, gp, statedb, header, tx, &usedGas, vmConfig)
+		if err != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %v", i, tx.Hash().Hex(), err)
+		}
+		receipts = append(receipts, receipt)
+	}
+	result := &L2RunResult{
+		BlockHash:    block.Hash(),
+		StateRoot:    statedb.IntermediateRoot(config.IsEIP158(header.Number)),
+		ReceiptsRoot: types.DeriveSha(receipts, trie.NewStackTrie(nil)),
+		LogsBloom:    types.CreateBloom(receipts),
+		GasUsed:      hexutil.Uint64(usedGas),
+		Receipts:     receipts,
+	}
+	if fullState && result.StateRoot != payload.StateRoot {
+		result.Mismatches = append(result.Mismatches, fmt.Sprintf("stateRoot (payload %x)", payload.StateRoot))
+	}
+	if result.ReceiptsRoot != payload.ReceiptRoot {
+		result.Mismatches = append(result.Mismatches, fmt.Sprintf("receiptsRoot (payload %x)", payload.ReceiptRoot))
+	}
+	if result.LogsBloom != types.BytesToBloom(payload.LogsBloom) {
+		result.Mismatches = append(result.Mismatches, "logsBloom")
+	}
+	if uint64(result.GasUsed) != uint64(payload.GasUsed) {
+		result.Mismatches = append(result.Mismatches, fmt.Sprintf("gasUsed (payload %d)", payload.GasUsed))
+	}
+	if result.BlockHash != payload.BlockHash {
+		result.Mismatches = append(result.Mismatches, fmt.Sprintf("blockHash (payload %x)", payload.BlockHash))
+	}
+	return result, nil
+}
+
+// openChainDatabase opens the chain database in the given directory read-only,
+// along with its ancient store if present.
+func openChainDatabase(dir string) (ethdb.Database, error) {
+	options := rawdb.OpenOptions{Directory: dir, Cache: 16, Handles: 16, Namespace: "evm/", ReadOnly: true}
+	if ancients := filepath.Join(dir, "ancient"); common.FileExist(ancients) {
+		options.AncientsDirectory = ancients
+	}
+	return rawdb.Open(options)
+}
+
+// readJSONFile decodes the JSON content of the given file.
+func readJSONFile(path string, dest interface{}) error {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(blob, dest); err != nil {
+		return fmt.Errorf("invalid %s: %v", path, err)
+	}
+	return nil
+}
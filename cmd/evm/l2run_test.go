@@ -0,0 +1,102 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/eth"
+	"github.com/scroll-tech/go-ethereum/internal/cmdtest"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// makeL2RunInputs writes the payload of a block calling a logging contract,
+// along with the witness it executes against, into the given directory.
+func makeL2RunInputs(t *testing.T, dir string) (string, string) {
+	var (
+		key, _  = crypto.GenerateKey()
+		sender  = crypto.PubkeyToAddress(key.PublicKey)
+		emitter = common.HexToAddress("0xe0")
+		config  = params.TestChainConfig
+		alloc   = core.GenesisAlloc{
+			sender:  {Balance: big.NewInt(params.Ether)},
+			emitter: {Balance: common.Big0, Code: common.FromHex("60006000a0")}, // LOG0 of empty memory
+		}
+		gspec   = &core.Genesis{Config: config, Alloc: alloc, GasLimit: 10_000_000, BaseFee: big.NewInt(params.InitialBaseFee)}
+		db      = rawdb.NewMemoryDatabase()
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := core.GenerateChain(config, genesis, ethash.NewFaker(), db, 1, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.HexToAddress("0xc0"))
+		b.SetDifficulty(big.NewInt(1))
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(sender), emitter, common.Big0, 50_000, b.BaseFee(), nil), types.LatestSigner(config), key)
+		b.AddTx(tx)
+	})
+	payload, err := eth.NewExecutableL2Data(blocks[0], ethash.NewFaker())
+	if err != nil {
+		t.Fatalf("failed to create payload: %v", err)
+	}
+	witness := &l2Witness{ChainConfig: config, Parent: genesis.Header(), Alloc: alloc}
+
+	write := func(name string, v interface{}) string {
+		blob, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, blob, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	tampered := *payload
+	tampered.ReceiptRoot = common.Hash{0x01}
+	write("tampered.json", &tampered)
+	return write("payload.json", payload), write("witness.json", witness)
+}
+
+func TestL2Run(t *testing.T) {
+	dir := t.TempDir()
+	payload, witness := makeL2RunInputs(t, dir)
+
+	for i, tc := range []struct {
+		args     []string
+		exitCode int
+	}{
+		{[]string{"--witness", witness, payload}, 0},
+		{[]string{"--witness", witness, filepath.Join(dir, "tampered.json")}, 1}, // Receipts root mismatch
+		{[]string{payload}, 1}, // Neither state nor witness
+	} {
+		tt := new(testT8n)
+		tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+		tt.Run("evm-test", append([]string{"l2-run"}, tc.args...)...)
+		tt.WaitExit()
+		if have := tt.ExitStatus(); have != tc.exitCode {
+			t.Fatalf("test %d: wrong exit code, have %d, want %d, stderr %s", i, have, tc.exitCode, tt.StderrText())
+		}
+	}
+}
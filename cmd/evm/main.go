@@ -152,6 +152,7 @@ var stateTransitionCommand = cli.Command{
 		t8ntool.InputEnvFlag,
 		t8ntool.InputTxsFlag,
 		t8ntool.ForknameFlag,
+		t8ntool.ChainConfigFlag,
 		t8ntool.ChainIDFlag,
 		t8ntool.RewardFlag,
 		t8ntool.VerbosityFlag,
@@ -223,6 +224,7 @@ func init() {
 		stateTransitionCommand,
 		transactionCommand,
 		blockBuilderCommand,
+		l2RunCommand,
 	}
 	cli.CommandHelpTemplate = flags.OriginCommandHelpTemplate
 }
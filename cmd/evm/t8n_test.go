@@ -40,6 +40,7 @@ type t8nInput struct {
 	inEnv    string
 	stFork   string
 	stReward string
+	stConfig string
 }
 
 func (args *t8nInput) get(base string) []string {
@@ -62,6 +63,10 @@ func (args *t8nInput) get(base string) []string {
 	if opt := args.stReward; opt != "" {
 		out = append(out, "--state.reward", opt)
 	}
+	if opt := args.stConfig; opt != "" {
+		out = append(out, "--state.chainconfig")
+		out = append(out, fmt.Sprintf("%v/%v", base, opt))
+	}
 	return out
 }
 
@@ -103,7 +108,7 @@ func TestT8n(t *testing.T) {
 		{ // Test exit (3) on bad config
 			base: "./testdata/1",
 			input: t8nInput{
-				"alloc.json", "txs.json", "env.json", "Frontier+1346", "",
+				"alloc.json", "txs.json", "env.json", "Frontier+1346", "", "",
 			},
 			output:      t8nOutput{alloc: true, result: true},
 			expExitCode: 3,
@@ -111,7 +116,7 @@ func TestT8n(t *testing.T) {
 		{
 			base: "./testdata/1",
 			input: t8nInput{
-				"alloc.json", "txs.json", "env.json", "Byzantium", "",
+				"alloc.json", "txs.json", "env.json", "Byzantium", "", "",
 			},
 			output: t8nOutput{alloc: true, result: true},
 			expOut: "exp.json",
@@ -119,7 +124,7 @@ func TestT8n(t *testing.T) {
 		{ // blockhash test
 			base: "./testdata/3",
 			input: t8nInput{
-				"alloc.json", "txs.json", "env.json", "Berlin", "",
+				"alloc.json", "txs.json", "env.json", "Berlin", "", "",
 			},
 			output: t8nOutput{alloc: true, result: true},
 			expOut: "exp.json",
@@ -127,7 +132,7 @@ func TestT8n(t *testing.T) {
 		{ // missing blockhash test
 			base: "./testdata/4",
 			input: t8nInput{
-				"alloc.json", "txs.json", "env.json", "Berlin", "",
+				"alloc.json", "txs.json", "env.json", "Berlin", "", "",
 			},
 			output:      t8nOutput{alloc: true, result: true},
 			expExitCode: 4,
@@ -135,7 +140,7 @@ func TestT8n(t *testing.T) {
 		{ // Uncle test
 			base: "./testdata/5",
 			input: t8nInput{
-				"alloc.json", "txs.json", "env.json", "Byzantium", "0x80",
+				"alloc.json", "txs.json", "env.json", "Byzantium", "0x80", "",
 			},
 			output: t8nOutput{alloc: true, result: true},
 			expOut: "exp.json",
@@ -143,7 +148,7 @@ func TestT8n(t *testing.T) {
 		{ // Sign json transactions
 			base: "./testdata/13",
 			input: t8nInput{
-				"alloc.json", "txs.json", "env.json", "London", "",
+				"alloc.json", "txs.json", "env.json", "London", "", "",
 			},
 			output: t8nOutput{body: true},
 			expOut: "exp.json",
@@ -151,7 +156,7 @@ func TestT8n(t *testing.T) {
 		{ // Already signed transactions
 			base: "./testdata/13",
 			input: t8nInput{
-				"alloc.json", "signed_txs.rlp", "env.json", "London", "",
+				"alloc.json", "signed_txs.rlp", "env.json", "London", "", "",
 			},
 			output: t8nOutput{result: true},
 			expOut: "exp2.json",
@@ -159,7 +164,7 @@ func TestT8n(t *testing.T) {
 		{ // Difficulty calculation - no uncles
 			base: "./testdata/14",
 			input: t8nInput{
-				"alloc.json", "txs.json", "env.json", "London", "",
+				"alloc.json", "txs.json", "env.json", "London", "", "",
 			},
 			output: t8nOutput{result: true},
 			expOut: "exp.json",
@@ -167,7 +172,7 @@ func TestT8n(t *testing.T) {
 		{ // Difficulty calculation - with uncles
 			base: "./testdata/14",
 			input: t8nInput{
-				"alloc.json", "txs.json", "env.uncles.json", "London", "",
+				"alloc.json", "txs.json", "env.uncles.json", "London", "", "",
 			},
 			output: t8nOutput{result: true},
 			expOut: "exp2.json",
@@ -175,7 +180,7 @@ func TestT8n(t *testing.T) {
 		{ // Difficulty calculation - with ommers + Berlin
 			base: "./testdata/14",
 			input: t8nInput{
-				"alloc.json", "txs.json", "env.uncles.json", "Berlin", "",
+				"alloc.json", "txs.json", "env.uncles.json", "Berlin", "", "",
 			},
 			output: t8nOutput{result: true},
 			expOut: "exp_berlin.json",
@@ -183,7 +188,7 @@ func TestT8n(t *testing.T) {
 		{ // Difficulty calculation on arrow glacier
 			base: "./testdata/19",
 			input: t8nInput{
-				"alloc.json", "txs.json", "env.json", "London", "",
+				"alloc.json", "txs.json", "env.json", "London", "", "",
 			},
 			output: t8nOutput{result: true},
 			expOut: "exp_london.json",
@@ -191,7 +196,7 @@ func TestT8n(t *testing.T) {
 		{ // Difficulty calculation on arrow glacier
 			base: "./testdata/19",
 			input: t8nInput{
-				"alloc.json", "txs.json", "env.json", "ArrowGlacier", "",
+				"alloc.json", "txs.json", "env.json", "ArrowGlacier", "", "",
 			},
 			output: t8nOutput{result: true},
 			expOut: "exp_arrowglacier.json",
@@ -199,7 +204,7 @@ func TestT8n(t *testing.T) {
 		{ // Sign unprotected (pre-EIP155) transaction
 			base: "./testdata/23",
 			input: t8nInput{
-				"alloc.json", "txs.json", "env.json", "Berlin", "",
+				"alloc.json", "txs.json", "env.json", "Berlin", "", "",
 			},
 			output: t8nOutput{result: true},
 			expOut: "exp.json",
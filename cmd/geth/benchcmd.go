@@ -0,0 +1,121 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+
+	cli "gopkg.in/urfave/cli.v1"
+
+	"github.com/scroll-tech/go-ethereum/cmd/utils"
+	"github.com/scroll-tech/go-ethereum/eth/catalyst"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+var (
+	benchWorkloadFlag = cli.StringFlag{
+		Name:  "workload",
+		Usage: `Synthetic workload to run ("erc20", "swap" or "storage")`,
+		Value: catalyst.DefaultBenchConfig.Workload,
+	}
+	benchBlocksFlag = cli.IntFlag{
+		Name:  "blocks",
+		Usage: "Number of blocks to produce",
+		Value: catalyst.DefaultBenchConfig.Blocks,
+	}
+	benchTxsFlag = cli.IntFlag{
+		Name:  "txs",
+		Usage: "Number of transactions offered to every block",
+		Value: catalyst.DefaultBenchConfig.TxsPerBlock,
+	}
+	benchAccountsFlag = cli.IntFlag{
+		Name:  "accounts",
+		Usage: "Number of sending accounts (0 = one per transaction in a block)",
+	}
+	benchGasLimitFlag = cli.Uint64Flag{
+		Name:  "gaslimit",
+		Usage: "Block gas limit",
+		Value: catalyst.DefaultBenchConfig.GasLimit,
+	}
+	benchSlotsFlag = cli.IntFlag{
+		Name:  "slots",
+		Usage: "Storage slots written per transaction by the storage workload",
+		Value: catalyst.DefaultBenchConfig.SlotsPerTx,
+	}
+	benchDirFlag = cli.StringFlag{
+		Name:  "dir",
+		Usage: "Directory of the throwaway benchmark database (empty = in memory)",
+	}
+
+	benchCommand = cli.Command{
+		Name:        "bench",
+		Usage:       "A set of commands for benchmarking the node",
+		Category:    "MISCELLANEOUS COMMANDS",
+		Description: "",
+		Subcommands: []cli.Command{
+			{
+				Name:     "sequencer",
+				Usage:    "Measure the block production throughput with a synthetic workload",
+				Action:   utils.MigrateFlags(benchSequencer),
+				Category: "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					benchWorkloadFlag,
+					benchBlocksFlag,
+					benchTxsFlag,
+					benchAccountsFlag,
+					benchGasLimitFlag,
+					benchSlotsFlag,
+					benchDirFlag,
+					utils.DBEngineFlag,
+					utils.CacheFlag,
+				},
+				Description: `
+geth bench sequencer [--workload erc20|swap|storage] [--blocks N] [--txs N]
+produces blocks on a fresh single node chain through the same assemble and
+insert calls used by the sequencer, and reports the blocks and gas processed
+per second along with the assembly and commit latencies. The chain is created
+in memory unless --dir points to a (throwaway) database directory, whose
+backend is selected with --db.engine to compare leveldb and pebble.
+`,
+			},
+		},
+	}
+)
+
+func benchSequencer(ctx *cli.Context) error {
+	config := catalyst.DefaultBenchConfig
+	config.Workload = ctx.String(benchWorkloadFlag.Name)
+	config.Blocks = ctx.Int(benchBlocksFlag.Name)
+	config.TxsPerBlock = ctx.Int(benchTxsFlag.Name)
+	config.Accounts = ctx.Int(benchAccountsFlag.Name)
+	config.GasLimit = ctx.Uint64(benchGasLimitFlag.Name)
+	config.SlotsPerTx = ctx.Int(benchSlotsFlag.Name)
+	config.DataDir = ctx.String(benchDirFlag.Name)
+	config.DBEngine = ctx.GlobalString(utils.DBEngineFlag.Name)
+	if ctx.GlobalIsSet(utils.CacheFlag.Name) {
+		config.DatabaseCache = ctx.GlobalInt(utils.CacheFlag.Name)
+	}
+	log.Info("Running sequencer benchmark", "workload", config.Workload, "blocks", config.Blocks, "txs", config.TxsPerBlock)
+
+	result, err := catalyst.RunBenchmark(config)
+	if err != nil {
+		log.Error("Sequencer benchmark failed", "err", err)
+		return err
+	}
+	fmt.Println(result)
+	return nil
+}
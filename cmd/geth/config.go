@@ -33,8 +33,15 @@ import (
 	"github.com/scroll-tech/go-ethereum/accounts/scwallet"
 	"github.com/scroll-tech/go-ethereum/accounts/usbwallet"
 	"github.com/scroll-tech/go-ethereum/cmd/utils"
+	"github.com/scroll-tech/go-ethereum/eth/blockstream"
 	"github.com/scroll-tech/go-ethereum/eth/catalyst"
 	"github.com/scroll-tech/go-ethereum/eth/ethconfig"
+	"github.com/scroll-tech/go-ethereum/eth/eventsink"
+	"github.com/scroll-tech/go-ethereum/eth/firehose"
+	"github.com/scroll-tech/go-ethereum/eth/shadow"
+	"github.com/scroll-tech/go-ethereum/eth/txmirror"
+	"github.com/scroll-tech/go-ethereum/eth/txstream"
+	"github.com/scroll-tech/go-ethereum/eth/watchdog"
 	"github.com/scroll-tech/go-ethereum/internal/debug"
 	"github.com/scroll-tech/go-ethereum/internal/ethapi"
 	"github.com/scroll-tech/go-ethereum/log"
@@ -87,10 +94,17 @@ type ethstatsConfig struct {
 }
 
 type gethConfig struct {
-	Eth      ethconfig.Config
-	Node     node.Config
-	Ethstats ethstatsConfig
-	Metrics  metrics.Config
+	Eth         ethconfig.Config
+	Node        node.Config
+	Ethstats    ethstatsConfig
+	Shadow      shadow.Config
+	EventSink   eventsink.Config
+	BlockStream blockstream.Config
+	Firehose    firehose.Config
+	Watchdog    watchdog.Config
+	TxMirror    txmirror.Config
+	TxStream    txstream.Config
+	Metrics     metrics.Config
 }
 
 func loadConfig(file string, cfg *gethConfig) error {
@@ -149,6 +163,48 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 	if ctx.GlobalIsSet(utils.EthStatsURLFlag.Name) {
 		cfg.Ethstats.URL = ctx.GlobalString(utils.EthStatsURLFlag.Name)
 	}
+	if ctx.GlobalIsSet(utils.ShadowURLFlag.Name) {
+		cfg.Shadow.URL = ctx.GlobalString(utils.ShadowURLFlag.Name)
+	}
+	if ctx.GlobalIsSet(utils.ShadowHaltFlag.Name) {
+		cfg.Shadow.Halt = ctx.GlobalBool(utils.ShadowHaltFlag.Name)
+	}
+	if ctx.GlobalIsSet(utils.EventSinkURLFlag.Name) {
+		cfg.EventSink.URL = ctx.GlobalString(utils.EventSinkURLFlag.Name)
+	}
+	if ctx.GlobalIsSet(utils.EventSinkEventsFlag.Name) {
+		cfg.EventSink.Events = utils.SplitAndTrim(ctx.GlobalString(utils.EventSinkEventsFlag.Name))
+	}
+	if ctx.GlobalIsSet(utils.BlockStreamURLFlag.Name) {
+		cfg.BlockStream.URL = ctx.GlobalString(utils.BlockStreamURLFlag.Name)
+	}
+	if ctx.GlobalIsSet(utils.BlockStreamTopicFlag.Name) {
+		cfg.BlockStream.Topic = ctx.GlobalString(utils.BlockStreamTopicFlag.Name)
+	}
+	if ctx.GlobalIsSet(utils.FirehoseEnabledFlag.Name) {
+		cfg.Firehose.Enabled = ctx.GlobalBool(utils.FirehoseEnabledFlag.Name)
+	}
+	if ctx.GlobalIsSet(utils.FirehoseOutputFlag.Name) {
+		cfg.Firehose.Output = ctx.GlobalString(utils.FirehoseOutputFlag.Name)
+	}
+	if ctx.GlobalIsSet(utils.WatchdogStallFlag.Name) {
+		cfg.Watchdog.StallTimeout = ctx.GlobalDuration(utils.WatchdogStallFlag.Name)
+	}
+	if ctx.GlobalIsSet(utils.WatchdogLatencyFlag.Name) {
+		cfg.Watchdog.CommitLatency = ctx.GlobalDuration(utils.WatchdogLatencyFlag.Name)
+	}
+	if ctx.GlobalIsSet(utils.WatchdogWebhookFlag.Name) {
+		cfg.Watchdog.Webhook = ctx.GlobalString(utils.WatchdogWebhookFlag.Name)
+	}
+	if ctx.GlobalIsSet(utils.TxPoolMirrorURLFlag.Name) {
+		cfg.TxMirror.URL = ctx.GlobalString(utils.TxPoolMirrorURLFlag.Name)
+	}
+	if ctx.GlobalIsSet(utils.TxPoolMirrorSecretFlag.Name) {
+		cfg.TxMirror.Secret = ctx.GlobalString(utils.TxPoolMirrorSecretFlag.Name)
+	}
+	if ctx.GlobalIsSet(utils.TxStreamSecretFlag.Name) {
+		cfg.TxStream.Secret = ctx.GlobalString(utils.TxStreamSecretFlag.Name)
+	}
 	applyTraceConfig(ctx, &cfg.Eth)
 	applyMetricConfig(ctx, &cfg)
 
@@ -173,14 +229,65 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 		}
 	}
 
+	// Configure the transaction pool mirror if requested.
+	if cfg.TxMirror.URL != "" || cfg.TxMirror.Secret != "" {
+		if eth == nil {
+			utils.Fatalf("Transaction pool mirroring does not work in light client mode.")
+		}
+		utils.RegisterTxMirrorService(stack, eth, cfg.TxMirror)
+	}
+
+	// Stream the transactions of the blocks being assembled if requested.
+	if cfg.TxStream.Secret != "" {
+		if eth == nil {
+			utils.Fatalf("Transaction streaming does not work in light client mode.")
+		}
+		utils.RegisterTxStreamService(stack, eth, cfg.TxStream)
+	}
+
+	// Stream the full blocks to a data pipeline if requested.
+	if cfg.BlockStream.URL != "" {
+		if eth == nil {
+			utils.Fatalf("Block streaming does not work in light client mode.")
+		}
+		utils.RegisterBlockStreamService(stack, eth, cfg.BlockStream)
+	}
+
+	// Write the blocks in the Firehose format if requested.
+	if cfg.Firehose.Enabled {
+		if eth == nil {
+			utils.Fatalf("Firehose extraction does not work in light client mode.")
+		}
+		utils.RegisterFirehoseService(stack, eth, cfg.Firehose)
+	}
+
 	// Configure GraphQL if requested
 	if ctx.GlobalIsSet(utils.GraphQLEnabledFlag.Name) {
 		utils.RegisterGraphQLService(stack, backend, cfg.Node)
 	}
+	// Serve proofs to light clients if requested
+	if ctx.GlobalBool(utils.LightProofEnabledFlag.Name) {
+		if eth == nil {
+			utils.Fatalf("Light client proofs can only be served by full nodes.")
+		}
+		utils.RegisterLightProofService(stack, eth, cfg.Node)
+	}
 	// Add the Ethereum Stats daemon if requested.
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, backend, cfg.Ethstats.URL)
 	}
+	// Add the shadow verifier if a reference node is configured.
+	if cfg.Shadow.URL != "" {
+		utils.RegisterShadowService(stack, backend, cfg.Shadow)
+	}
+	// Add the chain event sink if an endpoint is configured.
+	if cfg.EventSink.URL != "" {
+		utils.RegisterEventSinkService(stack, backend, cfg.EventSink)
+	}
+	// Add the block production watchdog if any alert is configured.
+	if cfg.Watchdog.Enabled() {
+		utils.RegisterWatchdogService(stack, backend, cfg.Watchdog)
+	}
 	return stack, backend
 }
 
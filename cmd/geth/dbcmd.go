@@ -36,6 +36,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/common/hexutil"
 	"github.com/scroll-tech/go-ethereum/console/prompt"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/crypto/codehash"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/trie"
@@ -70,6 +71,10 @@ Remove blockchain and state databases`,
 			dbDumpFreezerIndex,
 			dbImportCmd,
 			dbExportCmd,
+			dbMigrateCmd,
+			dbIndexCodeHashesCmd,
+			dbExportPreimagesCmd,
+			dbVerifyL2Cmd,
 		},
 	}
 	dbInspectCmd = cli.Command{
@@ -244,6 +249,106 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		},
 		Description: "Exports the specified chain data to an RLP encoded stream, optionally gzip-compressed.",
 	}
+	dbMigrateTargetFlag = cli.StringFlag{
+		Name:  "to",
+		Usage: "Database backend to convert the chain database to ('leveldb' or 'pebble')",
+		Value: "pebble",
+	}
+	dbMigrateCmd = cli.Command{
+		Action: utils.MigrateFlags(migrateChaindata),
+		Name:   "migrate",
+		Usage:  "Convert the chain database to another backend",
+		Flags: []cli.Flag{
+			dbMigrateTargetFlag,
+			utils.DataDirFlag,
+			utils.SyncModeFlag,
+			utils.MainnetFlag,
+			utils.RopstenFlag,
+			utils.SepoliaFlag,
+			utils.RinkebyFlag,
+			utils.GoerliFlag,
+			utils.ScrollAlphaFlag,
+			utils.CacheFlag,
+			utils.CacheDatabaseFlag,
+		},
+		Description: `This command copies every key-value pair of the chain database into a new
+database of the backend given by --to. The ancient store is moved over as is.
+Once done, the new database takes the place of the old one, which is kept as
+<chaindata>.<old backend> until removed manually. The node must not be running.`,
+	}
+	dbPreimageRangeFlag = cli.StringFlag{
+		Name:  "range",
+		Usage: "Range of blocks <first>-<last> to export the preimages of accessed trie keys for",
+	}
+	dbExportPreimagesCmd = cli.Command{
+		Action:    utils.MigrateFlags(dbExportPreimages),
+		Name:      "export-preimages",
+		Usage:     "Export trie key preimages into an RLP stream",
+		ArgsUsage: "<dumpfile>",
+		Flags: []cli.Flag{
+			dbPreimageRangeFlag,
+			utils.DataDirFlag,
+			utils.SyncModeFlag,
+			utils.MainnetFlag,
+			utils.RopstenFlag,
+			utils.SepoliaFlag,
+			utils.RinkebyFlag,
+			utils.GoerliFlag,
+			utils.ScrollAlphaFlag,
+		},
+		Description: `This command exports trie key preimages to an RLP encoded stream, optionally
+gzip-compressed, in the format read by "geth import-preimages". Without --range
+all preimages in the preimage store (see --cache.preimages) are exported. With
+--range only the preimages of the accounts and storage slots accessed by the
+given canonical blocks are, taken from the block access lists recorded at import
+time (see --recordaccesslists).`,
+	}
+	dbIndexCodeHashesCmd = cli.Command{
+		Action: utils.MigrateFlags(indexCodeHashes),
+		Name:   "index-code-hashes",
+		Usage:  "Index the contract code stored in the database by Poseidon code hash",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.SyncModeFlag,
+			utils.MainnetFlag,
+			utils.RopstenFlag,
+			utils.SepoliaFlag,
+			utils.RinkebyFlag,
+			utils.GoerliFlag,
+			utils.ScrollAlphaFlag,
+		},
+		Description: `This command computes the Poseidon hash of every contract code stored in the
+database and records it next to the keccak hash the code is stored under, so
+that the code can be served by either hash. Databases written by older versions
+only index code by keccak hash. The node must not be running.`,
+	}
+	dbVerifyL2RepairFlag = cli.BoolFlag{
+		Name:  "repair",
+		Usage: "Rewrite inconsistent derived indices: header numbers, transaction lookups and the withdraw trie",
+	}
+	dbVerifyL2Cmd = cli.Command{
+		Action: utils.MigrateFlags(dbVerifyL2),
+		Name:   "verify-l2",
+		Usage:  "Check the integrity of the chain data, including the L2 specific tables",
+		Flags: []cli.Flag{
+			dbVerifyL2RepairFlag,
+			utils.DataDirFlag,
+			utils.SyncModeFlag,
+			utils.MainnetFlag,
+			utils.RopstenFlag,
+			utils.SepoliaFlag,
+			utils.RinkebyFlag,
+			utils.GoerliFlag,
+			utils.ScrollAlphaFlag,
+		},
+		Description: `This command walks the canonical chain from the genesis up to the head block,
+checking the hash linkage of the headers, the bodies, receipts and receipt
+blooms against their headers, the transaction lookup entries, the withdraw trie
+recomputed from the L2 to L1 messages of the receipts, and the batches finalized
+on L1 against the state and withdraw trie roots of the blocks they finalize.
+With --repair the derived indices found inconsistent are rewritten; the other
+inconsistencies can only be fixed by resyncing. The node must not be running.`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -696,3 +801,216 @@ func exportChaindata(ctx *cli.Context) error {
 	db := utils.MakeChainDatabase(ctx, stack, true)
 	return utils.ExportChaindata(ctx.Args().Get(1), kind, exporter(db), stop)
 }
+
+// migrateChaindata converts the chain database to the backend given by --to.
+func migrateChaindata(ctx *cli.Context) error {
+	target := ctx.String(dbMigrateTargetFlag.Name)
+	if target != "leveldb" && target != "pebble" {
+		return fmt.Errorf("invalid target backend %q, allowed 'leveldb' or 'pebble'", target)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	name := "chaindata"
+	if ctx.GlobalString(utils.SyncModeFlag.Name) == "light" {
+		name = "lightchaindata"
+	}
+	var (
+		path   = stack.ResolvePath(name)
+		tmp    = path + ".migrating"
+		source = rawdb.PreexistingDatabase(path)
+
+		cache   = ctx.GlobalInt(utils.CacheFlag.Name) * ctx.GlobalInt(utils.CacheDatabaseFlag.Name) / 100
+		handles = utils.MakeDatabaseHandles()
+	)
+	switch source {
+	case "":
+		return fmt.Errorf("no database found at %s", path)
+	case target:
+		return fmt.Errorf("database at %s already uses %s", path, target)
+	}
+	if common.FileExist(tmp) {
+		return fmt.Errorf("leftover of an aborted migration found at %s, remove it first", tmp)
+	}
+	// Copy all key-value pairs, leaving the ancient store alone
+	from, err := rawdb.Open(rawdb.OpenOptions{Type: source, Directory: path, Cache: cache / 2, Handles: handles / 2, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	to, err := rawdb.Open(rawdb.OpenOptions{Type: target, Directory: tmp, Cache: cache / 2, Handles: handles / 2})
+	if err != nil {
+		from.Close()
+		return err
+	}
+	log.Info("Migrating chain database", "from", source, "to", target, "path", path)
+	count, err := copyDatabase(from, to)
+	from.Close()
+	if err != nil {
+		to.Close()
+		return err
+	}
+	log.Info("Compacting migrated database", "entries", count)
+	err = to.Compact(nil, nil)
+	if cerr := to.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	// Move the ancient store into the new database and swap the directories
+	if ancient := filepath.Join(path, "ancient"); common.FileExist(ancient) {
+		if err := os.Rename(ancient, filepath.Join(tmp, "ancient")); err != nil {
+			return err
+		}
+	}
+	backup := path + "." + source
+	if err := os.Rename(path, backup); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	log.Info("Migrated chain database", "to", target, "entries", count, "backup", backup)
+	log.Info("Remove the backup once the node runs fine on the new database", "path", backup)
+	return nil
+}
+
+// copyDatabase copies every key-value pair of a database into another one,
+// returning the number of pairs copied.
+func dbExportPreimages(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	var first, last uint64
+	if ctx.IsSet(dbPreimageRangeFlag.Name) {
+		parts := strings.Split(ctx.String(dbPreimageRangeFlag.Name), "-")
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid block range %q, want <first>-<last>", ctx.String(dbPreimageRangeFlag.Name))
+		}
+		var err error
+		if first, err = strconv.ParseUint(parts[0], 10, 64); err != nil {
+			return fmt.Errorf("invalid first block: %v", err)
+		}
+		if last, err = strconv.ParseUint(parts[1], 10, 64); err != nil {
+			return fmt.Errorf("invalid last block: %v", err)
+		}
+		if first > last {
+			return fmt.Errorf("invalid block range %d-%d", first, last)
+		}
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	if !ctx.IsSet(dbPreimageRangeFlag.Name) {
+		return utils.ExportPreimages(db, ctx.Args().First())
+	}
+	return utils.ExportPreimagesRange(db, ctx.Args().First(), first, last)
+}
+
+func indexCodeHashes(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	var (
+		it     = db.NewIterator(rawdb.CodePrefix, nil)
+		batch  = db.NewBatch()
+		count  int
+		start  = time.Now()
+		logged = time.Now()
+	)
+	defer it.Release()
+
+	for it.Next() {
+		ok, hash := rawdb.IsCodeKey(it.Key())
+		if !ok {
+			continue
+		}
+		rawdb.WritePoseidonCodeHash(batch, codehash.PoseidonCodeHash(it.Value()), common.BytesToHash(hash))
+		count++
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing contract code", "codes", count, "hash", common.BytesToHash(hash), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Indexed contract code", "codes", count, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func dbVerifyL2(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	repair := ctx.Bool(dbVerifyL2RepairFlag.Name)
+	db := utils.MakeChainDatabase(ctx, stack, !repair)
+	defer db.Close()
+
+	issues, err := utils.VerifyL2Database(db, repair)
+	if err != nil {
+		return err
+	}
+	var unrepaired int
+	for _, issue := range issues {
+		fmt.Println(issue)
+		if !issue.Repaired {
+			unrepaired++
+		}
+	}
+	if unrepaired > 0 {
+		return fmt.Errorf("found %d inconsistencies, %d repaired", len(issues), len(issues)-unrepaired)
+	}
+	fmt.Printf("Chain data consistent, %d inconsistencies repaired\n", len(issues))
+	return nil
+}
+
+func copyDatabase(from ethdb.KeyValueStore, to ethdb.KeyValueStore) (int, error) {
+	var (
+		it     = from.NewIterator(nil, nil)
+		batch  = to.NewBatch()
+		count  int
+		start  = time.Now()
+		logged = time.Now()
+	)
+	defer it.Release()
+
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			return count, err
+		}
+		count++
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return count, err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Migrating chain database", "entries", count, "key", hexutil.Encode(it.Key()), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return count, err
+	}
+	if err := batch.Write(); err != nil {
+		return count, err
+	}
+	return count, nil
+}
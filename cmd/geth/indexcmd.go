@@ -0,0 +1,102 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"strconv"
+
+	cli "gopkg.in/urfave/cli.v1"
+
+	"github.com/scroll-tech/go-ethereum/cmd/utils"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+var (
+	indexCommand = cli.Command{
+		Name:        "index",
+		Usage:       "A set of commands for maintaining the chain indexes",
+		Category:    "MISCELLANEOUS COMMANDS",
+		Description: "",
+		Subcommands: []cli.Command{
+			{
+				Name:      "rebuild-logs",
+				Usage:     "Rebuild the per-address/topic log index from the stored receipts",
+				ArgsUsage: "[<from>]",
+				Action:    utils.MigrateFlags(rebuildLogIndex),
+				Category:  "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.RopstenFlag,
+					utils.SepoliaFlag,
+					utils.RinkebyFlag,
+					utils.GoerliFlag,
+					utils.ScrollAlphaFlag,
+				},
+				Description: `
+geth index rebuild-logs [<from>]
+drops the existing log index and rebuilds it for all canonical blocks from
+the given block number (genesis by default) up to the current head. The
+node must be started with --logindex to keep the index up to date afterwards.
+`,
+			},
+		},
+	}
+)
+
+func rebuildLogIndex(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	if ctx.NArg() > 1 {
+		log.Error("Too many arguments given")
+		return errors.New("too many arguments")
+	}
+	var from uint64
+	if ctx.NArg() == 1 {
+		number, err := strconv.ParseUint(ctx.Args()[0], 10, 64)
+		if err != nil {
+			log.Error("Failed to parse start block", "err", err)
+			return err
+		}
+		from = number
+	}
+	chaindb := utils.MakeChainDatabase(ctx, stack, false)
+	defer chaindb.Close()
+
+	head := rawdb.ReadHeadBlockHash(chaindb)
+	number := rawdb.ReadHeaderNumber(chaindb, head)
+	if number == nil {
+		log.Error("Failed to load head block")
+		return errors.New("head block missing")
+	}
+	if from > *number {
+		log.Error("Start block above chain head", "from", from, "head", *number)
+		return errors.New("start block above chain head")
+	}
+	rawdb.DeleteLogIndex(chaindb)
+	rawdb.WriteLogIndexTail(chaindb, from)
+	rawdb.WriteLogIndexNext(chaindb, from)
+
+	if err := rawdb.IndexLogs(chaindb, from, *number, nil); err != nil {
+		log.Error("Failed to rebuild log index", "err", err)
+		return err
+	}
+	return nil
+}
@@ -65,6 +65,9 @@ var (
 		utils.BootnodesFlag,
 		utils.DataDirFlag,
 		utils.AncientFlag,
+		utils.DBEngineFlag,
+		utils.AncientRemoteFlag,
+		utils.AncientRemoteCacheFlag,
 		utils.MinFreeDiskSpaceFlag,
 		utils.KeyStoreDirFlag,
 		utils.ExternalSignerFlag,
@@ -84,6 +87,7 @@ var (
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolRejournalFlag,
+		utils.TxPoolPersistFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
 		utils.TxPoolAccountSlotsFlag,
@@ -91,11 +95,29 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolTxLifetimeFlag,
+		utils.TxPoolGapLifetimeFlag,
+		utils.TxPoolMirrorURLFlag,
+		utils.TxPoolMirrorSecretFlag,
+		utils.TxStreamSecretFlag,
 		utils.SyncModeFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
+		utils.RecordAccessListsFlag,
+		utils.LogIndexFlag,
+		utils.RecordCallTracesFlag,
+		utils.RecordBlockStatsFlag,
+		utils.RecordContractUsageFlag,
+		utils.RecordStateSizeFlag,
+		utils.CreationIndexFlag,
+		utils.AccessEpochFlag,
+		utils.WithdrawTrieFlag,
+		utils.RollupReplicaFlag,
+		utils.L1EndpointFlag,
+		utils.InclusionListWindowFlag,
+		utils.RollupAllowFinalizedRewindFlag,
 		utils.LightServeFlag,
 		utils.LightIngressFlag,
 		utils.LightEgressFlag,
@@ -117,6 +139,9 @@ var (
 		utils.CacheSnapshotFlag,
 		utils.CacheNoPrefetchFlag,
 		utils.CachePreimagesFlag,
+		utils.CachePreimagesKindFlag,
+		utils.CachePreimagesLimitFlag,
+		utils.MemoryBudgetFlag,
 		utils.ListenPortFlag,
 		utils.MaxPeersFlag,
 		utils.MaxPendingPeersFlag,
@@ -125,11 +150,16 @@ var (
 		utils.MinerNotifyFlag,
 		utils.LegacyMinerGasTargetFlag,
 		utils.MinerGasLimitFlag,
+		utils.MinerGasLimitDeltaFlag,
 		utils.MinerGasPriceFlag,
 		utils.MinerEtherbaseFlag,
 		utils.MinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
+		utils.MinerPayloadRecommitFlag,
+		utils.MinerPendingRecommitFlag,
 		utils.MinerNoVerifyFlag,
+		utils.MinerMaxClockSkewFlag,
+		utils.MinerNTPServerFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
@@ -149,6 +179,17 @@ var (
 		utils.VMEnableDebugFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
+		utils.ShadowURLFlag,
+		utils.ShadowHaltFlag,
+		utils.EventSinkURLFlag,
+		utils.EventSinkEventsFlag,
+		utils.BlockStreamURLFlag,
+		utils.BlockStreamTopicFlag,
+		utils.FirehoseEnabledFlag,
+		utils.FirehoseOutputFlag,
+		utils.WatchdogStallFlag,
+		utils.WatchdogLatencyFlag,
+		utils.WatchdogWebhookFlag,
 		utils.FakePoWFlag,
 		utils.NoCompactionFlag,
 		utils.GpoBlocksFlag,
@@ -169,6 +210,7 @@ var (
 		utils.GraphQLEnabledFlag,
 		utils.GraphQLCORSDomainFlag,
 		utils.GraphQLVirtualHostsFlag,
+		utils.LightProofEnabledFlag,
 		utils.HTTPApiFlag,
 		utils.HTTPPathPrefixFlag,
 		utils.WSEnabledFlag,
@@ -177,11 +219,23 @@ var (
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
 		utils.WSPathPrefixFlag,
+		utils.WSNotifyBufferFlag,
+		utils.WSNotifyPolicyFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 		utils.InsecureUnlockAllowedFlag,
 		utils.RPCGlobalGasCapFlag,
 		utils.RPCGlobalEVMTimeoutFlag,
+		utils.RPCLogQueryRangeFlag,
+		utils.RPCLogQueryLimitFlag,
+		utils.RPCMulticallBudgetFlag,
+		utils.RPCResponseCacheFlag,
+		utils.RPCHistoricalStateFlag,
+		utils.RPCWorkersFlag,
+		utils.RPCPriorityApiFlag,
+		utils.RPCBatchItemsFlag,
+		utils.RPCBatchCostFlag,
+		utils.RPCConnCallsFlag,
 		utils.RPCGlobalTxFeeCapFlag,
 		utils.AllowUnprotectedTxs,
 	}
@@ -214,6 +268,9 @@ func init() {
 		initCommand,
 		importCommand,
 		exportCommand,
+		exportVectorsCommand,
+		exportHistoryCommand,
+		verifyHistoryCommand,
 		importPreimagesCommand,
 		exportPreimagesCommand,
 		removedbCommand,
@@ -240,6 +297,9 @@ func init() {
 		utils.ShowDeprecated,
 		// See snapshot.go
 		snapshotCommand,
+		// See indexcmd.go
+		indexCommand,
+		benchCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
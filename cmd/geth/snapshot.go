@@ -47,6 +47,15 @@ var (
 )
 
 var (
+	pruneHistoryRetainFlag = cli.Uint64Flag{
+		Name:  "retain",
+		Usage: "Number of most recent block states to retain",
+		Value: 128,
+	}
+	pruneHistoryDiffsFlag = cli.BoolFlag{
+		Name:  "diffs",
+		Usage: "Also delete the access lists, call traces, block stats and contract usage of blocks whose state is pruned",
+	}
 	snapshotCommand = cli.Command{
 		Name:        "snapshot",
 		Usage:       "A set of commands based on the snapshot",
@@ -79,6 +88,43 @@ two version states are available: genesis and the specific one.
 
 The default pruning target is the HEAD-127 state.
 
+WARNING: It's necessary to delete the trie clean cache after the pruning.
+If you specify another directory for the trie clean cache via "--cache.trie.journal"
+during the use of Geth, please also specify it here for correct deletion. Otherwise
+the trie clean cache with default directory will be deleted.
+`,
+			},
+			{
+				Name:      "prune-history",
+				Usage:     "Prune the state of all but the most recent blocks from an archive database",
+				ArgsUsage: "",
+				Action:    utils.MigrateFlags(pruneHistory),
+				Category:  "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.RopstenFlag,
+					utils.SepoliaFlag,
+					utils.RinkebyFlag,
+					utils.GoerliFlag,
+					utils.ScrollAlphaFlag,
+					utils.CacheTrieJournalFlag,
+					utils.BloomFilterSizeFlag,
+					pruneHistoryRetainFlag,
+					pruneHistoryDiffsFlag,
+				},
+				Description: `
+geth snapshot prune-history --retain <N>
+will convert an archive database into a pruned one in place. All trie nodes
+and contract codes that do not belong to the states of the last N blocks or
+the genesis will be deleted from the database. With --diffs, the access lists,
+call traces, block stats and contract usage recorded for the blocks whose state
+is deleted are removed too.
+
+Unlike prune-state, the snapshot is not needed. The progress is persisted as the
+pruning goes, so an interrupted run is resumed by running the command again,
+keeping the range of retained states of the first run.
+
 WARNING: It's necessary to delete the trie clean cache after the pruning.
 If you specify another directory for the trie clean cache via "--cache.trie.journal"
 during the use of Geth, please also specify it here for correct deletion. Otherwise
@@ -216,6 +262,27 @@ func pruneState(ctx *cli.Context) error {
 	return nil
 }
 
+func pruneHistory(ctx *cli.Context) error {
+	stack, config := makeConfigNode(ctx)
+	defer stack.Close()
+
+	if ctx.NArg() > 0 {
+		log.Error("Too many arguments given")
+		return errors.New("too many arguments")
+	}
+	chaindb := utils.MakeChainDatabase(ctx, stack, false)
+	pruner, err := pruner.NewHistoryPruner(chaindb, stack.ResolvePath(""), stack.ResolvePath(config.Eth.TrieCleanCacheJournal), ctx.GlobalUint64(utils.BloomFilterSizeFlag.Name))
+	if err != nil {
+		log.Error("Failed to create history pruner", "err", err)
+		return err
+	}
+	if err = pruner.Prune(ctx.Uint64(pruneHistoryRetainFlag.Name), ctx.Bool(pruneHistoryDiffsFlag.Name)); err != nil {
+		log.Error("Failed to prune state history", "err", err)
+		return err
+	}
+	return nil
+}
+
 func verifyState(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
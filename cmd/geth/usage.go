@@ -37,6 +37,9 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			configFileFlag,
 			utils.DataDirFlag,
 			utils.AncientFlag,
+			utils.DBEngineFlag,
+			utils.AncientRemoteFlag,
+			utils.AncientRemoteCacheFlag,
 			utils.MinFreeDiskSpaceFlag,
 			utils.KeyStoreDirFlag,
 			utils.USBFlag,
@@ -52,7 +55,31 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.ExitWhenSyncedFlag,
 			utils.GCModeFlag,
 			utils.TxLookupLimitFlag,
+			utils.RecordAccessListsFlag,
+			utils.LogIndexFlag,
+			utils.RecordCallTracesFlag,
+			utils.RecordBlockStatsFlag,
+			utils.RecordContractUsageFlag,
+			utils.RecordStateSizeFlag,
+			utils.CreationIndexFlag,
+			utils.AccessEpochFlag,
+			utils.WithdrawTrieFlag,
+			utils.RollupReplicaFlag,
+			utils.L1EndpointFlag,
+			utils.InclusionListWindowFlag,
+			utils.RollupAllowFinalizedRewindFlag,
 			utils.EthStatsURLFlag,
+			utils.ShadowURLFlag,
+			utils.ShadowHaltFlag,
+			utils.EventSinkURLFlag,
+			utils.EventSinkEventsFlag,
+			utils.BlockStreamURLFlag,
+			utils.BlockStreamTopicFlag,
+			utils.FirehoseEnabledFlag,
+			utils.FirehoseOutputFlag,
+			utils.WatchdogStallFlag,
+			utils.WatchdogLatencyFlag,
+			utils.WatchdogWebhookFlag,
 			utils.IdentityFlag,
 			utils.LightKDFFlag,
 			utils.WhitelistFlag,
@@ -100,6 +127,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.TxPoolNoLocalsFlag,
 			utils.TxPoolJournalFlag,
 			utils.TxPoolRejournalFlag,
+			utils.TxPoolPersistFlag,
 			utils.TxPoolPriceLimitFlag,
 			utils.TxPoolPriceBumpFlag,
 			utils.TxPoolAccountSlotsFlag,
@@ -107,6 +135,11 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolTxLifetimeFlag,
+			utils.TxPoolGapLifetimeFlag,
+			utils.TxPoolMirrorURLFlag,
+			utils.TxPoolMirrorSecretFlag,
+			utils.TxStreamSecretFlag,
 		},
 	},
 	{
@@ -121,6 +154,9 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.CacheSnapshotFlag,
 			utils.CacheNoPrefetchFlag,
 			utils.CachePreimagesFlag,
+			utils.CachePreimagesKindFlag,
+			utils.CachePreimagesLimitFlag,
+			utils.MemoryBudgetFlag,
 		},
 	},
 	{
@@ -149,12 +185,25 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.WSPortFlag,
 			utils.WSApiFlag,
 			utils.WSPathPrefixFlag,
+			utils.WSNotifyBufferFlag,
+			utils.WSNotifyPolicyFlag,
 			utils.WSAllowedOriginsFlag,
 			utils.GraphQLEnabledFlag,
 			utils.GraphQLCORSDomainFlag,
 			utils.GraphQLVirtualHostsFlag,
+			utils.LightProofEnabledFlag,
 			utils.RPCGlobalGasCapFlag,
 			utils.RPCGlobalEVMTimeoutFlag,
+			utils.RPCLogQueryRangeFlag,
+			utils.RPCLogQueryLimitFlag,
+			utils.RPCMulticallBudgetFlag,
+			utils.RPCResponseCacheFlag,
+			utils.RPCHistoricalStateFlag,
+			utils.RPCWorkersFlag,
+			utils.RPCPriorityApiFlag,
+			utils.RPCBatchItemsFlag,
+			utils.RPCBatchCostFlag,
+			utils.RPCConnCallsFlag,
 			utils.RPCGlobalTxFeeCapFlag,
 			utils.AllowUnprotectedTxs,
 			utils.JSpathFlag,
@@ -187,10 +236,15 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.MinerNotifyFullFlag,
 			utils.MinerGasPriceFlag,
 			utils.MinerGasLimitFlag,
+			utils.MinerGasLimitDeltaFlag,
 			utils.MinerEtherbaseFlag,
 			utils.MinerExtraDataFlag,
 			utils.MinerRecommitIntervalFlag,
+			utils.MinerPayloadRecommitFlag,
+			utils.MinerPendingRecommitFlag,
 			utils.MinerNoVerifyFlag,
+			utils.MinerMaxClockSkewFlag,
+			utils.MinerNTPServerFlag,
 		},
 	},
 	{
@@ -20,6 +20,7 @@ package utils
 import (
 	"bufio"
 	"compress/gzip"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -37,11 +38,13 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/eth"
 	"github.com/scroll-tech/go-ethereum/eth/ethconfig"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/internal/debug"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/scroll-tech/go-ethereum/rlp"
 )
 
@@ -272,6 +275,66 @@ func ExportAppendChain(blockchain *core.BlockChain, fn string, first uint64, las
 	return nil
 }
 
+// TestVector is a language-agnostic test vector of a committed block, allowing
+// consensus client implementations to validate their handling of execution
+// payloads without running the execution layer.
+type TestVector struct {
+	Payload         *eth.ExecutableL2Data `json:"payload"`
+	ParentStateRoot common.Hash           `json:"parentStateRoot"`
+	StateRoot       common.Hash           `json:"expectedStateRoot"`
+	ReceiptsRoot    common.Hash           `json:"expectedReceiptsRoot"`
+	SigningRoot     common.Hash           `json:"signingRoot"` // Hash signed by the block producer
+}
+
+// TestVectors is the file format of exported test vectors.
+type TestVectors struct {
+	ChainConfig *params.ChainConfig `json:"chainConfig"`
+	Genesis     common.Hash         `json:"genesisHash"`
+	Vectors     []*TestVector       `json:"vectors"`
+}
+
+// ExportTestVectors exports the canonical blocks from first to last inclusive
+// as JSON test vectors into the specified file, truncating any data already
+// present in the file.
+func ExportTestVectors(blockchain *core.BlockChain, fn string, first uint64, last uint64) error {
+	log.Info("Exporting test vectors", "file", fn, "first", first, "last", last)
+
+	vectors := &TestVectors{
+		ChainConfig: blockchain.Config(),
+		Genesis:     blockchain.Genesis().Hash(),
+		Vectors:     make([]*TestVector, 0, last-first+1),
+	}
+	for number := first; number <= last; number++ {
+		block := blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("export failed on #%d: not found", number)
+		}
+		payload, err := eth.NewExecutableL2Data(block, blockchain.Engine())
+		if err != nil {
+			return fmt.Errorf("export failed on #%d: %v", number, err)
+		}
+		vector := &TestVector{
+			Payload:      payload,
+			StateRoot:    block.Root(),
+			ReceiptsRoot: block.ReceiptHash(),
+			SigningRoot:  payload.SigningRoot,
+		}
+		if parent := blockchain.GetHeader(block.ParentHash(), number-1); parent != nil {
+			vector.ParentStateRoot = parent.Root
+		}
+		vectors.Vectors = append(vectors.Vectors, vector)
+	}
+	blob, err := json.MarshalIndent(vectors, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(fn, blob, 0644); err != nil {
+		return err
+	}
+	log.Info("Exported test vectors", "file", fn, "count", len(vectors.Vectors))
+	return nil
+}
+
 // ImportPreimages imports a batch of exported hash preimages into the database.
 // It's a part of the deprecated functionality, should be removed in the future.
 func ImportPreimages(db ethdb.Database, fn string) error {
@@ -350,6 +413,67 @@ func ExportPreimages(db ethdb.Database, fn string) error {
 	return nil
 }
 
+// ExportPreimagesRange exports the preimages of the trie keys accessed by the
+// canonical blocks in the range [first, last] into the specified file, in the
+// format of ExportPreimages. The keys are taken from the block access lists
+// recorded at import time, so the preimage store isn't needed.
+func ExportPreimagesRange(db ethdb.Database, fn string, first, last uint64) error {
+	log.Info("Exporting preimages", "file", fn, "first", first, "last", last)
+
+	// Open the file handle and potentially wrap with a gzip stream
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		writer = gzip.NewWriter(writer)
+		defer writer.(*gzip.Writer).Close()
+	}
+	var (
+		seen   = make(map[string]struct{})
+		count  int
+		start  = time.Now()
+		logged = time.Now()
+	)
+	export := func(preimage []byte) error {
+		if _, ok := seen[string(preimage)]; ok {
+			return nil
+		}
+		seen[string(preimage)] = struct{}{}
+		count++
+		return rlp.Encode(writer, preimage)
+	}
+	for number := first; number <= last; number++ {
+		hash := rawdb.ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) {
+			return fmt.Errorf("block #%d not found", number)
+		}
+		list := rawdb.ReadBlockAccessList(db, hash)
+		if list == nil && number > 0 {
+			return fmt.Errorf("no access list recorded for block #%d (see --%s)", number, RecordAccessListsFlag.Name)
+		}
+		for _, acc := range list {
+			if err := export(acc.Address.Bytes()); err != nil {
+				return err
+			}
+			for _, slot := range acc.StorageKeys {
+				if err := export(slot.Bytes()); err != nil {
+					return err
+				}
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting preimages", "number", number, "preimages", count, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	log.Info("Exported preimages", "file", fn, "preimages", count, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
 // exportHeader is used in the export/import flow. When we do an export,
 // the first element we output is the exportHeader.
 // Whenever a backwards-incompatible change is made, the Version header
@@ -0,0 +1,272 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/ethdb/memorydb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+// IntegrityIssue is an inconsistency found in the chain database.
+type IntegrityIssue struct {
+	Number   uint64 // Number of the block, or index of the batch, concerned
+	Check    string // Name of the failed check
+	Message  string
+	Repaired bool // Whether the inconsistency was repaired
+}
+
+func (issue *IntegrityIssue) String() string {
+	if issue.Repaired {
+		return fmt.Sprintf("%s #%d: %s (repaired)", issue.Check, issue.Number, issue.Message)
+	}
+	return fmt.Sprintf("%s #%d: %s", issue.Check, issue.Number, issue.Message)
+}
+
+// teeWriter writes into two databases at once.
+type teeWriter struct {
+	a, b ethdb.KeyValueWriter
+}
+
+func (w teeWriter) Put(key []byte, value []byte) error {
+	if err := w.a.Put(key, value); err != nil {
+		return err
+	}
+	return w.b.Put(key, value)
+}
+
+func (w teeWriter) Delete(key []byte) error {
+	if err := w.a.Delete(key); err != nil {
+		return err
+	}
+	return w.b.Delete(key)
+}
+
+// VerifyL2Database checks the canonical chain stored in the database from the
+// genesis up to the head block: the linkage of the headers, the bodies and
+// receipts against their headers, the transaction lookup entries, the withdraw
+// trie recomputed from the receipts and the rollup batches finalized on L1
+// against the blocks they finalize, the stored withdraw trie being checked only
+// if maintained (see --withdrawtrie). With repair set, the derived indices, i.e.
+// header numbers, transaction lookups and the withdraw trie, are rewritten
+// where inconsistent. The inconsistencies found are returned.
+func VerifyL2Database(db ethdb.Database, repair bool) ([]*IntegrityIssue, error) {
+	headHash := rawdb.ReadHeadBlockHash(db)
+	headNumber := rawdb.ReadHeaderNumber(db, headHash)
+	if headNumber == nil {
+		return nil, fmt.Errorf("head block %x not found", headHash)
+	}
+	var (
+		issues []*IntegrityIssue
+		report = func(number uint64, check string, repaired bool, format string, args ...interface{}) {
+			issue := &IntegrityIssue{Number: number, Check: check, Message: fmt.Sprintf(format, args...), Repaired: repaired}
+			log.Warn("Database inconsistency", "check", check, "number", number, "msg", issue.Message, "repaired", repaired)
+			issues = append(issues, issue)
+		}
+		batch = db.NewBatch()
+		flush = func() error {
+			if batch.ValueSize() == 0 {
+				return nil
+			}
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+			return nil
+		}
+		txIndexTail uint64 // Legacy databases without a tail index every block
+		hasher      = trie.NewStackTrie(nil)
+		withdrawMem = memorydb.New()
+		withdraws   = withdrawtrie.OpenTree(withdrawMem, 0)
+		roots       = make(map[uint64][2]common.Hash) // State and withdraw trie roots of the blocks finalized by batches
+		parent      common.Hash
+		start       = time.Now()
+		logged      = time.Now()
+	)
+	// The withdraw trie is optional, only check the stored one if maintained up
+	// to the head, in which case it's maintained from the genesis
+	_, trackWithdraws := rawdb.ReadWithdrawTrieCount(db, headHash)
+	trackWithdraws = trackWithdraws || *headNumber == 0
+
+	if tail := rawdb.ReadTxIndexTail(db); tail != nil {
+		txIndexTail = *tail
+	}
+	batches, last := readRollupBatches(db)
+	for _, b := range batches {
+		roots[b.L2Block] = [2]common.Hash{}
+	}
+	for number := uint64(0); number <= *headNumber; number++ {
+		hash := rawdb.ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) {
+			report(number, "header", false, "canonical hash missing")
+			return issues, nil
+		}
+		header := rawdb.ReadHeader(db, hash, number)
+		if header == nil {
+			report(number, "header", false, "header %x missing", hash)
+			return issues, nil
+		}
+		if header.Hash() != hash {
+			report(number, "header", false, "header hashes to %x, want %x", header.Hash(), hash)
+		}
+		if number > 0 && header.ParentHash != parent {
+			report(number, "header", false, "parent hash %x, want %x", header.ParentHash, parent)
+		}
+		parent = hash
+
+		if n := rawdb.ReadHeaderNumber(db, hash); n == nil || *n != number {
+			if repair {
+				rawdb.WriteHeaderNumber(batch, hash, number)
+			}
+			report(number, "header number", repair, "hash %x not indexed to its number", hash)
+		}
+		body := rawdb.ReadBody(db, hash, number)
+		if body == nil {
+			report(number, "body", false, "body missing")
+			continue
+		}
+		hasher.Reset()
+		if root := types.DeriveSha(types.Transactions(body.Transactions), hasher); root != header.TxHash {
+			report(number, "body", false, "transaction root %x, want %x", root, header.TxHash)
+		}
+		if uncles := types.CalcUncleHash(body.Uncles); uncles != header.UncleHash {
+			report(number, "body", false, "uncle hash %x, want %x", uncles, header.UncleHash)
+		}
+		receipts := rawdb.ReadRawReceipts(db, hash, number)
+		if receipts == nil && len(body.Transactions) > 0 {
+			report(number, "receipts", false, "receipts missing")
+		} else {
+			hasher.Reset()
+			if root := types.DeriveSha(receipts, hasher); root != header.ReceiptHash {
+				report(number, "receipts", false, "receipt root %x, want %x", root, header.ReceiptHash)
+			}
+			if bloom := types.CreateBloom(receipts); bloom != header.Bloom {
+				report(number, "receipts", false, "receipt bloom doesn't match header")
+			}
+		}
+		if number >= txIndexTail {
+			block := types.NewBlockWithHeader(header).WithBody(body.Transactions, body.Uncles)
+			for _, tx := range body.Transactions {
+				if lookup := rawdb.ReadTxLookupEntry(db, tx.Hash()); lookup == nil || *lookup != number {
+					if repair {
+						rawdb.WriteTxLookupEntriesByBlock(batch, block)
+					}
+					report(number, "tx lookup", repair, "transaction %x not indexed to its block", tx.Hash())
+					break
+				}
+			}
+		}
+		// Recompute the withdraw trie from the receipts, comparing it with the
+		// one maintained at import time
+		if number > 0 && withdraws != nil {
+			first, hashes := withdrawtrie.MessageHashes(receipts)
+			if len(hashes) > 0 && first != withdraws.Count() {
+				report(number, "withdraw trie", false, "message index %d, want %d", first, withdraws.Count())
+				withdraws = nil
+			} else {
+				for _, h := range hashes {
+					withdraws.Append(h)
+				}
+				count, ok := rawdb.ReadWithdrawTrieCount(db, hash)
+				switch {
+				case !trackWithdraws:
+				case !ok || count != withdraws.Count():
+					if repair {
+						rawdb.WriteWithdrawTrieCount(batch, hash, withdraws.Count())
+					}
+					report(number, "withdraw trie", repair, "message count %d (tracked %v), want %d", count, ok, withdraws.Count())
+				case len(hashes) > 0:
+					if root := withdrawtrie.OpenTree(db, count).Root(); root != withdraws.Root() {
+						report(number, "withdraw trie", repair, "root %x, want %x", root, withdraws.Root())
+					}
+				}
+				// Persist the recomputed nodes, into the database too if repairing
+				if repair && trackWithdraws {
+					withdraws.Commit(teeWriter{withdrawMem, batch})
+				} else {
+					withdraws.Commit(withdrawMem)
+				}
+			}
+		}
+		if _, ok := roots[number]; ok {
+			var withdrawRoot common.Hash
+			if withdraws != nil {
+				withdrawRoot = withdraws.Root()
+			}
+			roots[number] = [2]common.Hash{header.Root, withdrawRoot}
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Verifying chain database", "number", number, "head", *headNumber, "issues", len(issues), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	// Check the batches finalized on L1 against the blocks they finalize
+	var prev *types.RollupBatch
+	for index := uint64(0); last != nil && index <= *last; index++ {
+		b, ok := batches[index]
+		if !ok {
+			if prev != nil {
+				report(index, "rollup batch", false, "batch missing")
+			}
+			continue
+		}
+		if prev != nil && b.L2Block <= prev.L2Block {
+			report(index, "rollup batch", false, "last block %d not above the one of the previous batch %d", b.L2Block, prev.L2Block)
+		}
+		prev = b
+		if b.L2Block > *headNumber {
+			continue
+		}
+		if root := roots[b.L2Block][0]; root != b.StateRoot {
+			report(index, "rollup batch", false, "state root %x of block %d, finalized %x", root, b.L2Block, b.StateRoot)
+		}
+		if withdraws != nil {
+			if root := roots[b.L2Block][1]; root != b.WithdrawRoot {
+				report(index, "rollup batch", false, "withdraw root %x of block %d, finalized %x", root, b.L2Block, b.WithdrawRoot)
+			}
+		}
+	}
+	log.Info("Verified chain database", "head", *headNumber, "issues", len(issues), "elapsed", common.PrettyDuration(time.Since(start)))
+	return issues, nil
+}
+
+// readRollupBatches reads the batches finalized on L1 recorded in the database,
+// along with the index of the last one.
+func readRollupBatches(db ethdb.KeyValueReader) (map[uint64]*types.RollupBatch, *uint64) {
+	batches := make(map[uint64]*types.RollupBatch)
+	last := rawdb.ReadLastRollupBatch(db)
+	if last == nil {
+		return batches, nil
+	}
+	for index := uint64(0); index <= *last; index++ {
+		if b := rawdb.ReadRollupBatch(db, index); b != nil {
+			batches[index] = b
+		}
+	}
+	return batches, last
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// TestVerifyL2Database checks that inconsistencies of the chain database are
+// detected, and that those of the derived indices are repaired.
+func TestVerifyL2Database(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Scroll.MaxTxPerBlock = nil
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &core.Genesis{Config: &config, Alloc: core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}}
+		signer = types.LatestSigner(&config)
+		db     = rawdb.NewMemoryDatabase()
+	)
+	blocks, _ := core.GenerateChain(&config, gspec.MustCommit(db), ethash.NewFaker(), db, 8, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{0x01}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	db = rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+	cacheConfig := &core.CacheConfig{TrieCleanLimit: 256, TrieDirtyLimit: 256, TrieTimeLimit: 5 * time.Minute, SnapshotLimit: 256, SnapshotWait: true, WithdrawTrie: true}
+	chain, err := core.NewBlockChain(db, cacheConfig, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	chain.Stop()
+
+	if issues, err := VerifyL2Database(db, false); err != nil || len(issues) != 0 {
+		t.Fatalf("consistent database: issues %v, err %v", issues, err)
+	}
+	// Break the derived indices, which must be detected and repaired
+	rawdb.DeleteHeaderNumber(db, blocks[2].Hash())
+	rawdb.DeleteTxLookupEntry(db, blocks[4].Transactions()[0].Hash())
+	rawdb.WriteWithdrawTrieCount(db, blocks[5].Hash(), 7)
+
+	issues, err := VerifyL2Database(db, false)
+	if err != nil {
+		t.Fatalf("failed to verify database: %v", err)
+	}
+	if len(issues) == 0 {
+		t.Fatal("broken indices not detected")
+	}
+	for _, issue := range issues {
+		if issue.Repaired {
+			t.Errorf("issue repaired without repair mode: %v", issue)
+		}
+	}
+	if issues, err = VerifyL2Database(db, true); err != nil {
+		t.Fatalf("failed to repair database: %v", err)
+	}
+	checks := make(map[string]bool)
+	for _, issue := range issues {
+		if !issue.Repaired {
+			t.Errorf("derived index not repaired: %v", issue)
+		}
+		checks[issue.Check] = true
+	}
+	for _, check := range []string{"header number", "tx lookup", "withdraw trie"} {
+		if !checks[check] {
+			t.Errorf("%s inconsistency not detected", check)
+		}
+	}
+	if issues, err := VerifyL2Database(db, false); err != nil || len(issues) != 0 {
+		t.Fatalf("repaired database: issues %v, err %v", issues, err)
+	}
+	// A batch finalized with a different state root can't be repaired
+	rawdb.WriteRollupBatch(db, &types.RollupBatch{Index: 0, StateRoot: common.Hash{0x01}, L2Block: 4})
+	rawdb.WriteLastRollupBatch(db, 0)
+
+	issues, err = VerifyL2Database(db, true)
+	if err != nil {
+		t.Fatalf("failed to verify database: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Check != "rollup batch" || issues[0].Repaired {
+		t.Fatalf("batch state root mismatch: issues %v", issues)
+	}
+}
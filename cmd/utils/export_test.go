@@ -17,13 +17,24 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/scroll-tech/go-ethereum/rlp"
 )
 
@@ -196,3 +207,124 @@ func TestImportFutureFormat(t *testing.T) {
 		t.Fatalf("wrong error: %v", err)
 	}
 }
+
+// TestExportImportChain checks that exported chains are re-imported losslessly,
+// including the optional header fields, in both plain and gzipped form.
+func TestExportImportChain(t *testing.T) {
+	for _, name := range []string{"chain.rlp", "chain.rlp.gz"} {
+		testExportImportChain(t, filepath.Join(t.TempDir(), name))
+	}
+}
+
+func testExportImportChain(t *testing.T, fn string) {
+	config := *params.TestChainConfig
+	config.Scroll.MaxTxPerBlock = nil
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &core.Genesis{Config: &config, Alloc: core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}}
+		signer = types.LatestSigner(&config)
+	)
+	newChain := func() *core.BlockChain {
+		db := rawdb.NewMemoryDatabase()
+		gspec.MustCommit(db)
+		chain, err := core.NewBlockChain(db, nil, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create chain: %v", err)
+		}
+		return chain
+	}
+	db := rawdb.NewMemoryDatabase()
+	blocks, _ := core.GenerateChain(&config, gspec.MustCommit(db), ethash.NewFaker(), db, 64, func(i int, b *core.BlockGen) {
+		b.SetExtra([]byte{byte(i)})
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{0x01}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	src := newChain()
+	defer src.Stop()
+	if _, err := src.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	if err := ExportChain(src, fn); err != nil {
+		t.Fatalf("failed to export chain: %v", err)
+	}
+	dst := newChain()
+	defer dst.Stop()
+	if err := ImportChain(dst, fn); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	if head := dst.CurrentBlock(); head.Hash() != blocks[len(blocks)-1].Hash() {
+		t.Fatalf("head mismatch: have #%d %x, want #%d %x", head.NumberU64(), head.Hash(), len(blocks), blocks[len(blocks)-1].Hash())
+	}
+	for _, block := range blocks {
+		have := dst.GetHeaderByNumber(block.NumberU64())
+		if have.BaseFee == nil {
+			t.Fatalf("block #%d: base fee dropped", block.NumberU64())
+		}
+		if !reflect.DeepEqual(have, block.Header()) {
+			t.Fatalf("block #%d: header mismatch: have %v, want %v", block.NumberU64(), have, block.Header())
+		}
+	}
+}
+
+// TestExportTestVectors checks that exported test vectors carry the payloads and
+// expected roots of the requested block range.
+func TestExportTestVectors(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Scroll.MaxTxPerBlock = nil
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		db     = rawdb.NewMemoryDatabase()
+		gspec  = &core.Genesis{Config: &config, Alloc: core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}}
+		signer = types.LatestSigner(&config)
+		engine = ethash.NewFaker()
+	)
+	blocks, _ := core.GenerateChain(&config, gspec.MustCommit(db), engine, db, 8, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{0x01}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	fn := filepath.Join(t.TempDir(), "vectors.json")
+	if err := ExportTestVectors(chain, fn, 3, 6); err != nil {
+		t.Fatalf("failed to export test vectors: %v", err)
+	}
+	blob, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatalf("failed to read test vectors: %v", err)
+	}
+	var vectors TestVectors
+	if err := json.Unmarshal(blob, &vectors); err != nil {
+		t.Fatalf("failed to decode test vectors: %v", err)
+	}
+	if vectors.Genesis != chain.Genesis().Hash() {
+		t.Errorf("genesis mismatch: have %x, want %x", vectors.Genesis, chain.Genesis().Hash())
+	}
+	if len(vectors.Vectors) != 4 {
+		t.Fatalf("vector count mismatch: have %d, want 4", len(vectors.Vectors))
+	}
+	for i, vector := range vectors.Vectors {
+		block := blocks[i+2]
+		if vector.Payload.BlockHash != block.Hash() || uint64(vector.Payload.Number) != block.NumberU64() {
+			t.Errorf("vector %d: payload mismatch: have #%d %x, want #%d %x", i, vector.Payload.Number, vector.Payload.BlockHash, block.NumberU64(), block.Hash())
+		}
+		if vector.ParentStateRoot != blocks[i+1].Root() {
+			t.Errorf("vector %d: parent state root mismatch: have %x, want %x", i, vector.ParentStateRoot, blocks[i+1].Root())
+		}
+		if vector.StateRoot != block.Root() || vector.ReceiptsRoot != block.ReceiptHash() {
+			t.Errorf("vector %d: expected roots mismatch", i)
+		}
+		if want := engine.SealHash(block.Header()); vector.SigningRoot != want {
+			t.Errorf("vector %d: signing root mismatch: have %x, want %x", i, vector.SigningRoot, want)
+		}
+	}
+}
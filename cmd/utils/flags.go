@@ -49,10 +49,19 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/vm"
 	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/eth"
+	"github.com/scroll-tech/go-ethereum/eth/blockstream"
 	"github.com/scroll-tech/go-ethereum/eth/downloader"
 	"github.com/scroll-tech/go-ethereum/eth/ethconfig"
+	"github.com/scroll-tech/go-ethereum/eth/eventsink"
+	"github.com/scroll-tech/go-ethereum/eth/firehose"
 	"github.com/scroll-tech/go-ethereum/eth/gasprice"
+	"github.com/scroll-tech/go-ethereum/eth/lightproof"
+	"github.com/scroll-tech/go-ethereum/eth/membudget"
+	"github.com/scroll-tech/go-ethereum/eth/shadow"
 	"github.com/scroll-tech/go-ethereum/eth/tracers"
+	"github.com/scroll-tech/go-ethereum/eth/txmirror"
+	"github.com/scroll-tech/go-ethereum/eth/txstream"
+	"github.com/scroll-tech/go-ethereum/eth/watchdog"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/ethstats"
 	"github.com/scroll-tech/go-ethereum/graphql"
@@ -70,6 +79,8 @@ import (
 	"github.com/scroll-tech/go-ethereum/p2p/nat"
 	"github.com/scroll-tech/go-ethereum/p2p/netutil"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/inclusion"
+	"github.com/scroll-tech/go-ethereum/trie"
 )
 
 func init() {
@@ -118,6 +129,19 @@ var (
 		Name:  "datadir.ancient",
 		Usage: "Data directory for ancient chain segments (default = inside chaindata)",
 	}
+	DBEngineFlag = cli.StringFlag{
+		Name:  "db.engine",
+		Usage: "Backing database implementation to use ('leveldb' or 'pebble', default = engine of the existing database, leveldb otherwise)",
+	}
+	AncientRemoteFlag = cli.StringFlag{
+		Name:  "datadir.ancient.remote",
+		Usage: "Object store to offload sealed ancient chain segments to (s3://bucket/prefix[?endpoint=URL&region=R] or gs://bucket/prefix, credentials from AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY)",
+	}
+	AncientRemoteCacheFlag = cli.IntFlag{
+		Name:  "datadir.ancient.remote.cache",
+		Usage: "Megabytes of local disk used to cache ancient data read back from the object store",
+		Value: 1024,
+	}
 	MinFreeDiskSpaceFlag = DirectoryFlag{
 		Name:  "datadir.minfreedisk",
 		Usage: "Minimum free disk space in MB, once reached triggers auto shut down (default = --cache.gc converted to MB, 0 = disabled)",
@@ -236,6 +260,59 @@ var (
 		Usage: "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
 		Value: ethconfig.Defaults.TxLookupLimit,
 	}
+	RecordAccessListsFlag = cli.BoolFlag{
+		Name:  "recordaccesslists",
+		Usage: "Record the block-level access list (accounts, slots, code) of every imported block",
+	}
+	LogIndexFlag = cli.BoolFlag{
+		Name:  "logindex",
+		Usage: "Maintain a per-address/topic log index to speed up log filtering",
+	}
+	RecordCallTracesFlag = cli.BoolFlag{
+		Name:  "recordcalltraces",
+		Usage: "Record the internal transactions (calls and creations) of every imported block",
+	}
+	RecordBlockStatsFlag = cli.BoolFlag{
+		Name:  "recordblockstats",
+		Usage: "Record the resource usage (execution time, trie hashing, db writes, cache hits) of every imported block",
+	}
+	RecordContractUsageFlag = cli.BoolFlag{
+		Name:  "recordcontractusage",
+		Usage: "Record the gas used and the storage slots created and cleared by each contract in every imported block",
+	}
+	RecordStateSizeFlag = cli.BoolFlag{
+		Name:  "recordstatesize",
+		Usage: "Record the number of accounts, storage slots and code bytes in the state after every imported block",
+	}
+	CreationIndexFlag = cli.BoolFlag{
+		Name:  "creationindex",
+		Usage: "Maintain an index of contract creations (creator, nonce or CREATE2 salt, init code hash) by address",
+	}
+	AccessEpochFlag = cli.Uint64Flag{
+		Name:  "accessepoch",
+		Usage: "Tag accessed accounts and storage slots with their last access epoch of this many blocks, for state expiry research (0 = disabled)",
+	}
+	WithdrawTrieFlag = cli.BoolFlag{
+		Name:  "withdrawtrie",
+		Usage: "Maintain the withdraw trie of L2 to L1 messages to serve withdrawal proofs (requires syncing from genesis)",
+	}
+	RollupReplicaFlag = cli.StringFlag{
+		Name:  "rollup.replica",
+		Usage: "Run as a read replica importing blocks solely from the block feed of the sequencer at the given websocket endpoint (disables mining and p2p sync)",
+	}
+	L1EndpointFlag = cli.StringFlag{
+		Name:  "l1.endpoint",
+		Usage: "RPC endpoint of the L1 node to follow batch finalization on, marking the blocks of finalized batches as finalized",
+	}
+	InclusionListWindowFlag = cli.Uint64Flag{
+		Name:  "rollup.inclusionwindow",
+		Usage: "Number of blocks a transaction required by an inclusion list must be included within",
+		Value: inclusion.DefaultWindow,
+	}
+	RollupAllowFinalizedRewindFlag = cli.BoolFlag{
+		Name:  "rollup.allowfinalizedrewind",
+		Usage: "Allow rewinding and reorging the chain below the block finalized on L1 (disaster recovery only)",
+	}
 	LightKDFFlag = cli.BoolFlag{
 		Name:  "lightkdf",
 		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -353,6 +430,10 @@ var (
 		Usage: "Time interval to regenerate the local transaction journal",
 		Value: core.DefaultTxPoolConfig.Rejournal,
 	}
+	TxPoolPersistFlag = cli.BoolFlag{
+		Name:  "txpool.persist",
+		Usage: "Persists all pooled transactions with their receive times in the database instead of the local journal",
+	}
 	TxPoolPriceLimitFlag = cli.Uint64Flag{
 		Name:  "txpool.pricelimit",
 		Usage: "Minimum gas price limit to enforce for acceptance into the pool",
@@ -388,6 +469,26 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: ethconfig.Defaults.TxPool.Lifetime,
 	}
+	TxPoolTxLifetimeFlag = cli.DurationFlag{
+		Name:  "txpool.txlifetime",
+		Usage: "Maximum amount of time any transaction stays in the pool, locals included (0 = unlimited)",
+	}
+	TxPoolGapLifetimeFlag = cli.DurationFlag{
+		Name:  "txpool.gaplifetime",
+		Usage: "Maximum amount of time transactions are queued behind a nonce gap, locals included (0 = --txpool.lifetime only)",
+	}
+	TxPoolMirrorURLFlag = cli.StringFlag{
+		Name:  "txpool.mirror.url",
+		Usage: "Websocket endpoint of a sequencer whose transaction pool to mirror",
+	}
+	TxPoolMirrorSecretFlag = cli.StringFlag{
+		Name:  "txpool.mirror.secret",
+		Usage: "Shared secret of the transaction pool mirror (serves the mirror on the \"scroll\" websocket API unless --txpool.mirror.url is set)",
+	}
+	TxStreamSecretFlag = cli.StringFlag{
+		Name:  "txstream.secret",
+		Usage: "Shared secret of the stream of transactions applied to the blocks being assembled (serves it on the \"scroll\" websocket API)",
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -432,6 +533,19 @@ var (
 		Name:  "cache.preimages",
 		Usage: "Enable recording the SHA3/keccak preimages of trie keys",
 	}
+	CachePreimagesKindFlag = cli.StringFlag{
+		Name:  "cache.preimages.kind",
+		Usage: `Kind of trie keys to record the preimages of ("all", "accounts" or "storage")`,
+		Value: trie.PreimagesAll,
+	}
+	CachePreimagesLimitFlag = cli.Uint64Flag{
+		Name:  "cache.preimages.limit",
+		Usage: "Maximum number of trie key preimages to store, recording stops once reached (0 = unlimited)",
+	}
+	MemoryBudgetFlag = cli.IntFlag{
+		Name:  "memory.budget",
+		Usage: "Megabytes of memory the node may use in total, sizing --cache and the txpool slots unless set explicitly (0 = unlimited)",
+	}
 	// Miner settings
 	MiningEnabledFlag = cli.BoolFlag{
 		Name:  "mine",
@@ -455,6 +569,10 @@ var (
 		Usage: "Target gas ceiling for mined blocks",
 		Value: ethconfig.Defaults.Miner.GasCeil,
 	}
+	MinerGasLimitDeltaFlag = cli.Uint64Flag{
+		Name:  "miner.gaslimitdelta",
+		Usage: "Maximum gas limit change between mined blocks while moving towards the gas ceiling (0 = consensus bound)",
+	}
 	MinerGasPriceFlag = BigFlag{
 		Name:  "miner.gasprice",
 		Usage: "Minimum gas price for mining a transaction",
@@ -474,10 +592,29 @@ var (
 		Usage: "Time interval to recreate the block being mined",
 		Value: ethconfig.Defaults.Miner.Recommit,
 	}
+	MinerPayloadRecommitFlag = cli.DurationFlag{
+		Name:  "miner.payloadrecommit",
+		Usage: "Time interval to improve the blocks built through the consensus API with new transactions (0 = disabled)",
+		Value: ethconfig.Defaults.Miner.PayloadRecommit,
+	}
+	MinerPendingRecommitFlag = cli.DurationFlag{
+		Name:  "miner.pendingrecommit",
+		Usage: "Time interval to re-execute the pending block served to the RPC APIs on head or pool changes (0 = disabled)",
+	}
 	MinerNoVerifyFlag = cli.BoolFlag{
 		Name:  "miner.noverify",
 		Usage: "Disable remote sealing verification",
 	}
+	MinerMaxClockSkewFlag = cli.DurationFlag{
+		Name:  "miner.maxclockskew",
+		Usage: "Maximum distance of block timestamps and local clock drift from wall clock before refusing to seal (0 = unchecked)",
+		Value: ethconfig.Defaults.Miner.MaxClockSkew,
+	}
+	MinerNTPServerFlag = cli.StringFlag{
+		Name:  "miner.ntpserver",
+		Usage: "NTP server used to measure local clock drift when --miner.maxclockskew is set (empty = trust local clock)",
+		Value: ethconfig.Defaults.Miner.NTPServer,
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -512,6 +649,50 @@ var (
 		Usage: "Sets a timeout used for eth_call (0=infinite)",
 		Value: ethconfig.Defaults.RPCEVMTimeout,
 	}
+	RPCLogQueryRangeFlag = cli.Uint64Flag{
+		Name:  "rpc.logs.blockrange",
+		Usage: "Sets a cap on the number of blocks a single eth_getLogs query may span (0=infinite)",
+		Value: ethconfig.Defaults.RPCLogQueryRange,
+	}
+	RPCLogQueryLimitFlag = cli.IntFlag{
+		Name:  "rpc.logs.limit",
+		Usage: "Sets a cap on the number of logs a single eth_getLogs query may return (0=infinite)",
+		Value: ethconfig.Defaults.RPCLogQueryLimit,
+	}
+	RPCMulticallBudgetFlag = cli.Uint64Flag{
+		Name:  "rpc.multicallbudget",
+		Usage: "Sets a cap on the total gas that a single scroll_multicall may use across its calls (0=infinite)",
+		Value: ethconfig.Defaults.RPCMulticallBudget,
+	}
+	RPCResponseCacheFlag = cli.IntFlag{
+		Name:  "rpc.responsecache",
+		Usage: "Megabytes of memory allocated to caching responses about finalized blocks, receipts and traces (0=disabled)",
+	}
+	RPCHistoricalStateFlag = cli.StringFlag{
+		Name:  "rpc.historicalstate",
+		Usage: "JSON-RPC endpoint of an archive node to forward the calls, proofs and traces of pruned blocks to",
+	}
+	RPCWorkersFlag = cli.IntFlag{
+		Name:  "rpc.workers",
+		Usage: "Sets a cap on the number of HTTP and WebSocket calls served concurrently, excluding priority API calls (0=infinite)",
+	}
+	RPCPriorityApiFlag = cli.StringFlag{
+		Name:  "rpc.priorityapi",
+		Usage: "API's whose calls are served on a dedicated pool, never queued behind other calls",
+		Value: strings.Join(node.DefaultConfig.RPCPriorityModules, ","),
+	}
+	RPCBatchItemsFlag = cli.IntFlag{
+		Name:  "rpc.batch.limit",
+		Usage: "Maximum number of calls executed from an HTTP or WebSocket batch, the rest are answered with an error (0=infinite)",
+	}
+	RPCBatchCostFlag = cli.IntFlag{
+		Name:  "rpc.batch.costlimit",
+		Usage: "Maximum cumulative cost of the calls executed from an HTTP or WebSocket batch, expensive calls costing more (0=infinite)",
+	}
+	RPCConnCallsFlag = cli.IntFlag{
+		Name:  "rpc.conncalls",
+		Usage: "Sets a cap on the number of calls of a single HTTP or WebSocket connection served concurrently (0=infinite)",
+	}
 	RPCGlobalTxFeeCapFlag = cli.Float64Flag{
 		Name:  "rpc.txfeecap",
 		Usage: "Sets a cap on transaction fee (in ether) that can be sent via the RPC APIs (0 = no cap)",
@@ -522,6 +703,51 @@ var (
 		Name:  "ethstats",
 		Usage: "Reporting URL of a ethstats service (nodename:secret@host:port)",
 	}
+	ShadowURLFlag = cli.StringFlag{
+		Name:  "shadow.url",
+		Usage: "RPC endpoint of a reference node to compare every committed block against",
+	}
+	ShadowHaltFlag = cli.BoolFlag{
+		Name:  "shadow.halt",
+		Usage: "Shut down the node when shadow verification detects a divergence",
+	}
+	EventSinkURLFlag = cli.StringFlag{
+		Name:  "eventsink.url",
+		Usage: "HTTP endpoint to post chain lifecycle events to as JSON",
+	}
+	EventSinkEventsFlag = cli.StringFlag{
+		Name:  "eventsink.events",
+		Usage: "Comma separated kinds of events to post to the event sink (block, reorg, finalized; default = all)",
+	}
+	BlockStreamURLFlag = cli.StringFlag{
+		Name:  "blockstream.url",
+		Usage: "HTTP endpoint to stream the full canonical blocks with receipts and traces to",
+	}
+	BlockStreamTopicFlag = cli.StringFlag{
+		Name:  "blockstream.topic",
+		Usage: "Topic of the block stream, keying its resumable offset",
+		Value: blockstream.DefaultTopic,
+	}
+	FirehoseEnabledFlag = cli.BoolFlag{
+		Name:  "firehose",
+		Usage: "Write the imported canonical blocks in the Firehose format",
+	}
+	FirehoseOutputFlag = cli.StringFlag{
+		Name:  "firehose.output",
+		Usage: "File to append the Firehose blocks to (default = stdout)",
+	}
+	WatchdogStallFlag = cli.DurationFlag{
+		Name:  "watchdog.stall",
+		Usage: "Alert when no block was committed for this long (0 = disabled)",
+	}
+	WatchdogLatencyFlag = cli.DurationFlag{
+		Name:  "watchdog.latency",
+		Usage: "Alert when a block is committed this long after its timestamp, for nodes at the chain tip (0 = disabled)",
+	}
+	WatchdogWebhookFlag = cli.StringFlag{
+		Name:  "watchdog.webhook",
+		Usage: "URL to post block production alerts to as JSON",
+	}
 	FakePoWFlag = cli.BoolFlag{
 		Name:  "fakepow",
 		Usage: "Disables proof-of-work verification",
@@ -577,6 +803,10 @@ var (
 		Name:  "graphql",
 		Usage: "Enable GraphQL on the HTTP-RPC server. Note that GraphQL can only be started if an HTTP server is started as well.",
 	}
+	LightProofEnabledFlag = cli.BoolFlag{
+		Name:  "lightproof",
+		Usage: "Serve headers and state, receipt and batch finalization proofs to light clients on the HTTP-RPC server, under /light/v1",
+	}
 	GraphQLCORSDomainFlag = cli.StringFlag{
 		Name:  "graphql.corsdomain",
 		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
@@ -616,6 +846,16 @@ var (
 		Usage: "HTTP path prefix on which JSON-RPC is served. Use '/' to serve on all paths.",
 		Value: "",
 	}
+	WSNotifyBufferFlag = cli.IntFlag{
+		Name:  "ws.notify.buffer",
+		Usage: "Maximum number of notifications queued per WebSocket subscription whose subscriber doesn't keep up (0=write right away)",
+		Value: node.DefaultConfig.WSNotifyBuffer,
+	}
+	WSNotifyPolicyFlag = cli.StringFlag{
+		Name:  "ws.notify.policy",
+		Usage: `What to do when the notification queue of a WebSocket subscription is full ("disconnect" or "drop" the oldest)`,
+		Value: node.DefaultConfig.WSNotifyPolicy,
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
@@ -978,6 +1218,21 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(AllowUnprotectedTxs.Name) {
 		cfg.AllowUnprotectedTxs = ctx.GlobalBool(AllowUnprotectedTxs.Name)
 	}
+	if ctx.GlobalIsSet(RPCWorkersFlag.Name) {
+		cfg.RPCWorkers = ctx.GlobalInt(RPCWorkersFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCPriorityApiFlag.Name) {
+		cfg.RPCPriorityModules = SplitAndTrim(ctx.GlobalString(RPCPriorityApiFlag.Name))
+	}
+	if ctx.GlobalIsSet(RPCBatchItemsFlag.Name) {
+		cfg.RPCBatchItems = ctx.GlobalInt(RPCBatchItemsFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCBatchCostFlag.Name) {
+		cfg.RPCBatchCost = ctx.GlobalInt(RPCBatchCostFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCConnCallsFlag.Name) {
+		cfg.RPCConnCalls = ctx.GlobalInt(RPCConnCallsFlag.Name)
+	}
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1015,6 +1270,19 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(WSPathPrefixFlag.Name) {
 		cfg.WSPathPrefix = ctx.GlobalString(WSPathPrefixFlag.Name)
 	}
+
+	if ctx.GlobalIsSet(WSNotifyBufferFlag.Name) {
+		cfg.WSNotifyBuffer = ctx.GlobalInt(WSNotifyBufferFlag.Name)
+	}
+
+	if ctx.GlobalIsSet(WSNotifyPolicyFlag.Name) {
+		switch policy := ctx.GlobalString(WSNotifyPolicyFlag.Name); policy {
+		case "drop", "disconnect":
+			cfg.WSNotifyPolicy = policy
+		default:
+			Fatalf("--%s: unknown policy %q, want \"drop\" or \"disconnect\"", WSNotifyPolicyFlag.Name, policy)
+		}
+	}
 }
 
 // setIPC creates an IPC path configuration from the set command line flags,
@@ -1206,6 +1474,12 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		cfg.NetRestrict = list
 	}
 
+	if ctx.GlobalIsSet(RollupReplicaFlag.Name) {
+		// Read replicas import blocks from the sequencer only
+		cfg.MaxPeers = 0
+		cfg.NoDiscovery = true
+		cfg.DiscoveryV5 = false
+	}
 	if ctx.GlobalBool(DeveloperFlag.Name) || ctx.GlobalBool(CatalystFlag.Name) {
 		// --dev mode can't use p2p networking.
 		cfg.MaxPeers = 0
@@ -1227,6 +1501,20 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setDataDir(ctx, cfg)
 	setSmartCard(ctx, cfg)
 
+	if ctx.GlobalIsSet(DBEngineFlag.Name) {
+		dbEngine := ctx.GlobalString(DBEngineFlag.Name)
+		if dbEngine != "leveldb" && dbEngine != "pebble" {
+			Fatalf("Invalid choice for db.engine '%s', allowed 'leveldb' or 'pebble'", dbEngine)
+		}
+		log.Info(fmt.Sprintf("Using %s as db engine", dbEngine))
+		cfg.DBEngine = dbEngine
+	}
+
+	if ctx.GlobalIsSet(AncientRemoteFlag.Name) {
+		cfg.AncientRemote = ctx.GlobalString(AncientRemoteFlag.Name)
+		cfg.AncientRemoteCache = ctx.GlobalInt(AncientRemoteCacheFlag.Name)
+	}
+
 	if ctx.GlobalIsSet(ExternalSignerFlag.Name) {
 		cfg.ExternalSigner = ctx.GlobalString(ExternalSignerFlag.Name)
 	}
@@ -1340,6 +1628,9 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.GlobalDuration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolPersistFlag.Name) {
+		cfg.Persist = ctx.GlobalBool(TxPoolPersistFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -1361,6 +1652,12 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolTxLifetimeFlag.Name) {
+		cfg.TxLifetime = ctx.GlobalDuration(TxPoolTxLifetimeFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolGapLifetimeFlag.Name) {
+		cfg.GapLifetime = ctx.GlobalDuration(TxPoolGapLifetimeFlag.Name)
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1401,15 +1698,30 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.GlobalIsSet(MinerGasLimitFlag.Name) {
 		cfg.GasCeil = ctx.GlobalUint64(MinerGasLimitFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerGasLimitDeltaFlag.Name) {
+		cfg.GasLimitDelta = ctx.GlobalUint64(MinerGasLimitDeltaFlag.Name)
+	}
 	if ctx.GlobalIsSet(MinerGasPriceFlag.Name) {
 		cfg.GasPrice = GlobalBig(ctx, MinerGasPriceFlag.Name)
 	}
 	if ctx.GlobalIsSet(MinerRecommitIntervalFlag.Name) {
 		cfg.Recommit = ctx.GlobalDuration(MinerRecommitIntervalFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerPayloadRecommitFlag.Name) {
+		cfg.PayloadRecommit = ctx.GlobalDuration(MinerPayloadRecommitFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerPendingRecommitFlag.Name) {
+		cfg.PendingRecommit = ctx.GlobalDuration(MinerPendingRecommitFlag.Name)
+	}
 	if ctx.GlobalIsSet(MinerNoVerifyFlag.Name) {
 		cfg.Noverify = ctx.GlobalBool(MinerNoVerifyFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerMaxClockSkewFlag.Name) {
+		cfg.MaxClockSkew = ctx.GlobalDuration(MinerMaxClockSkewFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerNTPServerFlag.Name) {
+		cfg.NTPServer = ctx.GlobalString(MinerNTPServerFlag.Name)
+	}
 	if ctx.GlobalIsSet(LegacyMinerGasTargetFlag.Name) {
 		log.Warn("The generic --miner.gastarget flag is deprecated and will be removed in the future!")
 	}
@@ -1485,6 +1797,8 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	CheckExclusive(ctx, MainnetFlag, DeveloperFlag, RopstenFlag, RinkebyFlag, GoerliFlag, SepoliaFlag, ScrollAlphaFlag)
 	CheckExclusive(ctx, LightServeFlag, SyncModeFlag, "light")
 	CheckExclusive(ctx, DeveloperFlag, ExternalSignerFlag) // Can't use both ephemeral unlocked and external signer
+	// Read replicas never produce blocks
+	CheckExclusive(ctx, RollupReplicaFlag, MiningEnabledFlag, DeveloperFlag, CatalystFlag)
 	if ctx.GlobalString(GCModeFlag.Name) == "archive" && ctx.GlobalUint64(TxLookupLimitFlag.Name) != 0 {
 		ctx.GlobalSet(TxLookupLimitFlag.Name, "0")
 		log.Warn("Disable transaction unindexing for archive node")
@@ -1504,6 +1818,21 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setWhitelist(ctx, cfg)
 	setLes(ctx, cfg)
 
+	// Size the caches and the transaction pool from the memory budget
+	if budget := ctx.GlobalInt(MemoryBudgetFlag.Name); budget > 0 {
+		alloc := membudget.Allocate(budget)
+		if !ctx.GlobalIsSet(CacheFlag.Name) {
+			ctx.GlobalSet(CacheFlag.Name, strconv.Itoa(alloc.Cache))
+		}
+		if !ctx.GlobalIsSet(TxPoolGlobalSlotsFlag.Name) {
+			cfg.TxPool.GlobalSlots = alloc.TxPoolSlots
+		}
+		if !ctx.GlobalIsSet(TxPoolGlobalQueueFlag.Name) {
+			cfg.TxPool.GlobalQueue = alloc.TxPoolQueue
+		}
+		cfg.MemoryBudget = budget
+		log.Info("Sized node to memory budget", "budget", budget, "cache", ctx.GlobalInt(CacheFlag.Name), "txslots", cfg.TxPool.GlobalSlots, "txqueue", cfg.TxPool.GlobalQueue)
+	}
 	// Cap the cache allowance and tune the garbage collector
 	mem, err := gopsutil.VirtualMemory()
 	if err == nil {
@@ -1553,9 +1882,59 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		cfg.Preimages = true
 		log.Info("Enabling recording of key preimages since archive mode is used")
 	}
+	if ctx.GlobalIsSet(CachePreimagesKindFlag.Name) {
+		cfg.PreimageKind = ctx.GlobalString(CachePreimagesKindFlag.Name)
+	}
+	if ctx.GlobalIsSet(CachePreimagesLimitFlag.Name) {
+		cfg.PreimageLimit = ctx.GlobalUint64(CachePreimagesLimitFlag.Name)
+	}
+	switch cfg.PreimageKind {
+	case "", trie.PreimagesAll, trie.PreimagesAccounts, trie.PreimagesStorage:
+	default:
+		Fatalf("--%s must be either '%s', '%s' or '%s'", CachePreimagesKindFlag.Name, trie.PreimagesAll, trie.PreimagesAccounts, trie.PreimagesStorage)
+	}
 	if ctx.GlobalIsSet(TxLookupLimitFlag.Name) {
 		cfg.TxLookupLimit = ctx.GlobalUint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.GlobalIsSet(RecordAccessListsFlag.Name) {
+		cfg.RecordAccessLists = ctx.GlobalBool(RecordAccessListsFlag.Name)
+	}
+	if ctx.GlobalIsSet(LogIndexFlag.Name) {
+		cfg.LogIndex = ctx.GlobalBool(LogIndexFlag.Name)
+	}
+	if ctx.GlobalIsSet(RecordCallTracesFlag.Name) {
+		cfg.RecordCallTraces = ctx.GlobalBool(RecordCallTracesFlag.Name)
+	}
+	if ctx.GlobalIsSet(RecordBlockStatsFlag.Name) {
+		cfg.RecordBlockStats = ctx.GlobalBool(RecordBlockStatsFlag.Name)
+	}
+	if ctx.GlobalIsSet(RecordContractUsageFlag.Name) {
+		cfg.RecordContractUsage = ctx.GlobalBool(RecordContractUsageFlag.Name)
+	}
+	if ctx.GlobalIsSet(RecordStateSizeFlag.Name) {
+		cfg.RecordStateSize = ctx.GlobalBool(RecordStateSizeFlag.Name)
+	}
+	if ctx.GlobalIsSet(CreationIndexFlag.Name) {
+		cfg.CreationIndex = ctx.GlobalBool(CreationIndexFlag.Name)
+	}
+	if ctx.GlobalIsSet(AccessEpochFlag.Name) {
+		cfg.AccessEpochLength = ctx.GlobalUint64(AccessEpochFlag.Name)
+	}
+	if ctx.GlobalIsSet(WithdrawTrieFlag.Name) {
+		cfg.WithdrawTrie = ctx.GlobalBool(WithdrawTrieFlag.Name)
+	}
+	if ctx.GlobalIsSet(L1EndpointFlag.Name) {
+		cfg.L1Endpoint = ctx.GlobalString(L1EndpointFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupReplicaFlag.Name) {
+		cfg.Replica = ctx.GlobalString(RollupReplicaFlag.Name)
+	}
+	if ctx.GlobalIsSet(RollupAllowFinalizedRewindFlag.Name) {
+		cfg.AllowFinalizedRewind = ctx.GlobalBool(RollupAllowFinalizedRewindFlag.Name)
+	}
+	if ctx.GlobalIsSet(InclusionListWindowFlag.Name) {
+		cfg.InclusionListWindow = ctx.GlobalUint64(InclusionListWindowFlag.Name)
+	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheTrieFlag.Name) / 100
 	}
@@ -1599,6 +1978,21 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.GlobalIsSet(RPCGlobalEVMTimeoutFlag.Name) {
 		cfg.RPCEVMTimeout = ctx.GlobalDuration(RPCGlobalEVMTimeoutFlag.Name)
 	}
+	if ctx.GlobalIsSet(RPCLogQueryRangeFlag.Name) {
+		cfg.RPCLogQueryRange = ctx.GlobalUint64(RPCLogQueryRangeFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCLogQueryLimitFlag.Name) {
+		cfg.RPCLogQueryLimit = ctx.GlobalInt(RPCLogQueryLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCResponseCacheFlag.Name) {
+		cfg.RPCResponseCache = ctx.GlobalInt(RPCResponseCacheFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCHistoricalStateFlag.Name) {
+		cfg.HistoricalStateURL = ctx.GlobalString(RPCHistoricalStateFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCMulticallBudgetFlag.Name) {
+		cfg.RPCMulticallBudget = ctx.GlobalUint64(RPCMulticallBudgetFlag.Name)
+	}
 	if ctx.GlobalIsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.GlobalFloat64(RPCGlobalTxFeeCapFlag.Name)
 	}
@@ -1754,6 +2148,69 @@ func RegisterEthStatsService(stack *node.Node, backend ethapi.Backend, url strin
 	}
 }
 
+// RegisterShadowService configures the shadow verifier comparing committed
+// blocks with a reference node and adds it to the given node.
+func RegisterShadowService(stack *node.Node, backend ethapi.Backend, cfg shadow.Config) {
+	if err := shadow.New(stack, backend, cfg); err != nil {
+		Fatalf("Failed to register the shadow verification service: %v", err)
+	}
+}
+
+// RegisterEventSinkService configures the chain event sink and adds it to the
+// given node.
+func RegisterEventSinkService(stack *node.Node, backend ethapi.Backend, cfg eventsink.Config) {
+	if err := eventsink.New(stack, backend, cfg); err != nil {
+		Fatalf("Failed to register the event sink service: %v", err)
+	}
+}
+
+// RegisterBlockStreamService configures the block stream and adds it to the
+// given node.
+func RegisterBlockStreamService(stack *node.Node, backend *eth.Ethereum, cfg blockstream.Config) {
+	if err := blockstream.New(stack, backend.BlockChain(), backend.ChainDb(), cfg); err != nil {
+		Fatalf("Failed to register the block stream service: %v", err)
+	}
+}
+
+// RegisterFirehoseService configures the Firehose extraction and adds it to the
+// given node.
+func RegisterFirehoseService(stack *node.Node, backend *eth.Ethereum, cfg firehose.Config) {
+	if err := firehose.New(stack, backend.BlockChain(), cfg); err != nil {
+		Fatalf("Failed to register the Firehose extraction: %v", err)
+	}
+}
+
+// RegisterWatchdogService configures the block production watchdog and adds it
+// to the given node.
+func RegisterWatchdogService(stack *node.Node, backend ethapi.Backend, cfg watchdog.Config) {
+	if err := watchdog.New(stack, backend, cfg); err != nil {
+		Fatalf("Failed to register the block production watchdog: %v", err)
+	}
+}
+
+// RegisterTxMirrorService configures the transaction pool mirror and adds it to
+// the given node: following a sequencer if its URL is configured, or serving
+// the local pool to authenticated followers otherwise.
+func RegisterTxMirrorService(stack *node.Node, backend *eth.Ethereum, cfg txmirror.Config) {
+	var err error
+	if cfg.URL != "" {
+		err = txmirror.NewClient(stack, backend.TxPool(), cfg)
+	} else {
+		err = txmirror.NewServer(stack, backend.TxPool(), cfg.Secret)
+	}
+	if err != nil {
+		Fatalf("Failed to register the transaction pool mirror: %v", err)
+	}
+}
+
+// RegisterTxStreamService serves the transactions applied to the blocks the
+// node assembles to authenticated subscribers.
+func RegisterTxStreamService(stack *node.Node, backend *eth.Ethereum, cfg txstream.Config) {
+	if err := txstream.New(stack, backend.BlockChain().Config(), backend.Miner(), cfg.Secret); err != nil {
+		Fatalf("Failed to register the transaction stream: %v", err)
+	}
+}
+
 // RegisterGraphQLService is a utility function to construct a new service and register it against a node.
 func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, cfg node.Config) {
 	if err := graphql.New(stack, backend, cfg.GraphQLCors, cfg.GraphQLVirtualHosts); err != nil {
@@ -1761,6 +2218,13 @@ func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, cfg node.C
 	}
 }
 
+// RegisterLightProofService mounts the light client proof endpoints on the HTTP
+// server of the node.
+func RegisterLightProofService(stack *node.Node, backend *eth.Ethereum, cfg node.Config) {
+	prover := ethapi.NewPublicBlockChainAPI(backend.APIBackend)
+	lightproof.New(stack, backend.BlockChain(), backend.ChainDb(), backend.Engine(), prover, cfg.HTTPCors, cfg.HTTPVirtualHosts)
+}
+
 func SetupMetrics(ctx *cli.Context) {
 	if metrics.Enabled {
 		log.Info("Enabling metrics collection")
@@ -1917,6 +2381,8 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 		TrieTimeLimit:       ethconfig.Defaults.TrieTimeout,
 		SnapshotLimit:       ethconfig.Defaults.SnapshotCache,
 		Preimages:           ctx.GlobalBool(CachePreimagesFlag.Name),
+		PreimageKind:        ctx.GlobalString(CachePreimagesKindFlag.Name),
+		PreimageLimit:       ctx.GlobalUint64(CachePreimagesLimitFlag.Name),
 	}
 	if cache.TrieDirtyDisabled && !cache.Preimages {
 		cache.Preimages = true
@@ -0,0 +1,148 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/internal/era"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// HistoryRootsFile is the name of the file listing the accumulator roots of the
+// archives of an exported history, one "<root> <filename>" line per archive.
+const HistoryRootsFile = "roots.txt"
+
+// ExportHistory exports the canonical blocks from first to last inclusive as
+// era1-style archives of up to era.MaxSize blocks into the given directory,
+// along with the list of their accumulator roots.
+func ExportHistory(blockchain *core.BlockChain, dir string, network string, first uint64, last uint64) error {
+	log.Info("Exporting history", "dir", dir, "first", first, "last", last)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var roots []string
+	for start := first; start <= last; start += era.MaxSize {
+		end := start + era.MaxSize - 1
+		if end > last {
+			end = last
+		}
+		name, root, err := exportArchive(blockchain, dir, network, start, end)
+		if err != nil {
+			return fmt.Errorf("export failed on #%d-#%d: %v", start, end, err)
+		}
+		roots = append(roots, fmt.Sprintf("%#x %s", root, name))
+		log.Info("Exported history archive", "file", name, "first", start, "last", end, "root", root)
+	}
+	return os.WriteFile(filepath.Join(dir, HistoryRootsFile), []byte(strings.Join(roots, "\n")+"\n"), 0644)
+}
+
+// exportArchive writes the archive of the blocks from start to end inclusive,
+// returning its file name and accumulator root.
+func exportArchive(blockchain *core.BlockChain, dir string, network string, start, end uint64) (string, common.Hash, error) {
+	tmp, err := os.CreateTemp(dir, "era-*.tmp")
+	if err != nil {
+		return "", common.Hash{}, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	var (
+		buf     = bufio.NewWriter(tmp)
+		builder = era.NewBuilder(buf)
+	)
+	for number := start; number <= end; number++ {
+		block := blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return "", common.Hash{}, fmt.Errorf("block #%d not found", number)
+		}
+		receipts := blockchain.GetReceiptsByHash(block.Hash())
+		if receipts == nil && len(block.Transactions()) > 0 {
+			return "", common.Hash{}, fmt.Errorf("receipts of block #%d not found", number)
+		}
+		td := blockchain.GetTd(block.Hash(), number)
+		if td == nil {
+			return "", common.Hash{}, fmt.Errorf("total difficulty of block #%d not found", number)
+		}
+		if err := builder.Add(block, receipts, td); err != nil {
+			return "", common.Hash{}, err
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		return "", common.Hash{}, err
+	}
+	if err := buf.Flush(); err != nil {
+		return "", common.Hash{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", common.Hash{}, err
+	}
+	name := era.Filename(network, int(start/era.MaxSize), root)
+	return name, root, os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
+
+// VerifyHistory verifies the archives of an exported history against the list
+// of their accumulator roots, which must be obtained from a trusted source, and
+// checks that they form a continuous chain.
+func VerifyHistory(dir string) error {
+	roots, err := os.ReadFile(filepath.Join(dir, HistoryRootsFile))
+	if err != nil {
+		return err
+	}
+	var prev *era.Summary
+	for i, line := range strings.Split(strings.TrimSpace(string(roots)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return fmt.Errorf("invalid line %d of %s: %q", i+1, HistoryRootsFile, line)
+		}
+		var root common.Hash
+		if err := root.UnmarshalText([]byte(fields[0])); err != nil {
+			return fmt.Errorf("invalid root on line %d of %s: %v", i+1, HistoryRootsFile, err)
+		}
+		summary, err := verifyArchive(filepath.Join(dir, fields[1]))
+		if err != nil {
+			return fmt.Errorf("archive %s: %v", fields[1], err)
+		}
+		if summary.Root != root {
+			return fmt.Errorf("archive %s: accumulator root %x, want %x", fields[1], summary.Root, root)
+		}
+		if prev != nil && (summary.Start != prev.Start+prev.Count || summary.ParentHash != prev.LastHash) {
+			return fmt.Errorf("archive %s: block #%d [%x] doesn't follow block #%d [%x]", fields[1], summary.Start, summary.ParentHash, prev.Start+prev.Count-1, prev.LastHash)
+		}
+		log.Info("Verified history archive", "file", fields[1], "first", summary.Start, "last", summary.Start+summary.Count-1)
+		prev = summary
+	}
+	return nil
+}
+
+func verifyArchive(path string) (*era.Summary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return era.Verify(bufio.NewReader(f))
+}
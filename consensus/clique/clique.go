@@ -331,6 +331,18 @@ func (c *Clique) verifyCascadingFields(chain consensus.ChainHeaderReader, header
 	if parent.Time+c.config.Period > header.Time {
 		return errInvalidTimestamp
 	}
+	if err := misc.VerifyBlockTime(chain.Config(), parent, header); err != nil {
+		return err
+	}
+	if err := misc.VerifyExtra(chain.Config(), header); err != nil {
+		return err
+	}
+	if err := misc.VerifyProposer(chain.Config(), header); err != nil {
+		return err
+	}
+	if err := misc.VerifyParamGuards(chain.Config(), parent, header); err != nil {
+		return err
+	}
 	// Verify that the gasUsed is <= gasLimit
 	if header.GasUsed > header.GasLimit {
 		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
@@ -340,7 +352,7 @@ func (c *Clique) verifyCascadingFields(chain consensus.ChainHeaderReader, header
 		if header.BaseFee != nil {
 			return fmt.Errorf("invalid baseFee before fork: have %d, want <nil>", header.BaseFee)
 		}
-		if err := misc.VerifyGaslimit(parent.GasLimit, header.GasLimit); err != nil {
+		if err := misc.VerifyScheduledGaslimit(chain.Config(), parent.GasLimit, header); err != nil {
 			return err
 		}
 	} else if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
@@ -554,10 +566,7 @@ func (c *Clique) Prepare(chain consensus.ChainHeaderReader, header *types.Header
 	if parent == nil {
 		return consensus.ErrUnknownAncestor
 	}
-	header.Time = parent.Time + c.config.Period
-	if header.Time < uint64(time.Now().Unix()) {
-		header.Time = uint64(time.Now().Unix())
-	}
+	header.Time = misc.CalcBlockTime(chain.Config(), parent, c.config.Period, uint64(time.Now().Unix()))
 	return nil
 }
 
@@ -275,6 +275,18 @@ func (ethash *Ethash) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 	if header.Time <= parent.Time {
 		return errOlderBlockTime
 	}
+	if err := misc.VerifyBlockTime(chain.Config(), parent, header); err != nil {
+		return err
+	}
+	if err := misc.VerifyExtra(chain.Config(), header); err != nil {
+		return err
+	}
+	if err := misc.VerifyProposer(chain.Config(), header); err != nil {
+		return err
+	}
+	if err := misc.VerifyParamGuards(chain.Config(), parent, header); err != nil {
+		return err
+	}
 	// Verify the block's difficulty based on its timestamp and parent's difficulty
 	expected := ethash.CalcDifficulty(chain, header.Time, parent)
 
@@ -296,7 +308,7 @@ func (ethash *Ethash) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 		if header.BaseFee != nil {
 			return fmt.Errorf("invalid baseFee before fork: have %d, expected 'nil'", header.BaseFee)
 		}
-		if err := misc.VerifyGaslimit(parent.GasLimit, header.GasLimit); err != nil {
+		if err := misc.VerifyScheduledGaslimit(chain.Config(), parent.GasLimit, header); err != nil {
 			return err
 		}
 	} else if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
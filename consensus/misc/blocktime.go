@@ -0,0 +1,68 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// ErrNonMonotonicTimestamp is returned if a block's timestamp is not strictly
+// newer than its parent's while the block time rules are active.
+var ErrNonMonotonicTimestamp = errors.New("timestamp not newer than parent")
+
+// VerifyBlockTime verifies that the header timestamp respects the configured
+// minimum block time in relation to the parent timestamp.
+func VerifyBlockTime(config *params.ChainConfig, parent, header *types.Header) error {
+	if !config.Scroll.IsBlockTime(header.Number) {
+		return nil
+	}
+	if header.Time <= parent.Time {
+		return fmt.Errorf("%w: have %d, parent %d", ErrNonMonotonicTimestamp, header.Time, parent.Time)
+	}
+	if min := config.Scroll.MinBlockTime(header.Number); header.Time-parent.Time < min {
+		return fmt.Errorf("invalid block time: have %d, want >= %d", header.Time-parent.Time, min)
+	}
+	return nil
+}
+
+// CalcBlockTime returns the timestamp a block producer should use for the
+// child of parent, given the engine's own minimum spacing and the current time.
+// The configured target block time takes precedence over the engine period
+// when it is longer, and the result never violates the minimum block time.
+func CalcBlockTime(config *params.ChainConfig, parent *types.Header, period uint64, now uint64) uint64 {
+	number := new(big.Int).Add(parent.Number, common.Big1)
+	if target := config.Scroll.TargetBlockTime(number); target > period {
+		period = target
+	}
+	if min := config.Scroll.MinBlockTime(number); min > period {
+		period = min
+	}
+	if config.Scroll.IsBlockTime(number) && period == 0 {
+		period = 1
+	}
+	time := parent.Time + period
+	if time < now {
+		time = now
+	}
+	return time
+}
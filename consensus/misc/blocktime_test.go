@@ -0,0 +1,101 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func blockTimeConfig(min, target uint64) *params.ChainConfig {
+	config := copyConfig(params.TestChainConfig)
+	config.Scroll.BlockTime = &params.BlockTimeConfig{
+		Block:           big.NewInt(10),
+		MinBlockTime:    min,
+		TargetBlockTime: target,
+	}
+	return config
+}
+
+// TestVerifyBlockTime tests the timestamp checks both across the activation
+// block and after it.
+func TestVerifyBlockTime(t *testing.T) {
+	for i, tc := range []struct {
+		min   uint64
+		pNum  int64
+		pTime uint64
+		time  uint64
+		err   bool
+	}{
+		// Before activation anything goes
+		{0, 8, 100, 100, false},
+		{2, 8, 100, 99, false},
+		// Strict monotonicity without a minimum delta
+		{0, 9, 100, 101, false},
+		{0, 9, 100, 100, true},
+		{0, 9, 100, 99, true},
+		// Minimum delta
+		{2, 9, 100, 102, false},
+		{2, 9, 100, 103, false},
+		{2, 9, 100, 101, true},
+		{2, 9, 100, 100, true},
+		{2, 20, 100, 101, true},
+	} {
+		parent := &types.Header{Number: big.NewInt(tc.pNum), Time: tc.pTime}
+		header := &types.Header{Number: big.NewInt(tc.pNum + 1), Time: tc.time}
+		err := VerifyBlockTime(blockTimeConfig(tc.min, 0), parent, header)
+		if tc.err && err == nil {
+			t.Errorf("test %d: expected error", i)
+		} else if !tc.err && err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		}
+	}
+	parent := &types.Header{Number: big.NewInt(10), Time: 100}
+	header := &types.Header{Number: big.NewInt(11), Time: 100}
+	if err := VerifyBlockTime(blockTimeConfig(0, 0), parent, header); !errors.Is(err, ErrNonMonotonicTimestamp) {
+		t.Errorf("duplicate timestamp: have %v, want %v", err, ErrNonMonotonicTimestamp)
+	}
+}
+
+// TestCalcBlockTime tests that block producers pick timestamps satisfying the
+// configured minimum and aiming for the configured target.
+func TestCalcBlockTime(t *testing.T) {
+	for i, tc := range []struct {
+		min, target uint64
+		pNum        int64
+		period      uint64
+		now         uint64
+		want        uint64
+	}{
+		{0, 0, 5, 0, 100, 100},  // Not active, engine behaviour unchanged
+		{3, 5, 5, 0, 100, 100},  // Not active, engine behaviour unchanged
+		{0, 0, 10, 0, 100, 101}, // Active, no duplicate timestamps
+		{0, 5, 10, 0, 100, 105}, // Target above period
+		{0, 5, 10, 8, 100, 108}, // Period above target
+		{3, 0, 10, 0, 100, 103}, // Minimum enforced
+		{3, 5, 10, 0, 200, 200}, // Wall clock ahead
+	} {
+		parent := &types.Header{Number: big.NewInt(tc.pNum), Time: 100}
+		if have := CalcBlockTime(blockTimeConfig(tc.min, tc.target), parent, tc.period, tc.now); have != tc.want {
+			t.Errorf("test %d: have %d, want %d", i, have, tc.want)
+		}
+	}
+}
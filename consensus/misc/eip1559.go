@@ -35,7 +35,7 @@ func VerifyEip1559Header(config *params.ChainConfig, parent, header *types.Heade
 	if !config.IsLondon(parent.Number) {
 		parentGasLimit = parent.GasLimit * params.ElasticityMultiplier
 	}
-	if err := VerifyGaslimit(parentGasLimit, header.GasLimit); err != nil {
+	if err := VerifyScheduledGaslimit(config, parentGasLimit, header); err != nil {
 		return err
 	}
 	// Verify the header is not malformed
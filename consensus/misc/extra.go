@@ -0,0 +1,56 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// PrepareExtra replaces the extra-data vanity of a header to be produced with
+// structured extra-data committing to its transaction shuffle seed, once the
+// structured layout is active. Its other fields are then set by the rules
+// owning them.
+func PrepareExtra(config *params.ChainConfig, header *types.Header) {
+	if config.Scroll.IsExtraSchema(header.Number) {
+		extra := types.NewL2Extra()
+		if config.Scroll.IsTxShuffle(header.Number) {
+			extra.ShuffleSeed = types.ExtraShuffleSeed(types.TxShuffleSeed(header.ParentHash))
+		}
+		header.Extra = extra.Encode()
+	}
+}
+
+// VerifyExtra verifies that the extra-data of a header leads with a valid
+// structured layout, once active. The consensus engine bounds its total size,
+// and the block validator checks the shuffle seed of blocks shuffling their
+// transactions.
+func VerifyExtra(config *params.ChainConfig, header *types.Header) error {
+	if !config.Scroll.IsExtraSchema(header.Number) {
+		return nil
+	}
+	extra, err := types.DecodeL2Extra(header.Extra)
+	if err != nil {
+		return err
+	}
+	if !config.Scroll.IsTxShuffle(header.Number) && extra.ShuffleSeed != ([types.ExtraSeedLength]byte{}) {
+		return fmt.Errorf("%w: shuffle seed without transaction shuffle", types.ErrInvalidExtra)
+	}
+	return nil
+}
@@ -19,7 +19,9 @@ package misc
 import (
 	"errors"
 	"fmt"
+	"math/big"
 
+	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/params"
 )
 
@@ -40,3 +42,52 @@ func VerifyGaslimit(parentGasLimit, headerGasLimit uint64) error {
 	}
 	return nil
 }
+
+// VerifyScheduledGaslimit verifies the header gas limit like VerifyGaslimit,
+// unless a gas limit schedule with a maximum delta is active, in which case the
+// gas limit may change by up to that delta instead.
+func VerifyScheduledGaslimit(config *params.ChainConfig, parentGasLimit uint64, header *types.Header) error {
+	schedule := config.Scroll.ActiveGasLimitSchedule(header.Number)
+	if schedule == nil || schedule.MaxDelta == 0 {
+		return VerifyGaslimit(parentGasLimit, header.GasLimit)
+	}
+	diff := parentGasLimit - header.GasLimit
+	if header.GasLimit > parentGasLimit {
+		diff = header.GasLimit - parentGasLimit
+	}
+	if diff > schedule.MaxDelta {
+		return fmt.Errorf("invalid gas limit: have %d, want %d +-= %d", header.GasLimit, parentGasLimit, schedule.MaxDelta)
+	}
+	if header.GasLimit < params.MinGasLimit {
+		return errors.New("invalid gas limit below 5000")
+	}
+	return nil
+}
+
+// CalcGasLimit computes the gas limit of the block with the given number on top
+// of a parent with the given gas limit. It moves towards the desired limit by at
+// most maxDelta per block (0 = the default bound), which is capped by the bound
+// verification accepts. While a gas limit schedule is active, the scheduled gas
+// limit replaces the desired one, so that all block producers ramp alike.
+func CalcGasLimit(config *params.ChainConfig, number *big.Int, parentGasLimit, desiredLimit, maxDelta uint64) uint64 {
+	bound := parentGasLimit/params.GasLimitBoundDivisor - 1
+	if schedule := config.Scroll.ActiveGasLimitSchedule(number); schedule != nil {
+		desiredLimit = schedule.GasLimit
+		if schedule.MaxDelta != 0 {
+			bound = schedule.MaxDelta
+		}
+	}
+	if maxDelta == 0 || maxDelta > bound {
+		maxDelta = bound
+	}
+	if desiredLimit < params.MinGasLimit {
+		desiredLimit = params.MinGasLimit
+	}
+	if parentGasLimit < desiredLimit && desiredLimit-parentGasLimit > maxDelta {
+		return parentGasLimit + maxDelta
+	}
+	if parentGasLimit > desiredLimit && parentGasLimit-desiredLimit > maxDelta {
+		return parentGasLimit - maxDelta
+	}
+	return desiredLimit
+}
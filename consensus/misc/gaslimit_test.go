@@ -0,0 +1,95 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func gasLimitScheduleConfig(schedule ...params.GasLimitScheduleConfig) *params.ChainConfig {
+	config := copyConfig(params.TestChainConfig)
+	config.Scroll.GasLimitSchedule = schedule
+	return config
+}
+
+// TestVerifyScheduledGaslimit tests that the scheduled maximum delta replaces
+// the default gas limit bound once active.
+func TestVerifyScheduledGaslimit(t *testing.T) {
+	config := gasLimitScheduleConfig(
+		params.GasLimitScheduleConfig{Block: big.NewInt(10), GasLimit: 20_000_000, MaxDelta: 1_000_000},
+		params.GasLimitScheduleConfig{Block: big.NewInt(20), GasLimit: 30_000_000},
+	)
+	for i, tc := range []struct {
+		num    int64
+		pLimit uint64
+		limit  uint64
+		err    bool
+	}{
+		// Default bound before the schedule
+		{9, 10_240_000, 10_249_999, false},
+		{9, 10_240_000, 10_250_000, true},
+		// Scheduled delta, in both directions
+		{10, 10_240_000, 11_240_000, false},
+		{10, 10_240_000, 11_240_001, true},
+		{15, 10_240_000, 9_240_000, false},
+		{15, 10_240_000, 9_239_999, true},
+		// Default bound again without a scheduled delta
+		{20, 10_240_000, 10_249_999, false},
+		{20, 10_240_000, 11_240_000, true},
+	} {
+		header := &types.Header{Number: big.NewInt(tc.num), GasLimit: tc.limit}
+		err := VerifyScheduledGaslimit(config, tc.pLimit, header)
+		if tc.err && err == nil {
+			t.Errorf("test %d: expected error", i)
+		} else if !tc.err && err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+// TestCalcGasLimit tests that block producers ramp the gas limit towards the
+// scheduled target, overriding their desired limit, within the allowed delta.
+func TestCalcGasLimit(t *testing.T) {
+	config := gasLimitScheduleConfig(
+		params.GasLimitScheduleConfig{Block: big.NewInt(10), GasLimit: 12_000_000, MaxDelta: 1_000_000},
+		params.GasLimitScheduleConfig{Block: big.NewInt(20), GasLimit: 8_000_000},
+	)
+	for i, tc := range []struct {
+		num      int64
+		pLimit   uint64
+		desired  uint64
+		maxDelta uint64
+		want     uint64
+	}{
+		{5, 10_240_000, 20_000_000, 0, 10_249_999},      // Default bound
+		{5, 10_240_000, 20_000_000, 5_000, 10_245_000},  // Local delta below the bound
+		{5, 10_240_000, 20_000_000, 50_000, 10_249_999}, // Local delta capped by the bound
+		{5, 10_240_000, 10_241_000, 0, 10_241_000},      // Desired limit reached
+		{10, 10_240_000, 8_000_000, 0, 11_240_000},      // Scheduled target and delta
+		{10, 10_240_000, 8_000_000, 500_000, 10_740_000},
+		{10, 11_500_000, 8_000_000, 0, 12_000_000},  // Scheduled target reached
+		{20, 10_240_000, 20_000_000, 0, 10_230_001}, // Scheduled target with default bound
+	} {
+		if have := CalcGasLimit(config, big.NewInt(tc.num), tc.pLimit, tc.desired, tc.maxDelta); have != tc.want {
+			t.Errorf("test %d: have %d, want %d", i, have, tc.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// ErrParamJump is returned if a block moves the base fee, gas limit or timestamp
+// further from its parent than the configured guards allow.
+var ErrParamJump = errors.New("block parameter jump beyond guard")
+
+// VerifyParamGuards verifies that the base fee and gas limit of the header stay
+// within the configured bounds relative to the parent, unless the header is
+// exempted by the override list.
+func VerifyParamGuards(config *params.ChainConfig, parent, header *types.Header) error {
+	if !config.Scroll.IsParamGuard(header.Number) {
+		return nil
+	}
+	guards := config.Scroll.ParamGuards
+	if max := guards.MaxGasLimitChange; max > 0 && exceedsChange(new(big.Int).SetUint64(parent.GasLimit), new(big.Int).SetUint64(header.GasLimit), max) {
+		return fmt.Errorf("%w: gas limit %d, parent %d, max change %d%%", ErrParamJump, header.GasLimit, parent.GasLimit, max)
+	}
+	// A base fee appearing or leaving is up to the EIP-1559 rules, and a zero
+	// base fee can't be measured against
+	if max := guards.MaxBaseFeeChange; max > 0 && parent.BaseFee != nil && parent.BaseFee.Sign() > 0 && header.BaseFee != nil && exceedsChange(parent.BaseFee, header.BaseFee, max) {
+		return fmt.Errorf("%w: base fee %v, parent %v, max change %d%%", ErrParamJump, header.BaseFee, parent.BaseFee, max)
+	}
+	return nil
+}
+
+// VerifyTimeJump verifies that the timestamp of the header doesn't move further
+// from its parent than the configured guard allows. Unlike the other guards it
+// is not a consensus rule, but checked by the sequencer before assembling.
+func VerifyTimeJump(config *params.ChainConfig, parent, header *types.Header) error {
+	if max := config.Scroll.MaxTimeJump(header.Number); max > 0 && header.Time > parent.Time+max {
+		return fmt.Errorf("%w: timestamp %d seconds after parent, max %d", ErrParamJump, header.Time-parent.Time, max)
+	}
+	return nil
+}
+
+// exceedsChange returns whether value differs from parent by more than percent
+// of parent.
+func exceedsChange(parent, value *big.Int, percent uint64) bool {
+	diff := new(big.Int).Sub(value, parent)
+	diff.Abs(diff).Mul(diff, big.NewInt(100))
+	return diff.Cmp(new(big.Int).Mul(parent, new(big.Int).SetUint64(percent))) > 0
+}
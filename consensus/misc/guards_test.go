@@ -0,0 +1,111 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func paramGuardConfig(overrides ...*big.Int) *params.ChainConfig {
+	config := copyConfig(params.TestChainConfig)
+	config.Scroll.ParamGuards = &params.ParamGuardConfig{
+		Block:             big.NewInt(10),
+		MaxBaseFeeChange:  50,
+		MaxGasLimitChange: 10,
+		MaxTimeJump:       60,
+		Overrides:         overrides,
+	}
+	return config
+}
+
+// TestVerifyParamGuards tests the parameter swing bounds both across the
+// activation block and after it.
+func TestVerifyParamGuards(t *testing.T) {
+	for i, tc := range []struct {
+		pNum     int64
+		pBaseFee int64
+		baseFee  int64
+		gasLimit uint64
+		time     uint64
+		err      bool
+	}{
+		// Before activation anything goes
+		{8, 1000, 5000, 20_000_000, 1000, false},
+		// Within bounds
+		{9, 1000, 1500, 11_000_000, 160, false},
+		{9, 1000, 500, 9_000_000, 101, false},
+		// Beyond the base fee bounds
+		{9, 1000, 1501, 10_000_000, 101, true},
+		{9, 1000, 499, 10_000_000, 101, true},
+		// Beyond the gas limit bounds
+		{9, 1000, 1000, 11_000_001, 101, true},
+		{9, 1000, 1000, 8_999_999, 101, true},
+		// The timestamp bound is up to the sequencer
+		{9, 1000, 1000, 10_000_000, 1000, false},
+		// A zero base fee can't be measured against
+		{9, 0, 1000, 10_000_000, 101, false},
+	} {
+		parent := &types.Header{Number: big.NewInt(tc.pNum), BaseFee: big.NewInt(tc.pBaseFee), GasLimit: 10_000_000, Time: 100}
+		header := &types.Header{Number: big.NewInt(tc.pNum + 1), BaseFee: big.NewInt(tc.baseFee), GasLimit: tc.gasLimit, Time: tc.time}
+		err := VerifyParamGuards(paramGuardConfig(), parent, header)
+		if tc.err && !errors.Is(err, ErrParamJump) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, ErrParamJump)
+		} else if !tc.err && err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		}
+	}
+	// Overridden blocks are exempt
+	parent := &types.Header{Number: big.NewInt(10), BaseFee: big.NewInt(1000), GasLimit: 10_000_000, Time: 100}
+	header := &types.Header{Number: big.NewInt(11), BaseFee: big.NewInt(5000), GasLimit: 10_000_000, Time: 101}
+	if err := VerifyParamGuards(paramGuardConfig(), parent, header); err == nil {
+		t.Fatalf("base fee jump accepted")
+	}
+	if err := VerifyParamGuards(paramGuardConfig(big.NewInt(11)), parent, header); err != nil {
+		t.Fatalf("overridden block rejected: %v", err)
+	}
+}
+
+// TestVerifyTimeJump tests the timestamp bound enforced by the sequencer.
+func TestVerifyTimeJump(t *testing.T) {
+	for i, tc := range []struct {
+		pNum int64
+		time uint64
+		err  bool
+	}{
+		// Before activation anything goes
+		{8, 1000, false},
+		// Within and beyond the bound
+		{9, 160, false},
+		{9, 161, true},
+		// Overridden blocks are exempt
+		{10, 1000, false},
+	} {
+		parent := &types.Header{Number: big.NewInt(tc.pNum), Time: 100}
+		header := &types.Header{Number: big.NewInt(tc.pNum + 1), Time: tc.time}
+		err := VerifyTimeJump(paramGuardConfig(big.NewInt(11)), parent, header)
+		if tc.err && !errors.Is(err, ErrParamJump) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, ErrParamJump)
+		} else if !tc.err && err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		}
+	}
+}
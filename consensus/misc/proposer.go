@@ -0,0 +1,84 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// ErrNotInTurn is returned if a block producer is asked to propose a block while
+// another proposer is in turn.
+var ErrNotInTurn = errors.New("not in turn to propose")
+
+// VerifyProposer verifies that the header commits to the proposer in turn
+// according to the proposer rotation schedule.
+func VerifyProposer(config *params.ChainConfig, header *types.Header) error {
+	if !config.Scroll.IsProposerRotation(header.Number) {
+		return nil
+	}
+	index, err := proposerIndex(config, header)
+	if err != nil {
+		return err
+	}
+	if want, _ := config.Scroll.ScheduledProposer(header.Number); index != want {
+		return fmt.Errorf("invalid proposer index: have %d, want %d", index, want)
+	}
+	return nil
+}
+
+// proposerIndex returns the proposer index committed in the extra-data of a
+// header, in the structured layout once active.
+func proposerIndex(config *params.ChainConfig, header *types.Header) (uint64, error) {
+	if !config.Scroll.IsExtraSchema(header.Number) {
+		return types.ProposerIndexFromExtra(header.Extra)
+	}
+	extra, err := types.DecodeL2Extra(header.Extra)
+	if err != nil {
+		return 0, err
+	}
+	return extra.ProposerIndex, nil
+}
+
+// SetProposer commits the header to the proposer in turn in place of the
+// extra-data vanity, or in the structured extra-data once active, failing if
+// that's not the given one. It does nothing if the proposer rotation is not
+// active.
+func SetProposer(config *params.ChainConfig, header *types.Header, proposer common.Address) error {
+	if !config.Scroll.IsProposerRotation(header.Number) {
+		return nil
+	}
+	index, want := config.Scroll.ScheduledProposer(header.Number)
+	if proposer != want {
+		return fmt.Errorf("%w block %d: proposer %x, in turn %x", ErrNotInTurn, header.Number, proposer, want)
+	}
+	if !config.Scroll.IsExtraSchema(header.Number) {
+		header.Extra = types.ProposerIndexExtra(index)
+		return nil
+	}
+	extra, err := types.DecodeL2Extra(header.Extra)
+	if err != nil {
+		extra = types.NewL2Extra()
+	}
+	extra.ProposerIndex = index
+	header.Extra = extra.Encode()
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+func proposerConfig(turn uint64, proposers ...common.Address) *params.ChainConfig {
+	config := copyConfig(params.TestChainConfig)
+	config.Scroll.ProposerRotation = &params.ProposerRotationConfig{
+		Block:     big.NewInt(10),
+		Proposers: proposers,
+		Turn:      turn,
+	}
+	return config
+}
+
+// TestProposerRotation tests that proposers take turns in the configured order
+// and that headers must commit to the proposer in turn.
+func TestProposerRotation(t *testing.T) {
+	var (
+		a, b, c = common.Address{0xa}, common.Address{0xb}, common.Address{0xc}
+		config  = proposerConfig(2, a, b, c)
+	)
+	for number, want := range map[int64]common.Address{10: a, 11: a, 12: b, 13: b, 14: c, 15: c, 16: a} {
+		header := &types.Header{Number: big.NewInt(number)}
+		for _, proposer := range []common.Address{a, b, c} {
+			err := SetProposer(config, header, proposer)
+			if proposer == want && err != nil {
+				t.Errorf("block %d: proposer in turn rejected: %v", number, err)
+			}
+			if proposer != want && !errors.Is(err, ErrNotInTurn) {
+				t.Errorf("block %d: proposer out of turn error mismatch: have %v, want %v", number, err, ErrNotInTurn)
+			}
+		}
+		if err := VerifyProposer(config, header); err != nil {
+			t.Errorf("block %d: failed to verify proposer: %v", number, err)
+		}
+	}
+	// Headers must commit to the proposer in turn
+	header := &types.Header{Number: big.NewInt(12)}
+	if err := VerifyProposer(config, header); !errors.Is(err, types.ErrMissingProposerIndex) {
+		t.Errorf("missing index error mismatch: have %v, want %v", err, types.ErrMissingProposerIndex)
+	}
+	header.Extra = types.ProposerIndexExtra(0)
+	if err := VerifyProposer(config, header); err == nil {
+		t.Error("proposer out of turn accepted")
+	}
+	// Before activation anything goes
+	header = &types.Header{Number: big.NewInt(9)}
+	if err := SetProposer(config, header, common.Address{0xd}); err != nil || header.Extra != nil {
+		t.Errorf("rotation applied before activation: %v, extra %x", err, header.Extra)
+	}
+	if err := VerifyProposer(config, header); err != nil {
+		t.Errorf("rotation verified before activation: %v", err)
+	}
+}
+
+// TestProposerRotationExtraSchema tests that the proposer index is committed in
+// the structured extra-data once active.
+func TestProposerRotationExtraSchema(t *testing.T) {
+	var (
+		a, b   = common.Address{0xa}, common.Address{0xb}
+		config = proposerConfig(1, a, b)
+	)
+	config.Scroll.ExtraSchemaBlock = big.NewInt(12)
+
+	header := &types.Header{Number: big.NewInt(13), Extra: []byte("vanity")}
+	PrepareExtra(config, header)
+	if err := SetProposer(config, header, b); err != nil {
+		t.Fatalf("proposer in turn rejected: %v", err)
+	}
+	if err := VerifyExtra(config, header); err != nil {
+		t.Fatalf("failed to verify extra-data: %v", err)
+	}
+	if err := VerifyProposer(config, header); err != nil {
+		t.Fatalf("failed to verify proposer: %v", err)
+	}
+	extra, err := types.DecodeL2Extra(header.Extra)
+	if err != nil || extra.ProposerIndex != 1 {
+		t.Fatalf("proposer index mismatch: have %v, want 1 (err %v)", extra, err)
+	}
+	// The legacy layout is no longer accepted
+	header.Extra = types.ProposerIndexExtra(1)
+	if err := VerifyExtra(config, header); !errors.Is(err, types.ErrInvalidExtra) {
+		t.Fatalf("error mismatch: have %v, want %v", err, types.ErrInvalidExtra)
+	}
+	// Before activation the vanity is kept
+	header = &types.Header{Number: big.NewInt(11), Extra: []byte("vanity")}
+	PrepareExtra(config, header)
+	if err := VerifyExtra(config, header); err != nil || string(header.Extra) != "vanity" {
+		t.Fatalf("schema applied before activation: %v, extra %x", err, header.Extra)
+	}
+}
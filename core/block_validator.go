@@ -23,6 +23,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/l1origin"
 	"github.com/scroll-tech/go-ethereum/trie"
 )
 
@@ -68,6 +69,33 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
 		return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxHash)
 	}
+	if err := types.VerifySystemTxs(v.config, header.Number, block.Transactions()); err != nil {
+		return err
+	}
+	if v.config.Scroll.IsSystemTx(header.Number) {
+		var origin *l1origin.Origin
+		if parent := v.bc.GetBlock(block.ParentHash(), block.NumberU64()-1); parent != nil {
+			origin = l1origin.FromBlock(parent)
+		}
+		if err := l1origin.VerifyOrigin(header, block.Transactions(), origin); err != nil {
+			return err
+		}
+	}
+	// The timestamp bounds depend on the L1 origin set in the body, so they're
+	// only enforced once the body is known
+	if err := l1origin.VerifyTime(v.config, header, block.Transactions()); err != nil {
+		return err
+	}
+	if v.config.Scroll.IsSystemTx(header.Number) {
+		if err := v.bc.verifyL1FeeRefunds(block); err != nil {
+			return err
+		}
+	}
+	if v.config.Scroll.IsTxShuffle(header.Number) {
+		if err := v.validateTxShuffle(block); err != nil {
+			return err
+		}
+	}
 	if !v.bc.HasBlockAndState(block.ParentHash(), block.NumberU64()-1) {
 		if !v.bc.HasBlock(block.ParentHash(), block.NumberU64()-1) {
 			return consensus.ErrUnknownAncestor
@@ -77,6 +105,37 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	return nil
 }
 
+// validateTxShuffle checks that the block commits to the shuffle seed derived
+// from its parent and that its transactions follow the shuffled fee band order.
+// Structured extra-data only has room for the leading bytes of the seed, so
+// such blocks commit to those.
+func (v *BlockValidator) validateTxShuffle(block *types.Block) error {
+	seed := types.TxShuffleSeed(block.ParentHash())
+	if v.config.Scroll.IsExtraSchema(block.Number()) {
+		extra, err := types.DecodeL2Extra(block.Extra())
+		if err != nil {
+			return err
+		}
+		if want := types.ExtraShuffleSeed(seed); extra.ShuffleSeed != want {
+			return fmt.Errorf("%w: have %x, want %x", types.ErrInvalidShuffleSeed, extra.ShuffleSeed, want)
+		}
+	} else {
+		committed, err := types.TxShuffleSeedFromExtra(block.Extra())
+		if err != nil {
+			return err
+		}
+		if committed != seed {
+			return fmt.Errorf("%w: have %x, want %x", types.ErrInvalidShuffleSeed, committed, seed)
+		}
+	}
+	// System transactions precede the shuffled ones
+	txs := block.Transactions()
+	txs = txs[types.SystemTxCount(txs):]
+
+	signer := types.MakeSigner(v.config, block.Number())
+	return types.VerifyShuffledOrder(signer, txs, block.BaseFee(), seed, v.config.Scroll.TxShuffleFeeBand())
+}
+
 // ValidateState validates the various changes that happen after a state
 // transition, such as amount of used gas, the receipt roots and the state root
 // itself. ValidateState returns a database batch if the validation was a success
@@ -41,6 +41,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/vm"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/internal/failpoint"
 	"github.com/scroll-tech/go-ethereum/internal/syncx"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/metrics"
@@ -53,6 +54,11 @@ var (
 	headBlockGauge     = metrics.NewRegisteredGauge("chain/head/block", nil)
 	headHeaderGauge    = metrics.NewRegisteredGauge("chain/head/header", nil)
 	headFastBlockGauge = metrics.NewRegisteredGauge("chain/head/receipt", nil)
+	headFinalizedGauge = metrics.NewRegisteredGauge("chain/head/finalized", nil)
+
+	stateAccountsGauge  = metrics.NewRegisteredGauge("chain/state/accounts", nil)
+	stateSlotsGauge     = metrics.NewRegisteredGauge("chain/state/slots", nil)
+	stateCodeBytesGauge = metrics.NewRegisteredGauge("chain/state/codebytes", nil)
 
 	accountReadTimer   = metrics.NewRegisteredTimer("chain/account/reads", nil)
 	accountHashTimer   = metrics.NewRegisteredTimer("chain/account/hashes", nil)
@@ -83,6 +89,7 @@ var (
 
 	errInsertionInterrupted = errors.New("insertion is interrupted")
 	errChainStopped         = errors.New("blockchain is stopped")
+	errBelowFinalized       = errors.New("rewind below finalized block")
 )
 
 const (
@@ -133,6 +140,19 @@ type CacheConfig struct {
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
 	Preimages           bool          // Whether to store preimage of trie key to the disk
 	MPTWitness          int           // How to generate witness data for mpt circuit, 0: nothing, 1: natural
+	RecordAccessLists   bool          // Whether to store the block-level access list of imported blocks
+	LogIndex            bool          // Whether to maintain the per-address/topic log index of imported blocks
+	RecordCallTraces    bool          // Whether to store the internal transactions of imported blocks
+	RecordBlockStats    bool          // Whether to store the resource usage of importing blocks
+	RecordContractUsage bool          // Whether to store the per-contract gas and state growth of imported blocks
+	RecordStateSize     bool          // Whether to store the state size after every imported block
+	WithdrawTrie        bool          // Whether to maintain the withdraw trie of L2 to L1 messages
+	CreationIndex       bool          // Whether to maintain the index of contract creations by address
+	AccessEpochLength   uint64        // Blocks per epoch to tag accessed state with its last access epoch (0 = disabled)
+	PreimageKind        string        // Kind of trie keys to store the preimages of (all, accounts or storage)
+	PreimageLimit       uint64        // Maximum number of trie key preimages to store (0 = unlimited)
+
+	AllowFinalizedRewind bool // Whether to allow rewinding below the finalized block (disaster recovery only)
 
 	SnapshotWait bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
 }
@@ -185,6 +205,8 @@ type BlockChain struct {
 	chainHeadFeed event.Feed
 	logsFeed      event.Feed
 	blockProcFeed event.Feed
+	equivFeed     event.Feed
+	finalFeed     event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
 
@@ -194,8 +216,10 @@ type BlockChain struct {
 
 	currentBlock     atomic.Value // Current head of the block chain
 	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
+	currentFinalized atomic.Value // Latest block finalized on L1, the chain never reorgs below it (may be nil)
 
 	stateCache    state.Database // State database to reuse between imports (contains state cache)
+	zktrieCache   state.Database // State database of the blocks past the zktrie cutover (nil = no cutover)
 	bodyCache     *lru.Cache     // Cache for the most recent block bodies
 	bodyRLPCache  *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
 	receiptsCache *lru.Cache     // Cache for the most recent receipts per block
@@ -203,6 +227,11 @@ type BlockChain struct {
 	txLookupCache *lru.Cache     // Cache for the most recent transaction lookup data.
 	futureBlocks  *lru.Cache     // future blocks are blocks added for later processing
 
+	logIndexLock sync.Mutex // Lock protecting the log index coverage
+	logIndexNext uint64     // First block number not yet covered by the log index
+
+	withdrawTrieLock sync.RWMutex // Lock protecting the withdraw trie against head updates
+
 	wg            sync.WaitGroup //
 	quit          chan struct{}  // shutdown signal, closed in Stop.
 	running       int32          // 0 if chain is running, 1 when stopped
@@ -231,7 +260,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	txLookupCache, _ := lru.New(txLookupCacheLimit)
 	futureBlocks, _ := lru.New(maxFutureBlocks)
 	// override snapshot setting
-	if chainConfig.Scroll.ZktrieEnabled() && cacheConfig.SnapshotLimit > 0 {
+	if (chainConfig.Scroll.ZktrieEnabled() || chainConfig.Scroll.ZktrieCutoverBlock != nil) && cacheConfig.SnapshotLimit > 0 {
 		log.Warn("Snapshot has been disabled by zktrie")
 		cacheConfig.SnapshotLimit = 0
 	}
@@ -250,6 +279,9 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			Journal:   cacheConfig.TrieCleanJournal,
 			Preimages: cacheConfig.Preimages,
 			Zktrie:    chainConfig.Scroll.ZktrieEnabled(),
+
+			PreimageKind:  cacheConfig.PreimageKind,
+			PreimageLimit: cacheConfig.PreimageLimit,
 		}),
 		quit:           make(chan struct{}),
 		chainmu:        syncx.NewClosableMutex(),
@@ -263,6 +295,12 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		engine:         engine,
 		vmConfig:       vmConfig,
 	}
+	if chainConfig.Scroll.IsZktrieCutover(chainConfig.Scroll.ZktrieCutoverBlock) {
+		bc.zktrieCache = state.NewDatabaseWithConfig(db, &trie.Config{
+			Cache:  cacheConfig.TrieCleanLimit,
+			Zktrie: true,
+		})
+	}
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
 	bc.processor = NewStateProcessor(chainConfig, bc, engine)
@@ -280,6 +318,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	var nilBlock *types.Block
 	bc.currentBlock.Store(nilBlock)
 	bc.currentFastBlock.Store(nilBlock)
+	bc.currentFinalized.Store(nilBlock)
 
 	// Initialize the chain with ancient data if it isn't empty.
 	var txIndexBlock uint64
@@ -296,10 +335,13 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	if err := bc.loadLastState(); err != nil {
 		return nil, err
 	}
+	// Consume the clean shutdown marker, a crash from here on must not find it
+	cleanShutdown := rawdb.ReadCleanShutdownMarker(bc.db) == bc.CurrentBlock().Hash()
+	rawdb.DeleteCleanShutdownMarker(bc.db)
 
 	// Make sure the state associated with the block is available
 	head := bc.CurrentBlock()
-	if _, err := state.New(head.Root(), bc.stateCache, bc.snaps); err != nil {
+	if _, err := state.New(head.Root(), bc.stateCacheAt(head.Number()), bc.snaps); err != nil {
 		// Head state is missing, before the state recovery, find out the
 		// disk layer point of snapshot(if it's enabled). Make sure the
 		// rewound point is lower than disk layer.
@@ -352,7 +394,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		}
 		if needRewind {
 			log.Error("Truncating ancient chain", "from", bc.CurrentHeader().Number.Uint64(), "to", low)
-			if err := bc.SetHead(low); err != nil {
+			if _, err := bc.setHeadBeyondRoot(low, common.Hash{}, false); err != nil {
 				return nil, err
 			}
 		}
@@ -370,7 +412,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			// make sure the headerByNumber (if present) is in our current canonical chain
 			if headerByNumber != nil && headerByNumber.Hash() == header.Hash() {
 				log.Error("Found bad hash, rewinding chain", "number", header.Number, "hash", header.ParentHash)
-				if err := bc.SetHead(header.Number.Uint64() - 1); err != nil {
+				if _, err := bc.setHeadBeyondRoot(header.Number.Uint64()-1, common.Hash{}, false); err != nil {
 					return nil, err
 				}
 				log.Error("Chain rewind was successful, resuming normal operation")
@@ -406,6 +448,21 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		go bc.maintainTxIndex(txIndexBlock)
 	}
 
+	// Repair the indices of the head block unless they were fully persisted
+	// during a clean shutdown.
+	if cleanShutdown {
+		log.Info("Clean shutdown detected, skipping recovery", "number", bc.CurrentBlock().Number(), "hash", bc.CurrentBlock().Hash())
+	} else {
+		bc.recoverIndices()
+	}
+	// Start the log indexer if requested.
+	if bc.cacheConfig.LogIndex {
+		bc.initLogIndex()
+	}
+	if bc.cacheConfig.WithdrawTrie {
+		bc.initWithdrawTrie()
+	}
+
 	// If periodic cache journal is required, spin it up.
 	if bc.cacheConfig.TrieCleanRejournal > 0 {
 		if bc.cacheConfig.TrieCleanRejournal < time.Minute {
@@ -476,6 +533,16 @@ func (bc *BlockChain) loadLastState() error {
 			headFastBlockGauge.Update(int64(block.NumberU64()))
 		}
 	}
+	// Restore the last finalized block, unless it was rewound
+	var finalized *types.Block
+	if hash := rawdb.ReadFinalizedBlockHash(bc.db); hash != (common.Hash{}) {
+		if block := bc.GetBlockByHash(hash); block != nil && block.NumberU64() <= currentBlock.NumberU64() && bc.GetCanonicalHash(block.NumberU64()) == hash {
+			finalized = block
+			headFinalizedGauge.Update(int64(block.NumberU64()))
+		}
+	}
+	bc.currentFinalized.Store(finalized)
+
 	// Issue a status log for the user
 	currentFastBlock := bc.CurrentFastBlock()
 
@@ -489,13 +556,22 @@ func (bc *BlockChain) loadLastState() error {
 	if pivot := rawdb.ReadLastPivotNumber(bc.db); pivot != nil {
 		log.Info("Loaded last fast-sync pivot marker", "number", *pivot)
 	}
+	if finalized != nil {
+		log.Info("Loaded most recent finalized block", "number", finalized.Number(), "hash", finalized.Hash())
+	}
 	return nil
 }
 
 // SetHead rewinds the local chain to a new head. Depending on whether the node
 // was fast synced or full synced and in which state, the method will try to
 // delete minimal data from disk whilst retaining chain consistency.
+//
+// The chain can't be rewound below the finalized block, unless explicitly
+// allowed for disaster recovery.
 func (bc *BlockChain) SetHead(head uint64) error {
+	if err := bc.checkRewind(head); err != nil {
+		return err
+	}
 	_, err := bc.setHeadBeyondRoot(head, common.Hash{}, false)
 	return err
 }
@@ -542,7 +618,7 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, root common.Hash, repair bo
 					if root != (common.Hash{}) && !beyondRoot && newHeadBlock.Root() == root {
 						beyondRoot, rootNumber = true, newHeadBlock.NumberU64()
 					}
-					if _, err := state.New(newHeadBlock.Root(), bc.stateCache, bc.snaps); err != nil {
+					if _, err := state.New(newHeadBlock.Root(), bc.stateCacheAt(newHeadBlock.Number()), bc.snaps); err != nil {
 						log.Trace("Block state missing, rewinding further", "number", newHeadBlock.NumberU64(), "hash", newHeadBlock.Hash())
 						if pivot == nil || newHeadBlock.NumberU64() > *pivot {
 							parent := bc.GetBlock(newHeadBlock.ParentHash(), newHeadBlock.NumberU64()-1)
@@ -645,6 +721,83 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, root common.Hash, repair bo
 	return rootNumber, bc.loadLastState()
 }
 
+// SetL2Head makes a known block with available state the head of the chain,
+// rewinding the chain to it or reorging onto it if it's on a side chain. Unlike
+// SetHead, the blocks leaving the canonical chain are retained. The chain can't
+// be reorged below the finalized block, unless explicitly allowed.
+func (bc *BlockChain) SetL2Head(hash common.Hash) error {
+	if !bc.chainmu.TryLock() {
+		return errChainStopped
+	}
+	defer bc.chainmu.Unlock()
+
+	block := bc.GetBlockByHash(hash)
+	if block == nil {
+		return fmt.Errorf("unknown block [%x..]", hash.Bytes()[:4])
+	}
+	if !bc.HasState(block.Root()) {
+		return fmt.Errorf("missing state of block #%d [%x..]", block.NumberU64(), hash.Bytes()[:4])
+	}
+	if current := bc.CurrentBlock(); current.Hash() == hash {
+		return nil
+	}
+	if err := bc.writeKnownBlock(block); err != nil {
+		return err
+	}
+	// Rewinding to an ancestor leaves the other heads above, pull them down too
+	if bc.CurrentHeader().Number.Uint64() > block.NumberU64() {
+		rawdb.WriteHeadHeaderHash(bc.db, hash)
+		bc.hc.SetCurrentHeader(block.Header())
+	}
+	if bc.CurrentFastBlock().NumberU64() > block.NumberU64() {
+		rawdb.WriteHeadFastBlockHash(bc.db, hash)
+		bc.currentFastBlock.Store(block)
+		headFastBlockGauge.Update(int64(block.NumberU64()))
+	}
+	bc.chainHeadFeed.Send(ChainHeadEvent{Block: block})
+	return nil
+}
+
+// checkRewind returns an error if rewinding the chain to the given head would
+// revert the finalized block and that isn't explicitly allowed. If it is, the
+// finalized block is dropped until the next one gets finalized.
+func (bc *BlockChain) checkRewind(head uint64) error {
+	finalized := bc.CurrentFinalizedBlock()
+	if finalized == nil || head >= finalized.NumberU64() {
+		return nil
+	}
+	if !bc.cacheConfig.AllowFinalizedRewind {
+		return fmt.Errorf("%w: target %d, finalized %d", errBelowFinalized, head, finalized.NumberU64())
+	}
+	log.Warn("Rewinding below finalized block", "target", head, "finalized", finalized.Number(), "hash", finalized.Hash())
+	rawdb.WriteFinalizedBlockHash(bc.db, common.Hash{})
+	bc.currentFinalized.Store((*types.Block)(nil))
+	headFinalizedGauge.Update(0)
+	return nil
+}
+
+// SetFinalized marks a canonical block as finalized, typically once the batch
+// containing it was finalized on L1. The chain refuses to reorg below the
+// finalized block from then on. The finalized block never moves backwards.
+func (bc *BlockChain) SetFinalized(block *types.Block) error {
+	if !bc.chainmu.TryLock() {
+		return errChainStopped
+	}
+	defer bc.chainmu.Unlock()
+
+	if bc.GetCanonicalHash(block.NumberU64()) != block.Hash() {
+		return fmt.Errorf("non canonical block #%d [%x..]", block.NumberU64(), block.Hash().Bytes()[:4])
+	}
+	if current := bc.CurrentFinalizedBlock(); current != nil && current.NumberU64() >= block.NumberU64() {
+		return nil
+	}
+	rawdb.WriteFinalizedBlockHash(bc.db, block.Hash())
+	bc.currentFinalized.Store(block)
+	headFinalizedGauge.Update(int64(block.NumberU64()))
+	bc.finalFeed.Send(FinalizedEvent{Block: block})
+	return nil
+}
+
 // FastSyncCommitHead sets the current head block to the one defined by the hash
 // irrelevant what the chain contents were prior.
 func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
@@ -653,7 +806,7 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	if block == nil {
 		return fmt.Errorf("non existent block [%x..]", hash[:4])
 	}
-	if _, err := trie.NewSecure(block.Root(), bc.stateCache.TrieDB()); err != nil {
+	if _, err := bc.stateCacheAt(block.Number()).OpenTrie(block.Root()); err != nil {
 		return err
 	}
 
@@ -683,7 +836,7 @@ func (bc *BlockChain) Reset() error {
 // specified genesis state.
 func (bc *BlockChain) ResetWithGenesisBlock(genesis *types.Block) error {
 	// Dump the entire block chain and purge the caches
-	if err := bc.SetHead(0); err != nil {
+	if _, err := bc.setHeadBeyondRoot(0, common.Hash{}, false); err != nil {
 		return err
 	}
 	if !bc.chainmu.TryLock() {
@@ -761,6 +914,13 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	rawdb.WriteTxLookupEntriesByBlock(batch, block)
 	rawdb.WriteHeadBlockHash(batch, block.Hash())
 
+	// Extend the withdraw trie along with the head, blocking proofs meanwhile
+	if bc.cacheConfig.WithdrawTrie {
+		bc.withdrawTrieLock.Lock()
+		defer bc.withdrawTrieLock.Unlock()
+
+		bc.writeWithdrawTrie(batch, block)
+	}
 	// If the block is better than our head or is on a different chain, force update heads
 	if updateHeads {
 		rawdb.WriteHeadHeaderHash(batch, block.Hash())
@@ -804,11 +964,15 @@ func (bc *BlockChain) Stop() {
 	bc.wg.Wait()
 
 	// Ensure that the entirety of the state snapshot is journalled to disk.
-	var snapBase common.Hash
+	var (
+		snapBase  common.Hash
+		persisted = true
+	)
 	if bc.snaps != nil {
 		var err error
 		if snapBase, err = bc.snaps.Journal(bc.CurrentBlock().Root()); err != nil {
 			log.Error("Failed to journal state snapshot", "err", err)
+			persisted = false
 		}
 	}
 
@@ -825,8 +989,9 @@ func (bc *BlockChain) Stop() {
 				recent := bc.GetBlockByNumber(number - offset)
 
 				log.Info("Writing cached state to disk", "block", recent.Number(), "hash", recent.Hash(), "root", recent.Root())
-				if err := triedb.Commit(recent.Root(), true, nil); err != nil {
+				if err := bc.stateCacheAt(recent.Number()).TrieDB().Commit(recent.Root(), true, nil); err != nil {
 					log.Error("Failed to commit recent state trie", "err", err)
+					persisted = false
 				}
 			}
 		}
@@ -834,14 +999,21 @@ func (bc *BlockChain) Stop() {
 			log.Info("Writing snapshot state to disk", "root", snapBase)
 			if err := triedb.Commit(snapBase, true, nil); err != nil {
 				log.Error("Failed to commit recent state trie", "err", err)
+				persisted = false
 			}
 		}
 		for !bc.triegc.Empty() {
-			triedb.Dereference(bc.triegc.PopItem().(common.Hash))
+			root, number := bc.triegc.Pop()
+			bc.stateCacheAt(big.NewInt(-number)).TrieDB().Dereference(root.(common.Hash))
 		}
 		if size, _ := triedb.Size(); size != 0 {
 			log.Error("Dangling trie nodes after full cleanup")
 		}
+		if bc.zktrieCache != nil {
+			if size, _ := bc.zktrieCache.TrieDB().Size(); size != 0 {
+				log.Error("Dangling zktrie nodes after full cleanup")
+			}
+		}
 	}
 	// Ensure all live cached entries be saved into disk, so that we can skip
 	// cache warmup when node restarts.
@@ -849,6 +1021,10 @@ func (bc *BlockChain) Stop() {
 		triedb := bc.stateCache.TrieDB()
 		triedb.SaveCache(bc.cacheConfig.TrieCleanJournal)
 	}
+	// Mark the shutdown clean so the next startup can skip recovery.
+	if persisted {
+		rawdb.WriteCleanShutdownMarker(bc.db, bc.CurrentBlock().Hash())
+	}
 	log.Info("Blockchain stopped")
 }
 
@@ -1179,6 +1355,7 @@ func (bc *BlockChain) writeBlockWithoutState(block *types.Block, td *big.Int) (e
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
 	}
+	bc.checkEquivocation(block.Header())
 	return nil
 }
 
@@ -1201,12 +1378,17 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 		return NonStatTy, errInsertionInterrupted
 	}
 	defer bc.chainmu.Unlock()
-	return bc.writeBlockWithState(block, receipts, logs, state, emitHeadEvent)
+	defer bc.snaps.Throttle().Begin()()
+
+	return bc.writeBlockWithState(block, receipts, logs, nil, nil, state, emitHeadEvent)
 }
 
 // writeBlockWithState writes the block and all associated state to the database,
 // but is expects the chain mutex to be held.
-func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, emitHeadEvent bool) (status WriteStatus, err error) {
+//
+// The optional call traces are stored atomically along with the block itself.
+// If stats is non-nil, the sizes and timings of the write are recorded into it.
+func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.Receipt, logs []*types.Log, traces [][]*types.InternalTransaction, stats *types.BlockStats, state *state.StateDB, emitHeadEvent bool) (status WriteStatus, err error) {
 	if bc.insertStopped() {
 		return NonStatTy, errInsertionInterrupted
 	}
@@ -1230,16 +1412,79 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
 	rawdb.WritePreimages(blockBatch, state.Preimages())
+	if bc.chainConfig.Scroll.IsSystemTx(block.Number()) {
+		writeL1FeeRefunds(blockBatch, block, receipts)
+	}
+	if list := state.AccessRecording(); list != nil {
+		if bc.cacheConfig.RecordAccessLists {
+			rawdb.WriteBlockAccessList(blockBatch, block.Hash(), list)
+		}
+		if bc.cacheConfig.AccessEpochLength > 0 {
+			bc.writeAccessEpochs(blockBatch, block.NumberU64(), list)
+		}
+	}
+	if traces != nil {
+		rawdb.WriteCallTraces(blockBatch, block.Hash(), traces)
+	}
+	if bc.cacheConfig.LogIndex {
+		bc.writeLogIndex(blockBatch, block.NumberU64(), receipts)
+	}
+	if bc.cacheConfig.CreationIndex {
+		for _, creation := range state.Creations() {
+			creation.BlockNumber = block.NumberU64()
+			rawdb.WriteContractCreation(blockBatch, creation)
+		}
+	}
+	if bc.cacheConfig.RecordContractUsage {
+		if usage := contractUsage(block, receipts, state); len(usage) > 0 {
+			rawdb.WriteContractUsage(blockBatch, block.Hash(), usage)
+		}
+	}
+	var size *types.StateSize
+	if growth := state.SizeGrowth(); growth != nil && bc.cacheConfig.RecordStateSize {
+		// The counts build on the parent, counting from this block if it has none
+		if size = rawdb.ReadStateSize(bc.db, block.ParentHash()); size == nil {
+			size = &types.StateSize{Base: block.NumberU64()}
+		}
+		size.Add(growth)
+		rawdb.WriteStateSize(blockBatch, block.Hash(), size)
+	}
+	if stats != nil {
+		stats.WriteBytes = uint64(blockBatch.ValueSize())
+	}
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
 	}
+	bc.checkEquivocation(block.Header())
+
 	// Commit all cached state changes into underlying memory database.
+	if err := failpoint.Inject(failpoint.StateCommit); err != nil {
+		return NonStatTy, err
+	}
+	var (
+		triedb         = state.Database().TrieDB()
+		dirtyBefore, _ = triedb.Size()
+		commitStart    = time.Now()
+	)
 	root, err := state.Commit(bc.chainConfig.IsEIP158(block.Number()))
 	if err != nil {
 		return NonStatTy, err
 	}
-	triedb := bc.stateCache.TrieDB()
+	if stats != nil {
+		stats.Commit = uint64(time.Since(commitStart))
+		if dirtyAfter, _ := triedb.Size(); dirtyAfter > dirtyBefore {
+			stats.TrieBytes = uint64(dirtyAfter - dirtyBefore)
+		}
+	}
 
+	// At the zktrie cutover, persist the last MPT state to be able to roll back
+	// below the cutover
+	if bc.zktrieCache != nil && bc.chainConfig.Scroll.IsZktrieCutover(block.Number()) && !bc.chainConfig.Scroll.IsZktrieCutover(new(big.Int).Sub(block.Number(), common.Big1)) {
+		parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+		if err := bc.stateCache.TrieDB().Commit(parent.Root, false, nil); err != nil {
+			return NonStatTy, err
+		}
+	}
 	// If we're running an archive node, always flush
 	if bc.cacheConfig.TrieDirtyDisabled {
 		if err := triedb.Commit(root, false, nil); err != nil {
@@ -1276,7 +1521,7 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 						log.Info("State in memory for too long, committing", "time", bc.gcproc, "allowance", bc.cacheConfig.TrieTimeLimit, "optimum", float64(chosen-lastWrite)/TriesInMemory)
 					}
 					// Flush an entire trie and restart the counters
-					triedb.Commit(header.Root, true, nil)
+					bc.stateCacheAt(header.Number).TrieDB().Commit(header.Root, true, nil)
 					lastWrite = chosen
 					bc.gcproc = 0
 				}
@@ -1288,7 +1533,7 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 					bc.triegc.Push(root, number)
 					break
 				}
-				triedb.Dereference(root.(common.Hash))
+				bc.stateCacheAt(big.NewInt(-number)).TrieDB().Dereference(root.(common.Hash))
 			}
 		}
 	}
@@ -1324,6 +1569,14 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	// Set new head.
 	if status == CanonStatTy {
 		bc.writeHeadBlock(block)
+		if bc.cacheConfig.WithdrawTrie {
+			bc.checkWithdrawTrie(block, state)
+		}
+		if size != nil {
+			stateAccountsGauge.Update(size.Accounts())
+			stateSlotsGauge.Update(size.Slots())
+			stateCodeBytesGauge.Update(size.CodeBytes())
+		}
 	}
 	bc.futureBlocks.Remove(block.Hash())
 
@@ -1423,6 +1676,8 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 	if bc.insertStopped() {
 		return 0, nil
 	}
+	// Keep any snapshot generation out of the way while importing
+	defer bc.snaps.Throttle().Begin()()
 
 	// Start a parallel signature recovery (signer will fluke on fork transition, minimal perf loss)
 	senderCacher.recoverFromBlocks(types.MakeSigner(bc.chainConfig, chain[0].Number()), chain)
@@ -1592,7 +1847,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		if parent == nil {
 			parent = bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
 		}
-		statedb, err := state.New(parent.Root, bc.stateCache, bc.snaps)
+		statedb, err := bc.StateAtChild(parent)
 		if err != nil {
 			return it.index, err
 		}
@@ -1601,12 +1856,20 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		statedb.StartPrefetcher("chain")
 		activeState = statedb
 
+		// Record all state accesses if block access lists or access epochs are requested
+		if bc.cacheConfig.RecordAccessLists || bc.cacheConfig.AccessEpochLength > 0 {
+			statedb.StartAccessRecording()
+		}
+		if bc.cacheConfig.RecordStateSize {
+			statedb.StartSizeTracking()
+		}
+
 		// If we have a followup block, run that against the current state to pre-cache
 		// transactions and probabilistically some of the account/storage trie nodes.
 		var followupInterrupt uint32
 		if !bc.cacheConfig.TrieCleanNoPrefetch {
 			if followup, err := it.peek(); followup != nil && err == nil {
-				throwaway, _ := state.New(parent.Root, bc.stateCache, bc.snaps)
+				throwaway, _ := bc.StateAtChild(parent)
 
 				go func(start time.Time, followup *types.Block, throwaway *state.StateDB, interrupt *uint32) {
 					bc.prefetcher.Prefetch(followup, throwaway, bc.vmConfig, &followupInterrupt)
@@ -1619,9 +1882,19 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 			}
 		}
 
+		// Record the internal transactions if requested and no other tracer is set
+		var (
+			vmConfig = bc.vmConfig
+			recorder *vm.CallRecorder
+		)
+		if bc.cacheConfig.RecordCallTraces && vmConfig.Tracer == nil {
+			recorder = vm.NewCallRecorder()
+			vmConfig.Debug, vmConfig.Tracer = true, recorder
+		}
+
 		// Process block using the parent state as reference point
 		substart := time.Now()
-		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
+		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, vmConfig)
 		if err != nil {
 			bc.reportBlock(block, receipts, err)
 			atomic.StoreUint32(&followupInterrupt, 1)
@@ -1639,7 +1912,8 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		trieproc := statedb.SnapshotAccountReads + statedb.AccountReads + statedb.AccountUpdates
 		trieproc += statedb.SnapshotStorageReads + statedb.StorageReads + statedb.StorageUpdates
 
-		blockExecutionTimer.Update(time.Since(substart) - trieproc - triehash)
+		execution := time.Since(substart) - triehash
+		blockExecutionTimer.Update(execution - trieproc)
 
 		// Validate the state using the default validator
 		substart = time.Now()
@@ -1648,6 +1922,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 			atomic.StoreUint32(&followupInterrupt, 1)
 			return it.index, err
 		}
+		validation := time.Since(substart)
 		proctime := time.Since(start)
 
 		// Update the metrics touched during block validation
@@ -1656,14 +1931,39 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 
 		blockValidationTimer.Update(time.Since(substart) - (statedb.AccountHashes + statedb.StorageHashes - triehash))
 
+		// Collect the recorded internal transactions to store along the block
+		var traces [][]*types.InternalTransaction
+		if recorder != nil {
+			if traces = recorder.Traces(); len(traces) != len(block.Transactions()) {
+				log.Warn("Mismatching call traces", "number", block.Number(), "hash", block.Hash(), "txs", len(block.Transactions()), "traces", len(traces))
+				traces = nil
+			}
+		}
+		// Collect the resource usage of the import, completed by the write below
+		var usage *types.BlockStats
+		if bc.cacheConfig.RecordBlockStats {
+			usage = &types.BlockStats{
+				Execution:     uint64(execution),
+				StateRead:     uint64(trieproc),
+				Validation:    uint64(validation),
+				AccountHits:   uint64(statedb.AccountHits),
+				AccountMisses: uint64(statedb.AccountMisses),
+				StorageHits:   uint64(statedb.StorageHits),
+				StorageMisses: uint64(statedb.StorageMisses),
+			}
+		}
 		// Write the block to the chain and get the status.
 		substart = time.Now()
 		// EvmTraces & StorageTrace being nil is safe because l2geth's p2p server is stoped and the code will not execute there.
-		status, err := bc.writeBlockWithState(block, receipts, logs, statedb, false)
+		status, err := bc.writeBlockWithState(block, receipts, logs, traces, usage, statedb, false)
 		atomic.StoreUint32(&followupInterrupt, 1)
 		if err != nil {
 			return it.index, err
 		}
+		if usage != nil {
+			usage.Write = uint64(time.Since(substart))
+			rawdb.WriteBlockStats(bc.db, block.Hash(), usage)
+		}
 		// Update the metrics touched during block commit
 		accountCommitTimer.Update(statedb.AccountCommits)   // Account commits are complete, we can mark them
 		storageCommitTimer.Update(statedb.StorageCommits)   // Storage commits are complete, we can mark them
@@ -1950,6 +2250,11 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 			return fmt.Errorf("invalid new chain")
 		}
 	}
+	// Finalized blocks are never reverted, unless explicitly allowed
+	if err := bc.checkRewind(commonBlock.NumberU64()); err != nil {
+		log.Error("Refusing reorg below finalized block", "number", commonBlock.Number(), "hash", commonBlock.Hash(), "err", err)
+		return err
+	}
 	// Ensure the user sees large reorgs
 	if len(oldChain) > 0 && len(newChain) > 0 {
 		logFn := log.Info
@@ -1963,6 +2268,10 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 		blockReorgAddMeter.Mark(int64(len(newChain)))
 		blockReorgDropMeter.Mark(int64(len(oldChain)))
 		blockReorgMeter.Mark(1)
+	} else if len(oldChain) > 0 {
+		// The new head is an ancestor of the current one, e.g. set explicitly
+		log.Warn("Chain rewound to ancestor", "number", commonBlock.Number(), "hash", commonBlock.Hash(), "drop", len(oldChain), "dropfrom", oldChain[0].Hash())
+		blockReorgDropMeter.Mark(int64(len(oldChain)))
 	} else {
 		log.Error("Impossible reorg, please file an issue", "oldnum", oldBlock.Number(), "oldhash", oldBlock.Hash(), "newnum", newBlock.Number(), "newhash", newBlock.Hash())
 	}
@@ -1984,8 +2293,12 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 	for _, tx := range types.TxDifference(deletedTxs, addedTxs) {
 		rawdb.DeleteTxLookupEntry(indexesBatch, tx.Hash())
 	}
-	// Delete any canonical number assignments above the new head
-	number := bc.CurrentBlock().NumberU64()
+	// Delete any canonical number assignments above the new head. The head itself
+	// is written by the caller, so only the blocks inserted above count.
+	number := commonBlock.NumberU64()
+	if len(newChain) > 1 {
+		number = newChain[1].NumberU64()
+	}
 	for i := number + 1; ; i++ {
 		hash := rawdb.ReadCanonicalHash(bc.db, i)
 		if hash == (common.Hash{}) {
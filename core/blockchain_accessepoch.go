@@ -0,0 +1,44 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+)
+
+// writeAccessEpochs tags every account and storage slot accessed by the block
+// with the epoch the block belongs to. Entries already tagged with that epoch
+// are not rewritten. Side chain blocks are tagged too, so the tags are an upper
+// bound of the last canonical access, which is good enough for estimating how
+// much state would expire.
+func (bc *BlockChain) writeAccessEpochs(db ethdb.KeyValueWriter, number uint64, list types.BlockAccessList) {
+	epoch := number / bc.cacheConfig.AccessEpochLength
+	tag := func(acc *types.AccountAccess, slot *common.Hash) {
+		if last, ok := rawdb.ReadAccessEpoch(bc.db, acc.Address, slot); !ok || last < epoch {
+			rawdb.WriteAccessEpoch(db, acc.Address, slot, epoch)
+		}
+	}
+	for _, acc := range list {
+		tag(acc, nil)
+		for i := range acc.StorageKeys {
+			tag(acc, &acc.StorageKeys[i])
+		}
+	}
+}
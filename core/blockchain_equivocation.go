@@ -0,0 +1,73 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// proposerOf returns the proposer of a block: the proposer in turn if multiple
+// sequencers rotate, or the author according to the consensus engine otherwise.
+func (bc *BlockChain) proposerOf(header *types.Header) (common.Address, error) {
+	if bc.chainConfig.Scroll.IsProposerRotation(header.Number) {
+		_, proposer := bc.chainConfig.Scroll.ScheduledProposer(header.Number)
+		return proposer, nil
+	}
+	return bc.engine.Author(header)
+}
+
+// checkEquivocation looks for a different block of the same proposer at the
+// height of a newly written, verified block. If there's one, the two headers are
+// stored as evidence of the proposer equivocating and an EquivocationEvent is
+// posted.
+func (bc *BlockChain) checkEquivocation(header *types.Header) {
+	hashes := rawdb.ReadAllHashes(bc.db, header.Number.Uint64())
+	if len(hashes) < 2 {
+		return
+	}
+	// Blocks may be written again when reorging to a side chain
+	hash := header.Hash()
+	if rawdb.HasEquivocationEvidence(bc.db, header.Number.Uint64(), hash) {
+		return
+	}
+	proposer, err := bc.proposerOf(header)
+	if err != nil {
+		return
+	}
+	for _, other := range hashes {
+		// Skip the block itself and the ones already proven to equivocate
+		if other == hash || rawdb.HasEquivocationEvidence(bc.db, header.Number.Uint64(), other) {
+			continue
+		}
+		first := bc.GetHeader(other, header.Number.Uint64())
+		if first == nil {
+			continue
+		}
+		if author, err := bc.proposerOf(first); err != nil || author != proposer {
+			continue
+		}
+		ev := &types.EquivocationEvidence{Proposer: proposer, First: first, Second: header}
+		rawdb.WriteEquivocationEvidence(bc.db, ev)
+
+		log.Warn("Proposer equivocation detected", "proposer", proposer, "number", header.Number, "first", other, "second", hash)
+		bc.equivFeed.Send(EquivocationEvent{Evidence: ev})
+		return
+	}
+}
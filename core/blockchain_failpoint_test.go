@@ -0,0 +1,66 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build failpoints
+// +build failpoints
+
+package core
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/internal/failpoint"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// Tests that blocks failing at the commit pipeline failpoints are rejected
+// without advancing the chain, and import once the failpoint is disarmed.
+func TestCommitFailpoints(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Scroll.MaxTxPerBlock = nil
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: &config}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := GenerateChain(&config, genesis, ethash.NewFaker(), db, 4, nil)
+
+	chain, err := NewBlockChain(db, nil, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	for i, name := range []string{failpoint.ProcessBlock, failpoint.StateCommit} {
+		if err := failpoint.Enable(name, "error"); err != nil {
+			t.Fatalf("failed to arm %s: %v", name, err)
+		}
+		if _, err := chain.InsertChain(blocks[i : i+1]); err != failpoint.ErrInjected {
+			t.Fatalf("%s: insert error mismatch: have %v, want %v", name, err, failpoint.ErrInjected)
+		}
+		if head := chain.CurrentBlock().NumberU64(); head != uint64(i) {
+			t.Fatalf("%s: head mismatch: have %d, want %d", name, head, i)
+		}
+		failpoint.Disable(name)
+		if _, err := chain.InsertChain(blocks[i : i+1]); err != nil {
+			t.Fatalf("%s: failed to insert block after disarming: %v", name, err)
+		}
+	}
+}
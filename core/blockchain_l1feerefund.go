@@ -0,0 +1,127 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+// ErrInvalidL1FeeRefund is returned if a block issues an L1 fee refund for a
+// transaction that wasn't charged, or already refunded, the amount.
+var ErrInvalidL1FeeRefund = errors.New("invalid L1 fee refund")
+
+// errNoFeeVault is returned if L1 fees are refunded on a chain without fee vault.
+var errNoFeeVault = errors.New("L1 fee refund without fee vault")
+
+// l1FeeRefunds returns the L1 fee refunds issued by the system transactions of
+// a block, along with the index of the issuing transactions.
+func l1FeeRefunds(block *types.Block) (map[int][]*types.L1FeeRefund, error) {
+	txs := block.Transactions()
+	refunds := make(map[int][]*types.L1FeeRefund)
+	for i, tx := range txs[:types.SystemTxCount(txs)] {
+		if *tx.To() != rcfg.L1FeeRefundAddress {
+			continue
+		}
+		list, err := types.DecodeL1FeeRefunds(tx.Data())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidL1FeeRefund, err)
+		}
+		refunds[i] = list
+	}
+	return refunds, nil
+}
+
+// VerifyL1FeeRefund checks that a refund issued in the block with the given
+// number pays the sender of a canonical transaction included earlier at most the
+// L1 fee it was charged, and that the transaction wasn't refunded before.
+func (bc *BlockChain) VerifyL1FeeRefund(refund *types.L1FeeRefund, number uint64) error {
+	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(bc.db, refund.TxHash)
+	if tx == nil || blockNumber >= number {
+		return fmt.Errorf("%w: transaction %x not included before block %d", ErrInvalidL1FeeRefund, refund.TxHash, number)
+	}
+	if tx.IsSystemTx() {
+		return fmt.Errorf("%w: system transaction %x", ErrInvalidL1FeeRefund, refund.TxHash)
+	}
+	sender, err := types.Sender(types.MakeSigner(bc.chainConfig, new(big.Int).SetUint64(blockNumber)), tx)
+	if err != nil || sender != refund.Recipient {
+		return fmt.Errorf("%w: recipient %x is not the sender of %x", ErrInvalidL1FeeRefund, refund.Recipient, refund.TxHash)
+	}
+	receipts := bc.GetReceiptsByHash(blockHash)
+	if uint64(len(receipts)) <= index || receipts[index].L1Fee == nil || receipts[index].L1Fee.Cmp(refund.Amount) < 0 {
+		return fmt.Errorf("%w: refund %v exceeds the L1 fee of %x", ErrInvalidL1FeeRefund, refund.Amount, refund.TxHash)
+	}
+	if bc.GetL1FeeRefund(refund.TxHash) != nil {
+		return fmt.Errorf("%w: transaction %x already refunded", ErrInvalidL1FeeRefund, refund.TxHash)
+	}
+	return nil
+}
+
+// verifyL1FeeRefunds checks all L1 fee refunds issued by a block.
+func (bc *BlockChain) verifyL1FeeRefunds(block *types.Block) error {
+	refunds, err := l1FeeRefunds(block)
+	if err != nil {
+		return err
+	}
+	seen := make(map[common.Hash]struct{})
+	for _, list := range refunds {
+		for _, refund := range list {
+			if _, ok := seen[refund.TxHash]; ok {
+				return fmt.Errorf("%w: transaction %x refunded twice", ErrInvalidL1FeeRefund, refund.TxHash)
+			}
+			seen[refund.TxHash] = struct{}{}
+
+			if err := bc.VerifyL1FeeRefund(refund, block.NumberU64()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeL1FeeRefunds records the L1 fee refunds successfully issued by a block.
+func writeL1FeeRefunds(db ethdb.KeyValueWriter, block *types.Block, receipts types.Receipts) {
+	refunds, err := l1FeeRefunds(block)
+	if err != nil {
+		return
+	}
+	for i, list := range refunds {
+		if i >= len(receipts) || receipts[i].Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+		for _, refund := range list {
+			rawdb.WriteL1FeeRefund(db, refund.TxHash, &types.L1FeeRefundRecord{
+				BlockHash:   block.Hash(),
+				BlockNumber: block.NumberU64(),
+				Amount:      refund.Amount,
+			})
+		}
+	}
+}
+
+// GetL1FeeRefund retrieves the L1 fee refund issued to a transaction by the
+// canonical chain, or nil if the transaction wasn't refunded.
+func (bc *BlockChain) GetL1FeeRefund(hash common.Hash) *types.L1FeeRefundRecord {
+	return rawdb.ReadCanonicalL1FeeRefund(bc.db, hash)
+}
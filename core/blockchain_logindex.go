@@ -0,0 +1,86 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// initLogIndex loads the range covered by the log index, initializing an empty
+// index right above the current head if none exists yet, and starts catching
+// up with blocks imported while indexing was disabled.
+func (bc *BlockChain) initLogIndex() {
+	head := bc.CurrentBlock().NumberU64()
+	tail, next, ok := rawdb.ReadLogIndexRange(bc.db)
+	if !ok {
+		tail, next = head+1, head+1
+		rawdb.WriteLogIndexTail(bc.db, tail)
+		rawdb.WriteLogIndexNext(bc.db, next)
+	}
+	bc.logIndexNext = next
+	log.Info("Loaded log index", "tail", tail, "next", next)
+
+	if next <= head {
+		bc.wg.Add(1)
+		go bc.catchupLogIndex()
+	}
+}
+
+// catchupLogIndex indexes canonical blocks from the stored receipts until the
+// log index reaches the chain head, after which import-time indexing takes over.
+func (bc *BlockChain) catchupLogIndex() {
+	defer bc.wg.Done()
+
+	for {
+		bc.logIndexLock.Lock()
+		next := bc.logIndexNext
+		bc.logIndexLock.Unlock()
+
+		head := bc.CurrentBlock().NumberU64()
+		if next > head {
+			return
+		}
+		if err := rawdb.IndexLogs(bc.db, next, head, bc.quit); err != nil {
+			log.Warn("Failed to catch up log index", "next", next, "head", head, "err", err)
+			return
+		}
+		bc.logIndexLock.Lock()
+		if bc.logIndexNext <= head {
+			bc.logIndexNext = head + 1
+		}
+		bc.logIndexLock.Unlock()
+	}
+}
+
+// writeLogIndex adds the log index entries of a freshly processed block to the
+// given batch. All processed blocks are indexed, including side chain ones, so
+// that any block that becomes canonical is covered. The covered range is only
+// extended if the block directly follows it.
+func (bc *BlockChain) writeLogIndex(db ethdb.KeyValueWriter, number uint64, receipts types.Receipts) {
+	rawdb.WriteLogIndex(db, number, receipts)
+
+	bc.logIndexLock.Lock()
+	defer bc.logIndexLock.Unlock()
+
+	if number == bc.logIndexNext {
+		bc.logIndexNext++
+		rawdb.WriteLogIndexNext(db, bc.logIndexNext)
+	}
+}
@@ -49,6 +49,12 @@ func (bc *BlockChain) CurrentFastBlock() *types.Block {
 	return bc.currentFastBlock.Load().(*types.Block)
 }
 
+// CurrentFinalizedBlock retrieves the latest block finalized on L1, or nil if
+// no block was finalized yet.
+func (bc *BlockChain) CurrentFinalizedBlock() *types.Block {
+	return bc.currentFinalized.Load().(*types.Block)
+}
+
 // HasHeader checks if a block header is present in the database or not, caching
 // it if present.
 func (bc *BlockChain) HasHeader(hash common.Hash, number uint64) bool {
@@ -253,6 +259,11 @@ func (bc *BlockChain) GetTd(hash common.Hash, number uint64) *big.Int {
 
 // HasState checks if state trie is fully present in the database or not.
 func (bc *BlockChain) HasState(hash common.Hash) bool {
+	if bc.zktrieCache != nil && bc.chainConfig.Scroll.IsZktrieCutover(bc.CurrentBlock().Number()) {
+		if _, err := bc.zktrieCache.OpenTrie(hash); err == nil {
+			return true
+		}
+	}
 	_, err := bc.stateCache.OpenTrie(hash)
 	return err == nil
 }
@@ -299,9 +310,43 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 
 // StateAt returns a new mutable state based on a particular point in time.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
+	// Past the zktrie cutover, only the states of the blocks before the cutover
+	// are in MPT
+	if bc.zktrieCache != nil && bc.chainConfig.Scroll.IsZktrieCutover(bc.CurrentBlock().Number()) {
+		if statedb, err := state.New(root, bc.zktrieCache, nil); err == nil {
+			return statedb, nil
+		}
+	}
 	return state.New(root, bc.stateCache, bc.snaps)
 }
 
+// StateAtChild returns a new mutable state to execute a child of the given block
+// on, in the state scheme of the child. The first block after the zktrie cutover
+// executes on the state of its parent migrated to zktrie.
+func (bc *BlockChain) StateAtChild(parent *types.Header) (*state.StateDB, error) {
+	number := new(big.Int).Add(parent.Number, common.Big1)
+	if bc.zktrieCache == nil || !bc.chainConfig.Scroll.IsZktrieCutover(number) {
+		return state.New(parent.Root, bc.stateCache, bc.snaps)
+	}
+	root := parent.Root
+	if !bc.chainConfig.Scroll.IsZktrieCutover(parent.Number) {
+		var migrated bool
+		if root, migrated = rawdb.ReadZktrieRoot(bc.db, parent.Root); !migrated {
+			return nil, ErrStateNotMigrated
+		}
+	}
+	return state.New(root, bc.zktrieCache, nil)
+}
+
+// stateCacheAt returns the state database holding the state of the block with
+// the given number, in zktrie past the zktrie cutover.
+func (bc *BlockChain) stateCacheAt(number *big.Int) state.Database {
+	if bc.zktrieCache != nil && bc.chainConfig.Scroll.IsZktrieCutover(number) {
+		return bc.zktrieCache
+	}
+	return bc.stateCache
+}
+
 // Config retrieves the chain's fork configuration.
 func (bc *BlockChain) Config() *params.ChainConfig { return bc.chainConfig }
 
@@ -328,7 +373,7 @@ func (bc *BlockChain) Processor() Processor {
 
 // StateCache returns the caching database underpinning the blockchain instance.
 func (bc *BlockChain) StateCache() state.Database {
-	return bc.stateCache
+	return bc.stateCacheAt(bc.CurrentBlock().Number())
 }
 
 // GasLimit returns the gas limit of the current HEAD block.
@@ -383,6 +428,16 @@ func (bc *BlockChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscript
 	return bc.scope.Track(bc.logsFeed.Subscribe(ch))
 }
 
+// SubscribeEquivocationEvent registers a subscription of EquivocationEvent.
+func (bc *BlockChain) SubscribeEquivocationEvent(ch chan<- EquivocationEvent) event.Subscription {
+	return bc.scope.Track(bc.equivFeed.Subscribe(ch))
+}
+
+// SubscribeFinalizedEvent registers a subscription of FinalizedEvent.
+func (bc *BlockChain) SubscribeFinalizedEvent(ch chan<- FinalizedEvent) event.Subscription {
+	return bc.scope.Track(bc.finalFeed.Subscribe(ch))
+}
+
 // SubscribeBlockProcessingEvent registers a subscription of bool where true means
 // block processing has started while false means it has stopped.
 func (bc *BlockChain) SubscribeBlockProcessingEvent(ch chan<- bool) event.Subscription {
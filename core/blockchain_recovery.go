@@ -0,0 +1,91 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// recoverIndices repairs the indices maintained alongside the chain so that
+// none of them points past the head block. It is run at startup after an
+// unclean shutdown, when the head may have been rewound below the data derived
+// from it, or written by a version not committing the indices atomically.
+func (bc *BlockChain) recoverIndices() {
+	head := bc.CurrentBlock()
+	if head.NumberU64() == 0 {
+		return
+	}
+	start := time.Now()
+	log.Warn("Unclean shutdown detected, verifying chain indices", "number", head.Number(), "hash", head.Hash())
+
+	if bc.cacheConfig.LogIndex {
+		bc.recoverLogIndex(head.NumberU64())
+	}
+	if bc.cacheConfig.WithdrawTrie {
+		bc.recoverWithdrawTrie()
+	}
+	log.Info("Verified chain indices", "number", head.Number(), "elapsed", common.PrettyDuration(time.Since(start)))
+}
+
+// recoverLogIndex shrinks the range covered by the log index to end at the
+// head block. Entries of blocks above the head are left in place, they are
+// overwritten once those heights are imported again.
+func (bc *BlockChain) recoverLogIndex(head uint64) {
+	tail, next, ok := rawdb.ReadLogIndexRange(bc.db)
+	if !ok || next <= head+1 {
+		return
+	}
+	log.Warn("Log index ahead of head, rewinding", "next", next, "head", head)
+	batch := bc.db.NewBatch()
+	if tail > head+1 {
+		rawdb.WriteLogIndexTail(batch, head+1)
+	}
+	rawdb.WriteLogIndexNext(batch, head+1)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to rewind log index", "err", err)
+	}
+}
+
+// recoverWithdrawTrie extends the withdraw trie to the head block if it is
+// tracked up to the parent, and verifies its root against the head state.
+func (bc *BlockChain) recoverWithdrawTrie() {
+	head := bc.CurrentBlock()
+
+	if _, ok := rawdb.ReadWithdrawTrieCount(bc.db, head.Hash()); !ok {
+		if _, ok := rawdb.ReadWithdrawTrieCount(bc.db, head.ParentHash()); !ok && head.NumberU64() > 1 {
+			return
+		}
+		log.Warn("Withdraw trie behind head, extending", "number", head.Number(), "hash", head.Hash())
+		bc.withdrawTrieLock.Lock()
+		batch := bc.db.NewBatch()
+		bc.writeWithdrawTrie(batch, head)
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to extend withdraw trie", "err", err)
+		}
+		bc.withdrawTrieLock.Unlock()
+	}
+	statedb, err := bc.StateAt(head.Root())
+	if err != nil {
+		log.Error("Failed to open head state for withdraw trie check", "number", head.Number(), "err", err)
+		return
+	}
+	bc.checkWithdrawTrie(head, statedb)
+}
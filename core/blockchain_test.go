@@ -17,12 +17,14 @@
 package core
 
 import (
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -39,6 +41,9 @@ import (
 	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/denylist"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
 	"github.com/scroll-tech/go-ethereum/trie"
 )
 
@@ -3238,3 +3243,1366 @@ func TestTransactionCountLimit(t *testing.T) {
 		t.Fatalf("error mismatch: have: %v, want: %v", err, consensus.ErrInvalidTxCount)
 	}
 }
+
+// Tests that the block-level access list is recorded on import if requested.
+func TestBlockAccessListRecording(t *testing.T) {
+	var (
+		aa   = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		code = []byte{byte(vm.PC), byte(vm.PC), byte(vm.SLOAD), byte(vm.SLOAD)}
+
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				address: {Balance: big.NewInt(1000000000000000)},
+				aa:      {Code: code, Balance: big.NewInt(0)},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 1, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{1})
+		tx, _ := types.SignNewTx(key, types.LatestSigner(gspec.Config), &types.LegacyTx{
+			Nonce:    0,
+			To:       &aa,
+			Gas:      30000,
+			GasPrice: b.header.BaseFee,
+		})
+		b.AddTx(tx)
+	})
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.RecordAccessLists = true
+	chain, err := NewBlockChain(diskdb, &cacheConfig, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	list := rawdb.ReadBlockAccessList(diskdb, blocks[0].Hash())
+	if list == nil {
+		t.Fatalf("block access list not recorded")
+	}
+	var found bool
+	for _, acc := range list {
+		if acc.Address != aa {
+			continue
+		}
+		found = true
+		if len(acc.StorageKeys) != 2 || acc.StorageKeys[0] != (common.Hash{}) || acc.StorageKeys[1] != common.BigToHash(common.Big1) {
+			t.Errorf("storage keys mismatch: %v", acc.StorageKeys)
+		}
+		if acc.CodeHash == nil || *acc.CodeHash != crypto.Keccak256Hash(code) {
+			t.Errorf("code hash mismatch: %v", acc.CodeHash)
+		}
+	}
+	if !found {
+		t.Fatalf("callee missing from access list")
+	}
+}
+
+func TestCallTraceRecording(t *testing.T) {
+	var (
+		aa = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		bb = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+
+		// aa calls into bb, which always reverts
+		aaCode = []byte{
+			byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+			byte(vm.PUSH2), 0xbb, 0xbb, byte(vm.GAS), byte(vm.CALL), byte(vm.POP), byte(vm.STOP),
+		}
+		bbCode = []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT)}
+
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				address: {Balance: big.NewInt(1000000000000000)},
+				aa:      {Code: aaCode, Balance: big.NewInt(0)},
+				bb:      {Code: bbCode, Balance: big.NewInt(0)},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+		input   = []byte{0x01, 0x02}
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 1, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{1})
+		tx, _ := types.SignNewTx(key, types.LatestSigner(gspec.Config), &types.LegacyTx{
+			Nonce:    0,
+			To:       &aa,
+			Value:    big.NewInt(1),
+			Gas:      100000,
+			GasPrice: b.header.BaseFee,
+			Data:     input,
+		})
+		b.AddTx(tx)
+	})
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.RecordCallTraces = true
+	chain, err := NewBlockChain(diskdb, &cacheConfig, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	traces := rawdb.ReadCallTraces(diskdb, blocks[0].Hash())
+	if len(traces) != 1 {
+		t.Fatalf("call traces count mismatch: have %d, want 1", len(traces))
+	}
+	want := []*types.InternalTransaction{
+		{Type: "CALL", From: address, To: aa, Value: big.NewInt(1), InputHash: crypto.Keccak256Hash(input), Depth: 0, Success: true},
+		{Type: "CALL", From: aa, To: bb, Value: new(big.Int), InputHash: crypto.Keccak256Hash(nil), Depth: 1, Success: false},
+	}
+	if len(traces[0]) != len(want) {
+		t.Fatalf("internal transaction count mismatch: have %d, want %d", len(traces[0]), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(traces[0][i], want[i]) {
+			t.Errorf("internal transaction %d mismatch: have %+v, want %+v", i, traces[0][i], want[i])
+		}
+	}
+}
+
+func TestBlockStatsRecording(t *testing.T) {
+	var (
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(1000000000000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 2, func(i int, b *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(address), common.Address{0x01}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.RecordBlockStats = true
+	chain, err := NewBlockChain(diskdb, &cacheConfig, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	for _, block := range blocks {
+		stats := rawdb.ReadBlockStats(diskdb, block.Hash())
+		if stats == nil {
+			t.Fatalf("block %d: stats missing", block.NumberU64())
+		}
+		if stats.Execution == 0 || stats.Validation == 0 || stats.Write == 0 || stats.Commit > stats.Write {
+			t.Errorf("block %d: invalid timings %+v", block.NumberU64(), stats)
+		}
+		if stats.WriteBytes == 0 || stats.TrieBytes == 0 {
+			t.Errorf("block %d: invalid write sizes %+v", block.NumberU64(), stats)
+		}
+		if stats.AccountMisses == 0 || stats.AccountHits == 0 {
+			t.Errorf("block %d: invalid account lookups %+v", block.NumberU64(), stats)
+		}
+	}
+}
+
+func TestCreationIndex(t *testing.T) {
+	var (
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		factory = common.HexToAddress("0xfac7")
+		config  = *params.TestChainConfig
+
+		// Init code deploying an empty contract, and a factory creating it with
+		// CREATE2, reverting afterwards if called with any calldata
+		initCode    = common.FromHex("60006000f3")
+		factoryCode = common.FromHex("6460006000f3600052602a6005601b6000f55036601857005b60006000fd")
+		salt        = common.Hash{31: 0x2a}
+	)
+	config.Scroll.MaxTxPerBlock = nil
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			address: {Balance: big.NewInt(params.Ether)},
+			factory: {Code: factoryCode, Balance: new(big.Int)},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.LatestSigner(&config)
+
+	var txs []*types.Transaction
+	blocks, _ := GenerateChain(&config, genesis, engine, db, 2, func(i int, b *BlockGen) {
+		var batch []*types.Transaction
+		if i == 0 {
+			create, _ := types.SignTx(types.NewContractCreation(b.TxNonce(address), new(big.Int), 100000, b.BaseFee(), initCode), signer, key)
+			b.AddTx(create)
+			reverted, _ := types.SignTx(types.NewTransaction(b.TxNonce(address), factory, new(big.Int), 100000, b.BaseFee(), []byte{0x01}), signer, key)
+			b.AddTx(reverted)
+			batch = append(batch, create, reverted)
+		} else {
+			create2, _ := types.SignTx(types.NewTransaction(b.TxNonce(address), factory, new(big.Int), 100000, b.BaseFee(), nil), signer, key)
+			b.AddTx(create2)
+			batch = append(batch, create2)
+		}
+		txs = append(txs, batch...)
+	})
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.CreationIndex = true
+	chain, err := NewBlockChain(diskdb, &cacheConfig, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	var (
+		created  = crypto.CreateAddress(address, 0)
+		created2 = crypto.CreateAddress2(factory, salt, crypto.Keccak256(initCode))
+	)
+	if n, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	want := &types.ContractCreation{Address: created, Creator: address, Nonce: 0, InitCodeHash: crypto.Keccak256Hash(initCode), TxHash: txs[0].Hash(), BlockNumber: 1}
+	if have := rawdb.ReadContractCreation(diskdb, created); !reflect.DeepEqual(have, want) {
+		t.Errorf("creation mismatch: have %+v, want %+v", have, want)
+	}
+	// Creations reverted along with their caller are not indexed
+	if have := rawdb.ReadContractCreation(diskdb, created2); have != nil {
+		t.Errorf("reverted creation indexed: %+v", have)
+	}
+	if n, err := chain.InsertChain(blocks[1:]); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	want = &types.ContractCreation{Address: created2, Creator: factory, Salt: &salt, InitCodeHash: crypto.Keccak256Hash(initCode), TxHash: txs[2].Hash(), BlockNumber: 2}
+	if have := rawdb.ReadContractCreation(diskdb, created2); !reflect.DeepEqual(have, want) {
+		t.Errorf("CREATE2 creation mismatch: have %+v, want %+v", have, want)
+	}
+}
+
+// Tests that accounts and storage slots accessed by imported blocks are tagged
+// with the epoch of their last access.
+func TestAccessEpochs(t *testing.T) {
+	var (
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+
+		key, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address   = crypto.PubkeyToAddress(key.PublicKey)
+		contract  = common.HexToAddress("0xc0de")
+		recipient = common.HexToAddress("0xbeef")
+		config    = *params.TestChainConfig
+	)
+	config.Scroll.MaxTxPerBlock = nil
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			address:  {Balance: big.NewInt(params.Ether)},
+			contract: {Code: common.FromHex("602a60005500"), Balance: new(big.Int)}, // sstore(0, 42)
+		},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.LatestSigner(&config)
+
+	blocks, _ := GenerateChain(&config, genesis, engine, db, 3, func(i int, b *BlockGen) {
+		switch i {
+		case 0:
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(address), contract, new(big.Int), 100000, b.BaseFee(), nil), signer, key)
+			b.AddTx(tx)
+		case 2:
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(address), recipient, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+			b.AddTx(tx)
+		}
+	})
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.AccessEpochLength = 2
+	chain, err := NewBlockChain(diskdb, &cacheConfig, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	slot := common.Hash{}
+	tests := []struct {
+		addr  common.Address
+		slot  *common.Hash
+		epoch uint64
+	}{
+		{contract, nil, 0},
+		{contract, &slot, 0},
+		{address, nil, 1},
+		{recipient, nil, 1},
+	}
+	for i, tt := range tests {
+		epoch, ok := rawdb.ReadAccessEpoch(diskdb, tt.addr, tt.slot)
+		if !ok || epoch != tt.epoch {
+			t.Errorf("test %d: epoch mismatch: have %d (%v), want %d", i, epoch, ok, tt.epoch)
+		}
+	}
+	if _, ok := rawdb.ReadAccessEpoch(diskdb, common.HexToAddress("0xdead"), nil); ok {
+		t.Errorf("untouched account tagged")
+	}
+}
+
+// Tests that two different blocks of the same proposer at the same height are
+// recorded as equivocation evidence, while blocks of other proposers aren't.
+func TestEquivocationEvidence(t *testing.T) {
+	var (
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+		gspec  = &Genesis{Config: params.TestChainConfig}
+
+		proposer = common.Address{0x01}
+		other    = common.Address{0x02}
+	)
+	genesis := gspec.MustCommit(db)
+	makeBlock := func(coinbase common.Address, extra []byte) *types.Block {
+		blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 1, func(i int, b *BlockGen) {
+			b.SetCoinbase(coinbase)
+			b.SetExtra(extra)
+		})
+		return blocks[0]
+	}
+	chain, err := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	events := make(chan EquivocationEvent, 1)
+	sub := chain.SubscribeEquivocationEvent(events)
+	defer sub.Unsubscribe()
+
+	var (
+		first  = makeBlock(proposer, []byte("first"))
+		second = makeBlock(proposer, []byte("second"))
+		third  = makeBlock(other, []byte("third"))
+	)
+	for _, block := range []*types.Block{first, third, second} {
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert block %x: %v", block.Hash(), err)
+		}
+	}
+	select {
+	case ev := <-events:
+		if ev.Evidence.Proposer != proposer || ev.Evidence.First.Hash() != first.Hash() || ev.Evidence.Second.Hash() != second.Hash() {
+			t.Fatalf("evidence mismatch: have proposer %x, blocks %x %x", ev.Evidence.Proposer, ev.Evidence.First.Hash(), ev.Evidence.Second.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("equivocation event missing")
+	}
+	evidence := rawdb.ReadEquivocationEvidence(db, 0, 10)
+	if len(evidence) != 1 {
+		t.Fatalf("stored evidence mismatch: have %d, want 1", len(evidence))
+	}
+	if evidence[0].Proposer != proposer || evidence[0].Second.Hash() != second.Hash() {
+		t.Fatalf("stored evidence mismatch: have proposer %x, block %x", evidence[0].Proposer, evidence[0].Second.Hash())
+	}
+	// Inserting a known block again doesn't produce new evidence
+	if _, err := chain.InsertChain(types.Blocks{second}); err != nil {
+		t.Fatalf("failed to reinsert block: %v", err)
+	}
+	if evidence := rawdb.ReadEquivocationEvidence(db, 0, 10); len(evidence) != 1 {
+		t.Fatalf("stored evidence mismatch after reinsert: have %d, want 1", len(evidence))
+	}
+}
+
+// Tests that the chain refuses to reorg below the finalized block, and that the
+// finalized block is restored after a restart.
+func TestFinalizedReorgProtection(t *testing.T) {
+	var (
+		engine  = ethash.NewFaker()
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 6, nil)
+	fork, _ := GenerateChain(gspec.Config, blocks[1], engine, db, 8, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+	})
+	chain, err := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if chain.CurrentFinalizedBlock() != nil {
+		t.Fatalf("finalized block set on fresh chain")
+	}
+	if err := chain.SetFinalized(fork[0]); err == nil {
+		t.Fatalf("non canonical block finalized")
+	}
+	if err := chain.SetFinalized(blocks[3]); err != nil {
+		t.Fatalf("failed to finalize block: %v", err)
+	}
+	// The finalized block never moves backwards
+	if err := chain.SetFinalized(blocks[2]); err != nil {
+		t.Fatalf("failed to finalize earlier block: %v", err)
+	}
+	if have := chain.CurrentFinalizedBlock(); have.Hash() != blocks[3].Hash() {
+		t.Fatalf("finalized block mismatch: have #%d, want #%d", have.NumberU64(), blocks[3].NumberU64())
+	}
+	// A heavier fork from below the finalized block is rejected
+	if _, err := chain.InsertChain(fork); !errors.Is(err, errBelowFinalized) {
+		t.Fatalf("reorg error mismatch: have %v, want %v", err, errBelowFinalized)
+	}
+	if have := chain.CurrentBlock(); have.Hash() != blocks[len(blocks)-1].Hash() {
+		t.Fatalf("head block mismatch: have #%d [%x], want #%d", have.NumberU64(), have.Hash(), len(blocks))
+	}
+	chain.Stop()
+
+	// The finalized block is restored after a restart
+	chain, err = NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to recreate tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if have := chain.CurrentFinalizedBlock(); have == nil || have.Hash() != blocks[3].Hash() {
+		t.Fatalf("finalized block not restored: have %v", have)
+	}
+}
+
+// Tests that explicit rewinds can't cross the finalized block, unless allowed
+// for disaster recovery.
+func TestFinalizedRewindProtection(t *testing.T) {
+	var (
+		engine  = ethash.NewFaker()
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 8, nil)
+	fork, _ := GenerateChain(gspec.Config, blocks[1], engine, db, 2, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+	})
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.SnapshotLimit = 0
+	cacheConfig.TrieDirtyDisabled = true
+	chain, err := NewBlockChain(db, &cacheConfig, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert side chain: %v", err)
+	}
+	if err := chain.SetFinalized(blocks[3]); err != nil {
+		t.Fatalf("failed to finalize block: %v", err)
+	}
+	// Rewinds and reorgs below the finalized block are rejected
+	if err := chain.SetHead(blocks[2].NumberU64()); !errors.Is(err, errBelowFinalized) {
+		t.Fatalf("rewind error mismatch: have %v, want %v", err, errBelowFinalized)
+	}
+	if err := chain.SetL2Head(fork[1].Hash()); !errors.Is(err, errBelowFinalized) {
+		t.Fatalf("reorg error mismatch: have %v, want %v", err, errBelowFinalized)
+	}
+	if have := chain.CurrentBlock(); have.Hash() != blocks[7].Hash() {
+		t.Fatalf("head block mismatch: have #%d, want #%d", have.NumberU64(), blocks[7].NumberU64())
+	}
+	// Setting the head above the finalized block retains the dropped blocks
+	if err := chain.SetL2Head(blocks[5].Hash()); err != nil {
+		t.Fatalf("failed to set head: %v", err)
+	}
+	if have := chain.CurrentBlock(); have.Hash() != blocks[5].Hash() {
+		t.Fatalf("head block mismatch: have #%d, want #%d", have.NumberU64(), blocks[5].NumberU64())
+	}
+	if have := chain.CurrentHeader(); have.Hash() != blocks[5].Hash() {
+		t.Fatalf("head header mismatch: have #%d, want #%d", have.Number, blocks[5].NumberU64())
+	}
+	if hash := chain.GetCanonicalHash(blocks[6].NumberU64()); hash != (common.Hash{}) {
+		t.Fatalf("dropped block still canonical")
+	}
+	if chain.GetBlockByHash(blocks[7].Hash()) == nil {
+		t.Fatalf("dropped block deleted")
+	}
+	if err := chain.SetL2Head(blocks[7].Hash()); err != nil {
+		t.Fatalf("failed to restore head: %v", err)
+	}
+	if hash := chain.GetCanonicalHash(blocks[6].NumberU64()); hash != blocks[6].Hash() {
+		t.Fatalf("restored block not canonical")
+	}
+	// Crossing the finalized block is possible if explicitly allowed
+	chain.cacheConfig.AllowFinalizedRewind = true
+	if err := chain.SetHead(blocks[2].NumberU64()); err != nil {
+		t.Fatalf("failed to rewind below finalized block: %v", err)
+	}
+	if chain.CurrentFinalizedBlock() != nil {
+		t.Fatalf("finalized block retained after rewind")
+	}
+}
+
+func TestWithdrawTrieTracking(t *testing.T) {
+	var (
+		// Stub message queue emitting AppendMessage(index, hash) from the calldata
+		topic = withdrawtrie.AppendMessageEventTopic
+		code  = append(append([]byte{
+			byte(vm.PUSH1), 64, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CALLDATACOPY),
+			byte(vm.PUSH32)}, topic[:]...),
+			byte(vm.PUSH1), 64, byte(vm.PUSH1), 0, byte(vm.LOG1), byte(vm.STOP),
+		)
+		config = *params.TestChainConfig
+		engine = ethash.NewFaker()
+		db     = rawdb.NewMemoryDatabase()
+
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: &config,
+			Alloc: GenesisAlloc{
+				address:                    {Balance: big.NewInt(1000000000000000)},
+				rcfg.L2MessageQueueAddress: {Code: code, Balance: big.NewInt(0)},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	config.Scroll.MaxTxPerBlock = nil
+
+	message := func(index uint64, seed byte) (common.Hash, []byte) {
+		hash := crypto.Keccak256Hash([]byte{seed, byte(index)})
+		return hash, append(common.BigToHash(new(big.Int).SetUint64(index)).Bytes(), hash[:]...)
+	}
+	// Chain A sends messages 0 and 1 in block 1 and message 2 in block 2, chain B
+	// forks off at block 1 and sends messages 2 and 3 in blocks 2 and 3
+	generate := func(parent *types.Block, n int, seed byte, counts []int, first uint64, nonce uint64) []*types.Block {
+		blocks, _ := GenerateChain(gspec.Config, parent, engine, db, n, func(i int, b *BlockGen) {
+			b.SetCoinbase(common.Address{seed})
+			for j := 0; j < counts[i]; j++ {
+				_, data := message(first, seed)
+				tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{
+					Nonce:    nonce,
+					To:       &rcfg.L2MessageQueueAddress,
+					Gas:      100000,
+					GasPrice: b.header.BaseFee,
+					Data:     data,
+				})
+				b.AddTx(tx)
+				first++
+				nonce++
+			}
+		})
+		return blocks
+	}
+	chainA := generate(genesis, 2, 0xa, []int{2, 1}, 0, 0)
+	chainB := generate(chainA[0], 2, 0xb, []int{1, 1}, 2, 2)
+
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.WithdrawTrie = true
+	chain, err := NewBlockChain(diskdb, &cacheConfig, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	check := func(index uint64, seed byte, head *types.Block) {
+		t.Helper()
+		header, proof, err := chain.WithdrawalProof(index)
+		if err != nil {
+			t.Fatalf("message %d: failed to prove: %v", index, err)
+		}
+		if header.Hash() != head.Hash() {
+			t.Fatalf("message %d: head mismatch: have %x, want %x", index, header.Hash(), head.Hash())
+		}
+		if hash, _ := message(index, seed); proof.MessageHash != hash {
+			t.Fatalf("message %d: hash mismatch: have %x, want %x", index, proof.MessageHash, hash)
+		}
+		if !proof.Verify() {
+			t.Fatalf("message %d: invalid proof", index)
+		}
+	}
+	if n, err := chain.InsertChain(chainA); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	check(0, 0xa, chainA[1])
+	check(2, 0xa, chainA[1])
+	if _, _, err := chain.WithdrawalProof(3); err != withdrawtrie.ErrUnknownMessage {
+		t.Fatalf("error mismatch: have %v, want %v", err, withdrawtrie.ErrUnknownMessage)
+	}
+	if n, err := chain.InsertChain(chainB); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	check(1, 0xa, chainB[1])
+	check(2, 0xb, chainB[1])
+	check(3, 0xb, chainB[1])
+}
+
+// Tests that a clean shutdown leaves a marker pointing to the persisted head,
+// which is consumed by the next startup.
+func TestCleanShutdownMarker(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+		genesis = gspec.MustCommit(db)
+		engine  = ethash.NewFaker()
+	)
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, engine, db, 4, nil)
+
+	chain, err := NewBlockChain(db, nil, params.TestChainConfig, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if marker := rawdb.ReadCleanShutdownMarker(db); marker != (common.Hash{}) {
+		t.Fatalf("clean shutdown marker present while running: %x", marker)
+	}
+	chain.Stop()
+	if marker, head := rawdb.ReadCleanShutdownMarker(db), blocks[len(blocks)-1].Hash(); marker != head {
+		t.Fatalf("clean shutdown marker mismatch: have %x, want %x", marker, head)
+	}
+	// Restart the chain, the marker must be consumed
+	chain, err = NewBlockChain(db, nil, params.TestChainConfig, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to recreate tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if marker := rawdb.ReadCleanShutdownMarker(db); marker != (common.Hash{}) {
+		t.Fatalf("clean shutdown marker not consumed on startup: %x", marker)
+	}
+}
+
+// Tests that the indices maintained alongside the chain are rewound to the head
+// block on startup after an unclean shutdown.
+func TestRecoverIndicesAfterUncleanShutdown(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+		genesis = gspec.MustCommit(db)
+		engine  = ethash.NewFaker()
+	)
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, engine, db, 4, nil)
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.LogIndex = true
+	cacheConfig.WithdrawTrie = true
+
+	chain, err := NewBlockChain(db, &cacheConfig, params.TestChainConfig, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+
+	// Simulate a crash that left the log index covering blocks above the head
+	rawdb.DeleteCleanShutdownMarker(db)
+	rawdb.WriteLogIndexNext(db, 10)
+
+	chain, err = NewBlockChain(db, &cacheConfig, params.TestChainConfig, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to recreate tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, next, _ := rawdb.ReadLogIndexRange(db); next != 5 {
+		t.Fatalf("log index next mismatch: have %d, want 5", next)
+	}
+	if count, ok := rawdb.ReadWithdrawTrieCount(db, blocks[3].Hash()); !ok || count != 0 {
+		t.Fatalf("withdraw trie count mismatch: have %d (%v), want 0", count, ok)
+	}
+}
+
+// Tests that system transactions are executed fee-free by the system address at
+// the top of a block, and that misplaced ones are rejected.
+func TestSystemTransactions(t *testing.T) {
+	var (
+		contract = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		code     = []byte{byte(vm.CALLER), byte(vm.PUSH1), 0x00, byte(vm.SSTORE)} // Store the caller in slot 0
+
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		config  = *params.TestChainConfig
+		engine  = ethash.NewFaker()
+		db      = rawdb.NewMemoryDatabase()
+	)
+	config.Scroll.MaxTxPerBlock = nil
+	config.Scroll.SystemTx = &params.SystemTxConfig{Block: common.Big0, Contracts: []common.Address{contract}}
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			address:  {Balance: big.NewInt(1000000000000000)},
+			contract: {Code: code, Balance: common.Big0},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+
+	addTx := func(b *BlockGen) {
+		tx := types.NewTransaction(b.TxNonce(address), address, big.NewInt(0), 50000, b.header.BaseFee, nil)
+		signed, _ := types.SignTx(tx, types.HomesteadSigner{}, key)
+		b.AddTx(signed)
+	}
+	blocks, _ := GenerateChain(&config, genesis, engine, db, 1, func(i int, b *BlockGen) {
+		b.AddTx(types.NewSystemTx(b.Number().Uint64(), contract, nil))
+		addTx(b)
+	})
+	chain, err := NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	receipts := chain.GetReceiptsByHash(blocks[0].Hash())
+	if receipts[0].Type != types.SystemTxType || receipts[0].Status != types.ReceiptStatusSuccessful || receipts[0].GasUsed != 0 {
+		t.Fatalf("system receipt mismatch: type %d, status %d, gas used %d", receipts[0].Type, receipts[0].Status, receipts[0].GasUsed)
+	}
+	if blocks[0].GasUsed() != params.TxGas {
+		t.Fatalf("block gas used mismatch: have %d, want %d", blocks[0].GasUsed(), params.TxGas)
+	}
+	state, _ := chain.State()
+	if caller := common.BytesToAddress(state.GetState(contract, common.Hash{}).Bytes()); caller != types.SystemAddress {
+		t.Fatalf("system caller mismatch: have %x, want %x", caller, types.SystemAddress)
+	}
+	// System transactions must precede all others, belong to their block and
+	// only call system contracts
+	tests := []struct {
+		gen  func(b *BlockGen)
+		want error
+	}{
+		{func(b *BlockGen) { addTx(b); b.AddTx(types.NewSystemTx(b.Number().Uint64(), contract, nil)) }, types.ErrSystemTxPosition},
+		{func(b *BlockGen) { b.AddTx(types.NewSystemTx(b.Number().Uint64()+1, contract, nil)) }, types.ErrSystemTxNonce},
+		{func(b *BlockGen) { b.AddTx(types.NewSystemTx(b.Number().Uint64(), address, nil)) }, types.ErrSystemTxTarget},
+	}
+	for i, tt := range tests {
+		invalid, _ := GenerateChain(&config, blocks[0], engine, db, 1, func(_ int, b *BlockGen) { tt.gen(b) })
+		if _, err := chain.InsertChain(invalid); !errors.Is(err, tt.want) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.want)
+		}
+	}
+}
+
+// Tests that L1 fee refunds are paid out of the fee vault, recorded for the
+// refunded transactions and can't be issued twice.
+func TestL1FeeRefunds(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		funds   = big.NewInt(1000000000000000)
+		config  = *params.TestChainConfig
+		vault   = *config.Scroll.FeeVaultAddress
+		engine  = ethash.NewFaker()
+		db      = rawdb.NewMemoryDatabase()
+	)
+	config.Scroll.MaxTxPerBlock = nil
+	config.Scroll.SystemTx = &params.SystemTxConfig{Block: common.Big0, Contracts: []common.Address{rcfg.L1FeeRefundAddress}}
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			address: {Balance: funds},
+			rcfg.L1GasPriceOracleAddress: {
+				Balance: common.Big0,
+				Storage: map[common.Hash]common.Hash{
+					rcfg.L1BaseFeeSlot: common.BigToHash(big.NewInt(1000)),
+					rcfg.ScalarSlot:    common.BigToHash(rcfg.Precision),
+				},
+			},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+
+	var charged *types.Transaction
+	refund := func(amount int64) func(b *BlockGen) {
+		return func(b *BlockGen) {
+			data, _ := types.EncodeL1FeeRefunds([]*types.L1FeeRefund{{TxHash: charged.Hash(), Recipient: address, Amount: big.NewInt(amount)}})
+			b.AddTx(types.NewSystemTx(b.Number().Uint64(), rcfg.L1FeeRefundAddress, data))
+		}
+	}
+	blocks, _ := GenerateChain(&config, genesis, engine, db, 2, func(i int, b *BlockGen) {
+		if i == 0 {
+			tx := types.NewTransaction(b.TxNonce(address), address, big.NewInt(0), 50000, b.header.BaseFee, nil)
+			charged, _ = types.SignTx(tx, types.HomesteadSigner{}, key)
+			b.AddTx(charged)
+			return
+		}
+		refund(1000)(b)
+	})
+	chain, err := NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	fee := chain.GetReceiptsByHash(blocks[0].Hash())[0].L1Fee
+	if fee.Cmp(big.NewInt(1000)) <= 0 {
+		t.Fatalf("L1 fee too low to refund: %v", fee)
+	}
+	state, _ := chain.State()
+	before := state.GetBalance(address)
+
+	if n, err := chain.InsertChain(blocks[1:]); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	state, _ = chain.State()
+	if have, want := state.GetBalance(address), new(big.Int).Add(before, big.NewInt(1000)); have.Cmp(want) != 0 {
+		t.Fatalf("refunded balance mismatch: have %v, want %v", have, want)
+	}
+	if record := chain.GetL1FeeRefund(charged.Hash()); record == nil || record.BlockHash != blocks[1].Hash() || record.Amount.Int64() != 1000 {
+		t.Fatalf("refund record mismatch: have %+v", record)
+	}
+	if state.GetBalance(vault).Sign() < 0 {
+		t.Fatalf("fee vault overdrawn")
+	}
+	// Refunding again, or more than charged, is rejected
+	for i, gen := range []func(b *BlockGen){refund(1), refund(fee.Int64() + 1)} {
+		parent := blocks[i]
+		invalid, _ := GenerateChain(&config, parent, engine, db, 1, func(_ int, b *BlockGen) { gen(b) })
+		if _, err := chain.InsertChain(invalid); !errors.Is(err, ErrInvalidL1FeeRefund) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, ErrInvalidL1FeeRefund)
+		}
+	}
+}
+
+func TestTxIndexProgress(t *testing.T) {
+	var (
+		gendb   = rawdb.NewMemoryDatabase()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{address: {Balance: big.NewInt(params.Ether)}}}
+		genesis = gspec.MustCommit(gendb)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), gendb, 64, func(i int, block *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{0x00}, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil), signer, key)
+		block.AddTx(tx)
+	})
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+
+	limit := uint64(32)
+	chain, err := NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, &limit)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	var progress *TxIndexProgress
+	for i := 0; i < 100; i++ {
+		if progress, err = chain.TxIndexProgress(); err == nil && progress.Done() && progress.Tail == 33 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("failed to get progress: %v", err)
+	}
+	want := &TxIndexProgress{Tail: 33, Head: 64, Limit: 32, Indexed: 32}
+	if *progress != *want {
+		t.Fatalf("progress mismatch: have %+v, want %+v", progress, want)
+	}
+	// Raising the limit requires indexing the blocks down to the new tail
+	chain.txLookupLimit = 48
+	if progress, _ = chain.TxIndexProgress(); progress.Remaining != 16 || progress.Done() {
+		t.Fatalf("remaining mismatch: have %d, want 16", progress.Remaining)
+	}
+}
+
+// Tests that the fees of sponsored transactions are charged to their fee payer,
+// recorded in their receipts, and that sponsored transactions are rejected
+// before their fork.
+func TestSponsoredTransactions(t *testing.T) {
+	var (
+		senderKey, _ = crypto.GenerateKey()
+		payerKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sender       = crypto.PubkeyToAddress(senderKey.PublicKey)
+		payer        = crypto.PubkeyToAddress(payerKey.PublicKey)
+		funds        = big.NewInt(1000000000000000)
+		config       = *params.TestChainConfig
+		engine       = ethash.NewFaker()
+		db           = rawdb.NewMemoryDatabase()
+	)
+	config.Scroll.MaxTxPerBlock = nil
+	config.Scroll.SponsoredTxBlock = common.Big0
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			sender: {Balance: big.NewInt(1)},
+			payer:  {Balance: funds},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.LatestSigner(&config)
+
+	blocks, _ := GenerateChain(&config, genesis, engine, db, 1, func(i int, b *BlockGen) {
+		tx := types.MustSignNewTx(senderKey, signer, &types.SponsoredTx{
+			ChainID:   config.ChainID,
+			Nonce:     b.TxNonce(sender),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: b.header.BaseFee,
+			Gas:       params.TxGas,
+			To:        &common.Address{0xaa},
+			Value:     big.NewInt(1),
+			FeePayer:  payer,
+		})
+		tx, err := types.SignFeePayer(tx, signer, payerKey)
+		if err != nil {
+			t.Fatalf("failed to sign as fee payer: %v", err)
+		}
+		b.AddTx(tx)
+	})
+	chain, err := NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	receipt := chain.GetReceiptsByHash(blocks[0].Hash())[0]
+	if receipt.Status != types.ReceiptStatusSuccessful || receipt.FeePayer == nil || *receipt.FeePayer != payer {
+		t.Fatalf("receipt mismatch: status %d, fee payer %v", receipt.Status, receipt.FeePayer)
+	}
+	state, _ := chain.State()
+	if balance := state.GetBalance(sender); balance.Sign() != 0 {
+		t.Fatalf("sender charged beyond the value: balance %v", balance)
+	}
+	if nonce := state.GetNonce(sender); nonce != 1 {
+		t.Fatalf("sender nonce mismatch: have %d, want 1", nonce)
+	}
+	if balance := state.GetBalance(payer); balance.Cmp(funds) >= 0 {
+		t.Fatalf("fee payer not charged: balance %v", balance)
+	}
+	// The same block is invalid before the fork
+	unforked := *params.TestChainConfig
+	unforked.Scroll.MaxTxPerBlock = nil
+	db = rawdb.NewMemoryDatabase()
+	(&Genesis{Config: &unforked, Alloc: gspec.Alloc}).MustCommit(db)
+	chain, err = NewBlockChain(db, nil, &unforked, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
+
+// Tests that transactions using nonce keys advance the nonce of their key only,
+// in parallel with the account nonce, and are rejected before their fork.
+func TestNonceKeyTransactions(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		config  = *params.TestChainConfig
+		engine  = ethash.NewFaker()
+		db      = rawdb.NewMemoryDatabase()
+	)
+	config.Scroll.MaxTxPerBlock = nil
+	config.Scroll.NonceKeyBlock = common.Big0
+	gspec := &Genesis{
+		Config: &config,
+		Alloc:  GenesisAlloc{address: {Balance: big.NewInt(1000000000000000)}},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.LatestSigner(&config)
+
+	blocks, _ := GenerateChain(&config, genesis, engine, db, 1, func(i int, b *BlockGen) {
+		for _, lane := range []struct{ key, nonce uint64 }{{1, 0}, {2, 0}, {1, 1}, {0, 0}} {
+			b.AddTx(types.MustSignNewTx(key, signer, &types.NonceKeyTx{
+				ChainID:   config.ChainID,
+				NonceKey:  lane.key,
+				Nonce:     lane.nonce,
+				GasTipCap: big.NewInt(1),
+				GasFeeCap: b.header.BaseFee,
+				Gas:       params.TxGas,
+				To:        &common.Address{0xaa},
+			}))
+		}
+	})
+	chain, err := NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	state, _ := chain.State()
+	for key, want := range map[uint64]uint64{0: 1, 1: 2, 2: 1, 3: 0} {
+		if nonce := state.GetNonceKey(address, key); nonce != want {
+			t.Errorf("nonce key %d mismatch: have %d, want %d", key, nonce, want)
+		}
+	}
+	if nonce := state.GetNonce(address); nonce != 1 {
+		t.Errorf("account nonce mismatch: have %d, want 1", nonce)
+	}
+	// The same block is invalid before the fork
+	unforked := *params.TestChainConfig
+	unforked.Scroll.MaxTxPerBlock = nil
+	db = rawdb.NewMemoryDatabase()
+	(&Genesis{Config: &unforked, Alloc: gspec.Alloc}).MustCommit(db)
+	chain, err = NewBlockChain(db, nil, &unforked, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
+
+// Tests that set-code transactions delegate the code of the authorities whose
+// authorization is valid, calls to them then running the code of the delegate
+// in their own context, and that they're rejected before their fork.
+func TestSetCodeTransactions(t *testing.T) {
+	var (
+		senderKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		authKey, _   = crypto.GenerateKey()
+		staleKey, _  = crypto.GenerateKey()
+		sender       = crypto.PubkeyToAddress(senderKey.PublicKey)
+		authority    = crypto.PubkeyToAddress(authKey.PublicKey)
+		stale        = crypto.PubkeyToAddress(staleKey.PublicKey)
+		delegate     = common.HexToAddress("0xdeadbeef")
+		config       = *params.TestChainConfig
+		engine       = ethash.NewFaker()
+		db           = rawdb.NewMemoryDatabase()
+	)
+	config.Scroll.MaxTxPerBlock = nil
+	config.Scroll.SetCodeBlock = common.Big0
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			sender: {Balance: big.NewInt(1000000000000000)},
+			// SSTORE(0, 1)
+			delegate: {Code: common.FromHex("0x600160005500"), Balance: common.Big0},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.LatestSigner(&config)
+
+	sign := func(key *ecdsa.PrivateKey, nonce uint64) types.SetCodeAuthorization {
+		auth, err := types.SignSetCode(key, types.SetCodeAuthorization{ChainID: config.ChainID, Address: delegate, Nonce: nonce})
+		if err != nil {
+			t.Fatalf("failed to sign authorization: %v", err)
+		}
+		return auth
+	}
+	blocks, _ := GenerateChain(&config, genesis, engine, db, 1, func(i int, b *BlockGen) {
+		b.AddTx(types.MustSignNewTx(senderKey, signer, &types.SetCodeTx{
+			ChainID:   config.ChainID,
+			Nonce:     b.TxNonce(sender),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: b.header.BaseFee,
+			Gas:       500000,
+			To:        authority,
+			Value:     new(big.Int),
+			AuthList:  []types.SetCodeAuthorization{sign(authKey, 0), sign(staleKey, 1)},
+		}))
+	})
+	chain, err := NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	if receipt := chain.GetReceiptsByHash(blocks[0].Hash())[0]; receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("set-code transaction failed")
+	}
+	state, _ := chain.State()
+	if target, ok := types.ParseDelegation(state.GetCode(authority)); !ok || target != delegate {
+		t.Fatalf("authority code mismatch: have %x", state.GetCode(authority))
+	}
+	if nonce := state.GetNonce(authority); nonce != 1 {
+		t.Fatalf("authority nonce mismatch: have %d, want 1", nonce)
+	}
+	if value := state.GetState(authority, common.Hash{}); value != common.BigToHash(common.Big1) {
+		t.Fatalf("delegated code not run by the authority: slot %x", value)
+	}
+	if code := state.GetCode(stale); len(code) != 0 {
+		t.Fatalf("authorization with a stale nonce applied: code %x", code)
+	}
+	// The same block is invalid before the fork
+	unforked := *params.TestChainConfig
+	unforked.Scroll.MaxTxPerBlock = nil
+	db = rawdb.NewMemoryDatabase()
+	(&Genesis{Config: &unforked, Alloc: gspec.Alloc}).MustCommit(db)
+	chain, err = NewBlockChain(db, nil, &unforked, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
+
+// Tests that blocks produced under a gas schedule are imported by nodes running
+// the same chain config, and rejected by nodes that miss the schedule.
+func TestGasScheduleBlocks(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xc0de")
+		config   = *params.TestChainConfig
+		engine   = ethash.NewFaker()
+		db       = rawdb.NewMemoryDatabase()
+	)
+	config.Scroll.MaxTxPerBlock = nil
+	config.Scroll.GasSchedules = []params.GasScheduleConfig{
+		{Block: big.NewInt(2), Opcodes: map[string]uint64{"KECCAK256": 1000, "SLOAD": 500}},
+	}
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			address: {Balance: big.NewInt(1000000000000000)},
+			// PUSH1 0 PUSH1 0 SHA3 PUSH1 0 SLOAD STOP
+			contract: {Code: common.FromHex("0x600060002060005400"), Balance: common.Big0},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.LatestSigner(&config)
+
+	blocks, _ := GenerateChain(&config, genesis, engine, db, 3, func(i int, b *BlockGen) {
+		b.AddTx(types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   config.ChainID,
+			Nonce:     uint64(i),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: b.header.BaseFee,
+			Gas:       100000,
+			To:        &contract,
+		}))
+	})
+	if blocks[1].GasUsed()-blocks[0].GasUsed() != 1000+500-params.Sha3Gas {
+		t.Fatalf("repricing mismatch: gas used %d before, %d after", blocks[0].GasUsed(), blocks[1].GasUsed())
+	}
+	// A follower with the same config agrees on the blocks
+	db = rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+	chain, err := NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	// A follower missing the schedule rejects the first repriced block
+	unscheduled := config
+	unscheduled.Scroll.GasSchedules = nil
+	db = rawdb.NewMemoryDatabase()
+	(&Genesis{Config: &unscheduled, Alloc: gspec.Alloc}).MustCommit(db)
+	chain, err = NewBlockChain(db, nil, &unscheduled, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err == nil || n != 1 {
+		t.Fatalf("insert mismatch: have block %d, error %v, want block 1 rejected", n, err)
+	}
+}
+
+// Tests that the code size limits of the chain config are enforced on the code
+// of contract creations, on the init code of creation transactions, and on the
+// init code of CREATE.
+func TestCodeSizeLimits(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		// PUSH1 <size> PUSH1 0 PUSH1 0 CREATE PUSH1 0 SSTORE STOP
+		fitting  = common.HexToAddress("0xc0de01")
+		oversize = common.HexToAddress("0xc0de02")
+		config   = *params.TestChainConfig
+		engine   = ethash.NewFaker()
+		db       = rawdb.NewMemoryDatabase()
+	)
+	config.Scroll.MaxTxPerBlock = nil
+	config.Scroll.CodeSize = &params.CodeSizeConfig{Block: common.Big0, MaxCodeSize: 32, MaxInitCodeSize: 64}
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			address:  {Balance: big.NewInt(params.Ether)},
+			fitting:  {Code: common.FromHex("0x604060006000f060005500"), Balance: common.Big0},
+			oversize: {Code: common.FromHex("0x604160006000f060005500"), Balance: common.Big0},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.LatestSigner(&config)
+
+	// deploy returns an init code of the given size deploying a contract of the
+	// given code size
+	deploy := func(codeSize, initSize int) []byte {
+		code := make([]byte, initSize)
+		copy(code, []byte{byte(vm.PUSH1), byte(codeSize), byte(vm.PUSH1), 0, byte(vm.RETURN)})
+		return code
+	}
+	newTx := func(b *BlockGen, to *common.Address, data []byte) *types.Transaction {
+		return types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   config.ChainID,
+			Nonce:     b.TxNonce(address),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: b.header.BaseFee,
+			Gas:       300000,
+			To:        to,
+			Data:      data,
+		})
+	}
+	blocks, receipts := GenerateChain(&config, genesis, engine, db, 1, func(i int, b *BlockGen) {
+		b.AddTx(newTx(b, nil, deploy(32, 64)))
+		b.AddTx(newTx(b, nil, deploy(33, 64)))
+		b.AddTx(newTx(b, &fitting, nil))
+		b.AddTx(newTx(b, &oversize, nil))
+	})
+	for i, want := range []uint64{types.ReceiptStatusSuccessful, types.ReceiptStatusFailed, types.ReceiptStatusSuccessful, types.ReceiptStatusSuccessful} {
+		if status := receipts[0][i].Status; status != want {
+			t.Errorf("tx %d: status mismatch: have %d, want %d", i, status, want)
+		}
+	}
+	chain, err := NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	state, _ := chain.State()
+	if created := state.GetState(fitting, common.Hash{}); created == (common.Hash{}) {
+		t.Errorf("CREATE within the init code limit failed")
+	}
+	if created := state.GetState(oversize, common.Hash{}); created != (common.Hash{}) {
+		t.Errorf("CREATE above the init code limit succeeded: %x", created)
+	}
+	// A creation transaction above the init code limit invalidates its block
+	relaxed := config
+	relaxed.Scroll.CodeSize = &params.CodeSizeConfig{Block: common.Big0, MaxCodeSize: 32, MaxInitCodeSize: 65}
+	db = rawdb.NewMemoryDatabase()
+	genesis = (&Genesis{Config: &relaxed, Alloc: gspec.Alloc}).MustCommit(db)
+	blocks, _ = GenerateChain(&relaxed, genesis, engine, db, 1, func(i int, b *BlockGen) {
+		b.AddTx(newTx(b, nil, deploy(32, 65)))
+	})
+	db = rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+	chain, err = NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); !errors.Is(err, ErrMaxInitCodeSizeExceeded) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrMaxInitCodeSizeExceeded)
+	}
+}
+
+// Tests that transactions to the addresses flagged by the denylist registry
+// invalidate their block from the denylist fork on.
+func TestDenylistTransactions(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		registry = common.HexToAddress("0xde71")
+		denied   = common.Address{0xaa}
+		config   = *params.TestChainConfig
+		engine   = ethash.NewFaker()
+		db       = rawdb.NewMemoryDatabase()
+	)
+	config.Scroll.MaxTxPerBlock = nil
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			address: {Balance: big.NewInt(params.Ether)},
+			registry: {
+				Code:    []byte{byte(vm.STOP)},
+				Storage: map[common.Hash]common.Hash{denylist.Slot(0, denied): common.BigToHash(common.Big1)},
+				Balance: common.Big0,
+			},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.LatestSigner(&config)
+
+	// Transfer to an allowed address in the first block and to the denied one
+	// in the second
+	blocks, _ := GenerateChain(&config, genesis, engine, db, 2, func(i int, b *BlockGen) {
+		to := common.Address{0xbb}
+		if i == 1 {
+			to = denied
+		}
+		b.AddTx(types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   config.ChainID,
+			Nonce:     uint64(i),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: b.header.BaseFee,
+			Gas:       params.TxGas,
+			To:        &to,
+			Value:     big.NewInt(1),
+		}))
+	})
+	forked := config
+	forked.Scroll.Denylist = &params.DenylistConfig{Block: common.Big1, Registry: registry}
+
+	db = rawdb.NewMemoryDatabase()
+	(&Genesis{Config: &forked, Alloc: gspec.Alloc}).MustCommit(db)
+	chain, err := NewBlockChain(db, nil, &forked, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); n != 1 || !errors.Is(err, ErrDeniedRecipient) {
+		t.Fatalf("insert mismatch: have block %d, error %v, want block 1 rejected with %v", n, err, ErrDeniedRecipient)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+)
+
+// errTxIndexUninitialized is returned if the transaction indexer hasn't recorded
+// the tail of the indexed blocks yet.
+var errTxIndexUninitialized = errors.New("transaction indexing not initialized")
+
+// TxIndexProgress is the progress of the transaction indexer towards indexing
+// the recent blocks covered by the txlookuplimit.
+type TxIndexProgress struct {
+	Tail      uint64 // Oldest block whose transactions are indexed
+	Head      uint64 // Current head block
+	Limit     uint64 // Number of recent blocks to index, 0 for all
+	Indexed   uint64 // Number of blocks indexed
+	Remaining uint64 // Number of blocks left to index
+}
+
+// Done returns whether all the blocks covered by the limit are indexed.
+func (p *TxIndexProgress) Done() bool {
+	return p.Remaining == 0
+}
+
+// TxIndexProgress returns the progress of the background transaction indexer.
+// Blocks whose transactions aren't indexed, either because they're beyond the
+// limit or because indexing is in progress, can't be looked up by transaction
+// hash.
+func (bc *BlockChain) TxIndexProgress() (*TxIndexProgress, error) {
+	tail := rawdb.ReadTxIndexTail(bc.db)
+	if tail == nil {
+		return nil, errTxIndexUninitialized
+	}
+	progress := &TxIndexProgress{
+		Tail:  *tail,
+		Head:  bc.CurrentBlock().NumberU64(),
+		Limit: bc.txLookupLimit,
+	}
+	if progress.Head >= progress.Tail {
+		progress.Indexed = progress.Head - progress.Tail + 1
+	}
+	var wanted uint64 // Oldest block to index
+	if progress.Limit != 0 && progress.Head >= progress.Limit {
+		wanted = progress.Head - progress.Limit + 1
+	}
+	if progress.Tail > wanted {
+		progress.Remaining = progress.Tail - wanted
+	}
+	return progress, nil
+}
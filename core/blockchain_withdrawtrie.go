@@ -0,0 +1,98 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
+)
+
+// initWithdrawTrie checks whether the withdraw trie covers the current head.
+func (bc *BlockChain) initWithdrawTrie() {
+	head := bc.CurrentBlock()
+	if head.NumberU64() == 0 {
+		return
+	}
+	if _, ok := rawdb.ReadWithdrawTrieCount(bc.db, head.Hash()); !ok {
+		log.Warn("Withdraw trie not tracked from genesis, proofs unavailable", "head", head.NumberU64())
+	}
+}
+
+// writeWithdrawTrie appends the L2 to L1 messages sent by a block becoming the
+// canonical head to the withdraw trie, discarding the messages of any previous
+// chain above its parent. The caller must hold the withdraw trie lock.
+func (bc *BlockChain) writeWithdrawTrie(db ethdb.KeyValueWriter, block *types.Block) {
+	var count uint64
+	if block.NumberU64() > 1 {
+		parent, ok := rawdb.ReadWithdrawTrieCount(bc.db, block.ParentHash())
+		if !ok {
+			return
+		}
+		count = parent
+	}
+	first, hashes := withdrawtrie.MessageHashes(rawdb.ReadRawReceipts(bc.db, block.Hash(), block.NumberU64()))
+	if len(hashes) > 0 && first != count {
+		log.Error("Withdraw message index mismatch", "number", block.Number(), "hash", block.Hash(), "have", first, "want", count)
+		return
+	}
+	tree := withdrawtrie.OpenTree(bc.db, count)
+	for _, hash := range hashes {
+		tree.Append(hash)
+	}
+	tree.Commit(db)
+	rawdb.WriteWithdrawTrieCount(db, block.Hash(), tree.Count())
+}
+
+// checkWithdrawTrie verifies that the withdraw trie root after the given block
+// matches the one maintained by the L2MessageQueue predeploy.
+func (bc *BlockChain) checkWithdrawTrie(block *types.Block, statedb *state.StateDB) {
+	bc.withdrawTrieLock.RLock()
+	defer bc.withdrawTrieLock.RUnlock()
+
+	count, ok := rawdb.ReadWithdrawTrieCount(bc.db, block.Hash())
+	if !ok {
+		return
+	}
+	have, want := withdrawtrie.OpenTree(bc.db, count).Root(), withdrawtrie.ReadWTRSlot(rcfg.L2MessageQueueAddress, statedb)
+	if have != want {
+		log.Error("Withdraw trie root mismatch", "number", block.Number(), "hash", block.Hash(), "have", have, "want", want)
+	}
+}
+
+// WithdrawalProof returns the proof of the L2 to L1 message with the given index
+// against the withdraw trie root of the current head block, along with the head
+// the proof was generated for.
+func (bc *BlockChain) WithdrawalProof(index uint64) (*types.Header, *withdrawtrie.Proof, error) {
+	bc.withdrawTrieLock.RLock()
+	defer bc.withdrawTrieLock.RUnlock()
+
+	head := bc.CurrentBlock()
+	count, ok := rawdb.ReadWithdrawTrieCount(bc.db, head.Hash())
+	if !ok && head.NumberU64() > 0 {
+		return nil, nil, withdrawtrie.ErrUntracked
+	}
+	proof, err := withdrawtrie.OpenTree(bc.db, count).Prove(index)
+	if err != nil {
+		return nil, nil, err
+	}
+	return head.Header(), proof, nil
+}
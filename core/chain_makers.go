@@ -23,11 +23,13 @@ import (
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/consensus"
 	"github.com/scroll-tech/go-ethereum/consensus/misc"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/core/vm"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/trie"
 )
 
 // BlockGen creates blocks for testing.
@@ -242,7 +244,16 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		return nil, nil
 	}
 	for i := 0; i < n; i++ {
-		statedb, err := state.New(parent.Root(), state.NewDatabase(db), nil)
+		// Past the zktrie cutover, the blocks commit to the state with zktrie,
+		// starting from the migrated state of the last block before
+		sdb, root := state.NewDatabase(db), parent.Root()
+		if config.Scroll.IsZktrieCutover(new(big.Int).Add(parent.Number(), common.Big1)) {
+			sdb = state.NewDatabaseWithConfig(db, &trie.Config{Zktrie: true})
+			if zkRoot, migrated := rawdb.ReadZktrieRoot(db, root); migrated {
+				root = zkRoot
+			}
+		}
+		statedb, err := state.New(root, sdb, nil)
 		if err != nil {
 			panic(err)
 		}
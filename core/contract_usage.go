@@ -0,0 +1,70 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// contractUsage attributes the gas used by the transactions of a block to the
+// contracts they call or create, along with the storage growth of the contracts
+// and the code size of the ones deployed. Transactions to accounts without code
+// are left out. The usage is sorted by contract address.
+func contractUsage(block *types.Block, receipts types.Receipts, statedb *state.StateDB) []*types.ContractUsage {
+	usage := make(map[common.Address]*types.ContractUsage)
+	get := func(addr common.Address) *types.ContractUsage {
+		if usage[addr] == nil {
+			usage[addr] = &types.ContractUsage{Address: addr}
+		}
+		return usage[addr]
+	}
+	for i, tx := range block.Transactions() {
+		addr := receipts[i].ContractAddress
+		if to := tx.To(); to != nil {
+			addr = *to
+		}
+		if statedb.GetCodeSize(addr) == 0 {
+			continue
+		}
+		contract := get(addr)
+		contract.Transactions++
+		contract.GasUsed += receipts[i].GasUsed
+	}
+	for addr, growth := range statedb.StorageGrowth() {
+		contract := get(addr)
+		contract.SlotsCreated += growth.SlotsCreated
+		contract.SlotsCleared += growth.SlotsCleared
+	}
+	for _, creation := range statedb.Creations() {
+		if size := statedb.GetCodeSize(creation.Address); size > 0 {
+			get(creation.Address).CodeSize = uint64(size)
+		}
+	}
+	list := make([]*types.ContractUsage, 0, len(usage))
+	for _, contract := range usage {
+		list = append(list, contract)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return bytes.Compare(list[i].Address[:], list[j].Address[:]) < 0
+	})
+	return list
+}
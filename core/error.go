@@ -32,6 +32,10 @@ var (
 	// ErrNoGenesis is returned when there is no Genesis Block.
 	ErrNoGenesis = errors.New("genesis not found in chain")
 
+	// ErrStateNotMigrated is returned if the first block after the zktrie cutover
+	// is to be executed before the state of its parent was migrated to zktrie.
+	ErrStateNotMigrated = errors.New("parent state not migrated to zktrie")
+
 	errSideChainReceipts = errors.New("side blocks can't be accepted as ancient chain data")
 )
 
@@ -74,10 +78,18 @@ var (
 	// than required to start the invocation.
 	ErrIntrinsicGas = errors.New("intrinsic gas too low")
 
+	// ErrMaxInitCodeSizeExceeded is returned if a contract creation transaction
+	// carries more init code than the chain config allows.
+	ErrMaxInitCodeSizeExceeded = errors.New("max initcode size exceeded")
+
 	// ErrTxTypeNotSupported is returned if a transaction is not supported in the
 	// current network configuration.
 	ErrTxTypeNotSupported = types.ErrTxTypeNotSupported
 
+	// ErrInvalidFeePayer is returned if the fee payer signature of a sponsored
+	// transaction is invalid.
+	ErrInvalidFeePayer = types.ErrInvalidFeePayer
+
 	// ErrTipAboveFeeCap is a sanity error to ensure no one is able to specify a
 	// transaction with a tip higher than the total fee cap.
 	ErrTipAboveFeeCap = errors.New("max priority fee per gas higher than max fee per gas")
@@ -96,4 +108,15 @@ var (
 
 	// ErrSenderNoEOA is returned if the sender of a transaction is a contract.
 	ErrSenderNoEOA = errors.New("sender not an eoa")
+
+	// ErrDeniedRecipient is returned if the recipient of a transaction is flagged
+	// by the denylist registry.
+	ErrDeniedRecipient = errors.New("recipient denied by registry")
+
+	// ErrEmptyAuthList is returned if a set-code transaction carries no
+	// authorization.
+	ErrEmptyAuthList = errors.New("set-code transaction with empty auth list")
+
+	// ErrSetCodeTxCreate is returned if a set-code transaction creates a contract.
+	ErrSetCodeTxCreate = errors.New("set-code transaction cannot be used to create contract")
 )
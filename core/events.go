@@ -24,6 +24,13 @@ import (
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// DroppedTxsEvent is posted when transactions leave the transaction pool without
+// being included, all for the same reason (see TxDropExpired and friends).
+type DroppedTxsEvent struct {
+	Txs    []*types.Transaction
+	Reason string
+}
+
 // NewMinedBlockEvent is posted when a block has been imported.
 type NewMinedBlockEvent struct{ Block *types.Block }
 
@@ -41,3 +48,20 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// EquivocationEvent is posted when a proposer is caught signing two different
+// blocks at the same height.
+type EquivocationEvent struct{ Evidence *types.EquivocationEvidence }
+
+// AssembledTxEvent is posted when a transaction is applied to a block being
+// assembled, before the block is sealed.
+type AssembledTxEvent struct {
+	Number     uint64      // Number of the block being assembled
+	ParentHash common.Hash // Parent of the block being assembled
+	Index      int         // Position of the transaction in the block
+	Tx         *types.Transaction
+	Receipt    *types.Receipt
+}
+
+// FinalizedEvent is posted when the finalized block advances.
+type FinalizedEvent struct{ Block *types.Block }
@@ -31,6 +31,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
 	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/log"
@@ -224,6 +225,9 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, override
 	if err := newcfg.CheckConfigForkOrder(); err != nil {
 		return newcfg, common.Hash{}, err
 	}
+	if err := vm.CheckGasSchedules(newcfg); err != nil {
+		return newcfg, common.Hash{}, err
+	}
 	storedcfg := rawdb.ReadChainConfig(db, stored)
 	if storedcfg == nil {
 		log.Warn("Found genesis block without chain config")
@@ -341,6 +345,9 @@ func (g *Genesis) Commit(db ethdb.Database) (*types.Block, error) {
 	if err := config.CheckConfigForkOrder(); err != nil {
 		return nil, err
 	}
+	if err := vm.CheckGasSchedules(config); err != nil {
+		return nil, err
+	}
 	if config.Clique != nil && len(block.Extra()) == 0 {
 		return nil, errors.New("can't start clique chain without signers")
 	}
@@ -352,9 +359,44 @@ func (g *Genesis) Commit(db ethdb.Database) (*types.Block, error) {
 	rawdb.WriteHeadFastBlockHash(db, block.Hash())
 	rawdb.WriteHeadHeaderHash(db, block.Hash())
 	rawdb.WriteChainConfig(db, block.Hash(), config)
+	rawdb.WriteStateSize(db, block.Hash(), g.stateSize())
+
+	// Migrating the state to zktrie needs the preimages of the trie keys
+	if config.Scroll.ZktrieCutoverBlock != nil {
+		rawdb.WritePreimages(db, g.preimages())
+	}
 	return block, nil
 }
 
+// preimages returns the preimages of the account and storage trie keys of the
+// genesis state.
+func (g *Genesis) preimages() map[common.Hash][]byte {
+	preimages := make(map[common.Hash][]byte)
+	for addr, account := range g.Alloc {
+		preimages[crypto.Keccak256Hash(addr.Bytes())] = common.CopyBytes(addr.Bytes())
+		for key := range account.Storage {
+			preimages[crypto.Keccak256Hash(key.Bytes())] = common.CopyBytes(key.Bytes())
+		}
+	}
+	return preimages
+}
+
+// stateSize counts the accounts, storage slots and code bytes of the genesis
+// state, which the state size of later blocks builds on.
+func (g *Genesis) stateSize() *types.StateSize {
+	size := new(types.StateSize)
+	for _, account := range g.Alloc {
+		size.AccountsCreated++
+		size.CodeBytesAdded += uint64(len(account.Code))
+		for _, value := range account.Storage {
+			if value != (common.Hash{}) {
+				size.SlotsCreated++
+			}
+		}
+	}
+	return size
+}
+
 // MustCommit writes the genesis block and state to db, panicking on error.
 // The block is committed as the canonical head block.
 func (g *Genesis) MustCommit(db ethdb.Database) *types.Block {
@@ -0,0 +1,73 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// ReadAccessEpoch retrieves the last epoch in which the account, or its storage
+// slot if slot is non-nil, was accessed.
+func ReadAccessEpoch(db ethdb.KeyValueReader, addr common.Address, slot *common.Hash) (uint64, bool) {
+	data, _ := db.Get(accessEpochKey(addr, slot))
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// WriteAccessEpoch stores the last epoch in which the account, or its storage
+// slot if slot is non-nil, was accessed.
+func WriteAccessEpoch(db ethdb.KeyValueWriter, addr common.Address, slot *common.Hash, epoch uint64) {
+	var enc [8]byte
+	binary.BigEndian.PutUint64(enc[:], epoch)
+	if err := db.Put(accessEpochKey(addr, slot), enc[:]); err != nil {
+		log.Crit("Failed to store access epoch", "err", err)
+	}
+}
+
+// IterateAccessEpochs calls fn with the last access epoch of every tagged
+// account and storage slot from the given address on, ordered by address with
+// the account preceding its slots, until fn returns false.
+func IterateAccessEpochs(db ethdb.Iteratee, start common.Address, fn func(addr common.Address, slot *common.Hash, epoch uint64) bool) error {
+	it := db.NewIterator(accessEpochPrefix, start.Bytes())
+	defer it.Release()
+
+	for it.Next() {
+		key, value := it.Key()[len(accessEpochPrefix):], it.Value()
+		if len(value) != 8 {
+			continue
+		}
+		var slot *common.Hash
+		switch len(key) {
+		case common.AddressLength:
+		case common.AddressLength + common.HashLength:
+			hash := common.BytesToHash(key[common.AddressLength:])
+			slot = &hash
+		default:
+			continue
+		}
+		if !fn(common.BytesToAddress(key[:common.AddressLength]), slot, binary.BigEndian.Uint64(value)) {
+			break
+		}
+	}
+	return it.Error()
+}
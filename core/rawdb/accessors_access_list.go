@@ -0,0 +1,58 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// ReadBlockAccessList retrieves the block-level access list recorded while
+// executing the block with the given hash.
+func ReadBlockAccessList(db ethdb.KeyValueReader, hash common.Hash) types.BlockAccessList {
+	data, _ := db.Get(blockAccessListKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var list types.BlockAccessList
+	if err := rlp.DecodeBytes(data, &list); err != nil {
+		log.Error("Invalid block access list RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return list
+}
+
+// WriteBlockAccessList stores the block-level access list of a block.
+func WriteBlockAccessList(db ethdb.KeyValueWriter, hash common.Hash, list types.BlockAccessList) {
+	data, err := rlp.EncodeToBytes(list)
+	if err != nil {
+		log.Crit("Failed to encode block access list", "err", err)
+	}
+	if err := db.Put(blockAccessListKey(hash), data); err != nil {
+		log.Crit("Failed to store block access list", "err", err)
+	}
+}
+
+// DeleteBlockAccessList removes the block-level access list of a block.
+func DeleteBlockAccessList(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(blockAccessListKey(hash)); err != nil {
+		log.Crit("Failed to delete block access list", "err", err)
+	}
+}
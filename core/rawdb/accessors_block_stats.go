@@ -0,0 +1,58 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// ReadBlockStats retrieves the resource usage recorded while importing the block
+// with the given hash.
+func ReadBlockStats(db ethdb.KeyValueReader, hash common.Hash) *types.BlockStats {
+	data, _ := db.Get(blockStatsKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	stats := new(types.BlockStats)
+	if err := rlp.DecodeBytes(data, stats); err != nil {
+		log.Error("Invalid block stats RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return stats
+}
+
+// WriteBlockStats stores the resource usage of importing a block.
+func WriteBlockStats(db ethdb.KeyValueWriter, hash common.Hash, stats *types.BlockStats) {
+	data, err := rlp.EncodeToBytes(stats)
+	if err != nil {
+		log.Crit("Failed to encode block stats", "err", err)
+	}
+	if err := db.Put(blockStatsKey(hash), data); err != nil {
+		log.Crit("Failed to store block stats", "err", err)
+	}
+}
+
+// DeleteBlockStats removes the resource usage of a block.
+func DeleteBlockStats(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(blockStatsKey(hash)); err != nil {
+		log.Crit("Failed to delete block stats", "err", err)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// BlockStreamOffset is the last block acknowledged by the sink of a block
+// stream.
+type BlockStreamOffset struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+// ReadBlockStreamOffset retrieves the last block acknowledged by the sink of the
+// block stream with the given topic.
+func ReadBlockStreamOffset(db ethdb.KeyValueReader, topic string) *BlockStreamOffset {
+	data, _ := db.Get(append(blockStreamOffsetPrefix, topic...))
+	if len(data) == 0 {
+		return nil
+	}
+	offset := new(BlockStreamOffset)
+	if err := rlp.DecodeBytes(data, offset); err != nil {
+		log.Error("Invalid block stream offset RLP", "topic", topic, "err", err)
+		return nil
+	}
+	return offset
+}
+
+// WriteBlockStreamOffset stores the last block acknowledged by the sink of the
+// block stream with the given topic.
+func WriteBlockStreamOffset(db ethdb.KeyValueWriter, topic string, offset *BlockStreamOffset) {
+	data, err := rlp.EncodeToBytes(offset)
+	if err != nil {
+		log.Crit("Failed to encode block stream offset", "err", err)
+	}
+	if err := db.Put(append(blockStreamOffsetPrefix, topic...), data); err != nil {
+		log.Crit("Failed to store block stream offset", "err", err)
+	}
+}
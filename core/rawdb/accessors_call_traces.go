@@ -0,0 +1,58 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// ReadCallTraces retrieves the internal transactions recorded while executing
+// the block with the given hash, one list per transaction in block order.
+func ReadCallTraces(db ethdb.KeyValueReader, hash common.Hash) [][]*types.InternalTransaction {
+	data, _ := db.Get(callTracesKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var traces [][]*types.InternalTransaction
+	if err := rlp.DecodeBytes(data, &traces); err != nil {
+		log.Error("Invalid call traces RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return traces
+}
+
+// WriteCallTraces stores the internal transactions of all transactions of a block.
+func WriteCallTraces(db ethdb.KeyValueWriter, hash common.Hash, traces [][]*types.InternalTransaction) {
+	data, err := rlp.EncodeToBytes(traces)
+	if err != nil {
+		log.Crit("Failed to encode call traces", "err", err)
+	}
+	if err := db.Put(callTracesKey(hash), data); err != nil {
+		log.Crit("Failed to store call traces", "err", err)
+	}
+}
+
+// DeleteCallTraces removes the internal transactions of a block.
+func DeleteCallTraces(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(callTracesKey(hash)); err != nil {
+		log.Crit("Failed to delete call traces", "err", err)
+	}
+}
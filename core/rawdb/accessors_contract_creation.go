@@ -0,0 +1,59 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// ReadContractCreation retrieves the last recorded creation of the contract at
+// the given address. The creation may have been reorged away since.
+func ReadContractCreation(db ethdb.KeyValueReader, addr common.Address) *types.ContractCreation {
+	data, _ := db.Get(creationKey(addr))
+	if len(data) == 0 {
+		return nil
+	}
+	creation := new(types.ContractCreation)
+	if err := rlp.DecodeBytes(data, creation); err != nil {
+		log.Error("Invalid contract creation RLP", "address", addr, "err", err)
+		return nil
+	}
+	return creation
+}
+
+// WriteContractCreation stores the creation of a contract, replacing any
+// previous one at the same address.
+func WriteContractCreation(db ethdb.KeyValueWriter, creation *types.ContractCreation) {
+	data, err := rlp.EncodeToBytes(creation)
+	if err != nil {
+		log.Crit("Failed to encode contract creation", "err", err)
+	}
+	if err := db.Put(creationKey(creation.Address), data); err != nil {
+		log.Crit("Failed to store contract creation", "err", err)
+	}
+}
+
+// DeleteContractCreation removes the creation of a contract.
+func DeleteContractCreation(db ethdb.KeyValueWriter, addr common.Address) {
+	if err := db.Delete(creationKey(addr)); err != nil {
+		log.Crit("Failed to delete contract creation", "err", err)
+	}
+}
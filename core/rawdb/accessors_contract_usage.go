@@ -0,0 +1,58 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// ReadContractUsage retrieves the per-contract gas and state growth recorded
+// while importing the block with the given hash.
+func ReadContractUsage(db ethdb.KeyValueReader, hash common.Hash) []*types.ContractUsage {
+	data, _ := db.Get(contractUsageKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var usage []*types.ContractUsage
+	if err := rlp.DecodeBytes(data, &usage); err != nil {
+		log.Error("Invalid contract usage RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return usage
+}
+
+// WriteContractUsage stores the per-contract gas and state growth of a block.
+func WriteContractUsage(db ethdb.KeyValueWriter, hash common.Hash, usage []*types.ContractUsage) {
+	data, err := rlp.EncodeToBytes(usage)
+	if err != nil {
+		log.Crit("Failed to encode contract usage", "err", err)
+	}
+	if err := db.Put(contractUsageKey(hash), data); err != nil {
+		log.Crit("Failed to store contract usage", "err", err)
+	}
+}
+
+// DeleteContractUsage removes the per-contract gas and state growth of a block.
+func DeleteContractUsage(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(contractUsageKey(hash)); err != nil {
+		log.Crit("Failed to delete contract usage", "err", err)
+	}
+}
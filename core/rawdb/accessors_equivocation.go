@@ -0,0 +1,70 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// ReadEquivocationEvidence retrieves all the equivocation evidence recorded for
+// blocks in the given range, ordered by block number.
+func ReadEquivocationEvidence(db ethdb.Iteratee, first, last uint64) []*types.EquivocationEvidence {
+	it := db.NewIterator(equivocationPrefix, encodeBlockNumber(first))
+	defer it.Release()
+
+	var evidence []*types.EquivocationEvidence
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(equivocationPrefix)+8+32 {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(equivocationPrefix):]) > last {
+			break
+		}
+		ev := new(types.EquivocationEvidence)
+		if err := rlp.DecodeBytes(it.Value(), ev); err != nil {
+			log.Error("Invalid equivocation evidence RLP", "key", key, "err", err)
+			continue
+		}
+		evidence = append(evidence, ev)
+	}
+	return evidence
+}
+
+// HasEquivocationEvidence checks whether the block with the given number and hash
+// was already recorded as the second of a pair of equivocating blocks.
+func HasEquivocationEvidence(db ethdb.KeyValueReader, number uint64, hash common.Hash) bool {
+	has, _ := db.Has(equivocationKey(number, hash))
+	return has
+}
+
+// WriteEquivocationEvidence stores the evidence of a proposer equivocating.
+func WriteEquivocationEvidence(db ethdb.KeyValueWriter, ev *types.EquivocationEvidence) {
+	data, err := rlp.EncodeToBytes(ev)
+	if err != nil {
+		log.Crit("Failed to encode equivocation evidence", "err", err)
+	}
+	if err := db.Put(equivocationKey(ev.Second.Number.Uint64(), ev.Second.Hash()), data); err != nil {
+		log.Crit("Failed to store equivocation evidence", "err", err)
+	}
+}
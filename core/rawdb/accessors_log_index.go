@@ -0,0 +1,190 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// errLogIndexInterrupted is returned if a log index rebuild is interrupted.
+var errLogIndexInterrupted = errors.New("log indexing interrupted")
+
+// ReadLogIndexRange retrieves the range of blocks [tail, next) covered by the
+// log index. The returned flag is false if the log index was never initialized.
+func ReadLogIndexRange(db ethdb.KeyValueReader) (uint64, uint64, bool) {
+	tail, _ := db.Get(logIndexTailKey)
+	next, _ := db.Get(logIndexNextKey)
+	if len(tail) != 8 || len(next) != 8 {
+		return 0, 0, false
+	}
+	return binary.BigEndian.Uint64(tail), binary.BigEndian.Uint64(next), true
+}
+
+// WriteLogIndexTail stores the number of the oldest block covered by the log index.
+func WriteLogIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(logIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store log index tail", "err", err)
+	}
+}
+
+// WriteLogIndexNext stores the number of the first block above the range
+// covered by the log index.
+func WriteLogIndexNext(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(logIndexNextKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store log index next", "err", err)
+	}
+}
+
+// WriteLogIndex stores the per-address and per-topic index entries for the
+// logs contained in the given receipts of a block.
+func WriteLogIndex(db ethdb.KeyValueWriter, number uint64, receipts types.Receipts) {
+	var (
+		addresses = make(map[common.Address]struct{})
+		topics    = make(map[common.Hash]struct{})
+	)
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			addresses[l.Address] = struct{}{}
+			for _, topic := range l.Topics {
+				topics[topic] = struct{}{}
+			}
+		}
+	}
+	for address := range addresses {
+		if err := db.Put(logAddressIndexKey(address, number), nil); err != nil {
+			log.Crit("Failed to store log address index", "err", err)
+		}
+	}
+	for topic := range topics {
+		if err := db.Put(logTopicIndexKey(topic, number), nil); err != nil {
+			log.Crit("Failed to store log topic index", "err", err)
+		}
+	}
+}
+
+// ReadLogIndexAddressBlocks returns the ascending numbers of all blocks in the
+// range [from, to] that contain logs emitted by the given address.
+func ReadLogIndexAddressBlocks(db ethdb.Iteratee, address common.Address, from, to uint64) []uint64 {
+	prefix := append(append([]byte{}, logAddressIndexPrefix...), address.Bytes()...)
+	return readLogIndexBlocks(db, prefix, from, to)
+}
+
+// ReadLogIndexTopicBlocks returns the ascending numbers of all blocks in the
+// range [from, to] that contain logs with the given topic in any position.
+func ReadLogIndexTopicBlocks(db ethdb.Iteratee, topic common.Hash, from, to uint64) []uint64 {
+	prefix := append(append([]byte{}, logTopicIndexPrefix...), topic.Bytes()...)
+	return readLogIndexBlocks(db, prefix, from, to)
+}
+
+func readLogIndexBlocks(db ethdb.Iteratee, prefix []byte, from, to uint64) []uint64 {
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	var numbers []uint64
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(prefix):])
+		if number > to {
+			break
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+// IndexLogs builds the log index for the canonical blocks in the range [from, to]
+// from the stored receipts, advancing the covered range as it progresses. The
+// tail of the covered range is left untouched. Any existing index entries are
+// kept, stale entries left behind by reorgs only cause redundant block lookups.
+func IndexLogs(db ethdb.Database, from, to uint64, interrupt chan struct{}) error {
+	var (
+		batch  = db.NewBatch()
+		start  = time.Now()
+		logged = time.Now()
+	)
+	flush := func(next uint64) {
+		WriteLogIndexNext(batch, next)
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed writing batch to db", "error", err)
+		}
+		batch.Reset()
+	}
+	for number := from; number <= to; number++ {
+		if interrupt != nil {
+			select {
+			case <-interrupt:
+				flush(number)
+				return errLogIndexInterrupted
+			default:
+			}
+		}
+		hash := ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) {
+			flush(number)
+			return errors.New("canonical hash missing")
+		}
+		WriteLogIndex(batch, number, ReadRawReceipts(db, hash, number))
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			flush(number + 1)
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing logs", "number", number, "target", to, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	flush(to + 1)
+	log.Info("Indexed logs", "from", from, "to", to, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// DeleteLogIndex removes all log index entries and the covered range markers.
+func DeleteLogIndex(db ethdb.Database) {
+	batch := db.NewBatch()
+	for _, prefix := range [][]byte{logAddressIndexPrefix, logTopicIndexPrefix} {
+		it := db.NewIterator(prefix, nil)
+		for it.Next() {
+			if err := batch.Delete(it.Key()); err != nil {
+				log.Crit("Failed to delete log index entry", "err", err)
+			}
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					log.Crit("Failed writing batch to db", "error", err)
+				}
+				batch.Reset()
+			}
+		}
+		it.Release()
+	}
+	for _, key := range [][]byte{logIndexTailKey, logIndexNextKey} {
+		if err := batch.Delete(key); err != nil {
+			log.Crit("Failed to delete log index range", "err", err)
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed writing batch to db", "error", err)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+func TestLogIndexStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	if _, _, ok := ReadLogIndexRange(db); ok {
+		t.Fatal("log index range present in empty database")
+	}
+	var (
+		addr1  = common.HexToAddress("0x01")
+		addr2  = common.HexToAddress("0x02")
+		topic1 = common.HexToHash("0x11")
+		topic2 = common.HexToHash("0x12")
+	)
+	WriteLogIndex(db, 1, types.Receipts{{Logs: []*types.Log{{Address: addr1, Topics: []common.Hash{topic1}}}}})
+	WriteLogIndex(db, 2, types.Receipts{{Logs: []*types.Log{{Address: addr2, Topics: []common.Hash{topic1, topic2}}}}})
+	WriteLogIndex(db, 3, types.Receipts{{Logs: []*types.Log{{Address: addr1}, {Address: addr1, Topics: []common.Hash{topic2}}}}})
+	WriteLogIndexTail(db, 1)
+	WriteLogIndexNext(db, 4)
+
+	if tail, next, ok := ReadLogIndexRange(db); !ok || tail != 1 || next != 4 {
+		t.Fatalf("log index range mismatch: have [%d, %d) %v, want [1, 4) true", tail, next, ok)
+	}
+	if have, want := ReadLogIndexAddressBlocks(db, addr1, 0, 10), []uint64{1, 3}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("address blocks mismatch: have %v, want %v", have, want)
+	}
+	if have, want := ReadLogIndexAddressBlocks(db, addr1, 2, 10), []uint64{3}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("address blocks mismatch: have %v, want %v", have, want)
+	}
+	if have, want := ReadLogIndexTopicBlocks(db, topic1, 0, 1), []uint64{1}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("topic blocks mismatch: have %v, want %v", have, want)
+	}
+	if have, want := ReadLogIndexTopicBlocks(db, topic2, 0, 10), []uint64{2, 3}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("topic blocks mismatch: have %v, want %v", have, want)
+	}
+	DeleteLogIndex(db)
+	if _, _, ok := ReadLogIndexRange(db); ok {
+		t.Fatal("log index range present after deletion")
+	}
+	if blocks := ReadLogIndexAddressBlocks(db, addr1, 0, 10); len(blocks) != 0 {
+		t.Fatalf("log index entries present after deletion: %v", blocks)
+	}
+}
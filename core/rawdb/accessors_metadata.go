@@ -138,3 +138,28 @@ func PopUncleanShutdownMarker(db ethdb.KeyValueStore) {
 		log.Warn("Failed to clear unclean-shutdown marker", "err", err)
 	}
 }
+
+// ReadCleanShutdownMarker retrieves the hash of the head block persisted by the
+// last clean shutdown, or an empty hash if the node did not shut down cleanly.
+func ReadCleanShutdownMarker(db ethdb.KeyValueReader) common.Hash {
+	data, _ := db.Get(cleanShutdownKey)
+	if len(data) != common.HashLength {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// WriteCleanShutdownMarker stores the hash of the head block that was fully
+// persisted during a clean shutdown.
+func WriteCleanShutdownMarker(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Put(cleanShutdownKey, hash.Bytes()); err != nil {
+		log.Crit("Failed to store clean shutdown marker", "err", err)
+	}
+}
+
+// DeleteCleanShutdownMarker removes the clean shutdown marker.
+func DeleteCleanShutdownMarker(db ethdb.KeyValueWriter) {
+	if err := db.Delete(cleanShutdownKey); err != nil {
+		log.Crit("Failed to remove clean shutdown marker", "err", err)
+	}
+}
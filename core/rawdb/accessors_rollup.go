@@ -0,0 +1,151 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// ReadRollupSyncedL1Block retrieves the number of the last L1 block scanned for
+// batch finalization events, or nil if scanning never started.
+func ReadRollupSyncedL1Block(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(rollupSyncedL1BlockKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteRollupSyncedL1Block stores the number of the last L1 block scanned for
+// batch finalization events.
+func WriteRollupSyncedL1Block(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(rollupSyncedL1BlockKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store rollup synced L1 block", "err", err)
+	}
+}
+
+// DeleteRollupSyncedL1Block removes the L1 scanning progress, restarting the
+// scan from the current L1 finality.
+func DeleteRollupSyncedL1Block(db ethdb.KeyValueWriter) {
+	if err := db.Delete(rollupSyncedL1BlockKey); err != nil {
+		log.Crit("Failed to delete rollup synced L1 block", "err", err)
+	}
+}
+
+// ReadRollupBatch retrieves the batch with the given index recorded as
+// finalized on L1.
+func ReadRollupBatch(db ethdb.KeyValueReader, index uint64) *types.RollupBatch {
+	data, _ := db.Get(rollupBatchKey(index))
+	if len(data) == 0 {
+		return nil
+	}
+	batch := new(types.RollupBatch)
+	if err := rlp.DecodeBytes(data, batch); err != nil {
+		log.Error("Invalid rollup batch RLP", "index", index, "err", err)
+		return nil
+	}
+	return batch
+}
+
+// WriteRollupBatch stores a batch finalized on L1.
+func WriteRollupBatch(db ethdb.KeyValueWriter, batch *types.RollupBatch) {
+	data, err := rlp.EncodeToBytes(batch)
+	if err != nil {
+		log.Crit("Failed to encode rollup batch", "err", err)
+	}
+	if err := db.Put(rollupBatchKey(batch.Index), data); err != nil {
+		log.Crit("Failed to store rollup batch", "err", err)
+	}
+}
+
+// DeleteRollupBatch removes the batch with the given index.
+func DeleteRollupBatch(db ethdb.KeyValueWriter, index uint64) {
+	if err := db.Delete(rollupBatchKey(index)); err != nil {
+		log.Crit("Failed to delete rollup batch", "err", err)
+	}
+}
+
+// ReadLastRollupBatch retrieves the index of the last batch recorded as
+// finalized on L1, or nil if none was recorded.
+func ReadLastRollupBatch(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(lastRollupBatchKey)
+	if len(data) != 8 {
+		return nil
+	}
+	index := binary.BigEndian.Uint64(data)
+	return &index
+}
+
+// WriteLastRollupBatch stores the index of the last batch recorded as
+// finalized on L1.
+func WriteLastRollupBatch(db ethdb.KeyValueWriter, index uint64) {
+	if err := db.Put(lastRollupBatchKey, encodeBlockNumber(index)); err != nil {
+		log.Crit("Failed to store last rollup batch", "err", err)
+	}
+}
+
+// DeleteLastRollupBatch removes the index of the last batch recorded as
+// finalized on L1.
+func DeleteLastRollupBatch(db ethdb.KeyValueWriter) {
+	if err := db.Delete(lastRollupBatchKey); err != nil {
+		log.Crit("Failed to delete last rollup batch", "err", err)
+	}
+}
+
+// ReadL1FeeRefund retrieves the L1 fee refund recorded for a transaction, or
+// nil if none was recorded. The record may stem from a block no longer
+// canonical.
+func ReadL1FeeRefund(db ethdb.KeyValueReader, hash common.Hash) *types.L1FeeRefundRecord {
+	data, _ := db.Get(l1FeeRefundKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	record := new(types.L1FeeRefundRecord)
+	if err := rlp.DecodeBytes(data, record); err != nil {
+		log.Error("Invalid L1 fee refund RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return record
+}
+
+// WriteL1FeeRefund stores the L1 fee refund of a transaction.
+func WriteL1FeeRefund(db ethdb.KeyValueWriter, hash common.Hash, record *types.L1FeeRefundRecord) {
+	data, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		log.Crit("Failed to encode L1 fee refund", "err", err)
+	}
+	if err := db.Put(l1FeeRefundKey(hash), data); err != nil {
+		log.Crit("Failed to store L1 fee refund", "err", err)
+	}
+}
+
+// ReadCanonicalL1FeeRefund retrieves the L1 fee refund of a transaction if it
+// was issued by a canonical block.
+func ReadCanonicalL1FeeRefund(db ethdb.Reader, hash common.Hash) *types.L1FeeRefundRecord {
+	record := ReadL1FeeRefund(db, hash)
+	if record == nil || ReadCanonicalHash(db, record.BlockNumber) != record.BlockHash {
+		return nil
+	}
+	return record
+}
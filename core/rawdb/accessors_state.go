@@ -17,6 +17,8 @@
 package rawdb
 
 import (
+	"encoding/binary"
+
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/log"
@@ -39,6 +41,49 @@ func WritePreimages(db ethdb.KeyValueWriter, preimages map[common.Hash][]byte) {
 	preimageHitCounter.Inc(int64(len(preimages)))
 }
 
+// ReadPreimageCount retrieves the number of trie key preimages written to the
+// database, as tracked by the preimage store. Preimages written before tracking
+// started are not counted.
+func ReadPreimageCount(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(preimageCountKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// WritePreimageCount stores the number of trie key preimages written to the
+// database.
+func WritePreimageCount(db ethdb.KeyValueWriter, count uint64) {
+	var enc [8]byte
+	binary.BigEndian.PutUint64(enc[:], count)
+	if err := db.Put(preimageCountKey, enc[:]); err != nil {
+		log.Crit("Failed to store preimage count", "err", err)
+	}
+}
+
+// ReadHistoryPruningMarker retrieves the last database key visited by an
+// interrupted state history pruning, or nil if none is in progress.
+func ReadHistoryPruningMarker(db ethdb.KeyValueReader) []byte {
+	marker, _ := db.Get(historyPruningKey)
+	return marker
+}
+
+// WriteHistoryPruningMarker stores the last database key visited by the state
+// history pruning.
+func WriteHistoryPruningMarker(db ethdb.KeyValueWriter, marker []byte) {
+	if err := db.Put(historyPruningKey, marker); err != nil {
+		log.Crit("Failed to store history pruning marker", "err", err)
+	}
+}
+
+// DeleteHistoryPruningMarker removes the state history pruning marker.
+func DeleteHistoryPruningMarker(db ethdb.KeyValueWriter) {
+	if err := db.Delete(historyPruningKey); err != nil {
+		log.Crit("Failed to delete history pruning marker", "err", err)
+	}
+}
+
 // ReadCode retrieves the contract code of the provided code hash.
 func ReadCode(db ethdb.KeyValueReader, hash common.Hash) []byte {
 	// Try with the legacy code scheme first, if not then try with current
@@ -75,6 +120,31 @@ func DeleteCode(db ethdb.KeyValueWriter, hash common.Hash) {
 	}
 }
 
+// ReadKeccakCodeHash retrieves the keccak hash of the contract code with the
+// given Poseidon hash, under which the code itself is stored.
+func ReadKeccakCodeHash(db ethdb.KeyValueReader, poseidonHash common.Hash) common.Hash {
+	data, _ := db.Get(poseidonCodeKey(poseidonHash))
+	return common.BytesToHash(data)
+}
+
+// WritePoseidonCodeHash stores the keccak hash of the contract code with the
+// given Poseidon hash, so that the code can be looked up by either hash.
+func WritePoseidonCodeHash(db ethdb.KeyValueWriter, poseidonHash, keccakHash common.Hash) {
+	if err := db.Put(poseidonCodeKey(poseidonHash), keccakHash.Bytes()); err != nil {
+		log.Crit("Failed to store Poseidon code hash", "err", err)
+	}
+}
+
+// ReadCodeByPoseidonHash retrieves the contract code with the given Poseidon
+// hash.
+func ReadCodeByPoseidonHash(db ethdb.KeyValueReader, poseidonHash common.Hash) []byte {
+	keccakHash := ReadKeccakCodeHash(db, poseidonHash)
+	if keccakHash == (common.Hash{}) {
+		return nil
+	}
+	return ReadCode(db, keccakHash)
+}
+
 // ReadTrieNode retrieves the trie node of the provided hash.
 func ReadTrieNode(db ethdb.KeyValueReader, hash common.Hash) []byte {
 	data, _ := db.Get(hash.Bytes())
@@ -0,0 +1,75 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// ReadZktrieRoot retrieves the zktrie root of the state with the given MPT
+// root, and whether the state was migrated at all. The empty zktrie has the
+// zero root.
+func ReadZktrieRoot(db ethdb.KeyValueReader, root common.Hash) (common.Hash, bool) {
+	data, _ := db.Get(zktrieRootKey(root))
+	if len(data) != common.HashLength {
+		return common.Hash{}, false
+	}
+	return common.BytesToHash(data), true
+}
+
+// WriteZktrieRoot stores the zktrie root of the state with the given MPT root.
+func WriteZktrieRoot(db ethdb.KeyValueWriter, root, zkRoot common.Hash) {
+	if err := db.Put(zktrieRootKey(root), zkRoot.Bytes()); err != nil {
+		log.Crit("Failed to store zktrie root", "err", err)
+	}
+}
+
+// ReadStateMigration retrieves the progress of the state migration to zktrie.
+func ReadStateMigration(db ethdb.KeyValueReader) *types.StateMigration {
+	data, _ := db.Get(stateMigrationKey)
+	if len(data) == 0 {
+		return nil
+	}
+	progress := new(types.StateMigration)
+	if err := rlp.DecodeBytes(data, progress); err != nil {
+		log.Error("Invalid state migration RLP", "err", err)
+		return nil
+	}
+	return progress
+}
+
+// WriteStateMigration stores the progress of the state migration to zktrie.
+func WriteStateMigration(db ethdb.KeyValueWriter, progress *types.StateMigration) {
+	data, err := rlp.EncodeToBytes(progress)
+	if err != nil {
+		log.Crit("Failed to encode state migration", "err", err)
+	}
+	if err := db.Put(stateMigrationKey, data); err != nil {
+		log.Crit("Failed to store state migration", "err", err)
+	}
+}
+
+// DeleteStateMigration removes the progress of the state migration to zktrie.
+func DeleteStateMigration(db ethdb.KeyValueWriter) {
+	if err := db.Delete(stateMigrationKey); err != nil {
+		log.Crit("Failed to delete state migration", "err", err)
+	}
+}
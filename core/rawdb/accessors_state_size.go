@@ -0,0 +1,57 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// ReadStateSize retrieves the state size after the block with the given hash.
+func ReadStateSize(db ethdb.KeyValueReader, hash common.Hash) *types.StateSize {
+	data, _ := db.Get(stateSizeKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	size := new(types.StateSize)
+	if err := rlp.DecodeBytes(data, size); err != nil {
+		log.Error("Invalid state size RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return size
+}
+
+// WriteStateSize stores the state size after a block.
+func WriteStateSize(db ethdb.KeyValueWriter, hash common.Hash, size *types.StateSize) {
+	data, err := rlp.EncodeToBytes(size)
+	if err != nil {
+		log.Crit("Failed to encode state size", "err", err)
+	}
+	if err := db.Put(stateSizeKey(hash), data); err != nil {
+		log.Crit("Failed to store state size", "err", err)
+	}
+}
+
+// DeleteStateSize removes the state size after a block.
+func DeleteStateSize(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(stateSizeKey(hash)); err != nil {
+		log.Crit("Failed to delete state size", "err", err)
+	}
+}
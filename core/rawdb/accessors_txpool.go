@@ -0,0 +1,64 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// ReadPooledTransactions retrieves all the transactions persisted by the
+// transaction pool, in no particular order.
+func ReadPooledTransactions(db ethdb.Iteratee) []*types.PooledTransaction {
+	it := db.NewIterator(pooledTxPrefix, nil)
+	defer it.Release()
+
+	var txs []*types.PooledTransaction
+	for it.Next() {
+		if len(it.Key()) != len(pooledTxPrefix)+common.HashLength {
+			continue
+		}
+		tx := new(types.PooledTransaction)
+		if err := rlp.DecodeBytes(it.Value(), tx); err != nil {
+			log.Error("Invalid pooled transaction RLP", "hash", common.BytesToHash(it.Key()[len(pooledTxPrefix):]), "err", err)
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// WritePooledTransaction stores a transaction persisted by the transaction pool.
+func WritePooledTransaction(db ethdb.KeyValueWriter, tx *types.PooledTransaction) {
+	data, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		log.Crit("Failed to encode pooled transaction", "err", err)
+	}
+	if err := db.Put(pooledTxKey(tx.Tx.Hash()), data); err != nil {
+		log.Crit("Failed to store pooled transaction", "err", err)
+	}
+}
+
+// DeletePooledTransaction removes a transaction persisted by the transaction pool.
+func DeletePooledTransaction(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(pooledTxKey(hash)); err != nil {
+		log.Crit("Failed to delete pooled transaction", "err", err)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// ReadWithdrawTrieNode retrieves the withdraw trie node at the given position.
+func ReadWithdrawTrieNode(db ethdb.KeyValueReader, level uint8, index uint64) common.Hash {
+	data, _ := db.Get(withdrawTrieNodeKey(level, index))
+	return common.BytesToHash(data)
+}
+
+// WriteWithdrawTrieNode stores the withdraw trie node at the given position.
+func WriteWithdrawTrieNode(db ethdb.KeyValueWriter, level uint8, index uint64, hash common.Hash) {
+	if err := db.Put(withdrawTrieNodeKey(level, index), hash.Bytes()); err != nil {
+		log.Crit("Failed to store withdraw trie node", "err", err)
+	}
+}
+
+// ReadWithdrawTrieCount retrieves the number of L2 to L1 messages sent up to and
+// including the given block. The returned flag is false if the block was not
+// tracked.
+func ReadWithdrawTrieCount(db ethdb.KeyValueReader, hash common.Hash) (uint64, bool) {
+	data, _ := db.Get(withdrawTrieCountKey(hash))
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// WriteWithdrawTrieCount stores the number of L2 to L1 messages sent up to and
+// including the given block.
+func WriteWithdrawTrieCount(db ethdb.KeyValueWriter, hash common.Hash, count uint64) {
+	if err := db.Put(withdrawTrieCountKey(hash), encodeBlockNumber(count)); err != nil {
+		log.Crit("Failed to store withdraw trie count", "err", err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync/atomic"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/ethdb/leveldb"
 	"github.com/scroll-tech/go-ethereum/ethdb/memorydb"
+	"github.com/scroll-tech/go-ethereum/ethdb/objstore"
 	"github.com/scroll-tech/go-ethereum/log"
 )
 
@@ -146,8 +148,16 @@ func NewDatabase(db ethdb.KeyValueStore) ethdb.Database {
 // value data store with a freezer moving immutable chain segments into cold
 // storage.
 func NewDatabaseWithFreezer(db ethdb.KeyValueStore, freezer string, namespace string, readonly bool) (ethdb.Database, error) {
+	return NewDatabaseWithRemoteFreezer(db, freezer, namespace, readonly, nil, 0)
+}
+
+// NewDatabaseWithRemoteFreezer creates a high level database on top of a given
+// key-value data store with a freezer moving immutable chain segments into cold
+// storage, and sealed freezer files further into an object store. Offloaded
+// data is read back through a local cache of the given size in megabytes.
+func NewDatabaseWithRemoteFreezer(db ethdb.KeyValueStore, freezer string, namespace string, readonly bool, store ObjectStore, cache int) (ethdb.Database, error) {
 	// Create the idle freezer instance
-	frdb, err := newFreezer(freezer, namespace, readonly, freezerTableSize, FreezerNoSnappy)
+	frdb, err := newFreezer(freezer, namespace, readonly, freezerTableSize, FreezerNoSnappy, store, cache)
 	if err != nil {
 		return nil, err
 	}
@@ -222,6 +232,10 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, freezer string, namespace st
 			frdb.freeze(db)
 			frdb.wg.Done()
 		}()
+		if frdb.remote != nil {
+			frdb.wg.Add(1)
+			go frdb.offload()
+		}
 	}
 	return &freezerdb{
 		KeyValueStore: db,
@@ -267,6 +281,99 @@ func NewLevelDBDatabaseWithFreezer(file string, cache int, handles int, freezer
 	return frdb, nil
 }
 
+const (
+	dbPebble  = "pebble"
+	dbLeveldb = "leveldb"
+)
+
+// PreexistingDatabase checks the given data directory whether a database is
+// already instantiated at that location, and if so, returns the type of
+// database (or the empty string).
+func PreexistingDatabase(path string) string {
+	if _, err := os.Stat(filepath.Join(path, "CURRENT")); err != nil {
+		return "" // No pre-existing db
+	}
+	if matches, err := filepath.Glob(filepath.Join(path, "OPTIONS*")); len(matches) > 0 || err != nil {
+		if err != nil {
+			panic(err) // only possible if the pattern is malformed
+		}
+		return dbPebble
+	}
+	return dbLeveldb
+}
+
+// OpenOptions contains the options to apply when opening a database.
+type OpenOptions struct {
+	Type              string // "leveldb" | "pebble"
+	Directory         string // the datadir
+	AncientsDirectory string // the ancients-dir, no freezer is attached if empty
+	Namespace         string // the namespace for database relevant metrics
+	Cache             int    // the capacity(in megabytes) of the data caching
+	Handles           int    // number of files to be open simultaneously
+	ReadOnly          bool
+
+	AncientsRemote string // the object store location to offload sealed ancient files to, if any
+	AncientsCache  int    // the capacity(in megabytes) of the local cache of offloaded ancient data
+}
+
+// openKeyValueDatabase opens a disk-based key-value database, e.g. leveldb or
+// pebble.
+//
+//	                      type == null          type != null
+//	                   +----------------------------------------
+//	db is non-existent |  leveldb default  |  specified type
+//	db is existent     |  from db          |  specified type (if compatible)
+func openKeyValueDatabase(o OpenOptions) (ethdb.Database, error) {
+	existingDb := PreexistingDatabase(o.Directory)
+	if len(existingDb) != 0 && len(o.Type) != 0 && o.Type != existingDb {
+		return nil, fmt.Errorf("db.engine choice was %v but found pre-existing %v database in specified data directory", o.Type, existingDb)
+	}
+	if o.Type == dbPebble || existingDb == dbPebble {
+		if PebbleEnabled {
+			log.Info("Using pebble as the backing database")
+			return NewPebbleDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly)
+		}
+		return nil, errors.New("db.engine 'pebble' not supported on this platform")
+	}
+	if len(o.Type) != 0 && o.Type != dbLeveldb {
+		return nil, fmt.Errorf("unknown db.engine %v", o.Type)
+	}
+	log.Info("Using leveldb as the backing database")
+	// Use leveldb, either as default (no explicit choice), or pre-existing, or chosen explicitly
+	return NewLevelDBDatabase(o.Directory, o.Cache, o.Handles, o.Namespace, o.ReadOnly)
+}
+
+// Open opens both a disk-based key-value database such as leveldb or pebble, and
+// an ancient store when an ancients directory is given.
+//
+// The passed o.AncientDir indicates the path of root ancient directory where
+// the chain freezer can be opened.
+func Open(o OpenOptions) (ethdb.Database, error) {
+	kvdb, err := openKeyValueDatabase(o)
+	if err != nil {
+		return nil, err
+	}
+	if len(o.AncientsDirectory) == 0 {
+		return kvdb, nil
+	}
+	var store ObjectStore
+	if len(o.AncientsRemote) != 0 {
+		client, err := objstore.New(o.AncientsRemote)
+		if err != nil {
+			kvdb.Close()
+			return nil, err
+		}
+		log.Info("Offloading ancient data to object store", "location", client)
+		store = client
+	}
+	frdb, err := NewDatabaseWithRemoteFreezer(kvdb, o.AncientsDirectory, o.Namespace, o.ReadOnly, store, o.AncientsCache)
+	if err != nil {
+		kvdb.Close()
+		return nil, err
+	}
+	return frdb, nil
+}
+
 type counter uint64
 
 func (c counter) String() string {
@@ -323,6 +430,21 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		preimages       stat
 		bloomBits       stat
 		cliqueSnaps     stat
+		accessLists     stat
+		logIndex        stat
+		callTraces      stat
+		blockStats      stat
+		contractUsage   stat
+		stateSizes      stat
+		zktrieRoots     stat
+		equivocations   stat
+		rollupBatches   stat
+		l1FeeRefunds    stat
+		creations       stat
+		poseidonCodes   stat
+		accessEpochs    stat
+		withdrawTrie    stat
+		pooledTxs       stat
 
 		// Ancient store statistics
 		ancientHeadersSize  common.StorageSize
@@ -382,6 +504,42 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			bloomBits.Add(size)
 		case bytes.HasPrefix(key, []byte("clique-")) && len(key) == 7+common.HashLength:
 			cliqueSnaps.Add(size)
+		case bytes.HasPrefix(key, blockAccessListPrefix) && len(key) == (len(blockAccessListPrefix)+common.HashLength):
+			accessLists.Add(size)
+		case bytes.HasPrefix(key, callTracesPrefix) && len(key) == (len(callTracesPrefix)+common.HashLength):
+			callTraces.Add(size)
+		case bytes.HasPrefix(key, blockStatsPrefix) && len(key) == (len(blockStatsPrefix)+common.HashLength):
+			blockStats.Add(size)
+		case bytes.HasPrefix(key, contractUsagePrefix) && len(key) == (len(contractUsagePrefix)+common.HashLength):
+			contractUsage.Add(size)
+		case bytes.HasPrefix(key, stateSizePrefix) && len(key) == (len(stateSizePrefix)+common.HashLength):
+			stateSizes.Add(size)
+		case bytes.HasPrefix(key, zktrieRootPrefix) && len(key) == (len(zktrieRootPrefix)+common.HashLength):
+			zktrieRoots.Add(size)
+		case bytes.HasPrefix(key, equivocationPrefix) && len(key) == (len(equivocationPrefix)+8+common.HashLength):
+			equivocations.Add(size)
+		case bytes.HasPrefix(key, rollupBatchPrefix) && len(key) == (len(rollupBatchPrefix)+8):
+			rollupBatches.Add(size)
+		case bytes.HasPrefix(key, l1FeeRefundPrefix) && len(key) == (len(l1FeeRefundPrefix)+common.HashLength):
+			l1FeeRefunds.Add(size)
+		case bytes.HasPrefix(key, creationPrefix) && len(key) == (len(creationPrefix)+common.AddressLength):
+			creations.Add(size)
+		case bytes.HasPrefix(key, poseidonCodePrefix) && len(key) == (len(poseidonCodePrefix)+common.HashLength):
+			poseidonCodes.Add(size)
+		case bytes.HasPrefix(key, accessEpochPrefix) && (len(key) == len(accessEpochPrefix)+common.AddressLength || len(key) == len(accessEpochPrefix)+common.AddressLength+common.HashLength):
+			accessEpochs.Add(size)
+		case bytes.HasPrefix(key, withdrawTrieNodePrefix) && len(key) == (len(withdrawTrieNodePrefix)+1+8):
+			withdrawTrie.Add(size)
+		case bytes.HasPrefix(key, withdrawTrieCountPrefix) && len(key) == (len(withdrawTrieCountPrefix)+common.HashLength):
+			withdrawTrie.Add(size)
+		case bytes.HasPrefix(key, logAddressIndexPrefix) && len(key) == (len(logAddressIndexPrefix)+common.AddressLength+8):
+			logIndex.Add(size)
+		case bytes.HasPrefix(key, logTopicIndexPrefix) && len(key) == (len(logTopicIndexPrefix)+common.HashLength+8):
+			logIndex.Add(size)
+		case bytes.HasPrefix(key, pooledTxPrefix) && len(key) == (len(pooledTxPrefix)+common.HashLength):
+			pooledTxs.Add(size)
+		case bytes.HasPrefix(key, blockStreamOffsetPrefix):
+			metadata.Add(size)
 		case bytes.HasPrefix(key, []byte("cht-")) ||
 			bytes.HasPrefix(key, []byte("chtIndexV2-")) ||
 			bytes.HasPrefix(key, []byte("chtRootV2-")): // Canonical hash trie
@@ -396,7 +554,9 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 				databaseVersionKey, headHeaderKey, headBlockKey, headFastBlockKey, lastPivotKey,
 				fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
 				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
-				uncleanShutdownKey, badBlockKey,
+				uncleanShutdownKey, cleanShutdownKey, badBlockKey, logIndexTailKey, logIndexNextKey,
+				headFinalizedBlockKey, rollupSyncedL1BlockKey, lastRollupBatchKey, preimageCountKey,
+				historyPruningKey, stateMigrationKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -443,6 +603,21 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Account snapshot", accountSnaps.Size(), accountSnaps.Count()},
 		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
 		{"Key-Value store", "Clique snapshots", cliqueSnaps.Size(), cliqueSnaps.Count()},
+		{"Key-Value store", "Block access lists", accessLists.Size(), accessLists.Count()},
+		{"Key-Value store", "Log index", logIndex.Size(), logIndex.Count()},
+		{"Key-Value store", "Call traces", callTraces.Size(), callTraces.Count()},
+		{"Key-Value store", "Block stats", blockStats.Size(), blockStats.Count()},
+		{"Key-Value store", "Contract usage", contractUsage.Size(), contractUsage.Count()},
+		{"Key-Value store", "State sizes", stateSizes.Size(), stateSizes.Count()},
+		{"Key-Value store", "Zktrie roots", zktrieRoots.Size(), zktrieRoots.Count()},
+		{"Key-Value store", "Equivocation evidence", equivocations.Size(), equivocations.Count()},
+		{"Key-Value store", "Rollup batches", rollupBatches.Size(), rollupBatches.Count()},
+		{"Key-Value store", "L1 fee refunds", l1FeeRefunds.Size(), l1FeeRefunds.Count()},
+		{"Key-Value store", "Contract creations", creations.Size(), creations.Count()},
+		{"Key-Value store", "Poseidon code hashes", poseidonCodes.Size(), poseidonCodes.Count()},
+		{"Key-Value store", "State access epochs", accessEpochs.Size(), accessEpochs.Count()},
+		{"Key-Value store", "Withdraw trie", withdrawTrie.Size(), withdrawTrie.Count()},
+		{"Key-Value store", "Pooled transactions", pooledTxs.Size(), pooledTxs.Count()},
 		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
 		{"Ancient store", "Headers", ancientHeadersSize.String(), ancients.String()},
 		{"Ancient store", "Bodies", ancientBodiesSize.String(), ancients.String()},
@@ -15,3 +15,53 @@
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
 package rawdb
+
+import (
+	"testing"
+)
+
+// Tests that Open detects the backend of an existing database and refuses to
+// open it with a different one.
+func TestOpenPreexistingDatabase(t *testing.T) {
+	engines := []string{dbLeveldb}
+	if PebbleEnabled {
+		engines = append(engines, dbPebble)
+	}
+	for _, engine := range engines {
+		dir := t.TempDir()
+		if have := PreexistingDatabase(dir); have != "" {
+			t.Fatalf("%s: empty directory detected as %q", engine, have)
+		}
+		db, err := Open(OpenOptions{Type: engine, Directory: dir, Cache: 16, Handles: 16})
+		if err != nil {
+			t.Fatalf("%s: failed to create database: %v", engine, err)
+		}
+		if err := db.Put([]byte("key"), []byte("value")); err != nil {
+			t.Fatalf("%s: failed to write: %v", engine, err)
+		}
+		db.Close()
+
+		if have := PreexistingDatabase(dir); have != engine {
+			t.Fatalf("%s: database detected as %q", engine, have)
+		}
+		// Reopening without an explicit type picks the existing backend
+		db, err = Open(OpenOptions{Directory: dir, Cache: 16, Handles: 16})
+		if err != nil {
+			t.Fatalf("%s: failed to reopen database: %v", engine, err)
+		}
+		if val, err := db.Get([]byte("key")); err != nil || string(val) != "value" {
+			t.Fatalf("%s: value mismatch: have %q (%v), want %q", engine, val, err, "value")
+		}
+		db.Close()
+
+		// Reopening with another type fails
+		other := dbPebble
+		if engine == dbPebble {
+			other = dbLeveldb
+		}
+		if db, err := Open(OpenOptions{Type: other, Directory: dir, Cache: 16, Handles: 16}); err == nil {
+			db.Close()
+			t.Fatalf("%s: opened as %s", engine, other)
+		}
+	}
+}
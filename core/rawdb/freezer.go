@@ -88,6 +88,7 @@ type freezer struct {
 
 	readonly     bool
 	tables       map[string]*freezerTable // Data tables for storing everything
+	remote       *freezerRemote           // Object store for sealed data files (nil = local only)
 	instanceLock fileutil.Releaser        // File-system lock to prevent double opens
 
 	trigger chan chan struct{} // Manual blocking freeze trigger, test determinism
@@ -102,7 +103,10 @@ type freezer struct {
 //
 // The 'tables' argument defines the data tables. If the value of a map
 // entry is true, snappy compression is disabled for the table.
-func newFreezer(datadir string, namespace string, readonly bool, maxTableSize uint32, tables map[string]bool) (*freezer, error) {
+//
+// If an object store is given, sealed data files are offloaded to it and read
+// back through a local cache of the given size in megabytes.
+func newFreezer(datadir string, namespace string, readonly bool, maxTableSize uint32, tables map[string]bool, store ObjectStore, cache int) (*freezer, error) {
 	// Create the initial freezer object
 	var (
 		readMeter  = metrics.NewRegisteredMeter(namespace+"ancient/read", nil)
@@ -131,10 +135,16 @@ func newFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 		trigger:      make(chan chan struct{}),
 		quit:         make(chan struct{}),
 	}
+	if store != nil {
+		if freezer.remote, err = newFreezerRemote(store, datadir, cache, namespace); err != nil {
+			lock.Release()
+			return nil, err
+		}
+	}
 
 	// Create the tables.
 	for name, disableSnappy := range tables {
-		table, err := newTable(datadir, name, readMeter, writeMeter, sizeGauge, maxTableSize, disableSnappy)
+		table, err := newRemoteTable(datadir, name, readMeter, writeMeter, sizeGauge, maxTableSize, disableSnappy, freezer.remote)
 		if err != nil {
 			for _, table := range freezer.tables {
 				table.Close()
@@ -23,6 +23,7 @@ import (
 	"github.com/golang/snappy"
 
 	"github.com/scroll-tech/go-ethereum/common/math"
+	"github.com/scroll-tech/go-ethereum/internal/failpoint"
 	"github.com/scroll-tech/go-ethereum/rlp"
 )
 
@@ -184,6 +185,13 @@ func (batch *freezerTableBatch) maybeCommit() error {
 
 // commit writes the batched items to the backing freezerTable.
 func (batch *freezerTableBatch) commit() error {
+	if err := failpoint.Inject(failpoint.FreezerCommit); err != nil {
+		if err == failpoint.ErrPartialWrite {
+			// Tear the write, leaving the data without its index entries
+			batch.t.head.Write(batch.dataBuffer[:len(batch.dataBuffer)/2])
+		}
+		return err
+	}
 	// Write data.
 	_, err := batch.t.head.Write(batch.dataBuffer)
 	if err != nil {
@@ -0,0 +1,269 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+const (
+	// remoteChunkSize is the granularity at which data of offloaded files is
+	// downloaded and cached locally.
+	remoteChunkSize = 1024 * 1024
+
+	// remoteCacheDir is the directory within the freezer holding the read cache
+	// of offloaded files.
+	remoteCacheDir = "remote-cache"
+
+	// remoteOffloadInterval is the frequency to check the freezer tables for
+	// sealed data files to offload.
+	remoteOffloadInterval = time.Minute
+)
+
+// errRemoteTruncation is returned if a freezer table would need to be truncated
+// into a data file which was already offloaded to the object store.
+var errRemoteTruncation = errors.New("cannot truncate into offloaded ancient data")
+
+// ObjectStore is an object storage (e.g. an S3 or GCS bucket) keeping the sealed
+// data files of the freezer, so that immutable history doesn't need local disk.
+type ObjectStore interface {
+	// Put uploads an object of the given size, replacing any existing one.
+	Put(name string, data io.ReadSeeker, size int64) error
+
+	// GetRange reads length bytes of an object starting at offset. Fewer bytes
+	// are returned if the object ends earlier.
+	GetRange(name string, offset int64, length int) ([]byte, error)
+
+	// Size returns the size of an object.
+	Size(name string) (int64, error)
+}
+
+// freezerRemote moves sealed freezer data files into an object store and serves
+// reads of them through a bounded local cache of chunks.
+type freezerRemote struct {
+	store    ObjectStore
+	cacheDir string
+	cache    *lru.Cache // Cached chunk file names, evicted ones are deleted
+
+	uploadMeter   metrics.Meter // Meter for the bytes uploaded to the store
+	downloadMeter metrics.Meter // Meter for the bytes downloaded from the store
+	hitMeter      metrics.Meter // Meter for the chunks served from the cache
+	missMeter     metrics.Meter // Meter for the chunks downloaded from the store
+}
+
+// newFreezerRemote creates the remote backend of a freezer, with a read cache of
+// the given size in megabytes inside the freezer directory.
+func newFreezerRemote(store ObjectStore, datadir string, cache int, namespace string) (*freezerRemote, error) {
+	dir := filepath.Join(datadir, remoteCacheDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	chunks := cache * 1024 * 1024 / remoteChunkSize
+	if chunks < 1 {
+		chunks = 1
+	}
+	lruCache, err := lru.NewWithEvict(chunks, func(key, _ interface{}) {
+		os.Remove(filepath.Join(dir, key.(string)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Pick up the chunks cached by a previous run, dropping partial downloads
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == ".tmp" {
+			os.Remove(filepath.Join(dir, entry.Name()))
+			continue
+		}
+		lruCache.Add(entry.Name(), nil)
+	}
+	return &freezerRemote{
+		store:         store,
+		cacheDir:      dir,
+		cache:         lruCache,
+		uploadMeter:   metrics.NewRegisteredMeter(namespace+"ancient/remote/upload", nil),
+		downloadMeter: metrics.NewRegisteredMeter(namespace+"ancient/remote/download", nil),
+		hitMeter:      metrics.NewRegisteredMeter(namespace+"ancient/remote/cache/hit", nil),
+		missMeter:     metrics.NewRegisteredMeter(namespace+"ancient/remote/cache/miss", nil),
+	}, nil
+}
+
+// upload copies a local data file into the object store, verifying that the
+// stored object is complete.
+func (r *freezerRemote) upload(path string, name string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if err := r.store.Put(name, file, stat.Size()); err != nil {
+		return 0, err
+	}
+	size, err := r.store.Size(name)
+	if err != nil {
+		return 0, err
+	}
+	if size != stat.Size() {
+		return 0, fmt.Errorf("uploaded %s size mismatch: have %d, want %d", name, size, stat.Size())
+	}
+	r.uploadMeter.Mark(size)
+	return size, nil
+}
+
+// readAt fills buf with the data of an offloaded file starting at offset.
+func (r *freezerRemote) readAt(name string, buf []byte, offset int64) error {
+	for len(buf) > 0 {
+		index := offset / remoteChunkSize
+		chunk, err := r.chunk(name, index)
+		if err != nil {
+			return err
+		}
+		start := offset - index*remoteChunkSize
+		if start >= int64(len(chunk)) {
+			return io.ErrUnexpectedEOF
+		}
+		n := copy(buf, chunk[start:])
+		buf = buf[n:]
+		offset += int64(n)
+	}
+	return nil
+}
+
+// chunk retrieves a chunk of an offloaded file, from the cache if available or
+// from the object store otherwise.
+func (r *freezerRemote) chunk(name string, index int64) ([]byte, error) {
+	key := fmt.Sprintf("%s.%d", name, index)
+	path := filepath.Join(r.cacheDir, key)
+
+	if _, ok := r.cache.Get(key); ok {
+		if data, err := os.ReadFile(path); err == nil {
+			r.hitMeter.Mark(1)
+			return data, nil
+		}
+	}
+	r.missMeter.Mark(1)
+	data, err := r.store.GetRange(name, index*remoteChunkSize, remoteChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	r.downloadMeter.Mark(int64(len(data)))
+
+	// Cache the chunk, a failure only costs a download later
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		log.Warn("Failed to cache ancient chunk", "name", key, "err", err)
+		return data, nil
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		log.Warn("Failed to cache ancient chunk", "name", key, "err", err)
+		return data, nil
+	}
+	r.cache.Add(key, nil)
+	return data, nil
+}
+
+// offload is a background thread that periodically moves the sealed data files
+// of all freezer tables into the object store.
+func (f *freezer) offload() {
+	defer f.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+		case <-f.quit:
+			return
+		}
+		for name, table := range f.tables {
+			for {
+				start := time.Now()
+				num, size, err := table.offload()
+				if err != nil {
+					log.Error("Failed to offload ancient data", "table", name, "err", err)
+					break
+				}
+				if size == 0 {
+					break
+				}
+				log.Info("Offloaded ancient data", "table", name, "file", num, "size", common.StorageSize(size), "elapsed", common.PrettyDuration(time.Since(start)))
+
+				select {
+				case <-f.quit:
+					return
+				default:
+				}
+			}
+		}
+		timer.Reset(remoteOffloadInterval)
+	}
+}
+
+// offload uploads the oldest sealed data file still stored locally into the
+// object store and deletes the local copy. It returns the number and size of
+// the offloaded file, or zero if there is nothing to offload.
+func (t *freezerTable) offload() (uint32, int64, error) {
+	t.lock.RLock()
+	num, head := t.remoteHead, t.headId
+	t.lock.RUnlock()
+
+	if num >= head {
+		return 0, 0, nil
+	}
+	// Upload without holding the lock, the file is immutable unless the table
+	// gets truncated into it, which is checked for afterwards
+	name := t.fileName(num)
+	path := filepath.Join(t.path, name)
+	size, err := t.remote.upload(path, name)
+	if err != nil {
+		return 0, 0, err
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.remoteHead != num || num >= t.headId {
+		return 0, 0, nil
+	}
+	if stat, err := os.Stat(path); err != nil || stat.Size() != size {
+		return 0, 0, nil
+	}
+	t.releaseFile(num)
+	if err := os.Remove(path); err != nil {
+		return 0, 0, err
+	}
+	t.remoteHead++
+	return num, size, nil
+}
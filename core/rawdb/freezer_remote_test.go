@@ -0,0 +1,164 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+// memoryObjectStore is an in-memory object store counting the range requests.
+type memoryObjectStore struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+	gets    int
+}
+
+func newMemoryObjectStore() *memoryObjectStore {
+	return &memoryObjectStore{objects: make(map[string][]byte)}
+}
+
+func (s *memoryObjectStore) Put(name string, data io.ReadSeeker, size int64) error {
+	blob, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.objects[name] = blob
+	return nil
+}
+
+func (s *memoryObjectStore) GetRange(name string, offset int64, length int) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.gets++
+	blob, ok := s.objects[name]
+	if !ok || offset >= int64(len(blob)) {
+		return nil, fmt.Errorf("object %s range %d not found", name, offset)
+	}
+	if end := offset + int64(length); end < int64(len(blob)) {
+		return blob[offset:end], nil
+	}
+	return blob[offset:], nil
+}
+
+func (s *memoryObjectStore) Size(name string) (int64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	blob, ok := s.objects[name]
+	if !ok {
+		return 0, os.ErrNotExist
+	}
+	return int64(len(blob)), nil
+}
+
+// Tests that sealed data files are offloaded to the object store, and that the
+// table keeps serving their items, also after a restart.
+func TestFreezerRemoteOffload(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		store = newMemoryObjectStore()
+		items = make(map[uint64][]byte)
+	)
+	open := func(store ObjectStore) (*freezerTable, error) {
+		var remote *freezerRemote
+		if store != nil {
+			var err error
+			if remote, err = newFreezerRemote(store, dir, 0, ""); err != nil {
+				return nil, err
+			}
+		}
+		return newRemoteTable(dir, "test", metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, 50, true, remote)
+	}
+	f, err := open(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Write 30 items of 15 bytes, 3 fitting in every file: 0-8 sealed, 9 head
+	writeChunks(t, f, 30, 15)
+	for i := 0; i < 30; i++ {
+		items[uint64(i)] = getChunk(15, i)
+	}
+	for want := uint32(0); ; want++ {
+		num, size, err := f.offload()
+		if err != nil {
+			t.Fatalf("failed to offload file %d: %v", want, err)
+		}
+		if size == 0 {
+			if want != 9 {
+				t.Fatalf("offloaded files mismatch: have %d, want 9", want)
+			}
+			break
+		}
+		if num != want || size != 45 {
+			t.Fatalf("offloaded file mismatch: have %d (%d bytes), want %d (45 bytes)", num, size, want)
+		}
+	}
+	for i := uint32(0); i < 10; i++ {
+		_, err := os.Stat(filepath.Join(dir, f.fileName(i)))
+		if offloaded := os.IsNotExist(err); offloaded != (i < 9) {
+			t.Fatalf("file %d offloaded mismatch: have %v, want %v", i, offloaded, i < 9)
+		}
+	}
+	checkRetrieve(t, f, items)
+
+	// Ranges spanning offloaded and local files are served in one go
+	values, err := f.RetrieveItems(24, 6, 1000)
+	if err != nil || len(values) != 6 {
+		t.Fatalf("range across offloaded files failed: %d items, %v", len(values), err)
+	}
+	// Offloaded data is served from the local cache once downloaded
+	gets := store.gets
+	checkRetrieve(t, f, map[uint64][]byte{26: items[26]})
+	if store.gets != gets {
+		t.Fatalf("cached chunk downloaded again: %d requests, want %d", store.gets, gets)
+	}
+	// Offloaded data is immutable, truncation within the head file is fine
+	if err := f.truncate(20); err != errRemoteTruncation {
+		t.Fatalf("truncation into offloaded data error mismatch: have %v, want %v", err, errRemoteTruncation)
+	}
+	if err := f.truncate(28); err != nil {
+		t.Fatalf("failed to truncate head file: %v", err)
+	}
+	delete(items, 28)
+	delete(items, 29)
+	f.Close()
+
+	// Reopen the table and check all data is still there
+	if f, err = open(store); err != nil {
+		t.Fatalf("failed to reopen table: %v", err)
+	}
+	defer f.Close()
+	if f.remoteHead != 9 {
+		t.Fatalf("offloaded files mismatch after reopen: have %d, want 9", f.remoteHead)
+	}
+	checkRetrieve(t, f, items)
+
+	// Without the object store, the table can't be opened
+	if _, err := open(nil); err == nil {
+		t.Fatal("opened table with offloaded files without object store")
+	}
+}
@@ -106,6 +106,9 @@ type freezerTable struct {
 	tailId uint32              // number of the earliest file
 	index  *os.File            // File descriptor for the indexEntry file of the table
 
+	remote     *freezerRemote // Object store holding offloaded data files (nil = all local)
+	remoteHead uint32         // number of the earliest file stored locally, earlier ones are offloaded
+
 	// In the case that old items are deleted (from the tail), we use itemOffset
 	// to count how many historic items have gone missing.
 	itemOffset uint32 // Offset (number of discarded items)
@@ -166,6 +169,12 @@ func truncateFreezerFile(file *os.File, size int64) error {
 // non existent. Both files are truncated to the shortest common length to ensure
 // they don't go out of sync.
 func newTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, maxFilesize uint32, noCompression bool) (*freezerTable, error) {
+	return newRemoteTable(path, name, readMeter, writeMeter, sizeGauge, maxFilesize, noCompression, nil)
+}
+
+// newRemoteTable opens a freezer table whose sealed data files may have been
+// offloaded to the given remote backend.
+func newRemoteTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, maxFilesize uint32, noCompression bool, remote *freezerRemote) (*freezerTable, error) {
 	// Ensure the containing directory exists and open the indexEntry file
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, err
@@ -194,6 +203,7 @@ func newTable(path string, name string, readMeter metrics.Meter, writeMeter metr
 		logger:        log.New("database", path, "table", name),
 		noCompression: noCompression,
 		maxFileSize:   maxFilesize,
+		remote:        remote,
 	}
 	if err := tab.repair(); err != nil {
 		tab.Close()
@@ -286,6 +296,9 @@ func (t *freezerTable) repair() error {
 			newLastIndex.unmarshalBinary(buffer)
 			// We might have slipped back into an earlier head-file here
 			if newLastIndex.filenum != lastIndex.filenum {
+				if t.offloaded(newLastIndex.filenum) {
+					return errRemoteTruncation
+				}
 				// Release earlier opened file
 				t.releaseFile(lastIndex.filenum)
 				if t.head, err = t.openFile(newLastIndex.filenum, openFreezerFileForAppend); err != nil {
@@ -329,8 +342,13 @@ func (t *freezerTable) repair() error {
 func (t *freezerTable) preopen() (err error) {
 	// The repair might have already opened (some) files
 	t.releaseFilesAfter(0, false)
+	// Skip the files offloaded to the object store, which are always the oldest
+	t.remoteHead = t.tailId
+	for t.remoteHead < t.headId && t.offloaded(t.remoteHead) {
+		t.remoteHead++
+	}
 	// Open all except head in RDONLY
-	for i := t.tailId; i < t.headId; i++ {
+	for i := t.remoteHead; i < t.headId; i++ {
 		if _, err = t.openFile(i, openFreezerFileForReadOnly); err != nil {
 			return err
 		}
@@ -361,17 +379,21 @@ func (t *freezerTable) truncate(items uint64) error {
 		log = t.logger.Warn // Only loud warn if we delete multiple items
 	}
 	log("Truncating freezer table", "items", existing, "limit", items)
-	if err := truncateFreezerFile(t.index, int64(items+1)*indexEntrySize); err != nil {
-		return err
-	}
-	// Calculate the new expected size of the data file and truncate it
+
+	// Calculate the new expected size of the data file, which can't be in a file
+	// already offloaded to the object store
 	buffer := make([]byte, indexEntrySize)
 	if _, err := t.index.ReadAt(buffer, int64(items*indexEntrySize)); err != nil {
 		return err
 	}
 	var expected indexEntry
 	expected.unmarshalBinary(buffer)
-
+	if expected.filenum < t.remoteHead {
+		return errRemoteTruncation
+	}
+	if err := truncateFreezerFile(t.index, int64(items+1)*indexEntrySize); err != nil {
+		return err
+	}
 	// We might need to truncate back to older files
 	if expected.filenum != t.headId {
 		// If already open for reading, force-reopen for writing
@@ -428,17 +450,25 @@ func (t *freezerTable) Close() error {
 	return nil
 }
 
+// fileName returns the name of the data file with the given number.
+func (t *freezerTable) fileName(num uint32) string {
+	if t.noCompression {
+		return fmt.Sprintf("%s.%04d.rdat", t.name, num)
+	}
+	return fmt.Sprintf("%s.%04d.cdat", t.name, num)
+}
+
+// offloaded returns whether the data file with the given number only exists in
+// the object store.
+func (t *freezerTable) offloaded(num uint32) bool {
+	return t.remote != nil && !common.FileExist(filepath.Join(t.path, t.fileName(num)))
+}
+
 // openFile assumes that the write-lock is held by the caller
 func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error)) (f *os.File, err error) {
 	var exist bool
 	if f, exist = t.files[num]; !exist {
-		var name string
-		if t.noCompression {
-			name = fmt.Sprintf("%s.%04d.rdat", t.name, num)
-		} else {
-			name = fmt.Sprintf("%s.%04d.cdat", t.name, num)
-		}
-		f, err = opener(filepath.Join(t.path, name))
+		f, err = opener(filepath.Join(t.path, t.fileName(num)))
 		if err != nil {
 			return nil, err
 		}
@@ -586,6 +616,13 @@ func (t *freezerTable) retrieveItems(start, count, maxBytes uint64) ([]byte, []i
 		if len(output) < length {
 			output = make([]byte, length)
 		}
+		if fileId < t.remoteHead {
+			if err := t.remote.readAt(t.fileName(fileId), output[outputSize:outputSize+length], int64(start)); err != nil {
+				return err
+			}
+			outputSize += length
+			return nil
+		}
 		dataFile, exist := t.files[fileId]
 		if !exist {
 			return fmt.Errorf("missing data file %d", fileId)
@@ -39,6 +39,9 @@ var (
 	// headFastBlockKey tracks the latest known incomplete block's hash during fast sync.
 	headFastBlockKey = []byte("LastFast")
 
+	// headFinalizedBlockKey tracks the latest known finalized block's hash.
+	headFinalizedBlockKey = []byte("LastFinalized")
+
 	// lastPivotKey tracks the last pivot block used by fast sync (to reenable on sethead).
 	lastPivotKey = []byte("LastPivot")
 
@@ -69,12 +72,40 @@ var (
 	// fastTxLookupLimitKey tracks the transaction lookup limit during fast sync.
 	fastTxLookupLimitKey = []byte("FastTransactionLookupLimit")
 
+	// logIndexTailKey tracks the oldest block covered by the log index.
+	logIndexTailKey = []byte("LogIndexTail")
+
+	// logIndexNextKey tracks the first block above the range covered by the log index.
+	logIndexNextKey = []byte("LogIndexNext")
+
+	// historyPruningKey tracks the progress of an interrupted state history pruning.
+	historyPruningKey = []byte("HistoryPruning")
+
+	// stateMigrationKey tracks the progress of the state migration to zktrie.
+	stateMigrationKey = []byte("StateMigration")
+
 	// badBlockKey tracks the list of bad blocks seen by local
 	badBlockKey = []byte("InvalidBlock")
 
 	// uncleanShutdownKey tracks the list of local crashes
 	uncleanShutdownKey = []byte("unclean-shutdown") // config prefix for the db
 
+	// cleanShutdownKey tracks the head block hash persisted by the last clean shutdown.
+	cleanShutdownKey = []byte("clean-shutdown")
+
+	// rollupSyncedL1BlockKey tracks the last L1 block scanned for batch finalization events.
+	rollupSyncedL1BlockKey = []byte("RollupSyncedL1Block")
+
+	// lastRollupBatchKey tracks the index of the last batch recorded as finalized on L1.
+	lastRollupBatchKey = []byte("LastRollupBatch")
+
+	// blockStreamOffsetPrefix + topic tracks the last block acknowledged by the
+	// sink of a block stream.
+	blockStreamOffsetPrefix = []byte("stream-offset-")
+
+	// preimageCountKey tracks the number of trie key preimages written to the database.
+	preimageCountKey = []byte("PreimageCount")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
@@ -90,6 +121,25 @@ var (
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
 	CodePrefix            = []byte("c") // CodePrefix + code hash -> account code
 
+	blockAccessListPrefix = []byte("bal-") // blockAccessListPrefix + hash -> block access list
+	callTracesPrefix      = []byte("ct-")  // callTracesPrefix + hash -> call traces of the block transactions
+	blockStatsPrefix      = []byte("bs-")  // blockStatsPrefix + hash -> resource usage of importing the block
+	contractUsagePrefix   = []byte("cu-")  // contractUsagePrefix + hash -> per-contract gas and state growth of the block
+	stateSizePrefix       = []byte("ss-")  // stateSizePrefix + hash -> state size after the block
+	zktrieRootPrefix      = []byte("zr-")  // zktrieRootPrefix + MPT state root -> zktrie root of the same state
+	equivocationPrefix    = []byte("eq-")  // equivocationPrefix + num (uint64 big endian) + hash -> equivocation evidence
+	rollupBatchPrefix     = []byte("rb-")  // rollupBatchPrefix + index (uint64 big endian) -> batch finalized on L1
+	l1FeeRefundPrefix     = []byte("lr-")  // l1FeeRefundPrefix + tx hash -> L1 fee refund of the transaction
+	creationPrefix        = []byte("cc-")  // creationPrefix + address -> creation of the contract
+	poseidonCodePrefix    = []byte("pc-")  // poseidonCodePrefix + poseidon code hash -> keccak code hash
+	accessEpochPrefix     = []byte("ae-")  // accessEpochPrefix + address (+ slot) -> last access epoch
+
+	withdrawTrieNodePrefix  = []byte("wn-") // withdrawTrieNodePrefix + level (uint8) + index (uint64 big endian) -> node hash
+	withdrawTrieCountPrefix = []byte("wc-") // withdrawTrieCountPrefix + hash -> number of withdraw messages up to the block
+	logAddressIndexPrefix   = []byte("la-") // logAddressIndexPrefix + address + num (uint64 big endian) -> nil
+	logTopicIndexPrefix     = []byte("lt-") // logTopicIndexPrefix + topic + num (uint64 big endian) -> nil
+	pooledTxPrefix          = []byte("pt-") // pooledTxPrefix + tx hash -> transaction persisted by the transaction pool
+
 	PreimagePrefix = []byte("secure-key-")      // PreimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
 
@@ -207,6 +257,81 @@ func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	return key
 }
 
+// blockAccessListKey = blockAccessListPrefix + hash
+func blockAccessListKey(hash common.Hash) []byte {
+	return append(blockAccessListPrefix, hash.Bytes()...)
+}
+
+// callTracesKey = callTracesPrefix + hash
+func callTracesKey(hash common.Hash) []byte {
+	return append(callTracesPrefix, hash.Bytes()...)
+}
+
+// blockStatsKey = blockStatsPrefix + hash
+func blockStatsKey(hash common.Hash) []byte {
+	return append(blockStatsPrefix, hash.Bytes()...)
+}
+
+// contractUsageKey = contractUsagePrefix + hash
+func contractUsageKey(hash common.Hash) []byte {
+	return append(contractUsagePrefix, hash.Bytes()...)
+}
+
+// stateSizeKey = stateSizePrefix + hash
+func stateSizeKey(hash common.Hash) []byte {
+	return append(stateSizePrefix, hash.Bytes()...)
+}
+
+// zktrieRootKey = zktrieRootPrefix + root
+func zktrieRootKey(root common.Hash) []byte {
+	return append(zktrieRootPrefix, root.Bytes()...)
+}
+
+// equivocationKey = equivocationPrefix + num (uint64 big endian) + hash
+func equivocationKey(number uint64, hash common.Hash) []byte {
+	return append(append(append([]byte{}, equivocationPrefix...), encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// rollupBatchKey = rollupBatchPrefix + index (uint64 big endian)
+func rollupBatchKey(index uint64) []byte {
+	return append(append([]byte{}, rollupBatchPrefix...), encodeBlockNumber(index)...)
+}
+
+// l1FeeRefundKey = l1FeeRefundPrefix + tx hash
+func l1FeeRefundKey(hash common.Hash) []byte {
+	return append(append([]byte{}, l1FeeRefundPrefix...), hash.Bytes()...)
+}
+
+// creationKey = creationPrefix + address
+func creationKey(addr common.Address) []byte {
+	return append(append([]byte{}, creationPrefix...), addr.Bytes()...)
+}
+
+// withdrawTrieNodeKey = withdrawTrieNodePrefix + level (uint8) + index (uint64 big endian)
+func withdrawTrieNodeKey(level uint8, index uint64) []byte {
+	return append(append(append([]byte{}, withdrawTrieNodePrefix...), level), encodeBlockNumber(index)...)
+}
+
+// withdrawTrieCountKey = withdrawTrieCountPrefix + hash
+func withdrawTrieCountKey(hash common.Hash) []byte {
+	return append(withdrawTrieCountPrefix, hash.Bytes()...)
+}
+
+// logAddressIndexKey = logAddressIndexPrefix + address + num (uint64 big endian)
+func logAddressIndexKey(address common.Address, number uint64) []byte {
+	return append(append(append([]byte{}, logAddressIndexPrefix...), address.Bytes()...), encodeBlockNumber(number)...)
+}
+
+// logTopicIndexKey = logTopicIndexPrefix + topic + num (uint64 big endian)
+func logTopicIndexKey(topic common.Hash, number uint64) []byte {
+	return append(append(append([]byte{}, logTopicIndexPrefix...), topic.Bytes()...), encodeBlockNumber(number)...)
+}
+
+// pooledTxKey = pooledTxPrefix + tx hash
+func pooledTxKey(hash common.Hash) []byte {
+	return append(append([]byte{}, pooledTxPrefix...), hash.Bytes()...)
+}
+
 // preimageKey = PreimagePrefix + hash
 func preimageKey(hash common.Hash) []byte {
 	return append(PreimagePrefix, hash.Bytes()...)
@@ -217,6 +342,20 @@ func codeKey(hash common.Hash) []byte {
 	return append(CodePrefix, hash.Bytes()...)
 }
 
+// accessEpochKey = accessEpochPrefix + address (+ slot)
+func accessEpochKey(addr common.Address, slot *common.Hash) []byte {
+	key := append(append([]byte{}, accessEpochPrefix...), addr.Bytes()...)
+	if slot != nil {
+		key = append(key, slot.Bytes()...)
+	}
+	return key
+}
+
+// poseidonCodeKey = poseidonCodePrefix + poseidon code hash
+func poseidonCodeKey(hash common.Hash) []byte {
+	return append(append([]byte{}, poseidonCodePrefix...), hash.Bytes()...)
+}
+
 // IsCodeKey reports whether the given byte slice is the key of contract code,
 // if so return the raw code hash as well.
 func IsCodeKey(key []byte) (bool, []byte) {
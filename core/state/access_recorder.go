@@ -0,0 +1,114 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// accessRecorder collects every account, storage slot and contract code read
+// or written through the StateDB. Unlike the per-transaction access list it
+// is never reset between transactions nor reverted on snapshot reverts.
+type accessRecorder struct {
+	accounts map[common.Address]*accountRecord
+}
+
+type accountRecord struct {
+	slots    map[common.Hash]struct{}
+	codeHash *common.Hash
+}
+
+func newAccessRecorder() *accessRecorder {
+	return &accessRecorder{accounts: make(map[common.Address]*accountRecord)}
+}
+
+func (r *accessRecorder) account(addr common.Address) *accountRecord {
+	acc, ok := r.accounts[addr]
+	if !ok {
+		acc = &accountRecord{slots: make(map[common.Hash]struct{})}
+		r.accounts[addr] = acc
+	}
+	return acc
+}
+
+func (r *accessRecorder) addAddress(addr common.Address) {
+	r.account(addr)
+}
+
+func (r *accessRecorder) addSlot(addr common.Address, slot common.Hash) {
+	r.account(addr).slots[slot] = struct{}{}
+}
+
+func (r *accessRecorder) addCode(addr common.Address, codeHash common.Hash) {
+	if acc := r.account(addr); acc.codeHash == nil {
+		acc.codeHash = &codeHash
+	}
+}
+
+func (r *accessRecorder) copy() *accessRecorder {
+	cpy := newAccessRecorder()
+	for addr, acc := range r.accounts {
+		slots := make(map[common.Hash]struct{}, len(acc.slots))
+		for slot := range acc.slots {
+			slots[slot] = struct{}{}
+		}
+		cpy.accounts[addr] = &accountRecord{slots: slots, codeHash: acc.codeHash}
+	}
+	return cpy
+}
+
+// accessList flattens the recorded accesses into a deterministically sorted list.
+func (r *accessRecorder) accessList() types.BlockAccessList {
+	list := make(types.BlockAccessList, 0, len(r.accounts))
+	for addr, acc := range r.accounts {
+		entry := &types.AccountAccess{
+			Address:     addr,
+			StorageKeys: make([]common.Hash, 0, len(acc.slots)),
+			CodeHash:    acc.codeHash,
+		}
+		for slot := range acc.slots {
+			entry.StorageKeys = append(entry.StorageKeys, slot)
+		}
+		sort.Slice(entry.StorageKeys, func(i, j int) bool {
+			return bytes.Compare(entry.StorageKeys[i][:], entry.StorageKeys[j][:]) < 0
+		})
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return bytes.Compare(list[i].Address[:], list[j].Address[:]) < 0
+	})
+	return list
+}
+
+// StartAccessRecording enables recording of all state accesses made through
+// this StateDB from now on, discarding anything recorded previously.
+func (s *StateDB) StartAccessRecording() {
+	s.accessRecorder = newAccessRecorder()
+}
+
+// AccessRecording returns the accesses recorded since StartAccessRecording was
+// called, or nil if recording is not enabled.
+func (s *StateDB) AccessRecording() types.BlockAccessList {
+	if s.accessRecorder == nil {
+		return nil
+	}
+	return s.accessRecorder.accessList()
+}
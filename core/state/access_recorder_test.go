@@ -0,0 +1,72 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+func TestAccessRecording(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	var (
+		addr1 = common.Address{0x01}
+		addr2 = common.Address{0x02}
+		addr3 = common.Address{0x03}
+		code  = []byte{0x60, 0x00}
+	)
+	state.SetCode(addr1, code)
+	state.GetBalance(addr3)
+	if list := state.AccessRecording(); list != nil {
+		t.Fatalf("access list recorded without recording enabled: %v", list)
+	}
+	state.StartAccessRecording()
+
+	state.GetCode(addr1)
+	state.SetState(addr2, common.Hash{0x02}, common.Hash{0xff})
+	state.GetState(addr2, common.Hash{0x01})
+	snap := state.Snapshot()
+	state.GetCommittedState(addr2, common.Hash{0x03})
+	state.RevertToSnapshot(snap)
+
+	// Accesses must be kept across reverts and copies
+	list := state.Copy().AccessRecording()
+	if len(list) != 2 {
+		t.Fatalf("account count mismatch: have %d, want 2", len(list))
+	}
+	if list[0].Address != addr1 || list[1].Address != addr2 {
+		t.Fatalf("unexpected accounts: %x, %x", list[0].Address, list[1].Address)
+	}
+	if list[0].CodeHash == nil || *list[0].CodeHash != crypto.Keccak256Hash(code) {
+		t.Fatalf("code hash mismatch: have %v", list[0].CodeHash)
+	}
+	if list[1].CodeHash != nil {
+		t.Fatalf("code hash recorded for untouched code")
+	}
+	want := []common.Hash{{0x01}, {0x02}, {0x03}}
+	if len(list[1].StorageKeys) != len(want) {
+		t.Fatalf("slot count mismatch: have %d, want %d", len(list[1].StorageKeys), len(want))
+	}
+	for i, key := range want {
+		if list[1].StorageKeys[i] != key {
+			t.Errorf("slot %d mismatch: have %x, want %x", i, list[1].StorageKeys[i], key)
+		}
+	}
+}
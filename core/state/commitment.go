@@ -0,0 +1,224 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"fmt"
+
+	zkt "github.com/scroll-tech/zktrie/types"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+var (
+	// MPTCommitment commits to the state with Merkle Patricia tries keyed by
+	// keccak hashes.
+	MPTCommitment Commitment = mptCommitment{}
+
+	// ZktrieCommitment commits to the state with binary sparse Merkle tries
+	// keyed and hashed by Poseidon.
+	ZktrieCommitment Commitment = zktrieCommitment{}
+)
+
+// Commitment is the scheme committing to the state: the tries holding the
+// accounts and storage slots, how their values are encoded and how their keys
+// are proven. The state database, the witness generator and the proof APIs only
+// go through it, so another scheme (e.g. verkle) is supported by implementing it.
+type Commitment interface {
+	// OpenTrie opens the account trie at a specific root hash.
+	OpenTrie(db *trie.Database, root common.Hash) (Trie, error)
+
+	// OpenStorageTrie opens the storage trie of an account.
+	OpenStorageTrie(db *trie.Database, addrHash, root common.Hash) (Trie, error)
+
+	// CopyTrie returns an independent copy of a trie of the scheme.
+	CopyTrie(Trie) Trie
+
+	// DecodeAccount decodes an account as stored in the account trie.
+	DecodeAccount(enc []byte) (*types.StateAccount, error)
+
+	// EncodeStorage encodes a non-zero storage value to store in a storage trie.
+	EncodeStorage(value common.Hash) []byte
+
+	// DecodeStorage decodes a storage value as stored in a storage trie.
+	DecodeStorage(enc []byte) (common.Hash, error)
+
+	// EmptyStorageRoot returns the storage root reported in the proofs of
+	// accounts without storage.
+	EmptyStorageRoot() common.Hash
+
+	// ProveAccount constructs the proof of an account in the account trie.
+	ProveAccount(tr Trie, addr common.Address, proofDb ethdb.KeyValueWriter) error
+
+	// ProveStorage constructs the proof of a slot in a storage trie.
+	ProveStorage(tr Trie, slot common.Hash, proofDb ethdb.KeyValueWriter) error
+
+	// ProveStorageWithDeletion constructs the proof of a slot in a storage trie,
+	// along with the sibling node needed to predict the trie after deleting
+	// the slot if the scheme restructures the trie on deletion, or nil.
+	ProveStorageWithDeletion(tr Trie, slot common.Hash, proofDb ethdb.KeyValueWriter) ([]byte, error)
+}
+
+// NewCommitment returns the commitment scheme of the state stored in a trie
+// database with the given config.
+func NewCommitment(config *trie.Config) Commitment {
+	if config != nil && config.Zktrie {
+		return ZktrieCommitment
+	}
+	return MPTCommitment
+}
+
+type mptCommitment struct{}
+
+func (mptCommitment) OpenTrie(db *trie.Database, root common.Hash) (Trie, error) {
+	tr, err := trie.NewSecure(root, db)
+	if err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+func (mptCommitment) OpenStorageTrie(db *trie.Database, addrHash, root common.Hash) (Trie, error) {
+	tr, err := trie.NewSecure(root, db)
+	if err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+func (mptCommitment) CopyTrie(t Trie) Trie {
+	switch t := t.(type) {
+	case *trie.SecureTrie:
+		return t.Copy()
+	default:
+		panic(fmt.Errorf("unknown trie type %T", t))
+	}
+}
+
+func (mptCommitment) DecodeAccount(enc []byte) (*types.StateAccount, error) {
+	data := new(types.StateAccount)
+	if err := rlp.DecodeBytes(enc, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (mptCommitment) EncodeStorage(value common.Hash) []byte {
+	// Encoding []byte cannot fail, ok to ignore the error.
+	enc, _ := rlp.EncodeToBytes(common.TrimLeftZeroes(value[:]))
+	return enc
+}
+
+func (mptCommitment) DecodeStorage(enc []byte) (common.Hash, error) {
+	var value common.Hash
+	if len(enc) > 0 {
+		_, content, _, err := rlp.Split(enc)
+		if err != nil {
+			return value, err
+		}
+		value.SetBytes(content)
+	}
+	return value, nil
+}
+
+func (mptCommitment) EmptyStorageRoot() common.Hash {
+	return types.EmptyRootHash
+}
+
+func (mptCommitment) ProveAccount(tr Trie, addr common.Address, proofDb ethdb.KeyValueWriter) error {
+	return tr.Prove(crypto.Keccak256(addr.Bytes()), 0, proofDb)
+}
+
+func (mptCommitment) ProveStorage(tr Trie, slot common.Hash, proofDb ethdb.KeyValueWriter) error {
+	return tr.Prove(crypto.Keccak256(slot.Bytes()), 0, proofDb)
+}
+
+func (c mptCommitment) ProveStorageWithDeletion(tr Trie, slot common.Hash, proofDb ethdb.KeyValueWriter) ([]byte, error) {
+	return nil, c.ProveStorage(tr, slot, proofDb)
+}
+
+type zktrieCommitment struct{}
+
+func (zktrieCommitment) OpenTrie(db *trie.Database, root common.Hash) (Trie, error) {
+	tr, err := trie.NewZkTrie(root, trie.NewZktrieDatabaseFromTriedb(db))
+	if err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+func (c zktrieCommitment) OpenStorageTrie(db *trie.Database, addrHash, root common.Hash) (Trie, error) {
+	return c.OpenTrie(db, root)
+}
+
+func (zktrieCommitment) CopyTrie(t Trie) Trie {
+	switch t := t.(type) {
+	case *trie.ZkTrie:
+		return t.Copy()
+	default:
+		panic(fmt.Errorf("unknown trie type %T", t))
+	}
+}
+
+func (zktrieCommitment) DecodeAccount(enc []byte) (*types.StateAccount, error) {
+	return types.UnmarshalStateAccount(enc)
+}
+
+func (zktrieCommitment) EncodeStorage(value common.Hash) []byte {
+	return common.CopyBytes(value[:])
+}
+
+func (zktrieCommitment) DecodeStorage(enc []byte) (common.Hash, error) {
+	return common.BytesToHash(enc), nil
+}
+
+func (zktrieCommitment) EmptyStorageRoot() common.Hash {
+	return common.Hash{}
+}
+
+func (zktrieCommitment) ProveAccount(tr Trie, addr common.Address, proofDb ethdb.KeyValueWriter) error {
+	key, err := zkt.ToSecureKeyBytes(addr.Bytes())
+	if err != nil {
+		return err
+	}
+	return tr.Prove(common.BytesToHash(key.Bytes()).Bytes(), 0, proofDb)
+}
+
+func (zktrieCommitment) ProveStorage(tr Trie, slot common.Hash, proofDb ethdb.KeyValueWriter) error {
+	key, err := zkt.ToSecureKeyBytes(slot.Bytes())
+	if err != nil {
+		return err
+	}
+	return tr.Prove(key.Bytes(), 0, proofDb)
+}
+
+func (zktrieCommitment) ProveStorageWithDeletion(tr Trie, slot common.Hash, proofDb ethdb.KeyValueWriter) ([]byte, error) {
+	zkTrie, ok := tr.(*trie.ZkTrie)
+	if !ok {
+		return nil, fmt.Errorf("unexpected trie type %T for zktrie", tr)
+	}
+	key, err := zkt.ToSecureKeyBytes(slot.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return zkTrie.ProveWithDeletion(key.Bytes(), 0, proofDb)
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+// Tests that the state database picks the commitment scheme of its trie
+// database, and that storage values and proofs go through it.
+func TestCommitmentSchemes(t *testing.T) {
+	for _, zktrie := range []bool{false, true} {
+		var (
+			sdb  = NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Zktrie: zktrie})
+			want = MPTCommitment
+			addr = common.Address{0x01}
+			slot = common.Hash{0x02}
+		)
+		if zktrie {
+			want = ZktrieCommitment
+		}
+		if sdb.Commitment() != want {
+			t.Fatalf("zktrie %v: commitment mismatch: have %T, want %T", zktrie, sdb.Commitment(), want)
+		}
+		value := common.BigToHash(big.NewInt(0x1234))
+		if have, err := want.DecodeStorage(want.EncodeStorage(value)); err != nil || have != value {
+			t.Fatalf("zktrie %v: storage round trip mismatch: have %x, err %v, want %x", zktrie, have, err, value)
+		}
+		state, _ := New(common.Hash{}, sdb, nil)
+		state.SetBalance(addr, big.NewInt(1))
+		state.SetState(addr, slot, value)
+		root, err := state.Commit(false)
+		if err != nil {
+			t.Fatalf("zktrie %v: failed to commit state: %v", zktrie, err)
+		}
+		state, _ = New(root, sdb, nil)
+		if have := state.GetState(addr, slot); have != value {
+			t.Fatalf("zktrie %v: storage mismatch: have %x, want %x", zktrie, have, value)
+		}
+		if proof, err := state.GetProof(addr); err != nil || len(proof) == 0 {
+			t.Fatalf("zktrie %v: account proof missing: %v", zktrie, err)
+		}
+		if proof, err := state.GetStorageProof(addr, slot); err != nil || len(proof) == 0 {
+			t.Fatalf("zktrie %v: storage proof missing: %v", zktrie, err)
+		}
+	}
+}
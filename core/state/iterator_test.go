@@ -58,7 +58,7 @@ func TestNodeIteratorCoverage(t *testing.T) {
 	it := db.TrieDB().DiskDB().(ethdb.Database).NewIterator(nil, nil)
 	for it.Next() {
 		key := it.Key()
-		if bytes.HasPrefix(key, []byte("secure-key-")) {
+		if bytes.HasPrefix(key, []byte("secure-key-")) || bytes.HasPrefix(key, []byte("pc-")) {
 			continue
 		}
 		if _, ok := hashes[common.BytesToHash(key)]; !ok {
@@ -127,6 +127,9 @@ type (
 	addPreimageChange struct {
 		hash common.Hash
 	}
+	addCreationChange struct {
+		address common.Address
+	}
 	touchChange struct {
 		account *common.Address
 	}
@@ -235,6 +238,14 @@ func (ch addLogChange) dirtied() *common.Address {
 	return nil
 }
 
+func (ch addCreationChange) revert(s *StateDB) {
+	s.creations = s.creations[:len(s.creations)-1]
+}
+
+func (ch addCreationChange) dirtied() *common.Address {
+	return nil
+}
+
 func (ch addPreimageChange) revert(s *StateDB) {
 	delete(s.preimages, ch.hash)
 }
@@ -0,0 +1,63 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"encoding/binary"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+// NonceKeySlot returns the storage slot of the nonce manager holding the nonce
+// of the given nonce key of an account, i.e. keccak256(address ++ key) with the
+// key as 8 big-endian bytes.
+func NonceKeySlot(addr common.Address, key uint64) common.Hash {
+	var enc [common.AddressLength + 8]byte
+	copy(enc[:], addr[:])
+	binary.BigEndian.PutUint64(enc[common.AddressLength:], key)
+	return crypto.Keccak256Hash(enc[:])
+}
+
+// GetNonceKey returns the nonce of the given nonce key of an account, the key 0
+// being the account nonce.
+func (s *StateDB) GetNonceKey(addr common.Address, key uint64) uint64 {
+	if key == 0 {
+		return s.GetNonce(addr)
+	}
+	value := s.GetState(rcfg.NonceManagerAddress, NonceKeySlot(addr, key))
+	return binary.BigEndian.Uint64(value[common.HashLength-8:])
+}
+
+// SetNonceKey sets the nonce of the given nonce key of an account, the key 0
+// being the account nonce. The nonces of the other keys are kept in the storage
+// of the nonce manager.
+func (s *StateDB) SetNonceKey(addr common.Address, key uint64, nonce uint64) {
+	if key == 0 {
+		s.SetNonce(addr, nonce)
+		return
+	}
+	// Like any contract, give the nonce manager a nonce so that it's never
+	// deleted as an empty account
+	if s.GetNonce(rcfg.NonceManagerAddress) == 0 {
+		s.SetNonce(rcfg.NonceManagerAddress, 1)
+	}
+	var value common.Hash
+	binary.BigEndian.PutUint64(value[common.HashLength-8:], nonce)
+	s.SetState(rcfg.NonceManagerAddress, NonceKeySlot(addr, key), value)
+}
@@ -0,0 +1,318 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// historyBloomFilePrefix is the filename prefix of the state bloom filter of
+// the history pruning, distinct from the one of the snapshot based pruning.
+const historyBloomFilePrefix = "historybloom"
+
+// HistoryPruner is an offline tool to convert an archive database into a
+// pruned one in place, retaining only the states of the most recent blocks.
+// Unlike Pruner it doesn't need the snapshot, it walks the retained states in
+// the trie database instead:
+//
+//   - iterate the retained states, committing their entries into the bloom
+//     filter, each one only for the trie nodes changed since the previous one
+//   - iterate the database, deleting all other state entries which don't
+//     belong to the retained states and the genesis state
+//
+// The progress of the deletion is persisted along with it, so an interrupted
+// pruning resumes where it stopped instead of starting over.
+type HistoryPruner struct {
+	db            ethdb.Database
+	stateBloom    *stateBloom
+	datadir       string
+	trieCachePath string
+	headHeader    *types.Header
+}
+
+// NewHistoryPruner creates the history pruner instance.
+func NewHistoryPruner(db ethdb.Database, datadir, trieCachePath string, bloomSize uint64) (*HistoryPruner, error) {
+	headBlock := rawdb.ReadHeadBlock(db)
+	if headBlock == nil {
+		return nil, errors.New("Failed to load head block")
+	}
+	genesisHash := rawdb.ReadCanonicalHash(db, 0)
+	if config := rawdb.ReadChainConfig(db, genesisHash); config != nil && config.Scroll.UseZktrie {
+		return nil, errors.New("state history pruning is not supported with zktrie")
+	}
+	// Sanitize the bloom filter size if it's too small.
+	if bloomSize < 256 {
+		log.Warn("Sanitizing bloomfilter size", "provided(MB)", bloomSize, "updated(MB)", 256)
+		bloomSize = 256
+	}
+	stateBloom, err := newStateBloomWithSize(bloomSize)
+	if err != nil {
+		return nil, err
+	}
+	return &HistoryPruner{
+		db:            db,
+		stateBloom:    stateBloom,
+		datadir:       datadir,
+		trieCachePath: trieCachePath,
+		headHeader:    headBlock.Header(),
+	}, nil
+}
+
+// Prune deletes all historical state nodes except the nodes belonging to the
+// states of the last retain blocks and the genesis. If diffs is set, the
+// access lists, call traces, block stats and contract usage of the blocks whose
+// state is deleted are removed as well. If a previous pruning was interrupted,
+// it is resumed instead, keeping its retained range.
+func (p *HistoryPruner) Prune(retain uint64, diffs bool) error {
+	bloomPath, first, err := findHistoryBloomFilter(p.datadir)
+	if err != nil {
+		return err
+	}
+	if bloomPath != "" {
+		return p.resume(bloomPath, first, diffs)
+	}
+	head := p.headHeader.Number.Uint64()
+	if retain == 0 {
+		return errors.New("at least one state must be retained")
+	}
+	if retain >= head {
+		return fmt.Errorf("nothing to prune: %d states requested, chain has %d beyond genesis", retain, head)
+	}
+	first = head - retain + 1
+
+	// Ensure all the retained states are really present. The weak assumption
+	// is the presence of root can indicate the presence of the entire trie.
+	roots := make([]common.Hash, 0, retain)
+	for number := first; number <= head; number++ {
+		header := rawdb.ReadHeader(p.db, rawdb.ReadCanonicalHash(p.db, number), number)
+		if header == nil {
+			return fmt.Errorf("missing header #%d", number)
+		}
+		if blob := rawdb.ReadTrieNode(p.db, header.Root); len(blob) == 0 {
+			return fmt.Errorf("state of block #%d [%x] is not present", number, header.Root)
+		}
+		roots = append(roots, header.Root)
+	}
+	log.Info("Selecting retained states", "first", first, "head", head)
+
+	// Before start the pruning, delete the clean trie cache first.
+	// It's necessary otherwise in the next restart we will hit the
+	// deleted state root in the "clean cache" so that the incomplete
+	// state is picked for usage.
+	deleteCleanTrieCache(p.trieCachePath)
+
+	// Traverse the retained states, each against its predecessor, and
+	// commit them to the given bloom filter.
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		base   common.Hash
+	)
+	for i, root := range roots {
+		if err := extractState(p.db, base, root, p.stateBloom); err != nil {
+			return err
+		}
+		base = root
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Collecting retained states", "number", first+uint64(i), "head", head, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	// Traverse the genesis, put all genesis state entries into the
+	// bloom filter too.
+	if err := extractGenesis(p.db, p.stateBloom); err != nil {
+		return err
+	}
+	filterName := historyBloomFilterName(p.datadir, first)
+
+	log.Info("Writing state bloom to disk", "name", filterName)
+	if err := p.stateBloom.Commit(filterName, filterName+stateBloomFileTempSuffix); err != nil {
+		return err
+	}
+	log.Info("State bloom filter committed", "name", filterName)
+	return pruneHistory(p.db, p.stateBloom, filterName, first, diffs, start)
+}
+
+// resume continues an interrupted history pruning with the bloom filter it
+// committed to disk. Resuming is mandatory once the bloom filter exists, as a
+// part of the retained states may already be missing to build a new one.
+func (p *HistoryPruner) resume(bloomPath string, first uint64, diffs bool) error {
+	stateBloom, err := NewStateBloomFromDisk(bloomPath)
+	if err != nil {
+		return err
+	}
+	log.Info("Resuming interrupted history pruning", "path", bloomPath, "first", first)
+
+	deleteCleanTrieCache(p.trieCachePath)
+	return pruneHistory(p.db, stateBloom, bloomPath, first, diffs, time.Now())
+}
+
+// pruneHistory deletes the state entries not contained in the bloom filter,
+// continuing from the persisted marker if a previous run was interrupted, and
+// the records of the blocks below first if diffs is set.
+func pruneHistory(maindb ethdb.Database, stateBloom *stateBloom, bloomPath string, first uint64, diffs bool, start time.Time) error {
+	var (
+		count  int
+		size   common.StorageSize
+		pstart = time.Now()
+		logged = time.Now()
+		batch  = maindb.NewBatch()
+		marker = rawdb.ReadHistoryPruningMarker(maindb)
+		iter   = maindb.NewIterator(nil, marker)
+	)
+	if marker != nil {
+		log.Info("Continuing state history deletion", "marker", common.Bytes2Hex(marker))
+	}
+	for iter.Next() {
+		key := iter.Key()
+
+		// All state entries don't belong to the retained states and genesis
+		// are deleted here
+		isCode, codeKey := rawdb.IsCodeKey(key)
+		if len(key) != common.HashLength && !isCode {
+			continue
+		}
+		checkKey := key
+		if isCode {
+			checkKey = codeKey
+		}
+		if ok, err := stateBloom.Contain(checkKey); err != nil {
+			return err
+		} else if ok {
+			continue
+		}
+		count += 1
+		size += common.StorageSize(len(key) + len(iter.Value()))
+		batch.Delete(key)
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning state history", "nodes", count, "size", size, "at", common.Bytes2Hex(key[:4]),
+				"elapsed", common.PrettyDuration(time.Since(pstart)))
+			logged = time.Now()
+		}
+		// Persist the progress with every batch, and recreate the iterator
+		// to allow the underlying compactor to delete the entries.
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			rawdb.WriteHistoryPruningMarker(batch, key)
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+
+			iter.Release()
+			iter = maindb.NewIterator(nil, key)
+		}
+	}
+	err := iter.Error()
+	iter.Release()
+	if err != nil {
+		return err
+	}
+	if batch.ValueSize() > 0 {
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+	log.Info("Pruned state history", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(pstart)))
+
+	// Delete the records of the blocks whose state is gone, they are
+	// idempotent to delete so no progress is tracked.
+	if diffs {
+		for number := uint64(1); number < first; number++ {
+			hash := rawdb.ReadCanonicalHash(maindb, number)
+			rawdb.DeleteBlockAccessList(batch, hash)
+			rawdb.DeleteCallTraces(batch, hash)
+			rawdb.DeleteBlockStats(batch, hash)
+			rawdb.DeleteContractUsage(batch, hash)
+
+			if batch.ValueSize() >= ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+		log.Info("Pruned block records", "blocks", first-1)
+	}
+	// Delete the marker and then the state bloom, which marks the entire
+	// pruning procedure as finished. If any crashes or manual exit happens
+	// before this, the next run will pick it up to redo the remaining work.
+	rawdb.DeleteHistoryPruningMarker(maindb)
+	os.RemoveAll(bloomPath)
+
+	// Start compactions, will remove the deleted data from the disk immediately.
+	// Note for small pruning, the compaction is skipped.
+	if count >= rangeCompactionThreshold {
+		if err := compactDatabase(maindb); err != nil {
+			return err
+		}
+	}
+	log.Info("State history pruning successful", "pruned", size, "first", first, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func historyBloomFilterName(datadir string, first uint64) string {
+	return filepath.Join(datadir, fmt.Sprintf("%s.%d.%s", historyBloomFilePrefix, first, stateBloomFileSuffix))
+}
+
+func isHistoryBloomFilter(filename string) (bool, uint64) {
+	filename = filepath.Base(filename)
+	if strings.HasPrefix(filename, historyBloomFilePrefix+".") && strings.HasSuffix(filename, "."+stateBloomFileSuffix) {
+		first, err := strconv.ParseUint(filename[len(historyBloomFilePrefix)+1:len(filename)-len(stateBloomFileSuffix)-1], 10, 64)
+		if err == nil {
+			return true, first
+		}
+	}
+	return false, 0
+}
+
+func findHistoryBloomFilter(datadir string) (string, uint64, error) {
+	var (
+		bloomPath string
+		first     uint64
+	)
+	if err := filepath.Walk(datadir, func(path string, info os.FileInfo, err error) error {
+		if info != nil && !info.IsDir() {
+			if ok, number := isHistoryBloomFilter(path); ok {
+				bloomPath = path
+				first = number
+			}
+		}
+		return nil
+	}); err != nil {
+		return "", 0, err
+	}
+	return bloomPath, first, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// Tests that pruning the state history of an archive database keeps the
+// retained states and the genesis complete, and deletes the older ones.
+func TestHistoryPruning(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Scroll.MaxTxPerBlock = nil
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		storer = common.Address{0x51} // Sets the slot of the block number
+		db     = rawdb.NewMemoryDatabase()
+		gspec  = &core.Genesis{Config: &config, Alloc: core.GenesisAlloc{
+			addr:   {Balance: big.NewInt(params.Ether)},
+			storer: {Balance: common.Big0, Code: common.FromHex("6001435500")},
+		}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(&config)
+	)
+	blocks, _ := core.GenerateChain(&config, genesis, ethash.NewFaker(), db, 10, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), storer, common.Big1, 50_000, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	chain, err := core.NewBlockChain(db, &core.CacheConfig{TrieDirtyDisabled: true, RecordBlockStats: true}, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	chain.Stop()
+
+	datadir := t.TempDir()
+	pruner, err := NewHistoryPruner(db, datadir, "", 256)
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	if err := pruner.Prune(3, true); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	// The genesis and the last three states are complete
+	sdb := state.NewDatabase(db)
+	for _, root := range []common.Hash{genesis.Root(), blocks[7].Root(), blocks[8].Root(), blocks[9].Root()} {
+		statedb, err := state.New(root, sdb, nil)
+		if err != nil {
+			t.Fatalf("retained state %x missing: %v", root, err)
+		}
+		if statedb.GetBalance(addr).Sign() == 0 || len(statedb.GetCode(storer)) == 0 {
+			t.Fatalf("retained state %x incomplete", root)
+		}
+		if err := statedb.Error(); err != nil {
+			t.Fatalf("retained state %x incomplete: %v", root, err)
+		}
+	}
+	// The older states and their records are gone
+	for _, block := range blocks[:7] {
+		if blob := rawdb.ReadTrieNode(db, block.Root()); len(blob) != 0 {
+			t.Errorf("state of block #%d not pruned", block.NumberU64())
+		}
+		if rawdb.ReadBlockStats(db, block.Hash()) != nil {
+			t.Errorf("block stats of block #%d not pruned", block.NumberU64())
+		}
+	}
+	if rawdb.ReadBlockStats(db, blocks[7].Hash()) == nil {
+		t.Errorf("block stats of retained block #%d pruned", blocks[7].NumberU64())
+	}
+	if marker := rawdb.ReadHistoryPruningMarker(db); marker != nil {
+		t.Errorf("pruning marker left behind: %x", marker)
+	}
+	if path, _, _ := findHistoryBloomFilter(datadir); path != "" {
+		t.Errorf("state bloom left behind: %s", path)
+	}
+}
@@ -207,27 +207,36 @@ func prune(snaptree *snapshot.Tree, root common.Hash, maindb ethdb.Database, sta
 	// Start compactions, will remove the deleted data from the disk immediately.
 	// Note for small pruning, the compaction is skipped.
 	if count >= rangeCompactionThreshold {
-		cstart := time.Now()
-		for b := 0x00; b <= 0xf0; b += 0x10 {
-			var (
-				start = []byte{byte(b)}
-				end   = []byte{byte(b + 0x10)}
-			)
-			if b == 0xf0 {
-				end = nil
-			}
-			log.Info("Compacting database", "range", fmt.Sprintf("%#x-%#x", start, end), "elapsed", common.PrettyDuration(time.Since(cstart)))
-			if err := maindb.Compact(start, end); err != nil {
-				log.Error("Database compaction failed", "error", err)
-				return err
-			}
+		if err := compactDatabase(maindb); err != nil {
+			return err
 		}
-		log.Info("Database compaction finished", "elapsed", common.PrettyDuration(time.Since(cstart)))
 	}
 	log.Info("State pruning successful", "pruned", size, "elapsed", common.PrettyDuration(time.Since(start)))
 	return nil
 }
 
+// compactDatabase compacts the whole key space of the database in ranges,
+// removing deleted entries from the disk.
+func compactDatabase(maindb ethdb.Database) error {
+	cstart := time.Now()
+	for b := 0x00; b <= 0xf0; b += 0x10 {
+		var (
+			start = []byte{byte(b)}
+			end   = []byte{byte(b + 0x10)}
+		)
+		if b == 0xf0 {
+			end = nil
+		}
+		log.Info("Compacting database", "range", fmt.Sprintf("%#x-%#x", start, end), "elapsed", common.PrettyDuration(time.Since(cstart)))
+		if err := maindb.Compact(start, end); err != nil {
+			log.Error("Database compaction failed", "error", err)
+			return err
+		}
+	}
+	log.Info("Database compaction finished", "elapsed", common.PrettyDuration(time.Since(cstart)))
+	return nil
+}
+
 // Prune deletes all historical state nodes except the nodes belong to the
 // specified state version. If user doesn't specify the state version, use
 // the bottom-most snapshot diff layer as the target.
@@ -410,11 +419,28 @@ func extractGenesis(db ethdb.Database, stateBloom *stateBloom) error {
 	if genesis == nil {
 		return errors.New("missing genesis block")
 	}
-	t, err := trie.NewSecure(genesis.Root(), trie.NewDatabase(db))
+	return extractState(db, common.Hash{}, genesis.Root(), stateBloom)
+}
+
+// extractState commits all the state entries of the given state root into the
+// given bloomfilter. If a base root is given, the trie nodes shared with the
+// base state are assumed to be committed already and are skipped.
+func extractState(db ethdb.Database, base, root common.Hash, stateBloom *stateBloom) error {
+	triedb := trie.NewDatabase(db)
+	t, err := trie.New(root, triedb)
 	if err != nil {
 		return err
 	}
-	accIter := t.NodeIterator(nil)
+	var (
+		accIter  = t.NodeIterator(nil)
+		baseTrie *trie.Trie
+	)
+	if base != (common.Hash{}) {
+		if baseTrie, err = trie.New(base, triedb); err != nil {
+			return err
+		}
+		accIter, _ = trie.NewDifferenceIterator(baseTrie.NodeIterator(nil), accIter)
+	}
 	for accIter.Next(true) {
 		hash := accIter.Hash()
 
@@ -429,12 +455,34 @@ func extractGenesis(db ethdb.Database, stateBloom *stateBloom) error {
 			if err := rlp.DecodeBytes(accIter.LeafBlob(), &acc); err != nil {
 				return err
 			}
-			if acc.Root != emptyRoot {
-				storageTrie, err := trie.NewSecure(acc.Root, trie.NewDatabase(db))
+			// Only the storage nodes changed since the base state are new
+			baseRoot := emptyRoot
+			if baseTrie != nil {
+				enc, err := baseTrie.TryGet(accIter.LeafKey())
+				if err != nil {
+					return err
+				}
+				if len(enc) > 0 {
+					var baseAcc types.StateAccount
+					if err := rlp.DecodeBytes(enc, &baseAcc); err != nil {
+						return err
+					}
+					baseRoot = baseAcc.Root
+				}
+			}
+			if acc.Root != emptyRoot && acc.Root != baseRoot {
+				storageTrie, err := trie.New(acc.Root, triedb)
 				if err != nil {
 					return err
 				}
 				storageIter := storageTrie.NodeIterator(nil)
+				if baseRoot != emptyRoot {
+					baseStorage, err := trie.New(baseRoot, triedb)
+					if err != nil {
+						return err
+					}
+					storageIter, _ = trie.NewDifferenceIterator(baseStorage.NodeIterator(nil), storageIter)
+				}
 				for storageIter.Next(true) {
 					hash := storageIter.Hash()
 					if hash != (common.Hash{}) {
@@ -77,6 +77,9 @@ var (
 	snapSuccessfulRangeProofMeter = metrics.NewRegisteredMeter("state/snapshot/generation/proof/success", nil)
 	snapFailedRangeProofMeter     = metrics.NewRegisteredMeter("state/snapshot/generation/proof/failure", nil)
 
+	// snapProgressGauge reports the covered share of the account space in basis points
+	snapProgressGauge = metrics.NewRegisteredGauge("state/snapshot/generation/progress", nil)
+
 	// snapAccountProveCounter measures time spent on the account proving
 	snapAccountProveCounter = metrics.NewRegisteredCounter("state/snapshot/generation/duration/account/prove", nil)
 	// snapAccountTrieReadCounter measures time spent on the account trie iteration
@@ -146,7 +149,7 @@ func (gs *generatorStats) Log(msg string, root common.Hash, marker []byte) {
 // generateSnapshot regenerates a brand new snapshot based on an existing state
 // database and head block asynchronously. The snapshot is returned immediately
 // and generation is continued in the background until done.
-func generateSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int, root common.Hash) *diskLayer {
+func generateSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int, root common.Hash, throttle *Throttle) *diskLayer {
 	// Create a new disk layer with an initialized state marker at zero
 	var (
 		stats     = &generatorStats{start: time.Now()}
@@ -166,12 +169,22 @@ func generateSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache i
 		genMarker:  genMarker,
 		genPending: make(chan struct{}),
 		genAbort:   make(chan chan *generatorStats),
+		throttle:   throttle,
 	}
 	go base.generate(stats)
 	log.Debug("Start snapshot generation", "root", root)
 	return base
 }
 
+// markerProgress estimates the percentage of the account space covered by the
+// generator from its marker.
+func markerProgress(marker []byte) float64 {
+	if len(marker) < 8 {
+		return 0
+	}
+	return float64(binary.BigEndian.Uint64(marker[:8])) / float64(math.MaxUint64) * 100
+}
+
 // journalProgress persists the generator stats into the database to resume later.
 func journalProgress(db ethdb.KeyValueWriter, marker []byte, stats *generatorStats) {
 	// Write out the generator marker. Note it's a standalone disk layer generator
@@ -557,9 +570,13 @@ func (dl *diskLayer) generate(stats *generatorStats) {
 	stats.Log("Resuming state snapshot generation", dl.root, dl.genMarker)
 
 	checkAndFlush := func(currentLocation []byte) error {
-		select {
-		case abort = <-dl.genAbort:
-		default:
+		// Yield to block production, unless asked to abort meanwhile
+		abort = dl.throttle.wait(dl.genAbort)
+		if abort == nil {
+			select {
+			case abort = <-dl.genAbort:
+			default:
+			}
 		}
 		if batch.ValueSize() > ethdb.IdealBatchSize || abort != nil {
 			if bytes.Compare(currentLocation, dl.genMarker) < 0 {
@@ -582,6 +599,8 @@ func (dl *diskLayer) generate(stats *generatorStats) {
 			dl.genMarker = currentLocation
 			dl.lock.Unlock()
 
+			snapProgressGauge.Update(int64(markerProgress(currentLocation) * 100))
+
 			if abort != nil {
 				stats.Log("Aborting state snapshot generation", dl.root, currentLocation)
 				return errors.New("aborted")
@@ -761,6 +780,8 @@ func (dl *diskLayer) generate(stats *generatorStats) {
 	close(dl.genPending)
 	dl.lock.Unlock()
 
+	snapProgressGauge.Update(100 * 100)
+
 	// Someone will be looking for us, wait it out
 	abort = <-dl.genAbort
 	abort <- nil
@@ -67,7 +67,7 @@ func TestGeneration(t *testing.T) {
 	if have, want := root, common.HexToHash("0x0bc6b6959d2589404dd3e4b25783a829b58625f6b673f095e9a97391b474c3f9"); have != want {
 		t.Fatalf("have %#x want %#x", have, want)
 	}
-	snap := generateSnapshot(diskdb, triedb, 16, root)
+	snap := generateSnapshot(diskdb, triedb, 16, root, nil)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -132,7 +132,7 @@ func TestGenerateExistentState(t *testing.T) {
 	root, _, _ := accTrie.Commit(nil) // Root: 0xe3712f1a226f3782caca78ca770ccc19ee000552813a9f59d479f8611db9b1fd
 	triedb.Commit(root, false, nil)
 
-	snap := generateSnapshot(diskdb, triedb, 16, root)
+	snap := generateSnapshot(diskdb, triedb, 16, root, nil)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -223,7 +223,7 @@ func (t *testHelper) makeStorageTrie(keys []string, vals []string) []byte {
 func (t *testHelper) Generate() (common.Hash, *diskLayer) {
 	root, _, _ := t.accTrie.Commit(nil)
 	t.triedb.Commit(root, false, nil)
-	snap := generateSnapshot(t.diskdb, t.triedb, 16, root)
+	snap := generateSnapshot(t.diskdb, t.triedb, 16, root, nil)
 	return root, snap
 }
 
@@ -403,7 +403,7 @@ func TestGenerateCorruptAccountTrie(t *testing.T) {
 	triedb.Commit(common.HexToHash("0xa04693ea110a31037fb5ee814308a6f1d76bdab0b11676bdf4541d2de55ba978"), false, nil)
 	diskdb.Delete(common.HexToHash("0x65145f923027566669a1ae5ccac66f945b55ff6eaeb17d2ea8e048b7d381f2d7").Bytes())
 
-	snap := generateSnapshot(diskdb, triedb, 16, common.HexToHash("0xa04693ea110a31037fb5ee814308a6f1d76bdab0b11676bdf4541d2de55ba978"))
+	snap := generateSnapshot(diskdb, triedb, 16, common.HexToHash("0xa04693ea110a31037fb5ee814308a6f1d76bdab0b11676bdf4541d2de55ba978"), nil)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -463,7 +463,7 @@ func TestGenerateMissingStorageTrie(t *testing.T) {
 	// Delete a storage trie root and ensure the generator chokes
 	diskdb.Delete(common.HexToHash("0xddefcd9376dd029653ef384bd2f0a126bb755fe84fdcc9e7cf421ba454f2bc67").Bytes())
 
-	snap := generateSnapshot(diskdb, triedb, 16, common.HexToHash("0xe3712f1a226f3782caca78ca770ccc19ee000552813a9f59d479f8611db9b1fd"))
+	snap := generateSnapshot(diskdb, triedb, 16, common.HexToHash("0xe3712f1a226f3782caca78ca770ccc19ee000552813a9f59d479f8611db9b1fd"), nil)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -522,7 +522,7 @@ func TestGenerateCorruptStorageTrie(t *testing.T) {
 	// Delete a storage trie leaf and ensure the generator chokes
 	diskdb.Delete(common.HexToHash("0x18a0f4d79cff4459642dd7604f303886ad9d77c30cf3d7d7cedb3a693ab6d371").Bytes())
 
-	snap := generateSnapshot(diskdb, triedb, 16, common.HexToHash("0xe3712f1a226f3782caca78ca770ccc19ee000552813a9f59d479f8611db9b1fd"))
+	snap := generateSnapshot(diskdb, triedb, 16, common.HexToHash("0xe3712f1a226f3782caca78ca770ccc19ee000552813a9f59d479f8611db9b1fd"), nil)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -586,7 +586,7 @@ func TestGenerateWithExtraAccounts(t *testing.T) {
 		t.Fatalf("expected snap storage to exist")
 	}
 
-	snap := generateSnapshot(diskdb, triedb, 16, root)
+	snap := generateSnapshot(diskdb, triedb, 16, root, nil)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -644,7 +644,7 @@ func TestGenerateWithManyExtraAccounts(t *testing.T) {
 	t.Logf("root: %x", root)
 	triedb.Commit(root, false, nil)
 
-	snap := generateSnapshot(diskdb, triedb, 16, root)
+	snap := generateSnapshot(diskdb, triedb, 16, root, nil)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -697,7 +697,7 @@ func TestGenerateWithExtraBeforeAndAfter(t *testing.T) {
 	t.Logf("root: %x", root)
 	triedb.Commit(root, false, nil)
 
-	snap := generateSnapshot(diskdb, triedb, 16, root)
+	snap := generateSnapshot(diskdb, triedb, 16, root, nil)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
@@ -741,7 +741,7 @@ func TestGenerateWithMalformedSnapdata(t *testing.T) {
 	t.Logf("root: %x", root)
 	triedb.Commit(root, false, nil)
 
-	snap := generateSnapshot(diskdb, triedb, 16, root)
+	snap := generateSnapshot(diskdb, triedb, 16, root, nil)
 	select {
 	case <-snap.genPending:
 		// Snapshot generation succeeded
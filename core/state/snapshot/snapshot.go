@@ -25,6 +25,7 @@ import (
 	"sync/atomic"
 
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/log"
@@ -164,6 +165,8 @@ type Tree struct {
 	layers map[common.Hash]snapshot // Collection of all known layers
 	lock   sync.RWMutex
 
+	throttle *Throttle // Throttle pausing generation during block production
+
 	// Test hooks
 	onFlatten func() // Hook invoked when the bottom most diff layers are flattened
 }
@@ -189,16 +192,17 @@ func New(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int, root comm
 		panic("zktrie does not support snapshot yet")
 	}
 	snap := &Tree{
-		diskdb: diskdb,
-		triedb: triedb,
-		cache:  cache,
-		layers: make(map[common.Hash]snapshot),
+		diskdb:   diskdb,
+		triedb:   triedb,
+		cache:    cache,
+		layers:   make(map[common.Hash]snapshot),
+		throttle: NewThrottle(),
 	}
 	if !async {
 		defer snap.waitBuild()
 	}
 	// Attempt to load a previously persisted snapshot and rebuild one if failed
-	head, disabled, err := loadSnapshot(diskdb, triedb, cache, root, recovery)
+	head, disabled, err := loadSnapshot(diskdb, triedb, cache, root, recovery, snap.throttle)
 	if disabled {
 		log.Warn("Snapshot maintenance disabled (syncing)")
 		return snap, nil
@@ -636,6 +640,7 @@ func diffToDisk(bottom *diffLayer) *diskLayer {
 		triedb:     base.triedb,
 		genMarker:  base.genMarker,
 		genPending: base.genPending,
+		throttle:   base.throttle,
 	}
 	// If snapshot generation hasn't finished yet, port over all the starts and
 	// continue where the previous round left off.
@@ -731,7 +736,7 @@ func (t *Tree) Rebuild(root common.Hash) {
 	// generator will run a wiper first if there's not one running right now.
 	log.Info("Rebuilding state snapshot")
 	t.layers = map[common.Hash]snapshot{
-		root: generateSnapshot(t.diskdb, t.triedb, t.cache, root),
+		root: generateSnapshot(t.diskdb, t.triedb, t.cache, root, t.throttle),
 	}
 }
 
@@ -839,6 +844,53 @@ func (t *Tree) generating() (bool, error) {
 	return layer.genMarker != nil, nil
 }
 
+// Throttle returns the throttle through which block production pauses the
+// snapshot generation. A nil tree has no throttle.
+func (t *Tree) Throttle() *Throttle {
+	if t == nil {
+		return nil
+	}
+	return t.throttle
+}
+
+// GeneratorProgress is the progress of the snapshot generation.
+type GeneratorProgress struct {
+	Root       common.Hash   `json:"root"`       // State root the snapshot is generated for
+	Generating bool          `json:"generating"` // Whether generation is still in progress
+	Marker     hexutil.Bytes `json:"marker"`     // Account (and storage slot) generated up to
+	Progress   float64       `json:"progress"`   // Percentage of the account space covered
+	Throttled  bool          `json:"throttled"`  // Whether generation is paused for block production
+	Paused     string        `json:"paused"`     // Total time generation spent paused
+}
+
+// Progress returns the progress of the snapshot generation.
+func (t *Tree) Progress() (*GeneratorProgress, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	layer := t.disklayer()
+	if layer == nil {
+		return nil, errors.New("disk layer is missing")
+	}
+	layer.lock.RLock()
+	marker := common.CopyBytes(layer.genMarker)
+	generating := layer.genMarker != nil
+	layer.lock.RUnlock()
+
+	progress := 100.0
+	if generating {
+		progress = markerProgress(marker)
+	}
+	return &GeneratorProgress{
+		Root:       layer.root,
+		Generating: generating,
+		Marker:     marker,
+		Progress:   progress,
+		Throttled:  t.throttle.Paused(),
+		Paused:     common.PrettyDuration(t.throttle.PausedTime()).String(),
+	}, nil
+}
+
 // diskRoot is a external helper function to return the disk layer root.
 func (t *Tree) DiskRoot() common.Hash {
 	t.lock.Lock()
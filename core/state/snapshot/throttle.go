@@ -0,0 +1,124 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+var (
+	// snapThrottledGauge reports whether the generator is currently paused.
+	snapThrottledGauge = metrics.NewRegisteredGauge("state/snapshot/generation/throttled", nil)
+	// snapThrottledCounter measures the time the generator spent paused
+	snapThrottledCounter = metrics.NewRegisteredCounter("state/snapshot/generation/duration/throttled", nil)
+)
+
+// Throttle pauses snapshot generation while blocks are being assembled or
+// committed, so that a (re)generation after a crash doesn't compete with block
+// production for disk and CPU. Generation resumes in the idle gaps in between.
+//
+// The generator checks the throttle between every account and storage slot it
+// processes, so it yields within a few milliseconds of a window starting. A
+// request to abort the generator is always served, even while it's paused.
+type Throttle struct {
+	busy   int           // Number of block production windows in progress
+	resume chan struct{} // Closed when the last window in progress ends
+	lock   sync.Mutex
+
+	paused      int32 // Whether the generator is currently paused (atomic)
+	pausedTotal int64 // Nanoseconds the generator spent paused (atomic)
+}
+
+// NewThrottle creates a throttle without any block production in progress.
+func NewThrottle() *Throttle {
+	return &Throttle{}
+}
+
+// Begin marks the start of a block production window, pausing the generator
+// until the returned function is called to mark its end.
+func (t *Throttle) Begin() func() {
+	if t == nil {
+		return func() {}
+	}
+	t.lock.Lock()
+	if t.busy == 0 {
+		t.resume = make(chan struct{})
+	}
+	t.busy++
+	t.lock.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+
+			if t.busy--; t.busy == 0 {
+				close(t.resume)
+			}
+		})
+	}
+}
+
+// Paused reports whether the generator is currently waiting for block
+// production to finish.
+func (t *Throttle) Paused() bool {
+	return t != nil && atomic.LoadInt32(&t.paused) == 1
+}
+
+// PausedTime returns the total time the generator spent paused.
+func (t *Throttle) PausedTime() time.Duration {
+	if t == nil {
+		return 0
+	}
+	return time.Duration(atomic.LoadInt64(&t.pausedTotal))
+}
+
+// wait blocks the generator while block production is in progress. If an abort
+// request arrives meanwhile, it's returned for the generator to act upon.
+func (t *Throttle) wait(abort chan chan *generatorStats) chan *generatorStats {
+	if t == nil {
+		return nil
+	}
+	t.lock.Lock()
+	if t.busy == 0 {
+		t.lock.Unlock()
+		return nil
+	}
+	resume := t.resume
+	t.lock.Unlock()
+
+	atomic.StoreInt32(&t.paused, 1)
+	snapThrottledGauge.Update(1)
+	defer func(start time.Time) {
+		atomic.StoreInt32(&t.paused, 0)
+		atomic.AddInt64(&t.pausedTotal, int64(time.Since(start)))
+		snapThrottledGauge.Update(0)
+		snapThrottledCounter.Inc(time.Since(start).Nanoseconds())
+	}(time.Now())
+
+	select {
+	case <-resume:
+		return nil
+	case req := <-abort:
+		return req
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+// newThrottleTestState creates a state with a few accounts to generate the
+// snapshot of.
+func newThrottleTestState() (*testHelper, common.Hash) {
+	helper := newHelper()
+	for i := 0; i < 16; i++ {
+		helper.addTrieAccount(fmt.Sprintf("acc-%d", i), &Account{Balance: big.NewInt(int64(i)), Root: emptyRoot.Bytes(), KeccakCodeHash: emptyKeccakCode.Bytes(), PoseidonCodeHash: emptyPoseidonCode.Bytes()})
+	}
+	root, _, _ := helper.accTrie.Commit(nil)
+	helper.triedb.Commit(root, false, nil)
+	return helper, root
+}
+
+// Tests that snapshot generation is paused during block production and resumes
+// once it ends.
+func TestGenerateThrottled(t *testing.T) {
+	helper, root := newThrottleTestState()
+
+	throttle := NewThrottle()
+	end := throttle.Begin()
+	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root, throttle)
+
+	select {
+	case <-snap.genPending:
+		t.Fatal("snapshot generated during block production")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if !throttle.Paused() {
+		t.Fatal("generator not reported as paused")
+	}
+	end()
+	end() // Ending a window twice is harmless
+
+	select {
+	case <-snap.genPending:
+	case <-time.After(3 * time.Second):
+		t.Fatal("snapshot generation not resumed")
+	}
+	if throttle.Paused() || throttle.PausedTime() < 50*time.Millisecond {
+		t.Fatalf("pause accounting mismatch: paused %v for %v", throttle.Paused(), throttle.PausedTime())
+	}
+	checkSnapRoot(t, snap, root)
+
+	stop := make(chan *generatorStats)
+	snap.genAbort <- stop
+	<-stop
+}
+
+// Tests that a paused generator can still be aborted, as needed to flatten diff
+// layers into the disk layer while committing a block.
+func TestGenerateThrottledAbort(t *testing.T) {
+	helper, root := newThrottleTestState()
+
+	throttle := NewThrottle()
+	defer throttle.Begin()()
+	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root, throttle)
+
+	stop := make(chan *generatorStats)
+	select {
+	case snap.genAbort <- stop:
+	case <-time.After(3 * time.Second):
+		t.Fatal("paused generator didn't accept abort")
+	}
+	if stats := <-stop; stats == nil {
+		t.Fatal("aborted generator reported completion")
+	}
+	snap.lock.RLock()
+	defer snap.lock.RUnlock()
+	if snap.genMarker == nil {
+		t.Fatal("aborted generator lost its marker")
+	}
+}
+
+// Tests that the tree reports the generation progress.
+func TestTreeProgress(t *testing.T) {
+	helper, root := newThrottleTestState()
+
+	tree := &Tree{
+		diskdb:   helper.diskdb,
+		triedb:   trie.NewDatabase(helper.diskdb),
+		throttle: NewThrottle(),
+	}
+	end := tree.Throttle().Begin()
+	snap := generateSnapshot(helper.diskdb, helper.triedb, 16, root, tree.throttle)
+	tree.layers = map[common.Hash]snapshot{root: snap}
+
+	progress, err := tree.Progress()
+	if err != nil {
+		t.Fatalf("failed to retrieve progress: %v", err)
+	}
+	if !progress.Generating || progress.Root != root || progress.Progress == 100 {
+		t.Fatalf("progress mismatch during generation: %+v", progress)
+	}
+	end()
+	<-snap.genPending
+
+	if progress, _ = tree.Progress(); progress.Generating || progress.Progress != 100 || progress.Throttled {
+		t.Fatalf("progress mismatch after generation: %+v", progress)
+	}
+	stop := make(chan *generatorStats)
+	snap.genAbort <- stop
+	<-stop
+}
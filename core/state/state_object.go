@@ -196,11 +196,15 @@ func (s *stateObject) GetCommittedState(db Database, key common.Hash) common.Has
 	}
 	// If we have a pending write or clean cached, return that
 	if value, pending := s.pendingStorage[key]; pending {
+		s.db.StorageHits++
 		return value
 	}
 	if value, cached := s.originStorage[key]; cached {
+		s.db.StorageHits++
 		return value
 	}
+	s.db.StorageMisses++
+
 	// If no live objects are available, attempt to use snapshots
 	var (
 		enc   []byte
@@ -249,17 +253,9 @@ func (s *stateObject) GetCommittedState(db Database, key common.Hash) common.Has
 			return common.Hash{}
 		}
 	}
-	var value common.Hash
-	if db.TrieDB().Zktrie {
-		value = common.BytesToHash(enc)
-	} else {
-		if len(enc) > 0 {
-			_, content, _, err := rlp.Split(enc)
-			if err != nil {
-				s.setError(err)
-			}
-			value.SetBytes(content)
-		}
+	value, err := db.Commitment().DecodeStorage(enc)
+	if err != nil {
+		s.setError(err)
 	}
 	s.originStorage[key] = value
 	return value
@@ -347,22 +343,21 @@ func (s *stateObject) updateTrie(db Database) Trie {
 	usedStorage := make([][]byte, 0, len(s.pendingStorage))
 	for key, value := range s.pendingStorage {
 		// Skip noop changes, persist actual changes
-		if value == s.originStorage[key] {
+		origin := s.originStorage[key]
+		if value == origin {
 			continue
 		}
 		s.originStorage[key] = value
+		if (origin == common.Hash{}) || (value == common.Hash{}) {
+			s.db.addStorageGrowth(s.address, value == common.Hash{})
+		}
 
 		var v []byte
 		if (value == common.Hash{}) {
 			s.setError(tr.TryDelete(key[:]))
 			s.db.StorageDeleted += 1
 		} else {
-			if db.TrieDB().Zktrie {
-				v = common.CopyBytes(value[:])
-			} else {
-				// Encoding []byte cannot fail, ok to ignore the error.
-				v, _ = rlp.EncodeToBytes(common.TrimLeftZeroes(value[:]))
-			}
+			v = db.Commitment().EncodeStorage(value)
 			s.setError(tr.TryUpdate(key[:], v))
 			s.db.StorageUpdated += 1
 		}
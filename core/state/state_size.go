@@ -0,0 +1,61 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import "github.com/scroll-tech/go-ethereum/core/types"
+
+// StartSizeTracking enables counting the accounts created and deleted and the
+// code bytes added and removed as the account trie is updated from now on.
+func (s *StateDB) StartSizeTracking() {
+	s.sizeGrowth = new(types.StateSize)
+}
+
+// SizeGrowth returns the accounts, storage slots and code bytes added to and
+// removed from the state as of the last trie update since StartSizeTracking was
+// called, or nil if tracking is not enabled. Storage slots wiped by
+// self-destructs are not counted.
+func (s *StateDB) SizeGrowth() *types.StateSize {
+	if s.sizeGrowth == nil {
+		return nil
+	}
+	growth := *s.sizeGrowth
+	for _, slots := range s.storageGrowth {
+		growth.SlotsCreated += slots.SlotsCreated
+		growth.SlotsCleared += slots.SlotsCleared
+	}
+	return &growth
+}
+
+// trackSize counts the change of an account about to be written into or
+// deleted from the account trie.
+func (s *StateDB) trackSize(obj *stateObject) {
+	prev := s.readTrieAccount(obj.address, "trackSize")
+	switch {
+	case obj.deleted && prev != nil:
+		s.sizeGrowth.AccountsDeleted++
+		s.sizeGrowth.CodeBytesRemoved += prev.CodeSize
+	case obj.deleted:
+		// Created and deleted since the last update
+	case prev == nil:
+		s.sizeGrowth.AccountsCreated++
+		s.sizeGrowth.CodeBytesAdded += obj.data.CodeSize
+	case obj.data.CodeSize > prev.CodeSize:
+		s.sizeGrowth.CodeBytesAdded += obj.data.CodeSize - prev.CodeSize
+	case obj.data.CodeSize < prev.CodeSize:
+		s.sizeGrowth.CodeBytesRemoved += prev.CodeSize - obj.data.CodeSize
+	}
+}
@@ -24,8 +24,6 @@ import (
 	"sort"
 	"time"
 
-	zkt "github.com/scroll-tech/zktrie/types"
-
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/state/snapshot"
@@ -91,11 +89,24 @@ type StateDB struct {
 	logs    map[common.Hash][]*types.Log
 	logSize uint
 
+	creations []*types.ContractCreation
+
+	// Storage slots set from zero and reset to zero by account, counted as the
+	// storage tries are updated
+	storageGrowth map[common.Address]*types.ContractUsage
+
+	// Accounts and code added and removed, counted as the account trie is
+	// updated if size tracking is enabled
+	sizeGrowth *types.StateSize
+
 	preimages map[common.Hash][]byte
 
 	// Per-transaction access list
 	accessList *accessList
 
+	// Block-wide record of all state accesses, nil if not recording
+	accessRecorder *accessRecorder
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -119,6 +130,12 @@ type StateDB struct {
 	StorageUpdated int
 	AccountDeleted int
 	StorageDeleted int
+
+	// Lookups served by the live objects (hits) or loaded from snapshot or trie
+	AccountHits   int
+	AccountMisses int
+	StorageHits   int
+	StorageMisses int
 }
 
 // New creates a new state from a given trie.
@@ -184,8 +201,9 @@ func (s *StateDB) Error() error {
 	return s.dbErr
 }
 
-func (s *StateDB) IsZktrie() bool {
-	return s.db.TrieDB().Zktrie
+// Commitment returns the scheme committing to the state.
+func (s *StateDB) Commitment() Commitment {
+	return s.db.Commitment()
 }
 
 func (s *StateDB) AddLog(log *types.Log) {
@@ -214,6 +232,44 @@ func (s *StateDB) Logs() []*types.Log {
 	return logs
 }
 
+// AddCreation records a contract created by the current transaction.
+func (s *StateDB) AddCreation(creation *types.ContractCreation) {
+	s.journal.append(addCreationChange{address: creation.Address})
+
+	creation.TxHash = s.thash
+	s.creations = append(s.creations, creation)
+}
+
+// Creations returns the contracts created so far, in execution order.
+func (s *StateDB) Creations() []*types.ContractCreation {
+	return s.creations
+}
+
+// addStorageGrowth counts a storage slot of the given account set from zero,
+// or reset to zero if cleared is set.
+func (s *StateDB) addStorageGrowth(addr common.Address, cleared bool) {
+	if s.storageGrowth == nil {
+		s.storageGrowth = make(map[common.Address]*types.ContractUsage)
+	}
+	growth := s.storageGrowth[addr]
+	if growth == nil {
+		growth = &types.ContractUsage{Address: addr}
+		s.storageGrowth[addr] = growth
+	}
+	if cleared {
+		growth.SlotsCleared++
+	} else {
+		growth.SlotsCreated++
+	}
+}
+
+// StorageGrowth returns the storage slots set from zero and reset to zero by
+// account, as of the last storage trie update. Slots wiped by self-destructs
+// are not counted.
+func (s *StateDB) StorageGrowth() map[common.Address]*types.ContractUsage {
+	return s.storageGrowth
+}
+
 // AddPreimage records a SHA3 preimage seen by the VM.
 func (s *StateDB) AddPreimage(hash common.Hash, preimage []byte) {
 	if _, ok := s.preimages[hash]; !ok {
@@ -284,6 +340,9 @@ func (s *StateDB) TxIndex() int {
 func (s *StateDB) GetCode(addr common.Address) []byte {
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
+		if s.accessRecorder != nil {
+			s.accessRecorder.addCode(addr, common.BytesToHash(stateObject.KeccakCodeHash()))
+		}
 		return stateObject.Code(s.db)
 	}
 	return nil
@@ -315,6 +374,9 @@ func (s *StateDB) GetKeccakCodeHash(addr common.Address) common.Hash {
 
 // GetState retrieves a value from the given account's storage trie.
 func (s *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
+	if s.accessRecorder != nil {
+		s.accessRecorder.addSlot(addr, hash)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.GetState(s.db, hash)
@@ -324,11 +386,9 @@ func (s *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
 
 // GetProof returns the Merkle proof for a given account.
 func (s *StateDB) GetProof(addr common.Address) ([][]byte, error) {
-	if s.IsZktrie() {
-		addr_s, _ := zkt.ToSecureKeyBytes(addr.Bytes())
-		return s.GetProofByHash(common.BytesToHash(addr_s.Bytes()))
-	}
-	return s.GetProofByHash(crypto.Keccak256Hash(addr.Bytes()))
+	var proof proofList
+	err := s.Commitment().ProveAccount(s.trie, addr, &proof)
+	return proof, err
 }
 
 // GetProofByHash returns the Merkle proof for a given account.
@@ -371,17 +431,7 @@ func (s *StateDB) GetStorageTrieProof(a common.Address, key common.Hash) ([][]by
 	}
 
 	var proof proofList
-	var sibling []byte
-	if s.IsZktrie() {
-		zkTrie := trieS.(*trie.ZkTrie)
-		if zkTrie == nil {
-			panic("unexpected trie type for zktrie")
-		}
-		key_s, _ := zkt.ToSecureKeyBytes(key.Bytes())
-		sibling, err = zkTrie.ProveWithDeletion(key_s.Bytes(), 0, &proof)
-	} else {
-		err = trieS.Prove(crypto.Keccak256(key.Bytes()), 0, &proof)
-	}
+	sibling, err := s.Commitment().ProveStorageWithDeletion(trieS, key, &proof)
 	return proof, sibling, err
 }
 
@@ -392,18 +442,15 @@ func (s *StateDB) GetStorageProof(a common.Address, key common.Hash) ([][]byte,
 	if trie == nil {
 		return proof, errors.New("storage trie for requested address does not exist")
 	}
-	var err error
-	if s.IsZktrie() {
-		key_s, _ := zkt.ToSecureKeyBytes(key.Bytes())
-		err = trie.Prove(key_s.Bytes(), 0, &proof)
-	} else {
-		err = trie.Prove(crypto.Keccak256(key.Bytes()), 0, &proof)
-	}
+	err := s.Commitment().ProveStorage(trie, key, &proof)
 	return proof, err
 }
 
 // GetCommittedState retrieves a value from the given account's committed storage trie.
 func (s *StateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
+	if s.accessRecorder != nil {
+		s.accessRecorder.addSlot(addr, hash)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.GetCommittedState(s.db, hash)
@@ -478,6 +525,9 @@ func (s *StateDB) SetCode(addr common.Address, code []byte) {
 }
 
 func (s *StateDB) SetState(addr common.Address, key, value common.Hash) {
+	if s.accessRecorder != nil {
+		s.accessRecorder.addSlot(addr, key)
+	}
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetState(s.db, key, value)
@@ -567,10 +617,16 @@ func (s *StateDB) getStateObject(addr common.Address) *stateObject {
 // flag set. This is needed by the state journal to revert to the correct s-
 // destructed object instead of wiping all knowledge about the state object.
 func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
+	if s.accessRecorder != nil {
+		s.accessRecorder.addAddress(addr)
+	}
 	// Prefer live objects if any is available
 	if obj := s.stateObjects[addr]; obj != nil {
+		s.AccountHits++
 		return obj
 	}
+	s.AccountMisses++
+
 	// If no live objects are available, attempt to use snapshots
 	var (
 		data *types.StateAccount
@@ -608,22 +664,7 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 		if metrics.EnabledExpensive {
 			defer func(start time.Time) { s.AccountReads += time.Since(start) }(time.Now())
 		}
-		enc, err := s.trie.TryGet(addr.Bytes())
-		if err != nil {
-			s.setError(fmt.Errorf("getDeleteStateObject (%x) error: %v", addr.Bytes(), err))
-			return nil
-		}
-		if len(enc) == 0 {
-			return nil
-		}
-		if s.IsZktrie() {
-			data, err = types.UnmarshalStateAccount(enc)
-		} else {
-			data = new(types.StateAccount)
-			err = rlp.DecodeBytes(enc, data)
-		}
-		if err != nil {
-			log.Error("Failed to decode state object", "addr", addr, "err", err)
+		if data = s.readTrieAccount(addr, "getDeleteStateObject"); data == nil {
 			return nil
 		}
 	}
@@ -633,6 +674,25 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 	return obj
 }
 
+// readTrieAccount retrieves an account from the account trie, or nil if it
+// doesn't exist or can't be loaded.
+func (s *StateDB) readTrieAccount(addr common.Address, caller string) *types.StateAccount {
+	enc, err := s.trie.TryGet(addr.Bytes())
+	if err != nil {
+		s.setError(fmt.Errorf("%s (%x) error: %v", caller, addr.Bytes(), err))
+		return nil
+	}
+	if len(enc) == 0 {
+		return nil
+	}
+	data, err := s.Commitment().DecodeAccount(enc)
+	if err != nil {
+		log.Error("Failed to decode state object", "addr", addr, "err", err)
+		return nil
+	}
+	return data
+}
+
 func (s *StateDB) setStateObject(object *stateObject) {
 	s.stateObjects[object.Address()] = object
 }
@@ -773,6 +833,24 @@ func (s *StateDB) Copy() *StateDB {
 		}
 		state.logs[hash] = cpy
 	}
+	if len(s.creations) > 0 {
+		state.creations = make([]*types.ContractCreation, len(s.creations))
+		for i, c := range s.creations {
+			cpy := *c
+			state.creations[i] = &cpy
+		}
+	}
+	if s.sizeGrowth != nil {
+		growth := *s.sizeGrowth
+		state.sizeGrowth = &growth
+	}
+	if len(s.storageGrowth) > 0 {
+		state.storageGrowth = make(map[common.Address]*types.ContractUsage, len(s.storageGrowth))
+		for addr, growth := range s.storageGrowth {
+			cpy := *growth
+			state.storageGrowth[addr] = &cpy
+		}
+	}
 	for hash, preimage := range s.preimages {
 		state.preimages[hash] = preimage
 	}
@@ -782,6 +860,9 @@ func (s *StateDB) Copy() *StateDB {
 	// However, it doesn't cost us much to copy an empty list, so we do it anyway
 	// to not blow up if we ever decide copy it in the middle of a transaction
 	state.accessList = s.accessList.Copy()
+	if s.accessRecorder != nil {
+		state.accessRecorder = s.accessRecorder.copy()
+	}
 
 	// If there's a prefetcher running, make an inactive copy of it that can
 	// only access data but does not actively preload (since the user will not
@@ -933,7 +1014,11 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	}
 	usedAddrs := make([][]byte, 0, len(s.stateObjectsPending))
 	for addr := range s.stateObjectsPending {
-		if obj := s.stateObjects[addr]; obj.deleted {
+		obj := s.stateObjects[addr]
+		if s.sizeGrowth != nil {
+			s.trackSize(obj)
+		}
+		if obj.deleted {
 			s.deleteStateObject(obj)
 			s.AccountDeleted += 1
 		} else {
@@ -987,6 +1072,7 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (common.Hash, error) {
 			// Write any contract code associated with the state object
 			if obj.code != nil && obj.dirtyCode {
 				rawdb.WriteCode(codeWriter, common.BytesToHash(obj.KeccakCodeHash()), obj.code)
+				rawdb.WritePoseidonCodeHash(codeWriter, common.BytesToHash(obj.PoseidonCodeHash()), common.BytesToHash(obj.KeccakCodeHash()))
 				obj.dirtyCode = false
 			}
 			// Write any storage changes in the state object to its storage trie
@@ -916,3 +916,23 @@ func TestStateDBAccessList(t *testing.T) {
 		t.Fatalf("expected empty, got %d", got)
 	}
 }
+
+// Tests that committed contract code can be looked up by its Poseidon code hash.
+func TestCommitPoseidonCodeHash(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(db), nil)
+
+	addr := common.Address{0x01}
+	code := []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+	state.SetCode(addr, code)
+	if _, err := state.Commit(false); err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	poseidonHash, keccakHash := state.GetPoseidonCodeHash(addr), state.GetKeccakCodeHash(addr)
+	if have := rawdb.ReadKeccakCodeHash(db, poseidonHash); have != keccakHash {
+		t.Fatalf("keccak code hash mismatch: have %x, want %x", have, keccakHash)
+	}
+	if have := rawdb.ReadCodeByPoseidonHash(db, poseidonHash); !bytes.Equal(have, code) {
+		t.Fatalf("code mismatch: have %x, want %x", have, code)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/core/vm"
 	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/internal/failpoint"
 	"github.com/scroll-tech/go-ethereum/params"
 )
 
@@ -66,6 +67,9 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		allLogs     []*types.Log
 		gp          = new(GasPool).AddGas(block.GasLimit())
 	)
+	if err := failpoint.Inject(failpoint.ProcessBlock); err != nil {
+		return nil, nil, 0, err
+	}
 	// Mutate the block and state according to any hard-fork specs
 	if p.config.DAOForkSupport && p.config.DAOForkBlock != nil && p.config.DAOForkBlock.Cmp(block.Number()) == 0 {
 		misc.ApplyDAOHardFork(statedb)
@@ -93,6 +97,11 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 }
 
 func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainContext, author *common.Address, gp *GasPool, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas *uint64, evm *vm.EVM) (*types.Receipt, error) {
+	// Nonce key transactions are rejected before their fork even when using the
+	// account nonce
+	if tx.Type() == types.NonceKeyTxType && !config.Scroll.IsNonceKey(blockNumber) {
+		return nil, fmt.Errorf("%w: nonce key transaction %v", ErrTxTypeNotSupported, tx.Hash().Hex())
+	}
 	// Create a new context to be used in the EVM environment.
 	txContext := NewEVMTxContext(msg)
 	evm.Reset(txContext, statedb)
@@ -102,6 +111,12 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 	if err != nil {
 		return nil, err
 	}
+	// Without the L1 state it read, the outcome of the transaction is unknown.
+	// Its gas is returned to the pool, block producers skipping it.
+	if err := evm.L1StateErr(); err != nil {
+		gp.AddGas(result.UsedGas)
+		return nil, err
+	}
 
 	// Update the state with pending changes.
 	var root []byte
@@ -140,6 +155,7 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 	receipt.BlockNumber = blockNumber
 	receipt.TransactionIndex = uint(statedb.TxIndex())
 	receipt.L1Fee = result.L1Fee
+	receipt.FeePayer = msg.FeePayer()
 	return receipt, err
 }
 
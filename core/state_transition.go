@@ -28,7 +28,9 @@ import (
 	"github.com/scroll-tech/go-ethereum/crypto/codehash"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/denylist"
 	"github.com/scroll-tech/go-ethereum/rollup/fees"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
 )
 
 var emptyKeccakCodeHash = codehash.EmptyKeccakCodeHash
@@ -82,6 +84,10 @@ type Message interface {
 
 	Nonce() uint64
 	IsFake() bool
+	IsSystem() bool
+	FeePayer() *common.Address
+	NonceKey() uint64
+	AuthList() []types.SetCodeAuthorization
 	Data() []byte
 	AccessList() types.AccessList
 }
@@ -163,11 +169,20 @@ func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation b
 	return gas, nil
 }
 
+// addAuthListGas adds the intrinsic gas of the authorizations of a set-code
+// transaction, charged as if each authority was a new account.
+func addAuthListGas(gas uint64, authList []types.SetCodeAuthorization) (uint64, error) {
+	if (math.MaxUint64-gas)/params.TxAuthEmptyAccountGas < uint64(len(authList)) {
+		return 0, ErrGasUintOverflow
+	}
+	return gas + uint64(len(authList))*params.TxAuthEmptyAccountGas, nil
+}
+
 // NewStateTransition initialises and returns a new state transition object.
 func NewStateTransition(evm *vm.EVM, msg Message, gp *GasPool) *StateTransition {
 	l1Fee := new(big.Int)
-	if evm.ChainConfig().Scroll.FeeVaultEnabled() {
-		l1Fee, _ = fees.CalculateL1MsgFee(msg, evm.StateDB)
+	if evm.ChainConfig().Scroll.FeeVaultEnabled() && !msg.IsSystem() {
+		l1Fee, _ = fees.CalculateL1MsgFee(msg, evm.StateDB, evm.ChainConfig().Scroll.IsCompressedL1Fee(evm.Context.BlockNumber))
 	}
 
 	return &StateTransition{
@@ -203,6 +218,15 @@ func (st *StateTransition) to() common.Address {
 	return *st.msg.To()
 }
 
+// payer returns the account paying the fees of the message, which is the fee
+// payer of sponsored messages and the sender otherwise.
+func (st *StateTransition) payer() common.Address {
+	if payer := st.msg.FeePayer(); payer != nil {
+		return *payer
+	}
+	return st.msg.From()
+}
+
 func (st *StateTransition) buyGas() error {
 	mgval := new(big.Int).SetUint64(st.msg.Gas())
 	mgval = mgval.Mul(mgval, st.gasPrice)
@@ -217,14 +241,17 @@ func (st *StateTransition) buyGas() error {
 	if st.gasFeeCap != nil {
 		balanceCheck = new(big.Int).SetUint64(st.msg.Gas())
 		balanceCheck = balanceCheck.Mul(balanceCheck, st.gasFeeCap)
-		balanceCheck.Add(balanceCheck, st.value)
+		// The value of sponsored messages is checked against the sender later on
+		if st.msg.FeePayer() == nil {
+			balanceCheck.Add(balanceCheck, st.value)
+		}
 		if st.evm.ChainConfig().Scroll.FeeVaultEnabled() {
 			// always add l1fee, because all tx are L2-to-L1 ATM
 			balanceCheck.Add(balanceCheck, st.l1Fee)
 		}
 	}
-	if have, want := st.state.GetBalance(st.msg.From()), balanceCheck; have.Cmp(want) < 0 {
-		return fmt.Errorf("%w: address %v have %v want %v", ErrInsufficientFunds, st.msg.From().Hex(), have, want)
+	if have, want := st.state.GetBalance(st.payer()), balanceCheck; have.Cmp(want) < 0 {
+		return fmt.Errorf("%w: address %v have %v want %v", ErrInsufficientFunds, st.payer().Hex(), have, want)
 	}
 	if err := st.gp.SubGas(st.msg.Gas()); err != nil {
 		return err
@@ -232,15 +259,42 @@ func (st *StateTransition) buyGas() error {
 	st.gas += st.msg.Gas()
 
 	st.initialGas = st.msg.Gas()
-	st.state.SubBalance(st.msg.From(), mgval)
+	st.state.SubBalance(st.payer(), mgval)
 	return nil
 }
 
 func (st *StateTransition) preCheck() error {
+	// Make sure sponsored messages are accepted in this block
+	if st.msg.FeePayer() != nil && !st.evm.ChainConfig().Scroll.IsSponsoredTx(st.evm.Context.BlockNumber) {
+		return fmt.Errorf("%w: sponsored transaction from %v", ErrTxTypeNotSupported, st.msg.From().Hex())
+	}
+	// Make sure messages using nonce keys are accepted in this block
+	if st.msg.NonceKey() != 0 && !st.evm.ChainConfig().Scroll.IsNonceKey(st.evm.Context.BlockNumber) {
+		return fmt.Errorf("%w: nonce key %d of %v", ErrTxTypeNotSupported, st.msg.NonceKey(), st.msg.From().Hex())
+	}
+	// Make sure set-code messages are accepted in this block and well-formed
+	if st.msg.AuthList() != nil {
+		if !st.evm.ChainConfig().Scroll.IsSetCode(st.evm.Context.BlockNumber) {
+			return fmt.Errorf("%w: set-code transaction from %v", ErrTxTypeNotSupported, st.msg.From().Hex())
+		}
+		if st.msg.To() == nil {
+			return fmt.Errorf("%w (sender %v)", ErrSetCodeTxCreate, st.msg.From().Hex())
+		}
+		if len(st.msg.AuthList()) == 0 {
+			return fmt.Errorf("%w (sender %v)", ErrEmptyAuthList, st.msg.From().Hex())
+		}
+	}
+	// Make sure the recipient isn't flagged by the denylist registry, leaving
+	// calls to read denied contracts possible
+	if to := st.msg.To(); to != nil && !st.msg.IsFake() && st.evm.ChainConfig().Scroll.IsDenylist(st.evm.Context.BlockNumber) {
+		if denylist.IsDenied(st.evm.ChainConfig().Scroll.Denylist, st.state, *to) {
+			return fmt.Errorf("%w: address %v", ErrDeniedRecipient, to.Hex())
+		}
+	}
 	// Only check transactions that are not fake
 	if !st.msg.IsFake() {
 		// Make sure this transaction's nonce is correct.
-		stNonce := st.state.GetNonce(st.msg.From())
+		stNonce := st.state.GetNonceKey(st.msg.From(), st.msg.NonceKey())
 		if msgNonce := st.msg.Nonce(); stNonce < msgNonce {
 			return fmt.Errorf("%w: address %v, tx: %d state: %d", ErrNonceTooHigh,
 				st.msg.From().Hex(), msgNonce, stNonce)
@@ -251,10 +305,13 @@ func (st *StateTransition) preCheck() error {
 			return fmt.Errorf("%w: address %v, nonce: %d", ErrNonceMax,
 				st.msg.From().Hex(), stNonce)
 		}
-		// Make sure the sender is an EOA
+		// Make sure the sender is an EOA, which may delegate its code
 		if codeHash := st.state.GetKeccakCodeHash(st.msg.From()); codeHash != emptyKeccakCodeHash && codeHash != (common.Hash{}) {
-			return fmt.Errorf("%w: address %v, codehash: %s", ErrSenderNoEOA,
-				st.msg.From().Hex(), codeHash)
+			_, delegated := types.ParseDelegation(st.state.GetCode(st.msg.From()))
+			if !delegated || !st.evm.ChainConfig().Scroll.IsSetCode(st.evm.Context.BlockNumber) {
+				return fmt.Errorf("%w: address %v, codehash: %s", ErrSenderNoEOA,
+					st.msg.From().Hex(), codeHash)
+			}
 		}
 	}
 	// Make sure that transaction gasFeeCap is greater than the baseFee (post london)
@@ -306,12 +363,16 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	// applying the message. The rules include these clauses
 	//
 	// 1. the nonce of the message caller is correct
-	// 2. caller has enough balance to cover transaction fee(gaslimit * gasprice)
+	// 2. caller, or fee payer if sponsored, has enough balance to cover transaction fee(gaslimit * gasprice)
 	// 3. the amount of gas required is available in the block
 	// 4. the purchased gas is enough to cover intrinsic usage
 	// 5. there is no overflow when calculating intrinsic gas
 	// 6. caller has enough balance to cover asset transfer for **topmost** call
 
+	// System messages are exempt from all of the above
+	if st.msg.IsSystem() {
+		return st.transitionSystem()
+	}
 	// Check clauses 1-3, buy gas if everything is correct
 	if err := st.preCheck(); err != nil {
 		return nil, err
@@ -328,11 +389,19 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	if err != nil {
 		return nil, err
 	}
+	if gas, err = addAuthListGas(gas, st.msg.AuthList()); err != nil {
+		return nil, err
+	}
 	if st.gas < gas {
 		return nil, fmt.Errorf("%w: have %d, want %d", ErrIntrinsicGas, st.gas, gas)
 	}
 	st.gas -= gas
 
+	// Check whether the init code size has been exceeded
+	if limit := st.evm.ChainConfig().Scroll.MaxInitCodeSize(st.evm.Context.BlockNumber); contractCreation && limit != 0 && uint64(len(st.data)) > limit {
+		return nil, fmt.Errorf("%w: code size %v limit %v", ErrMaxInitCodeSizeExceeded, len(st.data), limit)
+	}
+
 	// Check clause 6
 	if msg.Value().Sign() > 0 && !st.evm.Context.CanTransfer(st.state, msg.From(), msg.Value()) {
 		return nil, fmt.Errorf("%w: address %v", ErrInsufficientFundsForTransfer, msg.From().Hex())
@@ -346,11 +415,28 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 		ret   []byte
 		vmerr error // vm errors do not effect consensus and are therefore not assigned to err
 	)
+	if key := msg.NonceKey(); key != 0 {
+		// Increment the nonce of the key, leaving the account nonce to contract
+		// creation which derives the contract address from it
+		st.state.SetNonceKey(msg.From(), key, st.state.GetNonceKey(msg.From(), key)+1)
+	}
 	if contractCreation {
 		ret, _, st.gas, vmerr = st.evm.Create(sender, st.data, st.gas, st.value)
 	} else {
 		// Increment the nonce for the next transaction
-		st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
+		if msg.NonceKey() == 0 {
+			st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
+		}
+		// Apply the authorizations after the sender nonce increment, so that an
+		// authorization by the sender itself must use the incremented nonce.
+		// Invalid authorizations are skipped without failing the transaction.
+		for i := range msg.AuthList() {
+			st.applyAuthorization(&msg.AuthList()[i])
+		}
+		// Calling an account delegating its code also accesses the delegate
+		if target, ok := types.ParseDelegation(st.state.GetCode(st.to())); ok && st.evm.ChainConfig().Scroll.IsSetCode(st.evm.Context.BlockNumber) {
+			st.state.AddAddressToAccessList(target)
+		}
 		ret, st.gas, vmerr = st.evm.Call(sender, st.to(), st.data, st.gas, st.value)
 	}
 
@@ -389,6 +475,92 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	}, nil
 }
 
+// transitionSystem applies a system message. System calls are issued by the
+// protocol itself, so they don't use a nonce, pay no fees and run with a fixed
+// gas allowance which is not taken from the block gas pool. The reported gas
+// usage is zero accordingly.
+func (st *StateTransition) transitionSystem() (*ExecutionResult, error) {
+	msg := st.msg
+	if st.to() == rcfg.L1FeeRefundAddress {
+		return &ExecutionResult{L1Fee: new(big.Int), Err: st.refundL1Fees()}, nil
+	}
+	if rules := st.evm.ChainConfig().Rules(st.evm.Context.BlockNumber); rules.IsBerlin {
+		st.state.PrepareAccessList(msg.From(), msg.To(), vm.ActivePrecompiles(rules), nil)
+	}
+	ret, _, vmerr := st.evm.Call(vm.AccountRef(msg.From()), st.to(), st.data, msg.Gas(), new(big.Int))
+	return &ExecutionResult{
+		L1Fee:      new(big.Int),
+		UsedGas:    0,
+		Err:        vmerr,
+		ReturnData: ret,
+	}, nil
+}
+
+// refundL1Fees pays the L1 fee refunds issued by a system message out of the
+// fee vault. Nothing is paid if the refunds can't be covered altogether.
+func (st *StateTransition) refundL1Fees() error {
+	vault := st.evm.ChainConfig().Scroll.FeeVaultAddress
+	if vault == nil {
+		return errNoFeeVault
+	}
+	refunds, err := types.DecodeL1FeeRefunds(st.data)
+	if err != nil {
+		return err
+	}
+	total := new(big.Int)
+	for _, refund := range refunds {
+		total.Add(total, refund.Amount)
+	}
+	if have := st.state.GetBalance(*vault); have.Cmp(total) < 0 {
+		return fmt.Errorf("%w: fee vault %v have %v want %v", ErrInsufficientFunds, vault.Hex(), have, total)
+	}
+	for _, refund := range refunds {
+		st.state.SubBalance(*vault, refund.Amount)
+		st.state.AddBalance(refund.Recipient, refund.Amount)
+	}
+	return nil
+}
+
+// applyAuthorization sets the code of the authority of a set-code authorization
+// to a delegation to its address, or clears it for the zero address. It returns
+// an error, without touching the state, if the authorization is invalid.
+func (st *StateTransition) applyAuthorization(auth *types.SetCodeAuthorization) error {
+	if auth.ChainID.Sign() != 0 && auth.ChainID.Cmp(st.evm.ChainConfig().ChainID) != 0 {
+		return types.ErrInvalidChainId
+	}
+	if auth.Nonce+1 < auth.Nonce {
+		return ErrNonceMax
+	}
+	authority, err := auth.Authority()
+	if err != nil {
+		return err
+	}
+	// The authority is accessed even if the authorization turns out invalid
+	st.state.AddAddressToAccessList(authority)
+
+	if code := st.state.GetCode(authority); len(code) != 0 {
+		if _, ok := types.ParseDelegation(code); !ok {
+			return ErrSenderNoEOA
+		}
+	}
+	if have := st.state.GetNonce(authority); have < auth.Nonce {
+		return ErrNonceTooHigh
+	} else if have > auth.Nonce {
+		return ErrNonceTooLow
+	}
+	// The new account cost was charged upfront, refund it for existing ones
+	if st.state.Exist(authority) {
+		st.state.AddRefund(params.TxAuthEmptyAccountGas - params.TxAuthTupleGas)
+	}
+	st.state.SetNonce(authority, auth.Nonce+1)
+	if auth.Address == (common.Address{}) {
+		st.state.SetCode(authority, nil)
+		return nil
+	}
+	st.state.SetCode(authority, types.AddressToDelegation(auth.Address))
+	return nil
+}
+
 func (st *StateTransition) refundGas(refundQuotient uint64) {
 	// Apply refund counter, capped to a refund quotient
 	refund := st.gasUsed() / refundQuotient
@@ -399,7 +571,7 @@ func (st *StateTransition) refundGas(refundQuotient uint64) {
 
 	// Return ETH for remaining gas, exchanged at the original rate.
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(st.gas), st.gasPrice)
-	st.state.AddBalance(st.msg.From(), remaining)
+	st.state.AddBalance(st.payer(), remaining)
 
 	// Also return remaining gas to the block gas counter so it is
 	// available for the next transaction.
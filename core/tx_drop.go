@@ -0,0 +1,88 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+// Reasons a transaction left the pool without being included, as reported by
+// DroppedTxsEvent and TxPool.DropReason.
+const (
+	TxDropExpired  = "expired"  // Outlived the transaction lifetime
+	TxDropEvicted  = "evicted"  // Evicted for the pool capacity, its price or the inactivity of its sender
+	TxDropGapped   = "gapped"   // Queued behind a nonce gap for longer than the gap lifetime
+	TxDropReplaced = "replaced" // Replaced by, or lost to, a transaction with the same nonce
+	TxDropInvalid  = "invalid"  // Invalidated by the chain state, like unpayable or to a denied address
+)
+
+// txDropsLimit is the number of recent drops whose reasons are remembered.
+const txDropsLimit = 16384
+
+var expiredTxMeter = metrics.NewRegisteredMeter("txpool/expired", nil)
+
+// txDrops remembers why recent transactions were dropped and gathers the drops
+// to announce, which happen with the pool lock held, until it's released.
+type txDrops struct {
+	reasons *lru.Cache // Reasons of the recent drops by transaction hash
+
+	events []DroppedTxsEvent // Drops not announced yet
+	lock   sync.Mutex
+}
+
+func newTxDrops() *txDrops {
+	reasons, _ := lru.New(txDropsLimit)
+	return &txDrops{reasons: reasons}
+}
+
+// add records the transactions dropped for the given reason.
+func (d *txDrops) add(reason string, txs ...*types.Transaction) {
+	if len(txs) == 0 {
+		return
+	}
+	for _, tx := range txs {
+		d.reasons.Add(tx.Hash(), reason)
+	}
+	d.lock.Lock()
+	d.events = append(d.events, DroppedTxsEvent{Txs: txs, Reason: reason})
+	d.lock.Unlock()
+}
+
+// reason returns why the transaction with the given hash was dropped, or the
+// empty string if it wasn't recently.
+func (d *txDrops) reason(hash common.Hash) string {
+	if reason, ok := d.reasons.Get(hash); ok {
+		return reason.(string)
+	}
+	return ""
+}
+
+// flush returns the drops not announced yet.
+func (d *txDrops) flush() []DroppedTxsEvent {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	events := d.events
+	d.events = nil
+	return events
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+var queuedGappedMeter = metrics.NewRegisteredMeter("txpool/queued/gapped", nil) // Dropped due to the gap lifetime
+
+// NonceGap is a run of nonces missing in front of queued transactions, from
+// From to To inclusive.
+type NonceGap struct {
+	From uint64
+	To   uint64
+}
+
+// NonceGaps returns the next nonce of an account after its executable
+// transactions, the runs of nonces missing in front of its queued transactions,
+// and the queued transactions blocked by them. Only the account nonce is
+// considered, not the other nonce keys.
+func (pool *TxPool) NonceGaps(addr common.Address) (uint64, []NonceGap, types.Transactions) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	next := pool.pendingNonces.get(addr, 0)
+	list := pool.queue[addr]
+	if list == nil {
+		return next, nil, nil
+	}
+	var (
+		queued = list.txs.Flatten()
+		gaps   []NonceGap
+	)
+	for _, tx := range queued {
+		if tx.Nonce() > next {
+			gaps = append(gaps, NonceGap{From: next, To: tx.Nonce() - 1})
+		}
+		next = tx.Nonce() + 1
+	}
+	return pool.pendingNonces.get(addr, 0), gaps, queued
+}
+
+// StuckTxs returns the executable transactions of an account paying less than
+// the pool requires for inclusion, the pending base fee and the minimum tip.
+func (pool *TxPool) StuckTxs(addr common.Address) types.Transactions {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	list := pool.pending[addr]
+	if list == nil {
+		return nil
+	}
+	var stuck types.Transactions
+	for _, tx := range list.Flatten() {
+		if tx.EffectiveGasTipIntCmp(pool.gasPrice, pool.priced.urgent.baseFee) < 0 {
+			stuck = append(stuck, tx)
+		}
+	}
+	return stuck
+}
+
+// ReplacementFees returns the minimum fee cap and tip of a transaction both
+// replacing the given one in the pool and paying enough for inclusion.
+func (pool *TxPool) ReplacementFees(tx *types.Transaction) (*big.Int, *big.Int) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	feeCap, tip := replacementThreshold(tx, pool.config.PriceBump)
+	if tip.Cmp(pool.gasPrice) < 0 {
+		tip.Set(pool.gasPrice)
+	}
+	if baseFee := pool.priced.urgent.baseFee; baseFee != nil {
+		if min := new(big.Int).Add(baseFee, tip); feeCap.Cmp(min) < 0 {
+			feeCap = min
+		}
+	}
+	if feeCap.Cmp(tip) < 0 {
+		feeCap.Set(tip)
+	}
+	return feeCap, tip
+}
+
+// replacementThreshold returns the minimum fee cap and tip a transaction must
+// pay to replace the given one, each bumped by priceBump percent.
+func replacementThreshold(old *types.Transaction, priceBump uint64) (*big.Int, *big.Int) {
+	// thresholdFeeCap = oldFC  * (100 + priceBump) / 100
+	a := big.NewInt(100 + int64(priceBump))
+	aFeeCap := new(big.Int).Mul(a, old.GasFeeCap())
+	aTip := a.Mul(a, old.GasTipCap())
+
+	// thresholdTip    = oldTip * (100 + priceBump) / 100
+	b := big.NewInt(100)
+	return aFeeCap.Div(aFeeCap, b), aTip.Div(aTip, b)
+}
+
+// expireGapped removes the queues which waited behind a nonce gap without any
+// activity for longer than the gap lifetime, local ones included. The queued
+// transactions of each nonce key are checked for a gap separately.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) expireGapped() {
+	for addr, list := range pool.queue {
+		if time.Since(pool.beats[addr]) <= pool.config.GapLifetime {
+			continue
+		}
+		var txs types.Transactions
+		for _, key := range list.laneKeys() {
+			if queued := list.lane(key).Flatten(); queued[0].Nonce() > pool.pendingNonces.get(addr, key) {
+				txs = append(txs, queued...)
+			}
+		}
+		if len(txs) == 0 {
+			continue
+		}
+		for _, tx := range txs {
+			pool.removeTx(tx.Hash(), true)
+		}
+		queuedGappedMeter.Mark(int64(len(txs)))
+		pool.drops.add(TxDropGapped, txs...)
+	}
+}
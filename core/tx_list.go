@@ -249,9 +249,14 @@ func (m *txSortedMap) LastElement() *types.Transaction {
 // nonce. The same type can be used both for storing contiguous transactions for
 // the executable/pending queue; and for storing gapped transactions for the non-
 // executable/future queue, with minor behavioral changes.
+//
+// The transactions using a nonce key other than the account nonce are kept in a
+// separate lane per key, each sorted by the nonce of its key. The balance of the
+// account funds all the lanes at once.
 type txList struct {
-	strict bool         // Whether nonces are strictly continuous or not
-	txs    *txSortedMap // Heap indexed sorted hash map of the transactions
+	strict bool                    // Whether nonces are strictly continuous or not
+	txs    *txSortedMap            // Heap indexed sorted hash map of the transactions of the account nonce
+	lanes  map[uint64]*txSortedMap // Heap indexed sorted hash maps of the transactions of the other nonce keys
 
 	costcap *big.Int // Price of the highest costing transaction (reset only if exceeds balance)
 	gascap  uint64   // Gas limit of the highest spending transaction (reset only if exceeds block limit)
@@ -263,14 +268,47 @@ func newTxList(strict bool) *txList {
 	return &txList{
 		strict:  strict,
 		txs:     newTxSortedMap(),
+		lanes:   make(map[uint64]*txSortedMap),
 		costcap: new(big.Int),
 	}
 }
 
+// lane returns the transactions of the given nonce key, or nil if there are none.
+func (l *txList) lane(key uint64) *txSortedMap {
+	if key == 0 {
+		return l.txs
+	}
+	return l.lanes[key]
+}
+
+// laneKeys returns the nonce keys of the lanes holding transactions, the account
+// nonce first and the other keys in ascending order.
+func (l *txList) laneKeys() []uint64 {
+	keys := make([]uint64, 0, len(l.lanes)+1)
+	if l.txs.Len() > 0 {
+		keys = append(keys, 0)
+	}
+	for key := range l.lanes {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// prune drops the lanes of the nonce keys left without transactions.
+func (l *txList) prune() {
+	for key, lane := range l.lanes {
+		if lane.Len() == 0 {
+			delete(l.lanes, key)
+		}
+	}
+}
+
 // Overlaps returns whether the transaction specified has the same nonce as one
 // already contained within the list.
 func (l *txList) Overlaps(tx *types.Transaction) bool {
-	return l.txs.Get(tx.Nonce()) != nil
+	lane := l.lane(tx.NonceKey())
+	return lane != nil && lane.Get(tx.Nonce()) != nil
 }
 
 // Add tries to insert a new transaction into the list, returning whether the
@@ -279,21 +317,18 @@ func (l *txList) Overlaps(tx *types.Transaction) bool {
 // If the new transaction is accepted into the list, the lists' cost and gas
 // thresholds are also potentially updated.
 func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Transaction) {
+	lane := l.lane(tx.NonceKey())
+	if lane == nil {
+		lane = newTxSortedMap()
+		l.lanes[tx.NonceKey()] = lane
+	}
 	// If there's an older better transaction, abort
-	old := l.txs.Get(tx.Nonce())
+	old := lane.Get(tx.Nonce())
 	if old != nil {
 		if old.GasFeeCapCmp(tx) >= 0 || old.GasTipCapCmp(tx) >= 0 {
 			return false, nil
 		}
-		// thresholdFeeCap = oldFC  * (100 + priceBump) / 100
-		a := big.NewInt(100 + int64(priceBump))
-		aFeeCap := new(big.Int).Mul(a, old.GasFeeCap())
-		aTip := a.Mul(a, old.GasTipCap())
-
-		// thresholdTip    = oldTip * (100 + priceBump) / 100
-		b := big.NewInt(100)
-		thresholdFeeCap := aFeeCap.Div(aFeeCap, b)
-		thresholdTip := aTip.Div(aTip, b)
+		thresholdFeeCap, thresholdTip := replacementThreshold(old, priceBump)
 
 		// We have to ensure that both the new fee cap and tip are higher than the
 		// old ones as well as checking the percentage threshold to ensure that
@@ -303,8 +338,8 @@ func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Tran
 		}
 	}
 	// Otherwise overwrite the old transaction with the current one
-	l.txs.Put(tx)
-	if cost := tx.Cost(); l.costcap.Cmp(cost) < 0 {
+	lane.Put(tx)
+	if cost := tx.SenderCost(); l.costcap.Cmp(cost) < 0 {
 		l.costcap = cost
 	}
 	if gas := tx.Gas(); l.gascap < gas {
@@ -314,10 +349,15 @@ func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Tran
 }
 
 // Forward removes all transactions from the list with a nonce lower than the
-// provided threshold. Every removed transaction is returned for any post-removal
-// maintenance.
-func (l *txList) Forward(threshold uint64) types.Transactions {
-	return l.txs.Forward(threshold)
+// threshold of their nonce key. Every removed transaction is returned for any
+// post-removal maintenance.
+func (l *txList) Forward(thresholds func(key uint64) uint64) types.Transactions {
+	var removed types.Transactions
+	for _, key := range l.laneKeys() {
+		removed = append(removed, l.lane(key).Forward(thresholds(key))...)
+	}
+	l.prune()
+	return removed
 }
 
 // Filter removes all transactions from the list with a cost or gas limit higher
@@ -325,77 +365,141 @@ func (l *txList) Forward(threshold uint64) types.Transactions {
 // post-removal maintenance. Strict-mode invalidated transactions are also
 // returned.
 //
+// The lanes of the nonce keys may be executed in any order, so each of them is
+// only left with the balance the costliest transactions of the lanes before it
+// don't spend, the account nonce first.
+//
 // This method uses the cached costcap and gascap to quickly decide if there's even
 // a point in calculating all the costs or if the balance covers all. If the threshold
 // is lower than the costgas cap, the caps will be reset to a new high after removing
 // the newly invalidated transactions.
 func (l *txList) Filter(costLimit *big.Int, gasLimit uint64) (types.Transactions, types.Transactions) {
 	// If all transactions are below the threshold, short circuit
-	if l.costcap.Cmp(costLimit) <= 0 && l.gascap <= gasLimit {
+	if len(l.lanes) == 0 && l.costcap.Cmp(costLimit) <= 0 && l.gascap <= gasLimit {
 		return nil, nil
 	}
 	l.costcap = new(big.Int).Set(costLimit) // Lower the caps to the thresholds
 	l.gascap = gasLimit
 
-	// Filter out all the transactions above the account's funds
-	removed := l.txs.Filter(func(tx *types.Transaction) bool {
-		return tx.Gas() > gasLimit || tx.Cost().Cmp(costLimit) > 0
-	})
+	var (
+		budget   = new(big.Int).Set(costLimit)
+		removed  types.Transactions
+		invalids types.Transactions
+	)
+	for _, key := range l.laneKeys() {
+		lane := l.lane(key)
+
+		// Filter out all the transactions above the account's funds
+		drops := lane.Filter(func(tx *types.Transaction) bool {
+			return tx.Gas() > gasLimit || tx.SenderCost().Cmp(budget) > 0
+		})
+		// If the list was strict, filter anything above the lowest nonce
+		if len(drops) > 0 && l.strict {
+			lowest := uint64(math.MaxUint64)
+			for _, tx := range drops {
+				if nonce := tx.Nonce(); lowest > nonce {
+					lowest = nonce
+				}
+			}
+			invalids = append(invalids, lane.filter(func(tx *types.Transaction) bool { return tx.Nonce() > lowest })...)
+			lane.reheap()
+		}
+		removed = append(removed, drops...)
+		budget.Sub(budget, laneCost(lane))
+	}
+	l.prune()
 
 	if len(removed) == 0 {
 		return nil, nil
 	}
-	var invalids types.Transactions
-	// If the list was strict, filter anything above the lowest nonce
-	if l.strict {
-		lowest := uint64(math.MaxUint64)
-		for _, tx := range removed {
-			if nonce := tx.Nonce(); lowest > nonce {
-				lowest = nonce
-			}
+	return removed, invalids
+}
+
+// Costs returns the cost of the costliest transaction of each nonce key.
+func (l *txList) Costs() map[uint64]*big.Int {
+	costs := make(map[uint64]*big.Int, len(l.lanes)+1)
+	for _, key := range l.laneKeys() {
+		costs[key] = laneCost(l.lane(key))
+	}
+	return costs
+}
+
+// laneCost returns the cost of the costliest transaction of a lane.
+func laneCost(lane *txSortedMap) *big.Int {
+	cost := new(big.Int)
+	for _, tx := range lane.items {
+		if c := tx.SenderCost(); cost.Cmp(c) < 0 {
+			cost = c
 		}
-		invalids = l.txs.filter(func(tx *types.Transaction) bool { return tx.Nonce() > lowest })
 	}
-	l.txs.reheap()
-	return removed, invalids
+	return cost
 }
 
 // Cap places a hard limit on the number of items, returning all transactions
-// exceeding that limit.
+// exceeding that limit. The transactions of the highest nonce keys are dropped
+// first.
 func (l *txList) Cap(threshold int) types.Transactions {
-	return l.txs.Cap(threshold)
+	var drops types.Transactions
+
+	keys := l.laneKeys()
+	for i := len(keys) - 1; i >= 0 && l.Len() > threshold; i-- {
+		lane := l.lane(keys[i])
+
+		keep := lane.Len() - (l.Len() - threshold)
+		if keep < 0 {
+			keep = 0
+		}
+		drops = append(drops, lane.Cap(keep)...)
+	}
+	l.prune()
+	return drops
 }
 
 // Remove deletes a transaction from the maintained list, returning whether the
 // transaction was found, and also returning any transaction invalidated due to
 // the deletion (strict mode only).
 func (l *txList) Remove(tx *types.Transaction) (bool, types.Transactions) {
+	lane := l.lane(tx.NonceKey())
+	if lane == nil {
+		return false, nil
+	}
+	defer l.prune()
+
 	// Remove the transaction from the set
 	nonce := tx.Nonce()
-	if removed := l.txs.Remove(nonce); !removed {
+	if removed := lane.Remove(nonce); !removed {
 		return false, nil
 	}
 	// In strict mode, filter out non-executable transactions
 	if l.strict {
-		return true, l.txs.Filter(func(tx *types.Transaction) bool { return tx.Nonce() > nonce })
+		return true, lane.Filter(func(tx *types.Transaction) bool { return tx.Nonce() > nonce })
 	}
 	return true, nil
 }
 
-// Ready retrieves a sequentially increasing list of transactions starting at the
-// provided nonce that is ready for processing. The returned transactions will be
-// removed from the list.
+// Ready retrieves a sequentially increasing list of transactions of each nonce
+// key starting at the provided nonce of the key that is ready for processing.
+// The returned transactions will be removed from the list.
 //
 // Note, all transactions with nonces lower than start will also be returned to
 // prevent getting into and invalid state. This is not something that should ever
 // happen but better to be self correcting than failing!
-func (l *txList) Ready(start uint64) types.Transactions {
-	return l.txs.Ready(start)
+func (l *txList) Ready(starts func(key uint64) uint64) types.Transactions {
+	var ready types.Transactions
+	for _, key := range l.laneKeys() {
+		ready = append(ready, l.lane(key).Ready(starts(key))...)
+	}
+	l.prune()
+	return ready
 }
 
 // Len returns the length of the transaction list.
 func (l *txList) Len() int {
-	return l.txs.Len()
+	length := l.txs.Len()
+	for _, lane := range l.lanes {
+		length += lane.Len()
+	}
+	return length
 }
 
 // Empty returns whether the list of transactions is empty or not.
@@ -404,16 +508,27 @@ func (l *txList) Empty() bool {
 }
 
 // Flatten creates a nonce-sorted slice of transactions based on the loosely
-// sorted internal representation. The result of the sorting is cached in case
-// it's requested again before any modifications are made to the contents.
+// sorted internal representation, the account nonce first and the other nonce
+// keys in ascending order. The result of the sorting is cached in case it's
+// requested again before any modifications are made to the contents.
 func (l *txList) Flatten() types.Transactions {
-	return l.txs.Flatten()
+	if len(l.lanes) == 0 {
+		return l.txs.Flatten()
+	}
+	txs := make(types.Transactions, 0, l.Len())
+	for _, key := range l.laneKeys() {
+		txs = append(txs, l.lane(key).flatten()...)
+	}
+	return txs
 }
 
-// LastElement returns the last element of a flattened list, thus, the
-// transaction with the highest nonce
-func (l *txList) LastElement() *types.Transaction {
-	return l.txs.LastElement()
+// Nonces returns the nonce following the last transaction of each nonce key.
+func (l *txList) Nonces() map[uint64]uint64 {
+	nonces := make(map[uint64]uint64, len(l.lanes)+1)
+	for _, key := range l.laneKeys() {
+		nonces[key] = l.lane(key).LastElement().Nonce() + 1
+	}
+	return nonces
 }
 
 // priceHeap is a heap.Interface implementation over transactions for retrieving
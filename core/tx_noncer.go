@@ -23,12 +23,19 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/state"
 )
 
+// txLane identifies the transactions of an account sharing a nonce key, the key
+// 0 being the account nonce.
+type txLane struct {
+	addr common.Address
+	key  uint64
+}
+
 // txNoncer is a tiny virtual state database to manage the executable nonces of
 // accounts in the pool, falling back to reading from a real state database if
-// an account is unknown.
+// an account is unknown. The nonces are tracked per nonce key of an account.
 type txNoncer struct {
 	fallback *state.StateDB
-	nonces   map[common.Address]uint64
+	nonces   map[txLane]uint64
 	lock     sync.Mutex
 }
 
@@ -36,50 +43,52 @@ type txNoncer struct {
 func newTxNoncer(statedb *state.StateDB) *txNoncer {
 	return &txNoncer{
 		fallback: statedb.Copy(),
-		nonces:   make(map[common.Address]uint64),
+		nonces:   make(map[txLane]uint64),
 	}
 }
 
-// get returns the current nonce of an account, falling back to a real state
-// database if the account is unknown.
-func (txn *txNoncer) get(addr common.Address) uint64 {
+// get returns the current nonce of the given nonce key of an account, falling
+// back to a real state database if the account is unknown.
+func (txn *txNoncer) get(addr common.Address, key uint64) uint64 {
 	// We use mutex for get operation is the underlying
 	// state will mutate db even for read access.
 	txn.lock.Lock()
 	defer txn.lock.Unlock()
 
-	if _, ok := txn.nonces[addr]; !ok {
-		txn.nonces[addr] = txn.fallback.GetNonce(addr)
+	lane := txLane{addr, key}
+	if _, ok := txn.nonces[lane]; !ok {
+		txn.nonces[lane] = txn.fallback.GetNonceKey(addr, key)
 	}
-	return txn.nonces[addr]
+	return txn.nonces[lane]
 }
 
 // set inserts a new virtual nonce into the virtual state database to be returned
 // whenever the pool requests it instead of reaching into the real state database.
-func (txn *txNoncer) set(addr common.Address, nonce uint64) {
+func (txn *txNoncer) set(addr common.Address, key uint64, nonce uint64) {
 	txn.lock.Lock()
 	defer txn.lock.Unlock()
 
-	txn.nonces[addr] = nonce
+	txn.nonces[txLane{addr, key}] = nonce
 }
 
 // setIfLower updates a new virtual nonce into the virtual state database if the
 // the new one is lower.
-func (txn *txNoncer) setIfLower(addr common.Address, nonce uint64) {
+func (txn *txNoncer) setIfLower(addr common.Address, key uint64, nonce uint64) {
 	txn.lock.Lock()
 	defer txn.lock.Unlock()
 
-	if _, ok := txn.nonces[addr]; !ok {
-		txn.nonces[addr] = txn.fallback.GetNonce(addr)
+	lane := txLane{addr, key}
+	if _, ok := txn.nonces[lane]; !ok {
+		txn.nonces[lane] = txn.fallback.GetNonceKey(addr, key)
 	}
-	if txn.nonces[addr] <= nonce {
+	if txn.nonces[lane] <= nonce {
 		return
 	}
-	txn.nonces[addr] = nonce
+	txn.nonces[lane] = nonce
 }
 
 // setAll sets the nonces for all accounts to the given map.
-func (txn *txNoncer) setAll(all map[common.Address]uint64) {
+func (txn *txNoncer) setAll(all map[txLane]uint64) {
 	txn.lock.Lock()
 	defer txn.lock.Unlock()
 
@@ -0,0 +1,196 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+var (
+	// ErrTxPaused is returned if a transaction belongs to a class of transactions
+	// temporarily refused by the node operator.
+	ErrTxPaused = errors.New("transaction class paused")
+
+	errPauseRuleEmpty   = errors.New("pause rule matches no transaction")
+	errPauseRuleCreate  = errors.New("pause rule of contract creations with a recipient")
+	errPauseRuleExpired = errors.New("pause rule expired")
+	errPauseSelector    = errors.New("pause rule selector not 4 bytes")
+
+	pausedTxMeter = metrics.NewRegisteredMeter("txpool/paused", nil)
+)
+
+// TxPauseRule refuses a class of transactions until it expires, to contain an
+// exploit. A rule matches contract creations if Create is set, else the calls
+// to To if set, restricted to the calls of the 4-byte Selector if set.
+type TxPauseRule struct {
+	ID       uint64          `json:"id"`
+	Create   bool            `json:"create,omitempty"`
+	To       *common.Address `json:"to,omitempty"`
+	Selector hexutil.Bytes   `json:"selector,omitempty"`
+	Expiry   uint64          `json:"expiry"` // Unix timestamp the rule lifts at
+	Reason   string          `json:"reason,omitempty"`
+}
+
+// matches returns whether the rule refuses the given transaction.
+func (r *TxPauseRule) matches(tx *types.Transaction) bool {
+	if r.Create {
+		return tx.To() == nil
+	}
+	if tx.To() == nil {
+		return false
+	}
+	if r.To != nil && *r.To != *tx.To() {
+		return false
+	}
+	return r.Selector == nil || bytes.HasPrefix(tx.Data(), r.Selector)
+}
+
+// txPauses is the set of active pause rules, shared by the transaction pool and
+// the block producer. Expired rules are dropped as they're looked up.
+type txPauses struct {
+	rules  map[uint64]*TxPauseRule
+	nextID uint64
+	now    func() time.Time
+	lock   sync.RWMutex
+}
+
+func newTxPauses() *txPauses {
+	return &txPauses{
+		rules:  make(map[uint64]*TxPauseRule),
+		nextID: 1,
+		now:    time.Now,
+	}
+}
+
+// add validates and activates a rule, returning its assigned ID.
+func (p *txPauses) add(rule TxPauseRule) (uint64, error) {
+	switch {
+	case !rule.Create && rule.To == nil && rule.Selector == nil:
+		return 0, errPauseRuleEmpty
+	case rule.Create && (rule.To != nil || rule.Selector != nil):
+		return 0, errPauseRuleCreate
+	case rule.Selector != nil && len(rule.Selector) != 4:
+		return 0, errPauseSelector
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if rule.Expiry <= uint64(p.now().Unix()) {
+		return 0, errPauseRuleExpired
+	}
+	rule.ID = p.nextID
+	p.nextID++
+	p.rules[rule.ID] = &rule
+
+	log.Warn("Paused transaction class", "id", rule.ID, "create", rule.Create, "to", rule.To, "selector", rule.Selector,
+		"expiry", time.Unix(int64(rule.Expiry), 0), "reason", rule.Reason)
+	return rule.ID, nil
+}
+
+// remove lifts the rule with the given ID, returning whether it was active.
+func (p *txPauses) remove(id uint64) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.expire()
+	if _, ok := p.rules[id]; !ok {
+		return false
+	}
+	delete(p.rules, id)
+	log.Warn("Unpaused transaction class", "id", id)
+	return true
+}
+
+// list returns the active rules, ordered by ID.
+func (p *txPauses) list() []TxPauseRule {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.expire()
+	rules := make([]TxPauseRule, 0, len(p.rules))
+	for _, rule := range p.rules {
+		rules = append(rules, *rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// match returns the active rule refusing the given transaction, if any.
+func (p *txPauses) match(tx *types.Transaction) *TxPauseRule {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if len(p.rules) == 0 {
+		return nil
+	}
+	now := uint64(p.now().Unix())
+	for _, rule := range p.rules {
+		if rule.Expiry > now && rule.matches(tx) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// expire drops the expired rules. The lock must be held.
+func (p *txPauses) expire() {
+	now := uint64(p.now().Unix())
+	for id, rule := range p.rules {
+		if rule.Expiry <= now {
+			delete(p.rules, id)
+			log.Warn("Transaction class pause expired", "id", id, "reason", rule.Reason)
+		}
+	}
+}
+
+// PauseTxs refuses the transactions matching the given rule, both when added to
+// the pool and when producing blocks, until the rule expires or is removed. The
+// transactions already pooled are kept, to be included once the rule lifts.
+func (pool *TxPool) PauseTxs(rule TxPauseRule) (uint64, error) {
+	return pool.pauses.add(rule)
+}
+
+// UnpauseTxs lifts the pause rule with the given ID, returning whether it was
+// active.
+func (pool *TxPool) UnpauseTxs(id uint64) bool {
+	return pool.pauses.remove(id)
+}
+
+// PausedTxs returns the active pause rules.
+func (pool *TxPool) PausedTxs() []TxPauseRule {
+	return pool.pauses.list()
+}
+
+// Paused returns whether the given transaction is refused by a pause rule.
+func (pool *TxPool) Paused(tx *types.Transaction) bool {
+	rule := pool.pauses.match(tx)
+	if rule != nil {
+		pausedTxMeter.Mark(1)
+		log.Debug("Refused paused transaction", "hash", tx.Hash(), "rule", rule.ID)
+	}
+	return rule != nil
+}
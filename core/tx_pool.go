@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"math"
 	"math/big"
@@ -30,10 +31,12 @@ import (
 	"github.com/scroll-tech/go-ethereum/consensus/misc"
 	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/event"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/metrics"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/denylist"
 	"github.com/scroll-tech/go-ethereum/rollup/fees"
 )
 
@@ -86,6 +89,10 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrInflightTxLimitReached is returned if an account delegating its code
+	// already has a transaction in the pool, other than the one being replaced.
+	ErrInflightTxLimitReached = errors.New("in-flight transaction limit reached for delegated accounts")
 )
 
 var (
@@ -170,6 +177,9 @@ type TxPoolConfig struct {
 	Journal   string           // Journal of local transactions to survive node restarts
 	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
 
+	Persist  bool                // Whether to persist all transactions in the database instead of journaling locals
+	Database ethdb.KeyValueStore `toml:"-"` // Database to persist the transactions in, set by the node
+
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
@@ -178,7 +188,9 @@ type TxPoolConfig struct {
 	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
-	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+	Lifetime    time.Duration // Maximum amount of time non-executable transaction are queued
+	TxLifetime  time.Duration // Maximum amount of time any transaction stays in the pool, locals included (0 = unlimited)
+	GapLifetime time.Duration // Maximum amount of time transactions are queued behind a nonce gap, locals included (0 = Lifetime only)
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -206,6 +218,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool journal time", "provided", conf.Rejournal, "updated", time.Second)
 		conf.Rejournal = time.Second
 	}
+	if conf.Persist && conf.Database == nil {
+		log.Warn("Sanitizing txpool persistence without database", "provided", conf.Persist, "updated", false)
+		conf.Persist = false
+	}
 	if conf.PriceLimit < 1 {
 		log.Warn("Sanitizing invalid txpool price limit", "provided", conf.PriceLimit, "updated", DefaultTxPoolConfig.PriceLimit)
 		conf.PriceLimit = DefaultTxPoolConfig.PriceLimit
@@ -250,6 +266,7 @@ type TxPool struct {
 	chain       blockChain
 	gasPrice    *big.Int
 	txFeed      event.Feed
+	dropFeed    event.Feed
 	scope       event.SubscriptionScope
 	signer      types.Signer
 	mu          sync.RWMutex
@@ -258,12 +275,25 @@ type TxPool struct {
 	eip2718  bool // Fork indicator whether we are using EIP-2718 type transactions.
 	eip1559  bool // Fork indicator whether we are using EIP-1559 type transactions.
 
+	sponsoredTx bool // Fork indicator whether sponsored transactions are accepted.
+	nonceKey    bool // Fork indicator whether nonce key transactions are accepted.
+	setCode     bool // Fork indicator whether set-code transactions are accepted.
+	denylist    bool // Fork indicator whether transactions to denied addresses are invalid.
+
+	compressedL1Fee bool // Fork indicator whether the L1 fee is charged by the compressed size.
+
+	maxInitCodeSize uint64 // Init code size limit of contract creations (0 = unlimited)
+
+	pauses *txPauses // Transaction classes refused during incidents
+	drops  *txDrops  // Reasons of the recent drops, and the drops to announce
+
 	currentState  *state.StateDB // Current state in the blockchain head
 	pendingNonces *txNoncer      // Pending state tracking virtual nonces
 	currentMaxGas uint64         // Current gas limit for transaction caps
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
+	store   *txStore    // Database store of all transactions, replacing the journal if enabled
 
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
@@ -315,12 +345,18 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		initDoneCh:      make(chan struct{}),
 		gasPrice:        new(big.Int).SetUint64(config.PriceLimit),
 		spammers:        prque.New(nil),
+		pauses:          newTxPauses(),
+		drops:           newTxDrops(),
 	}
 	pool.locals = newAccountSet(pool.signer)
 	for _, addr := range config.Locals {
 		log.Info("Setting new local account", "address", addr)
 		pool.locals.add(addr)
 	}
+	if config.Persist {
+		pool.store = newTxStore(config.Database)
+		pool.all.store = pool.store
+	}
 	pool.priced = newTxPricedList(pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
@@ -328,8 +364,16 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	pool.wg.Add(1)
 	go pool.scheduleReorgLoop()
 
-	// If local transactions and journaling is enabled, load from disk
-	if !config.NoLocals && config.Journal != "" {
+	// If the transactions are persisted, restore them from the database, else if
+	// local transactions and journaling is enabled, load from disk
+	if pool.store != nil {
+		pool.store.load(func(txs []*types.Transaction, local bool) []error {
+			if local {
+				return pool.AddLocals(txs)
+			}
+			return pool.addTxs(txs, false, true)
+		}, pool.Has)
+	} else if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
 
 		if err := pool.journal.load(pool.AddLocals); err != nil {
@@ -410,9 +454,17 @@ func (pool *TxPool) loop() {
 						pool.removeTx(tx.Hash(), true)
 					}
 					queuedEvictionMeter.Mark(int64(len(list)))
+					pool.drops.add(TxDropEvicted, list...)
 				}
 			}
+			if pool.config.GapLifetime > 0 {
+				pool.expireGapped()
+			}
+			if pool.config.TxLifetime > 0 {
+				pool.expireTxs()
+			}
 			pool.mu.Unlock()
+			pool.announceDrops()
 
 		// Handle local transaction journal rotation
 		case <-journal.C:
@@ -437,6 +489,13 @@ func (pool *TxPool) Stop() {
 	pool.wg.Wait()
 
 	if pool.journal != nil {
+		// Flush the current set of local transactions so that nothing that was
+		// dropped or included since the last rotation is resurrected on restart
+		pool.mu.Lock()
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to rotate local tx journal", "err", err)
+		}
+		pool.mu.Unlock()
 		pool.journal.close()
 	}
 	log.Info("Transaction pool stopped")
@@ -448,6 +507,47 @@ func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- NewTxsEvent) event.Subscripti
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeDroppedTxsEvent registers a subscription of DroppedTxsEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeDroppedTxsEvent(ch chan<- DroppedTxsEvent) event.Subscription {
+	return pool.scope.Track(pool.dropFeed.Subscribe(ch))
+}
+
+// DropReason returns why the transaction with the given hash was dropped from
+// the pool without being included, or the empty string if it wasn't recently.
+func (pool *TxPool) DropReason(hash common.Hash) string {
+	return pool.drops.reason(hash)
+}
+
+// announceDrops sends the drops not announced yet to the subscribers. It must be
+// called without the pool lock held, as subscribers may block.
+func (pool *TxPool) announceDrops() {
+	for _, ev := range pool.drops.flush() {
+		pool.dropFeed.Send(ev)
+	}
+}
+
+// expireTxs removes the transactions which outlived the transaction lifetime,
+// local ones included.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) expireTxs() {
+	var expired []*types.Transaction
+	pool.all.Range(func(hash common.Hash, tx *types.Transaction, local bool) bool {
+		if time.Since(tx.Time()) > pool.config.TxLifetime {
+			expired = append(expired, tx)
+		}
+		return true
+	}, true, true)
+
+	for _, tx := range expired {
+		log.Trace("Removed expired transaction", "hash", tx.Hash())
+		pool.removeTx(tx.Hash(), true)
+	}
+	expiredTxMeter.Mark(int64(len(expired)))
+	pool.drops.add(TxDropExpired, expired...)
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -459,6 +559,8 @@ func (pool *TxPool) GasPrice() *big.Int {
 // SetGasPrice updates the minimum price required by the transaction pool for a
 // new transaction, and drops all transactions below this threshold.
 func (pool *TxPool) SetGasPrice(price *big.Int) {
+	defer pool.announceDrops()
+
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
@@ -472,6 +574,7 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 			pool.removeTx(tx.Hash(), false)
 		}
 		pool.priced.Removed(len(drop))
+		pool.drops.add(TxDropEvicted, drop...)
 	}
 
 	log.Info("Transaction pool price threshold updated", "price", price)
@@ -483,7 +586,16 @@ func (pool *TxPool) Nonce(addr common.Address) uint64 {
 	pool.mu.RLock()
 	defer pool.mu.RUnlock()
 
-	return pool.pendingNonces.get(addr)
+	return pool.pendingNonces.get(addr, 0)
+}
+
+// NonceKey returns the next nonce of the given nonce key of an account, with all
+// transactions executable by the pool already applied on top of it.
+func (pool *TxPool) NonceKey(addr common.Address, key uint64) uint64 {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.pendingNonces.get(addr, key)
 }
 
 // Stats retrieves the current pool stats, namely the number of pending and the
@@ -609,10 +721,44 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if !pool.eip1559 && tx.Type() == types.DynamicFeeTxType {
 		return ErrTxTypeNotSupported
 	}
+	// Reject sponsored transactions until they're accepted in blocks.
+	if !pool.sponsoredTx && tx.Type() == types.SponsoredTxType {
+		return ErrTxTypeNotSupported
+	}
+	// Reject nonce key transactions until they're accepted in blocks.
+	if !pool.nonceKey && tx.Type() == types.NonceKeyTxType {
+		return ErrTxTypeNotSupported
+	}
+	// Reject set-code transactions until they're accepted in blocks, and
+	// those without authorization.
+	if tx.Type() == types.SetCodeTxType {
+		if !pool.setCode {
+			return ErrTxTypeNotSupported
+		}
+		if len(tx.SetCodeAuthorizations()) == 0 {
+			return ErrEmptyAuthList
+		}
+	}
+	// System transactions are inserted by the sequencer, never submitted.
+	if tx.IsSystemTx() {
+		return ErrTxTypeNotSupported
+	}
 	// Reject transactions over defined size to prevent DOS attacks
 	if uint64(tx.Size()) > txMaxSize {
 		return ErrOversizedData
 	}
+	// Refuse the transaction classes paused by the operator
+	if pool.Paused(tx) {
+		return ErrTxPaused
+	}
+	// Reject transactions to the addresses flagged by the denylist registry
+	if to := tx.To(); to != nil && pool.denylist && denylist.IsDenied(pool.chainconfig.Scroll.Denylist, pool.currentState, *to) {
+		return ErrDeniedRecipient
+	}
+	// Reject contract creations the next block can't include
+	if tx.To() == nil && pool.maxInitCodeSize != 0 && uint64(len(tx.Data())) > pool.maxInitCodeSize {
+		return ErrMaxInitCodeSizeExceeded
+	}
 	// Transactions can't be negative. This may never happen using RLP decoded
 	// transactions but may occur if you create a transaction using the RPC.
 	if tx.Value().Sign() < 0 {
@@ -644,22 +790,68 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		return ErrUnderpriced
 	}
 	// Ensure the transaction adheres to nonce ordering
-	if pool.currentState.GetNonce(from) > tx.Nonce() {
+	if pool.currentState.GetNonceKey(from, tx.NonceKey()) > tx.Nonce() {
 		return ErrNonceTooLow
 	}
 	// Transactor should have enough funds to cover the costs
 	// cost == V + GP * GL
-	if pool.currentState.GetBalance(from).Cmp(tx.Cost()) < 0 {
-		return ErrInsufficientFunds
+	if tx.Type() != types.SponsoredTxType {
+		if pool.currentState.GetBalance(from).Cmp(tx.Cost()) < 0 {
+			return ErrInsufficientFunds
+		}
+	} else if err := pool.validateFeePayer(tx, from); err != nil {
+		return err
+	}
+	// The balance of the sender funds all its nonce keys at once, so it must also
+	// cover the costliest transaction of each of the other keys
+	if others := pool.laneCosts(from, tx.NonceKey()); others.Sign() > 0 {
+		if pool.currentState.GetBalance(from).Cmp(new(big.Int).Add(tx.SenderCost(), others)) < 0 {
+			return ErrInsufficientFunds
+		}
 	}
 	// Ensure the transaction has more gas than the basic tx fee.
 	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul)
 	if err != nil {
 		return err
 	}
+	if intrGas, err = addAuthListGas(intrGas, tx.SetCodeAuthorizations()); err != nil {
+		return err
+	}
 	if tx.Gas() < intrGas {
 		return ErrIntrinsicGas
 	}
+	// The code an account delegates to may spend its balance at any time, so
+	// keep a single transaction of such accounts in flight
+	if _, ok := types.ParseDelegation(pool.currentState.GetCode(from)); ok && pool.setCode {
+		for _, list := range []*txList{pool.pending[from], pool.queue[from]} {
+			if list != nil && list.Len() > 0 && !list.Overlaps(tx) {
+				return ErrInflightTxLimitReached
+			}
+		}
+	}
+	return nil
+}
+
+// validateFeePayer checks that the fee payer of a sponsored transaction signed
+// for it and can cover its fees along with those of the other transactions it
+// sponsors in the pool, while the sender covers the value. Sponsored transactions
+// whose fee payer got drained meanwhile are dropped on reset.
+func (pool *TxPool) validateFeePayer(tx *types.Transaction, from common.Address) error {
+	payer, err := types.FeePayer(pool.signer, tx)
+	if err != nil {
+		return ErrInvalidFeePayer
+	}
+	fee := new(big.Int).Sub(tx.Cost(), tx.Value())
+	if payer == from {
+		fee = tx.Cost()
+	}
+	fee.Add(fee, pool.sponsoredCosts(payer, from, tx))
+	if pool.currentState.GetBalance(payer).Cmp(fee) < 0 {
+		return ErrInsufficientFunds
+	}
+	if pool.currentState.GetBalance(from).Cmp(tx.SenderCost()) < 0 {
+		return ErrInsufficientFunds
+	}
 	return nil
 }
 
@@ -683,7 +875,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 	isLocal := local || pool.locals.containsTx(tx)
 
 	if pool.chainconfig.Scroll.FeeVaultEnabled() {
-		if err := fees.VerifyFee(pool.signer, tx, pool.currentState); err != nil {
+		if err := fees.VerifyFee(pool.signer, tx, pool.currentState, pool.compressedL1Fee); err != nil {
 			log.Trace("Discarding insufficient l1fee transaction", "hash", hash, "err", err)
 			invalidTxMeter.Mark(1)
 			return false, err
@@ -732,6 +924,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 			underpricedTxMeter.Mark(1)
 			pool.removeTx(tx.Hash(), false)
 		}
+		pool.drops.add(TxDropEvicted, drop...)
 	}
 	// Try to replace an existing transaction in the pending pool
 	from, _ := types.Sender(pool.signer, tx) // already validated
@@ -747,6 +940,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed(1)
 			pendingReplaceMeter.Mark(1)
+			pool.drops.add(TxDropReplaced, old)
 		}
 		pool.all.Add(tx, isLocal)
 		pool.priced.Put(tx, isLocal)
@@ -798,6 +992,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction, local boo
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		queuedReplaceMeter.Mark(1)
+		pool.drops.add(TxDropReplaced, old)
 	} else {
 		// Nothing was replaced, bump the queued counter
 		queuedGauge.Inc(1)
@@ -818,6 +1013,57 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction, local boo
 	return old != nil, nil
 }
 
+// stateNonces returns the nonces of the nonce keys of an account in the current
+// state.
+func (pool *TxPool) stateNonces(addr common.Address) func(key uint64) uint64 {
+	return func(key uint64) uint64 {
+		return pool.currentState.GetNonceKey(addr, key)
+	}
+}
+
+// laneCosts returns the sum of the costs of the costliest pooled transaction of
+// each nonce key of an account, except for the given key.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) laneCosts(addr common.Address, skip uint64) *big.Int {
+	costs := make(map[uint64]*big.Int)
+	for _, list := range []*txList{pool.pending[addr], pool.queue[addr]} {
+		if list == nil {
+			continue
+		}
+		for key, cost := range list.Costs() {
+			if key != skip && (costs[key] == nil || costs[key].Cmp(cost) < 0) {
+				costs[key] = cost
+			}
+		}
+	}
+	total := new(big.Int)
+	for _, cost := range costs {
+		total.Add(total, cost)
+	}
+	return total
+}
+
+// sponsoredCosts returns the fees the given payer sponsors in the pool, apart
+// from those of the transaction the given one of the sender would replace.
+func (pool *TxPool) sponsoredCosts(payer common.Address, from common.Address, tx *types.Transaction) *big.Int {
+	total := new(big.Int)
+	for _, sponsored := range pool.all.Sponsored(payer) {
+		if sponsored.Nonce() == tx.Nonce() && sponsored.NonceKey() == tx.NonceKey() {
+			if sender, _ := types.Sender(pool.signer, sponsored); sender == from {
+				continue
+			}
+		}
+		total.Add(total, sponsoredFee(sponsored))
+	}
+	return total
+}
+
+// sponsoredFee returns the fees the payer of a sponsored transaction covers.
+func sponsoredFee(tx *types.Transaction) *big.Int {
+	return new(big.Int).Sub(tx.Cost(), tx.Value())
+}
+
 // journalTx adds the specified transaction to the local disk journal if it is
 // deemed to have been sent from a local account.
 func (pool *TxPool) journalTx(from common.Address, tx *types.Transaction) {
@@ -847,6 +1093,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.all.Remove(hash)
 		pool.priced.Removed(1)
 		pendingDiscardMeter.Mark(1)
+		pool.drops.add(TxDropReplaced, tx)
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -854,12 +1101,13 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		pendingReplaceMeter.Mark(1)
+		pool.drops.add(TxDropReplaced, old)
 	} else {
 		// Nothing was replaced, bump the pending counter
 		pendingGauge.Inc(1)
 	}
 	// Set the potentially new pending nonce and notify any subsystems of the new tx
-	pool.pendingNonces.set(addr, tx.Nonce()+1)
+	pool.pendingNonces.set(addr, tx.NonceKey(), tx.Nonce()+1)
 
 	// Successful promotion, bump the heartbeat
 	pool.beats[addr] = time.Now()
@@ -989,9 +1237,9 @@ func (pool *TxPool) Status(hashes []common.Hash) []TxStatus {
 		}
 		from, _ := types.Sender(pool.signer, tx) // already validated
 		pool.mu.RLock()
-		if txList := pool.pending[from]; txList != nil && txList.txs.items[tx.Nonce()] != nil {
+		if txList := pool.pending[from]; txList != nil && txList.Overlaps(tx) {
 			status[i] = TxStatusPending
-		} else if txList := pool.queue[from]; txList != nil && txList.txs.items[tx.Nonce()] != nil {
+		} else if txList := pool.queue[from]; txList != nil && txList.Overlaps(tx) {
 			status[i] = TxStatusQueued
 		}
 		// implicit else: the tx may have been included into a block between
@@ -1012,6 +1260,24 @@ func (pool *TxPool) Has(hash common.Hash) bool {
 	return pool.all.Get(hash) != nil
 }
 
+// RemoveTxs drops the given transactions from the pool, moving any subsequent
+// transactions of their senders back to the future queue. It is used to mirror
+// evictions from another node's pool and returns the number of transactions
+// actually removed.
+func (pool *TxPool) RemoveTxs(hashes []common.Hash) int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	removed := 0
+	for _, hash := range hashes {
+		if pool.all.Get(hash) != nil {
+			pool.removeTx(hash, true)
+			removed++
+		}
+	}
+	return removed
+}
+
 // removeTx removes a single transaction from the queue, moving all subsequent
 // transactions back to the future queue.
 func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
@@ -1043,7 +1309,7 @@ func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
 				pool.enqueueTx(tx.Hash(), tx, false, false)
 			}
 			// Update the account nonce if needed
-			pool.pendingNonces.setIfLower(addr, tx.Nonce())
+			pool.pendingNonces.setIfLower(addr, tx.NonceKey(), tx.Nonce())
 			// Reduce the pending counter
 			pendingGauge.Dec(int64(1 + len(invalids)))
 			return
@@ -1104,7 +1370,7 @@ func (pool *TxPool) scheduleReorgLoop() {
 		launchNextRun bool
 		reset         *txpoolResetRequest
 		dirtyAccounts *accountSet
-		queuedEvents  = make(map[common.Address]*txSortedMap)
+		queuedEvents  = make(map[txLane]*txSortedMap)
 	)
 	for {
 		// Launch next background reorg if needed
@@ -1117,7 +1383,7 @@ func (pool *TxPool) scheduleReorgLoop() {
 			launchNextRun = false
 
 			reset, dirtyAccounts = nil, nil
-			queuedEvents = make(map[common.Address]*txSortedMap)
+			queuedEvents = make(map[txLane]*txSortedMap)
 		}
 
 		select {
@@ -1145,10 +1411,11 @@ func (pool *TxPool) scheduleReorgLoop() {
 			// Queue up the event, but don't schedule a reorg. It's up to the caller to
 			// request one later if they want the events sent.
 			addr, _ := types.Sender(pool.signer, tx)
-			if _, ok := queuedEvents[addr]; !ok {
-				queuedEvents[addr] = newTxSortedMap()
+			lane := txLane{addr, tx.NonceKey()}
+			if _, ok := queuedEvents[lane]; !ok {
+				queuedEvents[lane] = newTxSortedMap()
 			}
-			queuedEvents[addr].Put(tx)
+			queuedEvents[lane].Put(tx)
 
 		case <-curDone:
 			curDone = nil
@@ -1165,7 +1432,7 @@ func (pool *TxPool) scheduleReorgLoop() {
 }
 
 // runReorg runs reset and promoteExecutables on behalf of scheduleReorgLoop.
-func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirtyAccounts *accountSet, events map[common.Address]*txSortedMap) {
+func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirtyAccounts *accountSet, events map[txLane]*txSortedMap) {
 	defer func(t0 time.Time) {
 		reorgDurationTimer.Update(time.Since(t0))
 	}(time.Now())
@@ -1184,10 +1451,10 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 		pool.reset(reset.oldHead, reset.newHead)
 
 		// Nonces were reset, discard any events that became stale
-		for addr := range events {
-			events[addr].Forward(pool.pendingNonces.get(addr))
-			if events[addr].Len() == 0 {
-				delete(events, addr)
+		for lane := range events {
+			events[lane].Forward(pool.pendingNonces.get(lane.addr, lane.key))
+			if events[lane].Len() == 0 {
+				delete(events, lane)
 			}
 		}
 		// Reset needs promote for all addresses
@@ -1204,16 +1471,19 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 	// remove any transaction that has been included in the block or was invalidated
 	// because of another transaction (e.g. higher gas price).
 	if reset != nil {
+		pool.dropDenied()
 		pool.demoteUnexecutables()
+		pool.dropUnfunded()
 		if reset.newHead != nil && pool.chainconfig.IsLondon(new(big.Int).Add(reset.newHead.Number, big.NewInt(1))) {
 			pendingBaseFee := misc.CalcBaseFee(pool.chainconfig, reset.newHead)
 			pool.priced.SetBaseFee(pendingBaseFee)
 		}
 		// Update all accounts to the latest known pending nonce
-		nonces := make(map[common.Address]uint64, len(pool.pending))
+		nonces := make(map[txLane]uint64, len(pool.pending))
 		for addr, list := range pool.pending {
-			highestPending := list.LastElement()
-			nonces[addr] = highestPending.Nonce() + 1
+			for key, nonce := range list.Nonces() {
+				nonces[txLane{addr, key}] = nonce
+			}
 		}
 		pool.pendingNonces.setAll(nonces)
 	}
@@ -1228,10 +1498,11 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 	// Notify subsystems for newly added transactions
 	for _, tx := range promoted {
 		addr, _ := types.Sender(pool.signer, tx)
-		if _, ok := events[addr]; !ok {
-			events[addr] = newTxSortedMap()
+		lane := txLane{addr, tx.NonceKey()}
+		if _, ok := events[lane]; !ok {
+			events[lane] = newTxSortedMap()
 		}
-		events[addr].Put(tx)
+		events[lane].Put(tx)
 	}
 	if len(events) > 0 {
 		var txs []*types.Transaction
@@ -1240,6 +1511,7 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 		}
 		pool.txFeed.Send(NewTxsEvent{txs})
 	}
+	pool.announceDrops()
 }
 
 // reset retrieves the current state of the blockchain and ensures the content
@@ -1332,6 +1604,12 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 
 	pool.eip2718 = pool.chainconfig.Scroll.EnableEIP2718 && pool.chainconfig.IsBerlin(next)
 	pool.eip1559 = pool.chainconfig.Scroll.EnableEIP1559 && pool.chainconfig.IsLondon(next)
+	pool.compressedL1Fee = pool.chainconfig.Scroll.IsCompressedL1Fee(next)
+	pool.sponsoredTx = pool.chainconfig.Scroll.IsSponsoredTx(next)
+	pool.nonceKey = pool.chainconfig.Scroll.IsNonceKey(next)
+	pool.setCode = pool.chainconfig.Scroll.IsSetCode(next)
+	pool.maxInitCodeSize = pool.chainconfig.Scroll.MaxInitCodeSize(next)
+	pool.denylist = pool.chainconfig.Scroll.IsDenylist(next)
 }
 
 // promoteExecutables moves transactions that have become processable from the
@@ -1348,7 +1626,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 			continue // Just in case someone calls with a non existing account
 		}
 		// Drop all transactions that are deemed too old (low nonce)
-		forwards := list.Forward(pool.currentState.GetNonce(addr))
+		forwards := list.Forward(pool.stateNonces(addr))
 		for _, tx := range forwards {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
@@ -1362,9 +1640,10 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 		}
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
+		pool.drops.add(TxDropInvalid, drops...)
 
 		// Gather all executable transactions and promote them
-		readies := list.Ready(pool.pendingNonces.get(addr))
+		readies := list.Ready(func(key uint64) uint64 { return pool.pendingNonces.get(addr, key) })
 		for _, tx := range readies {
 			hash := tx.Hash()
 			if pool.promoteTx(addr, hash, tx) {
@@ -1384,6 +1663,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
 			queuedRateLimitMeter.Mark(int64(len(caps)))
+			pool.drops.add(TxDropEvicted, caps...)
 		}
 		// Mark all the items dropped as removed
 		pool.priced.Removed(len(forwards) + len(drops) + len(caps))
@@ -1446,10 +1726,11 @@ func (pool *TxPool) truncatePending() {
 						pool.all.Remove(hash)
 
 						// Update the account nonce to the dropped transaction
-						pool.pendingNonces.setIfLower(offenders[i], tx.Nonce())
+						pool.pendingNonces.setIfLower(offenders[i], tx.NonceKey(), tx.Nonce())
 						log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 					}
 					pool.priced.Removed(len(caps))
+					pool.drops.add(TxDropEvicted, caps...)
 					pendingGauge.Dec(int64(len(caps)))
 					if pool.locals.contains(offenders[i]) {
 						localGauge.Dec(int64(len(caps)))
@@ -1473,10 +1754,11 @@ func (pool *TxPool) truncatePending() {
 					pool.all.Remove(hash)
 
 					// Update the account nonce to the dropped transaction
-					pool.pendingNonces.setIfLower(addr, tx.Nonce())
+					pool.pendingNonces.setIfLower(addr, tx.NonceKey(), tx.Nonce())
 					log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 				}
 				pool.priced.Removed(len(caps))
+				pool.drops.add(TxDropEvicted, caps...)
 				pendingGauge.Dec(int64(len(caps)))
 				if pool.locals.contains(addr) {
 					localGauge.Dec(int64(len(caps)))
@@ -1517,11 +1799,13 @@ func (pool *TxPool) truncateQueue() {
 
 		// Drop all transactions if they are less than the overflow
 		if size := uint64(list.Len()); size <= drop {
-			for _, tx := range list.Flatten() {
+			txs := list.Flatten()
+			for _, tx := range txs {
 				pool.removeTx(tx.Hash(), true)
 			}
 			drop -= size
 			queuedRateLimitMeter.Mark(int64(size))
+			pool.drops.add(TxDropEvicted, txs...)
 			continue
 		}
 		// Otherwise drop only last few transactions
@@ -1530,6 +1814,7 @@ func (pool *TxPool) truncateQueue() {
 			pool.removeTx(txs[i].Hash(), true)
 			drop--
 			queuedRateLimitMeter.Mark(1)
+			pool.drops.add(TxDropEvicted, txs[i])
 		}
 	}
 }
@@ -1544,10 +1829,10 @@ func (pool *TxPool) truncateQueue() {
 func (pool *TxPool) demoteUnexecutables() {
 	// Iterate over all accounts and demote any non-executable transactions
 	for addr, list := range pool.pending {
-		nonce := pool.currentState.GetNonce(addr)
+		nonces := pool.stateNonces(addr)
 
 		// Drop all transactions that are deemed too old (low nonce)
-		olds := list.Forward(nonce)
+		olds := list.Forward(nonces)
 		for _, tx := range olds {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
@@ -1561,6 +1846,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			pool.all.Remove(hash)
 		}
 		pendingNofundsMeter.Mark(int64(len(drops)))
+		pool.drops.add(TxDropInvalid, drops...)
 
 		for _, tx := range invalids {
 			hash := tx.Hash()
@@ -1574,7 +1860,13 @@ func (pool *TxPool) demoteUnexecutables() {
 			localGauge.Dec(int64(len(olds) + len(drops) + len(invalids)))
 		}
 		// If there's a gap in front, alert (should never happen) and postpone all transactions
-		if list.Len() > 0 && list.txs.Get(nonce) == nil {
+		gap := false
+		for _, key := range list.laneKeys() {
+			if list.lane(key).Get(nonces(key)) == nil {
+				gap = true
+			}
+		}
+		if gap {
 			gapped := list.Cap(0)
 			for _, tx := range gapped {
 				hash := tx.Hash()
@@ -1594,6 +1886,80 @@ func (pool *TxPool) demoteUnexecutables() {
 	}
 }
 
+// dropDenied removes the transactions to the addresses flagged by the denylist
+// registry since they were added, demoting the later transactions of their
+// senders.
+func (pool *TxPool) dropDenied() {
+	if !pool.denylist {
+		return
+	}
+	var (
+		denied = make(map[common.Address]bool)
+		drops  []*types.Transaction
+	)
+	for _, lists := range []map[common.Address]*txList{pool.pending, pool.queue} {
+		for _, list := range lists {
+			for _, tx := range list.Flatten() {
+				to := tx.To()
+				if to == nil {
+					continue
+				}
+				flagged, ok := denied[*to]
+				if !ok {
+					flagged = denylist.IsDenied(pool.chainconfig.Scroll.Denylist, pool.currentState, *to)
+					denied[*to] = flagged
+				}
+				if flagged {
+					drops = append(drops, tx)
+				}
+			}
+		}
+	}
+	for _, tx := range drops {
+		log.Trace("Removed transaction to denied address", "hash", tx.Hash())
+		pool.removeTx(tx.Hash(), true)
+	}
+	pool.drops.add(TxDropInvalid, drops...)
+}
+
+// dropUnfunded removes the sponsored transactions whose fee payer can no longer
+// cover the fees of all of them, the highest nonces first, demoting the later
+// transactions of their senders.
+func (pool *TxPool) dropUnfunded() {
+	var drops []*types.Transaction
+	for _, payer := range pool.all.FeePayers() {
+		var (
+			txs     = pool.all.Sponsored(payer)
+			total   = new(big.Int)
+			balance = pool.currentState.GetBalance(payer)
+		)
+		for _, tx := range txs {
+			total.Add(total, sponsoredFee(tx))
+		}
+		if total.Cmp(balance) <= 0 {
+			continue
+		}
+		sort.Slice(txs, func(i, j int) bool {
+			if txs[i].Nonce() != txs[j].Nonce() {
+				return txs[i].Nonce() > txs[j].Nonce()
+			}
+			return bytes.Compare(txs[i].Hash().Bytes(), txs[j].Hash().Bytes()) < 0
+		})
+		for _, tx := range txs {
+			if total.Cmp(balance) <= 0 {
+				break
+			}
+			total.Sub(total, sponsoredFee(tx))
+			drops = append(drops, tx)
+		}
+	}
+	for _, tx := range drops {
+		log.Trace("Removed unfunded sponsored transaction", "hash", tx.Hash())
+		pool.removeTx(tx.Hash(), true)
+	}
+	pool.drops.add(TxDropInvalid, drops...)
+}
+
 // addressByHeartbeat is an account address tagged with its last activity timestamp.
 type addressByHeartbeat struct {
 	address   common.Address
@@ -1699,17 +2065,20 @@ func (as *accountSet) merge(other *accountSet) {
 // This lookup set combines the notion of "local transactions", which is useful
 // to build upper-level structure.
 type txLookup struct {
-	slots   int
-	lock    sync.RWMutex
-	locals  map[common.Hash]*types.Transaction
-	remotes map[common.Hash]*types.Transaction
+	slots     int
+	lock      sync.RWMutex
+	locals    map[common.Hash]*types.Transaction
+	remotes   map[common.Hash]*types.Transaction
+	sponsored map[common.Address]map[common.Hash]*types.Transaction // Sponsored transactions by fee payer
+	store     *txStore                                              // Database the transactions are persisted in, if enabled
 }
 
 // newTxLookup returns a new txLookup structure.
 func newTxLookup() *txLookup {
 	return &txLookup{
-		locals:  make(map[common.Hash]*types.Transaction),
-		remotes: make(map[common.Hash]*types.Transaction),
+		locals:    make(map[common.Hash]*types.Transaction),
+		remotes:   make(map[common.Hash]*types.Transaction),
+		sponsored: make(map[common.Address]map[common.Hash]*types.Transaction),
 	}
 }
 
@@ -1787,6 +2156,31 @@ func (t *txLookup) RemoteCount() int {
 	return len(t.remotes)
 }
 
+// Sponsored returns the transactions in the lookup whose fees the given payer
+// covers.
+func (t *txLookup) Sponsored(payer common.Address) types.Transactions {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	txs := make(types.Transactions, 0, len(t.sponsored[payer]))
+	for _, tx := range t.sponsored[payer] {
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// FeePayers returns the fee payers of the sponsored transactions in the lookup.
+func (t *txLookup) FeePayers() []common.Address {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	payers := make([]common.Address, 0, len(t.sponsored))
+	for payer := range t.sponsored {
+		payers = append(payers, payer)
+	}
+	return payers
+}
+
 // Slots returns the current number of slots used in the lookup.
 func (t *txLookup) Slots() int {
 	t.lock.RLock()
@@ -1808,6 +2202,15 @@ func (t *txLookup) Add(tx *types.Transaction, local bool) {
 	} else {
 		t.remotes[tx.Hash()] = tx
 	}
+	if payer := tx.FeePayer(); payer != nil {
+		if t.sponsored[*payer] == nil {
+			t.sponsored[*payer] = make(map[common.Hash]*types.Transaction)
+		}
+		t.sponsored[*payer][tx.Hash()] = tx
+	}
+	if t.store != nil {
+		t.store.insert(tx, local)
+	}
 }
 
 // Remove removes a transaction from the lookup.
@@ -1828,6 +2231,16 @@ func (t *txLookup) Remove(hash common.Hash) {
 
 	delete(t.locals, hash)
 	delete(t.remotes, hash)
+
+	if payer := tx.FeePayer(); payer != nil {
+		delete(t.sponsored[*payer], hash)
+		if len(t.sponsored[*payer]) == 0 {
+			delete(t.sponsored, *payer)
+		}
+	}
+	if t.store != nil {
+		t.store.remove(hash)
+	}
 }
 
 // RemoteToLocals migrates the transactions belongs to the given locals to locals
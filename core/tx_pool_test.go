@@ -24,17 +24,20 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/event"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/denylist"
 	"github.com/scroll-tech/go-ethereum/trie"
 )
 
@@ -160,15 +163,17 @@ func validateTxPoolInternals(pool *TxPool) error {
 	}
 	// Ensure the next nonce to assign is the correct one
 	for addr, txs := range pool.pending {
-		// Find the last transaction
-		var last uint64
-		for nonce := range txs.txs.items {
-			if last < nonce {
-				last = nonce
+		for _, key := range txs.laneKeys() {
+			// Find the last transaction
+			var last uint64
+			for nonce := range txs.lane(key).items {
+				if last < nonce {
+					last = nonce
+				}
+			}
+			if nonce := pool.pendingNonces.get(addr, key); nonce != last+1 {
+				return fmt.Errorf("pending nonce mismatch of key %d: have %v, want %v", key, nonce, last+1)
 			}
-		}
-		if nonce := pool.pendingNonces.get(addr); nonce != last+1 {
-			return fmt.Errorf("pending nonce mismatch: have %v, want %v", nonce, last+1)
 		}
 	}
 	return nil
@@ -658,6 +663,40 @@ func TestTransactionDropping(t *testing.T) {
 	}
 }
 
+// Tests that explicitly removed transactions are dropped from the pool and any
+// subsequent ones of the same sender are postponed into the future queue.
+func TestTransactionRemoval(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPoolWithConfig(noL1feeConfig)
+	defer pool.Stop()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000000))
+
+	txs := []*types.Transaction{transaction(0, 100000, key), transaction(1, 100000, key), transaction(2, 100000, key), transaction(5, 100000, key)}
+	for i, err := range pool.AddRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("tx %d: failed to add transaction: %v", i, err)
+		}
+	}
+	if removed := pool.RemoveTxs([]common.Hash{txs[1].Hash(), txs[3].Hash(), {0x01}}); removed != 2 {
+		t.Fatalf("removed transaction mismatch: have %d, want %d", removed, 2)
+	}
+	if pool.Has(txs[1].Hash()) || pool.Has(txs[3].Hash()) {
+		t.Errorf("removed transaction still present")
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Errorf("pool stats mismatch: have %d pending %d queued, want 1 pending 1 queued", pending, queued)
+	}
+	if _, ok := pool.queue[account].txs.items[txs[2].Nonce()]; !ok {
+		t.Errorf("subsequent transaction not postponed: %v", txs[2])
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that if a transaction is dropped from the current pending pool (e.g. out
 // of fund), all consecutive (still valid, but not executable) transactions are
 // postponed back into the future queue to prevent broadcasting them.
@@ -2361,6 +2400,96 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// Tests that the pooled transactions are persisted in the database, locals and
+// remotes alike, and restored with their receive times and sources on restart,
+// while the transactions dropped from the pool are deleted.
+func TestTransactionPersistence(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{1000000, statedb, new(event.Feed)}
+
+	db := rawdb.NewMemoryDatabase()
+	config := testTxPoolConfig
+	config.Persist = true
+	config.Database = db
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+
+	// Add two local and two remote transactions, received in a known order
+	txs := []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(1), remote),
+		pricedTransaction(0, 100000, big.NewInt(1), local),
+		pricedTransaction(1, 100000, big.NewInt(1), local),
+		pricedTransaction(1, 100000, big.NewInt(1), remote),
+	}
+	for i, tx := range txs {
+		tx.SetTime(time.Unix(int64(1000+i), 0))
+	}
+	if err := pool.addRemoteSync(txs[0]); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	if errs := pool.AddLocals(txs[1:3]); errs[0] != nil || errs[1] != nil {
+		t.Fatalf("failed to add local transactions: %v", errs)
+	}
+	if err := pool.addRemoteSync(txs[3]); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	stored := rawdb.ReadPooledTransactions(db)
+	if len(stored) != 4 {
+		t.Fatalf("stored transactions mismatched: have %d, want %d", len(stored), 4)
+	}
+	for _, entry := range stored {
+		want := TxSourceRemote
+		if from, _ := types.Sender(pool.signer, entry.Tx); from == crypto.PubkeyToAddress(local.PublicKey) {
+			want = TxSourceLocal
+		}
+		if TxSource(entry.Source) != want {
+			t.Errorf("transaction %x: source mismatch: have %s, want %s", entry.Tx.Hash(), entry.Source, want)
+		}
+	}
+	// Terminate the old pool, bump the local nonce, create a new pool and ensure
+	// the remaining transactions survive with their receive times
+	pool.Stop()
+	statedb.SetNonce(crypto.PubkeyToAddress(local.PublicKey), 1)
+
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	pending, queued := pool.Stats()
+	if pending != 3 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 3)
+	}
+	if queued != 0 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 0)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	for _, i := range []int{0, 2, 3} {
+		have := pool.Get(txs[i].Hash())
+		if have == nil {
+			t.Fatalf("transaction %d missing", i)
+		}
+		if !have.Time().Equal(txs[i].Time()) {
+			t.Errorf("transaction %d: time mismatch: have %v, want %v", i, have.Time(), txs[i].Time())
+		}
+	}
+	if pool.all.GetLocal(txs[2].Hash()) == nil || pool.all.GetLocal(txs[3].Hash()) != nil {
+		t.Errorf("transaction locality not restored")
+	}
+	// The transaction included in the meantime is deleted from the database
+	if stored := rawdb.ReadPooledTransactions(db); len(stored) != 3 {
+		t.Fatalf("stored transactions mismatched: have %d, want %d", len(stored), 3)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
@@ -2575,3 +2704,547 @@ func BenchmarkPoolMultiAccountBatchInsert(b *testing.B) {
 		pool.AddRemotesSync([]*types.Transaction{tx})
 	}
 }
+
+// Tests that sponsored transactions are only accepted once their fork activates,
+// with a valid fee payer signature and the fees covered by the fee payer.
+func TestTransactionSponsored(t *testing.T) {
+	t.Parallel()
+
+	config := *eip1559NoL1feeConfig
+	config.Scroll.SponsoredTxBlock = common.Big0
+
+	pool, key := setupTxPoolWithConfig(&config)
+	defer pool.Stop()
+
+	payerKey, _ := crypto.GenerateKey()
+	sign := func(nonce uint64, payer *ecdsa.PrivateKey) *types.Transaction {
+		tx := types.MustSignNewTx(key, pool.signer, &types.SponsoredTx{
+			ChainID:   config.ChainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(1),
+			Gas:       params.TxGas,
+			To:        &common.Address{},
+			Value:     big.NewInt(100),
+			FeePayer:  crypto.PubkeyToAddress(payerKey.PublicKey),
+		})
+		if payer != nil {
+			tx, _ = types.SignFeePayer(tx, pool.signer, payer)
+		}
+		return tx
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, sender, big.NewInt(100))
+
+	if err := pool.AddRemote(sign(0, nil)); !errors.Is(err, ErrInvalidFeePayer) {
+		t.Fatalf("unsigned by fee payer: have %v, want %v", err, ErrInvalidFeePayer)
+	}
+	if err := pool.AddRemote(sign(0, payerKey)); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("unfunded fee payer: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	testAddBalance(pool, crypto.PubkeyToAddress(payerKey.PublicKey), big.NewInt(int64(params.TxGas)))
+	if err := pool.addRemoteSync(sign(0, payerKey)); err != nil {
+		t.Fatalf("failed to add sponsored transaction: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatch: have %d, want 1", pending)
+	}
+	// Sponsored transactions are rejected before their fork
+	unforked, key := setupTxPoolWithConfig(eip1559NoL1feeConfig)
+	defer unforked.Stop()
+
+	tx := types.MustSignNewTx(key, unforked.signer, &types.SponsoredTx{ChainID: config.ChainID, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1), Gas: params.TxGas, Value: new(big.Int)})
+	if err := unforked.AddRemote(tx); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("unforked pool: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
+
+// Tests that a fee payer must cover the fees of all the transactions it sponsors
+// in the pool, those it can no longer cover being dropped on reset.
+func TestTransactionSponsoredCosts(t *testing.T) {
+	t.Parallel()
+
+	config := *eip1559NoL1feeConfig
+	config.Scroll.SponsoredTxBlock = common.Big0
+
+	pool, key := setupTxPoolWithConfig(&config)
+	defer pool.Stop()
+
+	var (
+		otherKey, _ = crypto.GenerateKey()
+		payerKey, _ = crypto.GenerateKey()
+		payer       = crypto.PubkeyToAddress(payerKey.PublicKey)
+	)
+	sign := func(key *ecdsa.PrivateKey) *types.Transaction {
+		tx := types.MustSignNewTx(key, pool.signer, &types.SponsoredTx{
+			ChainID:   config.ChainID,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(1),
+			Gas:       params.TxGas,
+			To:        &common.Address{},
+			Value:     new(big.Int),
+			FeePayer:  payer,
+		})
+		tx, _ = types.SignFeePayer(tx, pool.signer, payerKey)
+		return tx
+	}
+	testAddBalance(pool, payer, big.NewInt(int64(params.TxGas)))
+
+	if err := pool.addRemoteSync(sign(key)); err != nil {
+		t.Fatalf("failed to add sponsored transaction: %v", err)
+	}
+	// The fee payer can't cover the fees of both senders
+	if err := pool.AddRemote(sign(otherKey)); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("overdrawn fee payer: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	testAddBalance(pool, payer, big.NewInt(int64(params.TxGas)))
+	if err := pool.addRemoteSync(sign(otherKey)); err != nil {
+		t.Fatalf("failed to add sponsored transaction: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 2 {
+		t.Fatalf("pending transactions mismatch: have %d, want 2", pending)
+	}
+	// Draining the fee payer drops the transactions it can no longer cover
+	testAddBalance(pool, payer, big.NewInt(-1))
+	<-pool.requestReset(nil, nil)
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatch: have %d, want 1", pending)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that transactions using different nonce keys of an account are ordered
+// independently, a gap in one key not blocking the others.
+func TestTransactionNonceKeys(t *testing.T) {
+	t.Parallel()
+
+	config := *eip1559NoL1feeConfig
+	config.Scroll.NonceKeyBlock = common.Big0
+
+	pool, key := setupTxPoolWithConfig(&config)
+	defer pool.Stop()
+
+	sign := func(nonceKey, nonce uint64, value int64) *types.Transaction {
+		return types.MustSignNewTx(key, pool.signer, &types.NonceKeyTx{
+			ChainID:   config.ChainID,
+			NonceKey:  nonceKey,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(1),
+			Gas:       params.TxGas,
+			To:        &common.Address{},
+			Value:     big.NewInt(value),
+		})
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, sender, big.NewInt(1000000))
+
+	for _, tx := range []*types.Transaction{sign(0, 0, 0), sign(1, 0, 0), sign(1, 1, 0), sign(2, 1, 0)} {
+		if err := pool.addRemoteSync(tx); err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 3 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 3/1", pending, queued)
+	}
+	for nonceKey, want := range map[uint64]uint64{0: 1, 1: 2, 2: 0, 3: 0} {
+		if nonce := pool.NonceKey(sender, nonceKey); nonce != want {
+			t.Errorf("nonce key %d mismatch: have %d, want %d", nonceKey, nonce, want)
+		}
+	}
+	// Filling the gap of a key promotes its queued transactions
+	if err := pool.addRemoteSync(sign(2, 0, 0)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 5 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 5/0", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// All the nonce keys are reported under their sender
+	if pending, _ := pool.Content(); len(pending) != 1 || len(pending[sender]) != 5 {
+		t.Fatalf("pending content mismatch: have %d accounts, want 5 transactions of the sender", len(pending))
+	}
+	// The balance must cover the costliest transaction of every nonce key at once
+	value := int64(1000000 - 4*params.TxGas)
+	if err := pool.addRemoteSync(sign(3, 0, value+1)); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("overdrafting transaction: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	if err := pool.addRemoteSync(sign(3, 0, value)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	// Lowering the balance drops the transactions of the highest nonce key first
+	testAddBalance(pool, sender, big.NewInt(-1))
+	<-pool.requestReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending != 5 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 5/0", pending, queued)
+	}
+	if pool.pending[sender].lane(3) != nil {
+		t.Fatalf("unfunded nonce key kept")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Nonce key transactions are rejected before their fork
+	unforked, key := setupTxPoolWithConfig(eip1559NoL1feeConfig)
+	defer unforked.Stop()
+
+	tx := types.MustSignNewTx(key, unforked.signer, &types.NonceKeyTx{ChainID: config.ChainID, NonceKey: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1), Gas: params.TxGas, Value: new(big.Int)})
+	if err := unforked.AddRemote(tx); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("unforked pool: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
+
+// Tests that set-code transactions are validated against their fork and auth
+// list, and that accounts delegating their code keep a single transaction in
+// flight.
+func TestTransactionSetCode(t *testing.T) {
+	t.Parallel()
+
+	config := *eip1559NoL1feeConfig
+	config.Scroll.SetCodeBlock = common.Big0
+
+	pool, key := setupTxPoolWithConfig(&config)
+	defer pool.Stop()
+
+	authKey, _ := crypto.GenerateKey()
+	auth, _ := types.SignSetCode(authKey, types.SetCodeAuthorization{ChainID: config.ChainID, Address: common.Address{0xaa}})
+	sign := func(nonce uint64, authList []types.SetCodeAuthorization) *types.Transaction {
+		return types.MustSignNewTx(key, pool.signer, &types.SetCodeTx{
+			ChainID:   config.ChainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(1),
+			Gas:       params.TxGas + params.TxAuthEmptyAccountGas,
+			To:        common.Address{0xbb},
+			Value:     new(big.Int),
+			AuthList:  authList,
+		})
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, sender, big.NewInt(1000000))
+
+	if err := pool.AddRemote(sign(0, []types.SetCodeAuthorization{})); !errors.Is(err, ErrEmptyAuthList) {
+		t.Fatalf("empty auth list: have %v, want %v", err, ErrEmptyAuthList)
+	}
+	if err := pool.addRemoteSync(sign(0, []types.SetCodeAuthorization{auth, auth})); !errors.Is(err, ErrIntrinsicGas) {
+		t.Fatalf("auth list gas: have %v, want %v", err, ErrIntrinsicGas)
+	}
+	if err := pool.addRemoteSync(sign(0, []types.SetCodeAuthorization{auth})); err != nil {
+		t.Fatalf("failed to add set-code transaction: %v", err)
+	}
+	// Once the sender delegates its code, only one of its transactions is kept
+	pool.mu.Lock()
+	pool.currentState.SetCode(sender, types.AddressToDelegation(common.Address{0xaa}))
+	pool.mu.Unlock()
+
+	if err := pool.addRemoteSync(sign(1, []types.SetCodeAuthorization{auth})); !errors.Is(err, ErrInflightTxLimitReached) {
+		t.Fatalf("second in-flight transaction: have %v, want %v", err, ErrInflightTxLimitReached)
+	}
+	// Set-code transactions are rejected before their fork
+	unforked, key := setupTxPoolWithConfig(eip1559NoL1feeConfig)
+	defer unforked.Stop()
+
+	tx := types.MustSignNewTx(key, unforked.signer, &types.SetCodeTx{ChainID: config.ChainID, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1), Gas: params.TxGas, Value: new(big.Int), AuthList: []types.SetCodeAuthorization{auth}})
+	if err := unforked.AddRemote(tx); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("unforked pool: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
+
+// Tests that contract creations above the init code size limit of the chain
+// config are rejected.
+func TestTransactionInitCodeSize(t *testing.T) {
+	t.Parallel()
+
+	config := *eip1559NoL1feeConfig
+	config.Scroll.CodeSize = &params.CodeSizeConfig{Block: common.Big0, MaxInitCodeSize: 64}
+
+	pool, key := setupTxPoolWithConfig(&config)
+	defer pool.Stop()
+
+	sign := func(nonce uint64, to *common.Address, size int) *types.Transaction {
+		return types.MustSignNewTx(key, pool.signer, &types.DynamicFeeTx{
+			ChainID:   config.ChainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(1),
+			Gas:       100000,
+			To:        to,
+			Data:      make([]byte, size),
+		})
+	}
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	if err := pool.addRemoteSync(sign(0, nil, 65)); !errors.Is(err, ErrMaxInitCodeSizeExceeded) {
+		t.Fatalf("oversized init code: have %v, want %v", err, ErrMaxInitCodeSizeExceeded)
+	}
+	if err := pool.addRemoteSync(sign(0, nil, 64)); err != nil {
+		t.Fatalf("failed to add contract creation: %v", err)
+	}
+	// The limit doesn't apply to the calldata of calls
+	if err := pool.addRemoteSync(sign(1, &common.Address{0xaa}, 65)); err != nil {
+		t.Fatalf("failed to add call: %v", err)
+	}
+}
+
+// Tests that the transaction classes paused by the operator are refused until
+// their rule expires or is lifted.
+func TestTransactionPause(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	now := time.Unix(1000, 0)
+	pool.pauses.now = func() time.Time { return now }
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	var (
+		target   = common.Address{0xaa}
+		selector = hexutil.Bytes{0xde, 0xad, 0xbe, 0xef}
+	)
+	sign := func(nonce uint64, to *common.Address, data []byte) *types.Transaction {
+		var tx *types.Transaction
+		if to == nil {
+			tx = types.NewContractCreation(nonce, new(big.Int), 100000, big.NewInt(1), data)
+		} else {
+			tx = types.NewTransaction(nonce, *to, new(big.Int), 100000, big.NewInt(1), data)
+		}
+		signed, _ := types.SignTx(tx, types.HomesteadSigner{}, key)
+		return signed
+	}
+	// Invalid rules are rejected
+	for i, rule := range []TxPauseRule{
+		{Expiry: 2000},
+		{Create: true, To: &target, Expiry: 2000},
+		{Selector: hexutil.Bytes{0xde}, Expiry: 2000},
+		{Create: true, Expiry: 1000},
+	} {
+		if _, err := pool.PauseTxs(rule); err == nil {
+			t.Errorf("rule %d: invalid rule accepted", i)
+		}
+	}
+	create, err := pool.PauseTxs(TxPauseRule{Create: true, Expiry: 2000, Reason: "exploit"})
+	if err != nil {
+		t.Fatalf("failed to pause contract creations: %v", err)
+	}
+	if _, err := pool.PauseTxs(TxPauseRule{To: &target, Selector: selector, Expiry: 3000}); err != nil {
+		t.Fatalf("failed to pause selector: %v", err)
+	}
+	if rules := pool.PausedTxs(); len(rules) != 2 || rules[0].ID != create || !rules[0].Create {
+		t.Fatalf("pause rules mismatch: have %+v", rules)
+	}
+	if err := pool.addRemoteSync(sign(0, nil, nil)); !errors.Is(err, ErrTxPaused) {
+		t.Fatalf("paused contract creation: have %v, want %v", err, ErrTxPaused)
+	}
+	if err := pool.addRemoteSync(sign(0, &target, append(common.CopyBytes(selector), 0x01))); !errors.Is(err, ErrTxPaused) {
+		t.Fatalf("paused selector: have %v, want %v", err, ErrTxPaused)
+	}
+	// Other selectors and recipients are accepted
+	if err := pool.addRemoteSync(sign(0, &target, []byte{0xde, 0xad, 0xbe, 0xee})); err != nil {
+		t.Fatalf("failed to add call of another selector: %v", err)
+	}
+	if err := pool.addRemoteSync(sign(1, &common.Address{0xbb}, selector)); err != nil {
+		t.Fatalf("failed to add call to another recipient: %v", err)
+	}
+	// Rules lift once expired or removed
+	now = time.Unix(2000, 0)
+	if err := pool.addRemoteSync(sign(2, nil, nil)); err != nil {
+		t.Fatalf("failed to add contract creation after expiry: %v", err)
+	}
+	rules := pool.PausedTxs()
+	if len(rules) != 1 {
+		t.Fatalf("pause rules mismatch after expiry: have %+v", rules)
+	}
+	if !pool.UnpauseTxs(rules[0].ID) || pool.UnpauseTxs(rules[0].ID) {
+		t.Fatalf("unpause mismatch")
+	}
+	if err := pool.addRemoteSync(sign(3, &target, selector)); err != nil {
+		t.Fatalf("failed to add call after unpause: %v", err)
+	}
+}
+
+// Tests that transactions to the addresses flagged by the denylist registry are
+// rejected, and dropped once flagged after their admission.
+func TestTransactionDenylist(t *testing.T) {
+	t.Parallel()
+
+	registry := common.HexToAddress("0xde71")
+	config := *eip1559NoL1feeConfig
+	config.Scroll.Denylist = &params.DenylistConfig{Block: common.Big0, Registry: registry, Slot: 1}
+
+	pool, key := setupTxPoolWithConfig(&config)
+	defer pool.Stop()
+
+	statedb := pool.chain.(*testBlockChain).statedb
+	flag := func(addr common.Address) {
+		pool.mu.Lock()
+		statedb.SetState(registry, denylist.Slot(1, addr), common.BigToHash(common.Big1))
+		pool.mu.Unlock()
+	}
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+	flag(common.Address{0xaa})
+
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(1), key)); err != nil {
+		t.Fatalf("failed to add transaction to an allowed address: %v", err)
+	}
+	denied, _ := types.SignTx(types.NewTransaction(1, common.Address{0xaa}, big.NewInt(100), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	if err := pool.addRemoteSync(denied); !errors.Is(err, ErrDeniedRecipient) {
+		t.Fatalf("denied recipient: have %v, want %v", err, ErrDeniedRecipient)
+	}
+	// Flagging the recipient of a pooled transaction drops it on the next reset
+	flag(common.Address{})
+	<-pool.requestReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("pool size mismatch: have %d pending, %d queued, want none", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that transactions outliving the transaction lifetime are dropped, locals
+// included, and that the drops are announced and remembered with their reason.
+func TestTransactionExpiry(t *testing.T) {
+	// Reduce the eviction interval to a testable amount
+	defer func(old time.Duration) { evictionInterval = old }(evictionInterval)
+	evictionInterval = time.Millisecond * 100
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{1000000, statedb, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.TxLifetime = time.Minute
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	drops := make(chan DroppedTxsEvent, 16)
+	sub := pool.SubscribeDroppedTxsEvent(drops)
+	defer sub.Unsubscribe()
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+
+	// Replacements are reported as such
+	replaced := pricedTransaction(0, 100000, big.NewInt(1), remote)
+	if err := pool.addRemoteSync(replaced); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	fresh := pricedTransaction(0, 100000, big.NewInt(2), remote)
+	if err := pool.addRemoteSync(fresh); err != nil {
+		t.Fatalf("failed to replace remote transaction: %v", err)
+	}
+	select {
+	case ev := <-drops:
+		if ev.Reason != TxDropReplaced || len(ev.Txs) != 1 || ev.Txs[0].Hash() != replaced.Hash() {
+			t.Fatalf("replacement event mismatch: have %s %v", ev.Reason, ev.Txs)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("replacement not announced")
+	}
+	// Transactions first seen longer ago than the lifetime expire, locals too
+	stale := pricedTransaction(0, 100000, big.NewInt(1), local)
+	stale.SetTime(time.Now().Add(-time.Hour))
+	if err := pool.AddLocal(stale); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	select {
+	case ev := <-drops:
+		if ev.Reason != TxDropExpired || len(ev.Txs) != 1 || ev.Txs[0].Hash() != stale.Hash() {
+			t.Fatalf("expiry event mismatch: have %s %v", ev.Reason, ev.Txs)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expiry not announced")
+	}
+	if pool.Get(stale.Hash()) != nil {
+		t.Fatalf("expired transaction still pooled")
+	}
+	if pool.Get(fresh.Hash()) == nil {
+		t.Fatalf("fresh transaction expired")
+	}
+	for tx, want := range map[*types.Transaction]string{replaced: TxDropReplaced, stale: TxDropExpired, fresh: ""} {
+		if reason := pool.DropReason(tx.Hash()); reason != want {
+			t.Errorf("drop reason mismatch for %x: have %q, want %q", tx.Hash(), reason, want)
+		}
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the nonce gaps blocking queued transactions and the executable
+// transactions priced too low are reported, and that gap-blocked queues expire
+// after the gap lifetime.
+func TestTransactionNonceGaps(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{1000000, statedb, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.GapLifetime = time.Minute
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, addr, big.NewInt(1000000000))
+
+	for _, nonce := range []uint64{0, 3, 4, 7} {
+		if err := pool.AddLocal(pricedTransaction(nonce, 100000, big.NewInt(1), key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", nonce, err)
+		}
+	}
+	next, gaps, blocked := pool.NonceGaps(addr)
+	if next != 1 {
+		t.Fatalf("next nonce mismatch: have %d, want 1", next)
+	}
+	if want := []NonceGap{{1, 2}, {5, 6}}; !reflect.DeepEqual(gaps, want) {
+		t.Fatalf("gaps mismatch: have %v, want %v", gaps, want)
+	}
+	if len(blocked) != 3 {
+		t.Fatalf("blocked transactions mismatch: have %d, want 3", len(blocked))
+	}
+	// Raising the minimum tip leaves the executable local transaction stuck
+	if stuck := pool.StuckTxs(addr); len(stuck) != 0 {
+		t.Fatalf("stuck transactions before price raise: have %d, want 0", len(stuck))
+	}
+	pool.SetGasPrice(big.NewInt(5))
+	stuck := pool.StuckTxs(addr)
+	if len(stuck) != 1 || stuck[0].Nonce() != 0 {
+		t.Fatalf("stuck transactions mismatch: have %v", stuck)
+	}
+	if feeCap, tip := pool.ReplacementFees(stuck[0]); tip.Cmp(big.NewInt(5)) != 0 || feeCap.Cmp(tip) < 0 {
+		t.Fatalf("replacement fees mismatch: have cap %v tip %v, want tip 5", feeCap, tip)
+	}
+	// Queues blocked by a gap without activity for the gap lifetime expire
+	pool.mu.Lock()
+	pool.expireGapped()
+	if _, queued := pool.stats(); queued != 3 {
+		t.Fatalf("queued transactions expired early: have %d, want 3", queued)
+	}
+	pool.beats[addr] = time.Now().Add(-2 * config.GapLifetime)
+	pool.expireGapped()
+	pending, queued := pool.stats()
+	pool.mu.Unlock()
+
+	if pending != 1 || queued != 0 {
+		t.Fatalf("pool mismatch after gap expiry: have %d pending %d queued, want 1 and 0", pending, queued)
+	}
+	for _, tx := range blocked {
+		if reason := pool.DropReason(tx.Hash()); reason != TxDropGapped {
+			t.Errorf("drop reason mismatch for nonce %d: have %q, want %q", tx.Nonce(), reason, TxDropGapped)
+		}
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sort"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// TxSource is the path a transaction entered the pool through.
+type TxSource string
+
+const (
+	TxSourceLocal  TxSource = "local"  // Submitted through the local APIs or sent by a local account
+	TxSourceRemote TxSource = "remote" // Received from peers or mirrored from the sequencer
+)
+
+// txStore persists all the pooled transactions in the database, along with the
+// time they were first seen and their source. Unlike the journal, it tracks the
+// pool as it changes, so that restarts restore the pool in the order it was
+// filled, and the stored transactions can be inspected after an incident.
+type txStore struct {
+	db ethdb.KeyValueStore
+}
+
+// newTxStore creates a transaction store persisting into the given database.
+func newTxStore(db ethdb.KeyValueStore) *txStore {
+	return &txStore{db: db}
+}
+
+// insert persists a transaction added to the pool.
+func (store *txStore) insert(tx *types.Transaction, local bool) {
+	source := TxSourceRemote
+	if local {
+		source = TxSourceLocal
+	}
+	rawdb.WritePooledTransaction(store.db, &types.PooledTransaction{
+		Tx:     tx,
+		Time:   uint64(tx.Time().UnixNano()),
+		Source: string(source),
+	})
+}
+
+// remove deletes a transaction dropped from the pool.
+func (store *txStore) remove(hash common.Hash) {
+	rawdb.DeletePooledTransaction(store.db, hash)
+}
+
+// load adds the persisted transactions into the pool in the order they were
+// first seen, restoring their original times. The transactions the pool does
+// not accept anymore are deleted.
+func (store *txStore) load(add func(txs []*types.Transaction, local bool) []error, known func(hash common.Hash) bool) {
+	stored := rawdb.ReadPooledTransactions(store.db)
+	sort.SliceStable(stored, func(i, j int) bool {
+		return stored[i].Time < stored[j].Time
+	})
+	var (
+		dropped int
+		batch   []*types.Transaction
+		local   bool
+	)
+	// Add the transactions in runs of the same source, as locals and remotes
+	// are added differently
+	flush := func() {
+		for i, err := range add(batch, local) {
+			if err != nil && !known(batch[i].Hash()) {
+				log.Debug("Failed to add stored transaction", "hash", batch[i].Hash(), "err", err)
+				store.remove(batch[i].Hash())
+				dropped++
+			}
+		}
+		batch = batch[:0]
+	}
+	for _, entry := range stored {
+		isLocal := TxSource(entry.Source) == TxSourceLocal
+		if len(batch) > 0 && (isLocal != local || len(batch) >= 1024) {
+			flush()
+		}
+		entry.Tx.SetTime(time.Unix(0, int64(entry.Time)))
+		batch, local = append(batch, entry.Tx), isLocal
+	}
+	if len(batch) > 0 {
+		flush()
+	}
+	log.Info("Loaded stored transactions", "transactions", len(stored), "dropped", dropped)
+}
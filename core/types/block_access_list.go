@@ -0,0 +1,43 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+// BlockAccessList is the complete set of accounts, storage slots and contract
+// code touched while executing all transactions of a block, sorted by address.
+type BlockAccessList []*AccountAccess
+
+// AccountAccess is the access record of a single account within a block.
+type AccountAccess struct {
+	Address     common.Address `json:"address"`
+	StorageKeys []common.Hash  `json:"storageKeys"`
+	// CodeHash is the keccak hash of the code of the account, only set if the
+	// code was loaded during execution.
+	CodeHash *common.Hash `json:"codeHash,omitempty" rlp:"nil"`
+}
+
+// StorageKeyCount returns the total number of storage slots in the list.
+func (al BlockAccessList) StorageKeyCount() int {
+	sum := 0
+	for _, acc := range al {
+		sum += len(acc.StorageKeys)
+	}
+	return sum
+}
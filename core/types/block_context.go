@@ -0,0 +1,44 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/binary"
+)
+
+// BlockContextLength is the length of an encoded block context.
+const BlockContextLength = 60
+
+// EncodeBlockContext encodes the context of a block in the layout consumed by
+// the prover circuit, all fields big endian:
+//
+//	number (8) || timestamp (8) || base fee (32) || gas limit (8) ||
+//	transaction count (2) || L1 message count (2)
+//
+// Blocks without a base fee encode it as zero.
+func EncodeBlockContext(header *Header, numTxs, numL1Messages uint16) []byte {
+	enc := make([]byte, BlockContextLength)
+	binary.BigEndian.PutUint64(enc[0:], header.Number.Uint64())
+	binary.BigEndian.PutUint64(enc[8:], header.Time)
+	if header.BaseFee != nil {
+		header.BaseFee.FillBytes(enc[16:48])
+	}
+	binary.BigEndian.PutUint64(enc[48:], header.GasLimit)
+	binary.BigEndian.PutUint16(enc[56:], numTxs)
+	binary.BigEndian.PutUint16(enc[58:], numL1Messages)
+	return enc
+}
@@ -0,0 +1,47 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+func TestEncodeBlockContext(t *testing.T) {
+	header := &Header{
+		Number:   big.NewInt(0x0102),
+		Time:     0x0304,
+		BaseFee:  big.NewInt(0x0506),
+		GasLimit: 0x0708,
+	}
+	want := common.FromHex("0000000000000102" + "0000000000000304" +
+		"0000000000000000000000000000000000000000000000000000000000000506" +
+		"0000000000000708" + "0009" + "0001")
+
+	if have := EncodeBlockContext(header, 9, 1); !bytes.Equal(have, want) {
+		t.Fatalf("block context mismatch:\nhave %x\nwant %x", have, want)
+	}
+	// Blocks without base fee encode it as zero
+	header.BaseFee = nil
+	copy(want[16:48], make([]byte, 32))
+	if have := EncodeBlockContext(header, 9, 1); !bytes.Equal(have, want) {
+		t.Fatalf("block context mismatch:\nhave %x\nwant %x", have, want)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+// BlockStats is a compact record of the resources spent importing a block, kept
+// for capacity planning and for tracking performance regressions over upgrades.
+//
+// Durations are in nanoseconds. StateRead is only measured if expensive metrics
+// are enabled and is part of Execution.
+type BlockStats struct {
+	Execution  uint64 // Time spent executing the transactions, state reads included
+	StateRead  uint64 // Time spent reading accounts and storage from snapshot or trie
+	Validation uint64 // Time spent validating the block, dominated by trie hashing
+	Commit     uint64 // Time spent committing the state into the trie database
+	Write      uint64 // Time spent writing the block and its state, commit included
+	WriteBytes uint64 // Size of the block data written into the database
+	TrieBytes  uint64 // Growth of the dirty trie nodes cached in memory
+
+	AccountHits   uint64 // Account lookups served by the state cache
+	AccountMisses uint64 // Account lookups loaded from snapshot or trie
+	StorageHits   uint64 // Storage lookups served by the state cache
+	StorageMisses uint64 // Storage lookups loaded from snapshot or trie
+}
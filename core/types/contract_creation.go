@@ -0,0 +1,34 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+// ContractCreation is the preimage of a contract address: the account creating
+// it along with its nonce for CREATE, or the salt and init code hash for
+// CREATE2, and the transaction executing the creation.
+type ContractCreation struct {
+	Address      common.Address
+	Creator      common.Address
+	Nonce        uint64       // Nonce of the creator, for CREATE
+	Salt         *common.Hash `rlp:"nil"` // Salt of CREATE2, nil for CREATE
+	InitCodeHash common.Hash
+	TxHash       common.Hash
+	BlockNumber  uint64
+}
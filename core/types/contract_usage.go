@@ -0,0 +1,31 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import "github.com/scroll-tech/go-ethereum/common"
+
+// ContractUsage is the gas consumed by and the state growth caused by a
+// contract within a block, kept to find out what drives the resource usage of
+// the chain.
+type ContractUsage struct {
+	Address      common.Address
+	Transactions uint64 // Transactions calling or creating the contract
+	GasUsed      uint64 // Gas used by the transactions calling or creating the contract
+	SlotsCreated uint64 // Storage slots set from zero
+	SlotsCleared uint64 // Storage slots reset to zero
+	CodeSize     uint64 // Size of the code deployed at the contract
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+const (
+	// ExtraVersion is the version of the structured extra-data layout.
+	ExtraVersion = 1
+
+	// ExtraLength is the number of leading extra-data bytes holding the
+	// structured layout. It fits both the maximum extra-data size of ethash and
+	// the vanity of clique.
+	ExtraLength = 32
+
+	// ExtraSeedLength is the number of leading transaction shuffle seed bytes
+	// committed in the structured layout.
+	ExtraSeedLength = ExtraLength - extraSeedOffset
+
+	extraVersionOffset  = 0 // Layout version
+	extraProposerOffset = 1 // Index of the proposer in the proposer rotation, 8 bytes
+	extraSeedOffset     = 9 // Leading bytes of the transaction shuffle seed, up to the end of the layout
+)
+
+var (
+	// ErrInvalidExtra is returned if the extra-data of a header doesn't follow
+	// the structured layout.
+	ErrInvalidExtra = errors.New("invalid structured extra-data")
+
+	// ErrUnsupportedExtraVersion is returned if the extra-data of a header uses
+	// a layout version this node doesn't know.
+	ErrUnsupportedExtraVersion = errors.New("unsupported extra-data version")
+)
+
+// L2Extra is the structured extra-data of L2 headers, replacing the opaque blob
+// each tool used to parse differently. It's encoded in the leading ExtraLength
+// bytes of the extra-data as a version byte, the big endian proposer index and
+// the leading bytes of the transaction shuffle seed.
+//
+// The full shuffle seed doesn't fit next to the other fields within the
+// extra-data size limit, so the layout only commits to its leading bytes.
+type L2Extra struct {
+	Version       uint8
+	ProposerIndex uint64                // Zero unless the proposer rotation is active
+	ShuffleSeed   [ExtraSeedLength]byte // Zero unless the transaction shuffle is active
+}
+
+// NewL2Extra creates empty structured extra-data of the current version.
+func NewL2Extra() *L2Extra {
+	return &L2Extra{Version: ExtraVersion}
+}
+
+// Encode returns the extra-data encoding of the fields.
+func (e *L2Extra) Encode() []byte {
+	extra := make([]byte, ExtraLength)
+	extra[extraVersionOffset] = e.Version
+	binary.BigEndian.PutUint64(extra[extraProposerOffset:], e.ProposerIndex)
+	copy(extra[extraSeedOffset:], e.ShuffleSeed[:])
+	return extra
+}
+
+// ExtraShuffleSeed returns the leading bytes of a transaction shuffle seed the
+// structured extra-data commits to.
+func ExtraShuffleSeed(seed common.Hash) [ExtraSeedLength]byte {
+	var committed [ExtraSeedLength]byte
+	copy(committed[:], seed[:])
+	return committed
+}
+
+// DecodeL2Extra decodes the structured layout from the leading bytes of the
+// given extra-data.
+func DecodeL2Extra(extra []byte) (*L2Extra, error) {
+	if len(extra) < ExtraLength {
+		return nil, fmt.Errorf("%w: length %d, want at least %d", ErrInvalidExtra, len(extra), ExtraLength)
+	}
+	if version := extra[extraVersionOffset]; version != ExtraVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedExtraVersion, version)
+	}
+	dec := &L2Extra{
+		Version:       extra[extraVersionOffset],
+		ProposerIndex: binary.BigEndian.Uint64(extra[extraProposerOffset:]),
+	}
+	copy(dec.ShuffleSeed[:], extra[extraSeedOffset:ExtraLength])
+	return dec, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+func TestL2Extra(t *testing.T) {
+	extra := &L2Extra{Version: ExtraVersion, ProposerIndex: 0x0102, ShuffleSeed: ExtraShuffleSeed(common.Hash{0x03, 0x04})}
+	enc := extra.Encode()
+	if want := append([]byte{ExtraVersion, 0, 0, 0, 0, 0, 0, 0x01, 0x02, 0x03, 0x04}, make([]byte, 21)...); !bytes.Equal(enc, want) {
+		t.Fatalf("encoding mismatch: have %x, want %x", enc, want)
+	}
+	// Trailing bytes, like clique signatures, are ignored
+	dec, err := DecodeL2Extra(append(enc, make([]byte, 65)...))
+	if err != nil {
+		t.Fatalf("failed to decode extra-data: %v", err)
+	}
+	if *dec != *extra {
+		t.Fatalf("decoded extra-data mismatch: have %+v, want %+v", dec, extra)
+	}
+	// Invalid layouts are rejected
+	tests := []struct {
+		name  string
+		extra []byte
+		err   error
+	}{
+		{"short", enc[:ExtraLength-1], ErrInvalidExtra},
+		{"version", append([]byte{ExtraVersion + 1}, enc[1:]...), ErrUnsupportedExtraVersion},
+	}
+	for _, tt := range tests {
+		if _, err := DecodeL2Extra(tt.extra); !errors.Is(err, tt.err) {
+			t.Errorf("%s: error mismatch: have %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
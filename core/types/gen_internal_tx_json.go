@@ -0,0 +1,74 @@
+// Code generated by github.com/fjl/gencodec. DO NOT EDIT.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+)
+
+var _ = (*internalTransactionMarshaling)(nil)
+
+// MarshalJSON marshals as JSON.
+func (i InternalTransaction) MarshalJSON() ([]byte, error) {
+	type InternalTransaction struct {
+		Type      string         `json:"type"`
+		From      common.Address `json:"from"`
+		To        common.Address `json:"to"`
+		Value     *hexutil.Big   `json:"value"`
+		InputHash common.Hash    `json:"inputHash"`
+		Depth     hexutil.Uint64 `json:"depth"`
+		Success   bool           `json:"success"`
+	}
+	var enc InternalTransaction
+	enc.Type = i.Type
+	enc.From = i.From
+	enc.To = i.To
+	enc.Value = (*hexutil.Big)(i.Value)
+	enc.InputHash = i.InputHash
+	enc.Depth = hexutil.Uint64(i.Depth)
+	enc.Success = i.Success
+	return json.Marshal(&enc)
+}
+
+// UnmarshalJSON unmarshals from JSON.
+func (i *InternalTransaction) UnmarshalJSON(input []byte) error {
+	type InternalTransaction struct {
+		Type      *string         `json:"type"`
+		From      *common.Address `json:"from"`
+		To        *common.Address `json:"to"`
+		Value     *hexutil.Big    `json:"value"`
+		InputHash *common.Hash    `json:"inputHash"`
+		Depth     *hexutil.Uint64 `json:"depth"`
+		Success   *bool           `json:"success"`
+	}
+	var dec InternalTransaction
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.Type != nil {
+		i.Type = *dec.Type
+	}
+	if dec.From != nil {
+		i.From = *dec.From
+	}
+	if dec.To != nil {
+		i.To = *dec.To
+	}
+	if dec.Value != nil {
+		i.Value = (*big.Int)(dec.Value)
+	}
+	if dec.InputHash != nil {
+		i.InputHash = *dec.InputHash
+	}
+	if dec.Depth != nil {
+		i.Depth = uint64(*dec.Depth)
+	}
+	if dec.Success != nil {
+		i.Success = *dec.Success
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+)
+
+//go:generate gencodec -type InternalTransaction -field-override internalTransactionMarshaling -out gen_internal_tx_json.go
+
+// InternalTransaction is a compact record of a single message call or contract
+// creation executed by a transaction, including the top level one.
+type InternalTransaction struct {
+	Type      string         `json:"type"`
+	From      common.Address `json:"from"`
+	To        common.Address `json:"to"`
+	Value     *big.Int       `json:"value"`
+	InputHash common.Hash    `json:"inputHash"`
+	Depth     uint64         `json:"depth"`
+	Success   bool           `json:"success"`
+}
+
+type internalTransactionMarshaling struct {
+	Value *hexutil.Big
+	Depth hexutil.Uint64
+}
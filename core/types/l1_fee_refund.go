@@ -0,0 +1,57 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/rlp"
+)
+
+// L1FeeRefund pays back the part of the L1 data fee a transaction was charged
+// in excess of its share of the compressed batch. Transactions are charged at
+// the uncompressed upper estimate during execution, the refunds are issued by a
+// system transaction once the batch is compressed.
+type L1FeeRefund struct {
+	TxHash    common.Hash    // Transaction charged the L1 data fee
+	Recipient common.Address // Sender of the transaction
+	Amount    *big.Int       // Part of the L1 data fee refunded
+}
+
+// L1FeeRefundRecord is the refund of a transaction as recorded on import of the
+// block issuing it.
+type L1FeeRefundRecord struct {
+	BlockHash   common.Hash // Block issuing the refund
+	BlockNumber uint64      // Number of the block issuing the refund
+	Amount      *big.Int    // Part of the L1 data fee refunded
+}
+
+// EncodeL1FeeRefunds returns the calldata of a system transaction issuing the
+// given refunds.
+func EncodeL1FeeRefunds(refunds []*L1FeeRefund) ([]byte, error) {
+	return rlp.EncodeToBytes(refunds)
+}
+
+// DecodeL1FeeRefunds decodes the refunds issued by a system transaction.
+func DecodeL1FeeRefunds(data []byte) ([]*L1FeeRefund, error) {
+	var refunds []*L1FeeRefund
+	if err := rlp.DecodeBytes(data, &refunds); err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
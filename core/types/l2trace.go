@@ -60,8 +60,9 @@ type ExecutionResult struct {
 	// currently they are just `from` and `to` account
 	AccountsAfter []*AccountWrapper `json:"accountAfter"`
 
-	// `PoseidonCodeHash` only exists when tx is a contract call.
+	// `PoseidonCodeHash` and `KeccakCodeHash` only exist when tx is a contract call.
 	PoseidonCodeHash *common.Hash `json:"poseidonCodeHash,omitempty"`
+	KeccakCodeHash   *common.Hash `json:"keccakCodeHash,omitempty"`
 	// If it is a contract call, the contract code is returned.
 	ByteCode   string          `json:"byteCode,omitempty"`
 	StructLogs []*StructLogRes `json:"structLogs"`
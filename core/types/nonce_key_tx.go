@@ -0,0 +1,122 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+// NonceKeyTxType is the type of the transactions using the nonce of a nonce key
+// of their sender instead of the account nonce.
+const NonceKeyTxType = 0x7b
+
+// NonceKeyTx is a dynamic fee transaction ordered by the nonce of one of the
+// independent nonce keys of its sender, allowing an account to submit parallel
+// streams of transactions. The nonce key 0 is the account nonce itself, other
+// keys start at nonce 0 and leave the account nonce untouched.
+type NonceKeyTx struct {
+	ChainID    *big.Int
+	NonceKey   uint64
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         *common.Address `rlp:"nil"` // nil means contract creation
+	Value      *big.Int
+	Data       []byte
+	AccessList AccessList
+
+	// Signature values
+	V *big.Int `json:"v" gencodec:"required"`
+	R *big.Int `json:"r" gencodec:"required"`
+	S *big.Int `json:"s" gencodec:"required"`
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *NonceKeyTx) copy() TxData {
+	cpy := &NonceKeyTx{
+		NonceKey: tx.NonceKey,
+		Nonce:    tx.Nonce,
+		To:       copyAddressPtr(tx.To),
+		Data:     common.CopyBytes(tx.Data),
+		Gas:      tx.Gas,
+		// These are copied below.
+		AccessList: make(AccessList, len(tx.AccessList)),
+		Value:      new(big.Int),
+		ChainID:    new(big.Int),
+		GasTipCap:  new(big.Int),
+		GasFeeCap:  new(big.Int),
+		V:          new(big.Int),
+		R:          new(big.Int),
+		S:          new(big.Int),
+	}
+	copy(cpy.AccessList, tx.AccessList)
+	if tx.Value != nil {
+		cpy.Value.Set(tx.Value)
+	}
+	if tx.ChainID != nil {
+		cpy.ChainID.Set(tx.ChainID)
+	}
+	if tx.GasTipCap != nil {
+		cpy.GasTipCap.Set(tx.GasTipCap)
+	}
+	if tx.GasFeeCap != nil {
+		cpy.GasFeeCap.Set(tx.GasFeeCap)
+	}
+	if tx.V != nil {
+		cpy.V.Set(tx.V)
+	}
+	if tx.R != nil {
+		cpy.R.Set(tx.R)
+	}
+	if tx.S != nil {
+		cpy.S.Set(tx.S)
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+func (tx *NonceKeyTx) txType() byte           { return NonceKeyTxType }
+func (tx *NonceKeyTx) chainID() *big.Int      { return tx.ChainID }
+func (tx *NonceKeyTx) accessList() AccessList { return tx.AccessList }
+func (tx *NonceKeyTx) data() []byte           { return tx.Data }
+func (tx *NonceKeyTx) gas() uint64            { return tx.Gas }
+func (tx *NonceKeyTx) gasFeeCap() *big.Int    { return tx.GasFeeCap }
+func (tx *NonceKeyTx) gasTipCap() *big.Int    { return tx.GasTipCap }
+func (tx *NonceKeyTx) gasPrice() *big.Int     { return tx.GasFeeCap }
+func (tx *NonceKeyTx) value() *big.Int        { return tx.Value }
+func (tx *NonceKeyTx) nonce() uint64          { return tx.Nonce }
+func (tx *NonceKeyTx) to() *common.Address    { return tx.To }
+
+func (tx *NonceKeyTx) rawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *NonceKeyTx) setSignatureValues(chainID, v, r, s *big.Int) {
+	tx.ChainID, tx.V, tx.R, tx.S = chainID, v, r, s
+}
+
+// NonceKey returns the nonce key whose nonce the transaction uses, 0 being the
+// account nonce.
+func (tx *Transaction) NonceKey() uint64 {
+	if inner, ok := tx.inner.(*NonceKeyTx); ok {
+		return inner.NonceKey
+	}
+	return 0
+}
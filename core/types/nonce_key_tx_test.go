@@ -0,0 +1,77 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// Tests that nonce key transactions survive the encoding roundtrips and that the
+// nonce key is signed over.
+func TestNonceKeyTx(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+		signer = LatestSigner(params.TestChainConfig)
+	)
+	tx := MustSignNewTx(key, signer, &NonceKeyTx{
+		ChainID:   params.TestChainConfig.ChainID,
+		NonceKey:  7,
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(10),
+		Gas:       params.TxGas,
+		To:        &common.Address{0xaa},
+		Value:     big.NewInt(5),
+	})
+	if from, err := Sender(signer, tx); err != nil || from != sender {
+		t.Fatalf("sender mismatch: have %x (%v), want %x", from, err, sender)
+	}
+	blob, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	var dec Transaction
+	if err := dec.UnmarshalBinary(blob); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if dec.Hash() != tx.Hash() || dec.NonceKey() != 7 {
+		t.Fatalf("binary roundtrip mismatch: have %x (key %d), want %x", dec.Hash(), dec.NonceKey(), tx.Hash())
+	}
+	blob, err = json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if err := json.Unmarshal(blob, &dec); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if dec.Hash() != tx.Hash() || dec.NonceKey() != 7 {
+		t.Fatalf("json roundtrip mismatch: have %x (key %d), want %x", dec.Hash(), dec.NonceKey(), tx.Hash())
+	}
+	// Moving the transaction to another key invalidates the signature
+	moved := tx.inner.copy().(*NonceKeyTx)
+	moved.NonceKey = 8
+	if from, err := Sender(signer, NewTx(moved)); err == nil && from == sender {
+		t.Fatalf("signature valid for another nonce key")
+	}
+}
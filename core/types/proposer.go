@@ -0,0 +1,47 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ProposerIndexLength is the number of leading extra-data bytes used to commit
+// to the index of the proposer of a block in the proposer rotation.
+const ProposerIndexLength = 8
+
+// ErrMissingProposerIndex is returned if a block proposed in rotation does not
+// commit to the index of its proposer in the extra-data.
+var ErrMissingProposerIndex = errors.New("missing proposer index")
+
+// ProposerIndexFromExtra returns the proposer index committed in the extra-data
+// of a header.
+func ProposerIndexFromExtra(extra []byte) (uint64, error) {
+	if len(extra) < ProposerIndexLength {
+		return 0, ErrMissingProposerIndex
+	}
+	return binary.BigEndian.Uint64(extra[:ProposerIndexLength]), nil
+}
+
+// ProposerIndexExtra returns the extra-data committing to the given proposer
+// index.
+func ProposerIndexExtra(index uint64) []byte {
+	extra := make([]byte, ProposerIndexLength)
+	binary.BigEndian.PutUint64(extra, index)
+	return extra
+}
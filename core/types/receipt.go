@@ -75,7 +75,8 @@ type Receipt struct {
 	ReturnValue []byte `json:"returnValue,omitempty"`
 
 	// Scroll rollup
-	L1Fee *big.Int `json:"l1Fee,omitempty"`
+	L1Fee    *big.Int        `json:"l1Fee,omitempty"`
+	FeePayer *common.Address `json:"feePayer,omitempty"` // Sponsor which paid the fees, if any
 }
 
 type receiptMarshaling struct {
@@ -207,7 +208,7 @@ func (r *Receipt) DecodeRLP(s *rlp.Stream) error {
 			return errEmptyTypedReceipt
 		}
 		r.Type = b[0]
-		if r.Type == AccessListTxType || r.Type == DynamicFeeTxType {
+		if r.Type == AccessListTxType || r.Type == DynamicFeeTxType || r.Type == SystemTxType || r.Type == SponsoredTxType || r.Type == NonceKeyTxType || r.Type == SetCodeTxType {
 			var dec receiptRLP
 			if err := rlp.DecodeBytes(b[1:], &dec); err != nil {
 				return err
@@ -243,7 +244,7 @@ func (r *Receipt) decodeTyped(b []byte) error {
 		return errEmptyTypedReceipt
 	}
 	switch b[0] {
-	case DynamicFeeTxType, AccessListTxType:
+	case DynamicFeeTxType, AccessListTxType, SystemTxType, SponsoredTxType, NonceKeyTxType, SetCodeTxType:
 		var data receiptRLP
 		err := rlp.DecodeBytes(b[1:], &data)
 		if err != nil {
@@ -435,6 +436,18 @@ func (rs Receipts) EncodeIndex(i int, w *bytes.Buffer) {
 	case DynamicFeeTxType:
 		w.WriteByte(DynamicFeeTxType)
 		rlp.Encode(w, data)
+	case SystemTxType:
+		w.WriteByte(SystemTxType)
+		rlp.Encode(w, data)
+	case SponsoredTxType:
+		w.WriteByte(SponsoredTxType)
+		rlp.Encode(w, data)
+	case NonceKeyTxType:
+		w.WriteByte(NonceKeyTxType)
+		rlp.Encode(w, data)
+	case SetCodeTxType:
+		w.WriteByte(SetCodeTxType)
+		rlp.Encode(w, data)
 	default:
 		// For unsupported types, write nothing. Since this is for
 		// DeriveSha, the error will be caught matching the derived hash
@@ -455,6 +468,7 @@ func (rs Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, nu
 		// The transaction type and hash can be retrieved from the transaction itself
 		rs[i].Type = txs[i].Type()
 		rs[i].TxHash = txs[i].Hash()
+		rs[i].FeePayer = txs[i].FeePayer()
 
 		// block location fields
 		rs[i].BlockHash = hash
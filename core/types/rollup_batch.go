@@ -0,0 +1,30 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import "github.com/scroll-tech/go-ethereum/common"
+
+// RollupBatch is a batch of L2 blocks finalized on L1, as recorded while
+// following the rollup contract. The L1 block is kept to detect L1 reorgs.
+type RollupBatch struct {
+	Index        uint64      // Index of the batch in the rollup contract
+	StateRoot    common.Hash // State root the batch was finalized with
+	WithdrawRoot common.Hash // Withdraw trie root the batch was finalized with
+	L2Block      uint64      // Number of the last L2 block of the batch
+	L1Block      uint64      // Number of the L1 block finalizing the batch
+	L1Hash       common.Hash // Hash of the L1 block finalizing the batch
+}
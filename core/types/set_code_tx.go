@@ -0,0 +1,222 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+// SetCodeTxType is the type of the transactions setting the code of accounts to
+// a delegation to another account (EIP-7702).
+const SetCodeTxType = 0x04
+
+// setCodeAuthMagic prefixes the hash signed by the authority of an authorization.
+const setCodeAuthMagic = 0x05
+
+// DelegationPrefix prefixes the address an account delegates its code to in the
+// code of the account.
+var DelegationPrefix = []byte{0xef, 0x01, 0x00}
+
+// ErrInvalidAuthorization is returned if the signature of an authorization of a
+// set-code transaction is invalid.
+var ErrInvalidAuthorization = errors.New("invalid authorization signature")
+
+// SetCodeTx is a dynamic fee transaction carrying a list of authorizations, each
+// setting the code of its signer, the authority, to a delegation to the given
+// address. Calls to the authority then execute the code of that address in the
+// context of the authority.
+type SetCodeTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         common.Address
+	Value      *big.Int
+	Data       []byte
+	AccessList AccessList
+	AuthList   []SetCodeAuthorization
+
+	// Signature values
+	V *big.Int `json:"v" gencodec:"required"`
+	R *big.Int `json:"r" gencodec:"required"`
+	S *big.Int `json:"s" gencodec:"required"`
+}
+
+// SetCodeAuthorization is an authorization from an account to set its code to a
+// delegation to the given address. The chain ID 0 authorizes on any chain.
+type SetCodeAuthorization struct {
+	ChainID *big.Int
+	Address common.Address
+	Nonce   uint64
+	V       uint8
+	R       *big.Int
+	S       *big.Int
+}
+
+// setCodeAuthorizationJSON is the JSON representation of an authorization.
+type setCodeAuthorizationJSON struct {
+	ChainID *hexutil.Big   `json:"chainId"`
+	Address common.Address `json:"address"`
+	Nonce   hexutil.Uint64 `json:"nonce"`
+	V       hexutil.Uint64 `json:"yParity"`
+	R       *hexutil.Big   `json:"r"`
+	S       *hexutil.Big   `json:"s"`
+}
+
+// MarshalJSON marshals as JSON.
+func (a SetCodeAuthorization) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&setCodeAuthorizationJSON{
+		ChainID: (*hexutil.Big)(a.ChainID),
+		Address: a.Address,
+		Nonce:   hexutil.Uint64(a.Nonce),
+		V:       hexutil.Uint64(a.V),
+		R:       (*hexutil.Big)(a.R),
+		S:       (*hexutil.Big)(a.S),
+	})
+}
+
+// UnmarshalJSON unmarshals from JSON.
+func (a *SetCodeAuthorization) UnmarshalJSON(input []byte) error {
+	var dec setCodeAuthorizationJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.ChainID == nil || dec.R == nil || dec.S == nil {
+		return errors.New("missing required field in authorization")
+	}
+	if dec.V > 1 {
+		return errors.New("invalid yParity in authorization")
+	}
+	a.ChainID, a.Address, a.Nonce = (*big.Int)(dec.ChainID), dec.Address, uint64(dec.Nonce)
+	a.V, a.R, a.S = uint8(dec.V), (*big.Int)(dec.R), (*big.Int)(dec.S)
+	return nil
+}
+
+// SigHash returns the hash signed by the authority of the authorization.
+func (a *SetCodeAuthorization) SigHash() common.Hash {
+	return prefixedRlpHash(setCodeAuthMagic, []interface{}{a.ChainID, a.Address, a.Nonce})
+}
+
+// Authority returns the account recovered from the signature of the
+// authorization, whose code it sets.
+func (a *SetCodeAuthorization) Authority() (common.Address, error) {
+	if a.ChainID == nil || a.R == nil || a.S == nil || a.V > 1 {
+		return common.Address{}, ErrInvalidAuthorization
+	}
+	v := new(big.Int).SetUint64(uint64(a.V) + 27)
+	authority, err := recoverPlain(a.SigHash(), a.R, a.S, v, true)
+	if err != nil {
+		return common.Address{}, ErrInvalidAuthorization
+	}
+	return authority, nil
+}
+
+// SignSetCode signs an authorization with the private key of its authority.
+func SignSetCode(prv *ecdsa.PrivateKey, auth SetCodeAuthorization) (SetCodeAuthorization, error) {
+	h := auth.SigHash()
+	sig, err := crypto.Sign(h[:], prv)
+	if err != nil {
+		return SetCodeAuthorization{}, err
+	}
+	auth.R, auth.S, _ = decodeSignature(sig)
+	auth.V = sig[64]
+	return auth, nil
+}
+
+// AddressToDelegation returns the code of an account delegating to addr.
+func AddressToDelegation(addr common.Address) []byte {
+	return append(common.CopyBytes(DelegationPrefix), addr.Bytes()...)
+}
+
+// ParseDelegation returns the address the given account code delegates to, if
+// it's a delegation.
+func ParseDelegation(code []byte) (common.Address, bool) {
+	if len(code) != len(DelegationPrefix)+common.AddressLength || !bytes.HasPrefix(code, DelegationPrefix) {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(code[len(DelegationPrefix):]), true
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *SetCodeTx) copy() TxData {
+	cpy := &SetCodeTx{
+		Nonce: tx.Nonce,
+		To:    tx.To,
+		Data:  common.CopyBytes(tx.Data),
+		Gas:   tx.Gas,
+		// These are copied below.
+		AccessList: make(AccessList, len(tx.AccessList)),
+		AuthList:   make([]SetCodeAuthorization, len(tx.AuthList)),
+		Value:      new(big.Int),
+		ChainID:    new(big.Int),
+		GasTipCap:  new(big.Int),
+		GasFeeCap:  new(big.Int),
+		V:          new(big.Int),
+		R:          new(big.Int),
+		S:          new(big.Int),
+	}
+	copy(cpy.AccessList, tx.AccessList)
+	copy(cpy.AuthList, tx.AuthList)
+	for _, v := range []struct{ dst, src *big.Int }{
+		{cpy.Value, tx.Value}, {cpy.ChainID, tx.ChainID}, {cpy.GasTipCap, tx.GasTipCap}, {cpy.GasFeeCap, tx.GasFeeCap},
+		{cpy.V, tx.V}, {cpy.R, tx.R}, {cpy.S, tx.S},
+	} {
+		if v.src != nil {
+			v.dst.Set(v.src)
+		}
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+func (tx *SetCodeTx) txType() byte           { return SetCodeTxType }
+func (tx *SetCodeTx) chainID() *big.Int      { return tx.ChainID }
+func (tx *SetCodeTx) accessList() AccessList { return tx.AccessList }
+func (tx *SetCodeTx) data() []byte           { return tx.Data }
+func (tx *SetCodeTx) gas() uint64            { return tx.Gas }
+func (tx *SetCodeTx) gasFeeCap() *big.Int    { return tx.GasFeeCap }
+func (tx *SetCodeTx) gasTipCap() *big.Int    { return tx.GasTipCap }
+func (tx *SetCodeTx) gasPrice() *big.Int     { return tx.GasFeeCap }
+func (tx *SetCodeTx) value() *big.Int        { return tx.Value }
+func (tx *SetCodeTx) nonce() uint64          { return tx.Nonce }
+func (tx *SetCodeTx) to() *common.Address    { to := tx.To; return &to }
+
+func (tx *SetCodeTx) rawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *SetCodeTx) setSignatureValues(chainID, v, r, s *big.Int) {
+	tx.ChainID, tx.V, tx.R, tx.S = chainID, v, r, s
+}
+
+// SetCodeAuthorizations returns the authorizations of a set-code transaction, or
+// nil for other transactions.
+func (tx *Transaction) SetCodeAuthorizations() []SetCodeAuthorization {
+	if inner, ok := tx.inner.(*SetCodeTx); ok {
+		return inner.AuthList
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// Tests that set-code transactions survive the encoding roundtrips, with the
+// authorities recoverable from their authorizations.
+func TestSetCodeTx(t *testing.T) {
+	var (
+		key, _     = crypto.GenerateKey()
+		authKey, _ = crypto.GenerateKey()
+		sender     = crypto.PubkeyToAddress(key.PublicKey)
+		authority  = crypto.PubkeyToAddress(authKey.PublicKey)
+		signer     = LatestSigner(params.TestChainConfig)
+	)
+	auth, err := SignSetCode(authKey, SetCodeAuthorization{ChainID: big.NewInt(0), Address: common.Address{0xaa}, Nonce: 3})
+	if err != nil {
+		t.Fatalf("failed to sign authorization: %v", err)
+	}
+	if have, err := auth.Authority(); err != nil || have != authority {
+		t.Fatalf("authority mismatch: have %x (%v), want %x", have, err, authority)
+	}
+	tx := MustSignNewTx(key, signer, &SetCodeTx{
+		ChainID:   params.TestChainConfig.ChainID,
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(10),
+		Gas:       params.TxGas,
+		To:        authority,
+		Value:     big.NewInt(5),
+		AuthList:  []SetCodeAuthorization{auth},
+	})
+	if from, err := Sender(signer, tx); err != nil || from != sender {
+		t.Fatalf("sender mismatch: have %x (%v), want %x", from, err, sender)
+	}
+	blob, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	var dec Transaction
+	if err := dec.UnmarshalBinary(blob); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Fatalf("binary roundtrip mismatch: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	blob, err = json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if err := json.Unmarshal(blob, &dec); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Fatalf("json roundtrip mismatch: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	if have, err := dec.SetCodeAuthorizations()[0].Authority(); err != nil || have != authority {
+		t.Fatalf("decoded authority mismatch: have %x (%v), want %x", have, err, authority)
+	}
+	// Tampering with an authorization changes its authority
+	forged := auth
+	forged.Nonce++
+	if have, _ := forged.Authority(); have == authority {
+		t.Fatalf("authority recovered from a tampered authorization")
+	}
+	// Delegations are recognized by their exact encoding only
+	code := AddressToDelegation(common.Address{0xaa})
+	if target, ok := ParseDelegation(code); !ok || target != (common.Address{0xaa}) {
+		t.Fatalf("delegation mismatch: have %x (%v)", target, ok)
+	}
+	if _, ok := ParseDelegation(append(code, 0x00)); ok {
+		t.Fatalf("oversized code parsed as delegation")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+// SponsoredTxType is the type of the transactions whose fees are paid by a
+// sponsor instead of their sender.
+const SponsoredTxType = 0x7c
+
+// ErrInvalidFeePayer is returned if the fee payer signature of a sponsored
+// transaction is invalid or doesn't belong to the fee payer the sender signed
+// for.
+var ErrInvalidFeePayer = errors.New("invalid fee payer")
+
+// SponsoredTx is a dynamic fee transaction whose fees are paid by a separate fee
+// payer. The sender signs the transaction including the fee payer it agrees to
+// be sponsored by, then the fee payer countersigns the transaction for this
+// very sender. The fee payer is charged gas * price plus the L1 fee, while the
+// sender only pays the value transferred.
+type SponsoredTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         *common.Address `rlp:"nil"` // nil means contract creation
+	Value      *big.Int
+	Data       []byte
+	AccessList AccessList
+	FeePayer   common.Address
+
+	// Signature values of the sender
+	V *big.Int `json:"v" gencodec:"required"`
+	R *big.Int `json:"r" gencodec:"required"`
+	S *big.Int `json:"s" gencodec:"required"`
+
+	// Signature values of the fee payer
+	FeePayerV *big.Int `json:"feePayerV" gencodec:"required"`
+	FeePayerR *big.Int `json:"feePayerR" gencodec:"required"`
+	FeePayerS *big.Int `json:"feePayerS" gencodec:"required"`
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *SponsoredTx) copy() TxData {
+	cpy := &SponsoredTx{
+		Nonce:    tx.Nonce,
+		To:       copyAddressPtr(tx.To),
+		Data:     common.CopyBytes(tx.Data),
+		Gas:      tx.Gas,
+		FeePayer: tx.FeePayer,
+		// These are copied below.
+		AccessList: make(AccessList, len(tx.AccessList)),
+		Value:      new(big.Int),
+		ChainID:    new(big.Int),
+		GasTipCap:  new(big.Int),
+		GasFeeCap:  new(big.Int),
+		V:          new(big.Int),
+		R:          new(big.Int),
+		S:          new(big.Int),
+		FeePayerV:  new(big.Int),
+		FeePayerR:  new(big.Int),
+		FeePayerS:  new(big.Int),
+	}
+	copy(cpy.AccessList, tx.AccessList)
+	for _, v := range []struct{ dst, src *big.Int }{
+		{cpy.Value, tx.Value}, {cpy.ChainID, tx.ChainID}, {cpy.GasTipCap, tx.GasTipCap}, {cpy.GasFeeCap, tx.GasFeeCap},
+		{cpy.V, tx.V}, {cpy.R, tx.R}, {cpy.S, tx.S},
+		{cpy.FeePayerV, tx.FeePayerV}, {cpy.FeePayerR, tx.FeePayerR}, {cpy.FeePayerS, tx.FeePayerS},
+	} {
+		if v.src != nil {
+			v.dst.Set(v.src)
+		}
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+func (tx *SponsoredTx) txType() byte           { return SponsoredTxType }
+func (tx *SponsoredTx) chainID() *big.Int      { return tx.ChainID }
+func (tx *SponsoredTx) accessList() AccessList { return tx.AccessList }
+func (tx *SponsoredTx) data() []byte           { return tx.Data }
+func (tx *SponsoredTx) gas() uint64            { return tx.Gas }
+func (tx *SponsoredTx) gasFeeCap() *big.Int    { return tx.GasFeeCap }
+func (tx *SponsoredTx) gasTipCap() *big.Int    { return tx.GasTipCap }
+func (tx *SponsoredTx) gasPrice() *big.Int     { return tx.GasFeeCap }
+func (tx *SponsoredTx) value() *big.Int        { return tx.Value }
+func (tx *SponsoredTx) nonce() uint64          { return tx.Nonce }
+func (tx *SponsoredTx) to() *common.Address    { return tx.To }
+
+func (tx *SponsoredTx) rawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *SponsoredTx) setSignatureValues(chainID, v, r, s *big.Int) {
+	tx.ChainID, tx.V, tx.R, tx.S = chainID, v, r, s
+}
+
+// FeePayer returns the fee payer the sender of a sponsored transaction signed
+// for, or nil if the transaction isn't sponsored. Use the FeePayer function to
+// also verify the fee payer signature.
+func (tx *Transaction) FeePayer() *common.Address {
+	if inner, ok := tx.inner.(*SponsoredTx); ok {
+		return &inner.FeePayer
+	}
+	return nil
+}
+
+// SenderCost returns the amount charged to the sender of the transaction, that
+// is value for sponsored transactions and gas * gasPrice + value otherwise.
+func (tx *Transaction) SenderCost() *big.Int {
+	if tx.Type() == SponsoredTxType {
+		return new(big.Int).Set(tx.Value())
+	}
+	return tx.Cost()
+}
+
+// FeePayerHash returns the hash to be signed by the fee payer of a sponsored
+// transaction sent by the given sender.
+func FeePayerHash(signer Signer, tx *Transaction, sender common.Address) common.Hash {
+	return prefixedRlpHash(
+		SponsoredTxType,
+		[]interface{}{
+			signer.ChainID(),
+			tx.Nonce(),
+			tx.GasTipCap(),
+			tx.GasFeeCap(),
+			tx.Gas(),
+			tx.To(),
+			tx.Value(),
+			tx.Data(),
+			tx.AccessList(),
+			tx.FeePayer(),
+			sender,
+		})
+}
+
+// FeePayer returns the address recovered from the fee payer signature of a
+// sponsored transaction, checking that it's the fee payer the sender signed for.
+func FeePayer(signer Signer, tx *Transaction) (common.Address, error) {
+	inner, ok := tx.inner.(*SponsoredTx)
+	if !ok {
+		return common.Address{}, ErrTxTypeNotSupported
+	}
+	sender, err := Sender(signer, tx)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if inner.FeePayerV == nil || inner.FeePayerR == nil || inner.FeePayerS == nil {
+		return common.Address{}, ErrInvalidFeePayer
+	}
+	// Like dynamic fee transactions, the recovery id is 0 or 1
+	v := new(big.Int).Add(inner.FeePayerV, big.NewInt(27))
+	payer, err := recoverPlain(FeePayerHash(signer, tx, sender), inner.FeePayerR, inner.FeePayerS, v, true)
+	if err != nil || payer != inner.FeePayer {
+		return common.Address{}, ErrInvalidFeePayer
+	}
+	return payer, nil
+}
+
+// SignFeePayer countersigns a sponsored transaction signed by its sender with
+// the private key of its fee payer.
+func SignFeePayer(tx *Transaction, signer Signer, prv *ecdsa.PrivateKey) (*Transaction, error) {
+	inner, ok := tx.inner.(*SponsoredTx)
+	if !ok {
+		return nil, ErrTxTypeNotSupported
+	}
+	if crypto.PubkeyToAddress(prv.PublicKey) != inner.FeePayer {
+		return nil, ErrInvalidFeePayer
+	}
+	sender, err := Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	h := FeePayerHash(signer, tx, sender)
+	sig, err := crypto.Sign(h[:], prv)
+	if err != nil {
+		return nil, err
+	}
+	cpy := inner.copy().(*SponsoredTx)
+	cpy.FeePayerR, cpy.FeePayerS, _ = decodeSignature(sig)
+	cpy.FeePayerV = big.NewInt(int64(sig[64]))
+	return &Transaction{inner: cpy, time: tx.time}, nil
+}
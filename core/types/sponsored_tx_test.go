@@ -0,0 +1,113 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// Tests that sponsored transactions survive the encoding roundtrips and that the
+// fee payer signature binds the fee payer to both the transaction and its sender.
+func TestSponsoredTx(t *testing.T) {
+	var (
+		senderKey, _ = crypto.GenerateKey()
+		payerKey, _  = crypto.GenerateKey()
+		sender       = crypto.PubkeyToAddress(senderKey.PublicKey)
+		payer        = crypto.PubkeyToAddress(payerKey.PublicKey)
+		signer       = LatestSigner(params.TestChainConfig)
+	)
+	unsigned := &SponsoredTx{
+		ChainID:   params.TestChainConfig.ChainID,
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(10),
+		Gas:       params.TxGas,
+		To:        &common.Address{0xaa},
+		Value:     big.NewInt(5),
+		FeePayer:  payer,
+	}
+	tx := MustSignNewTx(senderKey, signer, unsigned)
+	if _, err := FeePayer(signer, tx); err != ErrInvalidFeePayer {
+		t.Fatalf("missing fee payer signature: have %v, want %v", err, ErrInvalidFeePayer)
+	}
+	if _, err := SignFeePayer(tx, signer, senderKey); err != ErrInvalidFeePayer {
+		t.Fatalf("signing by another fee payer: have %v, want %v", err, ErrInvalidFeePayer)
+	}
+	tx, err := SignFeePayer(tx, signer, payerKey)
+	if err != nil {
+		t.Fatalf("failed to sign as fee payer: %v", err)
+	}
+	if from, err := Sender(signer, tx); err != nil || from != sender {
+		t.Fatalf("sender mismatch: have %x (%v), want %x", from, err, sender)
+	}
+	if have, err := FeePayer(signer, tx); err != nil || have != payer {
+		t.Fatalf("fee payer mismatch: have %x (%v), want %x", have, err, payer)
+	}
+	if cost := tx.SenderCost(); cost.Cmp(tx.Value()) != 0 {
+		t.Fatalf("sender cost mismatch: have %v, want %v", cost, tx.Value())
+	}
+	// Roundtrip the transaction through both encodings
+	blob, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	var dec Transaction
+	if err := dec.UnmarshalBinary(blob); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if dec.Hash() != tx.Hash() || *dec.FeePayer() != payer {
+		t.Fatalf("binary roundtrip mismatch: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	blob, err = json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if err := json.Unmarshal(blob, &dec); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Fatalf("json roundtrip mismatch: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	// A fee payer signature can't be reused by another sender, nor for another
+	// fee payer the sender signed for
+	other, _ := crypto.GenerateKey()
+	forged := MustSignNewTx(other, signer, unsigned)
+	inner, signed := forged.inner.(*SponsoredTx), tx.inner.(*SponsoredTx)
+	inner.FeePayerV, inner.FeePayerR, inner.FeePayerS = signed.FeePayerV, signed.FeePayerR, signed.FeePayerS
+	if _, err := FeePayer(signer, forged); err != ErrInvalidFeePayer {
+		t.Fatalf("reused fee payer signature: have %v, want %v", err, ErrInvalidFeePayer)
+	}
+	redirected := tx.inner.copy().(*SponsoredTx)
+	redirected.FeePayer = sender
+	if _, err := FeePayer(signer, NewTx(redirected)); err != ErrInvalidFeePayer {
+		t.Fatalf("redirected fee payer: have %v, want %v", err, ErrInvalidFeePayer)
+	}
+	// Unsponsored transactions have no fee payer
+	legacy := MustSignNewTx(senderKey, signer, &LegacyTx{Gas: params.TxGas, GasPrice: big.NewInt(1), To: &common.Address{}})
+	if legacy.FeePayer() != nil {
+		t.Fatalf("legacy transaction with fee payer %x", *legacy.FeePayer())
+	}
+	if _, err := FeePayer(signer, legacy); err != ErrTxTypeNotSupported {
+		t.Fatalf("legacy fee payer error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import "github.com/scroll-tech/go-ethereum/common"
+
+// StateMigration is the progress of the migration of the state from MPT to
+// zktrie ahead of the zktrie cutover. The state of a first block is converted
+// account by account, then the state diff of every following block is applied,
+// keeping the zktrie state in step with the head of the chain.
+type StateMigration struct {
+	Number uint64      // Block whose state is migrated
+	Hash   common.Hash // Hash of the block whose state is migrated
+	Root   common.Hash // MPT state root of the block
+	ZkRoot common.Hash // Zktrie root of the state migrated so far
+
+	Converted bool   // Whether the state of the block is fully converted
+	Marker    []byte // Last account hash converted while the conversion is in progress
+	Accounts  uint64 // Accounts converted by the initial conversion
+	Slots     uint64 // Storage slots converted by the initial conversion
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+// StateSize counts the accounts, storage slots and code bytes added to and
+// removed from the state since a base block, kept for capacity planning and to
+// inform state rent and expiry decisions. If the base is the genesis block, the
+// genesis state is counted as added, making the counts cover the whole state.
+type StateSize struct {
+	Base uint64 // First block counted (0 = the whole state is counted)
+
+	AccountsCreated  uint64 // Accounts created
+	AccountsDeleted  uint64 // Accounts deleted
+	SlotsCreated     uint64 // Storage slots set from zero
+	SlotsCleared     uint64 // Storage slots reset to zero
+	CodeBytesAdded   uint64 // Bytes of code deployed
+	CodeBytesRemoved uint64 // Bytes of code of deleted accounts
+}
+
+// Add adds the counts of another state size, keeping the base.
+func (s *StateSize) Add(other *StateSize) {
+	s.AccountsCreated += other.AccountsCreated
+	s.AccountsDeleted += other.AccountsDeleted
+	s.SlotsCreated += other.SlotsCreated
+	s.SlotsCleared += other.SlotsCleared
+	s.CodeBytesAdded += other.CodeBytesAdded
+	s.CodeBytesRemoved += other.CodeBytesRemoved
+}
+
+// Accounts returns the net number of accounts added.
+func (s *StateSize) Accounts() int64 {
+	return int64(s.AccountsCreated) - int64(s.AccountsDeleted)
+}
+
+// Slots returns the net number of storage slots added.
+func (s *StateSize) Slots() int64 {
+	return int64(s.SlotsCreated) - int64(s.SlotsCleared)
+}
+
+// CodeBytes returns the net number of code bytes added.
+func (s *StateSize) CodeBytes() int64 {
+	return int64(s.CodeBytesAdded) - int64(s.CodeBytesRemoved)
+}
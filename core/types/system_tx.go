@@ -0,0 +1,143 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// SystemTxType is the type of the system transactions inserted by the sequencer.
+const SystemTxType = 0x7d
+
+// SystemAddress is the fixed caller of all system transactions. No private key
+// is known for it, so it can't originate any other transaction.
+var SystemAddress = common.HexToAddress("0xfffffffffffffffffffffffffffffffffffffffe")
+
+var (
+	// ErrSystemTxPosition is returned if a system transaction doesn't precede
+	// all other transactions of its block.
+	ErrSystemTxPosition = errors.New("system transaction not at the top of the block")
+
+	// ErrSystemTxNonce is returned if the nonce of a system transaction doesn't
+	// match the number of its block.
+	ErrSystemTxNonce = errors.New("system transaction nonce mismatch")
+
+	// ErrSystemTxTarget is returned if a system transaction calls a contract
+	// which is not a configured system contract.
+	ErrSystemTxTarget = errors.New("system transaction calls non-system contract")
+
+	// ErrSystemTxDuplicate is returned if a block repeats a system transaction.
+	ErrSystemTxDuplicate = errors.New("duplicate system transaction")
+)
+
+// SystemTx is a protocol operation inserted by the sequencer at the top of a
+// block, e.g. an update of the L1 base fee. It's executed with SystemAddress as
+// the caller and a fixed gas allowance, without paying any fees or consuming
+// the block gas. System transactions carry no signature and can't be signed.
+type SystemTx struct {
+	Nonce uint64         // Number of the including block, keeps recurring calls unique
+	To    common.Address // System contract to call
+	Data  []byte         // Calldata of the system call
+}
+
+// NewSystemTx creates a system transaction calling a system contract in the
+// block with the given number.
+func NewSystemTx(number uint64, to common.Address, data []byte) *Transaction {
+	return NewTx(&SystemTx{Nonce: number, To: to, Data: data})
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *SystemTx) copy() TxData {
+	return &SystemTx{
+		Nonce: tx.Nonce,
+		To:    tx.To,
+		Data:  common.CopyBytes(tx.Data),
+	}
+}
+
+// accessors for innerTx.
+func (tx *SystemTx) txType() byte           { return SystemTxType }
+func (tx *SystemTx) chainID() *big.Int      { return new(big.Int) }
+func (tx *SystemTx) accessList() AccessList { return nil }
+func (tx *SystemTx) data() []byte           { return tx.Data }
+func (tx *SystemTx) gas() uint64            { return params.SystemTxGas }
+func (tx *SystemTx) gasFeeCap() *big.Int    { return new(big.Int) }
+func (tx *SystemTx) gasTipCap() *big.Int    { return new(big.Int) }
+func (tx *SystemTx) gasPrice() *big.Int     { return new(big.Int) }
+func (tx *SystemTx) value() *big.Int        { return new(big.Int) }
+func (tx *SystemTx) nonce() uint64          { return tx.Nonce }
+func (tx *SystemTx) to() *common.Address    { return &tx.To }
+
+func (tx *SystemTx) rawSignatureValues() (v, r, s *big.Int) {
+	return new(big.Int), new(big.Int), new(big.Int)
+}
+
+func (tx *SystemTx) setSignatureValues(chainID, v, r, s *big.Int) {}
+
+// IsSystemTx returns whether the transaction is a system transaction.
+func (tx *Transaction) IsSystemTx() bool {
+	return tx.Type() == SystemTxType
+}
+
+// VerifySystemTxs checks that the system transactions of a block precede all
+// other transactions, belong to the block with the given number and only call
+// the configured system contracts. As system transactions commit to their block
+// through their nonce and can't be repeated within it, their hashes are unique
+// across the chain.
+func VerifySystemTxs(config *params.ChainConfig, number *big.Int, txs Transactions) error {
+	var (
+		enabled = config.Scroll.IsSystemTx(number)
+		seen    = make(map[common.Hash]bool)
+	)
+	for i, tx := range txs {
+		if !tx.IsSystemTx() {
+			continue
+		}
+		if !enabled {
+			return ErrTxTypeNotSupported
+		}
+		if i > 0 && !txs[i-1].IsSystemTx() {
+			return ErrSystemTxPosition
+		}
+		if tx.Nonce() != number.Uint64() {
+			return ErrSystemTxNonce
+		}
+		if !config.Scroll.IsSystemContract(*tx.To()) {
+			return ErrSystemTxTarget
+		}
+		if seen[tx.Hash()] {
+			return ErrSystemTxDuplicate
+		}
+		seen[tx.Hash()] = true
+	}
+	return nil
+}
+
+// SystemTxCount returns the number of system transactions at the top of the
+// given transactions.
+func SystemTxCount(txs Transactions) int {
+	for i, tx := range txs {
+		if !tx.IsSystemTx() {
+			return i
+		}
+	}
+	return len(txs)
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// Tests that system transactions survive the encoding roundtrips, originate from
+// the system address and can't be signed.
+func TestSystemTx(t *testing.T) {
+	tx := NewSystemTx(42, common.Address{0xaa}, []byte{0x01, 0x02})
+
+	blob, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	var dec Transaction
+	if err := dec.UnmarshalBinary(blob); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if dec.Hash() != tx.Hash() || !dec.IsSystemTx() {
+		t.Fatalf("binary roundtrip mismatch: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	blob, err = json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if err := json.Unmarshal(blob, &dec); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Fatalf("json roundtrip mismatch: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	signer := LatestSigner(params.TestChainConfig)
+	if from, err := Sender(signer, tx); err != nil || from != SystemAddress {
+		t.Fatalf("sender mismatch: have %x (%v), want %x", from, err, SystemAddress)
+	}
+	if tx.Gas() != params.SystemTxGas || tx.GasPrice().Sign() != 0 {
+		t.Fatalf("gas mismatch: have %d at %v, want %d for free", tx.Gas(), tx.GasPrice(), params.SystemTxGas)
+	}
+	key, _ := crypto.GenerateKey()
+	if _, err := SignTx(tx, signer, key); err != ErrTxTypeNotSupported {
+		t.Fatalf("signing error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
+
+// Tests that system transactions are bound to their block and unique within it,
+// keeping their hashes unique across the chain.
+func TestVerifySystemTxs(t *testing.T) {
+	config := &params.ChainConfig{Scroll: params.ScrollConfig{
+		SystemTx: &params.SystemTxConfig{Block: big.NewInt(0), Contracts: []common.Address{{0xaa}}},
+	}}
+	call := func(number uint64) *Transaction {
+		return NewSystemTx(number, common.Address{0xaa}, []byte{0x01})
+	}
+	if call(1).Hash() == call(2).Hash() {
+		t.Fatalf("same system call in different blocks shares its hash")
+	}
+	for i, tt := range []struct {
+		txs Transactions
+		err error
+	}{
+		{Transactions{call(1)}, nil},
+		{Transactions{call(2)}, ErrSystemTxNonce},
+		{Transactions{NewSystemTx(1, common.Address{0xbb}, nil)}, ErrSystemTxTarget},
+		{Transactions{call(1), NewSystemTx(1, common.Address{0xaa}, []byte{0x02})}, nil},
+		{Transactions{call(1), call(1)}, ErrSystemTxDuplicate},
+	} {
+		if err := VerifySystemTxs(config, big.NewInt(1), tt.txs); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
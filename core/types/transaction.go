@@ -186,6 +186,22 @@ func (tx *Transaction) decodeTyped(b []byte) (TxData, error) {
 		var inner DynamicFeeTx
 		err := rlp.DecodeBytes(b[1:], &inner)
 		return &inner, err
+	case SystemTxType:
+		var inner SystemTx
+		err := rlp.DecodeBytes(b[1:], &inner)
+		return &inner, err
+	case SponsoredTxType:
+		var inner SponsoredTx
+		err := rlp.DecodeBytes(b[1:], &inner)
+		return &inner, err
+	case NonceKeyTxType:
+		var inner NonceKeyTx
+		err := rlp.DecodeBytes(b[1:], &inner)
+		return &inner, err
+	case SetCodeTxType:
+		var inner SetCodeTx
+		err := rlp.DecodeBytes(b[1:], &inner)
+		return &inner, err
 	default:
 		return nil, ErrTxTypeNotSupported
 	}
@@ -240,6 +256,8 @@ func (tx *Transaction) Protected() bool {
 	switch tx := tx.inner.(type) {
 	case *LegacyTx:
 		return tx.V != nil && isProtectedV(tx.V)
+	case *SystemTx:
+		return false
 	default:
 		return true
 	}
@@ -358,6 +376,17 @@ func (tx *Transaction) EffectiveGasTipIntCmp(other *big.Int, baseFee *big.Int) i
 	return tx.EffectiveGasTipValue(baseFee).Cmp(other)
 }
 
+// Time returns the time the transaction was first seen locally.
+func (tx *Transaction) Time() time.Time {
+	return tx.time
+}
+
+// SetTime sets the time the transaction was first seen locally, restoring it
+// for transactions persisted across restarts.
+func (tx *Transaction) SetTime(t time.Time) {
+	tx.time = t
+}
+
 // Hash returns the transaction hash.
 func (tx *Transaction) Hash() common.Hash {
 	if hash := tx.hash.Load(); hash != nil {
@@ -447,6 +476,10 @@ func (s TxByNonce) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 type TxWithMinerFee struct {
 	tx       *Transaction
 	minerFee *big.Int
+
+	// Fields only set in shuffled ordering mode, see tx_shuffle.go
+	band *big.Int    // Fee band the miner fee falls into
+	key  common.Hash // Seeded shuffle key used to order within a band
 }
 
 // NewTxWithMinerFee creates a wrapped transaction, calculating the effective
@@ -469,6 +502,14 @@ type TxByPriceAndTime []*TxWithMinerFee
 
 func (s TxByPriceAndTime) Len() int { return len(s) }
 func (s TxByPriceAndTime) Less(i, j int) bool {
+	// In shuffled mode, order by fee band first and by the shuffle key within
+	// the same band.
+	if s[i].band != nil && s[j].band != nil {
+		if cmp := s[i].band.Cmp(s[j].band); cmp != 0 {
+			return cmp > 0
+		}
+		return bytes.Compare(s[i].key[:], s[j].key[:]) < 0
+	}
 	// If the prices are equal, use the time the transaction was first seen for
 	// deterministic sorting
 	cmp := s[i].minerFee.Cmp(s[j].minerFee)
@@ -499,6 +540,7 @@ type TransactionsByPriceAndNonce struct {
 	heads   TxByPriceAndTime                // Next transaction for each unique account (price heap)
 	signer  Signer                          // Signer for the set of transactions
 	baseFee *big.Int                        // Current base fee
+	shuffle *txShuffle                      // Fee band shuffling parameters (nil = plain price ordering)
 }
 
 // NewTransactionsByPriceAndNonce creates a transaction set that can retrieve
@@ -507,11 +549,15 @@ type TransactionsByPriceAndNonce struct {
 // Note, the input map is reowned so the caller should not interact any more with
 // if after providing it to the constructor.
 func NewTransactionsByPriceAndNonce(signer Signer, txs map[common.Address]Transactions, baseFee *big.Int) *TransactionsByPriceAndNonce {
+	return newTransactionsByPriceAndNonce(signer, txs, baseFee, nil)
+}
+
+func newTransactionsByPriceAndNonce(signer Signer, txs map[common.Address]Transactions, baseFee *big.Int, shuffle *txShuffle) *TransactionsByPriceAndNonce {
 	// Initialize a price and received time based heap with the head transactions
 	heads := make(TxByPriceAndTime, 0, len(txs))
 	for from, accTxs := range txs {
 		acc, _ := Sender(signer, accTxs[0])
-		wrapped, err := NewTxWithMinerFee(accTxs[0], baseFee)
+		wrapped, err := shuffle.wrap(accTxs[0], baseFee)
 		// Remove transaction if sender doesn't match from, or if wrapping fails.
 		if acc != from || err != nil {
 			delete(txs, from)
@@ -528,6 +574,7 @@ func NewTransactionsByPriceAndNonce(signer Signer, txs map[common.Address]Transa
 		heads:   heads,
 		signer:  signer,
 		baseFee: baseFee,
+		shuffle: shuffle,
 	}
 }
 
@@ -543,7 +590,7 @@ func (t *TransactionsByPriceAndNonce) Peek() *Transaction {
 func (t *TransactionsByPriceAndNonce) Shift() {
 	acc, _ := Sender(t.signer, t.heads[0].tx)
 	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
-		if wrapped, err := NewTxWithMinerFee(txs[0], t.baseFee); err == nil {
+		if wrapped, err := t.shuffle.wrap(txs[0], t.baseFee); err == nil {
 			t.heads[0], t.txs[acc] = wrapped, txs[1:]
 			heap.Fix(&t.heads, 0)
 			return
@@ -574,6 +621,11 @@ type Message struct {
 	data       []byte
 	accessList AccessList
 	isFake     bool
+	isSystem   bool
+	feePayer   *common.Address
+	nonceKey   uint64
+	authList   []SetCodeAuthorization
+	encoded    []byte // Signed encoding of the transaction, if its L1 fee is charged on it
 }
 
 func NewMessage(from common.Address, to *common.Address, nonce uint64, amount *big.Int, gasLimit uint64, gasPrice, gasFeeCap, gasTipCap *big.Int, data []byte, accessList AccessList, isFake bool) Message {
@@ -592,6 +644,25 @@ func NewMessage(from common.Address, to *common.Address, nonce uint64, amount *b
 	}
 }
 
+// WithAuthList returns a copy of the message carrying the given set-code
+// authorizations.
+func (m Message) WithAuthList(authList []SetCodeAuthorization) Message {
+	m.authList = authList
+	return m
+}
+
+// L1FeeEncoded returns whether the L1 fee of the transaction is charged on its
+// signed encoding, rather than on the unsigned legacy encoding of its fields.
+// That's the case of the transaction types introduced by the rollup, whose
+// fields don't fit the legacy encoding.
+func (tx *Transaction) L1FeeEncoded() bool {
+	switch tx.Type() {
+	case SetCodeTxType, NonceKeyTxType, SponsoredTxType:
+		return true
+	}
+	return false
+}
+
 // AsMessage returns the transaction as a core.Message.
 func (tx *Transaction) AsMessage(s Signer, baseFee *big.Int) (Message, error) {
 	msg := Message{
@@ -605,6 +676,9 @@ func (tx *Transaction) AsMessage(s Signer, baseFee *big.Int) (Message, error) {
 		data:       tx.Data(),
 		accessList: tx.AccessList(),
 		isFake:     false,
+		isSystem:   tx.IsSystemTx(),
+		nonceKey:   tx.NonceKey(),
+		authList:   tx.SetCodeAuthorizations(),
 	}
 	// If baseFee provided, set gasPrice to effectiveGasPrice.
 	if baseFee != nil {
@@ -612,20 +686,44 @@ func (tx *Transaction) AsMessage(s Signer, baseFee *big.Int) (Message, error) {
 	}
 	var err error
 	msg.from, err = Sender(s, tx)
-	return msg, err
-}
-
-func (m Message) From() common.Address   { return m.from }
-func (m Message) To() *common.Address    { return m.to }
-func (m Message) GasPrice() *big.Int     { return m.gasPrice }
-func (m Message) GasFeeCap() *big.Int    { return m.gasFeeCap }
-func (m Message) GasTipCap() *big.Int    { return m.gasTipCap }
-func (m Message) Value() *big.Int        { return m.amount }
-func (m Message) Gas() uint64            { return m.gasLimit }
-func (m Message) Nonce() uint64          { return m.nonce }
-func (m Message) Data() []byte           { return m.data }
-func (m Message) AccessList() AccessList { return m.accessList }
-func (m Message) IsFake() bool           { return m.isFake }
+	if err != nil {
+		return msg, err
+	}
+	// Keep set-code messages apart even if they carry no authorization
+	if tx.Type() == SetCodeTxType && msg.authList == nil {
+		msg.authList = []SetCodeAuthorization{}
+	}
+	if tx.Type() == SponsoredTxType {
+		payer, err := FeePayer(s, tx)
+		if err != nil {
+			return msg, err
+		}
+		msg.feePayer = &payer
+	}
+	if tx.L1FeeEncoded() {
+		if msg.encoded, err = tx.MarshalBinary(); err != nil {
+			return msg, err
+		}
+	}
+	return msg, nil
+}
+
+func (m Message) From() common.Address             { return m.from }
+func (m Message) To() *common.Address              { return m.to }
+func (m Message) GasPrice() *big.Int               { return m.gasPrice }
+func (m Message) GasFeeCap() *big.Int              { return m.gasFeeCap }
+func (m Message) GasTipCap() *big.Int              { return m.gasTipCap }
+func (m Message) Value() *big.Int                  { return m.amount }
+func (m Message) Gas() uint64                      { return m.gasLimit }
+func (m Message) Nonce() uint64                    { return m.nonce }
+func (m Message) Data() []byte                     { return m.data }
+func (m Message) AccessList() AccessList           { return m.accessList }
+func (m Message) IsFake() bool                     { return m.isFake }
+func (m Message) IsSystem() bool                   { return m.isSystem }
+func (m Message) FeePayer() *common.Address        { return m.feePayer }
+func (m Message) NonceKey() uint64                 { return m.nonceKey }
+func (m Message) AuthList() []SetCodeAuthorization { return m.authList }
+func (m Message) Encoded() []byte                  { return m.encoded }
 
 // copyAddressPtr copies an address.
 func copyAddressPtr(a *common.Address) *common.Address {
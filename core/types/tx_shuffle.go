@@ -0,0 +1,119 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+// TxShuffleSeedLength is the number of leading extra-data bytes used to commit
+// to the transaction shuffle seed of a block, unless its extra-data follows the
+// structured layout.
+const TxShuffleSeedLength = common.HashLength
+
+var (
+	// ErrMissingShuffleSeed is returned if a block in shuffled ordering mode
+	// does not commit to its shuffle seed in the extra-data.
+	ErrMissingShuffleSeed = errors.New("missing transaction shuffle seed")
+
+	// ErrInvalidShuffleSeed is returned if the committed shuffle seed does not
+	// match the one derived from the parent hash.
+	ErrInvalidShuffleSeed = errors.New("invalid transaction shuffle seed")
+
+	// ErrInvalidTxOrder is returned if the transactions of a block are not in
+	// the shuffled fee band order.
+	ErrInvalidTxOrder = errors.New("invalid transaction order")
+
+	shuffleSeedPrefix = []byte("scroll-tx-shuffle")
+)
+
+// TxShuffleSeed derives the transaction shuffle seed of a block from the hash
+// of its parent. The seed is fully determined by the parent, so a sequencer
+// cannot grind it while assembling the block.
+func TxShuffleSeed(parentHash common.Hash) common.Hash {
+	return crypto.Keccak256Hash(shuffleSeedPrefix, parentHash[:])
+}
+
+// TxShuffleSeedFromExtra returns the shuffle seed committed in the extra-data
+// of a header.
+func TxShuffleSeedFromExtra(extra []byte) (common.Hash, error) {
+	if len(extra) < TxShuffleSeedLength {
+		return common.Hash{}, ErrMissingShuffleSeed
+	}
+	return common.BytesToHash(extra[:TxShuffleSeedLength]), nil
+}
+
+// txShuffle holds the parameters of the shuffled fee band ordering.
+type txShuffle struct {
+	seed    common.Hash
+	feeBand *big.Int
+}
+
+// wrap creates a wrapped transaction for the price heap. If shuffling is enabled
+// the fee band and shuffle key are populated too.
+func (s *txShuffle) wrap(tx *Transaction, baseFee *big.Int) (*TxWithMinerFee, error) {
+	wrapped, err := NewTxWithMinerFee(tx, baseFee)
+	if err != nil || s == nil {
+		return wrapped, err
+	}
+	wrapped.band = new(big.Int).Set(wrapped.minerFee)
+	if s.feeBand != nil && s.feeBand.Sign() > 0 {
+		wrapped.band.Div(wrapped.band, s.feeBand)
+	}
+	hash := tx.Hash()
+	wrapped.key = crypto.Keccak256Hash(s.seed[:], hash[:])
+	return wrapped, nil
+}
+
+// NewTransactionsByShuffledPriceAndNonce creates a transaction set that orders
+// transactions by fee band, and within the same fee band by a pseudo-random key
+// derived from the given seed, while still honouring account nonces.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// if after providing it to the constructor.
+func NewTransactionsByShuffledPriceAndNonce(signer Signer, txs map[common.Address]Transactions, baseFee *big.Int, seed common.Hash, feeBand *big.Int) *TransactionsByPriceAndNonce {
+	return newTransactionsByPriceAndNonce(signer, txs, baseFee, &txShuffle{seed: seed, feeBand: feeBand})
+}
+
+// VerifyShuffledOrder checks that the given block transactions are in the order
+// the shuffled fee band rule would have produced for them. Transactions that a
+// sequencer dropped during assembly are not part of the block, so replaying the
+// selection over the included transactions must yield exactly the same order.
+func VerifyShuffledOrder(signer Signer, txs Transactions, baseFee *big.Int, seed common.Hash, feeBand *big.Int) error {
+	grouped := make(map[common.Address]Transactions)
+	for _, tx := range txs {
+		from, err := Sender(signer, tx)
+		if err != nil {
+			return err
+		}
+		grouped[from] = append(grouped[from], tx)
+	}
+	set := NewTransactionsByShuffledPriceAndNonce(signer, grouped, baseFee, seed, feeBand)
+	for i, tx := range txs {
+		next := set.Peek()
+		if next == nil || next.Hash() != tx.Hash() {
+			return fmt.Errorf("%w: transaction %d (%x) out of place", ErrInvalidTxOrder, i, tx.Hash())
+		}
+		set.Shift()
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+func makeShuffleTestTxs(t *testing.T, signer Signer) (map[common.Address]Transactions, int) {
+	keys := make([]*ecdsa.PrivateKey, 10)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	groups := map[common.Address]Transactions{}
+	count := 0
+	for _, key := range keys {
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		for i := 0; i < 5; i++ {
+			tx, err := SignTx(NewTx(&LegacyTx{
+				Nonce:    uint64(i),
+				To:       &common.Address{},
+				Value:    big.NewInt(100),
+				Gas:      100,
+				GasPrice: big.NewInt(int64(rand.Intn(50))),
+			}), signer, key)
+			if err != nil {
+				t.Fatalf("failed to sign tx: %s", err)
+			}
+			groups[addr] = append(groups[addr], tx)
+			count++
+		}
+	}
+	return groups, count
+}
+
+// Tests that the shuffled ordering honours nonces and fee bands, and that the
+// produced order passes verification while a tampered order does not.
+func TestTransactionShuffledSort(t *testing.T) {
+	signer := LatestSignerForChainID(common.Big1)
+	groups, count := makeShuffleTestTxs(t, signer)
+
+	var (
+		seed    = TxShuffleSeed(common.Hash{0x01})
+		feeBand = big.NewInt(10)
+		txs     Transactions
+	)
+	txset := NewTransactionsByShuffledPriceAndNonce(signer, groups, nil, seed, feeBand)
+	for tx := txset.Peek(); tx != nil; tx = txset.Peek() {
+		txs = append(txs, tx)
+		txset.Shift()
+	}
+	if len(txs) != count {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(txs), count)
+	}
+	for i, txi := range txs {
+		fromi, _ := Sender(signer, txi)
+		for j, txj := range txs[i+1:] {
+			fromj, _ := Sender(signer, txj)
+			if fromi == fromj && txi.Nonce() > txj.Nonce() {
+				t.Errorf("invalid nonce ordering: tx #%d (A=%x N=%v) < tx #%d (A=%x N=%v)", i, fromi[:4], txi.Nonce(), i+j+1, fromj[:4], txj.Nonce())
+			}
+		}
+	}
+	if err := VerifyShuffledOrder(signer, txs, nil, seed, feeBand); err != nil {
+		t.Fatalf("failed to verify shuffled order: %v", err)
+	}
+	// A different seed must yield a different order for the same transactions
+	if err := VerifyShuffledOrder(signer, txs, nil, TxShuffleSeed(common.Hash{0x02}), feeBand); !errors.Is(err, ErrInvalidTxOrder) {
+		t.Fatalf("order verified with wrong seed: %v", err)
+	}
+	// Moving the last transaction to the front must be rejected
+	tampered := append(Transactions{txs[len(txs)-1]}, txs[:len(txs)-1]...)
+	if err := VerifyShuffledOrder(signer, tampered, nil, seed, feeBand); !errors.Is(err, ErrInvalidTxOrder) {
+		t.Fatalf("tampered order verified: %v", err)
+	}
+}
+
+func TestTxShuffleSeedFromExtra(t *testing.T) {
+	seed := TxShuffleSeed(common.Hash{0x01})
+	if _, err := TxShuffleSeedFromExtra(seed[:10]); err != ErrMissingShuffleSeed {
+		t.Fatalf("short extra-data accepted: %v", err)
+	}
+	extra := append(common.CopyBytes(seed[:]), make([]byte, 65)...)
+	if have, err := TxShuffleSeedFromExtra(extra); err != nil || have != seed {
+		t.Fatalf("seed mismatch: have %x, want %x, err %v", have, seed, err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+// CallRecorder is an EVM logger that records every message call and contract
+// creation of the executed transactions as compact internal transactions. A
+// single recorder can be used across all transactions of a block.
+type CallRecorder struct {
+	traces [][]*types.InternalTransaction
+	stack  []*types.InternalTransaction // Calls of the current transaction not yet exited
+}
+
+// NewCallRecorder creates a new call recorder.
+func NewCallRecorder() *CallRecorder {
+	return &CallRecorder{traces: [][]*types.InternalTransaction{}}
+}
+
+// Traces returns the internal transactions recorded so far, one list per
+// executed transaction.
+func (r *CallRecorder) Traces() [][]*types.InternalTransaction {
+	return r.traces
+}
+
+func (r *CallRecorder) enter(typ OpCode, from common.Address, to common.Address, input []byte, value *big.Int) {
+	call := &types.InternalTransaction{
+		Type:      typ.String(),
+		From:      from,
+		To:        to,
+		Value:     new(big.Int),
+		InputHash: crypto.Keccak256Hash(input),
+		Depth:     uint64(len(r.stack)),
+	}
+	if value != nil {
+		call.Value.Set(value)
+	}
+	last := len(r.traces) - 1
+	r.traces[last] = append(r.traces[last], call)
+	r.stack = append(r.stack, call)
+}
+
+func (r *CallRecorder) exit(err error) {
+	if len(r.stack) == 0 {
+		return
+	}
+	r.stack[len(r.stack)-1].Success = err == nil
+	r.stack = r.stack[:len(r.stack)-1]
+}
+
+// CaptureStart implements the EVMLogger interface, starting the record of a
+// new transaction.
+func (r *CallRecorder) CaptureStart(env *EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	typ := CALL
+	if create {
+		typ = CREATE
+	}
+	r.traces = append(r.traces, nil)
+	r.stack = r.stack[:0]
+	r.enter(typ, from, to, input, value)
+}
+
+// CaptureState implements the EVMLogger interface.
+func (r *CallRecorder) CaptureState(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error) {
+}
+
+// CaptureStateAfter implements the EVMLogger interface.
+func (r *CallRecorder) CaptureStateAfter(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error) {
+}
+
+// CaptureEnter implements the EVMLogger interface, recording a nested call.
+func (r *CallRecorder) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if len(r.traces) == 0 {
+		return
+	}
+	r.enter(typ, from, to, input, value)
+}
+
+// CaptureExit implements the EVMLogger interface, recording the outcome of a
+// nested call.
+func (r *CallRecorder) CaptureExit(output []byte, gasUsed uint64, err error) {
+	r.exit(err)
+}
+
+// CaptureFault implements the EVMLogger interface.
+func (r *CallRecorder) CaptureFault(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, depth int, err error) {
+}
+
+// CaptureEnd implements the EVMLogger interface, recording the outcome of the
+// top level call of the transaction.
+func (r *CallRecorder) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) {
+	r.exit(err)
+	r.stack = r.stack[:0]
+}
@@ -130,16 +130,25 @@ func init() {
 
 // ActivePrecompiles returns the precompiles enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
+	var addrs []common.Address
 	switch {
 	case rules.IsBerlin:
-		return PrecompiledAddressesBerlin
+		addrs = PrecompiledAddressesBerlin
 	case rules.IsIstanbul:
-		return PrecompiledAddressesIstanbul
+		addrs = PrecompiledAddressesIstanbul
 	case rules.IsByzantium:
-		return PrecompiledAddressesByzantium
+		addrs = PrecompiledAddressesByzantium
 	default:
-		return PrecompiledAddressesHomestead
+		addrs = PrecompiledAddressesHomestead
 	}
+	// The rollup precompiles come on top, copying the shared lists
+	if rules.IsL1Sload {
+		addrs = append(addrs[:len(addrs):len(addrs)], L1SloadAddress)
+	}
+	if rules.IsMerkleProof {
+		addrs = append(addrs[:len(addrs):len(addrs)], MerkleProofAddress)
+	}
+	return addrs
 }
 
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
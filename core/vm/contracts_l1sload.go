@@ -0,0 +1,95 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/holiman/uint256"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+// L1SloadAddress is the address of the precompile reading L1 storage.
+var L1SloadAddress = common.BytesToAddress([]byte{1, 1})
+
+var (
+	// ErrL1StateUnavailable is returned if the L1 state read by a transaction
+	// couldn't be retrieved.
+	ErrL1StateUnavailable = errors.New("L1 state unavailable")
+
+	errL1SloadInput  = errors.New("invalid L1SLOAD input")
+	errL1SloadOrigin = errors.New("no L1 origin")
+	errL1SloadRange  = errors.New("L1 block out of L1SLOAD range")
+)
+
+// L1StateReader supplies the L1SLOAD precompile with L1 storage values.
+type L1StateReader interface {
+	// StorageAt returns the value of a storage slot of an L1 account as of the
+	// given L1 block, proven against the header chain ending at the L1 origin
+	// block with the given number and hash.
+	StorageAt(origin uint64, originHash common.Hash, number uint64, addr common.Address, slot common.Hash) (common.Hash, error)
+}
+
+// l1Sload implements the L1SLOAD precompile, returning the value of a storage
+// slot of an L1 account as of an L1 block. The input is the address (20 bytes),
+// the slot (32 bytes) and the L1 block number (32 bytes).
+//
+// Whether a read succeeds only depends on the L2 state: the L1 block must be
+// one of the last params.L1SloadWindow blocks up to the L1 origin written into
+// the L1Block contract. The value itself is retrieved through the configured
+// L1StateReader, and a node failing to retrieve it can't execute the
+// transaction, see EVM.L1StateErr.
+type l1Sload struct {
+	evm *EVM
+}
+
+func (c *l1Sload) RequiredGas(input []byte) uint64 {
+	return params.L1SloadGas
+}
+
+func (c *l1Sload) Run(input []byte) ([]byte, error) {
+	if len(input) != common.AddressLength+2*common.HashLength {
+		return nil, errL1SloadInput
+	}
+	var (
+		addr   = common.BytesToAddress(input[:20])
+		slot   = common.BytesToHash(input[20:52])
+		number = new(uint256.Int).SetBytes(input[52:84])
+	)
+	originHash := c.evm.StateDB.GetState(rcfg.L1BlockAddress, rcfg.L1BlockHashSlot)
+	if originHash == (common.Hash{}) {
+		return nil, errL1SloadOrigin
+	}
+	origin := c.evm.StateDB.GetState(rcfg.L1BlockAddress, rcfg.L1BlockNumberSlot).Big().Uint64()
+	if !number.IsUint64() || number.Uint64() > origin || origin-number.Uint64() >= params.L1SloadWindow {
+		return nil, errL1SloadRange
+	}
+	if c.evm.Config.L1State == nil {
+		c.evm.l1StateErr = ErrL1StateUnavailable
+		return nil, ErrL1StateUnavailable
+	}
+	value, err := c.evm.Config.L1State.StorageAt(origin, originHash, number.Uint64(), addr, slot)
+	if err != nil {
+		c.evm.l1StateErr = fmt.Errorf("%w: %v", ErrL1StateUnavailable, err)
+		return nil, ErrL1StateUnavailable
+	}
+	return value.Bytes(), nil
+}
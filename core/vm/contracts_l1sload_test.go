@@ -0,0 +1,114 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+// testL1State is an L1StateReader holding a single L1 storage value.
+type testL1State struct {
+	value common.Hash
+	err   error
+}
+
+func (s *testL1State) StorageAt(origin uint64, originHash common.Hash, number uint64, addr common.Address, slot common.Hash) (common.Hash, error) {
+	return s.value, s.err
+}
+
+func l1SloadInput(addr common.Address, slot common.Hash, number uint64) []byte {
+	return append(append(addr.Bytes(), slot.Bytes()...), common.BigToHash(new(big.Int).SetUint64(number)).Bytes()...)
+}
+
+// Tests that the L1SLOAD precompile is only available from its fork on, and
+// that reads succeed or fail deterministically but for the availability of the
+// L1 state.
+func TestL1Sload(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Scroll.L1SloadBlock = big.NewInt(10)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	newEVM := func(block int64, reader L1StateReader) *EVM {
+		vmctx := BlockContext{BlockNumber: big.NewInt(block)}
+		return NewEVM(vmctx, TxContext{}, statedb, &config, Config{L1State: reader})
+	}
+	if _, ok := newEVM(9, nil).precompile(L1SloadAddress); ok {
+		t.Fatal("L1SLOAD available before its fork")
+	}
+	if active := ActivePrecompiles(config.Rules(big.NewInt(10))); active[len(active)-1] != L1SloadAddress {
+		t.Fatalf("L1SLOAD not active: %v", active)
+	}
+	value := common.HexToHash("0x2a")
+	evm := newEVM(10, &testL1State{value: value})
+	p, ok := evm.precompile(L1SloadAddress)
+	if !ok {
+		t.Fatal("L1SLOAD not available from its fork")
+	}
+	addr := common.HexToAddress("0x01")
+
+	// Reads fail until an origin is known
+	if _, err := p.Run(l1SloadInput(addr, common.Hash{}, 0)); err != errL1SloadOrigin {
+		t.Fatalf("read without origin: have %v, want %v", err, errL1SloadOrigin)
+	}
+	statedb.SetState(rcfg.L1BlockAddress, rcfg.L1BlockNumberSlot, common.BigToHash(big.NewInt(100)))
+	statedb.SetState(rcfg.L1BlockAddress, rcfg.L1BlockHashSlot, common.HexToHash("0xff"))
+
+	for _, tt := range []struct {
+		input []byte
+		err   error
+	}{
+		{l1SloadInput(addr, common.Hash{}, 100), nil},
+		{l1SloadInput(addr, common.Hash{}, 100-params.L1SloadWindow+1), nil},
+		{l1SloadInput(addr, common.Hash{}, 100-params.L1SloadWindow), errL1SloadRange},
+		{l1SloadInput(addr, common.Hash{}, 101), errL1SloadRange},
+		{l1SloadInput(addr, common.Hash{}, 100)[:83], errL1SloadInput},
+	} {
+		ret, err := p.Run(tt.input)
+		if err != tt.err {
+			t.Errorf("input %x: error mismatch: have %v, want %v", tt.input, err, tt.err)
+		}
+		if err == nil && common.BytesToHash(ret) != value {
+			t.Errorf("input %x: value mismatch: have %x, want %v", tt.input, ret, value)
+		}
+	}
+	if err := evm.L1StateErr(); err != nil {
+		t.Fatalf("unexpected L1 state failure: %v", err)
+	}
+	// Failures to retrieve the L1 state are recorded until the next transaction
+	for _, reader := range []L1StateReader{nil, &testL1State{err: errors.New("L1 node down")}} {
+		evm := newEVM(10, reader)
+		p, _ := evm.precompile(L1SloadAddress)
+		if _, err := p.Run(l1SloadInput(addr, common.Hash{}, 100)); err != ErrL1StateUnavailable {
+			t.Errorf("reader %v: error mismatch: have %v, want %v", reader, err, ErrL1StateUnavailable)
+		}
+		if err := evm.L1StateErr(); !errors.Is(err, ErrL1StateUnavailable) {
+			t.Errorf("reader %v: failure not recorded: %v", reader, err)
+		}
+		evm.Reset(TxContext{}, statedb)
+		if err := evm.L1StateErr(); err != nil {
+			t.Errorf("reader %v: failure not reset: %v", reader, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/holiman/uint256"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// MerkleProofAddress is the address of the precompile verifying Merkle proofs.
+var MerkleProofAddress = common.BytesToAddress([]byte{1, 2})
+
+// Hash functions of the Merkle proofs verified by the precompile.
+const (
+	merkleKeccak256 = 0
+	merkleSha256    = 1
+)
+
+var (
+	errMerkleProofInput = errors.New("invalid Merkle proof input")
+	errMerkleProofHash  = errors.New("unknown Merkle proof hash function")
+	errMerkleProofDepth = errors.New("Merkle proof too deep")
+	errMerkleProofIndex = errors.New("Merkle proof index out of range")
+)
+
+// merkleProof implements the precompile verifying a Merkle proof of a leaf of
+// a binary tree, like the proofs of the L1 and L2 message trees. The input is
+// the hash function, the leaf, the root, the index of the leaf and the
+// siblings from the leaf level upwards, each a 32-byte word. The output is 1
+// if the proof is valid and 0 otherwise.
+//
+// Bit i of the index tells whether the node at level i is a right child, which
+// is hashed after its sibling.
+type merkleProof struct{}
+
+func (c *merkleProof) RequiredGas(input []byte) uint64 {
+	perLevel := params.MerkleProofKeccakPerLevelGas
+	if len(input) >= 32 && new(uint256.Int).SetBytes(input[:32]).Eq(uint256.NewInt(merkleSha256)) {
+		perLevel = params.MerkleProofSha256PerLevelGas
+	}
+	depth := uint64(0)
+	if len(input) > 4*32 {
+		depth = uint64(len(input)-4*32) / 32
+	}
+	return params.MerkleProofBaseGas + depth*perLevel
+}
+
+func (c *merkleProof) Run(input []byte) ([]byte, error) {
+	if len(input) < 4*32 || len(input)%32 != 0 {
+		return nil, errMerkleProofInput
+	}
+	var hasher func(left, right []byte) []byte
+	switch kind := new(uint256.Int).SetBytes(input[:32]); {
+	case kind.Eq(uint256.NewInt(merkleKeccak256)):
+		hasher = func(left, right []byte) []byte { return crypto.Keccak256(left, right) }
+	case kind.Eq(uint256.NewInt(merkleSha256)):
+		hasher = func(left, right []byte) []byte {
+			hash := sha256.Sum256(append(append(make([]byte, 0, 64), left...), right...))
+			return hash[:]
+		}
+	default:
+		return nil, errMerkleProofHash
+	}
+	var (
+		node     = input[32:64]
+		root     = input[64:96]
+		index    = new(uint256.Int).SetBytes(input[96:128])
+		siblings = input[128:]
+		depth    = uint64(len(siblings) / 32)
+	)
+	if depth > params.MerkleProofMaxDepth {
+		return nil, errMerkleProofDepth
+	}
+	if index.BitLen() > int(depth) {
+		return nil, errMerkleProofIndex
+	}
+	bits := index.Uint64()
+	for level := uint64(0); level < depth; level++ {
+		sibling := siblings[level*32 : (level+1)*32]
+		if (bits>>level)&1 == 0 {
+			node = hasher(node, sibling)
+		} else {
+			node = hasher(sibling, node)
+		}
+	}
+	if common.BytesToHash(node) != common.BytesToHash(root) {
+		return common.Hash{}.Bytes(), nil
+	}
+	return common.BigToHash(common.Big1).Bytes(), nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/ethdb/memorydb"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
+)
+
+func merkleProofInput(kind uint64, leaf, root common.Hash, index uint64, siblings []common.Hash) []byte {
+	input := common.BigToHash(new(big.Int).SetUint64(kind)).Bytes()
+	input = append(input, leaf.Bytes()...)
+	input = append(input, root.Bytes()...)
+	input = append(input, common.BigToHash(new(big.Int).SetUint64(index)).Bytes()...)
+	for _, sibling := range siblings {
+		input = append(input, sibling.Bytes()...)
+	}
+	return input
+}
+
+func sha256Pair(left, right common.Hash) common.Hash {
+	return sha256.Sum256(append(left.Bytes(), right.Bytes()...))
+}
+
+// Tests that the Merkle proof precompile verifies the proofs of the message
+// trees, charging gas by depth.
+func TestMerkleProof(t *testing.T) {
+	valid := common.BigToHash(common.Big1).Bytes()
+
+	// Keccak256 proofs of the withdraw trie
+	tree := withdrawtrie.OpenTree(memorydb.New(), 0)
+	for i := 0; i < 5; i++ {
+		tree.Append(common.BigToHash(big.NewInt(int64(i + 1))))
+	}
+	proof, err := tree.Prove(3)
+	if err != nil {
+		t.Fatalf("failed to prove message: %v", err)
+	}
+	// Sha256 proofs of a tree of four leaves
+	leaves := []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02"), common.HexToHash("0x03"), common.HexToHash("0x04")}
+	left, right := sha256Pair(leaves[0], leaves[1]), sha256Pair(leaves[2], leaves[3])
+	root := sha256Pair(left, right)
+
+	tampered := append([]common.Hash{{0xff}}, proof.Siblings[1:]...)
+	for i, tt := range []struct {
+		input []byte
+		gas   uint64
+		want  []byte
+		err   error
+	}{
+		{merkleProofInput(0, proof.MessageHash, proof.Root, 3, proof.Siblings), params.MerkleProofBaseGas + 3*params.MerkleProofKeccakPerLevelGas, valid, nil},
+		{merkleProofInput(0, proof.MessageHash, proof.Root, 2, proof.Siblings), params.MerkleProofBaseGas + 3*params.MerkleProofKeccakPerLevelGas, make([]byte, 32), nil},
+		{merkleProofInput(0, proof.MessageHash, proof.Root, 3, tampered), params.MerkleProofBaseGas + 3*params.MerkleProofKeccakPerLevelGas, make([]byte, 32), nil},
+		{merkleProofInput(1, leaves[2], root, 2, []common.Hash{leaves[3], left}), params.MerkleProofBaseGas + 2*params.MerkleProofSha256PerLevelGas, valid, nil},
+		{merkleProofInput(1, leaves[1], root, 1, []common.Hash{leaves[0], right}), params.MerkleProofBaseGas + 2*params.MerkleProofSha256PerLevelGas, valid, nil},
+		{merkleProofInput(0, leaves[1], root, 1, []common.Hash{leaves[0], right}), params.MerkleProofBaseGas + 2*params.MerkleProofKeccakPerLevelGas, make([]byte, 32), nil},
+		{merkleProofInput(0, leaves[0], leaves[0], 0, nil), params.MerkleProofBaseGas, valid, nil},
+
+		{merkleProofInput(2, leaves[0], root, 0, nil), params.MerkleProofBaseGas, nil, errMerkleProofHash},
+		{merkleProofInput(1, leaves[2], root, 4, []common.Hash{leaves[3], left}), params.MerkleProofBaseGas + 2*params.MerkleProofSha256PerLevelGas, nil, errMerkleProofIndex},
+		{merkleProofInput(0, leaves[0], root, 0, make([]common.Hash, params.MerkleProofMaxDepth+1)), params.MerkleProofBaseGas + (params.MerkleProofMaxDepth+1)*params.MerkleProofKeccakPerLevelGas, nil, errMerkleProofDepth},
+		{merkleProofInput(0, leaves[0], root, 0, nil)[:127], params.MerkleProofBaseGas, nil, errMerkleProofInput},
+	} {
+		p := &merkleProof{}
+		if gas := p.RequiredGas(tt.input); gas != tt.gas {
+			t.Errorf("test %d: gas mismatch: have %d, want %d", i, gas, tt.gas)
+		}
+		out, err := p.Run(tt.input)
+		if err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+		if common.Bytes2Hex(out) != common.Bytes2Hex(tt.want) {
+			t.Errorf("test %d: output mismatch: have %x, want %x", i, out, tt.want)
+		}
+	}
+	// The precompile is only available from its fork on
+	config := *params.TestChainConfig
+	config.Scroll.MerkleProofBlock = big.NewInt(10)
+	for _, block := range []int64{9, 10} {
+		evm := NewEVM(BlockContext{BlockNumber: big.NewInt(block)}, TxContext{}, nil, &config, Config{})
+		_, ok := evm.precompile(MerkleProofAddress)
+		active := ActivePrecompiles(config.Rules(big.NewInt(block)))
+		if listed := active[len(active)-1] == MerkleProofAddress; ok != (block >= 10) || listed != ok {
+			t.Errorf("block %d: precompile availability mismatch: available %v, listed %v", block, ok, listed)
+		}
+	}
+	if len(PrecompiledAddressesBerlin) != len(PrecompiledContractsBerlin) {
+		t.Errorf("shared precompile list modified")
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/holiman/uint256"
 
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/crypto/codehash"
 	"github.com/scroll-tech/go-ethereum/params"
@@ -55,6 +56,12 @@ func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 	default:
 		precompiles = PrecompiledContractsHomestead
 	}
+	if evm.chainRules.IsL1Sload && addr == L1SloadAddress {
+		return &l1Sload{evm: evm}, true
+	}
+	if evm.chainRules.IsMerkleProof && addr == MerkleProofAddress {
+		return &merkleProof{}, true
+	}
 	p, ok := precompiles[addr]
 	return p, ok
 }
@@ -123,6 +130,9 @@ type EVM struct {
 	// available gas is calculated in gasCall* according to the 63/64 rule and later
 	// applied in opCall*.
 	callGasTemp uint64
+	// l1StateErr is the failure to retrieve L1 state for the L1SLOAD precompile
+	// during the current transaction, making its outcome unknown
+	l1StateErr error
 }
 
 // NewEVM returns a new EVM. The returned EVM is not thread safe and should
@@ -145,6 +155,7 @@ func NewEVM(blockCtx BlockContext, txCtx TxContext, statedb StateDB, chainConfig
 func (evm *EVM) Reset(txCtx TxContext, statedb StateDB) {
 	evm.TxContext = txCtx
 	evm.StateDB = statedb
+	evm.l1StateErr = nil
 }
 
 // Cancel cancels any running EVM operation. This may be called concurrently and
@@ -153,6 +164,13 @@ func (evm *EVM) Cancel() {
 	atomic.StoreInt32(&evm.abort, 1)
 }
 
+// L1StateErr returns the failure to retrieve the L1 state read by the current
+// transaction, if any. The outcome of the transaction is unknown then, and it
+// must not be included in a block.
+func (evm *EVM) L1StateErr() error {
+	return evm.l1StateErr
+}
+
 // Cancelled returns true if Cancel has been called
 func (evm *EVM) Cancelled() bool {
 	return atomic.LoadInt32(&evm.abort) == 1
@@ -163,6 +181,19 @@ func (evm *EVM) Interpreter() *EVMInterpreter {
 	return evm.interpreter
 }
 
+// resolveCode returns the hash and code executed when calling addr, which are
+// those of the account addr delegates its code to, if any, once set-code
+// transactions are enabled.
+func (evm *EVM) resolveCode(addr common.Address) (common.Hash, []byte) {
+	code := evm.StateDB.GetCode(addr)
+	if evm.chainRules.IsSetCode {
+		if target, ok := types.ParseDelegation(code); ok {
+			return evm.StateDB.GetKeccakCodeHash(target), evm.StateDB.GetCode(target)
+		}
+	}
+	return evm.StateDB.GetKeccakCodeHash(addr), code
+}
+
 // Call executes the contract associated with the addr with the given input as
 // parameters. It also handles any necessary value transfer required and takes
 // the necessary steps to create accounts and reverses the state in case of an
@@ -221,7 +252,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	} else {
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
-		code := evm.StateDB.GetCode(addr)
+		codeHash, code := evm.resolveCode(addr)
 		if len(code) == 0 {
 			ret, err = nil, nil // gas is unchanged
 		} else {
@@ -229,7 +260,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 			// If the account has no code, we can abort here
 			// The depth-check is already done, and precompiles handled above
 			contract := NewContract(caller, AccountRef(addrCopy), value, gas)
-			contract.SetCallCode(&addrCopy, evm.StateDB.GetKeccakCodeHash(addrCopy), code)
+			contract.SetCallCode(&addrCopy, codeHash, code)
 			ret, err = evm.interpreter.Run(contract, input, false)
 			gas = contract.Gas
 		}
@@ -289,7 +320,8 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
 		contract := NewContract(caller, AccountRef(caller.Address()), value, gas)
-		contract.SetCallCode(&addrCopy, evm.StateDB.GetKeccakCodeHash(addrCopy), evm.StateDB.GetCode(addrCopy))
+		codeHash, code := evm.resolveCode(addrCopy)
+		contract.SetCallCode(&addrCopy, codeHash, code)
 		ret, err = evm.interpreter.Run(contract, input, false)
 		gas = contract.Gas
 	}
@@ -332,7 +364,8 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 		addrCopy := addr
 		// Initialise a new contract and make initialise the delegate values
 		contract := NewContract(caller, AccountRef(caller.Address()), nil, gas).AsDelegate()
-		contract.SetCallCode(&addrCopy, evm.StateDB.GetKeccakCodeHash(addrCopy), evm.StateDB.GetCode(addrCopy))
+		codeHash, code := evm.resolveCode(addrCopy)
+		contract.SetCallCode(&addrCopy, codeHash, code)
 		ret, err = evm.interpreter.Run(contract, input, false)
 		gas = contract.Gas
 	}
@@ -388,7 +421,8 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
 		contract := NewContract(caller, AccountRef(addrCopy), new(big.Int), gas)
-		contract.SetCallCode(&addrCopy, evm.StateDB.GetKeccakCodeHash(addrCopy), evm.StateDB.GetCode(addrCopy))
+		codeHash, code := evm.resolveCode(addrCopy)
+		contract.SetCallCode(&addrCopy, codeHash, code)
 		// When an error was returned by the EVM or when setting the creation code
 		// above we revert to the snapshot and consume any gas remaining. Additionally
 		// when we're in Homestead this also counts for code storage gas errors.
@@ -424,6 +458,10 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, common.Address{}, gas, ErrDepth
 	}
+	// Fail consuming all gas if the init code is above the configured limit
+	if limit := evm.chainConfig.Scroll.MaxInitCodeSize(evm.Context.BlockNumber); limit != 0 && uint64(len(codeAndHash.code)) > limit {
+		return nil, common.Address{}, 0, ErrMaxInitCodeSizeExceeded
+	}
 	if !evm.Context.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, common.Address{}, gas, ErrInsufficientBalance
 	}
@@ -472,7 +510,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	ret, err := evm.interpreter.Run(contract, nil, false)
 
 	// Check whether the max code size has been exceeded, assign err if the case.
-	if err == nil && evm.chainRules.IsEIP158 && len(ret) > params.MaxCodeSize {
+	if err == nil && evm.chainRules.IsEIP158 && uint64(len(ret)) > evm.chainConfig.Scroll.MaxCodeSize(evm.Context.BlockNumber) {
 		err = ErrMaxCodeSizeExceeded
 	}
 
@@ -516,8 +554,19 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 
 // Create creates a new contract using code as deployment code.
 func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
-	contractAddr = crypto.CreateAddress(caller.Address(), evm.StateDB.GetNonce(caller.Address()))
-	return evm.create(caller, &codeAndHash{code: code}, gas, value, contractAddr, CREATE)
+	nonce := evm.StateDB.GetNonce(caller.Address())
+	contractAddr = crypto.CreateAddress(caller.Address(), nonce)
+	codeAndHash := &codeAndHash{code: code}
+	ret, contractAddr, leftOverGas, err = evm.create(caller, codeAndHash, gas, value, contractAddr, CREATE)
+	if err == nil {
+		evm.StateDB.AddCreation(&types.ContractCreation{
+			Address:      contractAddr,
+			Creator:      caller.Address(),
+			Nonce:        nonce,
+			InitCodeHash: codeAndHash.Hash(),
+		})
+	}
+	return ret, contractAddr, leftOverGas, err
 }
 
 // Create2 creates a new contract using code as deployment code.
@@ -527,7 +576,17 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *big.Int, salt *uint256.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
 	codeAndHash := &codeAndHash{code: code}
 	contractAddr = crypto.CreateAddress2(caller.Address(), salt.Bytes32(), codeAndHash.Hash().Bytes())
-	return evm.create(caller, codeAndHash, gas, endowment, contractAddr, CREATE2)
+	ret, contractAddr, leftOverGas, err = evm.create(caller, codeAndHash, gas, endowment, contractAddr, CREATE2)
+	if err == nil {
+		saltHash := common.Hash(salt.Bytes32())
+		evm.StateDB.AddCreation(&types.ContractCreation{
+			Address:      contractAddr,
+			Creator:      caller.Address(),
+			Salt:         &saltHash,
+			InitCodeHash: codeAndHash.Hash(),
+		})
+	}
+	return ret, contractAddr, leftOverGas, err
 }
 
 // ChainConfig returns the environment's chain configuration
@@ -0,0 +1,83 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// gasScheduleOp returns the opcode repriced by a gas schedule entry, also
+// accepting KECCAK256 for SHA3.
+func gasScheduleOp(name string) (OpCode, bool) {
+	if name == "KECCAK256" {
+		name = SHA3.String()
+	}
+	op, ok := stringToOp[name]
+	return op, ok
+}
+
+// CheckGasSchedules checks that the gas schedules of a chain config only
+// reprice known opcodes, each once per schedule.
+func CheckGasSchedules(config *params.ChainConfig) error {
+	for i, schedule := range config.Scroll.GasSchedules {
+		seen := make(map[OpCode]bool)
+		for name := range schedule.Opcodes {
+			op, ok := gasScheduleOp(name)
+			if !ok {
+				return fmt.Errorf("gasSchedules[%d]: unknown opcode %q", i, name)
+			}
+			if seen[op] {
+				return fmt.Errorf("gasSchedules[%d]: opcode %v repriced twice", i, op)
+			}
+			seen[op] = true
+		}
+	}
+	return nil
+}
+
+// applyGasSchedules replaces the constant gas of the opcodes repriced by the gas
+// schedules active at the given block, later schedules overriding earlier ones.
+// The repriced operations are copied, leaving the instruction sets shared by
+// all interpreters untouched. Opcodes undefined in the jump table stay undefined.
+func applyGasSchedules(jt *JumpTable, config *params.ChainConfig, num *big.Int) {
+	for i, schedule := range config.Scroll.GasSchedules {
+		if !config.Scroll.IsGasSchedule(i, num) {
+			break
+		}
+		// Resolve the names first, so that the result doesn't depend on the map
+		// order should aliases of an opcode slip through CheckGasSchedules
+		overrides := make(map[OpCode]uint64, len(schedule.Opcodes))
+		for name, gas := range schedule.Opcodes {
+			if op, ok := gasScheduleOp(name); ok {
+				if have, dup := overrides[op]; !dup || gas > have {
+					overrides[op] = gas
+				}
+			}
+		}
+		for op, gas := range overrides {
+			if jt[op] == nil {
+				continue
+			}
+			repriced := *jt[op]
+			repriced.constantGas = gas
+			jt[op] = &repriced
+		}
+	}
+}
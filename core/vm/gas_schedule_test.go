@@ -0,0 +1,82 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// Tests that the gas schedules of the chain config reprice opcodes from their
+// block on, without altering the instruction sets shared by other chains.
+func TestGasSchedules(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Scroll.GasSchedules = []params.GasScheduleConfig{
+		{Block: big.NewInt(10), Opcodes: map[string]uint64{"KECCAK256": 100}},
+		{Block: big.NewInt(20), Opcodes: map[string]uint64{"SHA3": 200, "SLOAD": 50}},
+	}
+	if err := CheckGasSchedules(&config); err != nil {
+		t.Fatalf("failed to check gas schedules: %v", err)
+	}
+	address := common.BytesToAddress([]byte("contract"))
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.CreateAccount(address)
+	statedb.SetCode(address, hexutil.MustDecode("0x600060002060005400")) // SHA3(0, 0), SLOAD(0)
+
+	// PUSH1 * 3 + SHA3 + SLOAD (cold), all prices but the repriced ones
+	base := 3*GasFastestStep + params.ColdSloadCostEIP2929
+	for _, tt := range []struct {
+		block int64
+		used  uint64
+	}{
+		{9, base + params.Sha3Gas},
+		{10, base + 100},
+		{20, base + 200 + 50},
+	} {
+		vmctx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: big.NewInt(tt.block),
+		}
+		vmenv := NewEVM(vmctx, TxContext{}, statedb.Copy(), &config, Config{})
+		_, gas, err := vmenv.Call(AccountRef(common.Address{}), address, nil, 100000, new(big.Int))
+		if err != nil {
+			t.Fatalf("block %d: failed to call: %v", tt.block, err)
+		}
+		if used := 100000 - gas; used != tt.used {
+			t.Errorf("block %d: gas used mismatch: have %d, want %d", tt.block, used, tt.used)
+		}
+	}
+	if londonInstructionSet[SHA3].constantGas != params.Sha3Gas {
+		t.Errorf("shared instruction set repriced: SHA3 gas %d", londonInstructionSet[SHA3].constantGas)
+	}
+	config.Scroll.GasSchedules[1].Opcodes["KECCAK256"] = 300
+	if err := CheckGasSchedules(&config); err == nil {
+		t.Errorf("opcode repriced twice accepted")
+	}
+	delete(config.Scroll.GasSchedules[1].Opcodes, "KECCAK256")
+	config.Scroll.GasSchedules[1].Opcodes["NOTANOPCODE"] = 1
+	if err := CheckGasSchedules(&config); err == nil {
+		t.Errorf("unknown opcode accepted")
+	}
+}
@@ -33,6 +33,8 @@ type StateDB interface {
 
 	GetNonce(common.Address) uint64
 	SetNonce(common.Address, uint64)
+	GetNonceKey(common.Address, uint64) uint64
+	SetNonceKey(common.Address, uint64, uint64)
 
 	GetKeccakCodeHash(common.Address) common.Hash
 	GetCode(common.Address) []byte
@@ -79,6 +81,7 @@ type StateDB interface {
 
 	AddLog(*types.Log)
 	AddPreimage(common.Hash, []byte)
+	AddCreation(*types.ContractCreation)
 
 	ForEachStorage(common.Address, func(common.Hash, common.Hash) bool) error
 }
@@ -33,6 +33,8 @@ type Config struct {
 	NoBaseFee               bool      // Forces the EIP-1559 baseFee to 0 (needed for 0 price calls)
 	EnablePreimageRecording bool      // Enables recording of SHA3/keccak preimages
 
+	L1State L1StateReader // Source of the L1 storage read by the L1SLOAD precompile
+
 	JumpTable [256]*operation // EVM instruction table, automatically populated if unset
 
 	ExtraEips []int // Additional EIPS that are to be enabled
@@ -100,6 +102,8 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 				log.Error("EIP activation failed", "eip", eip, "error", err)
 			}
 		}
+		// Reprice the opcodes scheduled by the chain config for this block
+		applyGasSchedules(&jt, evm.chainConfig, evm.Context.BlockNumber)
 		cfg.JumpTable = jt
 	}
 
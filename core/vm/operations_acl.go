@@ -21,6 +21,7 @@ import (
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/common/math"
+	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/params"
 )
 
@@ -172,6 +173,22 @@ func makeCallVariantGasCallEIP2929(oldCalculator gasFunc) gasFunc {
 			if !contract.UseGas(coldCost) {
 				return 0, ErrOutOfGas
 			}
+		} else {
+			coldCost = 0
+		}
+		// Calling an account delegating its code also accesses the delegate
+		if evm.chainRules.IsSetCode {
+			if target, ok := types.ParseDelegation(evm.StateDB.GetCode(addr)); ok {
+				delegateCost := params.WarmStorageReadCostEIP2929
+				if !evm.StateDB.AddressInAccessList(target) {
+					evm.StateDB.AddAddressToAccessList(target)
+					delegateCost = params.ColdAccountAccessCostEIP2929
+				}
+				if !contract.UseGas(delegateCost) {
+					return 0, ErrOutOfGas
+				}
+				coldCost += delegateCost
+			}
 		}
 		// Now call the old calculator, which takes into account
 		// - create new account
@@ -179,7 +196,7 @@ func makeCallVariantGasCallEIP2929(oldCalculator gasFunc) gasFunc {
 		// - memory expansion
 		// - 63/64ths rule
 		gas, err := oldCalculator(evm, contract, stack, mem, memorySize)
-		if warmAccess || err != nil {
+		if coldCost == 0 || err != nil {
 			return gas, err
 		}
 		// In case of a cold access, we temporarily add the cold charge back, and also
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
 	"errors"
@@ -25,6 +26,7 @@ import (
 	"math/big"
 	"os"
 	"runtime"
+	"runtime/pprof"
 	"strings"
 	"time"
 
@@ -33,7 +35,9 @@ import (
 	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/state/snapshot"
 	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
 	"github.com/scroll-tech/go-ethereum/internal/ethapi"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/rlp"
@@ -136,6 +140,48 @@ func (api *PrivateMinerAPI) SetGasLimit(gasLimit hexutil.Uint64) bool {
 	return true
 }
 
+// SetGasLimitRamp sets the gaslimit to target towards during mining, changing
+// it by at most maxDelta per block (0 = consensus bound).
+func (api *PrivateMinerAPI) SetGasLimitRamp(gasLimit hexutil.Uint64, maxDelta hexutil.Uint64) bool {
+	api.e.Miner().SetGasLimitRamp(uint64(gasLimit), uint64(maxDelta))
+	return true
+}
+
+// L1FeeRefundArgs is a refund of the L1 data fee overcharged to a transaction,
+// as computed once its batch is compressed.
+type L1FeeRefundArgs struct {
+	TxHash common.Hash  `json:"txHash"`
+	Amount *hexutil.Big `json:"amount"`
+}
+
+// AddL1FeeRefunds queues L1 fee refunds to be issued by the next blocks produced.
+// Either all refunds are queued, or none if any of them is invalid.
+func (api *PrivateMinerAPI) AddL1FeeRefunds(args []L1FeeRefundArgs) error {
+	if api.e.l1FeeRefunds == nil {
+		return errors.New("L1 fee refunds not enabled")
+	}
+	refunds := make([]*types.L1FeeRefund, 0, len(args))
+	for _, arg := range args {
+		if arg.Amount == nil {
+			return fmt.Errorf("missing refund amount of %x", arg.TxHash)
+		}
+		tx, _, number, _ := rawdb.ReadTransaction(api.e.ChainDb(), arg.TxHash)
+		if tx == nil {
+			return fmt.Errorf("transaction %x not found", arg.TxHash)
+		}
+		sender, err := types.Sender(types.MakeSigner(api.e.blockchain.Config(), new(big.Int).SetUint64(number)), tx)
+		if err != nil {
+			return err
+		}
+		refunds = append(refunds, &types.L1FeeRefund{
+			TxHash:    arg.TxHash,
+			Recipient: sender,
+			Amount:    arg.Amount.ToInt(),
+		})
+	}
+	return api.e.l1FeeRefunds.Add(refunds)
+}
+
 // SetEtherbase sets the etherbase of the miner
 func (api *PrivateMinerAPI) SetEtherbase(etherbase common.Address) bool {
 	api.e.SetEtherbase(etherbase)
@@ -257,6 +303,35 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 	return true, nil
 }
 
+// SnapshotProgress returns the progress of the state snapshot generation, and
+// whether it's currently paused to make way for block production.
+func (api *PrivateAdminAPI) SnapshotProgress() (*snapshot.GeneratorProgress, error) {
+	snaps := api.eth.BlockChain().Snapshots()
+	if snaps == nil {
+		return nil, errors.New("snapshots disabled")
+	}
+	return snaps.Progress()
+}
+
+// PauseTxs temporarily refuses the transactions matching the given rule, like
+// contract creations or calls to an address or of a 4-byte selector, in the
+// transaction pool and the blocks produced, until its Unix expiry timestamp.
+// It returns the ID of the rule, to lift it earlier with UnpauseTxs.
+func (api *PrivateAdminAPI) PauseTxs(rule core.TxPauseRule) (uint64, error) {
+	return api.eth.TxPool().PauseTxs(rule)
+}
+
+// UnpauseTxs lifts the pause rule with the given ID, returning whether it was
+// active.
+func (api *PrivateAdminAPI) UnpauseTxs(id uint64) bool {
+	return api.eth.TxPool().UnpauseTxs(id)
+}
+
+// PausedTxs returns the active pause rules.
+func (api *PrivateAdminAPI) PausedTxs() []core.TxPauseRule {
+	return api.eth.TxPool().PausedTxs()
+}
+
 // PublicDebugAPI is the collection of Ethereum full node APIs exposed
 // over the public debugging endpoint.
 type PublicDebugAPI struct {
@@ -298,6 +373,10 @@ func (api *PublicDebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error
 	return stateDb.RawDump(opts), nil
 }
 
+// accessListReexec is the maximum number of blocks re-executed to regenerate
+// the parent state of a block whose access list was not recorded.
+const accessListReexec = 128
+
 // PrivateDebugAPI is the collection of Ethereum full node APIs exposed over
 // the private debugging endpoint.
 type PrivateDebugAPI struct {
@@ -318,6 +397,201 @@ func (api *PrivateDebugAPI) Preimage(ctx context.Context, hash common.Hash) (hex
 	return nil, errors.New("unknown preimage")
 }
 
+// GetBlockAccessList returns the complete set of accounts, storage slots and
+// contract code accessed while executing the given block. If the access list
+// was not recorded at import time, the block is re-executed to produce it.
+func (api *PrivateDebugAPI) GetBlockAccessList(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (types.BlockAccessList, error) {
+	block, err := api.eth.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New("block not found")
+	}
+	if list := rawdb.ReadBlockAccessList(api.eth.ChainDb(), block.Hash()); list != nil {
+		return list, nil
+	}
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not executable")
+	}
+	parent := api.eth.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %#x not found", block.ParentHash())
+	}
+	statedb, err := api.eth.stateAtBlock(parent, accessListReexec, nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	statedb.StartAccessRecording()
+	if _, _, _, err := api.eth.blockchain.Processor().Process(block, statedb, vm.Config{}); err != nil {
+		return nil, err
+	}
+	return statedb.AccessRecording(), nil
+}
+
+// BlockImportProfile is the CPU profile of the re-execution of a block.
+type BlockImportProfile struct {
+	Number         hexutil.Uint64 `json:"number"`
+	Hash           common.Hash    `json:"hash"`
+	ExecutionTime  float64        `json:"executionTime"`     // Time to execute the transactions, in milliseconds
+	ValidationTime float64        `json:"validationTime"`    // Time to validate the resulting state, in milliseconds
+	Profile        hexutil.Bytes  `json:"profile,omitempty"` // Gzipped pprof CPU profile, unless written to a file
+	File           string         `json:"file,omitempty"`    // File the profile was written to
+}
+
+// ProfileBlockImport re-executes the given block on top of its parent state and
+// validates the result with CPU profiling enabled, to investigate the import of
+// specific slow blocks. The pprof profile is returned, or written to the given
+// file if any, and can be rendered as a flamegraph with `go tool pprof -http`.
+//
+// Regenerating the parent state isn't profiled, but the profile covers the whole
+// process while the block executes. Samples of the re-execution are labelled
+// with the block hash, to isolate them with `-tagfocus`.
+func (api *PrivateDebugAPI) ProfileBlockImport(ctx context.Context, hash common.Hash, file *string) (*BlockImportProfile, error) {
+	block := api.eth.blockchain.GetBlockByHash(hash)
+	if block == nil {
+		return nil, fmt.Errorf("block %#x not found", hash)
+	}
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not executable")
+	}
+	parent := api.eth.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %#x not found", block.ParentHash())
+	}
+	statedb, err := api.eth.stateAtBlock(parent, accessListReexec, nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return nil, err
+	}
+	var (
+		profile = &BlockImportProfile{Number: hexutil.Uint64(block.NumberU64()), Hash: hash}
+		labels  = pprof.Labels("block", hash.Hex())
+	)
+	pprof.Do(ctx, labels, func(context.Context) {
+		start := time.Now()
+		var (
+			receipts types.Receipts
+			usedGas  uint64
+		)
+		receipts, _, usedGas, err = api.eth.blockchain.Processor().Process(block, statedb, *api.eth.blockchain.GetVMConfig())
+		profile.ExecutionTime = float64(time.Since(start)) / float64(time.Millisecond)
+		if err != nil {
+			return
+		}
+		start = time.Now()
+		err = api.eth.blockchain.Validator().ValidateState(block, statedb, receipts, usedGas)
+		profile.ValidationTime = float64(time.Since(start)) / float64(time.Millisecond)
+	})
+	pprof.StopCPUProfile()
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		profile.Profile = buf.Bytes()
+		return profile, nil
+	}
+	if err := os.WriteFile(*file, buf.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+	profile.File = *file
+	log.Info("Wrote block import CPU profile", "number", block.NumberU64(), "hash", hash, "file", *file)
+	return profile, nil
+}
+
+// StateAccessStats is the number of tagged accounts and storage slots by the
+// epoch they were last accessed in.
+type StateAccessStats struct {
+	EpochLength  hexutil.Uint64            `json:"epochLength"`
+	CurrentEpoch hexutil.Uint64            `json:"currentEpoch"`
+	Accounts     map[uint64]hexutil.Uint64 `json:"accounts"`
+	Slots        map[uint64]hexutil.Uint64 `json:"slots"`
+}
+
+// StateAccessStats returns how many accounts and storage slots were last
+// accessed in each epoch, as tagged at import time (see --accessepoch). The
+// whole tag index is scanned, which may take a while.
+func (api *PrivateDebugAPI) StateAccessStats(ctx context.Context) (*StateAccessStats, error) {
+	length := api.eth.config.AccessEpochLength
+	if length == 0 {
+		return nil, errors.New("state access epochs are not tagged")
+	}
+	stats := &StateAccessStats{
+		EpochLength:  hexutil.Uint64(length),
+		CurrentEpoch: hexutil.Uint64(api.eth.blockchain.CurrentBlock().NumberU64() / length),
+		Accounts:     make(map[uint64]hexutil.Uint64),
+		Slots:        make(map[uint64]hexutil.Uint64),
+	}
+	err := rawdb.IterateAccessEpochs(api.eth.ChainDb(), common.Address{}, func(addr common.Address, slot *common.Hash, epoch uint64) bool {
+		if slot == nil {
+			stats.Accounts[epoch]++
+		} else {
+			stats.Slots[epoch]++
+		}
+		return ctx.Err() == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, ctx.Err()
+}
+
+// StaleStateEntry is an account, or a storage slot of it, not accessed for a
+// number of epochs.
+type StaleStateEntry struct {
+	Address   common.Address `json:"address"`
+	Slot      *common.Hash   `json:"slot,omitempty"`
+	LastEpoch hexutil.Uint64 `json:"lastEpoch"`
+}
+
+// StaleStateResult is a page of stale state candidates. Next is the address to
+// continue from, nil if there are no more candidates.
+type StaleStateResult struct {
+	Entries []StaleStateEntry `json:"entries"`
+	Next    *common.Address   `json:"next"`
+}
+
+// GetStaleState returns the accounts and storage slots not accessed in the last
+// staleEpochs epochs, in address order from the given address on (see
+// --accessepoch). Pages end on account boundaries after at least maxResults
+// entries, so the slots of an account are never split across pages.
+func (api *PrivateDebugAPI) GetStaleState(ctx context.Context, staleEpochs hexutil.Uint64, start common.Address, maxResults int) (*StaleStateResult, error) {
+	length := api.eth.config.AccessEpochLength
+	if length == 0 {
+		return nil, errors.New("state access epochs are not tagged")
+	}
+	if maxResults <= 0 || maxResults > AccountRangeMaxResults {
+		maxResults = AccountRangeMaxResults
+	}
+	current := api.eth.blockchain.CurrentBlock().NumberU64() / length
+	if uint64(staleEpochs) > current {
+		return &StaleStateResult{Entries: []StaleStateEntry{}}, nil
+	}
+	var (
+		cutoff = current - uint64(staleEpochs)
+		result = &StaleStateResult{Entries: []StaleStateEntry{}}
+		last   common.Address
+	)
+	err := rawdb.IterateAccessEpochs(api.eth.ChainDb(), start, func(addr common.Address, slot *common.Hash, epoch uint64) bool {
+		if addr != last && len(result.Entries) >= maxResults {
+			result.Next = &addr
+			return false
+		}
+		last = addr
+		if epoch <= cutoff {
+			result.Entries = append(result.Entries, StaleStateEntry{Address: addr, Slot: slot, LastEpoch: hexutil.Uint64(epoch)})
+		}
+		return ctx.Err() == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, ctx.Err()
+}
+
 // BadBlockArgs represents the entries in the list returned when bad blocks are queried.
 type BadBlockArgs struct {
 	Hash  common.Hash            `json:"hash"`
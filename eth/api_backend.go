@@ -25,6 +25,7 @@ import (
 	"github.com/scroll-tech/go-ethereum"
 	"github.com/scroll-tech/go-ethereum/accounts"
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
 	"github.com/scroll-tech/go-ethereum/consensus"
 	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/bloombits"
@@ -35,6 +36,8 @@ import (
 	"github.com/scroll-tech/go-ethereum/eth/gasprice"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/internal/ethapi"
+	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/miner"
 	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/scroll-tech/go-ethereum/rpc"
@@ -46,6 +49,8 @@ type EthAPIBackend struct {
 	allowUnprotectedTxs bool
 	eth                 *Ethereum
 	gpo                 *gasprice.Oracle
+	responseCache       *ethapi.ResponseCache
+	historicalState     ethapi.HistoricalStateProvider
 }
 
 // ChainConfig returns the active chain configuration.
@@ -61,9 +66,9 @@ func (b *EthAPIBackend) CurrentBlock() *types.Block {
 	return b.eth.blockchain.CurrentBlock()
 }
 
-func (b *EthAPIBackend) SetHead(number uint64) {
+func (b *EthAPIBackend) SetHead(number uint64) error {
 	b.eth.handler.downloader.Cancel()
-	b.eth.blockchain.SetHead(number)
+	return b.eth.blockchain.SetHead(number)
 }
 
 func (b *EthAPIBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
@@ -76,6 +81,13 @@ func (b *EthAPIBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumb
 	if number == rpc.LatestBlockNumber {
 		return b.eth.blockchain.CurrentBlock().Header(), nil
 	}
+	if number == rpc.FinalizedBlockNumber {
+		block := b.eth.blockchain.CurrentFinalizedBlock()
+		if block == nil {
+			return nil, errors.New("finalized block not found")
+		}
+		return block.Header(), nil
+	}
 	return b.eth.blockchain.GetHeaderByNumber(uint64(number)), nil
 }
 
@@ -110,6 +122,13 @@ func (b *EthAPIBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumbe
 	if number == rpc.LatestBlockNumber {
 		return b.eth.blockchain.CurrentBlock(), nil
 	}
+	if number == rpc.FinalizedBlockNumber {
+		block := b.eth.blockchain.CurrentFinalizedBlock()
+		if block == nil {
+			return nil, errors.New("finalized block not found")
+		}
+		return block, nil
+	}
 	return b.eth.blockchain.GetBlockByNumber(uint64(number)), nil
 }
 
@@ -227,6 +246,10 @@ func (b *EthAPIBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Sub
 	return b.eth.BlockChain().SubscribeChainEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeFinalizedEvent(ch chan<- core.FinalizedEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeFinalizedEvent(ch)
+}
+
 func (b *EthAPIBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
 	return b.eth.BlockChain().SubscribeChainHeadEvent(ch)
 }
@@ -259,9 +282,52 @@ func (b *EthAPIBackend) GetPoolTransaction(hash common.Hash) *types.Transaction
 
 func (b *EthAPIBackend) GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
 	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(b.eth.ChainDb(), txHash)
+	if tx == nil && b.historicalState != nil {
+		// The transaction may be in a block whose transactions aren't indexed
+		// anymore, ask the historical state provider where to find it
+		if tail := rawdb.ReadTxIndexTail(b.eth.ChainDb()); tail != nil && *tail > 0 {
+			return b.getHistoricalTransaction(ctx, txHash, *tail)
+		}
+	}
 	return tx, blockHash, blockNumber, index, nil
 }
 
+// errInvalidHistoricalLookup is returned if the position of a transaction served
+// by the historical state provider doesn't match the local chain.
+var errInvalidHistoricalLookup = errors.New("invalid transaction lookup from historical state provider")
+
+// getHistoricalTransaction looks up a transaction of a block below the tail of
+// the transaction index through the historical state provider. Only the position
+// of the transaction is taken from the provider, the transaction itself is read
+// from the local chain.
+func (b *EthAPIBackend) getHistoricalTransaction(ctx context.Context, txHash common.Hash, tail uint64) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	var lookup *struct {
+		BlockHash   *common.Hash    `json:"blockHash"`
+		BlockNumber *hexutil.Uint64 `json:"blockNumber"`
+		Index       *hexutil.Uint64 `json:"transactionIndex"`
+	}
+	if err := b.historicalState.CallContext(ctx, &lookup, "eth_getTransactionByHash", txHash); err != nil {
+		return nil, common.Hash{}, 0, 0, err
+	}
+	if lookup == nil || lookup.BlockHash == nil || lookup.BlockNumber == nil || lookup.Index == nil {
+		return nil, common.Hash{}, 0, 0, nil // Unknown or pending
+	}
+	number, index := uint64(*lookup.BlockNumber), uint64(*lookup.Index)
+	if number >= tail || rawdb.ReadCanonicalHash(b.eth.ChainDb(), number) != *lookup.BlockHash {
+		return nil, common.Hash{}, 0, 0, nil // Indexed locally or not canonical here
+	}
+	block := b.eth.blockchain.GetBlock(*lookup.BlockHash, number)
+	if block == nil {
+		return nil, common.Hash{}, 0, 0, nil
+	}
+	txs := block.Transactions()
+	if index >= uint64(len(txs)) || txs[index].Hash() != txHash {
+		log.Warn("Invalid historical transaction lookup", "hash", txHash, "number", number, "index", index)
+		return nil, common.Hash{}, 0, 0, errInvalidHistoricalLookup
+	}
+	return txs[index], *lookup.BlockHash, number, index, nil
+}
+
 func (b *EthAPIBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
 	return b.eth.txPool.Nonce(addr), nil
 }
@@ -318,6 +384,14 @@ func (b *EthAPIBackend) UnprotectedAllowed() bool {
 	return b.allowUnprotectedTxs
 }
 
+func (b *EthAPIBackend) RPCResponseCache() *ethapi.ResponseCache {
+	return b.responseCache
+}
+
+func (b *EthAPIBackend) HistoricalState() ethapi.HistoricalStateProvider {
+	return b.historicalState
+}
+
 func (b *EthAPIBackend) RPCGasCap() uint64 {
 	return b.eth.config.RPCGasCap
 }
@@ -0,0 +1,159 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"net/url"
+
+	"github.com/scroll-tech/go-ethereum/eth/ethconfig"
+	"github.com/scroll-tech/go-ethereum/node"
+)
+
+// redacted replaces the parts of configuration values that may hold secrets.
+const redacted = "<redacted>"
+
+// NodeConfig is the resolved runtime configuration of a node, with secrets like
+// the credentials in endpoint URLs redacted.
+type NodeConfig struct {
+	Node     NodeSettings      `json:"node"`
+	Eth      *ethconfig.Config `json:"eth"`
+	Services []string          `json:"services"` // Optional services enabled on the node
+}
+
+// NodeSettings are the settings of the node hosting the Ethereum service.
+type NodeSettings struct {
+	Name     string `json:"name"`
+	DataDir  string `json:"dataDir"`
+	DBEngine string `json:"dbEngine"`
+
+	IPCPath      string   `json:"ipcPath"`
+	HTTPEndpoint string   `json:"httpEndpoint"`
+	HTTPModules  []string `json:"httpModules"`
+	WSEndpoint   string   `json:"wsEndpoint"`
+	WSModules    []string `json:"wsModules"`
+
+	RPCWorkers         int      `json:"rpcWorkers"`
+	RPCPriorityModules []string `json:"rpcPriorityModules"`
+	RPCBatchItems      int      `json:"rpcBatchItems"`
+	RPCBatchCost       int      `json:"rpcBatchCost"`
+	RPCConnCalls       int      `json:"rpcConnCalls"`
+
+	MaxPeers       int    `json:"maxPeers"`
+	ListenAddr     string `json:"listenAddr"`
+	NoDiscovery    bool   `json:"noDiscovery"`
+	ExternalSigner string `json:"externalSigner"`
+}
+
+// NodeConfig returns the resolved runtime configuration of the node, to debug
+// misconfigured nodes remotely. Endpoint URLs are stripped of their credentials,
+// paths and queries, which often carry API keys, and private keys are omitted.
+func (api *PrivateAdminAPI) NodeConfig() *NodeConfig {
+	return newNodeConfig(api.eth.nodeConfig, api.eth.config, api.eth.IsMining())
+}
+
+// newNodeConfig assembles the redacted configuration of a node.
+func newNodeConfig(stack *node.Config, config *ethconfig.Config, mining bool) *NodeConfig {
+	eth := *config
+	eth.Genesis = nil // Exported by scroll_getChainSpec
+	eth.Ethash.Log = nil
+	eth.Miner.Notify = make([]string, len(config.Miner.Notify))
+	for i, notify := range config.Miner.Notify {
+		eth.Miner.Notify[i] = redactURL(notify)
+	}
+	eth.HistoricalStateURL = redactURL(config.HistoricalStateURL)
+	eth.Replica = redactURL(config.Replica)
+	eth.L1Endpoint = redactURL(config.L1Endpoint)
+
+	result := &NodeConfig{Eth: &eth, Services: nodeServices(&eth, mining)}
+	if stack != nil {
+		result.Node = NodeSettings{
+			Name:               stack.Name,
+			DataDir:            stack.DataDir,
+			DBEngine:           stack.DBEngine,
+			IPCPath:            stack.IPCEndpoint(),
+			HTTPEndpoint:       stack.HTTPEndpoint(),
+			HTTPModules:        stack.HTTPModules,
+			WSEndpoint:         stack.WSEndpoint(),
+			WSModules:          stack.WSModules,
+			RPCWorkers:         stack.RPCWorkers,
+			RPCPriorityModules: stack.RPCPriorityModules,
+			RPCBatchItems:      stack.RPCBatchItems,
+			RPCBatchCost:       stack.RPCBatchCost,
+			RPCConnCalls:       stack.RPCConnCalls,
+			MaxPeers:           stack.P2P.MaxPeers,
+			ListenAddr:         stack.P2P.ListenAddr,
+			NoDiscovery:        stack.P2P.NoDiscovery,
+			ExternalSigner:     redactURL(stack.ExternalSigner),
+		}
+		if stack.HTTPHost == "" {
+			result.Node.HTTPEndpoint = ""
+		}
+		if stack.WSHost == "" {
+			result.Node.WSEndpoint = ""
+		}
+	}
+	return result
+}
+
+// nodeServices returns the optional services enabled by the given config.
+func nodeServices(config *ethconfig.Config, mining bool) []string {
+	services := []string{}
+	for _, service := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"mining", mining},
+		{"replica", config.Replica != ""},
+		{"l1Finality", config.L1Endpoint != ""},
+		{"historicalState", config.HistoricalStateURL != ""},
+		{"memoryBudget", config.MemoryBudget != 0},
+		{"snapshot", config.SnapshotCache != 0},
+		{"preimages", config.Preimages},
+		{"withdrawTrie", config.WithdrawTrie},
+		{"logIndex", config.LogIndex},
+		{"callTraces", config.RecordCallTraces},
+		{"accessLists", config.RecordAccessLists},
+		{"blockStats", config.RecordBlockStats},
+		{"contractUsage", config.RecordContractUsage},
+		{"stateSize", config.RecordStateSize},
+		{"creationIndex", config.CreationIndex},
+		{"accessEpochs", config.AccessEpochLength != 0},
+		{"responseCache", config.RPCResponseCache != 0},
+	} {
+		if service.enabled {
+			services = append(services, service.name)
+		}
+	}
+	return services
+}
+
+// redactURL strips an endpoint URL down to its scheme and host, marking any
+// credentials, path or query as redacted. Values not parsing as URLs with a
+// host, like IPC paths, are kept.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	if u.Host == "" {
+		return raw
+	}
+	if u.User == nil && (u.Path == "" || u.Path == "/") && u.RawQuery == "" && u.Fragment == "" {
+		return raw
+	}
+	return u.Scheme + "://" + u.Host + "/" + redacted
+}
@@ -0,0 +1,1489 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/consensus"
+	"github.com/scroll-tech/go-ethereum/consensus/misc"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/crypto/codehash"
+	"github.com/scroll-tech/go-ethereum/eth/gasprice"
+	"github.com/scroll-tech/go-ethereum/internal/ethapi"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/rollup/fees"
+	"github.com/scroll-tech/go-ethereum/rollup/l1origin"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// PublicScrollAPI provides an API to access Scroll specific chain data.
+type PublicScrollAPI struct {
+	e *Ethereum
+}
+
+// NewPublicScrollAPI creates a new Scroll API for full nodes.
+func NewPublicScrollAPI(e *Ethereum) *PublicScrollAPI {
+	return &PublicScrollAPI{e}
+}
+
+// WithdrawalProof is the Merkle proof of an L2 to L1 message against the
+// withdraw trie root of a block.
+type WithdrawalProof struct {
+	BlockNumber  hexutil.Uint64 `json:"blockNumber"`
+	BlockHash    common.Hash    `json:"blockHash"`
+	Index        hexutil.Uint64 `json:"index"`
+	MessageHash  common.Hash    `json:"messageHash"`
+	WithdrawRoot common.Hash    `json:"withdrawRoot"`
+	Proof        []common.Hash  `json:"proof"`
+}
+
+// GetWithdrawalProof returns the proof of the L2 to L1 message with the given
+// index against the withdraw trie root of the current head block.
+func (api *PublicScrollAPI) GetWithdrawalProof(index hexutil.Uint64) (*WithdrawalProof, error) {
+	header, proof, err := api.e.blockchain.WithdrawalProof(uint64(index))
+	if err != nil {
+		return nil, err
+	}
+	return &WithdrawalProof{
+		BlockNumber:  hexutil.Uint64(header.Number.Uint64()),
+		BlockHash:    header.Hash(),
+		Index:        index,
+		MessageHash:  proof.MessageHash,
+		WithdrawRoot: proof.Root,
+		Proof:        proof.Siblings,
+	}, nil
+}
+
+// L1Origin is the L1 block an L2 block was built on.
+type L1Origin struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	L1Number    hexutil.Uint64 `json:"l1Number"`
+	L1Hash      common.Hash    `json:"l1Hash"`
+	L1Timestamp hexutil.Uint64 `json:"l1Timestamp"`
+	L1BaseFee   *hexutil.Big   `json:"l1BaseFee"`
+}
+
+// GetL1Origin returns the L1 origin written into the given canonical block, or
+// nil if the block doesn't set one.
+func (api *PublicScrollAPI) GetL1Origin(blockNumber rpc.BlockNumber) (*L1Origin, error) {
+	var block *types.Block
+	switch blockNumber {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		block = api.e.blockchain.CurrentBlock()
+	default:
+		block = api.e.blockchain.GetBlockByNumber(uint64(blockNumber.Int64()))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNumber)
+	}
+	origin := l1origin.FromBlock(block)
+	if origin == nil {
+		return nil, nil
+	}
+	return &L1Origin{
+		BlockNumber: hexutil.Uint64(block.NumberU64()),
+		BlockHash:   block.Hash(),
+		L1Number:    hexutil.Uint64(origin.Number),
+		L1Hash:      origin.Hash,
+		L1Timestamp: hexutil.Uint64(origin.Time),
+		L1BaseFee:   (*hexutil.Big)(origin.BaseFee),
+	}, nil
+}
+
+// ExtraData is the structured extra-data of a block.
+type ExtraData struct {
+	BlockNumber   hexutil.Uint64 `json:"blockNumber"`
+	BlockHash     common.Hash    `json:"blockHash"`
+	Version       hexutil.Uint64 `json:"version"`
+	ProposerIndex hexutil.Uint64 `json:"proposerIndex"`
+}
+
+// GetExtraData returns the parsed structured extra-data of the given canonical
+// block, or nil if the block predates the structured layout.
+func (api *PublicScrollAPI) GetExtraData(blockNumber rpc.BlockNumber) (*ExtraData, error) {
+	var block *types.Block
+	switch blockNumber {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		block = api.e.blockchain.CurrentBlock()
+	default:
+		block = api.e.blockchain.GetBlockByNumber(uint64(blockNumber.Int64()))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNumber)
+	}
+	if !api.e.blockchain.Config().Scroll.IsExtraSchema(block.Number()) {
+		return nil, nil
+	}
+	extra, err := types.DecodeL2Extra(block.Extra())
+	if err != nil {
+		return nil, err
+	}
+	return &ExtraData{
+		BlockNumber:   hexutil.Uint64(block.NumberU64()),
+		BlockHash:     block.Hash(),
+		Version:       hexutil.Uint64(extra.Version),
+		ProposerIndex: hexutil.Uint64(extra.ProposerIndex),
+	}, nil
+}
+
+// Statuses of a transaction as reported by GetTransactionStatus.
+const (
+	TxStatusUnknown   = "unknown"   // Neither in the pool nor in a canonical block
+	TxStatusDropped   = "dropped"   // Recently dropped from the transaction pool without being included
+	TxStatusPending   = "pending"   // Waiting in the transaction pool
+	TxStatusUnsafe    = "unsafe"    // Included in a canonical block not finalized on L1 yet
+	TxStatusFinalized = "finalized" // Included in a canonical block finalized on L1
+)
+
+// TransactionStatus is the progress of a transaction towards L1 finality.
+type TransactionStatus struct {
+	Status      string          `json:"status"`
+	BlockNumber *hexutil.Uint64 `json:"blockNumber,omitempty"`
+	BlockHash   *common.Hash    `json:"blockHash,omitempty"`
+	Index       *hexutil.Uint64 `json:"transactionIndex,omitempty"`
+	Reason      string          `json:"reason,omitempty"` // Why the transaction was dropped (see core.TxDropExpired and friends)
+}
+
+// GetTransactionStatus returns how far the transaction with the given hash got
+// towards L1 finality, sparing the caller the combined lookups of the pool, the
+// canonical chain and the finalized block.
+func (api *PublicScrollAPI) GetTransactionStatus(hash common.Hash) *TransactionStatus {
+	if tx, blockHash, blockNumber, index := rawdb.ReadTransaction(api.e.ChainDb(), hash); tx != nil {
+		status := &TransactionStatus{
+			Status:      TxStatusUnsafe,
+			BlockNumber: (*hexutil.Uint64)(&blockNumber),
+			BlockHash:   &blockHash,
+			Index:       (*hexutil.Uint64)(&index),
+		}
+		if finalized := api.e.blockchain.CurrentFinalizedBlock(); finalized != nil && finalized.NumberU64() >= blockNumber {
+			status.Status = TxStatusFinalized
+		}
+		return status
+	}
+	if api.e.txPool != nil {
+		if api.e.txPool.Get(hash) != nil {
+			return &TransactionStatus{Status: TxStatusPending}
+		}
+		if reason := api.e.txPool.DropReason(hash); reason != "" {
+			return &TransactionStatus{Status: TxStatusDropped, Reason: reason}
+		}
+	}
+	return &TransactionStatus{Status: TxStatusUnknown}
+}
+
+// maxRawTransactions is the maximum number of transactions SendRawTransactions
+// accepts in a single call.
+const maxRawTransactions = 1024
+
+// Outcomes of a transaction submission as reported by SendRawTransactions.
+const (
+	SubmitRejected = "rejected" // Refused by the node or the pool, see the error
+	SubmitQueued   = "queued"   // Admitted to the pool, not executable yet
+	SubmitPending  = "pending"  // Admitted to the pool and executable
+)
+
+// SubmitResult is the outcome of submitting a single transaction of a batch.
+type SubmitResult struct {
+	Hash     *common.Hash       `json:"hash,omitempty"` // Missing if the transaction couldn't be decoded
+	Status   string             `json:"status"`
+	Error    string             `json:"error,omitempty"`
+	Position *hexutil.Uint64    `json:"position,omitempty"` // Executable transactions paying a higher tip, picked first by block producers
+	Estimate *InclusionEstimate `json:"estimate,omitempty"` // When an executable transaction should be included
+}
+
+// InclusionEstimate is the block an executable transaction is expected to be
+// included in, assuming no better paying transactions arrive in the meantime.
+type InclusionEstimate struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Timestamp   hexutil.Uint64 `json:"timestamp"`
+}
+
+// SendRawTransactionWithEstimate admits a signed transaction to the pool like
+// eth_sendRawTransaction, also returning its position in the pool and, if it's
+// executable, when it should be included.
+func (api *PublicScrollAPI) SendRawTransactionWithEstimate(input hexutil.Bytes) (*SubmitResult, error) {
+	results, err := api.SendRawTransactions([]hexutil.Bytes{input})
+	if err != nil {
+		return nil, err
+	}
+	if results[0].Status == SubmitRejected {
+		return nil, errors.New(results[0].Error)
+	}
+	return results[0], nil
+}
+
+// SendRawTransactions validates and admits a batch of signed transactions to
+// the pool at once, returning the outcome of each in order. Transactions which
+// are rejected don't affect the others.
+func (api *PublicScrollAPI) SendRawTransactions(inputs []hexutil.Bytes) ([]*SubmitResult, error) {
+	if len(inputs) > maxRawTransactions {
+		return nil, fmt.Errorf("too many transactions: have %d, max %d", len(inputs), maxRawTransactions)
+	}
+	var (
+		results = make([]*SubmitResult, len(inputs))
+		txs     = make([]*types.Transaction, 0, len(inputs))
+		indexes = make([]int, 0, len(inputs)) // Result index of each transaction submitted
+	)
+	for i, input := range inputs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(input); err != nil {
+			results[i] = &SubmitResult{Status: SubmitRejected, Error: err.Error()}
+			continue
+		}
+		hash := tx.Hash()
+		results[i] = &SubmitResult{Hash: &hash}
+		if err := ethapi.CheckSubmission(api.e.APIBackend, tx); err != nil {
+			results[i].Status, results[i].Error = SubmitRejected, err.Error()
+			continue
+		}
+		txs = append(txs, tx)
+		indexes = append(indexes, i)
+	}
+	var (
+		errs     = api.e.txPool.AddLocals(txs)
+		admitted []common.Hash
+	)
+	for j, err := range errs {
+		if err != nil {
+			results[indexes[j]].Status, results[indexes[j]].Error = SubmitRejected, err.Error()
+			continue
+		}
+		admitted = append(admitted, txs[j].Hash())
+	}
+	// Rank the executable transactions admitted among the pending ones
+	var (
+		head    = api.e.blockchain.CurrentBlock().Header()
+		baseFee *big.Int
+		queue   *pendingQueue
+	)
+	if config := api.e.blockchain.Config(); config.IsLondon(new(big.Int).Add(head.Number, common.Big1)) {
+		baseFee = misc.CalcBaseFee(config, head)
+	}
+	statuses := api.e.txPool.Status(admitted)
+	for _, status := range statuses {
+		if status == core.TxStatusPending {
+			queue = api.pendingQueue(baseFee)
+			break
+		}
+	}
+	j := 0
+	for _, result := range results {
+		if result.Status == SubmitRejected {
+			continue
+		}
+		switch statuses[j] {
+		case core.TxStatusPending:
+			result.Status = SubmitPending
+			if tx := api.e.txPool.Get(*result.Hash); tx != nil {
+				position := queue.position(tx.EffectiveGasTipValue(baseFee))
+				result.Position = (*hexutil.Uint64)(&position)
+				result.Estimate = api.estimateInclusion(head, position, queue.gasAhead(position)+tx.Gas())
+			}
+		case core.TxStatusQueued:
+			result.Status = SubmitQueued
+		default:
+			// Already included or dropped in the meantime
+			result.Status, result.Error = SubmitRejected, "transaction left the pool"
+		}
+		j++
+	}
+	log.Info("Submitted transaction batch", "count", len(inputs), "admitted", len(admitted))
+	return results, nil
+}
+
+// pendingQueue is the order block producers pick the executable transactions
+// of the pool in, by effective tip.
+type pendingQueue struct {
+	tips []*big.Int // Effective tips, highest first
+	gas  []uint64   // Gas of the transactions up to each, inclusive
+}
+
+// pendingQueue ranks the executable transactions in the pool.
+func (api *PublicScrollAPI) pendingQueue(baseFee *big.Int) *pendingQueue {
+	type entry struct {
+		tip *big.Int
+		gas uint64
+	}
+	var entries []entry
+	for _, txs := range api.e.txPool.Pending(false) {
+		for _, tx := range txs {
+			entries = append(entries, entry{tx.EffectiveGasTipValue(baseFee), tx.Gas()})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].tip.Cmp(entries[j].tip) > 0 })
+
+	queue := &pendingQueue{tips: make([]*big.Int, len(entries)), gas: make([]uint64, len(entries))}
+	var gas uint64
+	for i, entry := range entries {
+		gas += entry.gas
+		queue.tips[i], queue.gas[i] = entry.tip, gas
+	}
+	return queue
+}
+
+// position returns the number of transactions paying a higher tip than given.
+func (q *pendingQueue) position(tip *big.Int) uint64 {
+	return uint64(sort.Search(len(q.tips), func(i int) bool { return q.tips[i].Cmp(tip) <= 0 }))
+}
+
+// gasAhead returns the gas of the transactions ahead of the given position.
+func (q *pendingQueue) gasAhead(position uint64) uint64 {
+	if position == 0 {
+		return 0
+	}
+	return q.gas[position-1]
+}
+
+// estimateInclusion estimates the block a transaction is included in, given
+// the number of transactions ahead of it and the gas used up to it inclusive,
+// from the block capacity and the block time the sequencer aims for.
+func (api *PublicScrollAPI) estimateInclusion(head *types.Header, position uint64, gas uint64) *InclusionEstimate {
+	config := api.e.blockchain.Config()
+
+	// Blocks filled before the one including the transaction
+	var ahead uint64
+	if max := config.Scroll.MaxTxPerBlock; max != nil && *max > 0 {
+		ahead = position / uint64(*max)
+	}
+	if head.GasLimit > 0 {
+		if blocks := (gas - 1) / head.GasLimit; blocks > ahead {
+			ahead = blocks
+		}
+	}
+	// Space the blocks by the engine period, stretched to the block time rules
+	var period uint64
+	if config.Clique != nil {
+		period = config.Clique.Period
+	}
+	next := misc.CalcBlockTime(config, head, period, uint64(time.Now().Unix()))
+	interval := misc.CalcBlockTime(config, head, period, 0) - head.Time
+	if interval == 0 {
+		interval = 1
+	}
+	return &InclusionEstimate{
+		BlockNumber: hexutil.Uint64(head.Number.Uint64() + 1 + ahead),
+		Timestamp:   hexutil.Uint64(next + ahead*interval),
+	}
+}
+
+// NonceGap is a run of nonces missing in front of queued transactions.
+type NonceGap struct {
+	From hexutil.Uint64 `json:"from"`
+	To   hexutil.Uint64 `json:"to"` // Inclusive
+}
+
+// PooledTx identifies a transaction waiting in the pool, with the fees its
+// replacement must pay if it's stuck.
+type PooledTx struct {
+	Hash         common.Hash    `json:"hash"`
+	Nonce        hexutil.Uint64 `json:"nonce"`
+	MinGasFeeCap *hexutil.Big   `json:"minMaxFeePerGas,omitempty"`
+	MinGasTipCap *hexutil.Big   `json:"minMaxPriorityFeePerGas,omitempty"`
+}
+
+// NonceGaps is what keeps the transactions of an account from being included.
+type NonceGaps struct {
+	Address common.Address `json:"address"`
+	Nonce   hexutil.Uint64 `json:"nonce"`   // Next nonce after the executable transactions
+	Gaps    []NonceGap     `json:"gaps"`    // Nonces missing in front of the queued transactions
+	Blocked []PooledTx     `json:"blocked"` // Queued transactions waiting for the gaps to fill
+	Stuck   []PooledTx     `json:"stuck"`   // Executable transactions priced below what inclusion requires
+}
+
+// GetNonceGaps reports the nonces an account must fill for its queued
+// transactions to become executable, and the fees to replace its executable
+// transactions which are priced too low to be included.
+func (api *PublicScrollAPI) GetNonceGaps(address common.Address) (*NonceGaps, error) {
+	if api.e.txPool == nil {
+		return nil, errors.New("transaction pool not available")
+	}
+	next, gaps, blocked := api.e.txPool.NonceGaps(address)
+	result := &NonceGaps{
+		Address: address,
+		Nonce:   hexutil.Uint64(next),
+		Gaps:    make([]NonceGap, 0, len(gaps)),
+		Blocked: make([]PooledTx, 0, len(blocked)),
+		Stuck:   []PooledTx{},
+	}
+	for _, gap := range gaps {
+		result.Gaps = append(result.Gaps, NonceGap{From: hexutil.Uint64(gap.From), To: hexutil.Uint64(gap.To)})
+	}
+	for _, tx := range blocked {
+		result.Blocked = append(result.Blocked, PooledTx{Hash: tx.Hash(), Nonce: hexutil.Uint64(tx.Nonce())})
+	}
+	for _, tx := range api.e.txPool.StuckTxs(address) {
+		feeCap, tip := api.e.txPool.ReplacementFees(tx)
+		result.Stuck = append(result.Stuck, PooledTx{
+			Hash:         tx.Hash(),
+			Nonce:        hexutil.Uint64(tx.Nonce()),
+			MinGasFeeCap: (*hexutil.Big)(feeCap),
+			MinGasTipCap: (*hexutil.Big)(tip),
+		})
+	}
+	return result, nil
+}
+
+// TxIndexProgress is the progress of the background transaction indexer.
+type TxIndexProgress struct {
+	Tail      hexutil.Uint64 `json:"tail"`  // Oldest block whose transactions are indexed
+	Limit     hexutil.Uint64 `json:"limit"` // Number of recent blocks to index, 0 for all
+	Indexed   hexutil.Uint64 `json:"indexed"`
+	Remaining hexutil.Uint64 `json:"remaining"`
+}
+
+// TxIndexProgress returns the progress of the transaction indexer, as the
+// transactions of the blocks below the tail can only be looked up through the
+// historical state provider (see --rpc.historicalstate), if configured.
+func (api *PublicScrollAPI) TxIndexProgress() (*TxIndexProgress, error) {
+	progress, err := api.e.blockchain.TxIndexProgress()
+	if err != nil {
+		return nil, err
+	}
+	return &TxIndexProgress{
+		Tail:      hexutil.Uint64(progress.Tail),
+		Limit:     hexutil.Uint64(progress.Limit),
+		Indexed:   hexutil.Uint64(progress.Indexed),
+		Remaining: hexutil.Uint64(progress.Remaining),
+	}, nil
+}
+
+// ContractCreation is the preimage of a contract address and the transaction
+// creating it.
+type ContractCreation struct {
+	Address      common.Address  `json:"address"`
+	Creator      common.Address  `json:"creator"`
+	Type         string          `json:"type"`
+	Nonce        *hexutil.Uint64 `json:"nonce,omitempty"`
+	Salt         *common.Hash    `json:"salt,omitempty"`
+	InitCodeHash common.Hash     `json:"initCodeHash"`
+	TxHash       common.Hash     `json:"transactionHash"`
+	BlockNumber  hexutil.Uint64  `json:"blockNumber"`
+	BlockHash    common.Hash     `json:"blockHash"`
+}
+
+// GetContractCreation returns how the contract at the given address was created
+// in the canonical chain, or nil if its creation isn't indexed (see
+// --creationindex).
+func (api *PublicScrollAPI) GetContractCreation(address common.Address) *ContractCreation {
+	creation := rawdb.ReadContractCreation(api.e.ChainDb(), address)
+	if creation == nil {
+		return nil
+	}
+	tx, blockHash, blockNumber, _ := rawdb.ReadTransaction(api.e.ChainDb(), creation.TxHash)
+	if tx == nil || blockNumber != creation.BlockNumber {
+		return nil // Reorged away
+	}
+	result := &ContractCreation{
+		Address:      creation.Address,
+		Creator:      creation.Creator,
+		Type:         vm.CREATE.String(),
+		Salt:         creation.Salt,
+		InitCodeHash: creation.InitCodeHash,
+		TxHash:       creation.TxHash,
+		BlockNumber:  hexutil.Uint64(blockNumber),
+		BlockHash:    blockHash,
+	}
+	if creation.Salt != nil {
+		result.Type = vm.CREATE2.String()
+	} else {
+		result.Nonce = (*hexutil.Uint64)(&creation.Nonce)
+	}
+	return result
+}
+
+// GetNonceKey returns the next nonce of the given nonce key of an account, like
+// eth_getTransactionCount does for the account nonce, which is the key 0. The
+// pending block includes the executable transactions of the pool.
+func (api *PublicScrollAPI) GetNonceKey(ctx context.Context, address common.Address, key hexutil.Uint64, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Uint64, error) {
+	if blockNr, ok := blockNrOrHash.Number(); ok && blockNr == rpc.PendingBlockNumber {
+		nonce := api.e.TxPool().NonceKey(address, uint64(key))
+		return (*hexutil.Uint64)(&nonce), nil
+	}
+	state, _, err := api.e.APIBackend.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	nonce := state.GetNonceKey(address, uint64(key))
+	return (*hexutil.Uint64)(&nonce), state.Error()
+}
+
+// ChainSpec is the effective configuration of the chain a node runs, which
+// nodes must agree on to follow the same chain.
+type ChainSpec struct {
+	Genesis    common.Hash         `json:"genesis"`
+	Config     *params.ChainConfig `json:"config"`
+	Predeploys ChainSpecPredeploys `json:"predeploys"`
+	Fees       ChainSpecFees       `json:"fees"`
+}
+
+// ChainSpecPredeploys are the system contracts the protocol reads or writes.
+type ChainSpecPredeploys struct {
+	L2MessageQueue   common.Address `json:"l2MessageQueue"`
+	L1GasPriceOracle common.Address `json:"l1GasPriceOracle"`
+	L1Block          common.Address `json:"l1Block"`
+	L1FeeRefund      common.Address `json:"l1FeeRefund"`
+	NonceManager     common.Address `json:"nonceManager"`
+}
+
+// ChainSpecFees are the parameters of the fee computation not part of the
+// chain config.
+type ChainSpecFees struct {
+	L1FeePrecision     *hexutil.Big   `json:"l1FeePrecision"`
+	BaseFeeDenominator hexutil.Uint64 `json:"baseFeeChangeDenominator"`
+	ElasticityMultiple hexutil.Uint64 `json:"elasticityMultiplier"`
+	InitialBaseFee     hexutil.Uint64 `json:"initialBaseFee"`
+}
+
+// ChainSpecResult is a chain spec in canonical JSON, along with its hash.
+type ChainSpecResult struct {
+	Spec json.RawMessage `json:"spec"`
+	Hash common.Hash     `json:"hash"`
+}
+
+// newChainSpec returns the canonical JSON encoding of the chain spec of the
+// given genesis and chain config, and its Keccak256 hash. The encoding is
+// compact, with struct fields in declaration order and map keys sorted, so
+// that nodes running identical configurations produce identical hashes.
+func newChainSpec(genesis common.Hash, config *params.ChainConfig) (*ChainSpecResult, error) {
+	spec, err := json.Marshal(&ChainSpec{
+		Genesis: genesis,
+		Config:  config,
+		Predeploys: ChainSpecPredeploys{
+			L2MessageQueue:   rcfg.L2MessageQueueAddress,
+			L1GasPriceOracle: rcfg.L1GasPriceOracleAddress,
+			L1Block:          rcfg.L1BlockAddress,
+			L1FeeRefund:      rcfg.L1FeeRefundAddress,
+			NonceManager:     rcfg.NonceManagerAddress,
+		},
+		Fees: ChainSpecFees{
+			L1FeePrecision:     (*hexutil.Big)(rcfg.Precision),
+			BaseFeeDenominator: params.BaseFeeChangeDenominator,
+			ElasticityMultiple: params.ElasticityMultiplier,
+			InitialBaseFee:     params.InitialBaseFee,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ChainSpecResult{Spec: spec, Hash: crypto.Keccak256Hash(spec)}, nil
+}
+
+// GetChainSpec returns the effective chain spec of the node, including any
+// fork overrides, so that operators can check they run the same configuration
+// by comparing the hashes.
+func (api *PublicScrollAPI) GetChainSpec() (*ChainSpecResult, error) {
+	return newChainSpec(api.e.blockchain.Genesis().Hash(), api.e.blockchain.Config())
+}
+
+// BlockStats is the resource usage recorded while importing a block. Durations
+// are in nanoseconds, hit rates are the share of state lookups served by the
+// state cache.
+type BlockStats struct {
+	BlockNumber    hexutil.Uint64 `json:"blockNumber"`
+	BlockHash      common.Hash    `json:"blockHash"`
+	GasUsed        hexutil.Uint64 `json:"gasUsed"`
+	Transactions   hexutil.Uint64 `json:"transactions"`
+	Execution      hexutil.Uint64 `json:"execution"`
+	StateRead      hexutil.Uint64 `json:"stateRead"`
+	Validation     hexutil.Uint64 `json:"validation"`
+	Commit         hexutil.Uint64 `json:"commit"`
+	Write          hexutil.Uint64 `json:"write"`
+	WriteBytes     hexutil.Uint64 `json:"writeBytes"`
+	TrieBytes      hexutil.Uint64 `json:"trieBytes"`
+	AccountHits    hexutil.Uint64 `json:"accountHits"`
+	AccountMisses  hexutil.Uint64 `json:"accountMisses"`
+	AccountHitRate float64        `json:"accountHitRate"`
+	StorageHits    hexutil.Uint64 `json:"storageHits"`
+	StorageMisses  hexutil.Uint64 `json:"storageMisses"`
+	StorageHitRate float64        `json:"storageHitRate"`
+}
+
+// GetBlockStats returns the resource usage recorded while importing the given
+// canonical block, or nil if none was recorded (see --recordblockstats).
+func (api *PublicScrollAPI) GetBlockStats(blockNumber rpc.BlockNumber) (*BlockStats, error) {
+	var block *types.Block
+	switch blockNumber {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		block = api.e.blockchain.CurrentBlock()
+	default:
+		block = api.e.blockchain.GetBlockByNumber(uint64(blockNumber.Int64()))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNumber)
+	}
+	stats := rawdb.ReadBlockStats(api.e.ChainDb(), block.Hash())
+	if stats == nil {
+		return nil, nil
+	}
+	return &BlockStats{
+		BlockNumber:    hexutil.Uint64(block.NumberU64()),
+		BlockHash:      block.Hash(),
+		GasUsed:        hexutil.Uint64(block.GasUsed()),
+		Transactions:   hexutil.Uint64(len(block.Transactions())),
+		Execution:      hexutil.Uint64(stats.Execution),
+		StateRead:      hexutil.Uint64(stats.StateRead),
+		Validation:     hexutil.Uint64(stats.Validation),
+		Commit:         hexutil.Uint64(stats.Commit),
+		Write:          hexutil.Uint64(stats.Write),
+		WriteBytes:     hexutil.Uint64(stats.WriteBytes),
+		TrieBytes:      hexutil.Uint64(stats.TrieBytes),
+		AccountHits:    hexutil.Uint64(stats.AccountHits),
+		AccountMisses:  hexutil.Uint64(stats.AccountMisses),
+		AccountHitRate: hitRate(stats.AccountHits, stats.AccountMisses),
+		StorageHits:    hexutil.Uint64(stats.StorageHits),
+		StorageMisses:  hexutil.Uint64(stats.StorageMisses),
+		StorageHitRate: hitRate(stats.StorageHits, stats.StorageMisses),
+	}, nil
+}
+
+// hitRate returns the share of hits among all lookups, zero if there were none.
+func hitRate(hits, misses uint64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// StateSize is the size of the state after a block, along with how much the
+// block changed it.
+type StateSize struct {
+	BlockNumber   hexutil.Uint64 `json:"blockNumber"`
+	BlockHash     common.Hash    `json:"blockHash"`
+	Complete      bool           `json:"complete"`  // Whether the whole state is counted, or only its growth since the base block
+	BaseBlock     hexutil.Uint64 `json:"baseBlock"` // First block whose changes are counted
+	Accounts      int64          `json:"accounts"`
+	Slots         int64          `json:"slots"`
+	CodeBytes     int64          `json:"codeBytes"`
+	AccountGrowth int64          `json:"accountGrowth"`
+	SlotGrowth    int64          `json:"slotGrowth"`
+	CodeGrowth    int64          `json:"codeGrowth"`
+}
+
+// GetStateSize returns the number of accounts, storage slots and code bytes in
+// the state after the given canonical block, or nil if the block was imported
+// without the state size recorded (see --recordstatesize). If the tracking was
+// enabled after genesis, the counts are the growth since the base block.
+func (api *PublicScrollAPI) GetStateSize(blockNumber rpc.BlockNumber) (*StateSize, error) {
+	var block *types.Block
+	switch blockNumber {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		block = api.e.blockchain.CurrentBlock()
+	default:
+		block = api.e.blockchain.GetBlockByNumber(uint64(blockNumber.Int64()))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNumber)
+	}
+	size := rawdb.ReadStateSize(api.e.ChainDb(), block.Hash())
+	if size == nil {
+		return nil, nil
+	}
+	result := &StateSize{
+		BlockNumber:   hexutil.Uint64(block.NumberU64()),
+		BlockHash:     block.Hash(),
+		Complete:      size.Base == 0,
+		BaseBlock:     hexutil.Uint64(size.Base),
+		Accounts:      size.Accounts(),
+		Slots:         size.Slots(),
+		CodeBytes:     size.CodeBytes(),
+		AccountGrowth: size.Accounts(),
+		SlotGrowth:    size.Slots(),
+		CodeGrowth:    size.CodeBytes(),
+	}
+	// The growth of the block is the difference with its parent, unless the
+	// counting starts at this block
+	if block.NumberU64() > size.Base {
+		if parent := rawdb.ReadStateSize(api.e.ChainDb(), block.ParentHash()); parent != nil {
+			result.AccountGrowth -= parent.Accounts()
+			result.SlotGrowth -= parent.Slots()
+			result.CodeGrowth -= parent.CodeBytes()
+		}
+	}
+	return result, nil
+}
+
+// StateMigration is the progress of the state migration to zktrie ahead of the
+// zktrie cutover.
+type StateMigration struct {
+	CutoverBlock hexutil.Uint64 `json:"cutoverBlock"`
+	BlockNumber  hexutil.Uint64 `json:"blockNumber"` // Block whose state is migrated
+	BlockHash    common.Hash    `json:"blockHash"`
+	Root         common.Hash    `json:"root"`      // MPT state root of the block
+	ZkRoot       common.Hash    `json:"zkRoot"`    // Zktrie root of the state migrated so far
+	Converted    bool           `json:"converted"` // Whether the state of the block is fully converted
+	Accounts     hexutil.Uint64 `json:"accounts"`  // Accounts converted by the initial conversion
+	Slots        hexutil.Uint64 `json:"slots"`     // Storage slots converted by the initial conversion
+	Behind       hexutil.Uint64 `json:"behind"`    // Blocks the migrated state is behind the head, up to the cutover
+	Ready        bool           `json:"ready"`     // Whether the chain can cross the cutover
+	Error        string         `json:"error,omitempty"`
+}
+
+// GetStateMigration returns the progress of the state migration to zktrie. The
+// first block past the cutover can only be imported once the state of its
+// parent is migrated.
+func (api *PublicScrollAPI) GetStateMigration() (*StateMigration, error) {
+	if api.e.migration == nil {
+		return nil, errors.New("no zktrie cutover scheduled")
+	}
+	var (
+		cutover = api.e.blockchain.Config().Scroll.ZktrieCutoverBlock.Uint64()
+		head    = api.e.blockchain.CurrentBlock().NumberU64()
+		result  = &StateMigration{CutoverBlock: hexutil.Uint64(cutover)}
+	)
+	if err := api.e.migration.Err(); err != nil {
+		result.Error = err.Error()
+	}
+	if head >= cutover {
+		head = cutover - 1
+	}
+	if header := api.e.blockchain.GetHeaderByNumber(cutover - 1); header != nil {
+		_, result.Ready = rawdb.ReadZktrieRoot(api.e.ChainDb(), header.Root)
+	}
+	progress := api.e.migration.Progress()
+	if progress == nil {
+		result.Behind = hexutil.Uint64(head + 1)
+		return result, nil
+	}
+	result.BlockNumber = hexutil.Uint64(progress.Number)
+	result.BlockHash = progress.Hash
+	result.Root = progress.Root
+	result.ZkRoot = progress.ZkRoot
+	result.Converted = progress.Converted
+	result.Accounts = hexutil.Uint64(progress.Accounts)
+	result.Slots = hexutil.Uint64(progress.Slots)
+	if progress.Number < head {
+		result.Behind = hexutil.Uint64(head - progress.Number)
+	}
+	if !progress.Converted && result.Behind == 0 {
+		result.Behind = 1
+	}
+	return result, nil
+}
+
+// maxL2BlocksRange is the maximum number of blocks returned by a single
+// GetL2BlocksByRange call.
+const maxL2BlocksRange = 1000
+
+// ExecutableL2Data is the execution payload of a committed block, in the format
+// accepted by the consensus_newBlock endpoint.
+type ExecutableL2Data struct {
+	BlockHash    common.Hash     `json:"blockHash"`
+	SigningRoot  common.Hash     `json:"signingRoot"` // Hash signed by the block producer
+	ParentHash   common.Hash     `json:"parentHash"`
+	Miner        common.Address  `json:"miner"`
+	StateRoot    common.Hash     `json:"stateRoot"`
+	Number       hexutil.Uint64  `json:"number"`
+	GasLimit     hexutil.Uint64  `json:"gasLimit"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	Timestamp    hexutil.Uint64  `json:"timestamp"`
+	ReceiptRoot  common.Hash     `json:"receiptsRoot"`
+	LogsBloom    hexutil.Bytes   `json:"logsBloom"`
+	Transactions []hexutil.Bytes `json:"transactions"`
+}
+
+// NewExecutableL2Data creates the execution payload of the given block, whose
+// signing root is defined by the given consensus engine.
+func NewExecutableL2Data(block *types.Block, engine consensus.Engine) (*ExecutableL2Data, error) {
+	txs := make([]hexutil.Bytes, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		blob, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		txs[i] = blob
+	}
+	return &ExecutableL2Data{
+		BlockHash:    block.Hash(),
+		SigningRoot:  engine.SealHash(block.Header()),
+		ParentHash:   block.ParentHash(),
+		Miner:        block.Coinbase(),
+		StateRoot:    block.Root(),
+		Number:       hexutil.Uint64(block.NumberU64()),
+		GasLimit:     hexutil.Uint64(block.GasLimit()),
+		GasUsed:      hexutil.Uint64(block.GasUsed()),
+		Timestamp:    hexutil.Uint64(block.Time()),
+		ReceiptRoot:  block.ReceiptHash(),
+		LogsBloom:    block.Bloom().Bytes(),
+		Transactions: txs,
+	}, nil
+}
+
+// GetL2BlocksByRange returns the execution payloads of the committed canonical
+// blocks from start to end inclusive, to backfill missing ranges in a single
+// call. The range is truncated at the current head, and may span at most
+// maxL2BlocksRange blocks.
+func (api *PublicScrollAPI) GetL2BlocksByRange(start, end hexutil.Uint64) ([]*ExecutableL2Data, error) {
+	if start > end {
+		return nil, fmt.Errorf("invalid block range: start %d after end %d", start, end)
+	}
+	if uint64(end-start) >= maxL2BlocksRange {
+		return nil, fmt.Errorf("block range too large: %d blocks, maximum %d", uint64(end-start)+1, maxL2BlocksRange)
+	}
+	if head := hexutil.Uint64(api.e.blockchain.CurrentBlock().NumberU64()); end > head {
+		if start > head {
+			return []*ExecutableL2Data{}, nil
+		}
+		end = head
+	}
+	payloads := make([]*ExecutableL2Data, 0, int(end-start)+1)
+	for number := uint64(start); number <= uint64(end); number++ {
+		block := api.e.blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		payload, err := NewExecutableL2Data(block, api.e.engine)
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
+
+// maxMulticallCalls is the maximum number of calls a single Multicall may
+// execute.
+const maxMulticallCalls = 1000
+
+// errMulticallBudget is the error of the calls of a multicall left unexecuted
+// once its gas budget is used up.
+var errMulticallBudget = errors.New("multicall gas budget exhausted")
+
+// MulticallResult is the outcome of a single call of a multicall.
+type MulticallResult struct {
+	ReturnData hexutil.Bytes  `json:"returnData"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// Multicall executes the given eth_call-style calls in order against the state
+// of the same block, each of them starting from that state, to spare frontends
+// the round trips of querying it call by call. Calls are gas-free: no gas nor
+// L1 fee is charged to their senders, whatever fees they set.
+//
+// Each call is capped by the global gas cap (see --rpc.gascap), and the calls
+// together by the multicall budget (see --rpc.multicallbudget). Calls failing
+// or left unexecuted once the budget is used up report their error in their
+// result, without failing the others.
+func (api *PublicScrollAPI) Multicall(ctx context.Context, calls []ethapi.TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi.StateOverride) ([]*MulticallResult, error) {
+	if len(calls) > maxMulticallCalls {
+		return nil, fmt.Errorf("too many calls: %d, maximum %d", len(calls), maxMulticallCalls)
+	}
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	backend := api.e.APIBackend
+	state, header, err := backend.StateAndHeaderByNumberOrHash(ctx, bNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	if err := overrides.Apply(state); err != nil {
+		return nil, err
+	}
+	// The timeout applies to the multicall as a whole
+	var (
+		timeout = backend.RPCEVMTimeout()
+		cancel  context.CancelFunc
+	)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	var (
+		budget  = api.e.config.RPCMulticallBudget
+		used    uint64
+		results = make([]*MulticallResult, len(calls))
+	)
+	for i, args := range calls {
+		gasCap := backend.RPCGasCap()
+		if budget > 0 {
+			if used >= budget {
+				results[i] = &MulticallResult{ReturnData: hexutil.Bytes{}, Error: errMulticallBudget.Error()}
+				continue
+			}
+			if gasCap == 0 || budget-used < gasCap {
+				gasCap = budget - used
+			}
+		}
+		result, err := api.multicall(ctx, state, header, args, gasCap)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+		if err != nil {
+			results[i] = &MulticallResult{ReturnData: hexutil.Bytes{}, Error: err.Error()}
+			continue
+		}
+		used += result.UsedGas
+
+		results[i] = &MulticallResult{
+			ReturnData: result.Return(),
+			GasUsed:    hexutil.Uint64(result.UsedGas),
+		}
+		if result.Err != nil {
+			results[i].ReturnData = result.Revert()
+			results[i].Error = result.Err.Error()
+			if reason, err := abi.UnpackRevert(result.Revert()); err == nil {
+				results[i].Error = fmt.Sprintf("%v: %v", result.Err, reason)
+			}
+		}
+		if results[i].ReturnData == nil {
+			results[i].ReturnData = hexutil.Bytes{}
+		}
+	}
+	return results, nil
+}
+
+// multicall executes a single call of a multicall on the given state, reverting
+// its changes afterwards.
+func (api *PublicScrollAPI) multicall(ctx context.Context, state *state.StateDB, header *types.Header, args ethapi.TransactionArgs, gasCap uint64) (*core.ExecutionResult, error) {
+	args.GasPrice, args.MaxFeePerGas, args.MaxPriorityFeePerGas = nil, nil, nil
+	msg, err := args.ToMessage(gasCap, header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := state.Snapshot()
+	defer state.RevertToSnapshot(snapshot)
+
+	// Credit the sender with the L1 fee charged even at a zero gas price
+	if config := api.e.blockchain.Config(); config.Scroll.FeeVaultEnabled() {
+		l1Fee, err := fees.CalculateL1MsgFee(msg, state, config.Scroll.IsCompressedL1Fee(header.Number))
+		if err != nil {
+			return nil, err
+		}
+		state.AddBalance(msg.From(), l1Fee)
+	}
+	evm, vmError, err := api.e.APIBackend.GetEVM(ctx, msg, state, header, &vm.Config{NoBaseFee: true})
+	if err != nil {
+		return nil, err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			evm.Cancel()
+		case <-done:
+		}
+	}()
+	result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+	if err := vmError(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("err: %w (supplied gas %d)", err, msg.Gas())
+	}
+	return result, nil
+}
+
+// Limits of a single GetProofs call.
+const (
+	maxProofAccounts = 256  // Maximum number of accounts proven
+	maxProofSlots    = 4096 // Maximum number of storage slots proven across all accounts
+)
+
+// ProofRequest is an account to prove, along with the storage slots of it to
+// prove.
+type ProofRequest struct {
+	Address     common.Address `json:"address"`
+	StorageKeys []common.Hash  `json:"storageKeys"`
+}
+
+// Proofs are the proofs of a set of accounts and storage slots at a block. The
+// trie nodes are shared by all the proofs, each node being sent only once, and
+// the proofs list the indices of their nodes in order.
+type Proofs struct {
+	BlockHash common.Hash     `json:"blockHash"`
+	StateRoot common.Hash     `json:"stateRoot"`
+	Nodes     []hexutil.Bytes `json:"nodes"`
+	Accounts  []*AccountProof `json:"accounts"`
+}
+
+// AccountProof is the proof of an account and of some of its storage slots, as
+// returned by eth_getProof, with the proof nodes replaced by their indices.
+type AccountProof struct {
+	Address          common.Address  `json:"address"`
+	AccountProof     []hexutil.Uint  `json:"accountProof"`
+	Balance          *hexutil.Big    `json:"balance"`
+	PoseidonCodeHash common.Hash     `json:"poseidonCodeHash"`
+	KeccakCodeHash   common.Hash     `json:"keccakCodeHash"`
+	CodeSize         hexutil.Uint64  `json:"codeSize"`
+	Nonce            hexutil.Uint64  `json:"nonce"`
+	StorageHash      common.Hash     `json:"storageHash"`
+	StorageProof     []*StorageProof `json:"storageProof"`
+}
+
+// StorageProof is the proof of a storage slot, with the proof nodes replaced by
+// their indices.
+type StorageProof struct {
+	Key   common.Hash    `json:"key"`
+	Value *hexutil.Big   `json:"value"`
+	Proof []hexutil.Uint `json:"proof"`
+}
+
+// proofNodes deduplicates the trie nodes of a set of proofs.
+type proofNodes struct {
+	nodes   []hexutil.Bytes
+	indices map[string]hexutil.Uint
+}
+
+// add adds the nodes of a proof, returning their indices.
+func (p *proofNodes) add(proof [][]byte) []hexutil.Uint {
+	indices := make([]hexutil.Uint, len(proof))
+	for i, node := range proof {
+		index, ok := p.indices[string(node)]
+		if !ok {
+			index = hexutil.Uint(len(p.nodes))
+			p.indices[string(node)] = index
+			p.nodes = append(p.nodes, node)
+		}
+		indices[i] = index
+	}
+	return indices
+}
+
+// GetProofs returns the proofs of many accounts and storage slots at the same
+// block, like as many eth_getProof calls would, but sending the trie nodes they
+// share only once. Relayers proving dozens of slots per batch save most of the
+// bandwidth and of the hashing needed to check the proofs.
+func (api *PublicScrollAPI) GetProofs(ctx context.Context, requests []ProofRequest, blockNrOrHash rpc.BlockNumberOrHash) (*Proofs, error) {
+	if len(requests) > maxProofAccounts {
+		return nil, fmt.Errorf("too many accounts: %d, maximum %d", len(requests), maxProofAccounts)
+	}
+	slots := 0
+	for _, request := range requests {
+		slots += len(request.StorageKeys)
+	}
+	if slots > maxProofSlots {
+		return nil, fmt.Errorf("too many storage slots: %d, maximum %d", slots, maxProofSlots)
+	}
+	state, header, err := api.e.APIBackend.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	var (
+		nodes  = &proofNodes{indices: make(map[string]hexutil.Uint)}
+		proofs = &Proofs{BlockHash: header.Hash(), StateRoot: header.Root}
+	)
+	for _, request := range requests {
+		address := request.Address
+
+		// Mirror eth_getProof in the fields of missing accounts
+		var (
+			storageTrie      = state.StorageTrie(address)
+			storageHash      = state.Commitment().EmptyStorageRoot()
+			keccakCodeHash   = state.GetKeccakCodeHash(address)
+			poseidonCodeHash = state.GetPoseidonCodeHash(address)
+		)
+		if storageTrie != nil {
+			storageHash = storageTrie.Hash()
+		} else {
+			keccakCodeHash = codehash.EmptyKeccakCodeHash
+			poseidonCodeHash = codehash.EmptyPoseidonCodeHash
+		}
+		storageProofs := make([]*StorageProof, len(request.StorageKeys))
+		for i, key := range request.StorageKeys {
+			storageProofs[i] = &StorageProof{Key: key, Value: new(hexutil.Big), Proof: []hexutil.Uint{}}
+			if storageTrie == nil {
+				continue
+			}
+			proof, err := state.GetStorageProof(address, key)
+			if err != nil {
+				return nil, err
+			}
+			storageProofs[i].Value = (*hexutil.Big)(state.GetState(address, key).Big())
+			storageProofs[i].Proof = nodes.add(proof)
+		}
+		accountProof, err := state.GetProof(address)
+		if err != nil {
+			return nil, err
+		}
+		proofs.Accounts = append(proofs.Accounts, &AccountProof{
+			Address:          address,
+			AccountProof:     nodes.add(accountProof),
+			Balance:          (*hexutil.Big)(state.GetBalance(address)),
+			PoseidonCodeHash: poseidonCodeHash,
+			KeccakCodeHash:   keccakCodeHash,
+			CodeSize:         hexutil.Uint64(state.GetCodeSize(address)),
+			Nonce:            hexutil.Uint64(state.GetNonce(address)),
+			StorageHash:      storageHash,
+			StorageProof:     storageProofs,
+		})
+	}
+	proofs.Nodes = nodes.nodes
+	if proofs.Nodes == nil {
+		proofs.Nodes = []hexutil.Bytes{}
+	}
+	if proofs.Accounts == nil {
+		proofs.Accounts = []*AccountProof{}
+	}
+	return proofs, state.Error()
+}
+
+// FeeStats is the fee and pool wait time data of a range of blocks, for wallets
+// to pick the fees getting transactions included within a given time.
+type FeeStats struct {
+	OldestBlock    *hexutil.Big       `json:"oldestBlock"`
+	MinPriorityFee []*hexutil.Big     `json:"minPriorityFeePerGas"`
+	WaitSamples    []hexutil.Uint64   `json:"waitSamples"`
+	WaitTime       [][]hexutil.Uint64 `json:"waitTime,omitempty"`
+}
+
+// FeeStats returns, for each of the given number of blocks up to lastBlock, the
+// minimum effective priority fee per gas the block included (null if it has no
+// transactions), and the requested percentiles of the time in milliseconds its
+// transactions waited in the local pool, along with the number of transactions
+// measured. Wait times are only known for the last LatencyBlocks blocks accepted
+// since the node started, and are zero for blocks without measurements.
+func (api *PublicScrollAPI) FeeStats(ctx context.Context, blockCount rpc.DecimalOrHex, lastBlock rpc.BlockNumber, waitPercentiles []float64) (*FeeStats, error) {
+	for i, p := range waitPercentiles {
+		if p < 0 || p > 100 || (i > 0 && p < waitPercentiles[i-1]) {
+			return nil, fmt.Errorf("invalid wait percentile: %f", p)
+		}
+	}
+	first, last, err := api.blockRange(uint64(blockCount), gasprice.LatencyBlocks, lastBlock)
+	if err != nil {
+		return nil, err
+	}
+	count := last + 1 - first
+	stats := &FeeStats{
+		OldestBlock:    (*hexutil.Big)(new(big.Int).SetUint64(first)),
+		MinPriorityFee: make([]*hexutil.Big, 0, count),
+		WaitSamples:    make([]hexutil.Uint64, 0, count),
+	}
+	if len(waitPercentiles) > 0 {
+		stats.WaitTime = make([][]hexutil.Uint64, 0, count)
+	}
+	for number := first; number <= last; number++ {
+		block := api.e.blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		var minFee *big.Int
+		for _, tx := range block.Transactions() {
+			if fee, err := tx.EffectiveGasTip(block.BaseFee()); err == nil && (minFee == nil || fee.Cmp(minFee) < 0) {
+				minFee = fee
+			}
+		}
+		stats.MinPriorityFee = append(stats.MinPriorityFee, (*hexutil.Big)(minFee))
+
+		waits, measured, _ := api.e.latency.Waits(block.Hash(), waitPercentiles)
+		stats.WaitSamples = append(stats.WaitSamples, hexutil.Uint64(measured))
+		if len(waitPercentiles) > 0 {
+			millis := make([]hexutil.Uint64, len(waitPercentiles))
+			for i, wait := range waits {
+				millis[i] = hexutil.Uint64(wait.Milliseconds())
+			}
+			stats.WaitTime = append(stats.WaitTime, millis)
+		}
+	}
+	return stats, nil
+}
+
+// blockRange returns the first and last of the given number of canonical blocks
+// up to lastBlock, capping the number at the given maximum. The range is empty,
+// the first block following the last, if no blocks are requested.
+func (api *PublicScrollAPI) blockRange(count, max uint64, lastBlock rpc.BlockNumber) (uint64, uint64, error) {
+	if count > max {
+		count = max
+	}
+	head := api.e.blockchain.CurrentBlock().NumberU64()
+	last := head
+	switch {
+	case lastBlock == rpc.LatestBlockNumber || lastBlock == rpc.PendingBlockNumber:
+	case lastBlock < 0:
+		return 0, 0, fmt.Errorf("unsupported block tag %d", lastBlock)
+	case uint64(lastBlock) > head:
+		return 0, 0, fmt.Errorf("request beyond head block: requested %d, head %d", lastBlock, head)
+	default:
+		last = uint64(lastBlock)
+	}
+	if count > last+1 {
+		count = last + 1
+	}
+	return last + 1 - count, last, nil
+}
+
+const (
+	// maxContractUsageBlocks is the maximum number of blocks the contract usage
+	// is aggregated over.
+	maxContractUsageBlocks = 10000
+
+	// defaultContractUsageLimit is the number of contracts returned by the
+	// contract usage rankings if no limit is requested.
+	defaultContractUsageLimit = 20
+)
+
+// ContractUsage is the gas used by and the state growth caused by a contract
+// over a range of blocks.
+type ContractUsage struct {
+	Address      common.Address `json:"address"`
+	Transactions hexutil.Uint64 `json:"transactions"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	SlotsCreated hexutil.Uint64 `json:"slotsCreated"`
+	SlotsCleared hexutil.Uint64 `json:"slotsCleared"`
+	SlotGrowth   int64          `json:"slotGrowth"`
+	CodeSize     hexutil.Uint64 `json:"codeSize"`
+}
+
+// ContractUsageStats is a ranking of the contracts by their usage over a range
+// of blocks.
+type ContractUsageStats struct {
+	OldestBlock hexutil.Uint64   `json:"oldestBlock"`
+	LatestBlock hexutil.Uint64   `json:"latestBlock"`
+	Contracts   []*ContractUsage `json:"contracts"`
+}
+
+// TopGasConsumers returns the contracts whose calls and creations used the most
+// gas over the given number of blocks up to lastBlock, at most limit of them.
+// Only the blocks imported with the contract usage recorded are accounted for
+// (see --recordcontractusage).
+func (api *PublicScrollAPI) TopGasConsumers(blockCount rpc.DecimalOrHex, lastBlock rpc.BlockNumber, limit *hexutil.Uint64) (*ContractUsageStats, error) {
+	return api.rankContractUsage(blockCount, lastBlock, limit, func(usage *ContractUsage) bool {
+		return usage.GasUsed > 0
+	}, func(a, b *ContractUsage) bool {
+		return a.GasUsed > b.GasUsed
+	})
+}
+
+// StateGrowthByContract returns the contracts that grew the state the most over
+// the given number of blocks up to lastBlock, at most limit of them, ranked by
+// the storage slots they created net of the ones they cleared, then by the size
+// of the code deployed. Only the blocks imported with the contract usage
+// recorded are accounted for (see --recordcontractusage).
+func (api *PublicScrollAPI) StateGrowthByContract(blockCount rpc.DecimalOrHex, lastBlock rpc.BlockNumber, limit *hexutil.Uint64) (*ContractUsageStats, error) {
+	return api.rankContractUsage(blockCount, lastBlock, limit, func(usage *ContractUsage) bool {
+		return usage.SlotsCreated > 0 || usage.SlotsCleared > 0 || usage.CodeSize > 0
+	}, func(a, b *ContractUsage) bool {
+		if a.SlotGrowth != b.SlotGrowth {
+			return a.SlotGrowth > b.SlotGrowth
+		}
+		return a.CodeSize > b.CodeSize
+	})
+}
+
+// rankContractUsage aggregates the contract usage recorded for a range of
+// blocks, returning the contracts passing the filter ordered by the given
+// ranking, ties broken by address.
+func (api *PublicScrollAPI) rankContractUsage(blockCount rpc.DecimalOrHex, lastBlock rpc.BlockNumber, limit *hexutil.Uint64, filter func(*ContractUsage) bool, less func(a, b *ContractUsage) bool) (*ContractUsageStats, error) {
+	first, last, err := api.blockRange(uint64(blockCount), maxContractUsageBlocks, lastBlock)
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[common.Address]*ContractUsage)
+	for number := first; number <= last; number++ {
+		for _, record := range rawdb.ReadContractUsage(api.e.ChainDb(), api.e.blockchain.GetCanonicalHash(number)) {
+			contract := usage[record.Address]
+			if contract == nil {
+				contract = &ContractUsage{Address: record.Address}
+				usage[record.Address] = contract
+			}
+			contract.Transactions += hexutil.Uint64(record.Transactions)
+			contract.GasUsed += hexutil.Uint64(record.GasUsed)
+			contract.SlotsCreated += hexutil.Uint64(record.SlotsCreated)
+			contract.SlotsCleared += hexutil.Uint64(record.SlotsCleared)
+			if record.CodeSize > 0 {
+				contract.CodeSize = hexutil.Uint64(record.CodeSize)
+			}
+		}
+	}
+	contracts := make([]*ContractUsage, 0, len(usage))
+	for _, contract := range usage {
+		contract.SlotGrowth = int64(contract.SlotsCreated) - int64(contract.SlotsCleared)
+		if filter(contract) {
+			contracts = append(contracts, contract)
+		}
+	}
+	sort.Slice(contracts, func(i, j int) bool {
+		if less(contracts[i], contracts[j]) {
+			return true
+		}
+		if less(contracts[j], contracts[i]) {
+			return false
+		}
+		return bytes.Compare(contracts[i].Address[:], contracts[j].Address[:]) < 0
+	})
+	max := uint64(defaultContractUsageLimit)
+	if limit != nil {
+		max = uint64(*limit)
+	}
+	if uint64(len(contracts)) > max {
+		contracts = contracts[:max]
+	}
+	return &ContractUsageStats{
+		OldestBlock: hexutil.Uint64(first),
+		LatestBlock: hexutil.Uint64(last),
+		Contracts:   contracts,
+	}, nil
+}
+
+// blockFeedChanSize is the size of channel listening to ChainEvent for the
+// block feed.
+const blockFeedChanSize = 16
+
+// Blocks creates a subscription streaming the RLP encoded canonical blocks of
+// the chain, starting at the given block number. Blocks up to the current head
+// are replayed first, then every newly committed block follows in order. It is
+// the block feed consumed by read replicas.
+func (api *PublicScrollAPI) Blocks(ctx context.Context, from hexutil.Uint64) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		var (
+			events = make(chan core.ChainEvent, blockFeedChanSize)
+			sub    = api.e.blockchain.SubscribeChainEvent(events)
+			wake   = make(chan struct{}, 1)
+			quit   = make(chan struct{})
+
+			lock   sync.Mutex
+			lowest = uint64(math.MaxUint64) // Lowest block committed since the last wakeup
+		)
+		defer sub.Unsubscribe()
+		defer close(quit)
+
+		// Drain chain events separately, a slow subscriber or a long replay must
+		// never stall block insertion
+		go func() {
+			for {
+				select {
+				case ev := <-events:
+					lock.Lock()
+					if number := ev.Block.NumberU64(); number < lowest {
+						lowest = number
+					}
+					lock.Unlock()
+
+					select {
+					case wake <- struct{}{}:
+					default:
+					}
+				case <-quit:
+					return
+				}
+			}
+		}()
+		next := uint64(from)
+		for {
+			// Stream the canonical blocks up to the current head
+			for head := api.e.blockchain.CurrentBlock().NumberU64(); next <= head; next++ {
+				block := api.e.blockchain.GetBlockByNumber(next)
+				if block == nil {
+					break // Reorged away meanwhile, the new blocks follow
+				}
+				blob, err := rlp.EncodeToBytes(block)
+				if err != nil {
+					log.Error("Failed to encode streamed block", "number", next, "err", err)
+					return
+				}
+				if err := notifier.Notify(rpcSub.ID, hexutil.Bytes(blob)); err != nil {
+					return
+				}
+			}
+			select {
+			case <-wake:
+				// Rewind on reorgs so that the new canonical blocks are resent
+				lock.Lock()
+				if lowest < next {
+					next = lowest
+				}
+				lowest = math.MaxUint64
+				lock.Unlock()
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// maxEvidenceRange is the maximum number of blocks a single
+// GetEquivocationEvidence call may span.
+const maxEvidenceRange = 100000
+
+// GetEquivocationEvidence returns the evidence of proposers signing two different
+// blocks at the same height, recorded for the blocks from start to end inclusive.
+func (api *PublicScrollAPI) GetEquivocationEvidence(start, end hexutil.Uint64) ([]*types.EquivocationEvidence, error) {
+	if start > end {
+		return nil, fmt.Errorf("invalid block range: start %d after end %d", start, end)
+	}
+	if uint64(end-start) >= maxEvidenceRange {
+		return nil, fmt.Errorf("block range too large: %d blocks, maximum %d", uint64(end-start)+1, maxEvidenceRange)
+	}
+	evidence := rawdb.ReadEquivocationEvidence(api.e.ChainDb(), uint64(start), uint64(end))
+	if evidence == nil {
+		evidence = []*types.EquivocationEvidence{}
+	}
+	return evidence, nil
+}
+
+// EquivocationEvidence creates a subscription streaming the evidence of every
+// proposer equivocation detected from now on, for slashing tooling to act upon.
+func (api *PublicScrollAPI) EquivocationEvidence(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan core.EquivocationEvent, blockFeedChanSize)
+		sub := api.e.blockchain.SubscribeEquivocationEvent(events)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-events:
+				notifier.Notify(rpcSub.ID, ev.Evidence)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
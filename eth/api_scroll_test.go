@@ -0,0 +1,740 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/eth/ethconfig"
+	"github.com/scroll-tech/go-ethereum/eth/gasprice"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/ethdb/memorydb"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/internal/ethapi"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+// newScrollTestChain creates a chain of the given length with a transfer in
+// every block, importing only its first blocks up to the given count.
+func newScrollTestChain(t *testing.T, length, inserted int) (*core.BlockChain, ethdb.Database, []*types.Block) {
+	t.Helper()
+
+	config := *params.TestChainConfig
+	config.Scroll.MaxTxPerBlock = nil
+
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &core.Genesis{Config: &config, Alloc: core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(&config)
+	)
+	blocks, _ := core.GenerateChain(&config, genesis, ethash.NewFaker(), db, length, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{0x01}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	chain, err := core.NewBlockChain(db, nil, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks[:inserted]); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	return chain, db, blocks
+}
+
+// Tests that the block feed replays the requested range and then follows newly
+// committed blocks.
+func TestScrollBlockFeed(t *testing.T) {
+	chain, _, blocks := newScrollTestChain(t, 10, 8)
+	defer chain.Stop()
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("scroll", NewPublicScrollAPI(&Ethereum{blockchain: chain})); err != nil {
+		t.Fatalf("failed to register scroll API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	blobs := make(chan hexutil.Bytes, 16)
+	sub, err := client.Subscribe(context.Background(), "scroll", blobs, "blocks", hexutil.Uint64(3))
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	expect := func(number uint64) {
+		t.Helper()
+		select {
+		case blob := <-blobs:
+			block := new(types.Block)
+			if err := rlp.DecodeBytes(blob, block); err != nil {
+				t.Fatalf("failed to decode block: %v", err)
+			}
+			if want := blocks[number-1]; block.Hash() != want.Hash() {
+				t.Fatalf("block mismatch: have #%d %x, want #%d %x", block.NumberU64(), block.Hash(), number, want.Hash())
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for block #%d", number)
+		}
+	}
+	// Committed blocks are replayed from the requested number
+	for number := uint64(3); number <= 8; number++ {
+		expect(number)
+	}
+	// New blocks follow as they are committed
+	if _, err := chain.InsertChain(blocks[8:]); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	expect(9)
+	expect(10)
+}
+
+// Tests that execution payloads of committed block ranges are returned.
+func TestScrollGetL2BlocksByRange(t *testing.T) {
+	chain, _, blocks := newScrollTestChain(t, 10, 10)
+	defer chain.Stop()
+
+	api := NewPublicScrollAPI(&Ethereum{blockchain: chain, engine: chain.Engine()})
+	payloads, err := api.GetL2BlocksByRange(3, 6)
+	if err != nil {
+		t.Fatalf("failed to retrieve range: %v", err)
+	}
+	if len(payloads) != 4 {
+		t.Fatalf("payload count mismatch: have %d, want 4", len(payloads))
+	}
+	for i, payload := range payloads {
+		block := blocks[i+2]
+		if payload.BlockHash != block.Hash() || uint64(payload.Number) != block.NumberU64() || payload.StateRoot != block.Root() {
+			t.Errorf("payload %d: header mismatch: have #%d %x, want #%d %x", i, payload.Number, payload.BlockHash, block.NumberU64(), block.Hash())
+		}
+		if root := chain.Engine().SealHash(block.Header()); payload.SigningRoot != root {
+			t.Errorf("payload %d: signing root mismatch: have %x, want %x", i, payload.SigningRoot, root)
+		}
+		if len(payload.Transactions) != 1 {
+			t.Fatalf("payload %d: transaction count mismatch: have %d, want 1", i, len(payload.Transactions))
+		}
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(payload.Transactions[0]); err != nil || tx.Hash() != block.Transactions()[0].Hash() {
+			t.Errorf("payload %d: transaction mismatch: %v", i, err)
+		}
+	}
+	// Ranges are truncated at the head
+	if payloads, err := api.GetL2BlocksByRange(9, 20); err != nil || len(payloads) != 2 {
+		t.Errorf("truncated range mismatch: have %d payloads, err %v, want 2", len(payloads), err)
+	}
+	if payloads, err := api.GetL2BlocksByRange(11, 20); err != nil || len(payloads) != 0 {
+		t.Errorf("future range mismatch: have %d payloads, err %v, want 0", len(payloads), err)
+	}
+	// Invalid and oversized ranges are rejected
+	if _, err := api.GetL2BlocksByRange(6, 3); err == nil {
+		t.Errorf("inverted range accepted")
+	}
+	if _, err := api.GetL2BlocksByRange(0, maxL2BlocksRange); err == nil {
+		t.Errorf("oversized range accepted")
+	}
+}
+
+// Tests that transaction statuses follow inclusion and finalization.
+func TestScrollGetTransactionStatus(t *testing.T) {
+	chain, db, blocks := newScrollTestChain(t, 10, 9)
+	defer chain.Stop()
+
+	poolConfig := core.DefaultTxPoolConfig
+	poolConfig.Journal = ""
+	pool := core.NewTxPool(poolConfig, chain.Config(), chain)
+	defer pool.Stop()
+
+	api := NewPublicScrollAPI(&Ethereum{blockchain: chain, chainDb: db, txPool: pool})
+	check := func(tx *types.Transaction, want string, number uint64) {
+		t.Helper()
+		status := api.GetTransactionStatus(tx.Hash())
+		if status.Status != want {
+			t.Fatalf("status mismatch: have %s, want %s", status.Status, want)
+		}
+		if number == 0 {
+			if status.BlockNumber != nil {
+				t.Fatalf("block number mismatch: have %d, want none", *status.BlockNumber)
+			}
+			return
+		}
+		if status.BlockNumber == nil || uint64(*status.BlockNumber) != number || *status.BlockHash != blocks[number-1].Hash() {
+			t.Fatalf("block mismatch: have %v, want #%d", status.BlockNumber, number)
+		}
+	}
+	check(blocks[2].Transactions()[0], TxStatusUnsafe, 3)
+	check(blocks[9].Transactions()[0], TxStatusUnknown, 0)
+
+	if err := pool.AddLocal(blocks[9].Transactions()[0]); err != nil {
+		t.Fatalf("failed to add transaction to pool: %v", err)
+	}
+	check(blocks[9].Transactions()[0], TxStatusPending, 0)
+
+	if err := chain.SetFinalized(blocks[4]); err != nil {
+		t.Fatalf("failed to finalize block: %v", err)
+	}
+	check(blocks[2].Transactions()[0], TxStatusFinalized, 3)
+	check(blocks[4].Transactions()[0], TxStatusFinalized, 5)
+	check(blocks[5].Transactions()[0], TxStatusUnsafe, 6)
+}
+
+// Tests that a batch of raw transactions is admitted item by item, reporting the
+// outcome and pool position of each.
+func TestScrollSendRawTransactions(t *testing.T) {
+	chain, db, blocks := newScrollTestChain(t, 10, 9)
+	defer chain.Stop()
+
+	poolConfig := core.DefaultTxPoolConfig
+	poolConfig.Journal = ""
+	pool := core.NewTxPool(poolConfig, chain.Config(), chain)
+	defer pool.Stop()
+
+	eth := &Ethereum{blockchain: chain, chainDb: db, txPool: pool, config: &ethconfig.Config{RPCTxFeeCap: 1}}
+	eth.APIBackend = &EthAPIBackend{eth: eth, allowUnprotectedTxs: true}
+	api := NewPublicScrollAPI(eth)
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		signer = types.LatestSigner(chain.Config())
+		next   = blocks[9].Transactions()[0]
+	)
+	sign := func(nonce uint64, tip int64) hexutil.Bytes {
+		tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   chain.Config().ChainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(tip),
+			GasFeeCap: new(big.Int).Add(blocks[9].BaseFee(), big.NewInt(tip)),
+			Gas:       params.TxGas,
+			To:        &common.Address{0x01},
+		})
+		blob, _ := tx.MarshalBinary()
+		return blob
+	}
+	first, _ := next.MarshalBinary()
+	results, err := api.SendRawTransactions([]hexutil.Bytes{
+		first,               // Executable
+		sign(10, 1_000_000), // Executable, paying a higher tip than the first
+		sign(12, 1),         // Queued behind nonce 11
+		{0x01, 0x02},        // Undecodable
+		sign(3, 1),          // Nonce too low
+		sign(13, 1e18),      // Fee above the RPC cap
+	})
+	if err != nil {
+		t.Fatalf("failed to send transactions: %v", err)
+	}
+	want := []string{SubmitPending, SubmitPending, SubmitQueued, SubmitRejected, SubmitRejected, SubmitRejected}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("result %d: status mismatch: have %s (%s), want %s", i, result.Status, result.Error, want[i])
+		}
+		if (result.Status == SubmitRejected) != (result.Error != "") {
+			t.Errorf("result %d: error mismatch: have %q", i, result.Error)
+		}
+	}
+	if results[0].Position == nil || *results[0].Position != 1 || results[1].Position == nil || *results[1].Position != 0 {
+		t.Fatalf("positions mismatch: have %v and %v, want 1 and 0", results[0].Position, results[1].Position)
+	}
+	if results[0].Estimate == nil || results[0].Estimate.BlockNumber != 10 || results[2].Estimate != nil {
+		t.Fatalf("estimates mismatch: have %v and %v", results[0].Estimate, results[2].Estimate)
+	}
+	if results[3].Hash != nil || results[4].Hash == nil {
+		t.Fatalf("hashes mismatch: have %v and %v", results[3].Hash, results[4].Hash)
+	}
+	if _, err := api.SendRawTransactions(make([]hexutil.Bytes, maxRawTransactions+1)); err == nil {
+		t.Fatalf("oversized batch accepted")
+	}
+}
+
+// Tests that inclusion estimates account for the transaction and gas capacity
+// of blocks and the block time.
+func TestScrollInclusionEstimate(t *testing.T) {
+	chain, db, _ := newScrollTestChain(t, 1, 1)
+	defer chain.Stop()
+
+	maxTxs := 10
+	config := chain.Config()
+	config.Scroll.MaxTxPerBlock = &maxTxs
+	config.Scroll.BlockTime = &params.BlockTimeConfig{Block: common.Big0, TargetBlockTime: 3}
+
+	api := NewPublicScrollAPI(&Ethereum{blockchain: chain, chainDb: db})
+	head := &types.Header{Number: big.NewInt(100), GasLimit: 1_000_000, Time: uint64(time.Now().Unix()) + 60}
+	for i, tt := range []struct {
+		position, gas uint64
+		block, time   uint64
+	}{
+		{0, 21_000, 101, 3},
+		{9, 210_000, 101, 3},
+		{10, 231_000, 102, 6},
+		{25, 546_000, 103, 9},
+		{3, 1_000_001, 102, 6},
+	} {
+		estimate := api.estimateInclusion(head, tt.position, tt.gas)
+		if uint64(estimate.BlockNumber) != tt.block || uint64(estimate.Timestamp) != head.Time+tt.time {
+			t.Errorf("test %d: estimate mismatch: have #%d at %d, want #%d at %d", i, estimate.BlockNumber, estimate.Timestamp, tt.block, head.Time+tt.time)
+		}
+	}
+}
+
+// lookupStateProvider serves a fixed transaction lookup to forwarded queries.
+type lookupStateProvider struct {
+	response string
+}
+
+func (p *lookupStateProvider) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if method != "eth_getTransactionByHash" {
+		return fmt.Errorf("unexpected method %s", method)
+	}
+	return json.Unmarshal([]byte(p.response), result)
+}
+
+// Tests that the transaction index progress is reported and that transactions
+// of unindexed blocks are looked up through the historical state provider.
+func TestScrollTxIndexProgress(t *testing.T) {
+	chain, db, blocks := newScrollTestChain(t, 8, 8)
+	chain.Stop() // Stop the background indexer
+
+	rawdb.UnindexTransactions(db, 0, 4, nil)
+
+	e := &Ethereum{blockchain: chain, chainDb: db, config: &ethconfig.Config{}}
+	progress, err := NewPublicScrollAPI(e).TxIndexProgress()
+	if err != nil {
+		t.Fatalf("failed to get progress: %v", err)
+	}
+	if progress.Tail != 4 || progress.Indexed != 5 || progress.Remaining != 4 {
+		t.Fatalf("progress mismatch: have %+v, want tail 4, 5 indexed, 4 remaining", progress)
+	}
+	backend := &EthAPIBackend{eth: e}
+	tx := blocks[1].Transactions()[0]
+	if have, _, _, _, err := backend.GetTransaction(context.Background(), tx.Hash()); have != nil || err != nil {
+		t.Fatalf("unindexed transaction found without provider: %v, %v", have, err)
+	}
+	backend.historicalState = &lookupStateProvider{response: fmt.Sprintf(`{"blockHash":"%s","blockNumber":"0x2","transactionIndex":"0x0"}`, blocks[1].Hash().Hex())}
+	have, blockHash, number, index, err := backend.GetTransaction(context.Background(), tx.Hash())
+	if err != nil || have == nil || have.Hash() != tx.Hash() || blockHash != blocks[1].Hash() || number != 2 || index != 0 {
+		t.Fatalf("historical lookup mismatch: have %v #%d %x [%d], err %v", have, number, blockHash, index, err)
+	}
+	// A lookup pointing to another transaction must be rejected
+	backend.historicalState = &lookupStateProvider{response: fmt.Sprintf(`{"blockHash":"%s","blockNumber":"0x3","transactionIndex":"0x0"}`, blocks[2].Hash().Hex())}
+	if _, _, _, _, err := backend.GetTransaction(context.Background(), tx.Hash()); err != errInvalidHistoricalLookup {
+		t.Fatalf("invalid lookup error mismatch: have %v, want %v", err, errInvalidHistoricalLookup)
+	}
+}
+
+func TestScrollMulticall(t *testing.T) {
+	chain, db, _ := newScrollTestChain(t, 4, 4)
+	defer chain.Stop()
+
+	var (
+		constant = common.Address{0xc0}
+		counter  = common.Address{0xc1}
+		reverter = common.Address{0xc2}
+		config   = &ethconfig.Config{RPCGasCap: 50000000, RPCEVMTimeout: 5 * time.Second}
+		e        = &Ethereum{blockchain: chain, chainDb: db, config: config}
+	)
+	e.APIBackend = &EthAPIBackend{eth: e}
+	api := NewPublicScrollAPI(e)
+
+	overrides := &ethapi.StateOverride{
+		// Returns 42
+		constant: {Code: (*hexutil.Bytes)(&[]byte{0x60, 0x2a, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3})},
+		// Returns slot 0, then sets it to 1
+		counter: {Code: (*hexutil.Bytes)(&[]byte{0x60, 0x00, 0x54, 0x60, 0x00, 0x52, 0x60, 0x01, 0x60, 0x00, 0x55, 0x60, 0x20, 0x60, 0x00, 0xf3})},
+		// Reverts
+		reverter: {Code: (*hexutil.Bytes)(&[]byte{0x60, 0x00, 0x60, 0x00, 0xfd})},
+	}
+	gas := hexutil.Uint64(60000)
+	calls := []ethapi.TransactionArgs{
+		{To: &constant},
+		{To: &counter},
+		{To: &counter, Gas: &gas},
+		{To: &reverter},
+		{To: &constant},
+	}
+	results, err := api.Multicall(context.Background(), calls, nil, overrides)
+	if err != nil {
+		t.Fatalf("failed to execute multicall: %v", err)
+	}
+	if len(results) != len(calls) {
+		t.Fatalf("result count mismatch: have %d, want %d", len(results), len(calls))
+	}
+	// Calls are gas-free and start from the same state
+	if have := new(big.Int).SetBytes(results[0].ReturnData); have.Uint64() != 42 || results[0].Error != "" {
+		t.Errorf("call 0: result mismatch: have %d (%s), want 42", have, results[0].Error)
+	}
+	for i := 1; i <= 2; i++ {
+		if have := new(big.Int).SetBytes(results[i].ReturnData); have.Sign() != 0 || results[i].Error != "" {
+			t.Errorf("call %d: result mismatch: have %d (%s), want 0", i, have, results[i].Error)
+		}
+	}
+	if results[3].Error != vm.ErrExecutionReverted.Error() {
+		t.Errorf("call 3: error mismatch: have %q, want %q", results[3].Error, vm.ErrExecutionReverted)
+	}
+	// Calls past the budget aren't executed
+	for _, result := range results[:4] {
+		config.RPCMulticallBudget += uint64(result.GasUsed)
+	}
+	results, err = api.Multicall(context.Background(), calls, nil, overrides)
+	if err != nil {
+		t.Fatalf("failed to execute multicall: %v", err)
+	}
+	if results[3].Error != vm.ErrExecutionReverted.Error() {
+		t.Errorf("call 3: error mismatch: have %q, want %q", results[3].Error, vm.ErrExecutionReverted)
+	}
+	if results[4].Error != errMulticallBudget.Error() {
+		t.Errorf("call 4: error mismatch: have %q, want %q", results[4].Error, errMulticallBudget)
+	}
+}
+
+func TestScrollGetProofs(t *testing.T) {
+	chain, db, _ := newScrollTestChain(t, 4, 4)
+	defer chain.Stop()
+
+	e := &Ethereum{blockchain: chain, chainDb: db, config: &ethconfig.Config{}}
+	e.APIBackend = &EthAPIBackend{eth: e}
+	api := NewPublicScrollAPI(e)
+
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		accounts = []common.Address{sender, {0x01}, {0xff}}
+		requests = make([]ProofRequest, len(accounts))
+	)
+	for i, account := range accounts {
+		requests[i] = ProofRequest{Address: account, StorageKeys: []common.Hash{{0x01}}}
+	}
+	proofs, err := api.GetProofs(context.Background(), requests, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	if err != nil {
+		t.Fatalf("failed to get proofs: %v", err)
+	}
+	head := chain.CurrentBlock()
+	if proofs.BlockHash != head.Hash() || proofs.StateRoot != head.Root() {
+		t.Fatalf("block mismatch: have %x [%x], want %x [%x]", proofs.BlockHash, proofs.StateRoot, head.Hash(), head.Root())
+	}
+	// Shared nodes are only sent once
+	var total int
+	for _, account := range proofs.Accounts {
+		total += len(account.AccountProof)
+		if account.AccountProof[0] != 0 {
+			t.Errorf("account %x: root node not shared", account.Address)
+		}
+	}
+	if len(proofs.Nodes) >= total {
+		t.Errorf("nodes not deduplicated: %d nodes for %d proof entries", len(proofs.Nodes), total)
+	}
+	// The proofs verify against the state root
+	nodes := memorydb.New()
+	for _, node := range proofs.Nodes {
+		nodes.Put(crypto.Keccak256(node), node)
+	}
+	for i, account := range proofs.Accounts {
+		if account.Address != accounts[i] {
+			t.Fatalf("account %d: address mismatch: have %x, want %x", i, account.Address, accounts[i])
+		}
+		value, err := trie.VerifyProof(head.Root(), crypto.Keccak256(account.Address[:]), nodes)
+		if err != nil {
+			t.Fatalf("account %x: invalid proof: %v", account.Address, err)
+		}
+		if exists := value != nil; exists != (i < 2) {
+			t.Errorf("account %x: existence mismatch: have %v", account.Address, exists)
+		}
+		if len(account.StorageProof) != 1 || account.StorageProof[0].Value.ToInt().Sign() != 0 {
+			t.Errorf("account %x: storage proof mismatch", account.Address)
+		}
+	}
+	if have := proofs.Accounts[0].Nonce; have != 4 {
+		t.Errorf("sender nonce mismatch: have %d, want 4", have)
+	}
+	// Oversized requests are rejected
+	if _, err := api.GetProofs(context.Background(), make([]ProofRequest, maxProofAccounts+1), rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)); err == nil {
+		t.Errorf("oversized request accepted")
+	}
+}
+
+// scrollLatencyBackend feeds the pool events of a latency tracker manually.
+type scrollLatencyBackend struct {
+	*core.BlockChain
+	txFeed event.Feed
+}
+
+func (b *scrollLatencyBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
+	return b.txFeed.Subscribe(ch)
+}
+
+func TestScrollFeeStats(t *testing.T) {
+	chain, db, blocks := newScrollTestChain(t, 4, 2)
+	defer chain.Stop()
+
+	backend := &scrollLatencyBackend{BlockChain: chain}
+	tracker := gasprice.NewLatencyTracker(backend)
+	defer tracker.Stop()
+
+	// Only the transactions seen by the pool are measured
+	for backend.txFeed.Send(core.NewTxsEvent{Txs: blocks[2].Transactions()}) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := chain.InsertChain(blocks[2:]); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	for i := 0; ; i++ {
+		if _, _, ok := tracker.Waits(blocks[3].Hash(), nil); ok {
+			break
+		}
+		if i == 1000 {
+			t.Fatalf("blocks not tracked")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	api := NewPublicScrollAPI(&Ethereum{blockchain: chain, chainDb: db, latency: tracker})
+
+	stats, err := api.FeeStats(context.Background(), 3, rpc.LatestBlockNumber, []float64{50, 100})
+	if err != nil {
+		t.Fatalf("failed to get fee stats: %v", err)
+	}
+	if stats.OldestBlock.ToInt().Uint64() != 2 {
+		t.Fatalf("oldest block mismatch: have %d, want 2", stats.OldestBlock.ToInt())
+	}
+	if len(stats.MinPriorityFee) != 3 || len(stats.WaitSamples) != 3 || len(stats.WaitTime) != 3 {
+		t.Fatalf("block count mismatch: have %d/%d/%d, want 3", len(stats.MinPriorityFee), len(stats.WaitSamples), len(stats.WaitTime))
+	}
+	for i, block := range blocks[1:] {
+		tip, _ := block.Transactions()[0].EffectiveGasTip(block.BaseFee())
+		if stats.MinPriorityFee[i].ToInt().Cmp(tip) != 0 {
+			t.Errorf("block %d: min priority fee mismatch: have %v, want %v", block.NumberU64(), stats.MinPriorityFee[i], tip)
+		}
+	}
+	if want := []hexutil.Uint64{0, 1, 0}; !reflect.DeepEqual(stats.WaitSamples, want) {
+		t.Fatalf("wait samples mismatch: have %v, want %v", stats.WaitSamples, want)
+	}
+	if waits := stats.WaitTime[1]; waits[0] < 10 || waits[1] != waits[0] {
+		t.Fatalf("wait times mismatch: have %v, want at least 10ms", waits)
+	}
+	if _, err := api.FeeStats(context.Background(), 1, 10, nil); err == nil {
+		t.Fatalf("request beyond head succeeded")
+	}
+	if _, err := api.FeeStats(context.Background(), 1, rpc.LatestBlockNumber, []float64{50, 10}); err == nil {
+		t.Fatalf("unsorted percentiles accepted")
+	}
+}
+
+// Tests that the gas used and the state growth recorded by contract at import
+// are aggregated and ranked.
+func TestScrollContractUsage(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Scroll.MaxTxPerBlock = nil
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		storer = common.Address{0x51} // Sets the slot of the block number
+		reader = common.Address{0x52} // Reads a slot
+		db     = rawdb.NewMemoryDatabase()
+		gspec  = &core.Genesis{Config: &config, Alloc: core.GenesisAlloc{
+			addr:   {Balance: big.NewInt(params.Ether)},
+			storer: {Balance: common.Big0, Code: common.FromHex("6001435500")},
+			reader: {Balance: common.Big0, Code: common.FromHex("6000545000")},
+		}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(&config)
+		created = crypto.CreateAddress(addr, 0)
+	)
+	blocks, _ := core.GenerateChain(&config, genesis, ethash.NewFaker(), db, 3, func(i int, b *core.BlockGen) {
+		send := func(to *common.Address, gas uint64, data []byte) {
+			tx := types.NewTx(&types.LegacyTx{Nonce: b.TxNonce(addr), To: to, Gas: gas, GasPrice: b.BaseFee(), Data: data})
+			tx, _ = types.SignTx(tx, signer, key)
+			b.AddTx(tx)
+		}
+		if i == 0 {
+			// Deploys 10 bytes of code
+			send(nil, 100_000, common.FromHex("600a600c600039600a6000f300000000000000000000"))
+		} else {
+			send(&reader, 50_000, nil)
+		}
+		send(&storer, 50_000, nil)
+	})
+	chain, err := core.NewBlockChain(db, &core.CacheConfig{TrieCleanNoPrefetch: true, RecordContractUsage: true}, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	api := NewPublicScrollAPI(&Ethereum{blockchain: chain, chainDb: db})
+
+	limit := hexutil.Uint64(2)
+	top, err := api.TopGasConsumers(10, rpc.LatestBlockNumber, &limit)
+	if err != nil {
+		t.Fatalf("failed to get top gas consumers: %v", err)
+	}
+	if top.OldestBlock != 0 || top.LatestBlock != 3 {
+		t.Fatalf("block range mismatch: have %d-%d, want 0-3", top.OldestBlock, top.LatestBlock)
+	}
+	if len(top.Contracts) != 2 || top.Contracts[0].Address != storer || top.Contracts[1].Address != created {
+		t.Fatalf("top gas consumers mismatch: have %v", top.Contracts)
+	}
+	if have := top.Contracts[0]; have.Transactions != 3 || have.SlotsCreated != 3 || have.SlotGrowth != 3 {
+		t.Errorf("storer usage mismatch: have %+v", have)
+	}
+	if have := top.Contracts[1]; have.Transactions != 1 || have.CodeSize != 10 {
+		t.Errorf("created contract usage mismatch: have %+v", have)
+	}
+	growth, err := api.StateGrowthByContract(10, rpc.LatestBlockNumber, nil)
+	if err != nil {
+		t.Fatalf("failed to get state growth: %v", err)
+	}
+	if len(growth.Contracts) != 2 || growth.Contracts[0].Address != storer || growth.Contracts[1].Address != created {
+		t.Fatalf("state growth mismatch: have %v", growth.Contracts)
+	}
+	// Only the requested blocks are aggregated
+	top, err = api.TopGasConsumers(1, 2, nil)
+	if err != nil {
+		t.Fatalf("failed to get top gas consumers: %v", err)
+	}
+	if len(top.Contracts) != 2 || top.Contracts[0].Address != storer || top.Contracts[1].Address != reader || top.Contracts[1].Transactions != 1 {
+		t.Fatalf("top gas consumers of block 2 mismatch: have %v", top.Contracts)
+	}
+}
+
+// Tests that the state size is counted from the genesis state and tracked as
+// blocks are imported.
+func TestScrollStateSize(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Scroll.MaxTxPerBlock = nil
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		storer = common.Address{0x51} // Sets the slot of the block number
+		db     = rawdb.NewMemoryDatabase()
+		gspec  = &core.Genesis{Config: &config, Alloc: core.GenesisAlloc{
+			addr:   {Balance: big.NewInt(params.Ether)},
+			storer: {Balance: common.Big0, Code: common.FromHex("6001435500"), Storage: map[common.Hash]common.Hash{{}: {0x01}}},
+		}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(&config)
+	)
+	blocks, _ := core.GenerateChain(&config, genesis, ethash.NewFaker(), db, 2, func(i int, b *core.BlockGen) {
+		send := func(to *common.Address, gas uint64, data []byte) {
+			tx := types.NewTx(&types.LegacyTx{Nonce: b.TxNonce(addr), To: to, Gas: gas, GasPrice: b.BaseFee(), Data: data})
+			tx, _ = types.SignTx(tx, signer, key)
+			b.AddTx(tx)
+		}
+		if i == 0 {
+			// Deploys 10 bytes of code
+			send(nil, 100_000, common.FromHex("600a600c600039600a6000f300000000000000000000"))
+		}
+		send(&storer, 50_000, nil)
+	})
+	chain, err := core.NewBlockChain(db, &core.CacheConfig{TrieCleanNoPrefetch: true, RecordStateSize: true}, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	api := NewPublicScrollAPI(&Ethereum{blockchain: chain, chainDb: db})
+
+	size, err := api.GetStateSize(0)
+	if err != nil {
+		t.Fatalf("failed to get genesis state size: %v", err)
+	}
+	if !size.Complete || size.Accounts != 2 || size.Slots != 1 || size.CodeBytes != 5 {
+		t.Fatalf("genesis state size mismatch: have %+v", size)
+	}
+	// The first block creates the contract and the coinbase
+	size, err = api.GetStateSize(1)
+	if err != nil {
+		t.Fatalf("failed to get state size: %v", err)
+	}
+	if size.Accounts != 4 || size.Slots != 2 || size.CodeBytes != 15 {
+		t.Fatalf("state size of block 1 mismatch: have %+v", size)
+	}
+	if size.AccountGrowth != 2 || size.SlotGrowth != 1 || size.CodeGrowth != 10 {
+		t.Fatalf("state growth of block 1 mismatch: have %+v", size)
+	}
+	size, err = api.GetStateSize(rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatalf("failed to get state size: %v", err)
+	}
+	if size.BlockNumber != 2 || size.Accounts != 4 || size.Slots != 3 || size.CodeBytes != 15 {
+		t.Fatalf("state size of block 2 mismatch: have %+v", size)
+	}
+	if size.AccountGrowth != 0 || size.SlotGrowth != 1 || size.CodeGrowth != 0 {
+		t.Fatalf("state growth of block 2 mismatch: have %+v", size)
+	}
+}
+
+// Tests that the chain spec hash is stable across encodings of the same config,
+// and changes with any fork parameter.
+func TestScrollGetChainSpec(t *testing.T) {
+	chain, db, _ := newScrollTestChain(t, 1, 1)
+	defer chain.Stop()
+
+	e := &Ethereum{blockchain: chain, chainDb: db, config: &ethconfig.Config{}}
+	spec, err := NewPublicScrollAPI(e).GetChainSpec()
+	if err != nil {
+		t.Fatalf("failed to get chain spec: %v", err)
+	}
+	if spec.Hash != crypto.Keccak256Hash(spec.Spec) {
+		t.Fatalf("hash mismatch: have %x, want %x", spec.Hash, crypto.Keccak256Hash(spec.Spec))
+	}
+	var decoded ChainSpec
+	if err := json.Unmarshal(spec.Spec, &decoded); err != nil {
+		t.Fatalf("failed to decode chain spec: %v", err)
+	}
+	if decoded.Genesis != chain.Genesis().Hash() || decoded.Predeploys.L1GasPriceOracle != rcfg.L1GasPriceOracleAddress {
+		t.Fatalf("chain spec mismatch: have %s", spec.Spec)
+	}
+	// A config decoded from the spec yields the same spec
+	again, err := newChainSpec(decoded.Genesis, decoded.Config)
+	if err != nil {
+		t.Fatalf("failed to recreate chain spec: %v", err)
+	}
+	if again.Hash != spec.Hash {
+		t.Fatalf("recreated hash mismatch: have %x, want %x\nhave %s\nwant %s", again.Hash, spec.Hash, again.Spec, spec.Spec)
+	}
+	// Any change of the config changes the hash
+	changed := *chain.Config()
+	changed.Scroll.NonceKeyBlock = big.NewInt(100)
+	if other, _ := newChainSpec(decoded.Genesis, &changed); other.Hash == spec.Hash {
+		t.Fatalf("hash unchanged by a fork change")
+	}
+}
@@ -41,8 +41,10 @@ import (
 	"github.com/scroll-tech/go-ethereum/eth/ethconfig"
 	"github.com/scroll-tech/go-ethereum/eth/filters"
 	"github.com/scroll-tech/go-ethereum/eth/gasprice"
+	"github.com/scroll-tech/go-ethereum/eth/membudget"
 	"github.com/scroll-tech/go-ethereum/eth/protocols/eth"
 	"github.com/scroll-tech/go-ethereum/eth/protocols/snap"
+	"github.com/scroll-tech/go-ethereum/eth/replica"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/event"
 	"github.com/scroll-tech/go-ethereum/internal/ethapi"
@@ -54,16 +56,28 @@ import (
 	"github.com/scroll-tech/go-ethereum/p2p/enode"
 	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/rollup/feerefund"
+	"github.com/scroll-tech/go-ethereum/rollup/finality"
+	"github.com/scroll-tech/go-ethereum/rollup/inclusion"
+	"github.com/scroll-tech/go-ethereum/rollup/l1origin"
+	"github.com/scroll-tech/go-ethereum/rollup/l1sload"
+	"github.com/scroll-tech/go-ethereum/rollup/migration"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
 	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/scroll-tech/go-ethereum/trie"
 )
 
 // Config contains the configuration options of the ETH protocol.
 // Deprecated: use ethconfig.Config instead.
 type Config = ethconfig.Config
 
+// errReplicaMining is returned when trying to mine on a read replica.
+var errReplicaMining = errors.New("mining is disabled in read replica mode")
+
 // Ethereum implements the Ethereum full node service.
 type Ethereum struct {
-	config *ethconfig.Config
+	config     *ethconfig.Config
+	nodeConfig *node.Config // Configuration of the node hosting the service
 
 	// Handlers
 	txPool             *core.TxPool
@@ -84,6 +98,7 @@ type Ethereum struct {
 	closeBloomHandler chan struct{}
 
 	APIBackend *EthAPIBackend
+	latency    *gasprice.LatencyTracker // Pool wait times of the transactions of recent blocks
 
 	miner     *miner.Miner
 	gasPrice  *big.Int
@@ -94,6 +109,14 @@ type Ethereum struct {
 
 	p2pServer *p2p.Server
 
+	budget *membudget.Monitor // Memory budget monitor, nil if budgeting is disabled
+
+	inclusion *inclusion.List // Transactions required to be included by the blocks produced
+
+	l1FeeRefunds *feerefund.Queue // Queue of L1 fee refunds to issue, nil if refunds are disabled
+
+	migration *migration.Service // State migration to zktrie, nil if no zktrie cutover is scheduled
+
 	lock sync.RWMutex // Protects the variadic fields (e.g. gas price and etherbase)
 }
 
@@ -142,6 +165,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 	eth := &Ethereum{
 		config:            config,
+		nodeConfig:        stack.Config(),
 		chainDb:           chainDb,
 		eventMux:          stack.EventMux(),
 		accountManager:    stack.AccountManager(),
@@ -153,6 +177,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		bloomRequests:     make(chan chan *bloombits.Retrieval),
 		bloomIndexer:      core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
 		p2pServer:         stack.Server(),
+		inclusion:         inclusion.NewList(config.InclusionListWindow),
 	}
 
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
@@ -187,8 +212,42 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			SnapshotLimit:       config.SnapshotCache,
 			Preimages:           config.Preimages,
 			MPTWitness:          config.MPTWitness,
+			RecordAccessLists:   config.RecordAccessLists,
+			LogIndex:            config.LogIndex,
+			RecordCallTraces:    config.RecordCallTraces,
+			RecordBlockStats:    config.RecordBlockStats,
+			RecordContractUsage: config.RecordContractUsage,
+			RecordStateSize:     config.RecordStateSize,
+			WithdrawTrie:        config.WithdrawTrie,
+			CreationIndex:       config.CreationIndex,
+			AccessEpochLength:   config.AccessEpochLength,
+			PreimageKind:        config.PreimageKind,
+			PreimageLimit:       config.PreimageLimit,
+
+			AllowFinalizedRewind: config.AllowFinalizedRewind,
 		}
 	)
+	// Migrating the state to zktrie ahead of the cutover needs the preimages of
+	// all trie keys
+	if chainConfig.Scroll.ZktrieCutoverBlock != nil && !chainConfig.Scroll.UseZktrie {
+		if !cacheConfig.Preimages || (cacheConfig.PreimageKind != "" && cacheConfig.PreimageKind != trie.PreimagesAll) || cacheConfig.PreimageLimit != 0 {
+			log.Warn("Recording all trie key preimages for the zktrie cutover", "block", chainConfig.Scroll.ZktrieCutoverBlock)
+		}
+		cacheConfig.Preimages, cacheConfig.PreimageKind, cacheConfig.PreimageLimit = true, trie.PreimagesAll, 0
+	}
+	// Serve the L1 storage reads of the L1SLOAD precompile from the L1 node. The
+	// blocks reading L1 storage can't be processed without.
+	if chainConfig.Scroll.L1SloadBlock != nil {
+		if config.L1Endpoint == "" {
+			log.Warn("L1SLOAD precompile scheduled without L1 endpoint, blocks reading L1 storage will be rejected", "block", chainConfig.Scroll.L1SloadBlock)
+		} else {
+			l1Client, err := l1sload.Dial(config.L1Endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("failed to connect to L1 endpoint: %v", err)
+			}
+			vmConfig.L1State = l1sload.New(l1Client)
+		}
+	}
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, chainConfig, eth.engine, vmConfig, eth.shouldPreserve, &config.TxLookupLimit)
 	if err != nil {
 		return nil, err
@@ -204,6 +263,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	config.TxPool.Database = chainDb
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
 
 	// Permit the downloader to use the trie cache allowance during fast sync
@@ -229,7 +289,14 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	eth.miner = miner.New(eth, &config.Miner, chainConfig, eth.EventMux(), eth.engine, eth.isLocalBlock)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
 
-	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil}
+	// Issue the L1 fee refunds submitted by the rollup relayer in the blocks
+	// produced locally
+	if chainConfig.Scroll.IsSystemContract(rcfg.L1FeeRefundAddress) {
+		eth.l1FeeRefunds = feerefund.NewQueue(eth.blockchain)
+		eth.miner.AddSystemTxSource(eth.l1FeeRefunds.SystemTxs)
+	}
+
+	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil, ethapi.NewResponseCache(uint64(config.RPCResponseCache) * 1024 * 1024), ethapi.NewRemoteStateProvider(config.HistoricalStateURL)}
 	if eth.APIBackend.allowUnprotectedTxs {
 		log.Info("Unprotected transactions allowed")
 	}
@@ -238,6 +305,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		gpoParams.Default = config.Miner.GasPrice
 	}
 	eth.APIBackend.gpo = gasprice.NewOracle(eth.APIBackend, gpoParams)
+	eth.latency = gasprice.NewLatencyTracker(eth.APIBackend)
 
 	// Setup DNS discovery iterators.
 	dnsclient := dnsdisc.NewClient(dnsdisc.Config{})
@@ -257,6 +325,50 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	stack.RegisterAPIs(eth.APIs())
 	stack.RegisterProtocols(eth.Protocols())
 	stack.RegisterLifecycle(eth)
+
+	// Follow the sequencer's block feed in read replica mode, registered after
+	// the backend so that it stops before the chain
+	if config.Replica != "" {
+		log.Info("Running as read replica, block production disabled", "sequencer", config.Replica)
+		replica.New(stack, eth.blockchain, config.Replica)
+	}
+	// Advance the finalized block as batches get finalized on L1
+	if config.L1Endpoint != "" {
+		l1Config := chainConfig.Scroll.L1Config
+		if l1Config == nil {
+			return nil, errors.New("L1 endpoint configured without L1 config in the chain config")
+		}
+		l1Client, err := finality.Dial(config.L1Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to L1 endpoint: %v", err)
+		}
+		finality.New(stack, l1Client, l1Config, eth.blockchain, chainDb)
+
+		// Write the L1 origin into the blocks produced locally
+		if chainConfig.Scroll.IsSystemContract(rcfg.L1BlockAddress) {
+			eth.miner.AddSystemTxSource(l1origin.New(stack, l1Client).SystemTxs)
+		}
+		// Require the transactions of the L1 inclusion lists in the blocks produced
+		if l1Config.InclusionListAddress != nil {
+			inclusion.NewL1Source(stack, l1Client, *l1Config.InclusionListAddress, eth.inclusion)
+		}
+	}
+	// Migrate the state to zktrie in the background until the zktrie cutover
+	if chainConfig.Scroll.IsZktrieCutover(chainConfig.Scroll.ZktrieCutoverBlock) {
+		eth.migration = migration.New(stack, eth.blockchain, chainDb)
+	}
+	// Keep the memory in use within the budget, flushing dirty trie nodes under
+	// pressure. The clean trie and snapshot caches live outside the Go heap.
+	if config.MemoryBudget > 0 {
+		eth.budget = membudget.NewMonitor(config.MemoryBudget, config.TrieCleanCache+config.SnapshotCache)
+		eth.budget.OnPressure(func() {
+			triedb := eth.blockchain.StateCache().TrieDB()
+			if nodes, _ := triedb.Size(); nodes > 0 {
+				triedb.Cap(nodes / 2)
+			}
+		})
+		stack.RegisterLifecycle(eth.budget)
+	}
 	// Check for unclean shutdown
 	if uncleanShutdowns, discards, err := rawdb.PushUncleanShutdownMarker(chainDb); err != nil {
 		log.Error("Could not update unclean-shutdown-marker list", "error", err)
@@ -298,6 +410,10 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	limits := filters.LogQueryLimits{
+		BlockRange: s.config.RPCLogQueryRange,
+		Results:    s.config.RPCLogQueryLimit,
+	}
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -315,6 +431,11 @@ func (s *Ethereum) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   downloader.NewPublicDownloaderAPI(s.handler.downloader, s.eventMux),
 			Public:    true,
+		}, {
+			Namespace: "scroll",
+			Version:   "1.0",
+			Service:   NewPublicScrollAPI(s),
+			Public:    true,
 		}, {
 			Namespace: "miner",
 			Version:   "1.0",
@@ -323,7 +444,7 @@ func (s *Ethereum) APIs() []rpc.API {
 		}, {
 			Namespace: "eth",
 			Version:   "1.0",
-			Service:   filters.NewPublicFilterAPI(s.APIBackend, false, 5*time.Minute),
+			Service:   filters.NewPublicFilterAPI(s.APIBackend, false, 5*time.Minute, limits),
 			Public:    true,
 		}, {
 			Namespace: "admin",
@@ -441,6 +562,9 @@ func (s *Ethereum) SetEtherbase(etherbase common.Address) {
 // is already running, this method adjust the number of threads allowed to use
 // and updates the minimum price required by the transaction pool.
 func (s *Ethereum) StartMining(threads int) error {
+	if s.IsReplica() {
+		return errReplicaMining
+	}
 	// Update the thread count within the consensus engine
 	type threaded interface {
 		SetThreads(threads int)
@@ -483,6 +607,23 @@ func (s *Ethereum) StartMining(threads int) error {
 	return nil
 }
 
+// IsReplica reports whether the node runs as a read replica, importing blocks
+// solely from a sequencer's block feed.
+func (s *Ethereum) IsReplica() bool {
+	return s.config.Replica != ""
+}
+
+// MemoryBudget returns the memory budget monitor, nil if budgeting is disabled.
+func (s *Ethereum) MemoryBudget() *membudget.Monitor {
+	return s.budget
+}
+
+// InclusionList returns the transactions required to be included by the blocks
+// produced through the consensus API.
+func (s *Ethereum) InclusionList() *inclusion.List {
+	return s.inclusion
+}
+
 // StopMining terminates the miner, both at the consensus engine level as well as
 // at the block creation level.
 func (s *Ethereum) StopMining() {
@@ -511,6 +652,7 @@ func (s *Ethereum) Downloader() *downloader.Downloader { return s.handler.downlo
 func (s *Ethereum) Synced() bool                       { return atomic.LoadUint32(&s.handler.acceptTxs) == 1 }
 func (s *Ethereum) ArchiveMode() bool                  { return s.config.NoPruning }
 func (s *Ethereum) BloomIndexer() *core.ChainIndexer   { return s.bloomIndexer }
+func (s *Ethereum) Config() *ethconfig.Config          { return s.config }
 
 // Protocols returns all the currently configured
 // network protocols to start.
@@ -554,6 +696,7 @@ func (s *Ethereum) Stop() error {
 	// Then stop everything else.
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
+	s.latency.Stop()
 	s.txPool.Stop()
 	s.miner.Close()
 	s.blockchain.Stop()
@@ -0,0 +1,290 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package blockstream streams the full data of the canonical blocks, along with
+// their receipts and recorded call traces, to a data pipeline as they're
+// imported.
+//
+// Delivery is at least once: the last block acknowledged by the sink is
+// persisted as the offset of the stream, and the stream resumes after it on
+// restart. A block may thus be delivered again after a crash, and the blocks
+// replaced by a reorg are delivered again from the common ancestor on, so
+// consumers should upsert messages by block number.
+package blockstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/internal/ethapi"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+const (
+	// DefaultTopic is the topic of the stream if none is configured.
+	DefaultTopic = "blocks"
+
+	// retryDelay is the time to wait before retrying after a failure.
+	retryDelay = 5 * time.Second
+
+	// publishTimeout is the timeout of a single publication.
+	publishTimeout = 30 * time.Second
+
+	// chainHeadChanSize is the size of the channel listening to head events.
+	chainHeadChanSize = 16
+)
+
+var (
+	offsetGauge    = metrics.NewRegisteredGauge("blockstream/offset", nil)
+	publishedMeter = metrics.NewRegisteredMeter("blockstream/published", nil)
+	failedMeter    = metrics.NewRegisteredMeter("blockstream/failed", nil)
+)
+
+// Config contains the settings of the block stream.
+type Config struct {
+	URL   string `toml:",omitempty"` // HTTP endpoint to post the blocks to
+	Topic string `toml:",omitempty"` // Topic of the stream, keying its offset (default = blocks)
+}
+
+// Message is the data of a block, as published to the sink.
+type Message struct {
+	Topic    string                         `json:"topic"`
+	Number   hexutil.Uint64                 `json:"number"`
+	Hash     common.Hash                    `json:"hash"`
+	Block    map[string]interface{}         `json:"block"`            // Block with full transactions, as served by eth_getBlockByHash
+	Receipts types.Receipts                 `json:"receipts"`         // Receipts of the transactions, in block order
+	Traces   [][]*types.InternalTransaction `json:"traces,omitempty"` // Internal transactions of the transactions, if recorded
+}
+
+// Publisher is a destination of block messages. Publishing must only succeed
+// once the message was durably accepted.
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// httpPublisher posts messages to an HTTP endpoint.
+type httpPublisher struct {
+	url string
+}
+
+// Publish implements Publisher.
+func (p *httpPublisher) Publish(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("sink responded with status %s", res.Status)
+	}
+	return nil
+}
+
+// blockChain is the view of the chain needed to stream its blocks.
+type blockChain interface {
+	Config() *params.ChainConfig
+	CurrentBlock() *types.Block
+	GetBlockByNumber(number uint64) *types.Block
+	GetHeaderByHash(hash common.Hash) *types.Header
+	GetCanonicalHash(number uint64) common.Hash
+	GetReceiptsByHash(hash common.Hash) types.Receipts
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// Service streams the canonical blocks to a publisher in the background.
+type Service struct {
+	chain     blockChain
+	db        ethdb.KeyValueStore
+	publisher Publisher
+	topic     string
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a block stream posting to the configured URL and registers it
+// with the node.
+func New(stack *node.Node, chain blockChain, db ethdb.KeyValueStore, config Config) error {
+	if config.URL == "" {
+		return errors.New("missing block stream URL")
+	}
+	stack.RegisterLifecycle(newService(chain, db, &httpPublisher{url: config.URL}, config.Topic))
+	return nil
+}
+
+func newService(chain blockChain, db ethdb.KeyValueStore, publisher Publisher, topic string) *Service {
+	if topic == "" {
+		topic = DefaultTopic
+	}
+	return &Service{
+		chain:     chain,
+		db:        db,
+		publisher: publisher,
+		topic:     topic,
+		quit:      make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, starting to stream blocks.
+func (s *Service) Start() error {
+	s.wg.Add(1)
+	go s.loop()
+
+	log.Info("Started block stream", "topic", s.topic)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the stream.
+func (s *Service) Stop() error {
+	close(s.quit)
+	s.wg.Wait()
+	return nil
+}
+
+// loop streams the blocks up to the head whenever it changes, retrying after
+// failures, until termination.
+func (s *Service) loop() {
+	defer s.wg.Done()
+
+	heads := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	sub := s.chain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-heads:
+		case <-timer.C:
+		case <-sub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+		if err := s.sync(); err != nil {
+			failedMeter.Mark(1)
+			log.Warn("Failed to stream blocks", "topic", s.topic, "err", err)
+			timer.Reset(retryDelay)
+		}
+	}
+}
+
+// sync publishes the canonical blocks after the offset up to the head,
+// advancing the offset as the publisher acknowledges them.
+func (s *Service) sync() error {
+	head := s.chain.CurrentBlock()
+	next, err := s.resume(head)
+	if err != nil {
+		return err
+	}
+	for ; next <= head.NumberU64(); next++ {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		block := s.chain.GetBlockByNumber(next)
+		if block == nil {
+			return fmt.Errorf("canonical block %d not found", next)
+		}
+		msg, err := s.message(block)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		err = s.publisher.Publish(ctx, msg)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to publish block %d: %v", next, err)
+		}
+		rawdb.WriteBlockStreamOffset(s.db, s.topic, &rawdb.BlockStreamOffset{Number: next, Hash: block.Hash()})
+		offsetGauge.Update(int64(next))
+		publishedMeter.Mark(1)
+	}
+	return nil
+}
+
+// resume returns the number of the next block to publish. A new stream starts
+// after the current head, and a stream whose offset was reorged away resumes
+// after the last canonical ancestor of the offset.
+func (s *Service) resume(head *types.Block) (uint64, error) {
+	offset := rawdb.ReadBlockStreamOffset(s.db, s.topic)
+	if offset == nil {
+		rawdb.WriteBlockStreamOffset(s.db, s.topic, &rawdb.BlockStreamOffset{Number: head.NumberU64(), Hash: head.Hash()})
+		return head.NumberU64() + 1, nil
+	}
+	number, hash := offset.Number, offset.Hash
+	for s.chain.GetCanonicalHash(number) != hash {
+		header := s.chain.GetHeaderByHash(hash)
+		if header == nil || number == 0 {
+			return 0, fmt.Errorf("stream offset %d [%x] not found", offset.Number, offset.Hash)
+		}
+		number, hash = number-1, header.ParentHash
+	}
+	if number != offset.Number {
+		log.Warn("Block stream offset reorged, resuming from common ancestor", "topic", s.topic, "offset", offset.Number, "ancestor", number)
+	}
+	return number + 1, nil
+}
+
+// message assembles the data of a block to publish.
+func (s *Service) message(block *types.Block) (*Message, error) {
+	fields, err := ethapi.RPCMarshalBlock(block, true, true, s.chain.Config())
+	if err != nil {
+		return nil, err
+	}
+	receipts := s.chain.GetReceiptsByHash(block.Hash())
+	if receipts == nil && len(block.Transactions()) > 0 {
+		return nil, fmt.Errorf("receipts of block %d not found", block.NumberU64())
+	}
+	if receipts == nil {
+		receipts = types.Receipts{}
+	}
+	return &Message{
+		Topic:    s.topic,
+		Number:   hexutil.Uint64(block.NumberU64()),
+		Hash:     block.Hash(),
+		Block:    fields,
+		Receipts: receipts,
+		Traces:   rawdb.ReadCallTraces(s.db, block.Hash()),
+	}, nil
+}
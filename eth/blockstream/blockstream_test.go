@@ -0,0 +1,121 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockstream
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// testPublisher records the messages published, failing at the given block.
+type testPublisher struct {
+	msgs   []*Message
+	failAt uint64
+}
+
+func (p *testPublisher) Publish(ctx context.Context, msg *Message) error {
+	if uint64(msg.Number) == p.failAt {
+		return errors.New("unavailable")
+	}
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+
+func (p *testPublisher) numbers() []uint64 {
+	var numbers []uint64
+	for _, msg := range p.msgs {
+		numbers = append(numbers, uint64(msg.Number))
+	}
+	return numbers
+}
+
+func TestBlockStream(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &core.Genesis{Config: params.TestChainConfig, Alloc: core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(params.TestChainConfig)
+	)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 8, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{0x01}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	fork, _ := core.GenerateChain(params.TestChainConfig, blocks[1], ethash.NewFaker(), db, 3, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x02})
+	})
+	chain, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks[:4]); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	var (
+		publisher = &testPublisher{failAt: 6}
+		s         = newService(chain, db, publisher, "")
+	)
+	// New streams start after the current head
+	if err := s.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if len(publisher.msgs) != 0 {
+		t.Fatalf("blocks before the start published: %v", publisher.numbers())
+	}
+	// Blocks are published with their receipts until the publisher fails
+	if _, err := chain.InsertChain(blocks[4:]); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	if err := s.sync(); err == nil {
+		t.Fatalf("publisher failure not reported")
+	}
+	if offset := rawdb.ReadBlockStreamOffset(db, DefaultTopic); offset.Number != 5 || offset.Hash != blocks[4].Hash() {
+		t.Fatalf("offset mismatch: have %d [%x], want 5 [%x]", offset.Number, offset.Hash, blocks[4].Hash())
+	}
+	if msg := publisher.msgs[0]; len(msg.Receipts) != 1 || msg.Receipts[0].TxHash != blocks[4].Transactions()[0].Hash() {
+		t.Fatalf("receipts mismatch: %v", msg.Receipts)
+	}
+	// Streams resume after the acknowledged offset
+	publisher.failAt = 0
+	if err := s.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if numbers := publisher.numbers(); len(numbers) != 4 || numbers[0] != 5 || numbers[3] != 8 {
+		t.Fatalf("published blocks mismatch: %v", numbers)
+	}
+	// Streams whose offset was reorged away resume from the common ancestor
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	rawdb.WriteBlockStreamOffset(db, DefaultTopic, &rawdb.BlockStreamOffset{Number: 5, Hash: fork[2].Hash()})
+	if next, err := s.resume(chain.CurrentBlock()); err != nil || next != 3 {
+		t.Fatalf("resumed block mismatch: have %d, want 3 (err %v)", next, err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/scroll-tech/go-ethereum/common"
@@ -32,35 +33,138 @@ import (
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/node"
 	chainParams "github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/l1origin"
 	"github.com/scroll-tech/go-ethereum/rpc"
 	"github.com/scroll-tech/go-ethereum/trie"
 )
 
-// Register adds catalyst APIs to the node.
+// Register adds catalyst APIs to the node, using the consensus engine of the
+// backend to seal blocks.
 func Register(stack *node.Node, backend *eth.Ethereum) error {
+	return RegisterWithConsensus(stack, backend, nil)
+}
+
+// RegisterWithConsensus adds catalyst APIs to the node, sealing blocks with the
+// given sequencer consensus. If nil, the consensus engine of the backend is used.
+func RegisterWithConsensus(stack *node.Node, backend *eth.Ethereum, consensus SequencerConsensus) error {
 	chainconfig := backend.BlockChain().Config()
 	if chainconfig.TerminalTotalDifficulty == nil {
 		return errors.New("catalyst started without valid total difficulty")
 	}
+	if backend.IsReplica() {
+		return errors.New("catalyst does not work in read replica mode")
+	}
 
 	log.Warn("Catalyst mode enabled")
+	api := newConsensusAPI(backend)
+	if consensus != nil {
+		api.consensus = consensus
+	}
+	engine, err := newEngineAPI(api, stack.Config().NodeName())
+	if err != nil {
+		return err
+	}
 	stack.RegisterAPIs([]rpc.API{
 		{
 			Namespace: "consensus",
 			Version:   "1.0",
-			Service:   newConsensusAPI(backend),
+			Service:   api,
+			Public:    true,
+		},
+		{
+			Namespace: "engine",
+			Version:   "1.0",
+			Service:   engine,
 			Public:    true,
 		},
 	})
+	// Registered after the eth backend, so stopped before it
+	stack.RegisterLifecycle(api)
 	return nil
 }
 
+// errShuttingDown is returned by block producing and inserting calls once the
+// node started shutting down.
+var errShuttingDown = errors.New("node is shutting down")
+
 type consensusAPI struct {
-	eth *eth.Ethereum
+	eth       *eth.Ethereum
+	consensus SequencerConsensus
+
+	recommit time.Duration // Interval to improve the payloads built, 0 if disabled
+	payloads payloadQueue  // Payloads built through BuildBlock
+
+	handshakes handshakes // Last handshake with a consensus client
+
+	lock   sync.RWMutex  // Held for reading by in-flight block calls, for writing on shutdown
+	closed bool          // Whether new block calls are rejected
+	quit   chan struct{} // Closed on shutdown to abort the calls waiting for their timestamp
 }
 
 func newConsensusAPI(eth *eth.Ethereum) *consensusAPI {
-	return &consensusAPI{eth: eth}
+	return &consensusAPI{
+		eth:       eth,
+		consensus: NewEngineConsensus(eth.Engine(), eth.BlockChain().Config()),
+		recommit:  eth.Config().Miner.PayloadRecommit,
+		quit:      make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle.
+func (api *consensusAPI) Start() error {
+	return nil
+}
+
+// Stop implements node.Lifecycle, rejecting any new block calls and waiting for
+// the in-flight ones to complete, so that the backend never shuts down with a
+// half-committed block.
+func (api *consensusAPI) Stop() error {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	if !api.closed {
+		close(api.quit)
+	}
+	api.closed = true
+	api.payloads.interrupt()
+	log.Info("Consensus API stopped")
+	return nil
+}
+
+// enter registers an in-flight block call, failing if the node is shutting
+// down. The returned function must be called once the call completes.
+func (api *consensusAPI) enter() (func(), error) {
+	api.lock.RLock()
+	if api.closed {
+		api.lock.RUnlock()
+		return nil, errShuttingDown
+	}
+	if !api.handshakes.compatible() {
+		api.lock.RUnlock()
+		return nil, errIncompatiblePeer
+	}
+	return api.lock.RUnlock, nil
+}
+
+// waitTimestamp waits until the given block timestamp is no longer in the
+// future, without holding up shutdown.
+func (api *consensusAPI) waitTimestamp(timestamp uint64) error {
+	now := uint64(time.Now().Unix())
+	if timestamp <= now+1 {
+		return nil
+	}
+	wait := time.Duration(timestamp-now) * time.Second
+	log.Info("Producing block too far in the future", "wait", common.PrettyDuration(wait))
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-api.quit:
+		return errShuttingDown
+	}
 }
 
 // blockExecutionEnv gathers all the data required to execute
@@ -74,6 +178,8 @@ type blockExecutionEnv struct {
 	header   *types.Header
 	txs      []*types.Transaction
 	receipts []*types.Receipt
+
+	post func(core.AssembledTxEvent) // Streams the transactions applied to the block
 }
 
 func (env *blockExecutionEnv) commitTransaction(tx *types.Transaction, coinbase common.Address) error {
@@ -86,11 +192,20 @@ func (env *blockExecutionEnv) commitTransaction(tx *types.Transaction, coinbase
 	}
 	env.txs = append(env.txs, tx)
 	env.receipts = append(env.receipts, receipt)
+	if env.post != nil {
+		env.post(core.AssembledTxEvent{
+			Number:     env.header.Number.Uint64(),
+			ParentHash: env.header.ParentHash,
+			Index:      len(env.txs) - 1,
+			Tx:         tx,
+			Receipt:    receipt,
+		})
+	}
 	return nil
 }
 
 func (api *consensusAPI) makeEnv(parent *types.Block, header *types.Header) (*blockExecutionEnv, error) {
-	state, err := api.eth.BlockChain().StateAt(parent.Root())
+	state, err := api.eth.BlockChain().StateAtChild(parent.Header())
 	if err != nil {
 		return nil, err
 	}
@@ -99,6 +214,7 @@ func (api *consensusAPI) makeEnv(parent *types.Block, header *types.Header) (*bl
 		state:   state,
 		header:  header,
 		gasPool: new(core.GasPool).AddGas(header.GasLimit),
+		post:    api.eth.Miner().PostAssembledTx,
 	}
 	return env, nil
 }
@@ -106,59 +222,157 @@ func (api *consensusAPI) makeEnv(parent *types.Block, header *types.Header) (*bl
 // AssembleBlock creates a new block, inserts it into the chain, and returns the "execution
 // data" required for eth2 clients to process the new block.
 func (api *consensusAPI) AssembleBlock(params assembleBlockParams) (*executableData, error) {
+	// Wait for the timestamp before entering, not to delay shutdown
+	if err := api.waitTimestamp(params.Timestamp); err != nil {
+		return nil, err
+	}
+	done, err := api.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if err := api.eth.MemoryBudget().Admit(); err != nil {
+		return nil, err
+	}
 	log.Info("Producing block", "parentHash", params.ParentHash)
 
-	bc := api.eth.BlockChain()
-	parent := bc.GetBlockByHash(params.ParentHash)
+	parent, err := api.assembleParent(params)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err := api.assemble(parent, params)
+	return data, err
+}
+
+// assembleParent returns the parent of the block to assemble with the given
+// parameters.
+func (api *consensusAPI) assembleParent(params assembleBlockParams) (*types.Block, error) {
+	parent := api.eth.BlockChain().GetBlockByHash(params.ParentHash)
 	if parent == nil {
 		log.Warn("Cannot assemble block with parent hash to unknown block", "parentHash", params.ParentHash)
 		return nil, fmt.Errorf("cannot assemble block with unknown parent %s", params.ParentHash)
 	}
-
-	pool := api.eth.TxPool()
-
 	if parent.Time() >= params.Timestamp {
 		return nil, fmt.Errorf("child timestamp lower than parent's: %d >= %d", parent.Time(), params.Timestamp)
 	}
-	if now := uint64(time.Now().Unix()); params.Timestamp > now+1 {
-		wait := time.Duration(params.Timestamp-now) * time.Second
-		log.Info("Producing block too far in the future", "wait", common.PrettyDuration(wait))
-		time.Sleep(wait)
+	// Refuse to assemble blocks followers would reject, or the network would
+	// consider badly timed
+	header := &types.Header{Number: new(big.Int).Add(parent.Number(), common.Big1), Time: params.Timestamp}
+	if err := misc.VerifyBlockTime(api.eth.BlockChain().Config(), parent.Header(), header); err != nil {
+		return nil, err
+	}
+	if err := api.eth.Miner().VerifyTimestamp(parent.Header(), header); err != nil {
+		return nil, err
 	}
+	return parent, nil
+}
 
+// assemble creates a new block on top of the given parent out of the pending
+// transactions of the pool, returning its execution data along with the priority
+// fees it collects.
+func (api *consensusAPI) assemble(parent *types.Block, params assembleBlockParams) (*executableData, *big.Int, error) {
+	bc := api.eth.BlockChain()
+	defer bc.Snapshots().Throttle().Begin()()
+
+	pool := api.eth.TxPool()
 	pending := pool.Pending(true)
 
 	coinbase, err := api.eth.Etherbase()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	num := parent.Number()
+	num.Add(num, common.Big1)
+	gasCeil, gasLimitDelta := api.eth.Miner().GasLimitRamp()
 	header := &types.Header{
 		ParentHash: parent.Hash(),
-		Number:     num.Add(num, common.Big1),
+		Number:     num,
 		Coinbase:   coinbase,
-		GasLimit:   parent.GasLimit(), // Keep the gas limit constant in this prototype
+		GasLimit:   misc.CalcGasLimit(bc.Config(), num, parent.GasLimit(), gasCeil, gasLimitDelta),
 		Extra:      []byte{},
 		Time:       params.Timestamp,
 	}
-	if config := api.eth.BlockChain().Config(); config.IsLondon(header.Number) {
-		header.BaseFee = misc.CalcBaseFee(config, parent.Header())
+	if err := api.consensus.Prepare(bc, parent.Header(), header); err != nil {
+		return nil, nil, err
 	}
-	err = api.eth.Engine().Prepare(bc, header)
-	if err != nil {
-		return nil, err
+	// Commit the transaction shuffle seed as the extra-data, unless structured
+	// in which case Prepare committed its leading bytes
+	shuffle := bc.Config().Scroll.IsTxShuffle(header.Number)
+	if shuffle && !bc.Config().Scroll.IsExtraSchema(header.Number) {
+		seed := types.TxShuffleSeed(header.ParentHash)
+		header.Extra = common.CopyBytes(seed[:])
 	}
 
 	env, err := api.makeEnv(parent, header)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var (
 		signer       = types.MakeSigner(bc.Config(), header.Number)
-		txHeap       = types.NewTransactionsByPriceAndNonce(signer, pending, nil)
+		txHeap       *types.TransactionsByPriceAndNonce
 		transactions []*types.Transaction
 	)
+	// Insert the protocol's system transactions ahead of everything else
+	for _, tx := range api.eth.Miner().SystemTxs(header) {
+		if !bc.Config().Scroll.IsValidTxCount(env.tcount + 1) {
+			log.Error("Transaction count limit reached by system transactions", "have", env.tcount)
+			break
+		}
+		env.state.Prepare(tx.Hash(), env.tcount)
+		if err := env.commitTransaction(tx, header.Coinbase); err != nil {
+			log.Error("Failed to apply system transaction", "hash", tx.Hash(), "err", err)
+			continue
+		}
+		env.tcount++
+		transactions = append(transactions, tx)
+	}
+	// Refuse to assemble blocks the rollup contract would reject with their batch
+	if err := l1origin.VerifyTime(bc.Config(), header, env.txs); err != nil {
+		return nil, nil, err
+	}
+	if shuffle {
+		seed := types.TxShuffleSeed(header.ParentHash)
+		txHeap = types.NewTransactionsByShuffledPriceAndNonce(signer, pending, header.BaseFee, seed, bc.Config().Scroll.TxShuffleFeeBand())
+	} else {
+		txHeap = types.NewTransactionsByPriceAndNonce(signer, pending, nil)
+	}
+	// Followers replay the shuffled order from the included transactions alone,
+	// so a skipped transaction must drop the rest of its account with it.
+	skip := (*types.TransactionsByPriceAndNonce).Shift
+	if shuffle {
+		skip = (*types.TransactionsByPriceAndNonce).Pop
+	}
+	// Require the transactions of the given and the queued L1 inclusion lists
+	// from this block on, and include the required transactions first. In
+	// shuffled ordering mode inclusion lists aren't enforced, as followers
+	// verify the order of all transactions and forced ones would break it.
+	var (
+		list     = api.eth.InclusionList()
+		required []common.Hash
+		forced   []common.Hash
+	)
+	if !shuffle {
+		required = dedupHashes(append(append([]common.Hash{}, params.InclusionList...), list.Queued()...))
+		forced = dedupHashes(append(list.Pending(), required...))
+	}
+	for _, hash := range forced {
+		if bc.GetTransactionLookup(hash) != nil {
+			continue
+		}
+		tx := pool.Get(hash)
+		if tx == nil || env.gasPool.Gas() < tx.Gas() {
+			continue
+		}
+		env.state.Prepare(tx.Hash(), env.tcount)
+		if err := env.commitTransaction(tx, header.Coinbase); err != nil {
+			log.Debug("Required transaction failed", "hash", hash, "err", err)
+			continue
+		}
+		env.tcount++
+		transactions = append(transactions, tx)
+	}
 	for {
 		if env.gasPool.Gas() < chainParams.TxGas {
 			log.Trace("Not enough gas for further transactions", "have", env.gasPool, "want", chainParams.TxGas)
@@ -174,7 +388,7 @@ func (api *consensusAPI) AssembleBlock(params assembleBlockParams) (*executableD
 
 		// Execute the transaction
 		env.state.Prepare(tx.Hash(), env.tcount)
-		err = env.commitTransaction(tx, coinbase)
+		err = env.commitTransaction(tx, header.Coinbase)
 		switch err {
 		case core.ErrGasLimitReached:
 			// Pop the current out-of-gas transaction without shifting in the next from the account
@@ -184,7 +398,7 @@ func (api *consensusAPI) AssembleBlock(params assembleBlockParams) (*executableD
 		case core.ErrNonceTooLow:
 			// New head notification data race between the transaction pool and miner, shift
 			log.Trace("Skipping transaction with low nonce", "sender", from, "nonce", tx.Nonce())
-			txHeap.Shift()
+			skip(txHeap)
 
 		case core.ErrNonceTooHigh:
 			// Reorg notification data race between the transaction pool and miner, skip account =
@@ -201,17 +415,19 @@ func (api *consensusAPI) AssembleBlock(params assembleBlockParams) (*executableD
 			// Strange error, discard the transaction and get the next in line (note, the
 			// nonce-too-high clause will prevent us from executing in vain).
 			log.Debug("Transaction failed, account skipped", "hash", tx.Hash(), "err", err)
-			txHeap.Shift()
+			skip(txHeap)
 		}
 	}
 
 	// Create the block.
-	block, err := api.eth.Engine().FinalizeAndAssemble(bc, header, env.state, transactions, nil /* uncles */, env.receipts)
+	block, err := api.consensus.Finalize(bc, header, env.state, transactions, env.receipts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return &executableData{
-		BlockHash:    block.Hash(),
+	if err := api.verifyInclusionList(block, required); err != nil {
+		return nil, nil, err
+	}
+	data := &executableData{
 		ParentHash:   block.ParentHash(),
 		Miner:        block.Coinbase(),
 		StateRoot:    block.Root(),
@@ -222,7 +438,52 @@ func (api *consensusAPI) AssembleBlock(params assembleBlockParams) (*executableD
 		ReceiptRoot:  block.ReceiptHash(),
 		LogsBloom:    block.Bloom().Bytes(),
 		Transactions: encodeTransactions(block.Transactions()),
-	}, nil
+	}
+	// Echo the required lists for the followers to require them too
+	data.InclusionList = required
+
+	// Preview the hash of the block as NewBlock rebuilds it from the payload,
+	// along with the root the consensus layer signs, so that consensus clients
+	// don't need to hash headers themselves
+	preview, err := insertBlockParamsToBlock(bc.Config(), parent.Header(), *data)
+	if err != nil {
+		return nil, nil, err
+	}
+	data.BlockHash = preview.Hash()
+	data.SigningRoot = api.eth.Engine().SealHash(preview.Header())
+
+	fees := new(big.Int)
+	for i, tx := range transactions {
+		tip := new(big.Int).SetUint64(env.receipts[i].GasUsed)
+		fees.Add(fees, tip.Mul(tip, tx.EffectiveGasTipValue(header.BaseFee)))
+	}
+	return data, fees, nil
+}
+
+// verifySigningRoot checks that the signing root of a submitted payload, if set,
+// is the one of the block rebuilt from it.
+func (api *consensusAPI) verifySigningRoot(block *types.Block, params executableData) error {
+	if params.SigningRoot == (common.Hash{}) {
+		return nil
+	}
+	if root := api.eth.Engine().SealHash(block.Header()); root != params.SigningRoot {
+		return fmt.Errorf("signing root mismatch: have %x, payload %x", root, params.SigningRoot)
+	}
+	return nil
+}
+
+// dedupHashes returns the given hashes without duplicates, in order of first
+// appearance.
+func dedupHashes(hashes []common.Hash) []common.Hash {
+	seen := make(map[common.Hash]bool, len(hashes))
+	deduped := hashes[:0]
+	for _, hash := range hashes {
+		if !seen[hash] {
+			seen[hash] = true
+			deduped = append(deduped, hash)
+		}
+	}
+	return deduped
 }
 
 func encodeTransactions(txs []*types.Transaction) [][]byte {
@@ -270,24 +531,162 @@ func insertBlockParamsToBlock(config *chainParams.ChainConfig, parent *types.Hea
 	if config.IsLondon(number) {
 		header.BaseFee = misc.CalcBaseFee(config, parent)
 	}
+	// The structured extra-data is fully determined by the chain configuration
+	// and the parent, and so is the transaction shuffle seed committed in its
+	// place
+	if config.Scroll.IsExtraSchema(number) {
+		extra := types.NewL2Extra()
+		if config.Scroll.IsProposerRotation(number) {
+			extra.ProposerIndex, _ = config.Scroll.ScheduledProposer(number)
+		}
+		if config.Scroll.IsTxShuffle(number) {
+			extra.ShuffleSeed = types.ExtraShuffleSeed(types.TxShuffleSeed(params.ParentHash))
+		}
+		header.Extra = extra.Encode()
+	} else if config.Scroll.IsTxShuffle(number) {
+		seed := types.TxShuffleSeed(params.ParentHash)
+		header.Extra = common.CopyBytes(seed[:])
+	}
 	block := types.NewBlockWithHeader(header).WithBody(txs, nil /* uncles */)
 	return block, nil
 }
 
+// PayloadToBlock assembles the block of an execution payload on top of the given
+// parent, as NewBlock does, for the tools executing payloads offline.
+func PayloadToBlock(config *chainParams.ChainConfig, parent *types.Header, payload *eth.ExecutableL2Data) (*types.Block, error) {
+	txs := make([][]byte, len(payload.Transactions))
+	for i, tx := range payload.Transactions {
+		txs[i] = tx
+	}
+	return insertBlockParamsToBlock(config, parent, executableData{
+		BlockHash:    payload.BlockHash,
+		SigningRoot:  payload.SigningRoot,
+		ParentHash:   payload.ParentHash,
+		Miner:        payload.Miner,
+		StateRoot:    payload.StateRoot,
+		Number:       uint64(payload.Number),
+		GasLimit:     uint64(payload.GasLimit),
+		GasUsed:      uint64(payload.GasUsed),
+		Timestamp:    uint64(payload.Timestamp),
+		ReceiptRoot:  payload.ReceiptRoot,
+		LogsBloom:    payload.LogsBloom,
+		Transactions: txs,
+	})
+}
+
 // NewBlock creates an Eth1 block, inserts it in the chain, and either returns true,
 // or false + an error. This is a bit redundant for go, but simplifies things on the
 // eth2 side.
+//
+// NewBlock is idempotent: resubmitting a block that is already part of the
+// canonical chain succeeds without re-executing it, while submitting a different
+// block at an already committed height fails with an alreadyCommittedError.
 func (api *consensusAPI) NewBlock(params executableData) (*newBlockResponse, error) {
-	parent := api.eth.BlockChain().GetBlockByHash(params.ParentHash)
+	done, err := api.enter()
+	if err != nil {
+		return &newBlockResponse{false}, err
+	}
+	defer done()
+
+	chain := api.eth.BlockChain()
+	parent := chain.GetBlockByHash(params.ParentHash)
 	if parent == nil {
 		return &newBlockResponse{false}, fmt.Errorf("could not find parent %x", params.ParentHash)
 	}
-	block, err := insertBlockParamsToBlock(api.eth.BlockChain().Config(), parent.Header(), params)
+	block, err := insertBlockParamsToBlock(chain.Config(), parent.Header(), params)
 	if err != nil {
 		return nil, err
 	}
-	_, err = api.eth.BlockChain().InsertChainWithoutSealVerification(block)
-	return &newBlockResponse{err == nil}, err
+	if hash := chain.GetCanonicalHash(block.NumberU64()); hash == block.Hash() {
+		return &newBlockResponse{true}, nil
+	} else if hash != (common.Hash{}) {
+		return &newBlockResponse{false}, &alreadyCommittedError{number: block.NumberU64(), committed: hash, submitted: block.Hash()}
+	}
+	if err := api.verifySigningRoot(block, params); err != nil {
+		return &newBlockResponse{false}, err
+	}
+	if err := api.consensus.VerifyHeader(chain, block.Header()); err != nil {
+		return &newBlockResponse{false}, err
+	}
+	if err := api.verifyInclusionList(block, params.InclusionList); err != nil {
+		return &newBlockResponse{false}, err
+	}
+	if _, err = chain.InsertChainWithoutSealVerification(block); err != nil {
+		return &newBlockResponse{false}, err
+	}
+	list := api.eth.InclusionList()
+	if !chain.Config().Scroll.IsTxShuffle(block.Number()) {
+		list.Require(params.InclusionList, block.NumberU64())
+	}
+	list.Included(block)
+	return &newBlockResponse{true}, nil
+}
+
+// verifyInclusionList checks that the block includes the transactions required
+// by the inclusion lists, along with the given ones it requires itself. Blocks
+// in shuffled ordering mode are exempt, as their order is fully determined by
+// the shuffle seed and leaves no room to force transactions in.
+func (api *consensusAPI) verifyInclusionList(block *types.Block, required []common.Hash) error {
+	chain := api.eth.BlockChain()
+	if chain.Config().Scroll.IsTxShuffle(block.Number()) {
+		if len(required) > 0 {
+			return fmt.Errorf("inclusion list required in shuffled ordering mode at block %d", block.NumberU64())
+		}
+		return nil
+	}
+	return api.eth.InclusionList().Verify(chain, block, required)
+}
+
+// ValidateBlock checks whether NewBlock would accept the given block, executing
+// it on top of its parent state without inserting it into the chain.
+func (api *consensusAPI) ValidateBlock(params executableData) (*genericResponse, error) {
+	done, err := api.enter()
+	if err != nil {
+		return &genericResponse{false}, err
+	}
+	defer done()
+
+	chain := api.eth.BlockChain()
+	parent := chain.GetBlockByHash(params.ParentHash)
+	if parent == nil {
+		return &genericResponse{false}, fmt.Errorf("could not find parent %x", params.ParentHash)
+	}
+	block, err := insertBlockParamsToBlock(chain.Config(), parent.Header(), params)
+	if err != nil {
+		return &genericResponse{false}, err
+	}
+	if hash := chain.GetCanonicalHash(block.NumberU64()); hash == block.Hash() {
+		return &genericResponse{true}, nil
+	} else if hash != (common.Hash{}) {
+		return &genericResponse{false}, &alreadyCommittedError{number: block.NumberU64(), committed: hash, submitted: block.Hash()}
+	}
+	if err := api.verifySigningRoot(block, params); err != nil {
+		return &genericResponse{false}, err
+	}
+	if err := api.consensus.VerifyHeader(chain, block.Header()); err != nil {
+		return &genericResponse{false}, err
+	}
+	if err := api.verifyInclusionList(block, params.InclusionList); err != nil {
+		return &genericResponse{false}, err
+	}
+	if err := chain.Validator().ValidateBody(block); err != nil {
+		if errors.Is(err, core.ErrKnownBlock) {
+			return &genericResponse{true}, nil
+		}
+		return &genericResponse{false}, err
+	}
+	statedb, err := chain.StateAtChild(parent.Header())
+	if err != nil {
+		return &genericResponse{false}, err
+	}
+	receipts, _, usedGas, err := chain.Processor().Process(block, statedb, *chain.GetVMConfig())
+	if err != nil {
+		return &genericResponse{false}, err
+	}
+	if err := chain.Validator().ValidateState(block, statedb, receipts, usedGas); err != nil {
+		return &genericResponse{false}, err
+	}
+	return &genericResponse{true}, nil
 }
 
 // Used in tests to add a the list of transactions from a block to the tx pool.
@@ -304,7 +703,11 @@ func (api *consensusAPI) FinalizeBlock(blockHash common.Hash) (*genericResponse,
 	return &genericResponse{true}, nil
 }
 
-// SetHead is called to perform a force choice.
+// SetHead is called to perform a force choice, making a known block the head
+// of the L2 chain. The chain can't be reorged below the finalized block.
 func (api *consensusAPI) SetHead(newHead common.Hash) (*genericResponse, error) {
+	if err := api.eth.BlockChain().SetL2Head(newHead); err != nil {
+		return &genericResponse{false}, err
+	}
 	return &genericResponse{true}, nil
 }
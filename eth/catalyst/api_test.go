@@ -17,9 +17,17 @@
 package catalyst
 
 import (
+	"crypto/ecdsa"
+	"errors"
 	"math/big"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/consensus"
 	"github.com/scroll-tech/go-ethereum/consensus/ethash"
 	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
@@ -29,6 +37,8 @@ import (
 	"github.com/scroll-tech/go-ethereum/eth/ethconfig"
 	"github.com/scroll-tech/go-ethereum/node"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/l1origin"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
 )
 
 var (
@@ -136,6 +146,54 @@ func TestEth2AssembleBlock(t *testing.T) {
 	}
 }
 
+// vaultConsensus is a sequencer consensus routing fees to a vault and rejecting
+// all submitted headers.
+type vaultConsensus struct {
+	SequencerConsensus
+	vault common.Address
+}
+
+func (c *vaultConsensus) Prepare(chain consensus.ChainHeaderReader, parent *types.Header, header *types.Header) error {
+	if err := c.SequencerConsensus.Prepare(chain, parent, header); err != nil {
+		return err
+	}
+	header.Coinbase = c.vault
+	return nil
+}
+
+func (c *vaultConsensus) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header) error {
+	return errors.New("rejected")
+}
+
+func TestEth2SequencerConsensus(t *testing.T) {
+	genesis, blocks := generateTestChain()
+	n, ethservice := startEthService(t, genesis, blocks[1:9])
+	defer n.Close()
+
+	api := newConsensusAPI(ethservice)
+	api.consensus = &vaultConsensus{SequencerConsensus: api.consensus, vault: common.Address{0xfe}}
+
+	signer := types.NewEIP155Signer(ethservice.BlockChain().Config().ChainID)
+	tx, err := types.SignTx(types.NewTransaction(0, blocks[8].Coinbase(), big.NewInt(1000), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, testKey)
+	if err != nil {
+		t.Fatalf("error signing transaction, err=%v", err)
+	}
+	ethservice.TxPool().AddLocal(tx)
+	execData, err := api.AssembleBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 5})
+	if err != nil {
+		t.Fatalf("error producing block, err=%v", err)
+	}
+	if execData.Miner != (common.Address{0xfe}) {
+		t.Fatalf("fee recipient mismatch: have %x, want %x", execData.Miner, common.Address{0xfe})
+	}
+	if _, err := api.ValidateBlock(*execData); err == nil || err.Error() != "rejected" {
+		t.Fatalf("header verification not delegated: %v", err)
+	}
+	if _, err := api.NewBlock(*execData); err == nil || err.Error() != "rejected" {
+		t.Fatalf("header verification not delegated: %v", err)
+	}
+}
+
 func TestEth2AssembleBlockWithAnotherBlocksTxs(t *testing.T) {
 	genesis, blocks := generateTestChain()
 	n, ethservice := startEthService(t, genesis, blocks[1:9])
@@ -223,6 +281,573 @@ func TestEth2NewBlock(t *testing.T) {
 }
 */
 
+func TestEth2NewBlockIdempotent(t *testing.T) {
+	genesis, blocks := generateTestChain()
+	n, ethservice := startEthService(t, genesis, blocks[1:9])
+	defer n.Close()
+
+	var (
+		api   = newConsensusAPI(ethservice)
+		chain = ethservice.BlockChain()
+	)
+	assemble := func(parent *types.Block, timestamp uint64) *executableData {
+		execData, err := api.AssembleBlock(assembleBlockParams{ParentHash: parent.Hash(), Timestamp: timestamp})
+		if err != nil {
+			t.Fatalf("error producing block: %v", err)
+		}
+		return execData
+	}
+	first := assemble(blocks[8], blocks[8].Time()+5)
+	if resp, err := api.NewBlock(*first); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	head := chain.CurrentBlock()
+	if head.NumberU64() != 9 {
+		t.Fatalf("head number mismatch: have %d, want 9", head.NumberU64())
+	}
+	// Resubmitting the head must succeed without touching the chain
+	if resp, err := api.NewBlock(*first); err != nil || !resp.Valid {
+		t.Fatalf("failed to resubmit head block: %v", err)
+	}
+	// Extend the chain and resubmit the now ancestor block
+	second := assemble(head, head.Time()+5)
+	if resp, err := api.NewBlock(*second); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	tip := chain.CurrentBlock()
+	if resp, err := api.NewBlock(*first); err != nil || !resp.Valid {
+		t.Fatalf("failed to resubmit ancestor block: %v", err)
+	}
+	if chain.CurrentBlock().Hash() != tip.Hash() {
+		t.Fatalf("head changed by resubmitted ancestor")
+	}
+	// A different block at an already committed height is a conflict
+	sibling := assemble(blocks[8], blocks[8].Time()+6)
+	resp, err := api.NewBlock(*sibling)
+	if resp == nil || resp.Valid {
+		t.Fatalf("conflicting block reported as valid")
+	}
+	conflict, ok := err.(*alreadyCommittedError)
+	if !ok {
+		t.Fatalf("error mismatch: have %v, want alreadyCommittedError", err)
+	}
+	if conflict.ErrorCode() != alreadyCommittedDifferentCode || conflict.committed != head.Hash() {
+		t.Fatalf("conflict mismatch: have code %d hash %x, want code %d hash %x", conflict.ErrorCode(), conflict.committed, alreadyCommittedDifferentCode, head.Hash())
+	}
+	if chain.CurrentBlock().Hash() != tip.Hash() {
+		t.Fatalf("head changed by conflicting block")
+	}
+}
+
+func TestEth2ValidateBlock(t *testing.T) {
+	genesis, blocks := generateTestChain()
+	n, ethservice := startEthService(t, genesis, blocks[1:9])
+	defer n.Close()
+
+	var (
+		api    = newConsensusAPI(ethservice)
+		chain  = ethservice.BlockChain()
+		signer = types.NewEIP155Signer(ethservice.BlockChain().Config().ChainID)
+	)
+	tx, err := types.SignTx(types.NewTransaction(0, blocks[8].Coinbase(), big.NewInt(1000), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, testKey)
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+	ethservice.TxPool().AddLocal(tx)
+
+	execData, err := api.AssembleBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 5})
+	if err != nil {
+		t.Fatalf("error producing block: %v", err)
+	}
+	if len(execData.Transactions) != 1 {
+		t.Fatalf("invalid number of transactions: have %d, want 1", len(execData.Transactions))
+	}
+	// Invalid payloads must be rejected by both endpoints
+	tests := []struct {
+		name   string
+		mutate func(data *executableData)
+	}{
+		{"state root", func(data *executableData) { data.StateRoot = common.Hash{0x01} }},
+		{"receipt root", func(data *executableData) { data.ReceiptRoot = common.Hash{0x01} }},
+		{"gas used", func(data *executableData) { data.GasUsed++ }},
+		{"transaction", func(data *executableData) { data.Transactions = [][]byte{{0x01, 0x02}} }},
+		{"parent", func(data *executableData) { data.ParentHash = common.Hash{0x01} }},
+	}
+	for _, tt := range tests {
+		data := *execData
+		tt.mutate(&data)
+		if resp, err := api.ValidateBlock(data); err == nil || resp.Success {
+			t.Errorf("%s: invalid block validated", tt.name)
+		}
+		if resp, err := api.NewBlock(data); err == nil || (resp != nil && resp.Valid) {
+			t.Errorf("%s: invalid block inserted", tt.name)
+		}
+		if head := chain.CurrentBlock().NumberU64(); head != 8 {
+			t.Fatalf("%s: head changed: have %d, want 8", tt.name, head)
+		}
+	}
+	// A valid payload is accepted without being inserted
+	if resp, err := api.ValidateBlock(*execData); err != nil || !resp.Success {
+		t.Fatalf("failed to validate block: %v", err)
+	}
+	if head := chain.CurrentBlock().NumberU64(); head != 8 {
+		t.Fatalf("head changed by validation: have %d, want 8", head)
+	}
+	if resp, err := api.NewBlock(*execData); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert validated block: %v", err)
+	}
+	// Validating a committed block succeeds, a conflicting one does not
+	if resp, err := api.ValidateBlock(*execData); err != nil || !resp.Success {
+		t.Fatalf("failed to validate committed block: %v", err)
+	}
+	sibling, err := api.AssembleBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 6})
+	if err != nil {
+		t.Fatalf("error producing block: %v", err)
+	}
+	if _, err := api.ValidateBlock(*sibling); err == nil {
+		t.Fatalf("conflicting block validated")
+	} else if _, ok := err.(*alreadyCommittedError); !ok {
+		t.Fatalf("error mismatch: have %v, want alreadyCommittedError", err)
+	}
+}
+
+func TestEth2BlockHashPreview(t *testing.T) {
+	genesis, blocks := generateTestChain()
+	n, ethservice := startEthService(t, genesis, blocks[1:9])
+	defer n.Close()
+
+	var (
+		api   = newConsensusAPI(ethservice)
+		chain = ethservice.BlockChain()
+	)
+	execData, err := api.AssembleBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 5})
+	if err != nil {
+		t.Fatalf("error producing block: %v", err)
+	}
+	// Payloads signing another root are rejected
+	forged := *execData
+	forged.SigningRoot = common.Hash{0x01}
+	if resp, err := api.ValidateBlock(forged); err == nil || resp.Success {
+		t.Fatalf("payload with forged signing root validated")
+	}
+	if resp, err := api.NewBlock(forged); err == nil || resp.Valid {
+		t.Fatalf("payload with forged signing root inserted")
+	}
+	// The previewed hash and signing root are the ones of the inserted block
+	if resp, err := api.NewBlock(*execData); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	head := chain.CurrentBlock()
+	if head.Hash() != execData.BlockHash {
+		t.Fatalf("block hash mismatch: have %x, previewed %x", head.Hash(), execData.BlockHash)
+	}
+	if root := ethservice.Engine().SealHash(head.Header()); root != execData.SigningRoot {
+		t.Fatalf("signing root mismatch: have %x, previewed %x", root, execData.SigningRoot)
+	}
+}
+
+func TestEth2ExtraSchema(t *testing.T) {
+	genesis, blocks := generateTestChain()
+	config := *genesis.Config
+	config.Scroll.ExtraSchemaBlock = big.NewInt(9)
+	genesis.Config = &config
+
+	n, ethservice := startEthService(t, genesis, blocks[1:9])
+	defer n.Close()
+
+	api := newConsensusAPI(ethservice)
+	execData, err := api.AssembleBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 5})
+	if err != nil {
+		t.Fatalf("error producing block: %v", err)
+	}
+	if resp, err := api.NewBlock(*execData); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	head := ethservice.BlockChain().CurrentBlock()
+	if head.Hash() != execData.BlockHash {
+		t.Fatalf("block hash mismatch: have %x, previewed %x", head.Hash(), execData.BlockHash)
+	}
+	if extra, err := types.DecodeL2Extra(head.Extra()); err != nil || extra.Version != types.ExtraVersion {
+		t.Fatalf("invalid structured extra-data %x: %v", head.Extra(), err)
+	}
+}
+
+func TestEth2ExtraSchemaShuffleSeed(t *testing.T) {
+	genesis, blocks := generateTestChain()
+	config := *genesis.Config
+	config.Scroll.ExtraSchemaBlock = big.NewInt(9)
+	config.Scroll.TxShuffle = &params.TxShuffleConfig{Block: big.NewInt(9)}
+	genesis.Config = &config
+
+	n, ethservice := startEthService(t, genesis, blocks[1:9])
+	defer n.Close()
+
+	api := newConsensusAPI(ethservice)
+	execData, err := api.AssembleBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 5})
+	if err != nil {
+		t.Fatalf("error producing block: %v", err)
+	}
+	block, err := insertBlockParamsToBlock(ethservice.BlockChain().Config(), blocks[8].Header(), *execData)
+	if err != nil {
+		t.Fatalf("failed to convert block: %v", err)
+	}
+	want := types.ExtraShuffleSeed(types.TxShuffleSeed(blocks[8].Hash()))
+	if extra, err := types.DecodeL2Extra(block.Extra()); err != nil || extra.ShuffleSeed != want {
+		t.Fatalf("shuffle seed mismatch in structured extra-data %x, want %x (err %v)", block.Extra(), want, err)
+	}
+	// Blocks committing to another seed are rejected
+	header := block.Header()
+	header.Extra[len(header.Extra)-1] ^= 0xff
+	forged := types.NewBlockWithHeader(header).WithBody(block.Transactions(), nil)
+	if err := ethservice.BlockChain().Validator().ValidateBody(forged); !errors.Is(err, types.ErrInvalidShuffleSeed) {
+		t.Fatalf("forged shuffle seed error mismatch: have %v, want %v", err, types.ErrInvalidShuffleSeed)
+	}
+	if resp, err := api.NewBlock(*execData); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+}
+
+func TestEth2TxShuffle(t *testing.T) {
+	genesis, _ := generateTestChain()
+	config := *genesis.Config
+	config.Scroll.TxShuffle = &params.TxShuffleConfig{Block: big.NewInt(1)}
+	genesis.Config = &config
+
+	otherKey, _ := crypto.GenerateKey()
+	otherAddr := crypto.PubkeyToAddress(otherKey.PublicKey)
+	genesis.Alloc[otherAddr] = core.GenesisAccount{Balance: testBalance}
+
+	n, ethservice := startEthService(t, genesis, nil)
+	defer n.Close()
+
+	var (
+		api    = newConsensusAPI(ethservice)
+		parent = ethservice.BlockChain().CurrentBlock()
+		signer = types.LatestSigner(&config)
+	)
+	for _, key := range []*ecdsa.PrivateKey{testKey, otherKey} {
+		for nonce := uint64(0); nonce < 3; nonce++ {
+			tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{0x01}, big.NewInt(1000), params.TxGas, big.NewInt(2*params.InitialBaseFee), nil), signer, key)
+			if err != nil {
+				t.Fatalf("failed to sign tx: %v", err)
+			}
+			if err := ethservice.TxPool().AddLocal(tx); err != nil {
+				t.Fatalf("failed to add tx: %v", err)
+			}
+		}
+	}
+	execData, err := api.AssembleBlock(assembleBlockParams{ParentHash: parent.Hash(), Timestamp: parent.Time() + 5})
+	if err != nil {
+		t.Fatalf("error producing block: %v", err)
+	}
+	if len(execData.Transactions) != 6 {
+		t.Fatalf("invalid number of transactions: have %d, want 6", len(execData.Transactions))
+	}
+	if resp, err := api.NewBlock(*execData); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	head := ethservice.BlockChain().CurrentBlock()
+	if head.Hash() != execData.BlockHash {
+		t.Fatalf("block hash mismatch: have %x, previewed %x", head.Hash(), execData.BlockHash)
+	}
+	if seed, err := types.TxShuffleSeedFromExtra(head.Extra()); err != nil || seed != types.TxShuffleSeed(parent.Hash()) {
+		t.Fatalf("shuffle seed mismatch: have %x, want %x (err %v)", seed, types.TxShuffleSeed(parent.Hash()), err)
+	}
+}
+
+func TestEth2TxShuffleSkipped(t *testing.T) {
+	genesis, _ := generateTestChain()
+	config := *genesis.Config
+	config.Scroll.TxShuffle = &params.TxShuffleConfig{Block: big.NewInt(1)}
+	genesis.Config = &config
+
+	// The second account can only afford its first transaction
+	otherKey, _ := crypto.GenerateKey()
+	otherAddr := crypto.PubkeyToAddress(otherKey.PublicKey)
+	genesis.Alloc[otherAddr] = core.GenesisAccount{Balance: testBalance}
+
+	n, ethservice := startEthService(t, genesis, nil)
+	defer n.Close()
+
+	var (
+		api    = newConsensusAPI(ethservice)
+		parent = ethservice.BlockChain().CurrentBlock()
+		signer = types.LatestSigner(&config)
+		values = map[*ecdsa.PrivateKey]*big.Int{
+			testKey:  big.NewInt(1000),
+			otherKey: new(big.Int).Div(testBalance, big.NewInt(2)),
+		}
+	)
+	for key, value := range values {
+		for nonce := uint64(0); nonce < 3; nonce++ {
+			tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{0x01}, value, params.TxGas, big.NewInt(2*params.InitialBaseFee), nil), signer, key)
+			if err != nil {
+				t.Fatalf("failed to sign tx: %v", err)
+			}
+			if err := ethservice.TxPool().AddLocal(tx); err != nil {
+				t.Fatalf("failed to add tx: %v", err)
+			}
+		}
+	}
+	execData, err := api.AssembleBlock(assembleBlockParams{ParentHash: parent.Hash(), Timestamp: parent.Time() + 5})
+	if err != nil {
+		t.Fatalf("error producing block: %v", err)
+	}
+	if len(execData.Transactions) != 4 {
+		t.Fatalf("invalid number of transactions: have %d, want 4", len(execData.Transactions))
+	}
+	// Followers must be able to replay the order without the skipped transactions
+	block, err := insertBlockParamsToBlock(ethservice.BlockChain().Config(), parent.Header(), *execData)
+	if err != nil {
+		t.Fatalf("failed to convert block: %v", err)
+	}
+	if err := ethservice.BlockChain().Validator().ValidateBody(block); err != nil {
+		t.Fatalf("failed to validate block body: %v", err)
+	}
+	if resp, err := api.NewBlock(*execData); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+}
+
+func TestEth2TxShuffleInclusionList(t *testing.T) {
+	genesis, _ := generateTestChain()
+	config := *genesis.Config
+	config.Scroll.TxShuffle = &params.TxShuffleConfig{Block: big.NewInt(1)}
+	genesis.Config = &config
+
+	n, ethservice := startEthService(t, genesis, nil)
+	defer n.Close()
+
+	var (
+		api    = newConsensusAPI(ethservice)
+		parent = ethservice.BlockChain().CurrentBlock()
+		signer = types.LatestSigner(&config)
+	)
+	tx, err := types.SignTx(types.NewTransaction(0, common.Address{0x01}, big.NewInt(1000), params.TxGas, big.NewInt(2*params.InitialBaseFee), nil), signer, testKey)
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+	if err := ethservice.TxPool().AddLocal(tx); err != nil {
+		t.Fatalf("failed to add tx: %v", err)
+	}
+	// Inclusion lists aren't enforced in shuffled ordering mode
+	ethservice.InclusionList().Queue([]common.Hash{{0x02}})
+	execData, err := api.AssembleBlock(assembleBlockParams{
+		ParentHash:    parent.Hash(),
+		Timestamp:     parent.Time() + 5,
+		InclusionList: []common.Hash{{0x01}},
+	})
+	if err != nil {
+		t.Fatalf("error producing block: %v", err)
+	}
+	if len(execData.Transactions) != 1 {
+		t.Fatalf("invalid number of transactions: have %d, want 1", len(execData.Transactions))
+	}
+	if len(execData.InclusionList) != 0 {
+		t.Fatalf("inclusion list echoed in shuffled ordering mode: %x", execData.InclusionList)
+	}
+	block, err := insertBlockParamsToBlock(ethservice.BlockChain().Config(), parent.Header(), *execData)
+	if err != nil {
+		t.Fatalf("failed to convert block: %v", err)
+	}
+	if err := ethservice.BlockChain().Validator().ValidateBody(block); err != nil {
+		t.Fatalf("failed to validate block body: %v", err)
+	}
+	// Blocks requiring transactions in shuffled ordering mode are refused
+	requiring := *execData
+	requiring.InclusionList = []common.Hash{{0x01}}
+	if resp, err := api.ValidateBlock(requiring); err == nil || resp.Success {
+		t.Fatalf("validated block requiring transactions in shuffled ordering mode")
+	}
+	if resp, err := api.NewBlock(*execData); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	if pending := ethservice.InclusionList().Pending(); len(pending) != 0 {
+		t.Fatalf("transactions required in shuffled ordering mode: %x", pending)
+	}
+}
+
+func TestEth2SystemTxs(t *testing.T) {
+	genesis, _ := generateTestChain()
+	config := *genesis.Config
+	config.Scroll.SystemTx = &params.SystemTxConfig{Block: big.NewInt(1), Contracts: []common.Address{rcfg.L1BlockAddress}}
+	config.Scroll.L1Time = &params.L1TimeConfig{Block: big.NewInt(1), MaxDrift: 60}
+	genesis.Config = &config
+
+	n, ethservice := startEthService(t, genesis, nil)
+	defer n.Close()
+
+	var (
+		api    = newConsensusAPI(ethservice)
+		parent = ethservice.BlockChain().CurrentBlock()
+		signer = types.LatestSigner(&config)
+	)
+	tx, err := types.SignTx(types.NewTransaction(0, common.Address{0x01}, big.NewInt(1000), params.TxGas, big.NewInt(2*params.InitialBaseFee), nil), signer, testKey)
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+	if err := ethservice.TxPool().AddLocal(tx); err != nil {
+		t.Fatalf("failed to add tx: %v", err)
+	}
+	// Blocks bounded by their L1 origin are refused without one
+	if _, err := api.AssembleBlock(assembleBlockParams{ParentHash: parent.Hash(), Timestamp: parent.Time() + 5}); !errors.Is(err, l1origin.ErrMissingOrigin) {
+		t.Fatalf("assembled block without L1 origin: have %v, want %v", err, l1origin.ErrMissingOrigin)
+	}
+	origin := &l1origin.Origin{Number: 1, Time: parent.Time() + 1, BaseFee: big.NewInt(1)}
+	ethservice.Miner().AddSystemTxSource(func(header *types.Header) types.Transactions {
+		return types.Transactions{types.NewSystemTx(header.Number.Uint64(), rcfg.L1BlockAddress, origin.Calldata())}
+	})
+	execData, err := api.AssembleBlock(assembleBlockParams{ParentHash: parent.Hash(), Timestamp: parent.Time() + 5})
+	if err != nil {
+		t.Fatalf("error producing block: %v", err)
+	}
+	if resp, err := api.NewBlock(*execData); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	head := ethservice.BlockChain().CurrentBlock()
+	if head.Hash() != execData.BlockHash {
+		t.Fatalf("block hash mismatch: have %x, previewed %x", head.Hash(), execData.BlockHash)
+	}
+	if txs := head.Transactions(); len(txs) != 2 || !txs[0].IsSystemTx() || txs[1].Hash() != tx.Hash() {
+		t.Fatalf("block transactions mismatch: have %d, want the system transaction followed by %x", len(txs), tx.Hash())
+	}
+	if have := l1origin.FromBlock(head); have == nil || have.Time != origin.Time {
+		t.Fatalf("L1 origin mismatch: have %v, want %v", have, origin)
+	}
+}
+
+func TestEth2GasLimitSchedule(t *testing.T) {
+	genesis, _ := generateTestChain()
+	config := *genesis.Config
+	config.Scroll.GasLimitSchedule = []params.GasLimitScheduleConfig{{Block: big.NewInt(1), GasLimit: 10000000, MaxDelta: 1000}}
+	genesis.Config = &config
+
+	n, ethservice := startEthService(t, genesis, nil)
+	defer n.Close()
+
+	// Assembled blocks ramp towards the scheduled gas limit
+	api := newConsensusAPI(ethservice)
+	parent := ethservice.BlockChain().CurrentBlock()
+	execData, err := api.AssembleBlock(assembleBlockParams{ParentHash: parent.Hash(), Timestamp: parent.Time() + 5})
+	if err != nil {
+		t.Fatalf("error producing block: %v", err)
+	}
+	if want := parent.GasLimit() + 1000; execData.GasLimit != want {
+		t.Fatalf("gas limit mismatch: have %d, want %d", execData.GasLimit, want)
+	}
+	if resp, err := api.NewBlock(*execData); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+}
+
+func TestEth2BlockTime(t *testing.T) {
+	genesis, blocks := generateTestChain()
+	config := *genesis.Config
+	config.Scroll.BlockTime = &params.BlockTimeConfig{Block: big.NewInt(9), MinBlockTime: 10}
+	genesis.Config = &config
+
+	n, ethservice := startEthService(t, genesis, blocks[1:9])
+	defer n.Close()
+
+	// Blocks violating the minimum block time are refused rather than assembled
+	api := newConsensusAPI(ethservice)
+	if _, err := api.AssembleBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 5}); err == nil {
+		t.Fatalf("assembled block violating the minimum block time")
+	}
+	if _, err := api.BuildBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 5}); err == nil {
+		t.Fatalf("built block violating the minimum block time")
+	}
+	execData, err := api.AssembleBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 10})
+	if err != nil {
+		t.Fatalf("error producing block: %v", err)
+	}
+	if resp, err := api.NewBlock(*execData); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+}
+
+func TestEth2Shutdown(t *testing.T) {
+	genesis, blocks := generateTestChain()
+	n, ethservice := startEthService(t, genesis, blocks[1:9])
+	defer n.Close()
+
+	api := newConsensusAPI(ethservice)
+	execData, err := api.AssembleBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 5})
+	if err != nil {
+		t.Fatalf("error producing block: %v", err)
+	}
+	// Calls waiting for a future timestamp don't hold up the shutdown
+	waiting := make(chan error, 1)
+	go func() {
+		_, err := api.AssembleBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: uint64(time.Now().Unix()) + 3600})
+		waiting <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err := api.Stop(); err != nil {
+		t.Fatalf("failed to stop consensus API: %v", err)
+	}
+	select {
+	case err := <-waiting:
+		if err != errShuttingDown {
+			t.Fatalf("waiting assemble error mismatch: have %v, want %v", err, errShuttingDown)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("waiting assemble not aborted by shutdown")
+	}
+	if _, err := api.AssembleBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 5}); err != errShuttingDown {
+		t.Fatalf("assemble error mismatch: have %v, want %v", err, errShuttingDown)
+	}
+	if resp, err := api.NewBlock(*execData); err != errShuttingDown || resp.Valid {
+		t.Fatalf("new block error mismatch: have %v, want %v", err, errShuttingDown)
+	}
+	if head := ethservice.BlockChain().CurrentBlock().NumberU64(); head != 8 {
+		t.Fatalf("head changed after shutdown: have %d, want 8", head)
+	}
+}
+
+func TestEth2Handshake(t *testing.T) {
+	genesis, blocks := generateTestChain()
+	n, ethservice := startEthService(t, genesis, blocks[1:9])
+	defer n.Close()
+
+	api := newConsensusAPI(ethservice)
+	engine, err := newEngineAPI(api, "test")
+	if err != nil {
+		t.Fatalf("failed to create engine API: %v", err)
+	}
+	if status := engine.L2Status(); status.Peer != nil || uint64(status.Number) != 8 {
+		t.Fatalf("status mismatch before handshake: have %+v", status)
+	}
+	// A consensus client on another chain spec is refused, along with its block calls
+	peer := engineVersion{Client: "consensus", ChainSpecHash: common.Hash{0x01}, PayloadVersions: payloadVersions}
+	if _, err := engine.Hello(peer); err == nil {
+		t.Fatalf("handshake succeeded with mismatched chain spec")
+	}
+	if _, err := api.AssembleBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 5}); err != errIncompatiblePeer {
+		t.Fatalf("assemble error mismatch: have %v, want %v", err, errIncompatiblePeer)
+	}
+	if status := engine.L2Status(); status.Peer == nil || status.Peer.Compatible || len(status.Peer.Mismatches) != 1 {
+		t.Fatalf("status mismatch after failed handshake: have %+v", status.Peer)
+	}
+	// No payload version in common
+	peer.ChainSpecHash, peer.PayloadVersions = engine.version.ChainSpecHash, []hexutil.Uint64{2}
+	if _, err := engine.Hello(peer); err == nil {
+		t.Fatalf("handshake succeeded without common payload version")
+	}
+	// A matching consensus client lifts the refusal
+	peer.PayloadVersions = []hexutil.Uint64{1, 2}
+	result, err := engine.Hello(peer)
+	if err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	if !result.Compatible || result.PayloadVersion != 1 {
+		t.Fatalf("handshake result mismatch: have %+v", result)
+	}
+	if _, err := api.AssembleBlock(assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 5}); err != nil {
+		t.Fatalf("error producing block after handshake: %v", err)
+	}
+}
+
 // startEthService creates a full node instance for testing.
 func startEthService(t *testing.T, genesis *core.Genesis, blocks []*types.Block) (*node.Node, *eth.Ethereum) {
 	t.Helper()
@@ -232,7 +857,7 @@ func startEthService(t *testing.T, genesis *core.Genesis, blocks []*types.Block)
 		t.Fatal("can't create node:", err)
 	}
 
-	ethcfg := &ethconfig.Config{Genesis: genesis, Ethash: ethash.Config{PowMode: ethash.ModeFake}}
+	ethcfg := &ethconfig.Config{Genesis: genesis, Ethash: ethash.Config{PowMode: ethash.ModeFullFake}}
 	ethservice, err := eth.New(n, ethcfg)
 	if err != nil {
 		t.Fatal("can't create eth service:", err)
@@ -248,3 +873,161 @@ func startEthService(t *testing.T, genesis *core.Genesis, blocks []*types.Block)
 
 	return n, ethservice
 }
+
+func TestEth2InclusionList(t *testing.T) {
+	genesis, blocks := generateTestChain()
+	n, ethservice := startEthService(t, genesis, blocks[1:9])
+	defer n.Close()
+
+	var (
+		api    = newConsensusAPI(ethservice)
+		chain  = ethservice.BlockChain()
+		signer = types.NewEIP155Signer(ethservice.BlockChain().Config().ChainID)
+	)
+	tx, err := types.SignTx(types.NewTransaction(0, blocks[8].Coinbase(), big.NewInt(1000), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, testKey)
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+	ethservice.TxPool().AddLocal(tx)
+
+	// Required transactions are included and echoed in the produced block
+	execData, err := api.AssembleBlock(assembleBlockParams{
+		ParentHash:    blocks[8].Hash(),
+		Timestamp:     blocks[8].Time() + 5,
+		InclusionList: []common.Hash{tx.Hash()},
+	})
+	if err != nil {
+		t.Fatalf("error producing block: %v", err)
+	}
+	if len(execData.Transactions) != 1 {
+		t.Fatalf("invalid number of transactions: have %d, want 1", len(execData.Transactions))
+	}
+	if !reflect.DeepEqual(execData.InclusionList, []common.Hash{tx.Hash()}) {
+		t.Fatalf("inclusion list mismatch: have %x, want %x", execData.InclusionList, tx.Hash())
+	}
+	// A block requiring a transaction it doesn't include is accepted within
+	// the window
+	missing := *execData
+	missing.InclusionList = append(missing.InclusionList, common.Hash{0x01})
+	if resp, err := api.ValidateBlock(missing); err != nil || !resp.Success {
+		t.Fatalf("failed to validate block: %v", err)
+	}
+	if resp, err := api.NewBlock(*execData); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	if head := chain.CurrentBlock().NumberU64(); head != 9 {
+		t.Fatalf("head mismatch: have %d, want 9", head)
+	}
+	if pending := ethservice.InclusionList().Pending(); len(pending) != 0 {
+		t.Fatalf("included transaction still pending: %x", pending)
+	}
+}
+
+func TestEth2BuildBlock(t *testing.T) {
+	genesis, blocks := generateTestChain()
+	n, ethservice := startEthService(t, genesis, blocks[1:9])
+	defer n.Close()
+
+	var (
+		api         = newConsensusAPI(ethservice)
+		signer      = types.NewEIP155Signer(ethservice.BlockChain().Config().ChainID)
+		blockParams = assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: uint64(time.Now().Unix()) + 5}
+	)
+	api.recommit = 20 * time.Millisecond
+
+	sendTx := func(nonce uint64) {
+		tx, err := types.SignTx(types.NewTransaction(nonce, blocks[8].Coinbase(), big.NewInt(1000), params.TxGas, big.NewInt(2*params.InitialBaseFee), nil), signer, testKey)
+		if err != nil {
+			t.Fatalf("failed to sign tx: %v", err)
+		}
+		if err := ethservice.TxPool().AddLocal(tx); err != nil {
+			t.Fatalf("failed to add tx: %v", err)
+		}
+	}
+	id, err := api.BuildBlock(blockParams)
+	if err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	if again, err := api.BuildBlock(blockParams); err != nil || again != id {
+		t.Fatalf("rebuilding mismatch: have %s, want %s (err %v)", again, id, err)
+	}
+	// Transactions arriving while the slot allows improve the payload
+	sendTx(0)
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		p := api.payloads.get(id)
+		p.lock.Lock()
+		included := len(p.best.Transactions)
+		p.lock.Unlock()
+		if included == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("payload not improved with new transaction")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	execData, err := api.GetL2Payload(id)
+	if err != nil {
+		t.Fatalf("failed to get payload: %v", err)
+	}
+	if len(execData.Transactions) != 1 {
+		t.Fatalf("invalid number of transactions: have %d, want 1", len(execData.Transactions))
+	}
+	// Fetched payloads are no longer improved
+	sendTx(1)
+	time.Sleep(10 * api.recommit)
+	if again, err := api.GetL2Payload(id); err != nil || again.BlockHash != execData.BlockHash {
+		t.Fatalf("fetched payload changed: have %x, want %x (err %v)", again.BlockHash, execData.BlockHash, err)
+	}
+	if resp, err := api.NewBlock(*execData); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	if _, err := api.GetL2Payload(payloadID{0x01}); !errors.Is(err, errUnknownPayload) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errUnknownPayload)
+	}
+}
+
+func TestEth2BuildBlockConcurrent(t *testing.T) {
+	genesis, blocks := generateTestChain()
+	n, ethservice := startEthService(t, genesis, blocks[1:9])
+	defer n.Close()
+
+	var (
+		api         = newConsensusAPI(ethservice)
+		blockParams = assembleBlockParams{ParentHash: blocks[8].Hash(), Timestamp: blocks[8].Time() + 5}
+	)
+	// Payloads aren't improved unless enabled
+	if api.recommit != 0 {
+		t.Fatalf("payload recommit enabled by default: %v", api.recommit)
+	}
+	// Concurrent builds of the same payload all return it, tracked once
+	var (
+		ids  = make([]payloadID, 4)
+		errs = make([]error, len(ids))
+		wg   sync.WaitGroup
+	)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ids[i], errs[i] = api.BuildBlock(blockParams)
+		}(i)
+	}
+	wg.Wait()
+	for i := range ids {
+		if errs[i] != nil || ids[i] != computePayloadID(blockParams) {
+			t.Fatalf("build %d mismatch: have %s, want %s (err %v)", i, ids[i], computePayloadID(blockParams), errs[i])
+		}
+	}
+	if tracked := len(api.payloads.payloads); tracked != 1 {
+		t.Fatalf("tracked payloads mismatch: have %d, want 1", tracked)
+	}
+	execData, err := api.GetL2Payload(ids[0])
+	if err != nil {
+		t.Fatalf("failed to get payload: %v", err)
+	}
+	if resp, err := api.NewBlock(*execData); err != nil || !resp.Valid {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+}
@@ -17,6 +17,8 @@
 package catalyst
 
 import (
+	"fmt"
+
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/common/hexutil"
 )
@@ -27,6 +29,8 @@ import (
 type assembleBlockParams struct {
 	ParentHash common.Hash `json:"parentHash"    gencodec:"required"`
 	Timestamp  uint64      `json:"timestamp"     gencodec:"required"`
+
+	InclusionList []common.Hash `json:"inclusionList,omitempty"` // Transactions to require from this block on
 }
 
 // JSON type overrides for assembleBlockParams.
@@ -39,6 +43,7 @@ type assembleBlockParamsMarshaling struct {
 // Structure described at https://notes.ethereum.org/@n0ble/rayonism-the-merge-spec#Parameters1
 type executableData struct {
 	BlockHash    common.Hash    `json:"blockHash"     gencodec:"required"`
+	SigningRoot  common.Hash    `json:"signingRoot"`
 	ParentHash   common.Hash    `json:"parentHash"    gencodec:"required"`
 	Miner        common.Address `json:"miner"         gencodec:"required"`
 	StateRoot    common.Hash    `json:"stateRoot"     gencodec:"required"`
@@ -49,6 +54,8 @@ type executableData struct {
 	ReceiptRoot  common.Hash    `json:"receiptsRoot"  gencodec:"required"`
 	LogsBloom    []byte         `json:"logsBloom"     gencodec:"required"`
 	Transactions [][]byte       `json:"transactions"  gencodec:"required"`
+
+	InclusionList []common.Hash `json:"inclusionList,omitempty"` // Transactions required from this block on
 }
 
 // JSON type overrides for executableData.
@@ -61,6 +68,32 @@ type executableDataMarshaling struct {
 	Transactions []hexutil.Bytes
 }
 
+// alreadyCommittedDifferentCode is the JSON-RPC error code returned when a block
+// is submitted at a height where a different block is already committed.
+const alreadyCommittedDifferentCode = -38100
+
+// alreadyCommittedError is returned by NewBlock if the canonical chain already
+// contains a different block at the height of the submitted one.
+type alreadyCommittedError struct {
+	number    uint64
+	committed common.Hash
+	submitted common.Hash
+}
+
+func (e *alreadyCommittedError) Error() string {
+	return fmt.Sprintf("ALREADY_COMMITTED_DIFFERENT: block #%d already committed as %x, submitted %x", e.number, e.committed, e.submitted)
+}
+
+// ErrorCode returns the JSON error code for a conflicting block submission.
+func (e *alreadyCommittedError) ErrorCode() int {
+	return alreadyCommittedDifferentCode
+}
+
+// ErrorData returns the hash of the already committed block.
+func (e *alreadyCommittedError) ErrorData() interface{} {
+	return e.committed
+}
+
 type newBlockResponse struct {
 	Valid bool `json:"valid"`
 }
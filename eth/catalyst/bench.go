@@ -0,0 +1,350 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package catalyst
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/eth"
+	"github.com/scroll-tech/go-ethereum/eth/downloader"
+	"github.com/scroll-tech/go-ethereum/eth/ethconfig"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/p2p"
+	chainParams "github.com/scroll-tech/go-ethereum/params"
+)
+
+// Synthetic workloads supported by the sequencer benchmark.
+const (
+	WorkloadERC20   = "erc20"   // Token transfers to fresh recipients
+	WorkloadSwap    = "swap"    // Constant product swaps against a single pool
+	WorkloadStorage = "storage" // Transactions writing many fresh storage slots
+)
+
+var (
+	// benchTokenAddress, benchPoolAddress and benchStoreAddress are the genesis
+	// addresses of the contracts driven by the workloads.
+	benchTokenAddress = common.HexToAddress("0x000000000000000000000000000000000000b001")
+	benchPoolAddress  = common.HexToAddress("0x000000000000000000000000000000000000b002")
+	benchStoreAddress = common.HexToAddress("0x000000000000000000000000000000000000b003")
+
+	// benchTokenCode implements transfer(to, amount) with balances stored
+	// under the holder addresses.
+	benchTokenCode = []byte{
+		byte(vm.PUSH1), 0x20, byte(vm.CALLDATALOAD), // amount
+		byte(vm.DUP1), byte(vm.CALLER), byte(vm.SLOAD), byte(vm.SUB), // balance[caller] - amount
+		byte(vm.CALLER), byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x00, byte(vm.CALLDATALOAD), // to
+		byte(vm.DUP1), byte(vm.SLOAD), byte(vm.DUP3), byte(vm.ADD), // balance[to] + amount
+		byte(vm.SWAP1), byte(vm.SSTORE),
+		byte(vm.POP), byte(vm.STOP),
+	}
+	// benchPoolCode implements swap(amountIn) against the constant product
+	// reserves in slots 0 and 1, crediting the output to the caller.
+	benchPoolCode = []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.CALLDATALOAD), // in
+		byte(vm.PUSH1), 0x00, byte(vm.SLOAD), byte(vm.DUP2), byte(vm.ADD), // reserve0 + in
+		byte(vm.DUP1), byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x01, byte(vm.SLOAD), // reserve1
+		byte(vm.DUP1), byte(vm.DUP4), byte(vm.MUL), byte(vm.DUP3), byte(vm.SWAP1), byte(vm.DIV), // out = reserve1 * in / (reserve0 + in)
+		byte(vm.DUP1), byte(vm.SWAP2), byte(vm.SUB), // reserve1 - out
+		byte(vm.PUSH1), 0x01, byte(vm.SSTORE),
+		byte(vm.CALLER), byte(vm.SLOAD), byte(vm.ADD), byte(vm.CALLER), byte(vm.SSTORE), // balance[caller] + out
+		byte(vm.POP), byte(vm.POP), byte(vm.STOP),
+	}
+	// benchStoreCode implements write(count, base), storing base+i into slot
+	// base+i for every i below count.
+	benchStoreCode = []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.CALLDATALOAD), // count
+		byte(vm.PUSH1), 0x20, byte(vm.CALLDATALOAD), // base
+		byte(vm.JUMPDEST), // 0x06: loop
+		byte(vm.DUP2), byte(vm.ISZERO), byte(vm.PUSH1), 0x1b, byte(vm.JUMPI),
+		byte(vm.DUP1), byte(vm.DUP1), byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x01, byte(vm.ADD),
+		byte(vm.SWAP1), byte(vm.PUSH1), 0x01, byte(vm.SWAP1), byte(vm.SUB), byte(vm.SWAP1),
+		byte(vm.PUSH1), 0x06, byte(vm.JUMP),
+		byte(vm.JUMPDEST), byte(vm.STOP), // 0x1b: done
+	}
+)
+
+// BenchConfig configures a sequencer benchmark run.
+type BenchConfig struct {
+	Workload      string // Synthetic workload to run
+	Blocks        int    // Number of blocks to produce
+	TxsPerBlock   int    // Number of transactions offered to every block
+	Accounts      int    // Number of sending accounts (0 = one per transaction in a block)
+	GasLimit      uint64 // Block gas limit
+	SlotsPerTx    int    // Storage slots written per transaction by the storage workload
+	DataDir       string // Database directory (empty = in memory)
+	DBEngine      string // Database backend of DataDir ("leveldb" or "pebble", empty = leveldb)
+	DatabaseCache int    // Database cache in megabytes
+}
+
+// DefaultBenchConfig contains the default settings of a sequencer benchmark run.
+var DefaultBenchConfig = BenchConfig{
+	Workload:      WorkloadERC20,
+	Blocks:        100,
+	TxsPerBlock:   200,
+	GasLimit:      30000000,
+	SlotsPerTx:    10,
+	DatabaseCache: 512,
+}
+
+// BenchResult contains the measurements of a sequencer benchmark run.
+type BenchResult struct {
+	Blocks  int           // Number of blocks produced
+	Txs     int           // Number of transactions included
+	GasUsed uint64        // Total gas used by all blocks
+	Elapsed time.Duration // Wall time spent producing blocks
+
+	AssembleTotal time.Duration // Total time spent in AssembleBlock
+	AssembleMax   time.Duration // Slowest AssembleBlock call
+	CommitTotal   time.Duration // Total time spent in NewBlock executing and committing blocks
+	CommitMax     time.Duration // Slowest NewBlock call
+}
+
+// BlocksPerSecond returns the block production rate.
+func (r *BenchResult) BlocksPerSecond() float64 {
+	if r.Elapsed == 0 {
+		return 0
+	}
+	return float64(r.Blocks) / r.Elapsed.Seconds()
+}
+
+// GasPerSecond returns the gas throughput.
+func (r *BenchResult) GasPerSecond() float64 {
+	if r.Elapsed == 0 {
+		return 0
+	}
+	return float64(r.GasUsed) / r.Elapsed.Seconds()
+}
+
+// String implements fmt.Stringer, summarizing the run.
+func (r *BenchResult) String() string {
+	var assembleAvg, commitAvg time.Duration
+	if r.Blocks > 0 {
+		assembleAvg = r.AssembleTotal / time.Duration(r.Blocks)
+		commitAvg = r.CommitTotal / time.Duration(r.Blocks)
+	}
+	return fmt.Sprintf("blocks=%d txs=%d gas=%d elapsed=%v blocks/s=%.2f gas/s=%.0f assemble(avg=%v max=%v) commit(avg=%v max=%v)",
+		r.Blocks, r.Txs, r.GasUsed, common.PrettyDuration(r.Elapsed), r.BlocksPerSecond(), r.GasPerSecond(),
+		common.PrettyDuration(assembleAvg), common.PrettyDuration(r.AssembleMax), common.PrettyDuration(commitAvg), common.PrettyDuration(r.CommitMax))
+}
+
+// benchAccount is a funded sender of benchmark transactions.
+type benchAccount struct {
+	key   *ecdsa.PrivateKey
+	addr  common.Address
+	nonce uint64
+}
+
+// benchWorkload generates the transactions of a benchmark run.
+type benchWorkload struct {
+	config   BenchConfig
+	signer   types.Signer
+	accounts []*benchAccount
+	next     int    // Index of the account sending the next transaction
+	counter  uint64 // Number of transactions generated so far
+}
+
+// tx creates the next transaction of the workload.
+func (w *benchWorkload) tx() (*types.Transaction, error) {
+	acc := w.accounts[w.next]
+	w.next = (w.next + 1) % len(w.accounts)
+	w.counter++
+
+	var (
+		to   common.Address
+		gas  uint64
+		data = make([]byte, 64)
+	)
+	switch w.config.Workload {
+	case WorkloadERC20:
+		to, gas = benchTokenAddress, 100000
+		recipient := common.BigToAddress(new(big.Int).SetUint64(0x10000000 + w.counter))
+		copy(data[12:32], recipient[:])
+		big.NewInt(1).FillBytes(data[32:64])
+	case WorkloadSwap:
+		to, gas = benchPoolAddress, 100000
+		new(big.Int).SetUint64(1000 + w.counter%1000).FillBytes(data[:32])
+	case WorkloadStorage:
+		slots := uint64(w.config.SlotsPerTx)
+		to, gas = benchStoreAddress, 50000+30000*slots
+		new(big.Int).SetUint64(slots).FillBytes(data[:32])
+		new(big.Int).SetUint64(1 + w.counter*slots).FillBytes(data[32:64])
+	default:
+		return nil, fmt.Errorf("unknown workload %q", w.config.Workload)
+	}
+	tx, err := types.SignNewTx(acc.key, w.signer, &types.DynamicFeeTx{
+		ChainID:   w.signer.ChainID(),
+		Nonce:     acc.nonce,
+		GasTipCap: big.NewInt(chainParams.GWei),
+		GasFeeCap: big.NewInt(1000 * chainParams.GWei),
+		Gas:       gas,
+		To:        &to,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	acc.nonce++
+	return tx, nil
+}
+
+// RunBenchmark measures the sequencer throughput by producing blocks of the
+// configured synthetic workload through AssembleBlock and NewBlock on a fresh
+// single node chain.
+func RunBenchmark(config BenchConfig) (*BenchResult, error) {
+	switch config.Workload {
+	case WorkloadERC20, WorkloadSwap, WorkloadStorage:
+	default:
+		return nil, fmt.Errorf("unknown workload %q", config.Workload)
+	}
+	if config.Blocks <= 0 || config.TxsPerBlock <= 0 {
+		return nil, errors.New("blocks and transactions per block must be positive")
+	}
+	if config.Accounts <= 0 {
+		config.Accounts = config.TxsPerBlock
+	}
+	if config.SlotsPerTx <= 0 {
+		config.SlotsPerTx = DefaultBenchConfig.SlotsPerTx
+	}
+	// Create the funded accounts and the genesis holding the workload contracts
+	var (
+		chainConfig = *chainParams.AllEthashProtocolChanges
+		balance     = new(big.Int).Lsh(big.NewInt(1), 128)
+		alloc       = core.GenesisAlloc{
+			benchTokenAddress: {Code: benchTokenCode, Balance: new(big.Int), Storage: map[common.Hash]common.Hash{}},
+			benchPoolAddress: {Code: benchPoolCode, Balance: new(big.Int), Storage: map[common.Hash]common.Hash{
+				{}:                            common.BigToHash(balance),
+				common.BigToHash(common.Big1): common.BigToHash(balance),
+			}},
+			benchStoreAddress: {Code: benchStoreCode, Balance: new(big.Int)},
+		}
+		workload = &benchWorkload{config: config, signer: types.LatestSigner(&chainConfig)}
+	)
+	for i := 0; i < config.Accounts; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		alloc[addr] = core.GenesisAccount{Balance: balance}
+		alloc[benchTokenAddress].Storage[common.BytesToHash(addr[:])] = common.BigToHash(balance)
+		workload.accounts = append(workload.accounts, &benchAccount{key: key, addr: addr})
+	}
+	genesis := &core.Genesis{
+		Config:    &chainConfig,
+		Alloc:     alloc,
+		GasLimit:  config.GasLimit,
+		BaseFee:   big.NewInt(chainParams.InitialBaseFee),
+		Timestamp: uint64(time.Now().Unix()) - uint64(config.Blocks) - 1,
+	}
+	// Start a standalone node producing the blocks
+	stack, err := node.New(&node.Config{
+		DataDir:  config.DataDir,
+		DBEngine: config.DBEngine,
+		P2P:      p2p.Config{NoDiscovery: true, MaxPeers: 0, ListenAddr: ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer stack.Close()
+
+	ethcfg := ethconfig.Defaults
+	ethcfg.Genesis = genesis
+	ethcfg.NetworkId = chainConfig.ChainID.Uint64()
+	ethcfg.SyncMode = downloader.FullSync
+	ethcfg.Ethash = ethash.Config{PowMode: ethash.ModeFullFake}
+	ethcfg.DatabaseCache = config.DatabaseCache
+	ethcfg.TxPool.Journal = ""
+	ethcfg.TxPool.GlobalSlots = uint64(2 * config.TxsPerBlock)
+	ethcfg.TxPool.AccountSlots = uint64(2*config.TxsPerBlock/config.Accounts + 1)
+
+	backend, err := eth.New(stack, &ethcfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := stack.Start(); err != nil {
+		return nil, err
+	}
+	backend.SetEtherbase(common.Address{0xbe, 0xef})
+
+	var (
+		api    = newConsensusAPI(backend)
+		chain  = backend.BlockChain()
+		pool   = backend.TxPool()
+		result = new(BenchResult)
+		start  = time.Now()
+	)
+	for i := 0; i < config.Blocks; i++ {
+		// Top up the pool to offer the configured number of transactions. The
+		// pool drops included transactions asynchronously, so count them here.
+		pending := int(workload.counter) - result.Txs
+		txs := make([]*types.Transaction, 0, config.TxsPerBlock)
+		for len(txs) < config.TxsPerBlock-pending {
+			tx, err := workload.tx()
+			if err != nil {
+				return nil, err
+			}
+			txs = append(txs, tx)
+		}
+		for j, err := range pool.AddLocals(txs) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to add transaction %d: %v", j, err)
+			}
+		}
+		// Assemble and commit the next block
+		parent := chain.CurrentBlock()
+
+		assembleStart := time.Now()
+		data, err := api.AssembleBlock(assembleBlockParams{ParentHash: parent.Hash(), Timestamp: parent.Time() + 1})
+		if err != nil {
+			return nil, fmt.Errorf("failed to assemble block %d: %v", parent.NumberU64()+1, err)
+		}
+		assembleTime := time.Since(assembleStart)
+
+		commitStart := time.Now()
+		if _, err := api.NewBlock(*data); err != nil {
+			return nil, fmt.Errorf("failed to commit block %d: %v", data.Number, err)
+		}
+		commitTime := time.Since(commitStart)
+
+		result.Blocks++
+		result.Txs += len(data.Transactions)
+		result.GasUsed += data.GasUsed
+		result.AssembleTotal += assembleTime
+		result.CommitTotal += commitTime
+		if assembleTime > result.AssembleMax {
+			result.AssembleMax = assembleTime
+		}
+		if commitTime > result.CommitMax {
+			result.CommitMax = commitTime
+		}
+	}
+	result.Elapsed = time.Since(start)
+	return result, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package catalyst
+
+import (
+	"testing"
+)
+
+// Tests that every workload produces blocks including the offered transactions.
+func TestRunBenchmark(t *testing.T) {
+	for _, workload := range []string{WorkloadERC20, WorkloadSwap, WorkloadStorage} {
+		config := DefaultBenchConfig
+		config.Workload = workload
+		config.Blocks = 3
+		config.TxsPerBlock = 10
+
+		result, err := RunBenchmark(config)
+		if err != nil {
+			t.Fatalf("%s: benchmark failed: %v", workload, err)
+		}
+		if result.Blocks != 3 || result.Txs != 30 {
+			t.Fatalf("%s: result mismatch: have %d blocks %d txs, want 3 blocks 30 txs", workload, result.Blocks, result.Txs)
+		}
+		if result.GasUsed == 0 {
+			t.Fatalf("%s: no gas used", workload)
+		}
+	}
+}
+
+func benchmarkSequencer(b *testing.B, workload string) {
+	config := DefaultBenchConfig
+	config.Workload = workload
+	config.Blocks = b.N
+
+	b.ResetTimer()
+	result, err := RunBenchmark(config)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportMetric(result.GasPerSecond(), "gas/s")
+	b.ReportMetric(float64(result.CommitTotal.Nanoseconds())/float64(result.Blocks), "commit-ns/block")
+}
+
+func BenchmarkSequencerERC20(b *testing.B)   { benchmarkSequencer(b, WorkloadERC20) }
+func BenchmarkSequencerSwap(b *testing.B)    { benchmarkSequencer(b, WorkloadSwap) }
+func BenchmarkSequencerStorage(b *testing.B) { benchmarkSequencer(b, WorkloadStorage) }
+
+func benchmarkSequencerDisk(b *testing.B, workload string, engine string) {
+	config := DefaultBenchConfig
+	config.Workload = workload
+	config.Blocks = b.N
+	config.DataDir = b.TempDir()
+	config.DBEngine = engine
+
+	b.ResetTimer()
+	result, err := RunBenchmark(config)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportMetric(result.GasPerSecond(), "gas/s")
+	b.ReportMetric(float64(result.CommitTotal.Nanoseconds())/float64(result.Blocks), "commit-ns/block")
+}
+
+func BenchmarkSequencerERC20LevelDB(b *testing.B) {
+	benchmarkSequencerDisk(b, WorkloadERC20, "leveldb")
+}
+func BenchmarkSequencerERC20Pebble(b *testing.B) { benchmarkSequencerDisk(b, WorkloadERC20, "pebble") }
+func BenchmarkSequencerStorageLevelDB(b *testing.B) {
+	benchmarkSequencerDisk(b, WorkloadStorage, "leveldb")
+}
+func BenchmarkSequencerStoragePebble(b *testing.B) {
+	benchmarkSequencerDisk(b, WorkloadStorage, "pebble")
+}
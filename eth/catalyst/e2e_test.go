@@ -0,0 +1,399 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package catalyst
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/eth"
+	"github.com/scroll-tech/go-ethereum/eth/downloader"
+	"github.com/scroll-tech/go-ethereum/eth/ethconfig"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/p2p"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+var (
+	e2eSeed      = flag.Int64("e2e.seed", 0, "seed of the randomized e2e workload (0 = time based)")
+	e2eBlocks    = flag.Int("e2e.blocks", 48, "number of blocks produced by the e2e sequencer")
+	e2eFollowers = flag.Int("e2e.followers", 3, "number of follower nodes in the e2e network")
+)
+
+// e2eNode is a node of the e2e network, driven through its consensus API over
+// RPC like an external consensus client would.
+type e2eNode struct {
+	name    string
+	datadir string
+	genesis *core.Genesis
+
+	stack   *node.Node
+	backend *eth.Ethereum
+	client  *rpc.Client
+}
+
+// start boots the node on its data directory, resuming any chain persisted by
+// a previous run.
+func (n *e2eNode) start(t *testing.T) {
+	t.Helper()
+
+	stack, err := node.New(&node.Config{
+		DataDir: n.datadir,
+		P2P:     p2p.Config{NoDiscovery: true, MaxPeers: 0, ListenAddr: ""},
+	})
+	if err != nil {
+		t.Fatalf("%s: failed to create node: %v", n.name, err)
+	}
+	ethcfg := ethconfig.Defaults
+	ethcfg.Genesis = n.genesis
+	ethcfg.NetworkId = n.genesis.Config.ChainID.Uint64()
+	ethcfg.SyncMode = downloader.FullSync
+	ethcfg.NoPruning = true
+	ethcfg.Ethash = ethash.Config{PowMode: ethash.ModeFullFake}
+	ethcfg.TxPool.Journal = ""
+	ethcfg.DatabaseCache = 16
+	ethcfg.TrieCleanCache = 16
+	ethcfg.TrieDirtyCache = 16
+	ethcfg.SnapshotCache = 0
+
+	backend, err := eth.New(stack, &ethcfg)
+	if err != nil {
+		t.Fatalf("%s: failed to create eth service: %v", n.name, err)
+	}
+	if err := Register(stack, backend); err != nil {
+		t.Fatalf("%s: failed to register consensus API: %v", n.name, err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("%s: failed to start node: %v", n.name, err)
+	}
+	backend.SetEtherbase(common.Address{0xbe, 0xef})
+
+	client, err := stack.Attach()
+	if err != nil {
+		t.Fatalf("%s: failed to attach to node: %v", n.name, err)
+	}
+	n.stack, n.backend, n.client = stack, backend, client
+}
+
+// stop shuts the node down, keeping its data directory.
+func (n *e2eNode) stop() {
+	n.client.Close()
+	n.stack.Close()
+}
+
+// restart stops the node and boots it again on the same data directory.
+func (n *e2eNode) restart(t *testing.T) {
+	t.Helper()
+
+	n.stop()
+	n.start(t)
+}
+
+func (n *e2eNode) head() *types.Block {
+	return n.backend.BlockChain().CurrentBlock()
+}
+
+func (n *e2eNode) assembleBlock(parent *types.Block) (*executableData, error) {
+	var data executableData
+	err := n.client.Call(&data, "consensus_assembleBlock", assembleBlockParams{ParentHash: parent.Hash(), Timestamp: parent.Time() + 1})
+	return &data, err
+}
+
+func (n *e2eNode) newBlock(data *executableData) error {
+	var resp newBlockResponse
+	if err := n.client.Call(&resp, "consensus_newBlock", data); err != nil {
+		return err
+	}
+	if !resp.Valid {
+		return errors.New("block rejected")
+	}
+	return nil
+}
+
+// e2eNetwork is a sequencer producing blocks through AssembleBlock and NewBlock,
+// and followers receiving every produced block through NewBlock, subject to
+// randomly injected faults.
+type e2eNetwork struct {
+	t    *testing.T
+	rand *rand.Rand
+
+	signer    types.Signer
+	sequencer *e2eNode
+	followers []*e2eNode
+
+	payloads []*executableData // Committed payloads by block number, excluding the genesis
+	txs      int               // Number of transactions included in committed blocks
+	stats    map[string]int    // Number of injected faults by kind
+}
+
+// e2eKey is a funded account sending the transactions of the e2e workload.
+type e2eKey struct {
+	key  *ecdsa.PrivateKey
+	addr common.Address
+}
+
+var e2eKeys = func() []*e2eKey {
+	keys := make([]*e2eKey, 8)
+	for i := range keys {
+		key, _ := crypto.ToECDSA(crypto.Keccak256([]byte{byte(i)}))
+		keys[i] = &e2eKey{key: key, addr: crypto.PubkeyToAddress(key.PublicKey)}
+	}
+	return keys
+}()
+
+func newE2ENetwork(t *testing.T, seed int64, followers int) *e2eNetwork {
+	config := *params.AllEthashProtocolChanges
+	config.TerminalTotalDifficulty = common.Big0
+
+	alloc := core.GenesisAlloc{}
+	for _, key := range e2eKeys {
+		alloc[key.addr] = core.GenesisAccount{Balance: new(big.Int).Lsh(big.NewInt(1), 128)}
+	}
+	genesis := &core.Genesis{
+		Config:    &config,
+		Alloc:     alloc,
+		GasLimit:  30_000_000,
+		BaseFee:   big.NewInt(params.InitialBaseFee),
+		Timestamp: uint64(time.Now().Unix()) - uint64(*e2eBlocks) - 1,
+	}
+	network := &e2eNetwork{
+		t:      t,
+		rand:   rand.New(rand.NewSource(seed)),
+		signer: types.LatestSigner(&config),
+		stats:  make(map[string]int),
+	}
+	newNode := func(name string) *e2eNode {
+		n := &e2eNode{name: name, datadir: t.TempDir(), genesis: genesis}
+		n.start(t)
+		return n
+	}
+	network.sequencer = newNode("sequencer")
+	for i := 0; i < followers; i++ {
+		network.followers = append(network.followers, newNode(fmt.Sprintf("follower %d", i)))
+	}
+	return network
+}
+
+func (nw *e2eNetwork) close() {
+	nw.sequencer.stop()
+	for _, f := range nw.followers {
+		f.stop()
+	}
+}
+
+// submitTxs adds a random number of transfers and contract creations from the
+// funded accounts to the sequencer's pool.
+func (nw *e2eNetwork) submitTxs() {
+	pool := nw.sequencer.backend.TxPool()
+	for i := nw.rand.Intn(12); i > 0; i-- {
+		key := e2eKeys[nw.rand.Intn(len(e2eKeys))]
+		inner := &types.DynamicFeeTx{
+			ChainID:   nw.signer.ChainID(),
+			Nonce:     pool.Nonce(key.addr),
+			GasTipCap: big.NewInt(params.GWei),
+			GasFeeCap: big.NewInt(1000 * params.GWei),
+			Gas:       params.TxGas,
+			Value:     big.NewInt(nw.rand.Int63n(params.Ether)),
+		}
+		if nw.rand.Intn(4) == 0 {
+			// Deploy a contract returning random runtime code
+			code := make([]byte, 1+nw.rand.Intn(32))
+			nw.rand.Read(code)
+			inner.Data = append([]byte{0x60, byte(len(code)), 0x80, 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3, 0x00}, code...)
+			inner.Gas = 200000
+		} else {
+			to := common.BigToAddress(big.NewInt(nw.rand.Int63()))
+			inner.To = &to
+		}
+		tx := types.MustSignNewTx(key.key, nw.signer, inner)
+		if err := pool.AddLocal(tx); err != nil {
+			nw.t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+}
+
+// produce assembles and commits the next block on the sequencer, retrying calls
+// whose request or response got dropped.
+func (nw *e2eNetwork) produce() *executableData {
+	seq := nw.sequencer
+	parent := seq.head()
+
+	if nw.drop("assemble") {
+		// The assembled payload never reached the consensus client, which
+		// simply asks again
+		if _, err := seq.assembleBlock(parent); err != nil {
+			nw.t.Fatalf("failed to assemble block #%d: %v", parent.NumberU64()+1, err)
+		}
+	}
+	data, err := seq.assembleBlock(parent)
+	if err != nil {
+		nw.t.Fatalf("failed to assemble block #%d: %v", parent.NumberU64()+1, err)
+	}
+	for {
+		if nw.drop("commit request") {
+			continue
+		}
+		err := seq.newBlock(data)
+		if nw.drop("commit response") {
+			continue
+		}
+		if err != nil {
+			nw.t.Fatalf("failed to commit block #%d: %v", data.Number, err)
+		}
+		break
+	}
+	if head := seq.head(); head.NumberU64() != data.Number {
+		nw.t.Fatalf("sequencer head mismatch: have #%d, want #%d", head.NumberU64(), data.Number)
+	}
+	nw.payloads = append(nw.payloads, data)
+	nw.txs += len(data.Transactions)
+	return data
+}
+
+// deliver hands a committed payload to a follower. Payloads extending beyond
+// the follower's head are rejected for their unknown parent, in which case the
+// missing range is backfilled first.
+func (nw *e2eNetwork) deliver(f *e2eNode, data *executableData) {
+	if nw.drop("delivery") {
+		return
+	}
+	if err := f.newBlock(data); err == nil {
+		return
+	}
+	head := f.head().NumberU64()
+	if head >= data.Number {
+		nw.t.Fatalf("%s: committed block #%d rejected at head #%d", f.name, data.Number, head)
+	}
+	nw.stats["backfill"]++
+	nw.catchUp(f)
+}
+
+// catchUp delivers all payloads missing from the follower.
+func (nw *e2eNetwork) catchUp(f *e2eNode) {
+	for number := f.head().NumberU64() + 1; number <= uint64(len(nw.payloads)); number++ {
+		if err := f.newBlock(nw.payloads[number-1]); err != nil {
+			nw.t.Fatalf("%s: failed to backfill block #%d: %v", f.name, number, err)
+		}
+	}
+}
+
+// conflict submits a different block at an already committed height to the
+// node, which must refuse to reorg its committed chain.
+func (nw *e2eNetwork) conflict(n *e2eNode) {
+	if len(nw.payloads) == 0 || n.head().NumberU64() == 0 {
+		return
+	}
+	number := 1 + nw.rand.Intn(int(n.head().NumberU64()))
+	fork := *nw.payloads[number-1]
+	fork.Timestamp++
+
+	head := n.head().Hash()
+	err := n.newBlock(&fork)
+	if rpcErr, ok := err.(rpc.Error); !ok || rpcErr.ErrorCode() != alreadyCommittedDifferentCode {
+		nw.t.Fatalf("%s: conflicting block #%d error mismatch: have %v, want code %d", n.name, number, err, alreadyCommittedDifferentCode)
+	}
+	if n.head().Hash() != head {
+		nw.t.Fatalf("%s: head changed after conflicting block #%d", n.name, number)
+	}
+	nw.stats["conflict"]++
+}
+
+// drop randomly decides whether a call of the given kind gets lost.
+func (nw *e2eNetwork) drop(kind string) bool {
+	if nw.rand.Intn(10) != 0 {
+		return false
+	}
+	nw.stats["dropped "+kind]++
+	return true
+}
+
+// checkConvergence asserts that all nodes committed the same chain.
+func (nw *e2eNetwork) checkConvergence() {
+	want := nw.sequencer.backend.BlockChain()
+	for _, f := range nw.followers {
+		have := f.backend.BlockChain()
+		if head := have.CurrentBlock(); head.Hash() != want.CurrentBlock().Hash() {
+			nw.t.Fatalf("%s: head mismatch: have #%d %x, want #%d %x", f.name, head.NumberU64(), head.Hash(), want.CurrentBlock().NumberU64(), want.CurrentBlock().Hash())
+		}
+		for number := uint64(1); number <= want.CurrentBlock().NumberU64(); number++ {
+			if have.GetCanonicalHash(number) != want.GetCanonicalHash(number) {
+				nw.t.Fatalf("%s: block #%d mismatch", f.name, number)
+			}
+		}
+		if _, err := have.StateAt(have.CurrentBlock().Root()); err != nil {
+			nw.t.Fatalf("%s: head state unavailable: %v", f.name, err)
+		}
+	}
+}
+
+// TestE2ENetwork runs a sequencer and several followers through a randomized
+// workload with injected faults, asserting that all nodes converge on the
+// sequencer's chain. Use -e2e.seed to reproduce a failing run.
+func TestE2ENetwork(t *testing.T) {
+	seed := *e2eSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t.Logf("e2e seed: %d", seed)
+
+	nw := newE2ENetwork(t, seed, *e2eFollowers)
+	defer func() { nw.close() }()
+
+	for i := 0; i < *e2eBlocks; i++ {
+		nw.submitTxs()
+		data := nw.produce()
+		for _, f := range nw.followers {
+			nw.deliver(f, data)
+		}
+		// Inject node level faults
+		switch nw.rand.Intn(10) {
+		case 0:
+			f := nw.followers[nw.rand.Intn(len(nw.followers))]
+			f.restart(t)
+			nw.stats["follower restart"]++
+		case 1:
+			nw.sequencer.restart(t)
+			nw.stats["sequencer restart"]++
+		case 2:
+			nw.conflict(nw.followers[nw.rand.Intn(len(nw.followers))])
+		case 3:
+			nw.conflict(nw.sequencer)
+		}
+	}
+	// Deliver whatever the followers missed last and compare the chains
+	for _, f := range nw.followers {
+		nw.catchUp(f)
+	}
+	nw.checkConvergence()
+
+	if nw.txs == 0 {
+		t.Fatalf("no transactions included in %d blocks", len(nw.payloads))
+	}
+	t.Logf("e2e run: %d blocks, %d txs, faults %v", len(nw.payloads), nw.txs, nw.stats)
+}
@@ -0,0 +1,223 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build gofuzz
+// +build gofuzz
+
+package catalyst
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/eth"
+	"github.com/scroll-tech/go-ethereum/eth/downloader"
+	"github.com/scroll-tech/go-ethereum/eth/ethconfig"
+	"github.com/scroll-tech/go-ethereum/node"
+	chainParams "github.com/scroll-tech/go-ethereum/params"
+)
+
+var (
+	fuzzOnce    sync.Once
+	fuzzAPI     *consensusAPI
+	fuzzPayload executableData // Valid payload all inputs are derived from
+)
+
+// fuzzSetup starts a node and assembles the valid payload mutated by the fuzzer.
+func fuzzSetup() {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	config := *chainParams.AllEthashProtocolChanges
+	genesis := &core.Genesis{
+		Config:    &config,
+		Alloc:     core.GenesisAlloc{addr: {Balance: new(big.Int).Lsh(big.NewInt(1), 100)}},
+		GasLimit:  30000000,
+		BaseFee:   big.NewInt(chainParams.InitialBaseFee),
+		Timestamp: uint64(time.Now().Unix()) - 10,
+	}
+	stack, err := node.New(&node.Config{})
+	if err != nil {
+		panic(err)
+	}
+	ethcfg := ethconfig.Defaults
+	ethcfg.Genesis = genesis
+	ethcfg.SyncMode = downloader.FullSync
+	ethcfg.Ethash = ethash.Config{PowMode: ethash.ModeFullFake}
+	ethcfg.TxPool.Journal = ""
+
+	backend, err := eth.New(stack, &ethcfg)
+	if err != nil {
+		panic(err)
+	}
+	if err := stack.Start(); err != nil {
+		panic(err)
+	}
+	backend.SetEtherbase(common.Address{0xfa})
+
+	// Assemble a block with a few transfers and contract creations
+	signer := types.LatestSigner(&config)
+	for nonce := uint64(0); nonce < 4; nonce++ {
+		var tx *types.Transaction
+		if nonce%2 == 0 {
+			tx = types.NewTransaction(nonce, common.Address{byte(nonce)}, big.NewInt(1), chainParams.TxGas, big.NewInt(2*chainParams.InitialBaseFee), nil)
+		} else {
+			tx = types.NewContractCreation(nonce, big.NewInt(0), 100000, big.NewInt(2*chainParams.InitialBaseFee), []byte{0x60, 0x01, 0x60, 0x00, 0x55})
+		}
+		signed, err := types.SignTx(tx, signer, key)
+		if err != nil {
+			panic(err)
+		}
+		if err := backend.TxPool().AddLocal(signed); err != nil {
+			panic(err)
+		}
+	}
+	fuzzAPI = newConsensusAPI(backend)
+	head := backend.BlockChain().CurrentBlock()
+	payload, err := fuzzAPI.AssembleBlock(assembleBlockParams{ParentHash: head.Hash(), Timestamp: head.Time() + 1})
+	if err != nil {
+		panic(err)
+	}
+	fuzzPayload = *payload
+}
+
+// fuzzInput is a reader of fuzzer input running out gracefully.
+type fuzzInput []byte
+
+func (in *fuzzInput) bytes(n int) []byte {
+	if n > len(*in) {
+		n = len(*in)
+	}
+	out := (*in)[:n]
+	*in = (*in)[n:]
+	return out
+}
+
+func (in *fuzzInput) byte() byte {
+	if b := in.bytes(1); len(b) == 1 {
+		return b[0]
+	}
+	return 0
+}
+
+func (in *fuzzInput) uint64() uint64 {
+	var buf [8]byte
+	copy(buf[:], in.bytes(8))
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+// mutatePayload derives a payload from the valid one by applying the mutations
+// described by the fuzzer input.
+func mutatePayload(payload executableData, in fuzzInput) executableData {
+	payload.LogsBloom = common.CopyBytes(payload.LogsBloom)
+	txs := make([][]byte, len(payload.Transactions))
+	for i, tx := range payload.Transactions {
+		txs[i] = common.CopyBytes(tx)
+	}
+	payload.Transactions = txs
+
+	for len(in) > 0 {
+		switch in.byte() % 12 {
+		case 0:
+			payload.StateRoot = common.BytesToHash(in.bytes(32))
+		case 1:
+			payload.ReceiptRoot = common.BytesToHash(in.bytes(32))
+		case 2:
+			payload.GasLimit = in.uint64()
+		case 3:
+			payload.GasUsed = in.uint64()
+		case 4:
+			payload.Timestamp = in.uint64()
+		case 5:
+			payload.Number = in.uint64()
+		case 6:
+			payload.LogsBloom = common.CopyBytes(in.bytes(int(in.byte())))
+		case 7:
+			payload.Miner = common.BytesToAddress(in.bytes(20))
+		case 8:
+			// Replace or append a transaction with arbitrary bytes
+			index := int(in.byte()) % (len(payload.Transactions) + 1)
+			tx := common.CopyBytes(in.bytes(int(in.byte())))
+			if index == len(payload.Transactions) {
+				payload.Transactions = append(payload.Transactions, tx)
+			} else {
+				payload.Transactions[index] = tx
+			}
+		case 9:
+			// Flip a byte within a transaction
+			if len(payload.Transactions) > 0 {
+				tx := payload.Transactions[int(in.byte())%len(payload.Transactions)]
+				if len(tx) > 0 {
+					tx[int(in.byte())%len(tx)] ^= in.byte() | 1
+				}
+			}
+		case 10:
+			// Drop, duplicate or swap transactions
+			if n := len(payload.Transactions); n > 0 {
+				i, j := int(in.byte())%n, int(in.byte())%n
+				switch in.byte() % 3 {
+				case 0:
+					payload.Transactions = append(payload.Transactions[:i:i], payload.Transactions[i+1:]...)
+				case 1:
+					payload.Transactions = append(payload.Transactions, payload.Transactions[i])
+				case 2:
+					payload.Transactions[i], payload.Transactions[j] = payload.Transactions[j], payload.Transactions[i]
+				}
+			}
+		case 11:
+			payload.ParentHash = common.BytesToHash(in.bytes(32))
+		}
+	}
+	return payload
+}
+
+// FuzzPayload mutates a valid block payload according to the input and checks
+// that ValidateBlock and NewBlock reach the same verdict on it without panicking.
+func FuzzPayload(data []byte) int {
+	fuzzOnce.Do(fuzzSetup)
+
+	var (
+		chain   = fuzzAPI.eth.BlockChain()
+		parent  = chain.CurrentBlock()
+		payload = mutatePayload(fuzzPayload, data)
+	)
+	validated, verr := fuzzAPI.ValidateBlock(payload)
+	inserted, ierr := fuzzAPI.NewBlock(payload)
+
+	// NewBlock returns no response at all for undecodable transactions
+	valid := inserted != nil && inserted.Valid
+	if validated.Success != valid || (verr == nil) != (ierr == nil) {
+		panic(fmt.Sprintf("inconsistent payload verdict: validate %v (%v), insert %v (%v)", validated.Success, verr, valid, ierr))
+	}
+	// Rewind the chain so every input is checked against the same parent
+	if head := chain.CurrentBlock(); head.Hash() != parent.Hash() {
+		if err := chain.SetHead(parent.NumberU64()); err != nil {
+			panic(err)
+		}
+	}
+	if !valid {
+		return 0
+	}
+	return 1
+}
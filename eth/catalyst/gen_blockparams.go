@@ -15,20 +15,23 @@ var _ = (*assembleBlockParamsMarshaling)(nil)
 // MarshalJSON marshals as JSON.
 func (a assembleBlockParams) MarshalJSON() ([]byte, error) {
 	type assembleBlockParams struct {
-		ParentHash common.Hash    `json:"parentHash"    gencodec:"required"`
-		Timestamp  hexutil.Uint64 `json:"timestamp"     gencodec:"required"`
+		ParentHash    common.Hash    `json:"parentHash"    gencodec:"required"`
+		Timestamp     hexutil.Uint64 `json:"timestamp"     gencodec:"required"`
+		InclusionList []common.Hash  `json:"inclusionList,omitempty"`
 	}
 	var enc assembleBlockParams
 	enc.ParentHash = a.ParentHash
 	enc.Timestamp = hexutil.Uint64(a.Timestamp)
+	enc.InclusionList = a.InclusionList
 	return json.Marshal(&enc)
 }
 
 // UnmarshalJSON unmarshals from JSON.
 func (a *assembleBlockParams) UnmarshalJSON(input []byte) error {
 	type assembleBlockParams struct {
-		ParentHash *common.Hash    `json:"parentHash"    gencodec:"required"`
-		Timestamp  *hexutil.Uint64 `json:"timestamp"     gencodec:"required"`
+		ParentHash    *common.Hash    `json:"parentHash"    gencodec:"required"`
+		Timestamp     *hexutil.Uint64 `json:"timestamp"     gencodec:"required"`
+		InclusionList []common.Hash   `json:"inclusionList,omitempty"`
 	}
 	var dec assembleBlockParams
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -42,5 +45,8 @@ func (a *assembleBlockParams) UnmarshalJSON(input []byte) error {
 		return errors.New("missing required field 'timestamp' for assembleBlockParams")
 	}
 	a.Timestamp = uint64(*dec.Timestamp)
+	if dec.InclusionList != nil {
+		a.InclusionList = dec.InclusionList
+	}
 	return nil
 }
@@ -15,20 +15,23 @@ var _ = (*executableDataMarshaling)(nil)
 // MarshalJSON marshals as JSON.
 func (e executableData) MarshalJSON() ([]byte, error) {
 	type executableData struct {
-		BlockHash    common.Hash     `json:"blockHash"     gencodec:"required"`
-		ParentHash   common.Hash     `json:"parentHash"    gencodec:"required"`
-		Miner        common.Address  `json:"miner"         gencodec:"required"`
-		StateRoot    common.Hash     `json:"stateRoot"     gencodec:"required"`
-		Number       hexutil.Uint64  `json:"number"        gencodec:"required"`
-		GasLimit     hexutil.Uint64  `json:"gasLimit"      gencodec:"required"`
-		GasUsed      hexutil.Uint64  `json:"gasUsed"       gencodec:"required"`
-		Timestamp    hexutil.Uint64  `json:"timestamp"     gencodec:"required"`
-		ReceiptRoot  common.Hash     `json:"receiptsRoot"  gencodec:"required"`
-		LogsBloom    hexutil.Bytes   `json:"logsBloom"     gencodec:"required"`
-		Transactions []hexutil.Bytes `json:"transactions"  gencodec:"required"`
+		BlockHash     common.Hash     `json:"blockHash"     gencodec:"required"`
+		SigningRoot   common.Hash     `json:"signingRoot"`
+		ParentHash    common.Hash     `json:"parentHash"    gencodec:"required"`
+		Miner         common.Address  `json:"miner"         gencodec:"required"`
+		StateRoot     common.Hash     `json:"stateRoot"     gencodec:"required"`
+		Number        hexutil.Uint64  `json:"number"        gencodec:"required"`
+		GasLimit      hexutil.Uint64  `json:"gasLimit"      gencodec:"required"`
+		GasUsed       hexutil.Uint64  `json:"gasUsed"       gencodec:"required"`
+		Timestamp     hexutil.Uint64  `json:"timestamp"     gencodec:"required"`
+		ReceiptRoot   common.Hash     `json:"receiptsRoot"  gencodec:"required"`
+		LogsBloom     hexutil.Bytes   `json:"logsBloom"     gencodec:"required"`
+		Transactions  []hexutil.Bytes `json:"transactions"  gencodec:"required"`
+		InclusionList []common.Hash   `json:"inclusionList,omitempty"`
 	}
 	var enc executableData
 	enc.BlockHash = e.BlockHash
+	enc.SigningRoot = e.SigningRoot
 	enc.ParentHash = e.ParentHash
 	enc.Miner = e.Miner
 	enc.StateRoot = e.StateRoot
@@ -44,23 +47,26 @@ func (e executableData) MarshalJSON() ([]byte, error) {
 			enc.Transactions[k] = v
 		}
 	}
+	enc.InclusionList = e.InclusionList
 	return json.Marshal(&enc)
 }
 
 // UnmarshalJSON unmarshals from JSON.
 func (e *executableData) UnmarshalJSON(input []byte) error {
 	type executableData struct {
-		BlockHash    *common.Hash    `json:"blockHash"     gencodec:"required"`
-		ParentHash   *common.Hash    `json:"parentHash"    gencodec:"required"`
-		Miner        *common.Address `json:"miner"         gencodec:"required"`
-		StateRoot    *common.Hash    `json:"stateRoot"     gencodec:"required"`
-		Number       *hexutil.Uint64 `json:"number"        gencodec:"required"`
-		GasLimit     *hexutil.Uint64 `json:"gasLimit"      gencodec:"required"`
-		GasUsed      *hexutil.Uint64 `json:"gasUsed"       gencodec:"required"`
-		Timestamp    *hexutil.Uint64 `json:"timestamp"     gencodec:"required"`
-		ReceiptRoot  *common.Hash    `json:"receiptsRoot"  gencodec:"required"`
-		LogsBloom    *hexutil.Bytes  `json:"logsBloom"     gencodec:"required"`
-		Transactions []hexutil.Bytes `json:"transactions"  gencodec:"required"`
+		BlockHash     *common.Hash    `json:"blockHash"     gencodec:"required"`
+		SigningRoot   *common.Hash    `json:"signingRoot"`
+		ParentHash    *common.Hash    `json:"parentHash"    gencodec:"required"`
+		Miner         *common.Address `json:"miner"         gencodec:"required"`
+		StateRoot     *common.Hash    `json:"stateRoot"     gencodec:"required"`
+		Number        *hexutil.Uint64 `json:"number"        gencodec:"required"`
+		GasLimit      *hexutil.Uint64 `json:"gasLimit"      gencodec:"required"`
+		GasUsed       *hexutil.Uint64 `json:"gasUsed"       gencodec:"required"`
+		Timestamp     *hexutil.Uint64 `json:"timestamp"     gencodec:"required"`
+		ReceiptRoot   *common.Hash    `json:"receiptsRoot"  gencodec:"required"`
+		LogsBloom     *hexutil.Bytes  `json:"logsBloom"     gencodec:"required"`
+		Transactions  []hexutil.Bytes `json:"transactions"  gencodec:"required"`
+		InclusionList []common.Hash   `json:"inclusionList,omitempty"`
 	}
 	var dec executableData
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -70,6 +76,9 @@ func (e *executableData) UnmarshalJSON(input []byte) error {
 		return errors.New("missing required field 'blockHash' for executableData")
 	}
 	e.BlockHash = *dec.BlockHash
+	if dec.SigningRoot != nil {
+		e.SigningRoot = *dec.SigningRoot
+	}
 	if dec.ParentHash == nil {
 		return errors.New("missing required field 'parentHash' for executableData")
 	}
@@ -113,5 +122,8 @@ func (e *executableData) UnmarshalJSON(input []byte) error {
 	for k, v := range dec.Transactions {
 		e.Transactions[k] = v
 	}
+	if dec.InclusionList != nil {
+		e.InclusionList = dec.InclusionList
+	}
 	return nil
 }
@@ -0,0 +1,199 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package catalyst
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/eth"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// payloadVersions are the versions of the execution payloads produced and
+// accepted through the consensus API, oldest first.
+var payloadVersions = []hexutil.Uint64{1}
+
+// handshakeMismatchCode is the JSON-RPC error code returned when the engine
+// handshake finds the two ends of the connection incompatible.
+const handshakeMismatchCode = -38200
+
+// errIncompatiblePeer is returned by block calls after a handshake with an
+// incompatible consensus client, until a compatible one completes.
+var errIncompatiblePeer = errors.New("incompatible consensus client, see engine_l2Status")
+
+// engineVersion identifies the software at one end of the engine connection.
+type engineVersion struct {
+	Client          string           `json:"client"`          // Client name and version
+	Commit          string           `json:"commit"`          // Source commit the client was built from
+	ChainSpecHash   common.Hash      `json:"chainSpecHash"`   // Hash of the chain spec the client runs (see scroll_getChainSpec)
+	PayloadVersions []hexutil.Uint64 `json:"payloadVersions"` // Execution payload versions supported
+}
+
+// handshakeResult is the outcome of an engine handshake.
+type handshakeResult struct {
+	Version        engineVersion  `json:"version"`        // Version of this node
+	Compatible     bool           `json:"compatible"`     // Whether the consensus client can drive this node
+	PayloadVersion hexutil.Uint64 `json:"payloadVersion"` // Newest payload version both ends support, 0 if none
+	Mismatches     []string       `json:"mismatches,omitempty"`
+}
+
+// handshakeMismatchError is returned by the handshake if the consensus client
+// is incompatible, carrying the version of this node.
+type handshakeMismatchError struct {
+	result *handshakeResult
+}
+
+func (e *handshakeMismatchError) Error() string {
+	return fmt.Sprintf("engine handshake failed: %v", e.result.Mismatches)
+}
+
+// ErrorCode returns the JSON error code for a failed handshake.
+func (e *handshakeMismatchError) ErrorCode() int {
+	return handshakeMismatchCode
+}
+
+// ErrorData returns the version of this node.
+func (e *handshakeMismatchError) ErrorData() interface{} {
+	return e.result.Version
+}
+
+// peerHandshake is the last handshake completed with a consensus client.
+type peerHandshake struct {
+	Version        engineVersion  `json:"version"`
+	Time           hexutil.Uint64 `json:"time"` // Unix time of the handshake
+	Compatible     bool           `json:"compatible"`
+	PayloadVersion hexutil.Uint64 `json:"payloadVersion"`
+	Mismatches     []string       `json:"mismatches,omitempty"`
+}
+
+// l2Status is the status of the node as seen over the engine connection.
+type l2Status struct {
+	Version   engineVersion  `json:"version"`
+	Peer      *peerHandshake `json:"peer"` // Last handshake with a consensus client, nil if none
+	Head      common.Hash    `json:"head"`
+	Number    hexutil.Uint64 `json:"number"`
+	Finalized hexutil.Uint64 `json:"finalized"`
+}
+
+// handshakes tracks the last handshake of the consensus API, consulted by the
+// block calls to refuse an incompatible consensus client.
+type handshakes struct {
+	lock sync.RWMutex
+	last *peerHandshake
+}
+
+// compatible returns whether the last handshake, if any, succeeded. Consensus
+// clients not performing the handshake are let through.
+func (h *handshakes) compatible() bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return h.last == nil || h.last.Compatible
+}
+
+// engineAPI is the handshake between the node and the consensus client driving
+// it, served in the engine namespace. Mismatched deployments are reported at
+// connection time rather than through subtle payload errors.
+type engineAPI struct {
+	api     *consensusAPI
+	version engineVersion
+}
+
+// newEngineAPI creates the engine handshake API of a consensus API, with the
+// given client name identifying this node.
+func newEngineAPI(api *consensusAPI, client string) (*engineAPI, error) {
+	spec, err := eth.NewPublicScrollAPI(api.eth).GetChainSpec()
+	if err != nil {
+		return nil, err
+	}
+	return &engineAPI{
+		api: api,
+		version: engineVersion{
+			Client:          client,
+			Commit:          params.CommitHash,
+			ChainSpecHash:   spec.Hash,
+			PayloadVersions: payloadVersions,
+		},
+	}, nil
+}
+
+// Hello performs the handshake with a consensus client, checking it runs the
+// same chain spec and shares a payload version. The outcome is logged and kept
+// for engine_l2Status. If incompatible, the block calls are refused until a
+// compatible client completes the handshake.
+func (e *engineAPI) Hello(peer engineVersion) (*handshakeResult, error) {
+	result := &handshakeResult{Version: e.version}
+	if peer.ChainSpecHash != e.version.ChainSpecHash {
+		result.Mismatches = append(result.Mismatches, fmt.Sprintf("chain spec %x, want %x", peer.ChainSpecHash, e.version.ChainSpecHash))
+	}
+	for _, local := range e.version.PayloadVersions {
+		for _, remote := range peer.PayloadVersions {
+			if local == remote && local > result.PayloadVersion {
+				result.PayloadVersion = local
+			}
+		}
+	}
+	if result.PayloadVersion == 0 {
+		result.Mismatches = append(result.Mismatches, fmt.Sprintf("payload versions %v, want one of %v", peer.PayloadVersions, e.version.PayloadVersions))
+	}
+	result.Compatible = len(result.Mismatches) == 0
+
+	h := &e.api.handshakes
+	h.lock.Lock()
+	h.last = &peerHandshake{
+		Version:        peer,
+		Time:           hexutil.Uint64(time.Now().Unix()),
+		Compatible:     result.Compatible,
+		PayloadVersion: result.PayloadVersion,
+		Mismatches:     result.Mismatches,
+	}
+	h.lock.Unlock()
+
+	if !result.Compatible {
+		log.Error("Incompatible consensus client", "client", peer.Client, "commit", peer.Commit, "mismatches", result.Mismatches)
+		return nil, &handshakeMismatchError{result}
+	}
+	log.Info("Consensus client connected", "client", peer.Client, "commit", peer.Commit, "payload", uint64(result.PayloadVersion))
+	return result, nil
+}
+
+// L2Status returns the version of the node, the last handshake with a consensus
+// client and the head of the chain.
+func (e *engineAPI) L2Status() *l2Status {
+	h := &e.api.handshakes
+	h.lock.RLock()
+	peer := h.last
+	h.lock.RUnlock()
+
+	chain := e.api.eth.BlockChain()
+	status := &l2Status{
+		Version: e.version,
+		Peer:    peer,
+		Head:    chain.CurrentBlock().Hash(),
+		Number:  hexutil.Uint64(chain.CurrentBlock().NumberU64()),
+	}
+	if finalized := chain.CurrentFinalizedBlock(); finalized != nil {
+		status.Finalized = hexutil.Uint64(finalized.NumberU64())
+	}
+	return status
+}
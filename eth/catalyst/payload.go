@@ -0,0 +1,266 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package catalyst
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// maxTrackedPayloads is the maximum number of payloads built through BuildBlock
+// kept around to be fetched.
+const maxTrackedPayloads = 10
+
+// errUnknownPayload is returned by GetL2Payload if no payload with the given
+// id is being built.
+var errUnknownPayload = errors.New("unknown payload")
+
+// payloadID identifies a payload being built, derived from its parameters.
+type payloadID [8]byte
+
+func computePayloadID(params assembleBlockParams) payloadID {
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], params.Timestamp)
+
+	data := [][]byte{params.ParentHash[:], timestamp[:]}
+	for _, hash := range params.InclusionList {
+		data = append(data, hash[:])
+	}
+	var id payloadID
+	copy(id[:], crypto.Keccak256(data...))
+	return id
+}
+
+func (id payloadID) String() string {
+	return hexutil.Encode(id[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (id payloadID) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(id[:]).MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (id *payloadID) UnmarshalText(input []byte) error {
+	return hexutil.UnmarshalFixedText("payloadID", input, id[:])
+}
+
+// payload is a block being built, improved with the transactions arriving in
+// the pool until it's fetched or its slot starts.
+type payload struct {
+	id   payloadID
+	best *executableData // Version collecting the most priority fees so far
+	fees *big.Int        // Priority fees collected by the best version
+
+	stop chan struct{} // Closed to stop improving the payload
+	once sync.Once
+	lock sync.Mutex
+}
+
+func newPayload(id payloadID, data *executableData, fees *big.Int) *payload {
+	return &payload{
+		id:   id,
+		best: data,
+		fees: fees,
+		stop: make(chan struct{}),
+	}
+}
+
+// update replaces the best version of the payload with the given one if it
+// collects more priority fees.
+func (p *payload) update(data *executableData, fees *big.Int) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if fees.Cmp(p.fees) <= 0 {
+		return false
+	}
+	p.best, p.fees = data, fees
+	return true
+}
+
+// resolve stops improving the payload and returns its best version.
+func (p *payload) resolve() *executableData {
+	p.interrupt()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.best
+}
+
+// interrupt stops improving the payload.
+func (p *payload) interrupt() {
+	p.once.Do(func() { close(p.stop) })
+}
+
+// payloadQueue keeps the most recently built payloads.
+type payloadQueue struct {
+	payloads []*payload
+	lock     sync.Mutex
+}
+
+// put tracks a new payload unless one with the same id already is, interrupting
+// and dropping the oldest one if the queue is full. It returns whether the
+// payload was tracked.
+func (q *payloadQueue) put(p *payload) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for _, tracked := range q.payloads {
+		if tracked.id == p.id {
+			return false
+		}
+	}
+	if len(q.payloads) == maxTrackedPayloads {
+		q.payloads[0].interrupt()
+		q.payloads = q.payloads[1:]
+	}
+	q.payloads = append(q.payloads, p)
+	return true
+}
+
+// get returns the payload with the given id, nil if it's not tracked.
+func (q *payloadQueue) get(id payloadID) *payload {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for _, p := range q.payloads {
+		if p.id == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// interrupt stops improving all the payloads.
+func (q *payloadQueue) interrupt() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for _, p := range q.payloads {
+		p.interrupt()
+	}
+}
+
+// BuildBlock starts building a new block with the given parameters, returning
+// the id to fetch it with through GetL2Payload. Unlike AssembleBlock, the block
+// keeps being improved with the transactions arriving in the pool every payload
+// recommit interval, if enabled, until it's fetched or its slot starts. Building
+// a block with the parameters of a known payload returns that payload, leaving
+// it as it is.
+func (api *consensusAPI) BuildBlock(params assembleBlockParams) (payloadID, error) {
+	id := computePayloadID(params)
+	if api.payloads.get(id) != nil {
+		return id, nil
+	}
+	done, err := api.enter()
+	if err != nil {
+		return payloadID{}, err
+	}
+	defer done()
+
+	if err := api.eth.MemoryBudget().Admit(); err != nil {
+		return payloadID{}, err
+	}
+	log.Info("Building block", "parentHash", params.ParentHash, "id", id)
+
+	parent, err := api.assembleParent(params)
+	if err != nil {
+		return payloadID{}, err
+	}
+	data, fees, err := api.assemble(parent, params)
+	if err != nil {
+		return payloadID{}, err
+	}
+	// Concurrent calls with the same parameters race to build the payload, only
+	// the first one to finish tracks and improves it
+	p := newPayload(id, data, fees)
+	if !api.payloads.put(p) {
+		return id, nil
+	}
+	if api.recommit > 0 {
+		go api.improve(p, parent, params)
+	}
+	return id, nil
+}
+
+// GetL2Payload stops improving the block built with the given id and returns its
+// best version.
+func (api *consensusAPI) GetL2Payload(id payloadID) (*executableData, error) {
+	p := api.payloads.get(id)
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", errUnknownPayload, id)
+	}
+	return p.resolve(), nil
+}
+
+// improve rebuilds the payload every recommit interval until it's fetched or its
+// slot starts, keeping the version collecting the most priority fees.
+func (api *consensusAPI) improve(p *payload, parent *types.Block, params assembleBlockParams) {
+	var (
+		slot  = time.Unix(int64(params.Timestamp), 0)
+		timer = time.NewTimer(api.recommit)
+	)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+		case <-p.stop:
+			return
+		}
+		if !time.Now().Before(slot) {
+			return
+		}
+		data, fees, err := api.reassemble(parent, params)
+		if err != nil {
+			if err != errShuttingDown {
+				log.Warn("Failed to improve payload", "id", p.id, "err", err)
+			}
+			return
+		}
+		if p.update(data, fees) {
+			log.Debug("Improved payload", "id", p.id, "txs", len(data.Transactions), "fees", fees)
+		}
+		timer.Reset(api.recommit)
+	}
+}
+
+// reassemble assembles a new version of a payload, unless the node is shutting
+// down.
+func (api *consensusAPI) reassemble(parent *types.Block, params assembleBlockParams) (*executableData, *big.Int, error) {
+	done, err := api.enter()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer done()
+
+	if err := api.eth.MemoryBudget().Admit(); err != nil {
+		return nil, nil, err
+	}
+	return api.assemble(parent, params)
+}
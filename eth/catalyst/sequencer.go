@@ -0,0 +1,87 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package catalyst
+
+import (
+	"github.com/scroll-tech/go-ethereum/consensus"
+	"github.com/scroll-tech/go-ethereum/consensus/misc"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// SequencerConsensus is the consensus scheme of the blocks produced and accepted
+// through the engine API. It allows alternative schemes (e.g. multiple
+// sequencers or a proof-of-authority fallback) to be plugged in without touching
+// the API itself. Execution payloads carry no extra data, NewBlock rebuilds it
+// from the chain configuration, so a scheme must not seal blocks through the
+// extra data of the header.
+type SequencerConsensus interface {
+	// Prepare initializes the consensus fields of a header to be assembled on top
+	// of parent. Fees are credited to the coinbase while executing transactions,
+	// so this is where they are routed to their recipient.
+	Prepare(chain consensus.ChainHeaderReader, parent *types.Header, header *types.Header) error
+
+	// VerifyHeader checks whether a header submitted for insertion conforms to
+	// the consensus rules.
+	VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header) error
+
+	// Finalize applies any post-transaction state modifications and assembles the
+	// final block.
+	Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, receipts []*types.Receipt) (*types.Block, error)
+}
+
+// engineConsensus is the default sequencer consensus, deferring to the consensus
+// engine of the node and routing fees to the fee vault if the chain has one.
+type engineConsensus struct {
+	engine consensus.Engine
+	config *params.ChainConfig
+}
+
+// NewEngineConsensus creates the default sequencer consensus on top of the given
+// consensus engine.
+func NewEngineConsensus(engine consensus.Engine, config *params.ChainConfig) SequencerConsensus {
+	return &engineConsensus{engine: engine, config: config}
+}
+
+// Prepare implements SequencerConsensus, setting the base fee, the structured
+// extra-data, the proposer in turn and the fee recipient before deferring to
+// the consensus engine. The coinbase of the given header identifies the
+// proposer.
+func (c *engineConsensus) Prepare(chain consensus.ChainHeaderReader, parent *types.Header, header *types.Header) error {
+	if c.config.IsLondon(header.Number) {
+		header.BaseFee = misc.CalcBaseFee(c.config, parent)
+	}
+	misc.PrepareExtra(c.config, header)
+	if err := misc.SetProposer(c.config, header, header.Coinbase); err != nil {
+		return err
+	}
+	if c.config.Scroll.FeeVaultEnabled() {
+		header.Coinbase = *c.config.Scroll.FeeVaultAddress
+	}
+	return c.engine.Prepare(chain, header)
+}
+
+// VerifyHeader implements SequencerConsensus, deferring to the consensus engine.
+func (c *engineConsensus) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header) error {
+	return c.engine.VerifyHeader(chain, header, false)
+}
+
+// Finalize implements SequencerConsensus, deferring to the consensus engine.
+func (c *engineConsensus) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, receipts []*types.Receipt) (*types.Block, error) {
+	return c.engine.FinalizeAndAssemble(chain, header, state, txs, nil /* uncles */, receipts)
+}
@@ -92,6 +92,8 @@ var Defaults = Config{
 	RPCEVMTimeout: 5 * time.Second,
 	GPO:           FullNodeGPO,
 	RPCTxFeeCap:   1, // 1 ether
+
+	RPCMulticallBudget: 250000000,
 }
 
 func init() {
@@ -192,6 +194,24 @@ type Config struct {
 	// RPCEVMTimeout is the global timeout for eth-call.
 	RPCEVMTimeout time.Duration
 
+	// RPCLogQueryRange is the maximum number of blocks a log query may span.
+	RPCLogQueryRange uint64
+
+	// RPCLogQueryLimit is the maximum number of logs a log query may return.
+	RPCLogQueryLimit int
+
+	// RPCResponseCache is the memory allowance (MB) for caching the responses
+	// of queries about finalized blocks, receipts and traces.
+	RPCResponseCache int
+
+	// HistoricalStateURL is the JSON-RPC endpoint of an archive node serving
+	// the calls, proofs and traces of blocks whose state was pruned locally.
+	HistoricalStateURL string `toml:",omitempty"`
+
+	// RPCMulticallBudget is the total gas a single multicall may use across its
+	// calls (0 = no budget beyond the per-call gas cap).
+	RPCMulticallBudget uint64
+
 	// RPCTxFeeCap is the global transaction fee(price * gaslimit) cap for
 	// send-transction variants. The unit is ether.
 	RPCTxFeeCap float64
@@ -207,6 +227,61 @@ type Config struct {
 
 	// Trace option
 	MPTWitness int
+
+	// Whether to store the block-level access list of imported blocks
+	RecordAccessLists bool
+
+	// Whether to maintain the per-address/topic log index
+	LogIndex bool
+
+	// Whether to store the internal transactions of imported blocks
+	RecordCallTraces bool
+
+	// Whether to maintain the withdraw trie of L2 to L1 messages
+	WithdrawTrie bool
+
+	// Websocket endpoint of the sequencer to import blocks from in read replica
+	// mode, disabling block production
+	Replica string `toml:",omitempty"`
+
+	// Memory budget (MB) of the node, sizing its caches and transaction pool and
+	// applying backpressure as it nears. Zero disables budgeting.
+	MemoryBudget int `toml:",omitempty"`
+
+	// Whether to store the resource usage of importing blocks
+	RecordBlockStats bool
+
+	// Whether to store the gas used and the state growth caused by each contract
+	// in imported blocks
+	RecordContractUsage bool `toml:",omitempty"`
+
+	// Whether to store the number of accounts, storage slots and code bytes in
+	// the state after imported blocks
+	RecordStateSize bool `toml:",omitempty"`
+
+	// RPC endpoint of the L1 node to follow batch finalization on, advancing the
+	// finalized block. Requires the L1 config in the chain config.
+	L1Endpoint string `toml:",omitempty"`
+
+	// Whether to allow rewinding and reorging the chain below the finalized
+	// block, for disaster recovery only
+	AllowFinalizedRewind bool `toml:",omitempty"`
+
+	// Whether to maintain the index of contract creations by address
+	CreationIndex bool `toml:",omitempty"`
+
+	// Number of blocks per epoch to tag accessed accounts and storage slots
+	// with their last access epoch, for state expiry research (0 = disabled)
+	AccessEpochLength uint64 `toml:",omitempty"`
+
+	// Kind of trie keys to record the preimages of if Preimages is set (all,
+	// accounts or storage), and the maximum number of preimages to store
+	PreimageKind  string `toml:",omitempty"`
+	PreimageLimit uint64 `toml:",omitempty"`
+
+	// Number of blocks a transaction required by an inclusion list must be
+	// included within (0 = default)
+	InclusionListWindow uint64 `toml:",omitempty"`
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain configuration.
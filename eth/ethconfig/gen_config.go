@@ -56,10 +56,31 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		DocRoot                 string `toml:"-"`
 		RPCGasCap               uint64
 		RPCEVMTimeout           time.Duration
+		RPCLogQueryRange        uint64
+		RPCLogQueryLimit        int
+		RPCResponseCache        int
+		HistoricalStateURL      string `toml:",omitempty"`
+		RPCMulticallBudget      uint64
 		RPCTxFeeCap             float64
 		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
 		OverrideArrowGlacier    *big.Int                       `toml:",omitempty"`
+		RecordAccessLists       bool
+		LogIndex                bool
+		RecordCallTraces        bool
+		WithdrawTrie            bool
+		Replica                 string `toml:",omitempty"`
+		MemoryBudget            int    `toml:",omitempty"`
+		RecordBlockStats        bool
+		RecordContractUsage     bool   `toml:",omitempty"`
+		RecordStateSize         bool   `toml:",omitempty"`
+		L1Endpoint              string `toml:",omitempty"`
+		AllowFinalizedRewind    bool   `toml:",omitempty"`
+		CreationIndex           bool   `toml:",omitempty"`
+		AccessEpochLength       uint64 `toml:",omitempty"`
+		PreimageKind            string `toml:",omitempty"`
+		PreimageLimit           uint64 `toml:",omitempty"`
+		InclusionListWindow     uint64 `toml:",omitempty"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -100,10 +121,31 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.DocRoot = c.DocRoot
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCEVMTimeout = c.RPCEVMTimeout
+	enc.RPCLogQueryRange = c.RPCLogQueryRange
+	enc.RPCLogQueryLimit = c.RPCLogQueryLimit
+	enc.RPCResponseCache = c.RPCResponseCache
+	enc.HistoricalStateURL = c.HistoricalStateURL
+	enc.RPCMulticallBudget = c.RPCMulticallBudget
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
 	enc.Checkpoint = c.Checkpoint
 	enc.CheckpointOracle = c.CheckpointOracle
 	enc.OverrideArrowGlacier = c.OverrideArrowGlacier
+	enc.RecordAccessLists = c.RecordAccessLists
+	enc.LogIndex = c.LogIndex
+	enc.RecordCallTraces = c.RecordCallTraces
+	enc.WithdrawTrie = c.WithdrawTrie
+	enc.Replica = c.Replica
+	enc.MemoryBudget = c.MemoryBudget
+	enc.RecordBlockStats = c.RecordBlockStats
+	enc.RecordContractUsage = c.RecordContractUsage
+	enc.RecordStateSize = c.RecordStateSize
+	enc.L1Endpoint = c.L1Endpoint
+	enc.AllowFinalizedRewind = c.AllowFinalizedRewind
+	enc.CreationIndex = c.CreationIndex
+	enc.AccessEpochLength = c.AccessEpochLength
+	enc.PreimageKind = c.PreimageKind
+	enc.PreimageLimit = c.PreimageLimit
+	enc.InclusionListWindow = c.InclusionListWindow
 	return &enc, nil
 }
 
@@ -148,10 +190,31 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		DocRoot                 *string `toml:"-"`
 		RPCGasCap               *uint64
 		RPCEVMTimeout           *time.Duration
+		RPCLogQueryRange        *uint64
+		RPCLogQueryLimit        *int
+		RPCResponseCache        *int
+		HistoricalStateURL      *string `toml:",omitempty"`
+		RPCMulticallBudget      *uint64
 		RPCTxFeeCap             *float64
 		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
 		OverrideArrowGlacier    *big.Int                       `toml:",omitempty"`
+		RecordAccessLists       *bool
+		LogIndex                *bool
+		RecordCallTraces        *bool
+		WithdrawTrie            *bool
+		Replica                 *string `toml:",omitempty"`
+		MemoryBudget            *int    `toml:",omitempty"`
+		RecordBlockStats        *bool
+		RecordContractUsage     *bool   `toml:",omitempty"`
+		RecordStateSize         *bool   `toml:",omitempty"`
+		L1Endpoint              *string `toml:",omitempty"`
+		AllowFinalizedRewind    *bool   `toml:",omitempty"`
+		CreationIndex           *bool   `toml:",omitempty"`
+		AccessEpochLength       *uint64 `toml:",omitempty"`
+		PreimageKind            *string `toml:",omitempty"`
+		PreimageLimit           *uint64 `toml:",omitempty"`
+		InclusionListWindow     *uint64 `toml:",omitempty"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -271,6 +334,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.RPCEVMTimeout != nil {
 		c.RPCEVMTimeout = *dec.RPCEVMTimeout
 	}
+	if dec.RPCLogQueryRange != nil {
+		c.RPCLogQueryRange = *dec.RPCLogQueryRange
+	}
+	if dec.RPCLogQueryLimit != nil {
+		c.RPCLogQueryLimit = *dec.RPCLogQueryLimit
+	}
+	if dec.RPCResponseCache != nil {
+		c.RPCResponseCache = *dec.RPCResponseCache
+	}
+	if dec.HistoricalStateURL != nil {
+		c.HistoricalStateURL = *dec.HistoricalStateURL
+	}
+	if dec.RPCMulticallBudget != nil {
+		c.RPCMulticallBudget = *dec.RPCMulticallBudget
+	}
 	if dec.RPCTxFeeCap != nil {
 		c.RPCTxFeeCap = *dec.RPCTxFeeCap
 	}
@@ -283,5 +361,53 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.OverrideArrowGlacier != nil {
 		c.OverrideArrowGlacier = dec.OverrideArrowGlacier
 	}
+	if dec.RecordAccessLists != nil {
+		c.RecordAccessLists = *dec.RecordAccessLists
+	}
+	if dec.LogIndex != nil {
+		c.LogIndex = *dec.LogIndex
+	}
+	if dec.RecordCallTraces != nil {
+		c.RecordCallTraces = *dec.RecordCallTraces
+	}
+	if dec.WithdrawTrie != nil {
+		c.WithdrawTrie = *dec.WithdrawTrie
+	}
+	if dec.Replica != nil {
+		c.Replica = *dec.Replica
+	}
+	if dec.MemoryBudget != nil {
+		c.MemoryBudget = *dec.MemoryBudget
+	}
+	if dec.RecordBlockStats != nil {
+		c.RecordBlockStats = *dec.RecordBlockStats
+	}
+	if dec.RecordContractUsage != nil {
+		c.RecordContractUsage = *dec.RecordContractUsage
+	}
+	if dec.RecordStateSize != nil {
+		c.RecordStateSize = *dec.RecordStateSize
+	}
+	if dec.L1Endpoint != nil {
+		c.L1Endpoint = *dec.L1Endpoint
+	}
+	if dec.AllowFinalizedRewind != nil {
+		c.AllowFinalizedRewind = *dec.AllowFinalizedRewind
+	}
+	if dec.CreationIndex != nil {
+		c.CreationIndex = *dec.CreationIndex
+	}
+	if dec.AccessEpochLength != nil {
+		c.AccessEpochLength = *dec.AccessEpochLength
+	}
+	if dec.PreimageKind != nil {
+		c.PreimageKind = *dec.PreimageKind
+	}
+	if dec.PreimageLimit != nil {
+		c.PreimageLimit = *dec.PreimageLimit
+	}
+	if dec.InclusionListWindow != nil {
+		c.InclusionListWindow = *dec.InclusionListWindow
+	}
 	return nil
 }
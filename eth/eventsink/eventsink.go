@@ -0,0 +1,353 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package eventsink publishes chain lifecycle events as JSON to an external
+// sink, so that downstream systems can follow the chain without maintaining
+// websocket subscriptions.
+//
+// Events are published in order from a bounded queue. A sink failing to accept
+// an event is retried a few times before the event is dropped, and events are
+// dropped too while the queue is full, so the sink must not be relied upon for
+// completeness: consumers can reconcile through the JSON-RPC API using the block
+// numbers of the events.
+package eventsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/node"
+)
+
+const (
+	// queueSize is the maximum number of events waiting to be published.
+	queueSize = 1024
+
+	// maxAttempts is the number of times publishing an event is attempted
+	// before dropping it.
+	maxAttempts = 3
+
+	// publishTimeout is the timeout of a single publication.
+	publishTimeout = 10 * time.Second
+
+	// chainEventChanSize is the size of the channels listening to chain events.
+	chainEventChanSize = 16
+
+	// maxReorgSearch is the maximum number of blocks to walk back looking for
+	// the common ancestor of a reorg.
+	maxReorgSearch = 1024
+)
+
+// retryDelay is the time to wait before retrying to publish an event.
+var retryDelay = time.Second
+
+var (
+	publishedMeter = metrics.NewRegisteredMeter("eventsink/published", nil)
+	failedMeter    = metrics.NewRegisteredMeter("eventsink/failed", nil)
+	droppedMeter   = metrics.NewRegisteredMeter("eventsink/dropped", nil)
+)
+
+// Event kinds.
+const (
+	KindBlock     = "block"     // Block committed as the new head
+	KindReorg     = "reorg"     // Head replaced by a block not extending it
+	KindFinalized = "finalized" // Finalized block advanced by batch finalization
+)
+
+// Config contains the settings of the event sink.
+type Config struct {
+	URL    string   `toml:",omitempty"` // HTTP endpoint to post the events to
+	Events []string `toml:",omitempty"` // Kinds of events to publish (empty = all)
+}
+
+// Event is a chain lifecycle event, as published to the sink.
+type Event struct {
+	Kind  string     `json:"kind"`
+	Time  time.Time  `json:"time"`
+	Block *Block     `json:"block"`           // Committed, new head or finalized block
+	Reorg *ReorgInfo `json:"reorg,omitempty"` // Replaced chain segment of a reorg
+}
+
+// Block is the summary of a block in events.
+type Block struct {
+	Number       hexutil.Uint64 `json:"number"`
+	Hash         common.Hash    `json:"hash"`
+	ParentHash   common.Hash    `json:"parentHash"`
+	Timestamp    hexutil.Uint64 `json:"timestamp"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Transactions []common.Hash  `json:"transactions"`
+}
+
+// ReorgInfo is the chain segment replaced by a reorg.
+type ReorgInfo struct {
+	OldHead        common.Hash    `json:"oldHead"`
+	OldNumber      hexutil.Uint64 `json:"oldNumber"`
+	AncestorHash   common.Hash    `json:"ancestorHash"`
+	AncestorNumber hexutil.Uint64 `json:"ancestorNumber"`
+}
+
+// Sink is a destination of events.
+type Sink interface {
+	Publish(ctx context.Context, ev *Event) error
+}
+
+// httpSink posts events to an HTTP endpoint.
+type httpSink struct {
+	url string
+}
+
+// Publish implements Sink.
+func (s *httpSink) Publish(ctx context.Context, ev *Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("sink responded with status %s", res.Status)
+	}
+	return nil
+}
+
+// backend encompasses the functionality needed to follow the chain.
+type backend interface {
+	CurrentBlock() *types.Block
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
+	SubscribeFinalizedEvent(ch chan<- core.FinalizedEvent) event.Subscription
+}
+
+// Service publishes the chain lifecycle events to a sink in the background.
+type Service struct {
+	backend backend
+	sink    Sink
+	kinds   map[string]bool // Kinds of events to publish, nil for all
+
+	head  *types.Header // Last head seen, to detect reorgs
+	queue chan *Event
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates an event sink service posting to the configured URL and
+// registers it with the node.
+func New(stack *node.Node, backend backend, config Config) error {
+	if config.URL == "" {
+		return errors.New("missing event sink URL")
+	}
+	s, err := newService(backend, &httpSink{url: config.URL}, config.Events)
+	if err != nil {
+		return err
+	}
+	stack.RegisterLifecycle(s)
+	return nil
+}
+
+func newService(backend backend, sink Sink, kinds []string) (*Service, error) {
+	s := &Service{
+		backend: backend,
+		sink:    sink,
+		queue:   make(chan *Event, queueSize),
+		quit:    make(chan struct{}),
+	}
+	if len(kinds) > 0 {
+		s.kinds = make(map[string]bool)
+		for _, kind := range kinds {
+			switch kind {
+			case KindBlock, KindReorg, KindFinalized:
+				s.kinds[kind] = true
+			default:
+				return nil, fmt.Errorf("unknown event kind %q", kind)
+			}
+		}
+	}
+	return s, nil
+}
+
+// Start implements node.Lifecycle, starting to publish events.
+func (s *Service) Start() error {
+	if head := s.backend.CurrentBlock(); head != nil {
+		s.head = head.Header()
+	}
+	var (
+		blocks    = make(chan core.ChainEvent, chainEventChanSize)
+		finalized = make(chan core.FinalizedEvent, chainEventChanSize)
+	)
+	blockSub := s.backend.SubscribeChainEvent(blocks)
+	finalSub := s.backend.SubscribeFinalizedEvent(finalized)
+
+	s.wg.Add(2)
+	go s.loop(blocks, finalized, blockSub, finalSub)
+	go s.publishLoop()
+
+	log.Info("Started chain event sink")
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the publication. Queued events
+// are dropped.
+func (s *Service) Stop() error {
+	close(s.quit)
+	s.wg.Wait()
+	return nil
+}
+
+// loop turns chain events into sink events until termination.
+func (s *Service) loop(blocks chan core.ChainEvent, finalized chan core.FinalizedEvent, blockSub, finalSub event.Subscription) {
+	defer s.wg.Done()
+	defer blockSub.Unsubscribe()
+	defer finalSub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-blocks:
+			s.committed(ev.Block, time.Now())
+		case ev := <-finalized:
+			s.enqueue(&Event{Kind: KindFinalized, Time: time.Now(), Block: newBlock(ev.Block)})
+		case <-blockSub.Err():
+			return
+		case <-finalSub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// committed queues the events of a block committed as the new head, preceded by
+// a reorg event if it doesn't extend the previous head.
+func (s *Service) committed(block *types.Block, now time.Time) {
+	if s.head != nil && block.ParentHash() != s.head.Hash() {
+		s.enqueue(&Event{Kind: KindReorg, Time: now, Block: newBlock(block), Reorg: s.reorgInfo(block.Header())})
+	}
+	s.head = block.Header()
+	s.enqueue(&Event{Kind: KindBlock, Time: now, Block: newBlock(block)})
+}
+
+// reorgInfo finds the common ancestor of the previous head and a new head not
+// extending it. The ancestor is left empty if not found within maxReorgSearch
+// blocks.
+func (s *Service) reorgInfo(head *types.Header) *ReorgInfo {
+	info := &ReorgInfo{OldHead: s.head.Hash(), OldNumber: hexutil.Uint64(s.head.Number.Uint64())}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	old, current := s.head, head
+	for i := 0; i < maxReorgSearch && old != nil && current != nil; i++ {
+		if old.Hash() == current.Hash() {
+			info.AncestorHash, info.AncestorNumber = old.Hash(), hexutil.Uint64(old.Number.Uint64())
+			break
+		}
+		if old.Number.Cmp(current.Number) >= 0 {
+			old, _ = s.backend.HeaderByHash(ctx, old.ParentHash)
+		} else {
+			current, _ = s.backend.HeaderByHash(ctx, current.ParentHash)
+		}
+	}
+	return info
+}
+
+// enqueue queues an event for publication if its kind is published, dropping
+// it if the queue is full.
+func (s *Service) enqueue(ev *Event) {
+	if s.kinds != nil && !s.kinds[ev.Kind] {
+		return
+	}
+	select {
+	case s.queue <- ev:
+	default:
+		droppedMeter.Mark(1)
+		log.Warn("Event sink queue full, dropping event", "kind", ev.Kind, "number", uint64(ev.Block.Number))
+	}
+}
+
+// publishLoop publishes the queued events in order until termination.
+func (s *Service) publishLoop() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case ev := <-s.queue:
+			s.publish(ev)
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// publish publishes an event, retrying a few times before dropping it.
+func (s *Service) publish(ev *Event) {
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		err := s.sink.Publish(ctx, ev)
+		cancel()
+		if err == nil {
+			publishedMeter.Mark(1)
+			return
+		}
+		failedMeter.Mark(1)
+		if attempt == maxAttempts {
+			droppedMeter.Mark(1)
+			log.Warn("Failed to publish event, dropping", "kind", ev.Kind, "number", uint64(ev.Block.Number), "err", err)
+			return
+		}
+		log.Debug("Failed to publish event, retrying", "kind", ev.Kind, "number", uint64(ev.Block.Number), "err", err)
+		select {
+		case <-time.After(retryDelay):
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+func newBlock(block *types.Block) *Block {
+	txs := make([]common.Hash, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		txs[i] = tx.Hash()
+	}
+	return &Block{
+		Number:       hexutil.Uint64(block.NumberU64()),
+		Hash:         block.Hash(),
+		ParentHash:   block.ParentHash(),
+		Timestamp:    hexutil.Uint64(block.Time()),
+		GasUsed:      hexutil.Uint64(block.GasUsed()),
+		Transactions: txs,
+	}
+}
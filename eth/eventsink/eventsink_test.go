@@ -0,0 +1,152 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eventsink
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/event"
+)
+
+// testBackend serves the headers of the given blocks.
+type testBackend struct {
+	headers map[common.Hash]*types.Header
+}
+
+func (b *testBackend) CurrentBlock() *types.Block { return nil }
+
+func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.headers[hash], nil
+}
+
+func (b *testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
+	return nil
+}
+
+func (b *testBackend) SubscribeFinalizedEvent(ch chan<- core.FinalizedEvent) event.Subscription {
+	return nil
+}
+
+// add creates a block on top of the given parent and serves its header.
+func (b *testBackend) add(parent *types.Block, extra byte) *types.Block {
+	header := &types.Header{ParentHash: parent.Hash(), Number: new(big.Int).Add(parent.Number(), common.Big1), Extra: []byte{extra}}
+	b.headers[header.Hash()] = header
+	return types.NewBlockWithHeader(header)
+}
+
+// testSink records the events published, failing the given number of times
+// first.
+type testSink struct {
+	events   []*Event
+	failures int
+}
+
+func (s *testSink) Publish(ctx context.Context, ev *Event) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("unavailable")
+	}
+	s.events = append(s.events, ev)
+	return nil
+}
+
+func TestEventSinkReorg(t *testing.T) {
+	var (
+		backend = &testBackend{headers: make(map[common.Hash]*types.Header)}
+		genesis = types.NewBlockWithHeader(&types.Header{Number: common.Big0})
+		block1  = backend.add(genesis, 0)
+		block2  = backend.add(block1, 0)
+		block3  = backend.add(block2, 0)
+		fork2   = backend.add(block1, 1)
+		fork3   = backend.add(fork2, 1)
+	)
+	backend.headers[genesis.Hash()] = genesis.Header()
+
+	s, err := newService(backend, &testSink{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	s.head = genesis.Header()
+	for _, block := range []*types.Block{block1, block2, block3, fork3} {
+		s.committed(block, time.Now())
+	}
+	want := []struct {
+		kind string
+		hash common.Hash
+	}{
+		{KindBlock, block1.Hash()},
+		{KindBlock, block2.Hash()},
+		{KindBlock, block3.Hash()},
+		{KindReorg, fork3.Hash()},
+		{KindBlock, fork3.Hash()},
+	}
+	if len(s.queue) != len(want) {
+		t.Fatalf("event count mismatch: have %d, want %d", len(s.queue), len(want))
+	}
+	for i, w := range want {
+		ev := <-s.queue
+		if ev.Kind != w.kind || ev.Block.Hash != w.hash {
+			t.Fatalf("event %d mismatch: have %s %x, want %s %x", i, ev.Kind, ev.Block.Hash, w.kind, w.hash)
+		}
+		if ev.Kind == KindReorg {
+			if ev.Reorg.OldHead != block3.Hash() || ev.Reorg.AncestorHash != block1.Hash() || ev.Reorg.AncestorNumber != 1 {
+				t.Fatalf("reorg mismatch: %+v", ev.Reorg)
+			}
+		}
+	}
+}
+
+func TestEventSinkPublish(t *testing.T) {
+	backend := &testBackend{headers: make(map[common.Hash]*types.Header)}
+	if _, err := newService(backend, &testSink{}, []string{"block", "bogus"}); err == nil {
+		t.Fatalf("unknown event kind accepted")
+	}
+	defer func(delay time.Duration) { retryDelay = delay }(retryDelay)
+	retryDelay = time.Millisecond
+
+	sink := &testSink{failures: maxAttempts - 1}
+	s, err := newService(backend, sink, []string{KindFinalized})
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	block := types.NewBlockWithHeader(&types.Header{Number: common.Big1})
+
+	// Events of the kinds not published are skipped
+	s.enqueue(&Event{Kind: KindBlock, Block: newBlock(block)})
+	s.enqueue(&Event{Kind: KindFinalized, Block: newBlock(block)})
+	if len(s.queue) != 1 {
+		t.Fatalf("queued event count mismatch: have %d, want 1", len(s.queue))
+	}
+	// Failed publications are retried
+	s.publish(<-s.queue)
+	if len(sink.events) != 1 || sink.events[0].Kind != KindFinalized {
+		t.Fatalf("event not published after retries: %v", sink.events)
+	}
+	// Events still failing after the last attempt are dropped
+	sink.failures = maxAttempts
+	s.publish(&Event{Kind: KindFinalized, Block: newBlock(block)})
+	if len(sink.events) != 1 {
+		t.Fatalf("failing event published: %v", sink.events)
+	}
+}
@@ -18,6 +18,7 @@ package filters
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -34,6 +35,29 @@ import (
 	"github.com/scroll-tech/go-ethereum/rpc"
 )
 
+var (
+	errInvalidLogCursor = errors.New("invalid log cursor")
+	errPendingLogCursor = errors.New("pending logs cannot be paginated")
+)
+
+// logCursorLength is the length of an encoded log pagination cursor: the number
+// of the block to resume from followed by the index of the first log to return.
+const logCursorLength = 8 + 4
+
+// LogQueryLimits are the server side limits applied to historical log queries.
+type LogQueryLimits struct {
+	BlockRange uint64 // Maximum number of blocks a single query may span (0 = unlimited)
+	Results    int    // Maximum number of logs a single query may return (0 = unlimited)
+}
+
+// LogsPage is a page of logs returned by a paginated log query, along with the
+// cursor to pass in to retrieve the next page. The cursor is omitted once the
+// entire range was returned.
+type LogsPage struct {
+	Logs   []*types.Log   `json:"logs"`
+	Cursor *hexutil.Bytes `json:"cursor,omitempty"`
+}
+
 // filter is a helper struct that holds meta information over the filter type
 // and associated subscription in the event system.
 type filter struct {
@@ -56,16 +80,18 @@ type PublicFilterAPI struct {
 	filtersMu sync.Mutex
 	filters   map[rpc.ID]*filter
 	timeout   time.Duration
+	limits    LogQueryLimits
 }
 
 // NewPublicFilterAPI returns a new PublicFilterAPI instance.
-func NewPublicFilterAPI(backend Backend, lightMode bool, timeout time.Duration) *PublicFilterAPI {
+func NewPublicFilterAPI(backend Backend, lightMode bool, timeout time.Duration, limits LogQueryLimits) *PublicFilterAPI {
 	api := &PublicFilterAPI{
 		backend: backend,
 		chainDb: backend.ChainDb(),
 		events:  NewEventSystem(backend, lightMode),
 		filters: make(map[rpc.ID]*filter),
 		timeout: timeout,
+		limits:  limits,
 	}
 	go api.timeoutLoop(timeout)
 
@@ -345,6 +371,20 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 		if crit.ToBlock != nil {
 			end = crit.ToBlock.Int64()
 		}
+		// Reject queries spanning more blocks than allowed
+		if limit := api.limits.BlockRange; limit > 0 {
+			from, err := api.resolveBlockNumber(ctx, begin)
+			if err != nil {
+				return nil, err
+			}
+			to, err := api.resolveBlockNumber(ctx, end)
+			if err != nil {
+				return nil, err
+			}
+			if to >= from && to-from >= limit {
+				return nil, fmt.Errorf("query exceeds max block range %d, use eth_getLogsPaginated", limit)
+			}
+		}
 		// Construct the range filter
 		filter = NewRangeFilter(api.backend, begin, end, crit.Addresses, crit.Topics)
 	}
@@ -353,9 +393,119 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 	if err != nil {
 		return nil, err
 	}
+	if limit := api.limits.Results; limit > 0 && len(logs) > limit {
+		return nil, fmt.Errorf("query returned more than %d results, use eth_getLogsPaginated", limit)
+	}
 	return returnLogs(logs), err
 }
 
+// GetLogsPaginated returns logs matching the given argument that are stored
+// within the state, split into pages according to the server side block range
+// and result limits. The cursor returned with a page must be passed in along
+// with the same criteria to retrieve the next one.
+func (api *PublicFilterAPI) GetLogsPaginated(ctx context.Context, crit FilterCriteria, cursor *hexutil.Bytes) (*LogsPage, error) {
+	var (
+		resume      bool
+		resumeBlock uint64
+		resumeIndex uint
+	)
+	if cursor != nil {
+		if len(*cursor) != logCursorLength {
+			return nil, errInvalidLogCursor
+		}
+		resume = true
+		resumeBlock = binary.BigEndian.Uint64((*cursor)[:8])
+		resumeIndex = uint(binary.BigEndian.Uint32((*cursor)[8:]))
+	}
+	var (
+		filter   *Filter
+		last     uint64
+		end      uint64
+		hasRange bool
+	)
+	if crit.BlockHash != nil {
+		// Block filter requested, construct a single-shot filter
+		filter = NewBlockFilter(api.backend, *crit.BlockHash, crit.Addresses, crit.Topics)
+	} else {
+		// Resolve the block range, continuing from the cursor if given
+		begin := rpc.LatestBlockNumber.Int64()
+		if crit.FromBlock != nil {
+			begin = crit.FromBlock.Int64()
+		}
+		to := rpc.LatestBlockNumber.Int64()
+		if crit.ToBlock != nil {
+			to = crit.ToBlock.Int64()
+		}
+		if begin == rpc.PendingBlockNumber.Int64() || to == rpc.PendingBlockNumber.Int64() {
+			return nil, errPendingLogCursor
+		}
+		from, err := api.resolveBlockNumber(ctx, begin)
+		if err != nil {
+			return nil, err
+		}
+		if end, err = api.resolveBlockNumber(ctx, to); err != nil {
+			return nil, err
+		}
+		if resume {
+			if resumeBlock < from || resumeBlock > end {
+				return nil, errInvalidLogCursor
+			}
+			from = resumeBlock
+		}
+		if from > end {
+			return &LogsPage{Logs: []*types.Log{}}, nil
+		}
+		// Cap the page to the maximum block range
+		last, hasRange = end, true
+		if limit := api.limits.BlockRange; limit > 0 && end-from >= limit {
+			last = from + limit - 1
+		}
+		filter = NewRangeFilter(api.backend, int64(from), int64(last), crit.Addresses, crit.Topics)
+	}
+	logs, err := filter.Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Skip the logs already returned in previous pages
+	if resume {
+		for len(logs) > 0 && logs[0].BlockNumber == resumeBlock && logs[0].Index < resumeIndex {
+			logs = logs[1:]
+		}
+	}
+	page := &LogsPage{Logs: returnLogs(logs)}
+	if limit := api.limits.Results; limit > 0 && len(logs) > limit {
+		page.Logs = logs[:limit]
+		page.Cursor = encodeLogCursor(logs[limit].BlockNumber, logs[limit].Index)
+	} else if hasRange && last < end {
+		page.Cursor = encodeLogCursor(last+1, 0)
+	}
+	return page, nil
+}
+
+// resolveBlockNumber converts a block number of a log query into an absolute
+// one, resolving the latest and pending tags to the current head.
+func (api *PublicFilterAPI) resolveBlockNumber(ctx context.Context, number int64) (uint64, error) {
+	if number >= 0 {
+		return uint64(number), nil
+	}
+	header, err := api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, errors.New("unknown block")
+	}
+	return header.Number.Uint64(), nil
+}
+
+// encodeLogCursor creates the pagination cursor pointing at the given log.
+func encodeLogCursor(number uint64, index uint) *hexutil.Bytes {
+	cursor := make(hexutil.Bytes, logCursorLength)
+	binary.BigEndian.PutUint64(cursor[:8], number)
+	binary.BigEndian.PutUint32(cursor[8:], uint32(index))
+	return &cursor
+}
+
 // UninstallFilter removes the filter with the given filter id.
 //
 // https://eth.wiki/json-rpc/API#eth_uninstallfilter
@@ -20,10 +20,12 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"sort"
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/bloombits"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/ethdb"
 	"github.com/scroll-tech/go-ethereum/event"
@@ -36,12 +38,14 @@ type Backend interface {
 	HeaderByHash(ctx context.Context, blockHash common.Hash) (*types.Header, error)
 	GetReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error)
 	GetLogs(ctx context.Context, blockHash common.Hash) ([][]*types.Log, error)
+	GetPoolTransaction(txHash common.Hash) *types.Transaction
 
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
 	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
 	SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription
+	SubscribeFinalizedEvent(ch chan<- core.FinalizedEvent) event.Subscription
 
 	BloomStatus() (uint64, uint64)
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
@@ -142,6 +146,10 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if f.end == -1 {
 		end = head
 	}
+	// Serve the query from the log index if it covers the entire range
+	if logs, ok, err := f.logIndexLogs(ctx, end); ok {
+		return logs, err
+	}
 	// Gather all indexed logs, and finish with non indexed ones
 	var (
 		logs []*types.Log
@@ -210,6 +218,88 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 	}
 }
 
+// logIndexLogs returns the logs matching the filter criteria based on the
+// per-address and per-topic log index. The returned flag is false if the log
+// index cannot serve the query, either because it does not cover the entire
+// range or because the filter has no address or topic criteria to look up.
+func (f *Filter) logIndexLogs(ctx context.Context, end uint64) ([]*types.Log, bool, error) {
+	tail, next, ok := rawdb.ReadLogIndexRange(f.db)
+	if !ok || uint64(f.begin) < tail || end >= next {
+		return nil, false, nil
+	}
+	candidates, ok := f.logIndexCandidates(uint64(f.begin), end)
+	if !ok {
+		return nil, false, nil
+	}
+	var logs []*types.Log
+	for _, number := range candidates {
+		if err := ctx.Err(); err != nil {
+			return logs, true, err
+		}
+		f.begin = int64(number) + 1
+
+		header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if header == nil || err != nil {
+			return logs, true, err
+		}
+		found, err := f.checkMatches(ctx, header)
+		if err != nil {
+			return logs, true, err
+		}
+		logs = append(logs, found...)
+	}
+	f.begin = int64(end) + 1
+	return logs, true, nil
+}
+
+// logIndexCandidates returns the ascending numbers of the blocks in the range
+// [begin, end] which may contain logs matching the filter criteria according to
+// the log index. The returned flag is false if the filter has no criteria.
+func (f *Filter) logIndexCandidates(begin, end uint64) ([]uint64, bool) {
+	var set map[uint64]struct{}
+	restrict := func(numbers map[uint64]struct{}) {
+		if set == nil {
+			set = numbers
+			return
+		}
+		for number := range set {
+			if _, ok := numbers[number]; !ok {
+				delete(set, number)
+			}
+		}
+	}
+	if len(f.addresses) > 0 {
+		union := make(map[uint64]struct{})
+		for _, address := range f.addresses {
+			for _, number := range rawdb.ReadLogIndexAddressBlocks(f.db, address, begin, end) {
+				union[number] = struct{}{}
+			}
+		}
+		restrict(union)
+	}
+	for _, sub := range f.topics {
+		if len(sub) == 0 {
+			continue
+		}
+		union := make(map[uint64]struct{})
+		for _, topic := range sub {
+			for _, number := range rawdb.ReadLogIndexTopicBlocks(f.db, topic, begin, end) {
+				union[number] = struct{}{}
+			}
+		}
+		restrict(union)
+	}
+	if set == nil {
+		return nil, false
+	}
+	numbers := make([]uint64, 0, len(set))
+	for number := range set {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	return numbers, true
+}
+
 // unindexedLogs returns the logs matching the filter criteria based on raw block
 // iteration and bloom matching.
 func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
@@ -21,9 +21,11 @@ import (
 	"io/ioutil"
 	"math/big"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
 	"github.com/scroll-tech/go-ethereum/consensus/ethash"
 	"github.com/scroll-tech/go-ethereum/core"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
@@ -227,3 +229,168 @@ func TestFilters(t *testing.T) {
 		t.Error("expected 0 log, got", len(logs))
 	}
 }
+
+func TestLogIndexFilters(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db}
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key1.PublicKey)
+		other   = common.BytesToAddress([]byte("other"))
+
+		hash1 = common.BytesToHash([]byte("topic1"))
+		hash2 = common.BytesToHash([]byte("topic2"))
+	)
+	genesis := core.GenesisBlockForTesting(db, addr, big.NewInt(1000000))
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 100, func(i int, gen *core.BlockGen) {
+		var log *types.Log
+		switch i {
+		case 9:
+			log = &types.Log{Address: addr, Topics: []common.Hash{hash1}}
+		case 49:
+			log = &types.Log{Address: addr, Topics: []common.Hash{hash2}}
+		case 89:
+			log = &types.Log{Address: other, Topics: []common.Hash{hash1, hash2}}
+		default:
+			return
+		}
+		receipt := types.NewReceipt(nil, false, 0)
+		receipt.Logs = []*types.Log{log}
+		gen.AddUncheckedReceipt(receipt)
+		gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.HexToAddress("0x1"), big.NewInt(1), 1, gen.BaseFee(), nil))
+	})
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	rawdb.WriteLogIndexTail(db, 0)
+	if err := rawdb.IndexLogs(db, 0, 100, nil); err != nil {
+		t.Fatalf("failed to index logs: %v", err)
+	}
+	// Stale entries must be filtered out by checking the actual logs
+	rawdb.WriteLogIndex(db, 20, types.Receipts{{Logs: []*types.Log{{Address: addr, Topics: []common.Hash{hash1}}}}})
+
+	tests := []struct {
+		begin, end int64
+		addresses  []common.Address
+		topics     [][]common.Hash
+		want       []uint64
+	}{
+		{0, -1, []common.Address{addr}, nil, []uint64{10, 50}},
+		{0, -1, nil, [][]common.Hash{{hash1}}, []uint64{10, 90}},
+		{0, -1, []common.Address{addr}, [][]common.Hash{{hash2}}, []uint64{50}},
+		{0, -1, nil, [][]common.Hash{{hash1}, {hash2}}, []uint64{90}},
+		{0, -1, []common.Address{addr, other}, [][]common.Hash{{hash1, hash2}}, []uint64{10, 50, 90}},
+		{11, 89, []common.Address{addr, other}, nil, []uint64{50}},
+		{0, -1, []common.Address{common.BytesToAddress([]byte("fail"))}, nil, nil},
+	}
+	for i, tt := range tests {
+		filter := NewRangeFilter(backend, tt.begin, tt.end, tt.addresses, tt.topics)
+		logs, err := filter.Logs(context.Background())
+		if err != nil {
+			t.Fatalf("test %d: failed to filter logs: %v", i, err)
+		}
+		var have []uint64
+		for _, log := range logs {
+			have = append(have, log.BlockNumber)
+		}
+		if len(have) != len(tt.want) {
+			t.Fatalf("test %d: log blocks mismatch: have %v, want %v", i, have, tt.want)
+		}
+		for j := range have {
+			if have[j] != tt.want[j] {
+				t.Fatalf("test %d: log blocks mismatch: have %v, want %v", i, have, tt.want)
+			}
+		}
+	}
+	// Entries missing from the index are not found, proving it is being used
+	db.Delete(append(append([]byte("la-"), other.Bytes()...), 0, 0, 0, 0, 0, 0, 0, 90))
+	filter := NewRangeFilter(backend, 0, -1, []common.Address{other}, nil)
+	if logs, _ := filter.Logs(context.Background()); len(logs) != 0 {
+		t.Fatalf("expected the log index to serve the query, got %d logs", len(logs))
+	}
+}
+
+func TestGetLogsPaginated(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db}
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key1.PublicKey)
+	)
+	genesis := core.GenesisBlockForTesting(db, addr, big.NewInt(1000000))
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 30, func(i int, gen *core.BlockGen) {
+		var count int
+		switch i {
+		case 2, 11, 24:
+			count = 1
+		case 3:
+			count = 3
+		default:
+			return
+		}
+		receipt := types.NewReceipt(nil, false, 0)
+		for j := 0; j < count; j++ {
+			receipt.Logs = append(receipt.Logs, &types.Log{Address: addr})
+		}
+		gen.AddUncheckedReceipt(receipt)
+		gen.AddUncheckedTx(types.NewTransaction(uint64(i), common.HexToAddress("0x1"), big.NewInt(1), 1, gen.BaseFee(), nil))
+	})
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	var (
+		api  = NewPublicFilterAPI(backend, false, deadline, LogQueryLimits{BlockRange: 10, Results: 2})
+		crit = FilterCriteria{FromBlock: big.NewInt(0), Addresses: []common.Address{addr}}
+	)
+	if _, err := api.GetLogs(context.Background(), crit); err == nil {
+		t.Fatal("expected block range limit to be enforced")
+	}
+	if _, err := api.GetLogs(context.Background(), FilterCriteria{FromBlock: big.NewInt(0), ToBlock: big.NewInt(9)}); err == nil {
+		t.Fatal("expected result limit to be enforced")
+	}
+	// Walk all the pages and check that every log is returned exactly once
+	type position struct {
+		number uint64
+		index  uint
+	}
+	var (
+		have   []position
+		cursor *hexutil.Bytes
+		pages  int
+	)
+	for {
+		page, err := api.GetLogsPaginated(context.Background(), crit, cursor)
+		if err != nil {
+			t.Fatalf("failed to retrieve page %d: %v", pages, err)
+		}
+		if len(page.Logs) > 2 {
+			t.Fatalf("page %d exceeds result limit: %d logs", pages, len(page.Logs))
+		}
+		for _, log := range page.Logs {
+			have = append(have, position{log.BlockNumber, log.Index})
+		}
+		pages++
+		if cursor = page.Cursor; cursor == nil {
+			break
+		}
+	}
+	want := []position{{3, 0}, {4, 0}, {4, 1}, {4, 2}, {12, 0}, {25, 0}}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("paginated logs mismatch: have %v, want %v", have, want)
+	}
+	if pages != 4 {
+		t.Fatalf("page count mismatch: have %d, want 4", pages)
+	}
+	// Cursors outside of the queried range are rejected
+	bad := hexutil.Bytes(make([]byte, logCursorLength))
+	bad[7] = 100
+	if _, err := api.GetLogsPaginated(context.Background(), crit, &bad); err != errInvalidLogCursor {
+		t.Fatalf("error mismatch: have %v, want %v", err, errInvalidLogCursor)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// maxTrackedTxs is the maximum number of transactions a single transaction
+// status subscription follows at once. Further matches are still reported, but
+// not followed to finality.
+const maxTrackedTxs = 4096
+
+// Statuses of a transaction as reported by the transaction status subscription.
+const (
+	TxStatusPending   = "pending"   // Entered the transaction pool
+	TxStatusIncluded  = "included"  // Included in a canonical block
+	TxStatusReorged   = "reorged"   // Block including it was reorged away
+	TxStatusFinalized = "finalized" // Block including it was finalized on L1
+	TxStatusDropped   = "dropped"   // Left the transaction pool without being included
+)
+
+// TxStatusTarget selects the transactions a status subscription follows: a
+// single transaction by its hash, or all transactions sent from or to an
+// address.
+type TxStatusTarget struct {
+	Hash    *common.Hash
+	Address *common.Address
+}
+
+// UnmarshalJSON parses a transaction hash or an address.
+func (t *TxStatusTarget) UnmarshalJSON(data []byte) error {
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	switch len(raw) {
+	case common.HashLength:
+		hash := common.BytesToHash(raw)
+		t.Hash, t.Address = &hash, nil
+	case common.AddressLength:
+		addr := common.BytesToAddress(raw)
+		t.Hash, t.Address = nil, &addr
+	default:
+		return fmt.Errorf("invalid transaction status target length %d, want hash or address", len(raw))
+	}
+	return nil
+}
+
+// matches returns whether the transaction is followed by the subscription.
+func (t *TxStatusTarget) matches(tx *types.Transaction) bool {
+	if t.Hash != nil {
+		return tx.Hash() == *t.Hash
+	}
+	if to := tx.To(); to != nil && *to == *t.Address {
+		return true
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	return err == nil && from == *t.Address
+}
+
+// TxStatusEvent is a change of the status of a transaction.
+type TxStatusEvent struct {
+	TxHash      common.Hash     `json:"transactionHash"`
+	Status      string          `json:"status"`
+	BlockNumber *hexutil.Uint64 `json:"blockNumber,omitempty"`
+	BlockHash   *common.Hash    `json:"blockHash,omitempty"`
+}
+
+// trackedTx is the last known inclusion of a followed transaction, the zero
+// value if it's pending.
+type trackedTx struct {
+	number uint64
+	hash   common.Hash
+}
+
+// TransactionStatus creates a subscription reporting the transactions with the
+// given hash or sent from or to the given address, as they enter the pool, get
+// included in a block and that block is finalized on L1, or as they're dropped
+// from the pool or their block is reorged away. For a transaction hash, its
+// current status is reported first.
+func (api *PublicFilterAPI) TransactionStatus(ctx context.Context, target TxStatusTarget) (*rpc.Subscription, error) {
+	if target.Hash == nil && target.Address == nil {
+		return nil, fmt.Errorf("missing transaction hash or address")
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	// Subscribe upfront so that no change is missed after the initial status
+	var (
+		txs      = make(chan core.NewTxsEvent, txChanSize)
+		blocks   = make(chan core.ChainEvent, chainEvChanSize)
+		finals   = make(chan core.FinalizedEvent, chainEvChanSize)
+		txSub    = api.backend.SubscribeNewTxsEvent(txs)
+		blockSub = api.backend.SubscribeChainEvent(blocks)
+		finalSub = api.backend.SubscribeFinalizedEvent(finals)
+	)
+	go func() {
+		tracked := make(map[common.Hash]*trackedTx)
+
+		defer txSub.Unsubscribe()
+		defer blockSub.Unsubscribe()
+		defer finalSub.Unsubscribe()
+
+		notify := func(hash common.Hash, status string, inclusion *trackedTx) {
+			ev := &TxStatusEvent{TxHash: hash, Status: status}
+			if inclusion != nil {
+				ev.BlockNumber, ev.BlockHash = (*hexutil.Uint64)(&inclusion.number), &inclusion.hash
+			}
+			notifier.Notify(rpcSub.ID, ev)
+		}
+		track := func(hash common.Hash, inclusion *trackedTx) {
+			if _, ok := tracked[hash]; ok || len(tracked) < maxTrackedTxs {
+				tracked[hash] = inclusion
+			}
+		}
+		if target.Hash != nil {
+			if tx, blockHash, number, _ := rawdb.ReadTransaction(api.backend.ChainDb(), *target.Hash); tx != nil {
+				inclusion := &trackedTx{number: number, hash: blockHash}
+				if header, _ := api.backend.HeaderByNumber(ctx, rpc.FinalizedBlockNumber); header != nil && header.Number.Uint64() >= number {
+					notify(*target.Hash, TxStatusFinalized, inclusion)
+				} else {
+					notify(*target.Hash, TxStatusIncluded, inclusion)
+					track(*target.Hash, inclusion)
+				}
+			} else if api.backend.GetPoolTransaction(*target.Hash) != nil {
+				notify(*target.Hash, TxStatusPending, nil)
+				track(*target.Hash, &trackedTx{})
+			}
+		}
+		for {
+			select {
+			case ev := <-txs:
+				for _, tx := range ev.Txs {
+					if _, ok := tracked[tx.Hash()]; !ok && target.matches(tx) {
+						notify(tx.Hash(), TxStatusPending, nil)
+						track(tx.Hash(), &trackedTx{})
+					}
+				}
+			case ev := <-blocks:
+				inclusion := &trackedTx{number: ev.Block.NumberU64(), hash: ev.Block.Hash()}
+				for _, tx := range ev.Block.Transactions() {
+					if target.matches(tx) {
+						notify(tx.Hash(), TxStatusIncluded, inclusion)
+						track(tx.Hash(), inclusion)
+					}
+				}
+				// Check the transactions followed for reorgs and drops
+				db := api.backend.ChainDb()
+				for hash, last := range tracked {
+					if last.hash != (common.Hash{}) {
+						if rawdb.ReadCanonicalHash(db, last.number) != last.hash {
+							notify(hash, TxStatusReorged, last)
+							tracked[hash] = &trackedTx{}
+						}
+						continue
+					}
+					if api.backend.GetPoolTransaction(hash) == nil && rawdb.ReadTxLookupEntry(db, hash) == nil {
+						notify(hash, TxStatusDropped, nil)
+						delete(tracked, hash)
+					}
+				}
+			case ev := <-finals:
+				db := api.backend.ChainDb()
+				for hash, last := range tracked {
+					if last.hash == (common.Hash{}) || last.number > ev.Block.NumberU64() {
+						continue
+					}
+					if rawdb.ReadCanonicalHash(db, last.number) == last.hash {
+						notify(hash, TxStatusFinalized, last)
+						delete(tracked, hash)
+					}
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
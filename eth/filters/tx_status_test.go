@@ -0,0 +1,118 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// Tests that transaction status subscriptions follow transactions from the pool
+// to finality, and report the ones dropped.
+func TestTransactionStatusSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &core.Genesis{Config: params.TestChainConfig, Alloc: core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(params.TestChainConfig)
+
+		tx1, _ = types.SignTx(types.NewTransaction(0, common.Address{0x01}, big.NewInt(1), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, key)
+		tx2, _ = types.SignTx(types.NewTransaction(1, common.Address{0x01}, big.NewInt(1), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, key)
+	)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 1, func(i int, b *core.BlockGen) {
+		b.AddTx(tx1)
+	})
+	backend := &testBackend{db: db, pool: map[common.Hash]*types.Transaction{tx1.Hash(): tx1, tx2.Hash(): tx2}}
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", NewPublicFilterAPI(backend, false, deadline, LogQueryLimits{})); err != nil {
+		t.Fatalf("failed to register filter API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	subscribe := func(target interface{}) (chan *TxStatusEvent, *rpc.ClientSubscription) {
+		events := make(chan *TxStatusEvent, 16)
+		sub, err := client.EthSubscribe(context.Background(), events, "transactionStatus", target)
+		if err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+		return events, sub
+	}
+	expect := func(events chan *TxStatusEvent, tx *types.Transaction, status string, block *types.Block) {
+		t.Helper()
+		select {
+		case ev := <-events:
+			if ev.TxHash != tx.Hash() || ev.Status != status {
+				t.Fatalf("event mismatch: have %x %s, want %x %s", ev.TxHash, ev.Status, tx.Hash(), status)
+			}
+			if block == nil {
+				if ev.BlockHash != nil {
+					t.Fatalf("block mismatch: have %x, want none", *ev.BlockHash)
+				}
+				return
+			}
+			if ev.BlockHash == nil || *ev.BlockHash != block.Hash() || uint64(*ev.BlockNumber) != block.NumberU64() {
+				t.Fatalf("block mismatch: have %v, want #%d", ev.BlockNumber, block.NumberU64())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for %s event of %x", status, tx.Hash())
+		}
+	}
+	events, sub := subscribe(addr)
+	defer sub.Unsubscribe()
+
+	backend.txFeed.Send(core.NewTxsEvent{Txs: []*types.Transaction{tx1, tx2}})
+	expect(events, tx1, TxStatusPending, nil)
+	expect(events, tx2, TxStatusPending, nil)
+
+	// Include the first transaction and evict the second one from the pool
+	rawdb.WriteBlock(db, blocks[0])
+	rawdb.WriteCanonicalHash(db, blocks[0].Hash(), 1)
+	rawdb.WriteTxLookupEntriesByBlock(db, blocks[0])
+	delete(backend.pool, tx1.Hash())
+	delete(backend.pool, tx2.Hash())
+
+	backend.chainFeed.Send(core.ChainEvent{Block: blocks[0], Hash: blocks[0].Hash()})
+	expect(events, tx1, TxStatusIncluded, blocks[0])
+	expect(events, tx2, TxStatusDropped, nil)
+
+	// Subscribing to an included transaction reports its status right away
+	hashEvents, hashSub := subscribe(tx1.Hash())
+	defer hashSub.Unsubscribe()
+	expect(hashEvents, tx1, TxStatusIncluded, blocks[0])
+
+	backend.finalFeed.Send(core.FinalizedEvent{Block: blocks[0]})
+	expect(events, tx1, TxStatusFinalized, blocks[0])
+	expect(hashEvents, tx1, TxStatusFinalized, blocks[0])
+}
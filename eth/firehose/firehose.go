@@ -0,0 +1,249 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package firehose implements an extraction mode writing the canonical blocks in
+// the Firehose format as they're imported, so that Firehose and Substreams based
+// indexing stacks can consume the chain without an instrumented fork of geth.
+//
+// Every block is written as a single line
+//
+//	FIRE BLOCK <number> <hash> <parent number> <parent hash> <lib> <timestamp ns> <payload>
+//
+// following a FIRE INIT line announcing the protocol version and block model,
+// where the payload is the base64 protobuf encoding of an sf.ethereum.type.v2
+// Block and the last irreversible block is the last block finalized on L1.
+// Blocks carry their header, transactions, receipts and logs at the base detail
+// level: call trees and state changes aren't extracted.
+//
+// Extraction starts after the head at startup. The blocks replaced by a reorg
+// are followed by the blocks of the new canonical chain from the common
+// ancestor on, which the Firehose reader handles as a fork.
+package firehose
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+const (
+	// protocolVersion is the version of the Firehose console reader protocol.
+	protocolVersion = "3.0"
+
+	// blockModel is the protobuf type of the block payloads.
+	blockModel = "sf.ethereum.type.v2.Block"
+
+	// chainHeadChanSize is the size of the channel listening to head events.
+	chainHeadChanSize = 16
+)
+
+var (
+	headGauge     = metrics.NewRegisteredGauge("firehose/head", nil)
+	writtenMeter  = metrics.NewRegisteredMeter("firehose/blocks", nil)
+	failuresMeter = metrics.NewRegisteredMeter("firehose/failures", nil)
+)
+
+// Config contains the settings of the Firehose extraction.
+type Config struct {
+	Enabled bool   `toml:",omitempty"` // Whether to write the blocks in the Firehose format
+	Output  string `toml:",omitempty"` // File to append the blocks to (empty = stdout)
+}
+
+// blockChain is the view of the chain needed to extract its blocks.
+type blockChain interface {
+	Config() *params.ChainConfig
+	CurrentBlock() *types.Block
+	CurrentFinalizedBlock() *types.Block
+	GetBlockByNumber(number uint64) *types.Block
+	GetHeaderByHash(hash common.Hash) *types.Header
+	GetCanonicalHash(number uint64) common.Hash
+	GetReceiptsByHash(hash common.Hash) types.Receipts
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// Service writes the canonical blocks in the Firehose format in the background.
+type Service struct {
+	chain  blockChain
+	out    *bufio.Writer
+	closer io.Closer // Output file to close on termination, nil for stdout
+
+	last *types.Header // Last block written, or the head at startup
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a Firehose extraction writing to the configured output and
+// registers it with the node.
+func New(stack *node.Node, chain blockChain, config Config) error {
+	if config.Output == "" {
+		stack.RegisterLifecycle(newService(chain, os.Stdout, nil))
+		return nil
+	}
+	f, err := os.OpenFile(config.Output, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	stack.RegisterLifecycle(newService(chain, f, f))
+	return nil
+}
+
+func newService(chain blockChain, out io.Writer, closer io.Closer) *Service {
+	return &Service{
+		chain:  chain,
+		out:    bufio.NewWriter(out),
+		closer: closer,
+		quit:   make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, starting to write the blocks.
+func (s *Service) Start() error {
+	s.last = s.chain.CurrentBlock().Header()
+	if err := s.writeLine(fmt.Sprintf("FIRE INIT %s %s", protocolVersion, blockModel)); err != nil {
+		return err
+	}
+	heads := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	sub := s.chain.SubscribeChainHeadEvent(heads)
+
+	s.wg.Add(1)
+	go s.loop(heads, sub)
+
+	log.Info("Started Firehose extraction", "number", s.last.Number)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the extraction.
+func (s *Service) Stop() error {
+	close(s.quit)
+	s.wg.Wait()
+
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+	return nil
+}
+
+// loop writes the blocks up to the head whenever it changes, until termination.
+func (s *Service) loop(heads chan core.ChainHeadEvent, sub event.Subscription) {
+	defer s.wg.Done()
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-heads:
+			if err := s.sync(); err != nil {
+				failuresMeter.Mark(1)
+				log.Error("Failed to write Firehose blocks", "err", err)
+			}
+		case <-sub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// sync writes the canonical blocks after the last one written up to the head,
+// rewinding to the common ancestor first if the last block was reorged away.
+func (s *Service) sync() error {
+	head := s.chain.CurrentBlock()
+
+	last := s.last
+	for s.chain.GetCanonicalHash(last.Number.Uint64()) != last.Hash() {
+		parent := s.chain.GetHeaderByHash(last.ParentHash)
+		if parent == nil {
+			return fmt.Errorf("ancestor %x of block %d not found", last.ParentHash, last.Number)
+		}
+		last = parent
+	}
+	if last != s.last {
+		log.Warn("Firehose block reorged, writing from common ancestor", "number", s.last.Number, "ancestor", last.Number)
+		s.last = last
+	}
+	for next := last.Number.Uint64() + 1; next <= head.NumberU64(); next++ {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		block := s.chain.GetBlockByNumber(next)
+		if block == nil {
+			return fmt.Errorf("canonical block %d not found", next)
+		}
+		if err := s.writeBlock(block); err != nil {
+			return err
+		}
+		s.last = block.Header()
+		headGauge.Update(int64(next))
+		writtenMeter.Mark(1)
+	}
+	return nil
+}
+
+// writeBlock writes the Firehose line of a block.
+func (s *Service) writeBlock(block *types.Block) error {
+	receipts := s.chain.GetReceiptsByHash(block.Hash())
+	if len(receipts) != len(block.Transactions()) {
+		return fmt.Errorf("receipts of block %d not found", block.NumberU64())
+	}
+	var (
+		signer  = types.MakeSigner(s.chain.Config(), block.Number())
+		senders = make([]common.Address, len(block.Transactions()))
+	)
+	for i, tx := range block.Transactions() {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return fmt.Errorf("invalid transaction %d of block %d: %v", i, block.NumberU64(), err)
+		}
+		senders[i] = from
+	}
+	var (
+		number = block.NumberU64()
+		parent = number
+		lib    uint64
+	)
+	if number > 0 {
+		parent = number - 1
+	}
+	if finalized := s.chain.CurrentFinalizedBlock(); finalized != nil && finalized.NumberU64() <= number {
+		lib = finalized.NumberU64()
+	}
+	payload := base64.StdEncoding.EncodeToString(encodeBlock(block, receipts, senders))
+	return s.writeLine(fmt.Sprintf("FIRE BLOCK %d %x %d %x %d %d %s",
+		number, block.Hash(), parent, block.ParentHash(), lib, block.Time()*1e9, payload))
+}
+
+// writeLine writes and flushes a line to the output, so that lines are never
+// interleaved with partial output.
+func (s *Service) writeLine(line string) error {
+	if _, err := s.out.WriteString(line + "\n"); err != nil {
+		return err
+	}
+	return s.out.Flush()
+}
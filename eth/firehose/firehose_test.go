@@ -0,0 +1,143 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package firehose
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// decodeFields returns the raw values of the top level fields of a message,
+// keyed by field number.
+func decodeFields(t *testing.T, msg []byte) map[protowire.Number][][]byte {
+	fields := make(map[protowire.Number][][]byte)
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			t.Fatalf("invalid tag: %v", protowire.ParseError(n))
+		}
+		msg = msg[n:]
+		m := protowire.ConsumeFieldValue(num, typ, msg)
+		if m < 0 {
+			t.Fatalf("invalid field %d: %v", num, protowire.ParseError(m))
+		}
+		value := msg[:m]
+		if typ == protowire.BytesType {
+			value, _ = protowire.ConsumeBytes(value)
+		}
+		fields[num] = append(fields[num], value)
+		msg = msg[m:]
+	}
+	return fields
+}
+
+func TestFirehose(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &core.Genesis{Config: params.TestChainConfig, Alloc: core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(params.TestChainConfig)
+	)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 4, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{0x01}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	fork, _ := core.GenerateChain(params.TestChainConfig, blocks[1], ethash.NewFaker(), db, 3, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x02})
+	})
+	chain, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	var (
+		out = new(bytes.Buffer)
+		s   = newService(chain, out, nil)
+	)
+	s.last = chain.CurrentBlock().Header()
+
+	// Blocks after the start are written with their transactions and receipts
+	if _, err := chain.InsertChain(blocks[1:]); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	if err := s.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("written lines mismatch: have %d, want 3", len(lines))
+	}
+	block := blocks[1]
+	prefix := fmt.Sprintf("FIRE BLOCK 2 %x 1 %x 0 %d ", block.Hash(), block.ParentHash(), block.Time()*1e9)
+	if !strings.HasPrefix(lines[0], prefix) {
+		t.Fatalf("block line mismatch: have %q, want prefix %q", lines[0], prefix)
+	}
+	payload, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(lines[0], prefix))
+	if err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	fields := decodeFields(t, payload)
+	if hash := fields[2]; len(hash) != 1 || !bytes.Equal(hash[0], block.Hash().Bytes()) {
+		t.Fatalf("block hash mismatch: have %x, want %x", hash, block.Hash())
+	}
+	txs := fields[10]
+	if len(txs) != 1 {
+		t.Fatalf("transaction count mismatch: have %d, want 1", len(txs))
+	}
+	tx := decodeFields(t, txs[0])
+	if hash := tx[21]; len(hash) != 1 || !bytes.Equal(hash[0], block.Transactions()[0].Hash().Bytes()) {
+		t.Fatalf("transaction hash mismatch: have %x", hash)
+	}
+	if from := tx[22]; len(from) != 1 || !bytes.Equal(from[0], addr.Bytes()) {
+		t.Fatalf("sender mismatch: have %x, want %x", from, addr)
+	}
+	if len(tx[31]) != 1 {
+		t.Fatalf("receipt missing")
+	}
+	// Reorgs are followed by the new canonical blocks from the common ancestor
+	out.Reset()
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	if err := s.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	lines = strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], fmt.Sprintf("FIRE BLOCK 3 %x 2 %x ", fork[0].Hash(), blocks[1].Hash())) {
+		t.Fatalf("fork lines mismatch: %d lines, first %.100q", len(lines), lines[0])
+	}
+}
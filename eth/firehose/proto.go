@@ -0,0 +1,199 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package firehose
+
+import (
+	"math/big"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// The encoders below produce the protobuf wire encoding of the messages of the
+// sf.ethereum.type.v2 schema, field numbers included, without depending on its
+// generated bindings. Only the fields of the base detail level are encoded.
+
+// blockVersion is the version of the block model written in the Block.ver field.
+const blockVersion = 3
+
+// Field values of the enums of the schema.
+const (
+	detailLevelBase = 2
+
+	statusSucceeded = 1
+	statusFailed    = 2
+)
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendUint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// appendMessage appends an embedded message, even if empty.
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+// appendBigInt appends a BigInt message holding the big endian bytes of v,
+// omitted if v is nil.
+func appendBigInt(b []byte, num protowire.Number, v *big.Int) []byte {
+	if v == nil {
+		return b
+	}
+	return appendMessage(b, num, appendBytes(nil, 1, v.Bytes()))
+}
+
+// encodeBlock encodes a Block message from a block and the receipts and senders
+// of its transactions.
+func encodeBlock(block *types.Block, receipts types.Receipts, senders []common.Address) []byte {
+	hash := block.Hash()
+
+	var b []byte
+	b = appendUint(b, 1, blockVersion)
+	b = appendBytes(b, 2, hash[:])
+	b = appendUint(b, 3, block.NumberU64())
+	b = appendUint(b, 4, uint64(block.Size()))
+	b = appendMessage(b, 5, encodeHeader(block.Header()))
+	for _, uncle := range block.Uncles() {
+		b = appendMessage(b, 6, encodeHeader(uncle))
+	}
+	var logIndex uint32
+	for i, tx := range block.Transactions() {
+		b = appendMessage(b, 10, encodeTransaction(tx, uint32(i), senders[i], receipts[i], &logIndex))
+	}
+	b = appendUint(b, 12, detailLevelBase)
+	return b
+}
+
+// encodeHeader encodes a BlockHeader message.
+func encodeHeader(header *types.Header) []byte {
+	hash := header.Hash()
+
+	var b []byte
+	b = appendBytes(b, 1, header.ParentHash[:])
+	b = appendBytes(b, 2, header.UncleHash[:])
+	b = appendBytes(b, 3, header.Coinbase[:])
+	b = appendBytes(b, 4, header.Root[:])
+	b = appendBytes(b, 5, header.TxHash[:])
+	b = appendBytes(b, 6, header.ReceiptHash[:])
+	b = appendBytes(b, 7, header.Bloom[:])
+	b = appendBigInt(b, 8, header.Difficulty)
+	b = appendUint(b, 9, header.Number.Uint64())
+	b = appendUint(b, 10, header.GasLimit)
+	b = appendUint(b, 11, header.GasUsed)
+	b = appendMessage(b, 12, appendUint(nil, 1, header.Time)) // google.protobuf.Timestamp
+	b = appendBytes(b, 13, header.Extra)
+	b = appendBytes(b, 14, header.MixDigest[:])
+	b = appendUint(b, 15, header.Nonce.Uint64())
+	b = appendBytes(b, 16, hash[:])
+	b = appendBigInt(b, 18, header.BaseFee)
+	return b
+}
+
+// encodeTransaction encodes a TransactionTrace message, numbering its logs from
+// the given block-wide log index.
+func encodeTransaction(tx *types.Transaction, index uint32, from common.Address, receipt *types.Receipt, logIndex *uint32) []byte {
+	var (
+		hash    = tx.Hash()
+		v, r, s = tx.RawSignatureValues()
+	)
+	var b []byte
+	if to := tx.To(); to != nil {
+		b = appendBytes(b, 1, to[:])
+	}
+	b = appendUint(b, 2, tx.Nonce())
+	b = appendBigInt(b, 3, tx.GasPrice())
+	b = appendUint(b, 4, tx.Gas())
+	b = appendBigInt(b, 5, tx.Value())
+	b = appendBytes(b, 6, tx.Data())
+	b = appendBytes(b, 7, v.Bytes())
+	b = appendBytes(b, 8, r.Bytes())
+	b = appendBytes(b, 9, s.Bytes())
+	b = appendUint(b, 10, receipt.GasUsed)
+	if tx.Type() == types.DynamicFeeTxType {
+		b = appendBigInt(b, 11, tx.GasFeeCap())
+	}
+	b = appendUint(b, 12, uint64(tx.Type()))
+	if tx.Type() == types.DynamicFeeTxType {
+		b = appendBigInt(b, 13, tx.GasTipCap())
+	}
+	for _, tuple := range tx.AccessList() {
+		b = appendMessage(b, 14, encodeAccessTuple(tuple))
+	}
+	b = appendUint(b, 20, uint64(index))
+	b = appendBytes(b, 21, hash[:])
+	b = appendBytes(b, 22, from[:])
+
+	status := uint64(statusSucceeded)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		status = statusFailed
+	}
+	b = appendUint(b, 30, status)
+	b = appendMessage(b, 31, encodeReceipt(receipt, logIndex))
+	return b
+}
+
+// encodeAccessTuple encodes an AccessTuple message.
+func encodeAccessTuple(tuple types.AccessTuple) []byte {
+	var b []byte
+	b = appendBytes(b, 1, tuple.Address[:])
+	for _, key := range tuple.StorageKeys {
+		b = appendMessage(b, 2, key[:])
+	}
+	return b
+}
+
+// encodeReceipt encodes a TransactionReceipt message, numbering its logs from
+// the given block-wide log index.
+func encodeReceipt(receipt *types.Receipt, logIndex *uint32) []byte {
+	var b []byte
+	b = appendBytes(b, 1, receipt.PostState)
+	b = appendUint(b, 2, receipt.CumulativeGasUsed)
+	b = appendBytes(b, 3, receipt.Bloom[:])
+	for i, l := range receipt.Logs {
+		b = appendMessage(b, 4, encodeLog(l, uint32(i), *logIndex))
+		*logIndex++
+	}
+	return b
+}
+
+// encodeLog encodes a Log message.
+func encodeLog(l *types.Log, index uint32, blockIndex uint32) []byte {
+	var b []byte
+	b = appendBytes(b, 1, l.Address[:])
+	for _, topic := range l.Topics {
+		b = appendMessage(b, 2, topic[:])
+	}
+	b = appendBytes(b, 3, l.Data)
+	b = appendUint(b, 4, uint64(index))
+	b = appendUint(b, 6, uint64(blockIndex))
+	return b
+}
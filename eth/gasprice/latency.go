@@ -0,0 +1,182 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/event"
+)
+
+const (
+	// LatencyBlocks is the number of recent blocks whose pool wait times are
+	// retained.
+	LatencyBlocks = 1024
+
+	// maxTrackedWait is the time after which transactions still waiting in the
+	// pool are no longer tracked.
+	maxTrackedWait = time.Hour
+
+	// pruneInterval is the frequency to drop the transactions waiting for too
+	// long.
+	pruneInterval = time.Minute
+
+	// latencyChanSize is the size of the channels listening to pool and chain
+	// events.
+	latencyChanSize = 256
+)
+
+// LatencyBackend includes the event sources needed to track pool wait times.
+type LatencyBackend interface {
+	SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription
+	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
+}
+
+// LatencyTracker records how long the transactions included in recent blocks
+// waited in the local pool, from the time the pool first saw them until their
+// block was accepted. Transactions included without passing through the pool,
+// or waiting longer than maxTrackedWait, aren't measured.
+type LatencyTracker struct {
+	seen  map[common.Hash]time.Time // Pool transactions and the time they were first seen
+	waits *lru.Cache                // Sorted wait times of the transactions of recent blocks, by block hash
+	lock  sync.Mutex
+
+	txSub    event.Subscription
+	chainSub event.Subscription
+	wg       sync.WaitGroup
+}
+
+// NewLatencyTracker creates a tracker of the pool wait times of the
+// transactions of the blocks accepted from now on.
+func NewLatencyTracker(backend LatencyBackend) *LatencyTracker {
+	waits, _ := lru.New(LatencyBlocks)
+	t := &LatencyTracker{
+		seen:  make(map[common.Hash]time.Time),
+		waits: waits,
+	}
+	var (
+		txs    = make(chan core.NewTxsEvent, latencyChanSize)
+		blocks = make(chan core.ChainEvent, latencyChanSize)
+	)
+	t.txSub = backend.SubscribeNewTxsEvent(txs)
+	t.chainSub = backend.SubscribeChainEvent(blocks)
+
+	t.wg.Add(1)
+	go t.loop(txs, blocks)
+	return t
+}
+
+// Stop terminates the tracker.
+func (t *LatencyTracker) Stop() {
+	t.txSub.Unsubscribe()
+	t.chainSub.Unsubscribe()
+	t.wg.Wait()
+}
+
+// loop tracks the pool and the chain until termination.
+func (t *LatencyTracker) loop(txs chan core.NewTxsEvent, blocks chan core.ChainEvent) {
+	defer t.wg.Done()
+
+	prune := time.NewTicker(pruneInterval)
+	defer prune.Stop()
+
+	for {
+		select {
+		case ev := <-txs:
+			t.added(ev, time.Now())
+		case ev := <-blocks:
+			t.included(ev, time.Now())
+		case now := <-prune.C:
+			t.prune(now)
+		case <-t.txSub.Err():
+			return
+		case <-t.chainSub.Err():
+			return
+		}
+	}
+}
+
+// added records the time the pool saw new transactions.
+func (t *LatencyTracker) added(ev core.NewTxsEvent, now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, tx := range ev.Txs {
+		if _, ok := t.seen[tx.Hash()]; !ok {
+			t.seen[tx.Hash()] = now
+		}
+	}
+}
+
+// included records the wait times of the pool transactions included by a block.
+func (t *LatencyTracker) included(ev core.ChainEvent, now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var waits []time.Duration
+	for _, tx := range ev.Block.Transactions() {
+		if seen, ok := t.seen[tx.Hash()]; ok {
+			waits = append(waits, now.Sub(seen))
+			delete(t.seen, tx.Hash())
+		}
+	}
+	sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+	t.waits.Add(ev.Hash, waits)
+}
+
+// prune drops the transactions waiting in the pool for too long.
+func (t *LatencyTracker) prune(now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for hash, seen := range t.seen {
+		if now.Sub(seen) > maxTrackedWait {
+			delete(t.seen, hash)
+		}
+	}
+}
+
+// Waits returns the pool wait times of the transactions of the given block at
+// the given percentiles, along with the number of transactions measured. The
+// ok flag is false if the block wasn't tracked.
+func (t *LatencyTracker) Waits(hash common.Hash, percentiles []float64) (waits []time.Duration, measured int, ok bool) {
+	cached, ok := t.waits.Get(hash)
+	if !ok {
+		return nil, 0, false
+	}
+	sorted := cached.([]time.Duration)
+
+	waits = make([]time.Duration, len(percentiles))
+	if len(sorted) == 0 {
+		return waits, 0, true
+	}
+	for i, p := range percentiles {
+		index := int(math.Ceil(float64(len(sorted))*p/100)) - 1
+		if index < 0 {
+			index = 0
+		}
+		waits[i] = sorted[index]
+	}
+	return waits, len(sorted), true
+}
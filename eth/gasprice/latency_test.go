@@ -0,0 +1,94 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/event"
+)
+
+type testLatencyBackend struct {
+	txFeed    event.Feed
+	chainFeed event.Feed
+}
+
+func (b *testLatencyBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
+	return b.txFeed.Subscribe(ch)
+}
+
+func (b *testLatencyBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
+	return b.chainFeed.Subscribe(ch)
+}
+
+func TestLatencyTracker(t *testing.T) {
+	tracker := NewLatencyTracker(new(testLatencyBackend))
+	defer tracker.Stop()
+
+	var (
+		start = time.Unix(1000, 0)
+		txs   = make([]*types.Transaction, 5)
+	)
+	for i := range txs {
+		txs[i] = types.NewTransaction(uint64(i), common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+		tracker.added(core.NewTxsEvent{Txs: txs[i : i+1]}, start.Add(time.Duration(i)*time.Second))
+	}
+	// Transactions seen again keep their first seen time
+	tracker.added(core.NewTxsEvent{Txs: txs[:1]}, start.Add(time.Hour))
+
+	// Transactions not seen by the pool aren't measured
+	unseen := types.NewTransaction(10, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody(append(txs[:4:4], unseen), nil)
+	tracker.included(core.ChainEvent{Block: block, Hash: block.Hash()}, start.Add(10*time.Second))
+
+	waits, measured, ok := tracker.Waits(block.Hash(), []float64{0, 25, 50, 100})
+	if !ok {
+		t.Fatalf("block not tracked")
+	}
+	if measured != 4 {
+		t.Fatalf("measured transactions mismatch: have %d, want 4", measured)
+	}
+	want := []time.Duration{7 * time.Second, 7 * time.Second, 8 * time.Second, 10 * time.Second}
+	if !reflect.DeepEqual(waits, want) {
+		t.Fatalf("waits mismatch: have %v, want %v", waits, want)
+	}
+	// Included transactions are no longer tracked, others until they expire
+	tracker.lock.Lock()
+	if len(tracker.seen) != 1 {
+		t.Fatalf("tracked transactions mismatch: have %d, want 1", len(tracker.seen))
+	}
+	tracker.lock.Unlock()
+
+	tracker.prune(start.Add(maxTrackedWait + 4*time.Second))
+	if _, ok := tracker.seen[txs[4].Hash()]; !ok {
+		t.Fatalf("transaction pruned before expiry")
+	}
+	tracker.prune(start.Add(maxTrackedWait + 5*time.Second))
+	if _, ok := tracker.seen[txs[4].Hash()]; ok {
+		t.Fatalf("expired transaction not pruned")
+	}
+	// Blocks accepted before tracking are unknown
+	if _, _, ok := tracker.Waits(common.Hash{0x01}, nil); ok {
+		t.Fatalf("untracked block reported")
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package lightproof serves the canonical headers of the chain along with state,
+// receipt and batch finalization proofs over HTTP, so that resource constrained
+// clients can follow the chain verifying what they're served instead of trusting
+// an RPC endpoint.
+//
+// Clients verify the headers through their seals against the known signers,
+// anchor them to L1 through the finalized batches, whose state root must match
+// the state root of their last block, and verify state and receipts through the
+// Merkle proofs against the state and receipt roots of the headers.
+//
+// The endpoints, mounted on the HTTP server under /light/v1, are:
+//
+//	GET /light/v1/headers?from=<number>&count=<count>
+//	GET /light/v1/state?block=<number|tag|hash>&address=<address>[&slot=<key>...]
+//	GET /light/v1/receipt?tx=<hash>
+//	GET /light/v1/batch[?index=<index>]
+package lightproof
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/consensus"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/internal/ethapi"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+// maxHeaders is the maximum number of headers served by a single request.
+const maxHeaders = 1024
+
+// errNotFound is returned if the requested data isn't known.
+var errNotFound = errors.New("not found")
+
+// Header is a canonical header, along with the address that sealed it.
+type Header struct {
+	Header *types.Header  `json:"header"`
+	Hash   common.Hash    `json:"hash"`
+	Signer common.Address `json:"signer"` // Recovered from the seal, for convenience
+}
+
+// ReceiptProof is a receipt with its Merkle proof against the receipt root of
+// its block.
+type ReceiptProof struct {
+	BlockHash   common.Hash     `json:"blockHash"`
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+	ReceiptRoot common.Hash     `json:"receiptRoot"`
+	Index       hexutil.Uint    `json:"index"`   // Position of the receipt, whose RLP encoding is the trie key
+	Receipt     hexutil.Bytes   `json:"receipt"` // Consensus encoding of the receipt, the trie value
+	Proof       []hexutil.Bytes `json:"proof"`
+}
+
+// BatchProof is a batch finalized on L1, along with the header of its last
+// block, whose state root must match the finalized state root.
+type BatchProof struct {
+	Index        hexutil.Uint64 `json:"index"`
+	StateRoot    common.Hash    `json:"stateRoot"`
+	WithdrawRoot common.Hash    `json:"withdrawRoot"`
+	L1Block      hexutil.Uint64 `json:"l1Block"` // L1 block emitting the FinalizeBatch event
+	L1Hash       common.Hash    `json:"l1Hash"`
+	Header       *types.Header  `json:"header"`
+}
+
+// blockChain is the view of the chain needed to serve proofs.
+type blockChain interface {
+	GetHeaderByNumber(number uint64) *types.Header
+	GetHeaderByHash(hash common.Hash) *types.Header
+	GetCanonicalHash(number uint64) common.Hash
+	GetReceiptsByHash(hash common.Hash) types.Receipts
+}
+
+// stateProver creates the proofs of accounts, as served by eth_getProof.
+type stateProver interface {
+	GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*ethapi.AccountResult, error)
+}
+
+// handler serves the light client endpoints.
+type handler struct {
+	chain  blockChain
+	db     ethdb.Database
+	engine consensus.Engine
+	prover stateProver
+}
+
+// New mounts the light client endpoints on the HTTP server of the node.
+func New(stack *node.Node, chain blockChain, db ethdb.Database, engine consensus.Engine, prover stateProver, cors, vhosts []string) {
+	h := &handler{chain: chain, db: db, engine: engine, prover: prover}
+	stack.RegisterHandler("Light client proofs", "/light/v1/", node.NewHTTPHandlerStack(h.mux(), cors, vhosts))
+}
+
+func (h *handler) mux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/light/v1/headers", h.serve(h.headers))
+	mux.HandleFunc("/light/v1/state", h.serve(h.state))
+	mux.HandleFunc("/light/v1/receipt", h.serve(h.receipt))
+	mux.HandleFunc("/light/v1/batch", h.serve(h.batch))
+	return mux
+}
+
+// serve adapts an endpoint to HTTP, encoding its result as JSON.
+func (h *handler) serve(endpoint func(r *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		result, err := endpoint(r)
+		switch {
+		case errors.Is(err, errNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// headers serves a range of consecutive canonical headers.
+func (h *handler) headers(r *http.Request) (interface{}, error) {
+	from, err := strconv.ParseUint(r.URL.Query().Get("from"), 0, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from: %v", err)
+	}
+	count, err := strconv.ParseUint(r.URL.Query().Get("count"), 0, 64)
+	if err != nil || count == 0 || count > maxHeaders {
+		return nil, fmt.Errorf("invalid count, must be between 1 and %d", maxHeaders)
+	}
+	headers := make([]*Header, 0, count)
+	for number := from; number < from+count; number++ {
+		header := h.chain.GetHeaderByNumber(number)
+		if header == nil {
+			break
+		}
+		signer, err := h.engine.Author(header)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seal of header %d: %v", number, err)
+		}
+		headers = append(headers, &Header{Header: header, Hash: header.Hash(), Signer: signer})
+	}
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("%w: header %d", errNotFound, from)
+	}
+	return headers, nil
+}
+
+// state serves the proof of an account and some of its storage slots.
+func (h *handler) state(r *http.Request) (interface{}, error) {
+	query := r.URL.Query()
+
+	var address common.Address
+	if err := address.UnmarshalText([]byte(query.Get("address"))); err != nil {
+		return nil, fmt.Errorf("invalid address: %v", err)
+	}
+	block := query.Get("block")
+	if block == "" {
+		block = "latest"
+	}
+	var blockNrOrHash rpc.BlockNumberOrHash
+	if number, err := strconv.ParseUint(block, 10, 63); err == nil {
+		blockNrOrHash = rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(number))
+	} else if err := blockNrOrHash.UnmarshalJSON([]byte(strconv.Quote(block))); err != nil {
+		return nil, fmt.Errorf("invalid block: %v", err)
+	}
+	slots := query["slot"]
+	if slots == nil {
+		slots = []string{}
+	}
+	return h.prover.GetProof(r.Context(), address, slots, blockNrOrHash)
+}
+
+// receipt serves the proof of the receipt of a transaction.
+func (h *handler) receipt(r *http.Request) (interface{}, error) {
+	var hash common.Hash
+	if err := hash.UnmarshalText([]byte(r.URL.Query().Get("tx"))); err != nil {
+		return nil, fmt.Errorf("invalid tx: %v", err)
+	}
+	lookup := rawdb.ReadTxLookupEntry(h.db, hash)
+	if lookup == nil {
+		return nil, fmt.Errorf("%w: transaction %x", errNotFound, hash)
+	}
+	blockHash := h.chain.GetCanonicalHash(*lookup)
+	header := h.chain.GetHeaderByHash(blockHash)
+	receipts := h.chain.GetReceiptsByHash(blockHash)
+	if header == nil || receipts == nil {
+		return nil, fmt.Errorf("%w: block %d", errNotFound, *lookup)
+	}
+	index := -1
+	for i, receipt := range receipts {
+		if receipt.TxHash == hash {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("%w: receipt of transaction %x", errNotFound, hash)
+	}
+	proof, value, err := proveReceipt(receipts, index)
+	if err != nil {
+		return nil, err
+	}
+	return &ReceiptProof{
+		BlockHash:   blockHash,
+		BlockNumber: hexutil.Uint64(*lookup),
+		ReceiptRoot: header.ReceiptHash,
+		Index:       hexutil.Uint(index),
+		Receipt:     value,
+		Proof:       proof,
+	}, nil
+}
+
+// batch serves a finalized batch, the last one if no index is given.
+func (h *handler) batch(r *http.Request) (interface{}, error) {
+	var index uint64
+	if param := r.URL.Query().Get("index"); param != "" {
+		var err error
+		if index, err = strconv.ParseUint(param, 0, 64); err != nil {
+			return nil, fmt.Errorf("invalid index: %v", err)
+		}
+	} else {
+		last := rawdb.ReadLastRollupBatch(h.db)
+		if last == nil {
+			return nil, fmt.Errorf("%w: no finalized batch", errNotFound)
+		}
+		index = *last
+	}
+	batch := rawdb.ReadRollupBatch(h.db, index)
+	if batch == nil {
+		return nil, fmt.Errorf("%w: batch %d", errNotFound, index)
+	}
+	header := h.chain.GetHeaderByNumber(batch.L2Block)
+	if header == nil {
+		return nil, fmt.Errorf("%w: block %d", errNotFound, batch.L2Block)
+	}
+	return &BatchProof{
+		Index:        hexutil.Uint64(batch.Index),
+		StateRoot:    batch.StateRoot,
+		WithdrawRoot: batch.WithdrawRoot,
+		L1Block:      hexutil.Uint64(batch.L1Block),
+		L1Hash:       batch.L1Hash,
+		Header:       header,
+	}, nil
+}
+
+// proofList collects the nodes of a proof in order.
+type proofList []hexutil.Bytes
+
+func (l *proofList) Put(key []byte, value []byte) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func (l *proofList) Delete(key []byte) error {
+	panic("not supported")
+}
+
+// proveReceipt rebuilds the receipt trie of a block, returning the proof of the
+// receipt at the given index and its consensus encoding.
+func proveReceipt(receipts types.Receipts, index int) ([]hexutil.Bytes, hexutil.Bytes, error) {
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		value hexutil.Bytes
+		buf   = new(bytes.Buffer)
+	)
+	for i := range receipts {
+		buf.Reset()
+		receipts.EncodeIndex(i, buf)
+		tr.Update(rlp.AppendUint64(nil, uint64(i)), common.CopyBytes(buf.Bytes()))
+		if i == index {
+			value = common.CopyBytes(buf.Bytes())
+		}
+	}
+	var proof proofList
+	if err := tr.Prove(rlp.AppendUint64(nil, uint64(index)), 0, &proof); err != nil {
+		return nil, nil, err
+	}
+	return proof, value, nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package lightproof
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethdb/memorydb"
+	"github.com/scroll-tech/go-ethereum/internal/ethapi"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+// testProver records the state proofs requested.
+type testProver struct {
+	address common.Address
+	slots   []string
+}
+
+func (p *testProver) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*ethapi.AccountResult, error) {
+	p.address, p.slots = address, storageKeys
+	return &ethapi.AccountResult{Address: address}, nil
+}
+
+// get queries an endpoint, decoding its result into res and returning the status.
+func get(t *testing.T, server *httptest.Server, path string, res interface{}) int {
+	t.Helper()
+
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatalf("failed to query %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+			t.Fatalf("failed to decode %s: %v", path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestLightProofs(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &core.Genesis{Config: params.TestChainConfig, Alloc: core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(params.TestChainConfig)
+	)
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 4, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0xaa})
+		for j := 0; j < 3; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{0x01}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+			b.AddTx(tx)
+		}
+	})
+	chain, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	var (
+		prover = new(testProver)
+		h      = &handler{chain: chain, db: db, engine: ethash.NewFaker(), prover: prover}
+		server = httptest.NewServer(h.mux())
+	)
+	defer server.Close()
+
+	// Headers are served with their signer, up to the head
+	var headers []*Header
+	if status := get(t, server, "/light/v1/headers?from=3&count=5", &headers); status != http.StatusOK {
+		t.Fatalf("headers status mismatch: have %d, want %d", status, http.StatusOK)
+	}
+	if len(headers) != 2 || headers[0].Hash != blocks[2].Hash() || headers[1].Header.Hash() != blocks[3].Hash() {
+		t.Fatalf("headers mismatch: %v", headers)
+	}
+	if headers[0].Signer != (common.Address{0xaa}) {
+		t.Errorf("signer mismatch: have %x, want %x", headers[0].Signer, common.Address{0xaa})
+	}
+	if status := get(t, server, "/light/v1/headers?from=5&count=1", &headers); status != http.StatusNotFound {
+		t.Errorf("missing headers status mismatch: have %d, want %d", status, http.StatusNotFound)
+	}
+	// Receipts are served with a proof against the receipt root
+	tx := blocks[1].Transactions()[1]
+	var proof ReceiptProof
+	if status := get(t, server, fmt.Sprintf("/light/v1/receipt?tx=%s", tx.Hash().Hex()), &proof); status != http.StatusOK {
+		t.Fatalf("receipt status mismatch: have %d, want %d", status, http.StatusOK)
+	}
+	if proof.ReceiptRoot != blocks[1].ReceiptHash() || proof.Index != 1 {
+		t.Fatalf("receipt proof mismatch: root %x, index %d", proof.ReceiptRoot, proof.Index)
+	}
+	nodes := memorydb.New()
+	for _, node := range proof.Proof {
+		nodes.Put(crypto.Keccak256(node), node)
+	}
+	value, err := trie.VerifyProof(proof.ReceiptRoot, rlp.AppendUint64(nil, uint64(proof.Index)), nodes)
+	if err != nil {
+		t.Fatalf("invalid receipt proof: %v", err)
+	}
+	if !bytes.Equal(value, proof.Receipt) {
+		t.Fatalf("proven receipt mismatch: have %x, want %x", value, proof.Receipt)
+	}
+	receipt := new(types.Receipt)
+	if err := receipt.UnmarshalBinary(proof.Receipt); err != nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("invalid receipt: %v", err)
+	}
+	// State proofs are delegated to the prover
+	var account ethapi.AccountResult
+	if status := get(t, server, fmt.Sprintf("/light/v1/state?block=2&address=%s&slot=0x01&slot=0x02", addr.Hex()), &account); status != http.StatusOK {
+		t.Fatalf("state status mismatch: have %d, want %d", status, http.StatusOK)
+	}
+	if prover.address != addr || len(prover.slots) != 2 {
+		t.Fatalf("proof request mismatch: %x %v", prover.address, prover.slots)
+	}
+	// Finalized batches are served with the header of their last block
+	var batch BatchProof
+	if status := get(t, server, "/light/v1/batch", &batch); status != http.StatusNotFound {
+		t.Fatalf("missing batch status mismatch: have %d, want %d", status, http.StatusNotFound)
+	}
+	rawdb.WriteRollupBatch(db, &types.RollupBatch{Index: 7, StateRoot: blocks[2].Root(), L2Block: 3, L1Block: 100})
+	rawdb.WriteLastRollupBatch(db, 7)
+	if status := get(t, server, "/light/v1/batch", &batch); status != http.StatusOK {
+		t.Fatalf("batch status mismatch: have %d, want %d", status, http.StatusOK)
+	}
+	if batch.Index != 7 || batch.L1Block != hexutil.Uint64(100) || batch.Header.Hash() != blocks[2].Hash() || batch.Header.Root != batch.StateRoot {
+		t.Fatalf("batch mismatch: %+v", batch)
+	}
+}
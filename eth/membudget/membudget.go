@@ -0,0 +1,198 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package membudget sizes the memory hungry components of a node against a
+// single total limit and applies backpressure as the limit nears.
+//
+// At startup, Allocate splits the budget between the caches (divided further by
+// the --cache.* percentages) and the transaction pool. At runtime, a Monitor
+// samples the memory in use: under pressure it shrinks the caches that can give
+// memory back, and close to the limit it rejects block assembly until usage
+// drops again.
+package membudget
+
+import (
+	"errors"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+const (
+	// cacheShare is the percentage of the budget allocated to the database,
+	// trie and snapshot caches.
+	cacheShare = 60
+
+	// txPoolShare is the percentage of the budget allocated to the transactions
+	// of the pool. The rest is left for block processing, RPC and the runtime.
+	txPoolShare = 10
+
+	// txSlotSize is the size of a transaction pool slot, see core.txSlotSize.
+	txSlotSize = 32 * 1024
+
+	// shrinkRatio is the ratio of the budget in use above which the caches are
+	// shrunk.
+	shrinkRatio = 0.85
+
+	// rejectRatio is the ratio of the budget in use above which block assembly
+	// is rejected.
+	rejectRatio = 0.95
+
+	// sampleInterval is the time between two samples of the memory in use.
+	sampleInterval = 3 * time.Second
+)
+
+var (
+	usageGauge    = metrics.NewRegisteredGauge("membudget/usage", nil)
+	shrinkMeter   = metrics.NewRegisteredMeter("membudget/shrink", nil)
+	rejectedMeter = metrics.NewRegisteredMeter("membudget/rejected", nil)
+)
+
+// ErrMemoryPressure is returned by Admit while the memory in use is close to the
+// budget.
+var ErrMemoryPressure = errors.New("memory budget nearly exhausted")
+
+// Allocation is the static split of a memory budget.
+type Allocation struct {
+	Cache       int    // Megabytes of caches, as with --cache
+	TxPoolSlots uint64 // Executable transaction slots of the pool
+	TxPoolQueue uint64 // Non-executable transaction slots of the pool
+}
+
+// Allocate splits a memory budget in megabytes between the caches and the
+// transaction pool.
+func Allocate(budget int) Allocation {
+	slots := uint64(budget) * 1024 * 1024 * txPoolShare / 100 / txSlotSize
+	return Allocation{
+		Cache:       budget * cacheShare / 100,
+		TxPoolSlots: slots * 4 / 5,
+		TxPoolQueue: slots / 5,
+	}
+}
+
+// Monitor keeps the memory in use of a node within its budget.
+type Monitor struct {
+	limit   uint64        // Budget in bytes
+	offheap uint64        // Bytes allocated outside of the Go heap (e.g. fastcache)
+	usage   func() uint64 // Memory in use as seen by the runtime
+
+	shrinkers []func() // Callbacks giving cache memory back
+	reject    int32    // Whether block assembly is rejected (atomic)
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewMonitor creates a monitor for the given budget in megabytes, of which the
+// given amount of megabytes is statically allocated outside of the Go heap.
+func NewMonitor(budget int, offheap int) *Monitor {
+	return &Monitor{
+		limit:   uint64(budget) * 1024 * 1024,
+		offheap: uint64(offheap) * 1024 * 1024,
+		usage:   runtimeUsage,
+		quit:    make(chan struct{}),
+	}
+}
+
+// runtimeUsage returns the memory obtained from the OS by the Go runtime and not
+// yet returned to it.
+func runtimeUsage() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.Sys - stats.HeapReleased
+}
+
+// OnPressure registers a callback invoked to shrink caches while the memory in
+// use exceeds the shrink threshold. It must be called before Start.
+func (m *Monitor) OnPressure(shrink func()) {
+	m.shrinkers = append(m.shrinkers, shrink)
+}
+
+// Admit returns ErrMemoryPressure if new work should be rejected because the
+// memory in use is close to the budget. A nil monitor admits everything.
+func (m *Monitor) Admit() error {
+	if m == nil || atomic.LoadInt32(&m.reject) == 0 {
+		return nil
+	}
+	rejectedMeter.Mark(1)
+	return ErrMemoryPressure
+}
+
+// Start implements node.Lifecycle, starting the memory sampling.
+func (m *Monitor) Start() error {
+	m.wg.Add(1)
+	go m.loop()
+
+	log.Info("Started memory budget monitor", "budget", m.limit/1024/1024)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the memory sampling.
+func (m *Monitor) Stop() error {
+	close(m.quit)
+	m.wg.Wait()
+	return nil
+}
+
+func (m *Monitor) loop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(sampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.check()
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// check samples the memory in use, shrinking caches and toggling the rejection
+// of new work depending on how close it is to the budget.
+func (m *Monitor) check() {
+	used := m.usage() + m.offheap
+	usageGauge.Update(int64(used))
+
+	if float64(used) >= shrinkRatio*float64(m.limit) {
+		log.Warn("Memory budget under pressure, shrinking caches", "used", used/1024/1024, "budget", m.limit/1024/1024)
+		for _, shrink := range m.shrinkers {
+			shrink()
+		}
+		debug.FreeOSMemory()
+		shrinkMeter.Mark(1)
+
+		used = m.usage() + m.offheap
+		usageGauge.Update(int64(used))
+	}
+	reject := float64(used) >= rejectRatio*float64(m.limit)
+	if reject != (atomic.LoadInt32(&m.reject) == 1) {
+		if reject {
+			log.Warn("Memory budget nearly exhausted, rejecting block assembly", "used", used/1024/1024, "budget", m.limit/1024/1024)
+			atomic.StoreInt32(&m.reject, 1)
+		} else {
+			log.Info("Memory budget recovered, accepting block assembly", "used", used/1024/1024, "budget", m.limit/1024/1024)
+			atomic.StoreInt32(&m.reject, 0)
+		}
+	}
+}
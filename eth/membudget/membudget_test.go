@@ -0,0 +1,71 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package membudget
+
+import "testing"
+
+func TestAllocate(t *testing.T) {
+	alloc := Allocate(4096)
+	if alloc.Cache != 2457 {
+		t.Errorf("cache mismatch: have %d, want 2457", alloc.Cache)
+	}
+	// 10% of 4GB is 409.6MB, or 13107 slots of 32KB
+	if alloc.TxPoolSlots != 10485 || alloc.TxPoolQueue != 2621 {
+		t.Errorf("txpool mismatch: have %d/%d, want 10485/2621", alloc.TxPoolSlots, alloc.TxPoolQueue)
+	}
+}
+
+func TestMonitorBackpressure(t *testing.T) {
+	var (
+		used    uint64
+		shrinks int
+	)
+	m := NewMonitor(100, 10)
+	m.usage = func() uint64 { return used }
+	m.OnPressure(func() {
+		shrinks++
+		used -= used / 10
+	})
+	// Below the shrink threshold nothing happens
+	used = 70 << 20
+	m.check()
+	if shrinks != 0 || m.Admit() != nil {
+		t.Fatalf("idle monitor acted: shrinks %d, admit %v", shrinks, m.Admit())
+	}
+	// Above it caches get shrunk, but work is still admitted
+	used = 80 << 20
+	m.check()
+	if shrinks != 1 || m.Admit() != nil {
+		t.Fatalf("pressured monitor mismatch: shrinks %d, admit %v", shrinks, m.Admit())
+	}
+	// Near the limit, work is rejected if shrinking does not help enough
+	used = 100 << 20
+	m.check()
+	if shrinks != 2 || m.Admit() != ErrMemoryPressure {
+		t.Fatalf("exhausted monitor mismatch: shrinks %d, admit %v", shrinks, m.Admit())
+	}
+	// Work is admitted again once the usage drops
+	used = 50 << 20
+	m.check()
+	if m.Admit() != nil {
+		t.Fatalf("recovered monitor rejected work: %v", m.Admit())
+	}
+	// Nil monitors admit everything
+	if err := (*Monitor)(nil).Admit(); err != nil {
+		t.Fatalf("nil monitor rejected work: %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package replica implements the block ingestion of read replicas.
+//
+// A replica follows the block feed of a sequencer ("scroll_subscribe" of
+// "blocks") and imports the streamed blocks as its only source of chain data,
+// scaling RPC capacity horizontally without taking part in block production.
+// Gaps in the stream are backfilled by resubscribing from the local head.
+package replica
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+const (
+	// blockChanSize is the size of the channel receiving streamed blocks.
+	blockChanSize = 1024
+
+	// maxImportBatch is the maximum number of consecutive streamed blocks
+	// imported at once.
+	maxImportBatch = 256
+
+	// reconnectInterval is the time to wait before reconnecting to the
+	// sequencer after the block feed failed.
+	reconnectInterval = 5 * time.Second
+
+	// dialTimeout is the timeout of establishing the block feed.
+	dialTimeout = 10 * time.Second
+)
+
+var (
+	importedMeter  = metrics.NewRegisteredMeter("replica/imported", nil)
+	gapMeter       = metrics.NewRegisteredMeter("replica/gap", nil)
+	reconnectMeter = metrics.NewRegisteredMeter("replica/reconnect", nil)
+	headGauge      = metrics.NewRegisteredGauge("replica/head", nil)
+)
+
+// errGap is returned when the block feed skipped blocks, requiring a backfill.
+var errGap = errors.New("gap in block feed")
+
+// blockChain is the view of the local chain needed to import blocks.
+type blockChain interface {
+	CurrentBlock() *types.Block
+	InsertChain(chain types.Blocks) (int, error)
+}
+
+// Replica imports the blocks streamed by a sequencer into the local chain.
+type Replica struct {
+	url   string
+	chain blockChain
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a replica following the sequencer at the given websocket endpoint
+// and registers its lifecycle with the node.
+func New(stack *node.Node, chain blockChain, url string) *Replica {
+	r := &Replica{
+		url:   url,
+		chain: chain,
+		quit:  make(chan struct{}),
+	}
+	stack.RegisterLifecycle(r)
+	return r
+}
+
+// Start implements node.Lifecycle, starting the block ingestion.
+func (r *Replica) Start() error {
+	r.wg.Add(1)
+	go r.loop()
+
+	log.Info("Started read replica", "sequencer", r.url)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the block ingestion.
+func (r *Replica) Stop() error {
+	close(r.quit)
+	r.wg.Wait()
+
+	log.Info("Stopped read replica")
+	return nil
+}
+
+// loop keeps following the block feed until termination, resubscribing from
+// the local head whenever the feed fails or skips blocks.
+func (r *Replica) loop() {
+	defer r.wg.Done()
+
+	for {
+		err := r.follow()
+		switch {
+		case err == nil:
+			return
+		case errors.Is(err, errGap):
+			// Backfill right away by resubscribing from the local head
+			gapMeter.Mark(1)
+			log.Warn("Backfilling gap in block feed", "err", err)
+			continue
+		}
+		reconnectMeter.Mark(1)
+		log.Warn("Block feed failed", "sequencer", r.url, "err", err)
+
+		select {
+		case <-time.After(reconnectInterval):
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// follow subscribes to the block feed from the block following the local head
+// and imports the streamed blocks until the feed fails or the replica is
+// stopped, in which case it returns nil.
+func (r *Replica) follow() error {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	client, err := rpc.DialContext(ctx, r.url)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	from := r.chain.CurrentBlock().NumberU64() + 1
+	blobs := make(chan hexutil.Bytes, blockChanSize)
+	sub, err := client.Subscribe(ctx, "scroll", blobs, "blocks", hexutil.Uint64(from))
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	log.Info("Following sequencer block feed", "sequencer", r.url, "from", from)
+	for {
+		select {
+		case blob := <-blobs:
+			// Import all consecutive blocks already received in one batch
+			batch := []hexutil.Bytes{blob}
+		drain:
+			for len(batch) < maxImportBatch {
+				select {
+				case blob := <-blobs:
+					batch = append(batch, blob)
+				default:
+					break drain
+				}
+			}
+			if err := r.importBlocks(batch); err != nil {
+				return err
+			}
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		case <-r.quit:
+			return nil
+		}
+	}
+}
+
+// importBlocks decodes and imports a batch of streamed blocks. Each run of
+// consecutive blocks must continue the local chain, or replace part of it after
+// a sequencer reorg.
+func (r *Replica) importBlocks(blobs []hexutil.Bytes) error {
+	var run types.Blocks
+	for _, blob := range blobs {
+		block := new(types.Block)
+		if err := rlp.DecodeBytes(blob, block); err != nil {
+			return fmt.Errorf("invalid streamed block: %v", err)
+		}
+		if n := len(run); n > 0 && block.NumberU64() != run[n-1].NumberU64()+1 {
+			if err := r.insert(run); err != nil {
+				return err
+			}
+			run = nil
+		}
+		run = append(run, block)
+	}
+	return r.insert(run)
+}
+
+// insert imports a run of consecutive blocks into the local chain.
+func (r *Replica) insert(blocks types.Blocks) error {
+	if head := r.chain.CurrentBlock().NumberU64(); blocks[0].NumberU64() > head+1 {
+		return fmt.Errorf("%w: block %d after local head %d", errGap, blocks[0].NumberU64(), head)
+	}
+	if _, err := r.chain.InsertChain(blocks); err != nil {
+		return fmt.Errorf("failed to import streamed blocks: %v", err)
+	}
+	importedMeter.Mark(int64(len(blocks)))
+	headGauge.Update(int64(r.chain.CurrentBlock().NumberU64()))
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package replica
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// testFeed is a block feed dropping a block from the first subscription only.
+type testFeed struct {
+	blocks []*types.Block // Blocks by number, excluding the genesis
+	drop   uint64         // Block number missing from the first subscription
+
+	lock sync.Mutex
+	subs []uint64 // Starting block of each subscription
+}
+
+func (f *testFeed) Blocks(ctx context.Context, from hexutil.Uint64) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	rpcSub := notifier.CreateSubscription()
+
+	f.lock.Lock()
+	f.subs = append(f.subs, uint64(from))
+	first := len(f.subs) == 1
+	f.lock.Unlock()
+
+	go func() {
+		for _, block := range f.blocks {
+			if block.NumberU64() < uint64(from) || (first && block.NumberU64() == f.drop) {
+				continue
+			}
+			blob, _ := rlp.EncodeToBytes(block)
+			if err := notifier.Notify(rpcSub.ID, hexutil.Bytes(blob)); err != nil {
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+func newTestChain(t *testing.T, gspec *core.Genesis) *core.BlockChain {
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	return chain
+}
+
+func TestReplicaBackfillsGaps(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Scroll.MaxTxPerBlock = nil
+	gspec := &core.Genesis{Config: &config}
+	genesis := gspec.MustCommit(rawdb.NewMemoryDatabase())
+	blocks, _ := core.GenerateChain(&config, genesis, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 32, nil)
+
+	feed := &testFeed{blocks: blocks, drop: 10}
+	server := rpc.NewServer()
+	if err := server.RegisterName("scroll", feed); err != nil {
+		t.Fatalf("failed to register block feed: %v", err)
+	}
+	defer server.Stop()
+	http := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer http.Close()
+
+	chain := newTestChain(t, gspec)
+	defer chain.Stop()
+
+	replica := &Replica{url: "ws://" + strings.TrimPrefix(http.URL, "http://"), chain: chain, quit: make(chan struct{})}
+	replica.Start()
+	defer replica.Stop()
+
+	for deadline := time.Now().Add(5 * time.Second); chain.CurrentBlock().NumberU64() < 32; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("replica head mismatch: have %d, want 32", chain.CurrentBlock().NumberU64())
+		}
+	}
+	if head := chain.CurrentBlock(); head.Hash() != blocks[31].Hash() {
+		t.Fatalf("replica head hash mismatch: have %x, want %x", head.Hash(), blocks[31].Hash())
+	}
+	// The first subscription stalled at the gap, the second backfilled it
+	feed.lock.Lock()
+	defer feed.lock.Unlock()
+	if len(feed.subs) != 2 || feed.subs[0] != 1 || feed.subs[1] != 10 {
+		t.Fatalf("subscriptions mismatch: have %v, want [1 10]", feed.subs)
+	}
+}
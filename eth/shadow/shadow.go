@@ -0,0 +1,323 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package shadow implements a differential verification service that compares
+// every block committed by the local node against a reference node.
+//
+// It is meant for canary deployments: a node running a new release follows the
+// chain next to a trusted one, and any divergence in state root, receipts or
+// block contents is reported (and optionally halts the node) as soon as it is
+// observed, instead of surfacing later as a consensus split.
+package shadow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+const (
+	// chainEventChanSize is the size of channel listening to ChainEvent.
+	chainEventChanSize = 256
+
+	// maxPendingBlocks is the number of committed blocks queued for verification
+	// before new ones are skipped.
+	maxPendingBlocks = 1024
+
+	// retryInterval is the time to wait before asking the reference node again
+	// about a block it does not know yet, or after a failed request.
+	retryInterval = 3 * time.Second
+
+	// requestTimeout is the timeout of a single request to the reference node.
+	requestTimeout = 10 * time.Second
+)
+
+var (
+	verifiedMeter = metrics.NewRegisteredMeter("shadow/verified", nil)
+	divergedMeter = metrics.NewRegisteredMeter("shadow/diverged", nil)
+	skippedMeter  = metrics.NewRegisteredMeter("shadow/skipped", nil)
+	failureMeter  = metrics.NewRegisteredMeter("shadow/failure", nil)
+	pendingGauge  = metrics.NewRegisteredGauge("shadow/pending", nil)
+	verifiedGauge = metrics.NewRegisteredGauge("shadow/head", nil)
+)
+
+// errShadowClosed is returned by pending verifications when the service stops.
+var errShadowClosed = errors.New("shadow verifier closed")
+
+// Config contains the settings of the shadow verifier.
+type Config struct {
+	URL  string `toml:",omitempty"` // RPC endpoint of the reference node
+	Halt bool   `toml:",omitempty"` // Whether to shut the node down on divergence
+}
+
+// backend encompasses the functionality needed to verify local blocks.
+type backend interface {
+	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
+	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error)
+	GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error)
+}
+
+// reference is the view of the reference node used for comparison, satisfied
+// by ethclient.Client.
+type reference interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// Service compares committed blocks with a reference node in the background.
+type Service struct {
+	config  Config
+	backend backend
+	ref     reference
+	client  *rpc.Client
+	halt    func() // Invoked once on divergence if halting is enabled
+
+	sub  event.Subscription
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a shadow verifier and registers it with the node.
+func New(stack *node.Node, backend backend, config Config) error {
+	if config.URL == "" {
+		return errors.New("missing reference node URL")
+	}
+	s := &Service{
+		config:  config,
+		backend: backend,
+		halt: func() {
+			// Closing the node stops this service too, so it must not block
+			go stack.Close()
+		},
+		quit: make(chan struct{}),
+	}
+	stack.RegisterLifecycle(s)
+	return nil
+}
+
+// Start implements node.Lifecycle, connecting to the reference node and
+// starting the verification loop.
+func (s *Service) Start() error {
+	client, err := rpc.Dial(s.config.URL)
+	if err != nil {
+		return fmt.Errorf("failed to dial shadow reference node: %v", err)
+	}
+	s.client, s.ref = client, ethclient.NewClient(client)
+
+	events := make(chan core.ChainEvent, chainEventChanSize)
+	s.sub = s.backend.SubscribeChainEvent(events)
+
+	s.wg.Add(1)
+	go s.loop(events)
+
+	log.Info("Started shadow verifier", "reference", s.config.URL, "halt", s.config.Halt)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the verification loop.
+func (s *Service) Stop() error {
+	s.sub.Unsubscribe()
+	close(s.quit)
+	s.wg.Wait()
+	s.client.Close()
+
+	log.Info("Stopped shadow verifier")
+	return nil
+}
+
+// loop queues committed blocks and verifies them one by one. Chain events are
+// drained in a separate goroutine so that a slow reference node never stalls
+// block insertion; blocks beyond the queue limit are skipped instead.
+func (s *Service) loop(events chan core.ChainEvent) {
+	defer s.wg.Done()
+
+	queue := make(chan *types.Block, maxPendingBlocks)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			select {
+			case ev := <-events:
+				select {
+				case queue <- ev.Block:
+					pendingGauge.Update(int64(len(queue)))
+				default:
+					skippedMeter.Mark(1)
+					log.Warn("Shadow verification queue full, skipping block", "number", ev.Block.Number(), "hash", ev.Block.Hash())
+				}
+			case <-s.sub.Err():
+				return
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case block := <-queue:
+			pendingGauge.Update(int64(len(queue)))
+			diverged, err := s.verify(block)
+			if err != nil {
+				// Only returned on shutdown
+				return
+			}
+			if diverged && s.config.Halt {
+				log.Error("Halting node after shadow verification divergence", "number", block.Number(), "hash", block.Hash())
+				s.halt()
+				return
+			}
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// verify compares a locally committed block with the same height on the
+// reference node, waiting for the reference to catch up if necessary. It
+// reports whether the two diverge.
+func (s *Service) verify(block *types.Block) (bool, error) {
+	remote, err := s.referenceHeader(block.Number())
+	if err != nil {
+		return false, err
+	}
+	// Blocks reorged out locally since being committed are of no interest
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	local, err := s.backend.HeaderByNumber(ctx, rpc.BlockNumber(block.NumberU64()))
+	cancel()
+	if err == nil && local != nil && local.Hash() != block.Hash() {
+		log.Debug("Skipping shadow verification of reorged block", "number", block.Number(), "hash", block.Hash())
+		return false, nil
+	}
+	if remote.Hash() == block.Hash() {
+		verifiedMeter.Mark(1)
+		verifiedGauge.Update(int64(block.NumberU64()))
+		log.Trace("Shadow verified block", "number", block.Number(), "hash", block.Hash())
+		return false, nil
+	}
+	divergedMeter.Mark(1)
+
+	context := []interface{}{
+		"number", block.Number(), "local", block.Hash(), "reference", remote.Hash(),
+	}
+	context = append(context, diffHeaders(block.Header(), remote)...)
+	if block.ReceiptHash() != remote.ReceiptHash {
+		if index, reason := s.diffReceipts(block); index >= 0 {
+			context = append(context, "txindex", index, "txhash", block.Transactions()[index].Hash(), "receipt", reason)
+		}
+	}
+	log.Error("Shadow verification detected divergence", context...)
+	return true, nil
+}
+
+// referenceHeader retrieves the header at the given height from the reference
+// node, retrying until it becomes available or the service is stopped.
+func (s *Service) referenceHeader(number *big.Int) (*types.Header, error) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		header, err := s.ref.HeaderByNumber(ctx, number)
+		cancel()
+
+		switch {
+		case err == nil:
+			return header, nil
+		case errors.Is(err, ethereum.NotFound):
+			log.Debug("Reference node behind, waiting", "number", number)
+		default:
+			failureMeter.Mark(1)
+			log.Warn("Failed to retrieve reference header", "number", number, "err", err)
+		}
+		select {
+		case <-time.After(retryInterval):
+		case <-s.quit:
+			return nil, errShadowClosed
+		}
+	}
+}
+
+// diffReceipts locates the first transaction whose receipt differs between the
+// local and the reference node, returning its index and the differing field.
+// The index is -1 if no differing receipt could be found.
+func (s *Service) diffReceipts(block *types.Block) (int, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	locals, err := s.backend.GetReceipts(ctx, block.Hash())
+	if err != nil || len(locals) != len(block.Transactions()) {
+		log.Warn("Failed to retrieve local receipts", "number", block.Number(), "hash", block.Hash(), "err", err)
+		return -1, ""
+	}
+	for i, tx := range block.Transactions() {
+		remote, err := s.ref.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			if errors.Is(err, ethereum.NotFound) {
+				return i, "missing"
+			}
+			log.Warn("Failed to retrieve reference receipt", "tx", tx.Hash(), "err", err)
+			return -1, ""
+		}
+		local := locals[i]
+		switch {
+		case local.Status != remote.Status:
+			return i, fmt.Sprintf("status %d != %d", local.Status, remote.Status)
+		case local.CumulativeGasUsed != remote.CumulativeGasUsed:
+			return i, fmt.Sprintf("cumulative gas %d != %d", local.CumulativeGasUsed, remote.CumulativeGasUsed)
+		case len(local.Logs) != len(remote.Logs):
+			return i, fmt.Sprintf("logs %d != %d", len(local.Logs), len(remote.Logs))
+		case local.Bloom != remote.Bloom:
+			return i, "bloom"
+		}
+	}
+	return -1, ""
+}
+
+// diffHeaders returns the log context describing the consensus fields that
+// differ between two headers at the same height.
+func diffHeaders(local, remote *types.Header) []interface{} {
+	var context []interface{}
+	if local.ParentHash != remote.ParentHash {
+		context = append(context, "parent", local.ParentHash, "refparent", remote.ParentHash)
+	}
+	if local.Root != remote.Root {
+		context = append(context, "root", local.Root, "refroot", remote.Root)
+	}
+	if local.ReceiptHash != remote.ReceiptHash {
+		context = append(context, "receipts", local.ReceiptHash, "refreceipts", remote.ReceiptHash)
+	}
+	if local.TxHash != remote.TxHash {
+		context = append(context, "txs", local.TxHash, "reftxs", remote.TxHash)
+	}
+	if local.GasUsed != remote.GasUsed {
+		context = append(context, "gasused", local.GasUsed, "refgasused", remote.GasUsed)
+	}
+	if local.Bloom != remote.Bloom {
+		context = append(context, "bloom", "mismatch")
+	}
+	return context
+}
@@ -0,0 +1,199 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package shadow
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+type testBackend struct {
+	chainFeed event.Feed
+	headers   map[uint64]*types.Header
+	receipts  map[common.Hash]types.Receipts
+}
+
+func (b *testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
+	return b.chainFeed.Subscribe(ch)
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	return b.headers[uint64(number)], nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.receipts[hash], nil
+}
+
+type testReference struct {
+	lock     sync.Mutex
+	headers  map[uint64]*types.Header
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (r *testReference) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if header, ok := r.headers[number.Uint64()]; ok {
+		return header, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (r *testReference) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if receipt, ok := r.receipts[hash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func newTestService() (*Service, *testBackend, *testReference) {
+	backend := &testBackend{headers: make(map[uint64]*types.Header), receipts: make(map[common.Hash]types.Receipts)}
+	ref := &testReference{headers: make(map[uint64]*types.Header), receipts: make(map[common.Hash]*types.Receipt)}
+	return &Service{backend: backend, ref: ref, quit: make(chan struct{})}, backend, ref
+}
+
+// makeBlock creates a block with one transaction per given cumulative gas value.
+func makeBlock(number int64, root common.Hash, gas ...uint64) (*types.Block, types.Receipts) {
+	var (
+		txs      types.Transactions
+		receipts types.Receipts
+	)
+	for i, used := range gas {
+		txs = append(txs, types.NewTransaction(uint64(i), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil))
+		receipts = append(receipts, &types.Receipt{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: used, Logs: []*types.Log{}})
+	}
+	header := &types.Header{Number: big.NewInt(number), Root: root, Difficulty: common.Big1}
+	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil)), receipts
+}
+
+func TestVerifyMatching(t *testing.T) {
+	s, backend, ref := newTestService()
+
+	block, receipts := makeBlock(1, common.Hash{0x01}, 21000, 42000)
+	backend.headers[1], backend.receipts[block.Hash()] = block.Header(), receipts
+	ref.headers[1] = block.Header()
+
+	if diverged, err := s.verify(block); err != nil || diverged {
+		t.Fatalf("matching block: have diverged %v err %v, want no divergence", diverged, err)
+	}
+}
+
+func TestVerifyDivergence(t *testing.T) {
+	s, backend, ref := newTestService()
+
+	block, receipts := makeBlock(1, common.Hash{0x01}, 21000, 42000)
+	backend.headers[1], backend.receipts[block.Hash()] = block.Header(), receipts
+
+	// The reference executed the second transaction differently
+	remote, remoteReceipts := makeBlock(1, common.Hash{0x02}, 21000, 50000)
+	ref.headers[1] = remote.Header()
+	for i, tx := range remote.Transactions() {
+		ref.receipts[tx.Hash()] = remoteReceipts[i]
+	}
+	if diverged, err := s.verify(block); err != nil || !diverged {
+		t.Fatalf("diverging block: have diverged %v err %v, want divergence", diverged, err)
+	}
+	index, reason := s.diffReceipts(block)
+	if index != 1 || reason != "cumulative gas 42000 != 50000" {
+		t.Fatalf("receipt diff mismatch: have %d %q, want 1 %q", index, reason, "cumulative gas 42000 != 50000")
+	}
+	if context := diffHeaders(block.Header(), remote.Header()); len(context) != 8 {
+		t.Fatalf("header diff mismatch: have %v, want root, receipts", context)
+	}
+}
+
+func TestVerifyReorged(t *testing.T) {
+	s, backend, ref := newTestService()
+
+	block, _ := makeBlock(1, common.Hash{0x01}, 21000)
+	canonical, _ := makeBlock(1, common.Hash{0x02}, 21000)
+	backend.headers[1] = canonical.Header()
+	ref.headers[1] = canonical.Header()
+
+	if diverged, err := s.verify(block); err != nil || diverged {
+		t.Fatalf("reorged block: have diverged %v err %v, want no divergence", diverged, err)
+	}
+}
+
+func TestVerifyWaitsForReference(t *testing.T) {
+	s, backend, _ := newTestService()
+
+	block, _ := makeBlock(1, common.Hash{0x01}, 21000)
+	backend.headers[1] = block.Header()
+
+	// The reference is behind, verification must wait and fail only on shutdown
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.verify(block)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("verification finished before reference caught up: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(s.quit)
+	if err := <-done; err != errShadowClosed {
+		t.Fatalf("error mismatch: have %v, want %v", err, errShadowClosed)
+	}
+}
+
+func TestLoopHalts(t *testing.T) {
+	s, backend, ref := newTestService()
+	s.config.Halt = true
+
+	halted := make(chan struct{})
+	s.halt = func() { close(halted) }
+
+	events := make(chan core.ChainEvent, chainEventChanSize)
+	s.sub = backend.SubscribeChainEvent(events)
+	s.wg.Add(1)
+	go s.loop(events)
+
+	good, _ := makeBlock(1, common.Hash{0x01}, 21000)
+	bad, _ := makeBlock(2, common.Hash{0x02}, 21000)
+	remote, _ := makeBlock(2, common.Hash{0x03}, 21000)
+	backend.headers[1], backend.headers[2] = good.Header(), bad.Header()
+
+	ref.lock.Lock()
+	ref.headers[1], ref.headers[2] = good.Header(), remote.Header()
+	ref.lock.Unlock()
+
+	backend.chainFeed.Send(core.ChainEvent{Block: good, Hash: good.Hash()})
+	backend.chainFeed.Send(core.ChainEvent{Block: bad, Hash: bad.Hash()})
+	select {
+	case <-halted:
+	case <-time.After(time.Second):
+		t.Fatalf("node not halted on divergence")
+	}
+	s.sub.Unsubscribe()
+	close(s.quit)
+	s.wg.Wait()
+}
@@ -20,6 +20,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -73,6 +74,8 @@ type Backend interface {
 	BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error)
 	GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error)
 	RPCGasCap() uint64
+	RPCResponseCache() *ethapi.ResponseCache
+	HistoricalState() ethapi.HistoricalStateProvider
 	ChainConfig() *params.ChainConfig
 	CacheConfig() *core.CacheConfig
 	Engine() consensus.Engine
@@ -579,6 +582,11 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	}
 	statedb, err := api.backend.StateAtBlock(ctx, parent, reexec, nil, true, false)
 	if err != nil {
+		if provider := api.backend.HistoricalState(); provider != nil {
+			var results []*txTraceResult
+			err := provider.CallContext(ctx, &results, "debug_traceBlockByHash", block.Hash(), config)
+			return results, err
+		}
 		return nil, err
 	}
 	// Execute all the transaction contained within the block concurrently
@@ -795,6 +803,13 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 	}
 	msg, vmctx, statedb, err := api.backend.StateAtTransaction(ctx, block, int(index), reexec)
 	if err != nil {
+		if provider := api.backend.HistoricalState(); provider != nil {
+			var result json.RawMessage
+			if err := provider.CallContext(ctx, &result, "debug_traceTransaction", hash, config); err != nil {
+				return nil, err
+			}
+			return result, nil
+		}
 		return nil, err
 	}
 	txctx := &Context{
@@ -832,6 +847,13 @@ func (api *API) TraceCall(ctx context.Context, args ethapi.TransactionArgs, bloc
 	}
 	statedb, err := api.backend.StateAtBlock(ctx, block, reexec, nil, true, false)
 	if err != nil {
+		if provider := api.backend.HistoricalState(); provider != nil {
+			var result json.RawMessage
+			if err := provider.CallContext(ctx, &result, "debug_traceCall", args, rpc.BlockNumberOrHashWithHash(block.Hash(), false), config); err != nil {
+				return nil, err
+			}
+			return result, nil
+		}
 		return nil, err
 	}
 	// Apply the customized state rules if required.
@@ -900,8 +922,8 @@ func (api *API) traceTx(ctx context.Context, message core.Message, txctx *Contex
 	vmenv := vm.NewEVM(vmctx, txContext, statedb, api.backend.ChainConfig(), vm.Config{Debug: true, Tracer: tracer, NoBaseFee: true})
 
 	// If gasPrice is 0, make sure that the account has sufficient balance to cover `l1Fee`.
-	if api.backend.ChainConfig().Scroll.FeeVaultEnabled() && message.GasPrice().Cmp(big.NewInt(0)) == 0 {
-		l1Fee, err := fees.CalculateL1MsgFee(message, vmenv.StateDB)
+	if api.backend.ChainConfig().Scroll.FeeVaultEnabled() && !message.IsSystem() && message.GasPrice().Cmp(big.NewInt(0)) == 0 {
+		l1Fee, err := fees.CalculateL1MsgFee(message, vmenv.StateDB, api.backend.ChainConfig().Scroll.IsCompressedL1Fee(vmctx.BlockNumber))
 		if err != nil {
 			return nil, err
 		}
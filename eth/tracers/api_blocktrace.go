@@ -13,6 +13,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/internal/ethapi"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
@@ -23,6 +24,8 @@ import (
 
 type TraceBlock interface {
 	GetBlockTraceByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (trace *types.BlockTrace, err error)
+	GetInternalTransactions(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*TxInternalTransactions, error)
+	GetBlockCommitments(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*BlockCommitments, error)
 }
 
 type traceEnv struct {
@@ -71,6 +74,14 @@ func (api *API) GetBlockTraceByNumberOrHash(ctx context.Context, blockNrOrHash r
 		config.Tracer = nil
 		log.Warn("Tracer params is unsupported")
 	}
+	// Serve the traces of finalized blocks from the response cache if possible
+	var key string
+	if ethapi.IsFinalized(ctx, api.backend, block.NumberU64()) {
+		key = ethapi.ResponseKey("scroll_getBlockTraceByNumberOrHash", block.Hash(), config)
+		if trace, ok := api.backend.RPCResponseCache().Get(key); ok {
+			return trace.(*types.BlockTrace), nil
+		}
+	}
 
 	// create current execution environment.
 	env, err := api.createTraceEnv(ctx, config, block)
@@ -78,7 +89,11 @@ func (api *API) GetBlockTraceByNumberOrHash(ctx context.Context, blockNrOrHash r
 		return nil, err
 	}
 
-	return api.getBlockTrace(block, env)
+	trace, err = api.getBlockTrace(block, env)
+	if err == nil {
+		api.backend.RPCResponseCache().Add(key, trace)
+	}
+	return trace, err
 }
 
 // Make trace environment for current block.
@@ -382,16 +397,18 @@ func (api *API) fillBlockTrace(env *traceEnv, block *types.Block) (*types.BlockT
 		// probably a Contract Call
 		if len(tx.Data()) != 0 && tx.To() != nil {
 			evmTrace.ByteCode = hexutil.Encode(statedb.GetCode(*tx.To()))
-			// Get tx.to address's code hash.
-			codeHash := statedb.GetPoseidonCodeHash(*tx.To())
-			evmTrace.PoseidonCodeHash = &codeHash
+			// Get tx.to address's code hashes.
+			poseidonCodeHash := statedb.GetPoseidonCodeHash(*tx.To())
+			evmTrace.PoseidonCodeHash = &poseidonCodeHash
+			keccakCodeHash := statedb.GetKeccakCodeHash(*tx.To())
+			evmTrace.KeccakCodeHash = &keccakCodeHash
 		} else if tx.To() == nil { // Contract is created.
 			evmTrace.ByteCode = hexutil.Encode(tx.Data())
 		}
 	}
 
 	// only zktrie model has the ability to get `mptwitness`.
-	if api.backend.ChainConfig().Scroll.ZktrieEnabled() {
+	if statedb.Commitment() == state.ZktrieCommitment {
 		if err := zkproof.FillBlockTraceForMPTWitness(zkproof.MPTWitnessType(api.backend.CacheConfig().MPTWitness), blockTrace); err != nil {
 			log.Error("fill mpt witness fail", "error", err)
 		}
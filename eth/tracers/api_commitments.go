@@ -0,0 +1,102 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rollup/withdrawtrie"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// BlockCommitments are the commitments of a block consumed by the prover circuit.
+type BlockCommitments struct {
+	Number        hexutil.Uint64 `json:"number"`
+	Hash          common.Hash    `json:"hash"`
+	PrevStateRoot common.Hash    `json:"prevStateRoot"`
+	PostStateRoot common.Hash    `json:"postStateRoot"`
+	WithdrawRoot  common.Hash    `json:"withdrawRoot"`
+	// TxDataHash is the keccak hash of the concatenated canonical encodings of
+	// all transactions of the block.
+	TxDataHash common.Hash `json:"txDataHash"`
+	// L1MessageHashes are the hashes of the L1 messages included in the block.
+	L1MessageHashes []common.Hash `json:"l1MessageHashes"`
+	// BlockContext is the block context in the layout of types.EncodeBlockContext.
+	BlockContext hexutil.Bytes `json:"blockContext"`
+}
+
+// GetBlockCommitments returns all circuit relevant commitments of the given
+// block in the byte layout the prover circuit consumes.
+func (api *API) GetBlockCommitments(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*BlockCommitments, error) {
+	var (
+		block *types.Block
+		err   error
+	)
+	if number, ok := blockNrOrHash.Number(); ok {
+		block, err = api.blockByNumber(ctx, number)
+	}
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block, err = api.blockByHash(ctx, hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New("block not found")
+	}
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis has no commitments")
+	}
+	txs := block.Transactions()
+	if len(txs) > math.MaxUint16 {
+		return nil, fmt.Errorf("too many transactions for block context: %d", len(txs))
+	}
+	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := api.backend.StateAtBlock(ctx, block, defaultTraceReexec, nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	for _, tx := range txs {
+		enc, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, enc...)
+	}
+	return &BlockCommitments{
+		Number:          hexutil.Uint64(block.NumberU64()),
+		Hash:            block.Hash(),
+		PrevStateRoot:   parent.Root(),
+		PostStateRoot:   block.Root(),
+		WithdrawRoot:    withdrawtrie.ReadWTRSlot(rcfg.L2MessageQueueAddress, statedb),
+		TxDataHash:      crypto.Keccak256Hash(data),
+		L1MessageHashes: []common.Hash{},
+		BlockContext:    types.EncodeBlockContext(block.Header(), uint16(len(txs)), 0),
+	}, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+func TestGetBlockCommitments(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		accounts[1].addr: {Balance: big.NewInt(params.Ether)},
+	}}
+	var txs []*types.Transaction
+	signer := types.HomesteadSigner{}
+	backend := newTestBackend(t, 2, genesis, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+		txs = append(txs, tx)
+	})
+	api := NewAPI(backend)
+
+	result, err := api.GetBlockCommitments(context.Background(), rpc.BlockNumberOrHashWithNumber(2))
+	if err != nil {
+		t.Fatalf("failed to retrieve block commitments: %v", err)
+	}
+	block, parent := backend.chain.GetBlockByNumber(2), backend.chain.GetBlockByNumber(1)
+	if result.Hash != block.Hash() || uint64(result.Number) != 2 {
+		t.Errorf("block mismatch: have %d %x, want 2 %x", result.Number, result.Hash, block.Hash())
+	}
+	if result.PrevStateRoot != parent.Root() || result.PostStateRoot != block.Root() {
+		t.Errorf("state roots mismatch: have %x -> %x, want %x -> %x", result.PrevStateRoot, result.PostStateRoot, parent.Root(), block.Root())
+	}
+	enc, _ := txs[1].MarshalBinary()
+	if result.TxDataHash != crypto.Keccak256Hash(enc) {
+		t.Errorf("tx data hash mismatch: have %x, want %x", result.TxDataHash, crypto.Keccak256Hash(enc))
+	}
+	if want := types.EncodeBlockContext(block.Header(), 1, 0); !bytes.Equal(result.BlockContext, want) {
+		t.Errorf("block context mismatch: have %x, want %x", result.BlockContext, want)
+	}
+	if len(result.L1MessageHashes) != 0 {
+		t.Errorf("unexpected L1 messages: %v", result.L1MessageHashes)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// TxInternalTransactions holds the internal transactions executed by a single
+// transaction, starting with its top level call.
+type TxInternalTransactions struct {
+	TxHash common.Hash                  `json:"txHash"`
+	Calls  []*types.InternalTransaction `json:"calls"`
+}
+
+// GetInternalTransactions returns the internal transactions executed by every
+// transaction of the given block. The call traces recorded at import time are
+// served if available, otherwise the block is re-executed.
+func (api *API) GetInternalTransactions(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*TxInternalTransactions, error) {
+	var (
+		block *types.Block
+		err   error
+	)
+	if number, ok := blockNrOrHash.Number(); ok {
+		block, err = api.blockByNumber(ctx, number)
+	}
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block, err = api.blockByHash(ctx, hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New("block not found")
+	}
+	txs := block.Transactions()
+	traces := rawdb.ReadCallTraces(api.backend.ChainDb(), block.Hash())
+	if traces == nil && len(txs) > 0 {
+		if traces, err = api.recordCallTraces(ctx, block); err != nil {
+			return nil, err
+		}
+	}
+	if len(traces) != len(txs) {
+		return nil, fmt.Errorf("call traces mismatch: have %d, want %d", len(traces), len(txs))
+	}
+	results := make([]*TxInternalTransactions, len(txs))
+	for i, tx := range txs {
+		results[i] = &TxInternalTransactions{TxHash: tx.Hash(), Calls: traces[i]}
+	}
+	return results, nil
+}
+
+// recordCallTraces re-executes the given block and records the internal
+// transactions of all its transactions.
+func (api *API) recordCallTraces(ctx context.Context, block *types.Block) ([][]*types.InternalTransaction, error) {
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := api.backend.StateAtBlock(ctx, parent, defaultTraceReexec, nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		recorder           = vm.NewCallRecorder()
+		signer             = types.MakeSigner(api.backend.ChainConfig(), block.Number())
+		chainConfig        = api.backend.ChainConfig()
+		vmctx              = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+		deleteEmptyObjects = chainConfig.IsEIP158(block.Number())
+	)
+	for i, tx := range block.Transactions() {
+		msg, err := tx.AsMessage(signer, block.BaseFee())
+		if err != nil {
+			return nil, fmt.Errorf("transaction %#x failed: %w", tx.Hash(), err)
+		}
+		var (
+			txContext = core.NewEVMTxContext(msg)
+			vmenv     = vm.NewEVM(vmctx, txContext, statedb, chainConfig, vm.Config{Debug: true, Tracer: recorder})
+		)
+		statedb.Prepare(tx.Hash(), i)
+		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
+			return nil, fmt.Errorf("transaction %#x failed: %w", tx.Hash(), err)
+		}
+		statedb.Finalise(deleteEmptyObjects)
+	}
+	return recorder.Traces(), nil
+}
@@ -56,6 +56,9 @@ type testBackend struct {
 	engine      consensus.Engine
 	chaindb     ethdb.Database
 	chain       *core.BlockChain
+
+	pruned          bool // Whether the state is unavailable, as if pruned
+	historicalState ethapi.HistoricalStateProvider
 }
 
 func newTestBackend(t *testing.T, n int, gspec *core.Genesis, generator func(i int, b *core.BlockGen)) *testBackend {
@@ -122,6 +125,14 @@ func (b *testBackend) GetTransaction(ctx context.Context, txHash common.Hash) (*
 	return tx, hash, blockNumber, index, nil
 }
 
+func (b *testBackend) HistoricalState() ethapi.HistoricalStateProvider {
+	return b.historicalState
+}
+
+func (b *testBackend) RPCResponseCache() *ethapi.ResponseCache {
+	return nil
+}
+
 func (b *testBackend) RPCGasCap() uint64 {
 	return 25000000
 }
@@ -144,7 +155,7 @@ func (b *testBackend) ChainDb() ethdb.Database {
 
 func (b *testBackend) StateAtBlock(ctx context.Context, block *types.Block, reexec uint64, base *state.StateDB, checkLive bool, preferDisk bool) (*state.StateDB, error) {
 	statedb, err := b.chain.StateAt(block.Root())
-	if err != nil {
+	if err != nil || b.pruned {
 		return nil, errStateNotFound
 	}
 	return statedb, nil
@@ -156,7 +167,7 @@ func (b *testBackend) StateAtTransaction(ctx context.Context, block *types.Block
 		return nil, vm.BlockContext{}, nil, errBlockNotFound
 	}
 	statedb, err := b.chain.StateAt(parent.Root())
-	if err != nil {
+	if err != nil || b.pruned {
 		return nil, vm.BlockContext{}, nil, errStateNotFound
 	}
 	if txIndex == 0 && len(block.Transactions()) == 0 {
@@ -343,6 +354,55 @@ func TestTraceTransaction(t *testing.T) {
 	}
 }
 
+// testStateProvider serves a fixed response to forwarded queries, recording
+// the methods called.
+type testStateProvider struct {
+	response string
+	methods  []string
+}
+
+func (p *testStateProvider) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	p.methods = append(p.methods, method)
+	return json.Unmarshal([]byte(p.response), result)
+}
+
+func TestTraceTransactionHistorical(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+	}}
+	target := common.Hash{}
+	signer := types.HomesteadSigner{}
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+		target = tx.Hash()
+	})
+	backend.pruned = true
+	api := NewAPI(backend)
+
+	// Traces of pruned state fail without a historical state provider
+	if _, err := api.TraceTransaction(context.Background(), target, nil); err != errStateNotFound {
+		t.Fatalf("error mismatch: have %v, want %v", err, errStateNotFound)
+	}
+	// And are forwarded to it otherwise
+	provider := &testStateProvider{response: `{"gas":21000,"failed":false,"returnValue":"","structLogs":[]}`}
+	backend.historicalState = provider
+
+	result, err := api.TraceTransaction(context.Background(), target, nil)
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	if have, _ := json.Marshal(result); string(have) != provider.response {
+		t.Fatalf("result mismatch: have %s, want %s", have, provider.response)
+	}
+	if len(provider.methods) != 1 || provider.methods[0] != "debug_traceTransaction" {
+		t.Fatalf("forwarded methods mismatch: %v", provider.methods)
+	}
+}
+
 func TestTraceBlock(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,189 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txmirror
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+const (
+	// reconnectInterval is the time to wait before reconnecting to the sequencer
+	// after the mirror stream failed.
+	reconnectInterval = 5 * time.Second
+
+	// dialTimeout is the timeout of establishing the mirror stream.
+	dialTimeout = 10 * time.Second
+)
+
+var (
+	appliedAdmittedMeter = metrics.NewRegisteredMeter("txmirror/client/admitted", nil)
+	appliedRemovedMeter  = metrics.NewRegisteredMeter("txmirror/client/removed", nil)
+	rejectedMeter        = metrics.NewRegisteredMeter("txmirror/client/rejected", nil)
+	reconnectMeter       = metrics.NewRegisteredMeter("txmirror/client/reconnect", nil)
+)
+
+// mirrorPool is the view of the follower's transaction pool needed to apply
+// mirrored changes.
+type mirrorPool interface {
+	AddRemotes(txs []*types.Transaction) []error
+	RemoveTxs(hashes []common.Hash) int
+}
+
+// Client follows the transaction pool of a sequencer, applying its admissions
+// and removals to the local pool.
+type Client struct {
+	url    string
+	secret string
+	pool   mirrorPool
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewClient creates a mirror client following the sequencer at the given
+// websocket endpoint and registers its lifecycle with the node.
+func NewClient(stack *node.Node, pool mirrorPool, config Config) error {
+	if config.URL == "" {
+		return errors.New("missing sequencer URL")
+	}
+	c := &Client{
+		url:    config.URL,
+		secret: config.Secret,
+		pool:   pool,
+		quit:   make(chan struct{}),
+	}
+	stack.RegisterLifecycle(c)
+	return nil
+}
+
+// Start implements node.Lifecycle, starting to follow the sequencer's pool.
+func (c *Client) Start() error {
+	c.wg.Add(1)
+	go c.loop()
+
+	log.Info("Started transaction pool mirror client", "sequencer", c.url)
+	return nil
+}
+
+// Stop implements node.Lifecycle, disconnecting from the sequencer.
+func (c *Client) Stop() error {
+	close(c.quit)
+	c.wg.Wait()
+
+	log.Info("Stopped transaction pool mirror client")
+	return nil
+}
+
+// loop keeps the mirror stream to the sequencer alive until termination.
+func (c *Client) loop() {
+	defer c.wg.Done()
+
+	for {
+		err := c.follow()
+		if err == nil {
+			return
+		}
+		reconnectMeter.Mark(1)
+		log.Warn("Transaction pool mirror stream failed", "sequencer", c.url, "err", err)
+
+		select {
+		case <-time.After(reconnectInterval):
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+// follow subscribes to the sequencer's pool and applies its updates until the
+// stream fails or the client is stopped, in which case it returns nil.
+func (c *Client) follow() error {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	client, err := rpc.DialContext(ctx, c.url)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	events := make(chan Event, eventChanSize)
+	sub, err := client.Subscribe(ctx, "scroll", events, "txPoolMirror", c.secret)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	log.Info("Following sequencer transaction pool", "sequencer", c.url)
+	for {
+		select {
+		case ev := <-events:
+			c.apply(ev)
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		case <-c.quit:
+			return nil
+		}
+	}
+}
+
+// apply applies a single pool update of the sequencer to the local pool.
+func (c *Client) apply(ev Event) {
+	if len(ev.Removed) > 0 {
+		appliedRemovedMeter.Mark(int64(c.pool.RemoveTxs(ev.Removed)))
+	}
+	if len(ev.Admitted) == 0 {
+		return
+	}
+	txs := make([]*types.Transaction, 0, len(ev.Admitted))
+	for _, blob := range ev.Admitted {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(blob); err != nil {
+			rejectedMeter.Mark(1)
+			log.Debug("Failed to decode mirrored transaction", "err", err)
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	admitted := 0
+	for i, err := range c.pool.AddRemotes(txs) {
+		switch {
+		case err == nil:
+			admitted++
+		case errors.Is(err, core.ErrAlreadyKnown):
+		default:
+			// The local chain may lag behind the sequencer, leaving some
+			// transactions temporarily invalid locally.
+			rejectedMeter.Mark(1)
+			log.Trace("Rejected mirrored transaction", "hash", txs[i].Hash(), "err", err)
+		}
+	}
+	appliedAdmittedMeter.Mark(int64(admitted))
+}
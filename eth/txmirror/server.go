@@ -0,0 +1,282 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package txmirror relays the transaction pool of a sequencer to follower nodes.
+//
+// The sequencer side serves an authenticated "scroll_subscribe" stream of pool
+// admissions and removals; followers connect to it over websocket and apply the
+// same changes to their own pool, so that pending state and pending transaction
+// lookups on replicas match the sequencer's view.
+package txmirror
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+const (
+	// txChanSize is the size of channel listening to NewTxsEvent.
+	txChanSize = 4096
+
+	// eventChanSize is the size of the channel feeding a single subscriber.
+	eventChanSize = 128
+
+	// sweepInterval is the interval at which mirrored transactions are checked
+	// for removal from the pool.
+	sweepInterval = time.Second
+)
+
+var (
+	admittedMeter    = metrics.NewRegisteredMeter("txmirror/server/admitted", nil)
+	removedMeter     = metrics.NewRegisteredMeter("txmirror/server/removed", nil)
+	subscribersGauge = metrics.NewRegisteredGauge("txmirror/server/subscribers", nil)
+)
+
+// errUnauthorized is returned to subscribers presenting an invalid secret.
+var errUnauthorized = errors.New("unauthorized")
+
+// Config contains the settings of the transaction pool mirror.
+type Config struct {
+	URL    string `toml:",omitempty"` // Websocket endpoint of the sequencer to follow
+	Secret string `toml:",omitempty"` // Shared secret authenticating followers
+}
+
+// Event is a single update of the mirrored transaction pool.
+type Event struct {
+	Admitted []hexutil.Bytes `json:"admitted,omitempty"` // Binary encoded transactions added to the pool
+	Removed  []common.Hash   `json:"removed,omitempty"`  // Hashes of transactions dropped from the pool
+}
+
+// txPool is the view of the sequencer's transaction pool needed for mirroring.
+type txPool interface {
+	SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription
+	Pending(enforceTips bool) map[common.Address]types.Transactions
+	Has(hash common.Hash) bool
+}
+
+// Server tracks the sequencer's transaction pool and streams its changes to
+// authenticated followers.
+type Server struct {
+	pool   txPool
+	secret []byte
+
+	known map[common.Hash]struct{} // Mirrored transactions still in the pool
+	lock  sync.Mutex               // Protects known and orders feed updates
+	feed  event.Feed
+
+	sub  event.Subscription
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewServer creates a mirror server for the given pool, registering its API
+// in the "scroll" namespace and its lifecycle with the node.
+func NewServer(stack *node.Node, pool txPool, secret string) error {
+	if secret == "" {
+		return errors.New("missing mirror secret")
+	}
+	s := newServer(pool, secret)
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace: "scroll",
+		Version:   "1.0",
+		Service:   &API{s},
+		Public:    true,
+	}})
+	stack.RegisterLifecycle(s)
+	return nil
+}
+
+func newServer(pool txPool, secret string) *Server {
+	return &Server{
+		pool:   pool,
+		secret: []byte(secret),
+		known:  make(map[common.Hash]struct{}),
+		quit:   make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, starting to track the transaction pool.
+func (s *Server) Start() error {
+	txs := make(chan core.NewTxsEvent, txChanSize)
+	s.sub = s.pool.SubscribeNewTxsEvent(txs)
+
+	s.lock.Lock()
+	for _, list := range s.pool.Pending(false) {
+		for _, tx := range list {
+			s.known[tx.Hash()] = struct{}{}
+		}
+	}
+	s.lock.Unlock()
+
+	s.wg.Add(1)
+	go s.loop(txs)
+
+	log.Info("Started transaction pool mirror server")
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the pool tracking.
+func (s *Server) Stop() error {
+	s.sub.Unsubscribe()
+	close(s.quit)
+	s.wg.Wait()
+
+	log.Info("Stopped transaction pool mirror server")
+	return nil
+}
+
+// loop relays pool admissions as they happen and periodically sweeps mirrored
+// transactions for removals. The pool has no eviction events, so removals are
+// detected by checking which mirrored transactions are no longer present.
+func (s *Server) loop(txs chan core.NewTxsEvent) {
+	defer s.wg.Done()
+
+	sweep := time.NewTicker(sweepInterval)
+	defer sweep.Stop()
+
+	for {
+		select {
+		case ev := <-txs:
+			s.admit(ev.Txs)
+		case <-sweep.C:
+			s.sweep()
+		case <-s.sub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// admit records and announces transactions added to the pool.
+func (s *Server) admit(txs []*types.Transaction) {
+	ev := Event{Admitted: make([]hexutil.Bytes, 0, len(txs))}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, tx := range txs {
+		blob, err := tx.MarshalBinary()
+		if err != nil {
+			log.Warn("Failed to encode mirrored transaction", "hash", tx.Hash(), "err", err)
+			continue
+		}
+		s.known[tx.Hash()] = struct{}{}
+		ev.Admitted = append(ev.Admitted, blob)
+	}
+	if len(ev.Admitted) > 0 {
+		admittedMeter.Mark(int64(len(ev.Admitted)))
+		s.feed.Send(ev)
+	}
+}
+
+// sweep announces the mirrored transactions which left the pool since the last
+// check, whether included in a block, replaced or evicted.
+func (s *Server) sweep() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var removed []common.Hash
+	for hash := range s.known {
+		if !s.pool.Has(hash) {
+			removed = append(removed, hash)
+			delete(s.known, hash)
+		}
+	}
+	if len(removed) > 0 {
+		removedMeter.Mark(int64(len(removed)))
+		s.feed.Send(Event{Removed: removed})
+	}
+}
+
+// subscribe registers a follower for pool updates, returning the snapshot of
+// the currently mirrored transactions to start from. The snapshot and the
+// subscription are taken atomically, so no update is lost in between.
+func (s *Server) subscribe(ch chan<- Event) (Event, event.Subscription) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var snapshot Event
+	for _, list := range s.pool.Pending(false) {
+		for _, tx := range list {
+			if _, ok := s.known[tx.Hash()]; !ok {
+				continue
+			}
+			if blob, err := tx.MarshalBinary(); err == nil {
+				snapshot.Admitted = append(snapshot.Admitted, blob)
+			}
+		}
+	}
+	return snapshot, s.feed.Subscribe(ch)
+}
+
+// API exposes the transaction pool mirror stream.
+type API struct {
+	server *Server
+}
+
+// TxPoolMirror creates a subscription streaming the sequencer's pending pool:
+// a snapshot of the currently pending transactions first, then every admission
+// and removal. The secret must match the one the server was configured with.
+func (api *API) TxPoolMirror(ctx context.Context, secret string) (*rpc.Subscription, error) {
+	if subtle.ConstantTimeCompare([]byte(secret), api.server.secret) != 1 {
+		return nil, errUnauthorized
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan Event, eventChanSize)
+		snapshot, sub := api.server.subscribe(events)
+		defer sub.Unsubscribe()
+
+		subscribersGauge.Inc(1)
+		defer subscribersGauge.Dec(1)
+
+		if err := notifier.Notify(rpcSub.ID, snapshot); err != nil {
+			return
+		}
+		for {
+			select {
+			case ev := <-events:
+				if err := notifier.Notify(rpcSub.ID, ev); err != nil {
+					return
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
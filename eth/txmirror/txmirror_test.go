@@ -0,0 +1,191 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txmirror
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// testPool is a transaction pool usable both as the sequencer and the follower
+// side of the mirror.
+type testPool struct {
+	lock    sync.Mutex
+	txs     map[common.Hash]*types.Transaction
+	txsFeed event.Feed
+}
+
+func newTestPool() *testPool {
+	return &testPool{txs: make(map[common.Hash]*types.Transaction)}
+}
+
+func (p *testPool) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
+	return p.txsFeed.Subscribe(ch)
+}
+
+func (p *testPool) Pending(enforceTips bool) map[common.Address]types.Transactions {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	pending := make(map[common.Address]types.Transactions)
+	for _, tx := range p.txs {
+		pending[*tx.To()] = append(pending[*tx.To()], tx)
+	}
+	return pending
+}
+
+func (p *testPool) Has(hash common.Hash) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.txs[hash] != nil
+}
+
+func (p *testPool) AddRemotes(txs []*types.Transaction) []error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	errs := make([]error, len(txs))
+	for i, tx := range txs {
+		if p.txs[tx.Hash()] != nil {
+			errs[i] = core.ErrAlreadyKnown
+			continue
+		}
+		p.txs[tx.Hash()] = tx
+	}
+	return errs
+}
+
+func (p *testPool) RemoveTxs(hashes []common.Hash) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	removed := 0
+	for _, hash := range hashes {
+		if p.txs[hash] != nil {
+			delete(p.txs, hash)
+			removed++
+		}
+	}
+	return removed
+}
+
+// add inserts transactions into the pool, announcing them like the real pool.
+func (p *testPool) add(txs ...*types.Transaction) {
+	p.AddRemotes(txs)
+	p.txsFeed.Send(core.NewTxsEvent{Txs: txs})
+}
+
+// count returns the number of pooled transactions.
+func (p *testPool) count() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return len(p.txs)
+}
+
+func newTestTx(nonce uint64) *types.Transaction {
+	return types.NewTransaction(nonce, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil)
+}
+
+// startTestServer starts a mirror server on the given pool, serving it over a
+// websocket endpoint.
+func startTestServer(t *testing.T, pool *testPool, secret string) (*Server, string) {
+	t.Helper()
+
+	server := newServer(pool, secret)
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start mirror server: %v", err)
+	}
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("scroll", &API{server}); err != nil {
+		t.Fatalf("failed to register mirror API: %v", err)
+	}
+	http := httptest.NewServer(rpcServer.WebsocketHandler([]string{"*"}))
+	t.Cleanup(func() {
+		http.Close()
+		rpcServer.Stop()
+		server.Stop()
+	})
+	return server, "ws://" + strings.TrimPrefix(http.URL, "http://")
+}
+
+// waitFor polls the condition until it holds or the test times out.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timeout waiting for %s", what)
+}
+
+func TestMirror(t *testing.T) {
+	sequencer := newTestPool()
+	sequencer.add(newTestTx(0), newTestTx(1))
+	server, url := startTestServer(t, sequencer, "secret")
+
+	follower := newTestPool()
+	client := &Client{url: url, secret: "secret", pool: follower, quit: make(chan struct{})}
+	client.Start()
+	defer client.Stop()
+
+	// The follower starts from a snapshot of the sequencer's pool
+	waitFor(t, "snapshot", func() bool { return follower.count() == 2 })
+
+	// Admissions are relayed as they happen
+	tx := newTestTx(2)
+	sequencer.add(tx)
+	waitFor(t, "admission", func() bool { return follower.Has(tx.Hash()) })
+
+	// Removals are relayed on the next sweep
+	sequencer.RemoveTxs([]common.Hash{tx.Hash()})
+	server.sweep()
+	waitFor(t, "removal", func() bool { return !follower.Has(tx.Hash()) })
+
+	if follower.count() != 2 {
+		t.Fatalf("follower pool size mismatch: have %d, want 2", follower.count())
+	}
+}
+
+func TestMirrorUnauthorized(t *testing.T) {
+	_, url := startTestServer(t, newTestPool(), "secret")
+
+	client, err := rpc.Dial(url)
+	if err != nil {
+		t.Fatalf("failed to dial mirror server: %v", err)
+	}
+	defer client.Close()
+
+	events := make(chan Event)
+	if _, err := client.Subscribe(context.Background(), "scroll", events, "txPoolMirror", "wrong"); err == nil || err.Error() != errUnauthorized.Error() {
+		t.Fatalf("subscription error mismatch: have %v, want %v", err, errUnauthorized)
+	}
+}
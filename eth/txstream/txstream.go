@@ -0,0 +1,182 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package txstream streams the transactions applied to the blocks a sequencer
+// assembles, as they are applied.
+//
+// Inclusion monitoring and risk systems subscribe to an authenticated
+// "scroll_subscribe" stream of per-transaction execution summaries over
+// websocket, reacting before the block is even sealed.
+package txstream
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// eventChanSize is the size of the channel feeding a single subscriber. Block
+// assembly never waits for subscribers, slow ones miss transactions instead.
+const eventChanSize = 1024
+
+var (
+	streamedMeter    = metrics.NewRegisteredMeter("txstream/streamed", nil)
+	subscribersGauge = metrics.NewRegisteredGauge("txstream/subscribers", nil)
+)
+
+// errUnauthorized is returned to subscribers presenting an invalid secret.
+var errUnauthorized = errors.New("unauthorized")
+
+// Config contains the settings of the transaction stream.
+type Config struct {
+	Secret string `toml:",omitempty"` // Shared secret authenticating subscribers, the stream is disabled if empty
+}
+
+// Call is the top-level call of a transaction.
+type Call struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"` // Callee, or the contract created
+	Create   bool            `json:"create"`
+	Value    *hexutil.Big    `json:"value"`
+	Selector hexutil.Bytes   `json:"selector,omitempty"` // Leading four bytes of the calldata
+}
+
+// Summary is the execution summary of a transaction applied to a block being
+// assembled.
+type Summary struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	ParentHash  common.Hash    `json:"parentHash"`
+	Index       hexutil.Uint   `json:"transactionIndex"`
+	Hash        common.Hash    `json:"transactionHash"`
+	Status      hexutil.Uint64 `json:"status"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	Call        Call           `json:"call"`
+	Logs        []*types.Log   `json:"logs"`
+}
+
+// source is the block producer whose assembled transactions are streamed.
+type source interface {
+	SubscribeAssembledTxs(ch chan<- core.AssembledTxEvent) event.Subscription
+}
+
+// API exposes the transaction stream.
+type API struct {
+	config *params.ChainConfig
+	source source
+	secret []byte
+}
+
+// New registers the transaction stream API of the given block producer in the
+// "scroll" namespace.
+func New(stack *node.Node, config *params.ChainConfig, source source, secret string) error {
+	if secret == "" {
+		return errors.New("missing transaction stream secret")
+	}
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace: "scroll",
+		Version:   "1.0",
+		Service:   newAPI(config, source, secret),
+		Public:    true,
+	}})
+	return nil
+}
+
+func newAPI(config *params.ChainConfig, source source, secret string) *API {
+	return &API{config: config, source: source, secret: []byte(secret)}
+}
+
+// AssembledTxs creates a subscription streaming the execution summary of every
+// transaction applied to the blocks the node assembles, before they are sealed.
+// Transactions of assembly attempts which are later abandoned are streamed too.
+// The secret must match the one the stream was configured with.
+func (api *API) AssembledTxs(ctx context.Context, secret string) (*rpc.Subscription, error) {
+	if subtle.ConstantTimeCompare([]byte(secret), api.secret) != 1 {
+		return nil, errUnauthorized
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan core.AssembledTxEvent, eventChanSize)
+		sub := api.source.SubscribeAssembledTxs(events)
+		defer sub.Unsubscribe()
+
+		subscribersGauge.Inc(1)
+		defer subscribersGauge.Dec(1)
+
+		for {
+			select {
+			case ev := <-events:
+				streamedMeter.Mark(1)
+				if err := notifier.Notify(rpcSub.ID, api.summarize(ev)); err != nil {
+					return
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// summarize creates the execution summary of an assembled transaction.
+func (api *API) summarize(ev core.AssembledTxEvent) *Summary {
+	var (
+		tx      = ev.Tx
+		receipt = ev.Receipt
+	)
+	from, _ := types.Sender(types.MakeSigner(api.config, new(big.Int).SetUint64(ev.Number)), tx)
+	call := Call{
+		From:  from,
+		To:    tx.To(),
+		Value: (*hexutil.Big)(tx.Value()),
+	}
+	if call.To == nil {
+		call.To, call.Create = &receipt.ContractAddress, true
+	} else if data := tx.Data(); len(data) >= 4 {
+		call.Selector = common.CopyBytes(data[:4])
+	}
+	logs := receipt.Logs
+	if logs == nil {
+		logs = []*types.Log{}
+	}
+	return &Summary{
+		BlockNumber: hexutil.Uint64(ev.Number),
+		ParentHash:  ev.ParentHash,
+		Index:       hexutil.Uint(ev.Index),
+		Hash:        tx.Hash(),
+		Status:      hexutil.Uint64(receipt.Status),
+		GasUsed:     hexutil.Uint64(receipt.GasUsed),
+		Call:        call,
+		Logs:        logs,
+	}
+}
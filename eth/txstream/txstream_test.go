@@ -0,0 +1,124 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txstream
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// testSource is a block producer assembling transactions on demand.
+type testSource struct {
+	feed event.Feed
+}
+
+func (s *testSource) SubscribeAssembledTxs(ch chan<- core.AssembledTxEvent) event.Subscription {
+	return s.feed.Subscribe(ch)
+}
+
+// startTestStream serves the transaction stream of the given source over a
+// websocket endpoint.
+func startTestStream(t *testing.T, source *testSource, secret string) *rpc.Client {
+	t.Helper()
+
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("scroll", newAPI(params.TestChainConfig, source, secret)); err != nil {
+		t.Fatalf("failed to register stream API: %v", err)
+	}
+	http := httptest.NewServer(rpcServer.WebsocketHandler([]string{"*"}))
+	client, err := rpc.Dial("ws://" + strings.TrimPrefix(http.URL, "http://"))
+	if err != nil {
+		t.Fatalf("failed to dial stream: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		http.Close()
+		rpcServer.Stop()
+	})
+	return client
+}
+
+func TestStream(t *testing.T) {
+	var (
+		source = new(testSource)
+		client = startTestStream(t, source, "secret")
+		key, _ = crypto.GenerateKey()
+		signer = types.LatestSigner(params.TestChainConfig)
+		events = make(chan Summary, 2)
+	)
+	sub, err := client.Subscribe(context.Background(), "scroll", events, "assembledTxs", "secret")
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	call, _ := types.SignTx(types.NewTransaction(0, common.Address{0x01}, big.NewInt(1), 50000, big.NewInt(1), common.FromHex("a9059cbb0000")), signer, key)
+	create, _ := types.SignTx(types.NewContractCreation(1, big.NewInt(0), 100000, big.NewInt(1), common.FromHex("6000")), signer, key)
+	assembled := []core.AssembledTxEvent{
+		{Number: 5, ParentHash: common.Hash{0x04}, Index: 0, Tx: call, Receipt: &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 30000, Logs: []*types.Log{{Address: common.Address{0x01}, Topics: []common.Hash{{0x01}}, Data: []byte{}, TxHash: call.Hash()}}}},
+		{Number: 5, ParentHash: common.Hash{0x04}, Index: 1, Tx: create, Receipt: &types.Receipt{Status: types.ReceiptStatusFailed, GasUsed: 60000, ContractAddress: common.Address{0x02}}},
+	}
+	// The subscription is registered with the source asynchronously
+	for deadline := time.Now().Add(5 * time.Second); source.feed.Send(assembled[0]) == 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for subscription")
+		}
+	}
+	source.feed.Send(assembled[1])
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	for i, ev := range assembled {
+		var summary Summary
+		select {
+		case summary = <-events:
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for summary %d", i)
+		}
+		if summary.Hash != ev.Tx.Hash() || uint64(summary.BlockNumber) != 5 || int(summary.Index) != i {
+			t.Errorf("summary %d: position mismatch: have %+v", i, summary)
+		}
+		if uint64(summary.Status) != ev.Receipt.Status || uint64(summary.GasUsed) != ev.Receipt.GasUsed || len(summary.Logs) != len(ev.Receipt.Logs) {
+			t.Errorf("summary %d: execution mismatch: have %+v", i, summary)
+		}
+		if summary.Call.From != from {
+			t.Errorf("summary %d: sender mismatch: have %x, want %x", i, summary.Call.From, from)
+		}
+	}
+}
+
+func TestStreamUnauthorized(t *testing.T) {
+	client := startTestStream(t, new(testSource), "secret")
+
+	events := make(chan Summary)
+	if _, err := client.Subscribe(context.Background(), "scroll", events, "assembledTxs", "wrong"); err == nil || err.Error() != errUnauthorized.Error() {
+		t.Fatalf("subscription error mismatch: have %v, want %v", err, errUnauthorized)
+	}
+}
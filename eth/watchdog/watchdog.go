@@ -0,0 +1,242 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package watchdog raises alerts when block production misbehaves, so that
+// sequencer stalls surface before users notice them.
+//
+// Alerts are logged and metered, and optionally posted as JSON to a webhook.
+// A stall alert fires once no block was committed for the stall timeout, and
+// again every stall timeout while production remains stalled. A latency alert
+// fires for every block committed later than the latency threshold after its
+// timestamp.
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/node"
+)
+
+const (
+	// checkInterval is the time between two checks for stalls.
+	checkInterval = time.Second
+
+	// webhookTimeout is the timeout of a single webhook request.
+	webhookTimeout = 10 * time.Second
+
+	// chainEventChanSize is the size of the channel listening to chain events.
+	chainEventChanSize = 16
+)
+
+var (
+	sinceBlockGauge = metrics.NewRegisteredGauge("watchdog/sinceblock", nil)
+	latencyGauge    = metrics.NewRegisteredGauge("watchdog/latency", nil)
+	stallMeter      = metrics.NewRegisteredMeter("watchdog/alerts/stall", nil)
+	latencyMeter    = metrics.NewRegisteredMeter("watchdog/alerts/latency", nil)
+	webhookErrMeter = metrics.NewRegisteredMeter("watchdog/webhook/errors", nil)
+)
+
+// Alert kinds.
+const (
+	AlertStall   = "stall"   // No block committed for longer than the stall timeout
+	AlertLatency = "latency" // Block committed later than the latency threshold
+)
+
+// Config contains the settings of the block production watchdog.
+type Config struct {
+	StallTimeout  time.Duration `toml:",omitempty"` // Time without a committed block to alert after (0 = disabled)
+	CommitLatency time.Duration `toml:",omitempty"` // Delay between a block's timestamp and its commit to alert after (0 = disabled)
+	Webhook       string        `toml:",omitempty"` // URL to post alerts to (empty = disabled)
+}
+
+// Enabled returns whether any alert is configured.
+func (c Config) Enabled() bool {
+	return c.StallTimeout > 0 || c.CommitLatency > 0
+}
+
+// Alert is the JSON body posted to the webhook.
+type Alert struct {
+	Kind    string        `json:"kind"`
+	Message string        `json:"message"`
+	Number  uint64        `json:"number"`  // Number of the last block committed
+	Elapsed time.Duration `json:"elapsed"` // Time without block or commit latency, in nanoseconds
+	Time    time.Time     `json:"time"`
+}
+
+// backend encompasses the functionality needed to watch block production.
+type backend interface {
+	CurrentBlock() *types.Block
+	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
+}
+
+// Service watches the blocks committed to the chain and raises alerts when
+// production stalls or lags behind.
+type Service struct {
+	config  Config
+	backend backend
+
+	last    time.Time // Time the last block was committed, or the watchdog started
+	number  uint64    // Number of the last block committed
+	alerted time.Time // Time of the last stall alert since the last block, zero if none
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a block production watchdog and registers it with the node.
+func New(stack *node.Node, backend backend, config Config) error {
+	if !config.Enabled() {
+		return errors.New("no alert threshold configured")
+	}
+	s := &Service{
+		config:  config,
+		backend: backend,
+		quit:    make(chan struct{}),
+	}
+	stack.RegisterLifecycle(s)
+	return nil
+}
+
+// Start implements node.Lifecycle, starting to watch the chain.
+func (s *Service) Start() error {
+	s.last, s.number = time.Now(), s.backend.CurrentBlock().NumberU64()
+
+	events := make(chan core.ChainEvent, chainEventChanSize)
+	sub := s.backend.SubscribeChainEvent(events)
+
+	s.wg.Add(1)
+	go s.loop(events, sub)
+
+	log.Info("Started block production watchdog", "stall", s.config.StallTimeout, "latency", s.config.CommitLatency, "webhook", s.config.Webhook != "")
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the watchdog.
+func (s *Service) Stop() error {
+	close(s.quit)
+	s.wg.Wait()
+	return nil
+}
+
+func (s *Service) loop(events chan core.ChainEvent, sub event.Subscription) {
+	defer s.wg.Done()
+	defer sub.Unsubscribe()
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev := <-events:
+			s.committed(ev.Block, time.Now())
+		case now := <-ticker.C:
+			s.check(now)
+		case <-sub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// committed records a block committed at the given time, alerting if it was
+// committed too late after its timestamp.
+func (s *Service) committed(block *types.Block, now time.Time) {
+	if !s.alerted.IsZero() {
+		log.Info("Block production resumed", "number", block.NumberU64(), "stalled", common.PrettyDuration(now.Sub(s.last)))
+	}
+	s.last, s.number, s.alerted = now, block.NumberU64(), time.Time{}
+	sinceBlockGauge.Update(0)
+
+	latency := now.Sub(time.Unix(int64(block.Time()), 0))
+	latencyGauge.Update(int64(latency))
+	if s.config.CommitLatency > 0 && latency > s.config.CommitLatency {
+		latencyMeter.Mark(1)
+		s.alert(AlertLatency, fmt.Sprintf("block %d committed %v after its timestamp", s.number, common.PrettyDuration(latency)), latency, now)
+	}
+}
+
+// check alerts if no block was committed for longer than the stall timeout,
+// repeating the alert every stall timeout while production remains stalled.
+func (s *Service) check(now time.Time) {
+	elapsed := now.Sub(s.last)
+	sinceBlockGauge.Update(int64(elapsed))
+
+	if s.config.StallTimeout == 0 || elapsed <= s.config.StallTimeout {
+		return
+	}
+	if !s.alerted.IsZero() && now.Sub(s.alerted) < s.config.StallTimeout {
+		return
+	}
+	s.alerted = now
+	stallMeter.Mark(1)
+	s.alert(AlertStall, fmt.Sprintf("no block committed for %v since block %d", common.PrettyDuration(elapsed), s.number), elapsed, now)
+}
+
+// alert logs an alert and posts it to the webhook if configured.
+func (s *Service) alert(kind string, message string, elapsed time.Duration, now time.Time) {
+	log.Error("Block production alert", "kind", kind, "msg", message)
+	if s.config.Webhook == "" {
+		return
+	}
+	alert := Alert{Kind: kind, Message: message, Number: s.number, Elapsed: elapsed, Time: now}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.post(alert); err != nil {
+			webhookErrMeter.Mark(1)
+			log.Warn("Failed to post watchdog alert", "kind", kind, "err", err)
+		}
+	}()
+}
+
+// post posts an alert to the webhook.
+func (s *Service) post(alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.Webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook responded with status %s", res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package watchdog
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+func testBlock(number uint64, timestamp time.Time) *types.Block {
+	return types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(number), Time: uint64(timestamp.Unix())})
+}
+
+func TestWatchdogAlerts(t *testing.T) {
+	var (
+		alerts []Alert
+		lock   sync.Mutex
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("invalid alert: %v", err)
+		}
+		lock.Lock()
+		alerts = append(alerts, alert)
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	var (
+		start = time.Unix(1000, 0)
+		s     = &Service{
+			config: Config{StallTimeout: 10 * time.Second, CommitLatency: 2 * time.Second, Webhook: server.URL},
+			last:   start,
+			quit:   make(chan struct{}),
+		}
+	)
+	// Blocks committed in time don't alert
+	s.committed(testBlock(1, start), start.Add(time.Second))
+	s.check(start.Add(10 * time.Second))
+
+	// Stalls alert once per stall timeout
+	s.check(start.Add(12 * time.Second))
+	s.check(start.Add(15 * time.Second))
+	s.check(start.Add(22 * time.Second))
+
+	// Late blocks alert and end the stall
+	s.committed(testBlock(2, start.Add(20*time.Second)), start.Add(25*time.Second))
+	s.check(start.Add(30 * time.Second))
+	s.wg.Wait()
+
+	want := []Alert{
+		{Kind: AlertStall, Number: 1, Elapsed: 11 * time.Second},
+		{Kind: AlertStall, Number: 1, Elapsed: 21 * time.Second},
+		{Kind: AlertLatency, Number: 2, Elapsed: 5 * time.Second},
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if len(alerts) != len(want) {
+		t.Fatalf("alert count mismatch: have %d, want %d", len(alerts), len(want))
+	}
+	for _, w := range want {
+		var found bool
+		for _, alert := range alerts {
+			if alert.Kind == w.Kind && alert.Number == w.Number && alert.Elapsed == w.Elapsed {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing alert %+v", w)
+		}
+	}
+}
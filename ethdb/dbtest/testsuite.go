@@ -18,6 +18,7 @@ package dbtest
 
 import (
 	"bytes"
+	"crypto/rand"
 	"reflect"
 	"sort"
 	"testing"
@@ -315,6 +316,90 @@ func TestDatabaseSuite(t *testing.T, New func() ethdb.KeyValueStore) {
 
 }
 
+// BenchDatabaseSuite runs a suite of benchmarks against a KeyValueStore database
+// implementation, modelled after the access patterns of a sequencer: every
+// block commits a batch of many small trie node and snapshot writes, which are
+// then read back and iterated by the following blocks.
+func BenchDatabaseSuite(b *testing.B, New func() ethdb.KeyValueStore) {
+	const (
+		blockWrites = 1000 // Number of entries written by a block
+		keySize     = 32   // Size of a hashed trie node or snapshot key
+		valueSize   = 100  // Average size of a trie node or snapshot entry
+	)
+	// makeBlock creates the entries written by a single block.
+	makeBlock := func() ([][]byte, [][]byte) {
+		keys, vals := make([][]byte, blockWrites), make([][]byte, blockWrites)
+		for i := range keys {
+			keys[i], vals[i] = make([]byte, keySize), make([]byte, valueSize)
+			rand.Read(keys[i])
+			rand.Read(vals[i])
+		}
+		return keys, vals
+	}
+	// commitBlock writes the entries of a block as a single batch.
+	commitBlock := func(b *testing.B, db ethdb.KeyValueStore, keys, vals [][]byte) {
+		batch := db.NewBatch()
+		for i := range keys {
+			if err := batch.Put(keys[i], vals[i]); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := batch.Write(); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.Run("BlockCommit", func(b *testing.B) {
+		db := New()
+		defer db.Close()
+
+		blocks := make([][2][][]byte, b.N)
+		for i := range blocks {
+			blocks[i][0], blocks[i][1] = makeBlock()
+		}
+		b.SetBytes(blockWrites * (keySize + valueSize))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			commitBlock(b, db, blocks[i][0], blocks[i][1])
+		}
+	})
+	b.Run("RandomRead", func(b *testing.B) {
+		db := New()
+		defer db.Close()
+
+		var keys [][]byte
+		for i := 0; i < 100; i++ {
+			blockKeys, blockVals := makeBlock()
+			commitBlock(b, db, blockKeys, blockVals)
+			keys = append(keys, blockKeys...)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := db.Get(keys[i%len(keys)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Iterate", func(b *testing.B) {
+		db := New()
+		defer db.Close()
+
+		for i := 0; i < 100; i++ {
+			keys, vals := makeBlock()
+			commitBlock(b, db, keys, vals)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			it := db.NewIterator(nil, nil)
+			for it.Next() {
+			}
+			if err := it.Error(); err != nil {
+				b.Fatal(err)
+			}
+			it.Release()
+		}
+	})
+}
+
 func iterateKeys(it ethdb.Iterator) []string {
 	keys := []string{}
 	for it.Next() {
@@ -39,3 +39,13 @@ func TestLevelDB(t *testing.T) {
 		})
 	})
 }
+
+func BenchmarkLevelDB(b *testing.B) {
+	dbtest.BenchDatabaseSuite(b, func() ethdb.KeyValueStore {
+		db, err := New(b.TempDir(), 128, 128, "", false)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return db
+	})
+}
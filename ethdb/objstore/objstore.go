@@ -0,0 +1,313 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package objstore implements a minimal client for S3 compatible object storage,
+// as offered by AWS S3, Google Cloud Storage (through its XML interoperability
+// API), MinIO and others.
+//
+// Only the operations needed to keep immutable files in a bucket are supported:
+// uploading a whole object, reading a byte range of it and querying its size.
+// Requests are authenticated with AWS signature version 4 using the HMAC keys
+// from the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables.
+package objstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// gcsEndpoint is the S3 compatible endpoint of Google Cloud Storage.
+	gcsEndpoint = "https://storage.googleapis.com"
+
+	// requestTimeout is the time allowed for a request without a body, uploads
+	// are only bounded by the read timeout of the connection.
+	requestTimeout = time.Minute
+
+	// emptyHash is the hash of an empty request body.
+	emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// ErrNotFound is returned if the requested object does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// Client accesses the objects below a prefix of a bucket.
+type Client struct {
+	endpoint *url.URL // Scheme and host of the service
+	region   string   // Region used for signing requests
+	bucket   string   // Bucket holding the objects
+	prefix   string   // Key prefix of all objects, without trailing slash
+
+	accessKey string
+	secretKey string
+
+	client *http.Client
+	now    func() time.Time // Clock used for signing, replaced in tests
+}
+
+// New creates a client from a location of the form
+//
+//	s3://bucket/prefix?endpoint=https://host&region=us-east-1
+//	gs://bucket/prefix
+//
+// The endpoint defaults to AWS S3 in the given region (us-east-1 if unset) for
+// s3 locations and to the interoperability API of Google Cloud Storage for gs
+// locations. Objects are addressed in path-style.
+func New(location string) (*Client, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing bucket in %q", location)
+	}
+	var (
+		region   = u.Query().Get("region")
+		endpoint = u.Query().Get("endpoint")
+	)
+	switch u.Scheme {
+	case "s3":
+		if region == "" {
+			region = "us-east-1"
+		}
+		if endpoint == "" {
+			endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+		}
+	case "gs":
+		if region == "" {
+			region = "auto"
+		}
+		if endpoint == "" {
+			endpoint = gcsEndpoint
+		}
+	default:
+		return nil, fmt.Errorf("unsupported object storage scheme %q", u.Scheme)
+	}
+	ep, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if ep.Scheme != "http" && ep.Scheme != "https" {
+		return nil, fmt.Errorf("invalid object storage endpoint %q", endpoint)
+	}
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, errors.New("object storage credentials missing, set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	return &Client{
+		endpoint:  &url.URL{Scheme: ep.Scheme, Host: ep.Host},
+		region:    region,
+		bucket:    u.Host,
+		prefix:    strings.Trim(u.Path, "/"),
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &http.Client{Transport: http.DefaultTransport},
+		now:       time.Now,
+	}, nil
+}
+
+// String implements fmt.Stringer, returning the location of the objects.
+func (c *Client) String() string {
+	return fmt.Sprintf("%s/%s/%s", c.endpoint, c.bucket, c.prefix)
+}
+
+// Put uploads an object of the given size, replacing any existing one.
+func (c *Client) Put(name string, data io.ReadSeeker, size int64) error {
+	// Hash the payload first, it's part of the signature and lets the service
+	// verify the integrity of the upload
+	hasher := sha256.New()
+	if n, err := io.Copy(hasher, data); err != nil {
+		return err
+	} else if n != size {
+		return fmt.Errorf("object %s size mismatch: have %d, want %d", name, n, size)
+	}
+	if _, err := data.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	req, err := c.newRequest(http.MethodPut, name, io.NopCloser(data), hex.EncodeToString(hasher.Sum(nil)))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	res, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// GetRange reads length bytes of an object starting at offset. Fewer bytes are
+// returned if the object ends earlier.
+func (c *Client) GetRange(name string, offset int64, length int) ([]byte, error) {
+	req, err := c.newRequest(http.MethodGet, name, nil, emptyHash)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+int64(length)-1))
+	res, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("object %s: unexpected status %s for range request", name, res.Status)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, int64(length)))
+}
+
+// Size returns the size of an object, or ErrNotFound if it doesn't exist.
+func (c *Client) Size(name string) (int64, error) {
+	req, err := c.newRequest(http.MethodHead, name, nil, emptyHash)
+	if err != nil {
+		return 0, err
+	}
+	res, err := c.do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+	return res.ContentLength, nil
+}
+
+// newRequest creates an unsigned request for the named object.
+func (c *Client) newRequest(method string, name string, body io.ReadCloser, payloadHash string) (*http.Request, error) {
+	u := *c.endpoint
+	u.Path = "/" + path.Join(c.bucket, c.prefix, name)
+	u.RawPath = escapePath(u.Path)
+
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Body = body
+	}
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	return req, nil
+}
+
+// do signs and sends a request, converting error statuses into errors.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	c.sign(req)
+
+	client := c.client
+	if req.Method != http.MethodPut {
+		client = &http.Client{Transport: c.client.Transport, Timeout: requestTimeout}
+	}
+	return c.check(client.Do(req))
+}
+
+// check converts error statuses of a response into errors.
+func (c *Client) check(res *http.Response, err error) (*http.Response, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 300 {
+		return res, nil
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return nil, fmt.Errorf("object storage request %s %s failed: %s: %s", res.Request.Method, res.Request.URL.Path, res.Status, msg)
+}
+
+// sign adds an AWS signature version 4 to a request, covering the host, the
+// range and all x-amz-* headers.
+func (c *Client) sign(req *http.Request) {
+	var (
+		now   = c.now().UTC()
+		stamp = now.Format("20060102T150405Z")
+		date  = now.Format("20060102")
+		scope = date + "/" + c.region + "/s3/aws4_request"
+	)
+	req.Header.Set("X-Amz-Date", stamp)
+
+	// Assemble the canonical request
+	headers := map[string]string{"host": req.URL.Host}
+	for key, vals := range req.Header {
+		key = strings.ToLower(key)
+		if key == "range" || strings.HasPrefix(key, "x-amz-") {
+			headers[key] = strings.TrimSpace(strings.Join(vals, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	canonical.WriteString(req.Method + "\n")
+	canonical.WriteString(req.URL.EscapedPath() + "\n")
+	canonical.WriteString(req.URL.Query().Encode() + "\n")
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signed := strings.Join(names, ";")
+	canonical.WriteString("\n" + signed + "\n")
+	canonical.WriteString(req.Header.Get("X-Amz-Content-Sha256"))
+
+	// Sign the request with a key derived from the secret
+	hash := sha256.Sum256([]byte(canonical.String()))
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), date)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s", c.accessKey, scope, signed, signature))
+}
+
+// escapePath URI encodes a path as required by the signature, leaving only the
+// unreserved characters and the slashes as is.
+func escapePath(p string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(p); i++ {
+		switch b := p[i]; {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '_', b == '.', b == '~', b == '/':
+			escaped.WriteByte(b)
+		default:
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+// accumulatorDepth is the depth of the Merkle tree of the header records of an
+// archive, holding MaxSize leaves.
+const accumulatorDepth = 13
+
+// ComputeAccumulator returns the root of the header records of the blocks of an
+// archive, given their hashes and total difficulties: the SSZ hash tree root of
+// a List[HeaderRecord, MaxSize], with HeaderRecord a Container of the block hash
+// as Bytes32 and the total difficulty as uint256, as in era1 archives.
+func ComputeAccumulator(hashes []common.Hash, tds []*big.Int) (common.Hash, error) {
+	if len(hashes) != len(tds) {
+		return common.Hash{}, fmt.Errorf("mismatched header records: %d hashes, %d total difficulties", len(hashes), len(tds))
+	}
+	if len(hashes) > MaxSize {
+		return common.Hash{}, fmt.Errorf("too many header records: %d, maximum %d", len(hashes), MaxSize)
+	}
+	layer := make([][32]byte, len(hashes))
+	for i, hash := range hashes {
+		td := littleEndian256(tds[i])
+		layer[i] = sha256.Sum256(append(hash.Bytes(), td[:]...))
+	}
+	var zero [32]byte // Root of an empty subtree at the current depth
+	for depth := 0; depth < accumulatorDepth; depth++ {
+		next := make([][32]byte, (len(layer)+1)/2)
+		for i := 0; i < len(layer); i += 2 {
+			right := zero
+			if i+1 < len(layer) {
+				right = layer[i+1]
+			}
+			next[i/2] = sha256.Sum256(append(layer[i][:], right[:]...))
+		}
+		zero = sha256.Sum256(append(zero[:], zero[:]...))
+		layer = next
+	}
+	root := zero
+	if len(layer) > 0 {
+		root = layer[0]
+	}
+	// Mix in the length of the list
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(hashes)))
+	return sha256.Sum256(append(root[:], length[:]...)), nil
+}
+
+// littleEndian256 returns the 32 byte little endian encoding of a uint256.
+func littleEndian256(v *big.Int) [32]byte {
+	var enc [32]byte
+	v.FillBytes(enc[:])
+	for i, j := 0, len(enc)-1; i < j; i, j = i+1, j-1 {
+		enc[i], enc[j] = enc[j], enc[i]
+	}
+	return enc
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// headerSize is the size of the header of an e2store entry: its type, the length
+// of its data and two reserved bytes.
+const headerSize = 8
+
+// maxEntrySize is the maximum size of the data of an entry read, guarding against
+// allocating huge buffers for corrupt files.
+const maxEntrySize = 1 << 30
+
+// entry is a typed record of an e2store file.
+type entry struct {
+	typ   uint16
+	value []byte
+}
+
+// e2Writer writes entries to an e2store stream.
+type e2Writer struct {
+	w      io.Writer
+	offset int64 // Number of bytes written so far
+}
+
+// write writes an entry, returning its number of bytes.
+func (w *e2Writer) write(typ uint16, value []byte) (int, error) {
+	var header [headerSize]byte
+	binary.LittleEndian.PutUint16(header[0:], typ)
+	binary.LittleEndian.PutUint32(header[2:], uint32(len(value)))
+
+	n, err := w.w.Write(header[:])
+	w.offset += int64(n)
+	if err != nil {
+		return n, err
+	}
+	m, err := w.w.Write(value)
+	w.offset += int64(m)
+	return n + m, err
+}
+
+// e2Reader reads the entries of an e2store stream in order.
+type e2Reader struct {
+	r      io.Reader
+	offset int64 // Offset of the next entry
+}
+
+// read reads the next entry, returning io.EOF at the end of the stream.
+func (r *e2Reader) read() (*entry, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("truncated entry header at offset %d", r.offset)
+		}
+		return nil, err
+	}
+	if reserved := binary.LittleEndian.Uint16(header[6:]); reserved != 0 {
+		return nil, fmt.Errorf("non-zero reserved bytes in entry at offset %d", r.offset)
+	}
+	length := binary.LittleEndian.Uint32(header[2:])
+	if length > maxEntrySize {
+		return nil, fmt.Errorf("oversized entry at offset %d: %d bytes", r.offset, length)
+	}
+	e := &entry{typ: binary.LittleEndian.Uint16(header[0:]), value: make([]byte, length)}
+	if _, err := io.ReadFull(r.r, e.value); err != nil {
+		return nil, fmt.Errorf("truncated entry at offset %d: %v", r.offset, err)
+	}
+	r.offset += headerSize + int64(length)
+	return e, nil
+}
@@ -0,0 +1,335 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package era implements era1-style archives of the chain history, holding the
+// headers, bodies and receipts of a range of consecutive blocks along with the
+// accumulator root of their hashes and total difficulties.
+//
+// Archives are e2store files laid out as
+//
+//	Version | (Header | Body | Receipts | TotalDifficulty)* | Accumulator | BlockIndex
+//
+// with the header, body and receipts stored as snappy framed RLP, the total
+// difficulty as a little endian uint256 and the block index as the number of
+// the first block, the offsets of the headers relative to the index and the
+// number of blocks. As the accumulator root commits to every block of the
+// archive, archives can be distributed out of band and verified against a list
+// of trusted roots.
+package era
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/golang/snappy"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+// MaxSize is the maximum number of blocks of an archive.
+const MaxSize = 8192
+
+// Entry types of archives.
+const (
+	TypeVersion            = 0x3265
+	TypeCompressedHeader   = 0x03
+	TypeCompressedBody     = 0x04
+	TypeCompressedReceipts = 0x05
+	TypeTotalDifficulty    = 0x06
+	TypeAccumulator        = 0x07
+	TypeBlockIndex         = 0x3266
+)
+
+// Filename returns the name of the archive of the given epoch of a network,
+// suffixed with the start of its accumulator root.
+func Filename(network string, epoch int, root common.Hash) string {
+	return fmt.Sprintf("%s-%05d-%x.era1", network, epoch, root[:4])
+}
+
+// Builder writes an archive.
+type Builder struct {
+	w       *e2Writer
+	start   uint64
+	hashes  []common.Hash
+	tds     []*big.Int
+	offsets []int64 // Offsets of the headers of the blocks
+}
+
+// NewBuilder creates a builder writing an archive to w.
+func NewBuilder(w io.Writer) *Builder {
+	return &Builder{w: &e2Writer{w: w}}
+}
+
+// Add appends a block, its receipts and its total difficulty to the archive.
+// Blocks must be added in order.
+func (b *Builder) Add(block *types.Block, receipts types.Receipts, td *big.Int) error {
+	if len(b.hashes) == 0 {
+		if _, err := b.w.write(TypeVersion, nil); err != nil {
+			return err
+		}
+		b.start = block.NumberU64()
+	}
+	if len(b.hashes) == MaxSize {
+		return fmt.Errorf("archive full: %d blocks", MaxSize)
+	}
+	if want := b.start + uint64(len(b.hashes)); block.NumberU64() != want {
+		return fmt.Errorf("non-consecutive block %d, want %d", block.NumberU64(), want)
+	}
+	b.offsets = append(b.offsets, b.w.offset)
+	b.hashes = append(b.hashes, block.Hash())
+	b.tds = append(b.tds, new(big.Int).Set(td))
+
+	header, err := compress(block.Header())
+	if err != nil {
+		return err
+	}
+	body, err := compress(block.Body())
+	if err != nil {
+		return err
+	}
+	encReceipts, err := compress(receipts)
+	if err != nil {
+		return err
+	}
+	encTD := littleEndian256(td)
+	for _, e := range []entry{{TypeCompressedHeader, header}, {TypeCompressedBody, body}, {TypeCompressedReceipts, encReceipts}, {TypeTotalDifficulty, encTD[:]}} {
+		if _, err := b.w.write(e.typ, e.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Finalize writes the accumulator root and the block index, completing the
+// archive, and returns the accumulator root.
+func (b *Builder) Finalize() (common.Hash, error) {
+	if len(b.hashes) == 0 {
+		return common.Hash{}, errors.New("empty archive")
+	}
+	root, err := ComputeAccumulator(b.hashes, b.tds)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if _, err := b.w.write(TypeAccumulator, root[:]); err != nil {
+		return common.Hash{}, err
+	}
+	index := make([]byte, 16+8*len(b.offsets))
+	binary.LittleEndian.PutUint64(index, b.start)
+	for i, offset := range b.offsets {
+		binary.LittleEndian.PutUint64(index[8+8*i:], uint64(offset-b.w.offset))
+	}
+	binary.LittleEndian.PutUint64(index[8+8*len(b.offsets):], uint64(len(b.offsets)))
+	if _, err := b.w.write(TypeBlockIndex, index); err != nil {
+		return common.Hash{}, err
+	}
+	return root, nil
+}
+
+// Summary describes a verified archive.
+type Summary struct {
+	Start      uint64      // Number of the first block
+	Count      uint64      // Number of blocks
+	ParentHash common.Hash // Parent hash of the first block
+	LastHash   common.Hash // Hash of the last block
+	LastTD     *big.Int    // Total difficulty of the last block
+	Root       common.Hash // Accumulator root
+}
+
+// Verify reads a whole archive, checking that its blocks form a chain, that
+// their bodies and receipts match their headers, that the accumulator root
+// commits to them and that the block index points to them.
+func Verify(r io.Reader) (*Summary, error) {
+	return Iterate(r, nil)
+}
+
+// Iterate reads and verifies a whole archive like Verify, calling fn for every
+// block as it's verified. Blocks passed to fn before a failure aren't covered
+// by the accumulator check yet.
+func Iterate(r io.Reader, fn func(block *types.Block, receipts types.Receipts, td *big.Int) error) (*Summary, error) {
+	e2 := &e2Reader{r: r}
+	version, err := e2.read()
+	if err != nil {
+		return nil, err
+	}
+	if version.typ != TypeVersion {
+		return nil, fmt.Errorf("invalid version entry type %#x", version.typ)
+	}
+	var (
+		summary = new(Summary)
+		hashes  []common.Hash
+		tds     []*big.Int
+		offsets []int64
+	)
+	for {
+		offset := e2.offset
+		e, err := e2.read()
+		if err != nil {
+			return nil, err
+		}
+		if e.typ == TypeAccumulator {
+			if len(e.value) != common.HashLength {
+				return nil, fmt.Errorf("invalid accumulator length %d", len(e.value))
+			}
+			summary.Root = common.BytesToHash(e.value)
+			break
+		}
+		if e.typ != TypeCompressedHeader {
+			return nil, fmt.Errorf("unexpected entry type %#x at offset %d", e.typ, offset)
+		}
+		block, receipts, td, err := readBlock(e2, e.value)
+		if err != nil {
+			return nil, err
+		}
+		number := block.NumberU64()
+		if len(hashes) == 0 {
+			summary.Start, summary.ParentHash = number, block.ParentHash()
+		} else {
+			if want := summary.Start + uint64(len(hashes)); number != want {
+				return nil, fmt.Errorf("non-consecutive block %d, want %d", number, want)
+			}
+			if block.ParentHash() != hashes[len(hashes)-1] {
+				return nil, fmt.Errorf("block %d: parent hash %x, want %x", number, block.ParentHash(), hashes[len(hashes)-1])
+			}
+			if want := new(big.Int).Add(tds[len(tds)-1], block.Difficulty()); td.Cmp(want) != 0 {
+				return nil, fmt.Errorf("block %d: total difficulty %v, want %v", number, td, want)
+			}
+		}
+		if len(hashes) == MaxSize {
+			return nil, fmt.Errorf("too many blocks, maximum %d", MaxSize)
+		}
+		hashes, tds, offsets = append(hashes, block.Hash()), append(tds, td), append(offsets, offset)
+		if fn != nil {
+			if err := fn(block, receipts, td); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(hashes) == 0 {
+		return nil, errors.New("empty archive")
+	}
+	root, err := ComputeAccumulator(hashes, tds)
+	if err != nil {
+		return nil, err
+	}
+	if root != summary.Root {
+		return nil, fmt.Errorf("accumulator root mismatch: have %x, computed %x", summary.Root, root)
+	}
+	// Check the block index, which must end the archive
+	indexOffset := e2.offset
+	index, err := e2.read()
+	if err != nil {
+		return nil, fmt.Errorf("missing block index: %v", err)
+	}
+	if index.typ != TypeBlockIndex || len(index.value) != 16+8*len(offsets) {
+		return nil, fmt.Errorf("invalid block index: type %#x, length %d", index.typ, len(index.value))
+	}
+	if start := binary.LittleEndian.Uint64(index.value); start != summary.Start {
+		return nil, fmt.Errorf("block index start %d, want %d", start, summary.Start)
+	}
+	if count := binary.LittleEndian.Uint64(index.value[8+8*len(offsets):]); count != uint64(len(offsets)) {
+		return nil, fmt.Errorf("block index count %d, want %d", count, len(offsets))
+	}
+	for i, offset := range offsets {
+		if have := int64(binary.LittleEndian.Uint64(index.value[8+8*i:])); have != offset-indexOffset {
+			return nil, fmt.Errorf("block index offset of block %d: %d, want %d", summary.Start+uint64(i), have, offset-indexOffset)
+		}
+	}
+	if _, err := e2.read(); err != io.EOF {
+		return nil, errors.New("trailing data after block index")
+	}
+	summary.Count = uint64(len(hashes))
+	summary.LastHash, summary.LastTD = hashes[len(hashes)-1], tds[len(tds)-1]
+	return summary, nil
+}
+
+// readBlock decodes the entries of a block following its header, checking the
+// body and receipts against the header.
+func readBlock(e2 *e2Reader, encHeader []byte) (*types.Block, types.Receipts, *big.Int, error) {
+	header := new(types.Header)
+	if err := decompress(encHeader, header); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid header: %v", err)
+	}
+	number := header.Number.Uint64()
+
+	var entries [3]*entry
+	for i, typ := range []uint16{TypeCompressedBody, TypeCompressedReceipts, TypeTotalDifficulty} {
+		e, err := e2.read()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("block %d: %v", number, err)
+		}
+		if e.typ != typ {
+			return nil, nil, nil, fmt.Errorf("block %d: entry type %#x, want %#x", number, e.typ, typ)
+		}
+		entries[i] = e
+	}
+	body := new(types.Body)
+	if err := decompress(entries[0].value, body); err != nil {
+		return nil, nil, nil, fmt.Errorf("block %d: invalid body: %v", number, err)
+	}
+	var receipts types.Receipts
+	if err := decompress(entries[1].value, &receipts); err != nil {
+		return nil, nil, nil, fmt.Errorf("block %d: invalid receipts: %v", number, err)
+	}
+	if len(entries[2].value) != 32 {
+		return nil, nil, nil, fmt.Errorf("block %d: invalid total difficulty length %d", number, len(entries[2].value))
+	}
+	var encTD [32]byte
+	for i, b := range entries[2].value {
+		encTD[31-i] = b
+	}
+	td := new(big.Int).SetBytes(encTD[:])
+
+	hasher := trie.NewStackTrie(nil)
+	if root := types.DeriveSha(types.Transactions(body.Transactions), hasher); root != header.TxHash {
+		return nil, nil, nil, fmt.Errorf("block %d: transaction root %x, want %x", number, root, header.TxHash)
+	}
+	if hash := types.CalcUncleHash(body.Uncles); hash != header.UncleHash {
+		return nil, nil, nil, fmt.Errorf("block %d: uncle hash %x, want %x", number, hash, header.UncleHash)
+	}
+	hasher.Reset()
+	if root := types.DeriveSha(receipts, hasher); root != header.ReceiptHash {
+		return nil, nil, nil, fmt.Errorf("block %d: receipt root %x, want %x", number, root, header.ReceiptHash)
+	}
+	return types.NewBlockWithHeader(header).WithBody(body.Transactions, body.Uncles), receipts, td, nil
+}
+
+// compress returns the snappy framed RLP encoding of a value.
+func compress(val interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	w := snappy.NewBufferedWriter(&buf)
+	if err := rlp.Encode(w, val); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress decodes a snappy framed RLP encoded value.
+func decompress(data []byte, val interface{}) error {
+	return rlp.Decode(snappy.NewReader(bytes.NewReader(data)), val)
+}
@@ -0,0 +1,172 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// buildArchive generates a chain and returns the archive of its blocks, along
+// with the blocks and the accumulator root.
+func buildArchive(t *testing.T, n int) ([]byte, []*types.Block, common.Hash) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &core.Genesis{Config: params.TestChainConfig, Alloc: core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(params.TestChainConfig)
+	)
+	blocks, receipts := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, n, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{0x01}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	var (
+		buf     bytes.Buffer
+		builder = NewBuilder(&buf)
+		td      = new(big.Int).Set(genesis.Difficulty())
+	)
+	for i, block := range blocks {
+		td.Add(td, block.Difficulty())
+		if err := builder.Add(block, receipts[i], td); err != nil {
+			t.Fatalf("failed to add block %d: %v", block.NumberU64(), err)
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		t.Fatalf("failed to finalize archive: %v", err)
+	}
+	return buf.Bytes(), blocks, root
+}
+
+func TestArchiveRoundtrip(t *testing.T) {
+	archive, blocks, root := buildArchive(t, 5)
+
+	var hashes []common.Hash
+	summary, err := Iterate(bytes.NewReader(archive), func(block *types.Block, receipts types.Receipts, td *big.Int) error {
+		if len(receipts) != len(block.Transactions()) {
+			t.Errorf("block %d: have %d receipts, want %d", block.NumberU64(), len(receipts), len(block.Transactions()))
+		}
+		hashes = append(hashes, block.Hash())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to verify archive: %v", err)
+	}
+	if len(hashes) != len(blocks) {
+		t.Fatalf("iterated %d blocks, want %d", len(hashes), len(blocks))
+	}
+	for i, block := range blocks {
+		if hashes[i] != block.Hash() {
+			t.Errorf("block %d: hash %x, want %x", i+1, hashes[i], block.Hash())
+		}
+	}
+	if summary.Root != root {
+		t.Errorf("root mismatch: have %x, want %x", summary.Root, root)
+	}
+	if summary.Start != 1 || summary.Count != 5 {
+		t.Errorf("range mismatch: have #%d+%d, want #1+5", summary.Start, summary.Count)
+	}
+	if summary.ParentHash != blocks[0].ParentHash() || summary.LastHash != blocks[4].Hash() {
+		t.Errorf("boundary hashes mismatch")
+	}
+}
+
+func TestArchiveTampering(t *testing.T) {
+	archive, _, _ := buildArchive(t, 3)
+
+	// Locate the entries of the archive to tamper with
+	var offsets = make(map[uint16][]int64)
+	e2 := &e2Reader{r: bytes.NewReader(archive)}
+	for {
+		offset := e2.offset
+		e, err := e2.read()
+		if err != nil {
+			break
+		}
+		offsets[e.typ] = append(offsets[e.typ], offset)
+	}
+	tests := []struct {
+		name   string
+		tamper func(data []byte) []byte
+		err    string
+	}{
+		{
+			name:   "body",
+			tamper: func(data []byte) []byte { data[offsets[TypeCompressedBody][1]+headerSize+12] ^= 0xff; return data },
+			err:    "block 2",
+		},
+		{
+			name:   "total difficulty",
+			tamper: func(data []byte) []byte { data[offsets[TypeTotalDifficulty][2]+headerSize] ^= 0x01; return data },
+			err:    "total difficulty",
+		},
+		{
+			name:   "accumulator",
+			tamper: func(data []byte) []byte { data[offsets[TypeAccumulator][0]+headerSize] ^= 0x01; return data },
+			err:    "accumulator root mismatch",
+		},
+		{
+			name:   "block index",
+			tamper: func(data []byte) []byte { data[offsets[TypeBlockIndex][0]+headerSize+8] ^= 0x01; return data },
+			err:    "block index offset",
+		},
+		{
+			name:   "truncated",
+			tamper: func(data []byte) []byte { return data[:len(data)-1] },
+			err:    "truncated",
+		},
+		{
+			name:   "trailing",
+			tamper: func(data []byte) []byte { return append(data, make([]byte, headerSize)...) },
+			err:    "trailing data",
+		},
+	}
+	for _, tt := range tests {
+		data := tt.tamper(common.CopyBytes(archive))
+		_, err := Verify(bytes.NewReader(data))
+		if err == nil {
+			t.Errorf("%s: tampered archive verified", tt.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: error %q, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestAccumulatorLimits(t *testing.T) {
+	if _, err := ComputeAccumulator([]common.Hash{{}}, nil); err == nil {
+		t.Error("mismatched header records accepted")
+	}
+	empty, _ := ComputeAccumulator(nil, nil)
+	one, _ := ComputeAccumulator([]common.Hash{{}}, []*big.Int{new(big.Int)})
+	if empty == one {
+		t.Error("accumulator doesn't commit to the length of the list")
+	}
+}
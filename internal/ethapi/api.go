@@ -38,6 +38,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/consensus/ethash"
 	"github.com/scroll-tech/go-ethereum/consensus/misc"
 	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/core/state"
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/core/vm"
@@ -163,7 +164,7 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransac
 	for account, txs := range pending {
 		dump := make(map[string]*RPCTransaction)
 		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx, curHeader, s.b.ChainConfig())
+			dump[txPoolNonce(tx)] = newRPCPendingTransaction(tx, curHeader, s.b.ChainConfig())
 		}
 		content["pending"][account.Hex()] = dump
 	}
@@ -171,7 +172,7 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransac
 	for account, txs := range queue {
 		dump := make(map[string]*RPCTransaction)
 		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx, curHeader, s.b.ChainConfig())
+			dump[txPoolNonce(tx)] = newRPCPendingTransaction(tx, curHeader, s.b.ChainConfig())
 		}
 		content["queued"][account.Hex()] = dump
 	}
@@ -187,20 +188,30 @@ func (s *PublicTxPoolAPI) ContentFrom(addr common.Address) map[string]map[string
 	// Build the pending transactions
 	dump := make(map[string]*RPCTransaction, len(pending))
 	for _, tx := range pending {
-		dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx, curHeader, s.b.ChainConfig())
+		dump[txPoolNonce(tx)] = newRPCPendingTransaction(tx, curHeader, s.b.ChainConfig())
 	}
 	content["pending"] = dump
 
 	// Build the queued transactions
 	dump = make(map[string]*RPCTransaction, len(queue))
 	for _, tx := range queue {
-		dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx, curHeader, s.b.ChainConfig())
+		dump[txPoolNonce(tx)] = newRPCPendingTransaction(tx, curHeader, s.b.ChainConfig())
 	}
 	content["queued"] = dump
 
 	return content
 }
 
+// txPoolNonce returns the key a transaction is listed under in the content of
+// its sender, its nonce prefixed by its nonce key unless it uses the account
+// nonce, since every nonce key counts its nonces independently.
+func txPoolNonce(tx *types.Transaction) string {
+	if key := tx.NonceKey(); key != 0 {
+		return fmt.Sprintf("%d:%d", key, tx.Nonce())
+	}
+	return fmt.Sprintf("%d", tx.Nonce())
+}
+
 // Status returns the number of pending and queued transaction in the pool.
 func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
 	pending, queue := s.b.Stats()
@@ -230,7 +241,7 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	for account, txs := range pending {
 		dump := make(map[string]string)
 		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = format(tx)
+			dump[txPoolNonce(tx)] = format(tx)
 		}
 		content["pending"][account.Hex()] = dump
 	}
@@ -238,7 +249,7 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	for account, txs := range queue {
 		dump := make(map[string]string)
 		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = format(tx)
+			dump[txPoolNonce(tx)] = format(tx)
 		}
 		content["queued"][account.Hex()] = dump
 	}
@@ -656,18 +667,16 @@ type StorageResult struct {
 
 // GetProof returns the Merkle-proof for a given account and optionally some storage keys.
 func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*AccountResult, error) {
+	if header := historicalHeader(ctx, s.b, blockNrOrHash); header != nil {
+		return s.historicalProof(ctx, address, storageKeys, header)
+	}
 	state, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
 	if state == nil || err != nil {
 		return nil, err
 	}
 
-	zktrie := s.b.ChainConfig().Scroll.ZktrieEnabled()
-
 	storageTrie := state.StorageTrie(address)
-	var storageHash common.Hash
-	if !zktrie {
-		storageHash = types.EmptyRootHash
-	}
+	storageHash := state.Commitment().EmptyStorageRoot()
 	keccakCodeHash := state.GetKeccakCodeHash(address)
 	poseidonCodeHash := state.GetPoseidonCodeHash(address)
 	storageProof := make([]StorageResult, len(storageKeys))
@@ -713,6 +722,28 @@ func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Addre
 	}, state.Error()
 }
 
+// historicalProof retrieves the proof of an account from the historical state
+// provider, verifying it against the state root of the header unless the state
+// is a zktrie, whose proofs are returned unverified.
+func (s *PublicBlockChainAPI) historicalProof(ctx context.Context, address common.Address, storageKeys []string, header *types.Header) (*AccountResult, error) {
+	var res AccountResult
+	if err := s.b.HistoricalState().CallContext(ctx, &res, "eth_getProof", address, storageKeys, rpc.BlockNumberOrHashWithHash(header.Hash(), false)); err != nil {
+		return nil, err
+	}
+	if s.b.ChainConfig().Scroll.ZktrieEnabled() {
+		return &res, nil
+	}
+	if res.Address != address || len(res.StorageProof) != len(storageKeys) {
+		historicalRejectedMeter.Mark(1)
+		return nil, fmt.Errorf("%w: mismatched account or storage keys", errInvalidHistoricalProof)
+	}
+	if err := verifyAccountResult(header.Root, &res); err != nil {
+		historicalRejectedMeter.Mark(1)
+		return nil, fmt.Errorf("%w: %v", errInvalidHistoricalProof, err)
+	}
+	return &res, nil
+}
+
 // GetHeaderByNumber returns the requested canonical block header.
 // * When blockNr is -1 the chain head is returned.
 // * When blockNr is -2 the pending chain head is returned.
@@ -746,6 +777,14 @@ func (s *PublicBlockChainAPI) GetHeaderByHash(ctx context.Context, hash common.H
 //   - When fullTx is true all transactions in the block are returned, otherwise
 //     only the transaction hash is returned.
 func (s *PublicBlockChainAPI) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	// Serve finalized blocks from the response cache if possible
+	var key string
+	if number >= 0 && IsFinalized(ctx, s.b, uint64(number)) {
+		key = ResponseKey("eth_getBlockByNumber", number, fullTx)
+		if response, ok := s.b.RPCResponseCache().Get(key); ok {
+			return response.(map[string]interface{}), nil
+		}
+	}
 	block, err := s.b.BlockByNumber(ctx, number)
 	if block != nil && err == nil {
 		response, err := s.rpcMarshalBlock(ctx, block, true, fullTx)
@@ -755,6 +794,9 @@ func (s *PublicBlockChainAPI) GetBlockByNumber(ctx context.Context, number rpc.B
 				response[field] = nil
 			}
 		}
+		if err == nil {
+			s.b.RPCResponseCache().Add(key, response)
+		}
 		return response, err
 	}
 	return nil, err
@@ -763,9 +805,17 @@ func (s *PublicBlockChainAPI) GetBlockByNumber(ctx context.Context, number rpc.B
 // GetBlockByHash returns the requested block. When fullTx is true all transactions in the block are returned in full
 // detail, otherwise only the transaction hash is returned.
 func (s *PublicBlockChainAPI) GetBlockByHash(ctx context.Context, hash common.Hash, fullTx bool) (map[string]interface{}, error) {
+	key := ResponseKey("eth_getBlockByHash", hash, fullTx)
+	if response, ok := s.b.RPCResponseCache().Get(key); ok {
+		return response.(map[string]interface{}), nil
+	}
 	block, err := s.b.BlockByHash(ctx, hash)
 	if block != nil {
-		return s.rpcMarshalBlock(ctx, block, true, fullTx)
+		response, err := s.rpcMarshalBlock(ctx, block, true, fullTx)
+		if err == nil && IsFinalized(ctx, s.b, block.NumberU64()) {
+			s.b.RPCResponseCache().Add(key, response)
+		}
+		return response, err
 	}
 	return nil, err
 }
@@ -830,6 +880,42 @@ func (s *PublicBlockChainAPI) GetCode(ctx context.Context, address common.Addres
 	return code, state.Error()
 }
 
+// CodeHashes are the hashes of the code of an account, under both the keccak
+// and the Poseidon hash function.
+type CodeHashes struct {
+	KeccakCodeHash   common.Hash    `json:"keccakCodeHash"`
+	PoseidonCodeHash common.Hash    `json:"poseidonCodeHash"`
+	CodeSize         hexutil.Uint64 `json:"codeSize"`
+}
+
+// GetCodeHashes returns the keccak and Poseidon code hashes stored in the state
+// of the given address for the given block number.
+func (s *PublicBlockChainAPI) GetCodeHashes(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*CodeHashes, error) {
+	state, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	hashes := &CodeHashes{
+		KeccakCodeHash:   state.GetKeccakCodeHash(address),
+		PoseidonCodeHash: state.GetPoseidonCodeHash(address),
+		CodeSize:         hexutil.Uint64(state.GetCodeSize(address)),
+	}
+	return hashes, state.Error()
+}
+
+// GetCodeByHash returns the contract code with the given keccak or Poseidon
+// code hash.
+func (s *PublicBlockChainAPI) GetCodeByHash(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
+	db := s.b.ChainDb()
+	if code := rawdb.ReadCode(db, hash); len(code) > 0 {
+		return code, nil
+	}
+	if code := rawdb.ReadCodeByPoseidonHash(db, hash); len(code) > 0 {
+		return code, nil
+	}
+	return nil, fmt.Errorf("code %x not found", hash)
+}
+
 // GetStorageAt returns the storage from the state at the given address, key and
 // block number. The rpc.LatestBlockNumber and rpc.PendingBlockNumber meta block
 // numbers are also allowed.
@@ -947,7 +1033,7 @@ func CalculateL1MsgFee(ctx context.Context, b Backend, args TransactionArgs, blo
 		evm.Cancel()
 	}()
 
-	return fees.CalculateL1MsgFee(msg, evm.StateDB)
+	return fees.CalculateL1MsgFee(msg, evm.StateDB, config.Scroll.IsCompressedL1Fee(header.Number))
 }
 
 func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, timeout time.Duration, globalGasCap uint64) (*core.ExecutionResult, error) {
@@ -1042,6 +1128,13 @@ func (e *revertError) ErrorData() interface{} {
 // Note, this function doesn't make and changes in the state/blockchain and is
 // useful to execute and retrieve values.
 func (s *PublicBlockChainAPI) Call(ctx context.Context, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride) (hexutil.Bytes, error) {
+	// Forward the call if the state was pruned locally, the result can't be
+	// verified without it.
+	if header := historicalHeader(ctx, s.b, blockNrOrHash); header != nil {
+		var result hexutil.Bytes
+		err := s.b.HistoricalState().CallContext(ctx, &result, "eth_call", args, rpc.BlockNumberOrHashWithHash(header.Hash(), false), overrides)
+		return result, err
+	}
 	// If gasPrice is 0 and no state override is set, make sure
 	// that the account has sufficient balance to cover `l1Fee`.
 	isGasPriceZero := args.GasPrice == nil || args.GasPrice.ToInt().Cmp(big.NewInt(0)) == 0
@@ -1304,25 +1397,28 @@ func (s *PublicBlockChainAPI) rpcMarshalBlock(ctx context.Context, b *types.Bloc
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	BlockHash        *common.Hash      `json:"blockHash"`
-	BlockNumber      *hexutil.Big      `json:"blockNumber"`
-	From             common.Address    `json:"from"`
-	Gas              hexutil.Uint64    `json:"gas"`
-	GasPrice         *hexutil.Big      `json:"gasPrice"`
-	GasFeeCap        *hexutil.Big      `json:"maxFeePerGas,omitempty"`
-	GasTipCap        *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
-	Hash             common.Hash       `json:"hash"`
-	Input            hexutil.Bytes     `json:"input"`
-	Nonce            hexutil.Uint64    `json:"nonce"`
-	To               *common.Address   `json:"to"`
-	TransactionIndex *hexutil.Uint64   `json:"transactionIndex"`
-	Value            *hexutil.Big      `json:"value"`
-	Type             hexutil.Uint64    `json:"type"`
-	Accesses         *types.AccessList `json:"accessList,omitempty"`
-	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
-	V                *hexutil.Big      `json:"v"`
-	R                *hexutil.Big      `json:"r"`
-	S                *hexutil.Big      `json:"s"`
+	BlockHash        *common.Hash                 `json:"blockHash"`
+	BlockNumber      *hexutil.Big                 `json:"blockNumber"`
+	From             common.Address               `json:"from"`
+	Gas              hexutil.Uint64               `json:"gas"`
+	GasPrice         *hexutil.Big                 `json:"gasPrice"`
+	GasFeeCap        *hexutil.Big                 `json:"maxFeePerGas,omitempty"`
+	GasTipCap        *hexutil.Big                 `json:"maxPriorityFeePerGas,omitempty"`
+	Hash             common.Hash                  `json:"hash"`
+	Input            hexutil.Bytes                `json:"input"`
+	Nonce            hexutil.Uint64               `json:"nonce"`
+	To               *common.Address              `json:"to"`
+	TransactionIndex *hexutil.Uint64              `json:"transactionIndex"`
+	Value            *hexutil.Big                 `json:"value"`
+	Type             hexutil.Uint64               `json:"type"`
+	Accesses         *types.AccessList            `json:"accessList,omitempty"`
+	ChainID          *hexutil.Big                 `json:"chainId,omitempty"`
+	FeePayer         *common.Address              `json:"feePayer,omitempty"`
+	NonceKey         *hexutil.Uint64              `json:"nonceKey,omitempty"`
+	AuthList         []types.SetCodeAuthorization `json:"authorizationList,omitempty"`
+	V                *hexutil.Big                 `json:"v"`
+	R                *hexutil.Big                 `json:"r"`
+	S                *hexutil.Big                 `json:"s"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1355,10 +1451,16 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		al := tx.AccessList()
 		result.Accesses = &al
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
-	case types.DynamicFeeTxType:
+	case types.DynamicFeeTxType, types.SponsoredTxType, types.NonceKeyTxType, types.SetCodeTxType:
 		al := tx.AccessList()
 		result.Accesses = &al
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
+		result.FeePayer = tx.FeePayer()
+		result.AuthList = tx.SetCodeAuthorizations()
+		if tx.Type() == types.NonceKeyTxType {
+			key := tx.NonceKey()
+			result.NonceKey = (*hexutil.Uint64)(&key)
+		}
 		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
 		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
 		// if the transaction has been mined, compute the effective gas price
@@ -1638,6 +1740,10 @@ func (s *PublicTransactionPoolAPI) GetRawTransactionByHash(ctx context.Context,
 
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
 func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	key := ResponseKey("eth_getTransactionReceipt", hash)
+	if response, ok := s.b.RPCResponseCache().Get(key); ok {
+		return response.(map[string]interface{}), nil
+	}
 	tx, blockHash, blockNumber, index, err := s.b.GetTransaction(ctx, hash)
 	if err != nil {
 		return nil, nil
@@ -1688,6 +1794,20 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 		gasPrice := new(big.Int).Add(baseFee, tx.EffectiveGasTipValue(header.BaseFee))
 		fields["effectiveGasPrice"] = hexutil.Uint64(gasPrice.Uint64())
 	}
+	// Mark system transactions, which neither pay for nor consume gas
+	if tx.IsSystemTx() {
+		fields["systemTx"] = true
+	}
+	// Name the sponsor which paid the fees of sponsored transactions
+	if payer := tx.FeePayer(); payer != nil {
+		fields["feePayer"] = *payer
+	}
+	// Extend by the L1 fee refunded once the batch got compressed, if any
+	refund := rawdb.ReadCanonicalL1FeeRefund(s.b.ChainDb(), hash)
+	if refund != nil && receipt.L1Fee != nil {
+		fields["l1FeeRefund"] = hexutil.Uint64(refund.Amount.Uint64())
+		fields["l1NetFee"] = hexutil.Uint64(new(big.Int).Sub(receipt.L1Fee, refund.Amount).Uint64())
+	}
 	// Assign receipt status or post state.
 	if len(receipt.PostState) > 0 {
 		fields["root"] = hexutil.Bytes(receipt.PostState)
@@ -1701,6 +1821,13 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
+	// Cache the receipt once it can't change anymore: its block is finalized,
+	// and it either paid no L1 fee or the refund of it is finalized too
+	if IsFinalized(ctx, s.b, blockNumber) {
+		if receipt.L1Fee == nil || receipt.L1Fee.Sign() == 0 || (refund != nil && IsFinalized(ctx, s.b, refund.BlockNumber)) {
+			s.b.RPCResponseCache().Add(key, fields)
+		}
+	}
 	return fields, nil
 }
 
@@ -1717,16 +1844,25 @@ func (s *PublicTransactionPoolAPI) sign(addr common.Address, tx *types.Transacti
 	return wallet.SignTx(account, tx, s.b.ChainConfig().ChainID)
 }
 
-// SubmitTransaction is a helper function that submits tx to txPool and logs a message.
-func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (common.Hash, error) {
+// CheckSubmission runs the checks of the RPC node on a transaction submitted
+// to it, on top of the validation of the transaction pool.
+func CheckSubmission(b Backend, tx *types.Transaction) error {
 	// If the transaction fee cap is already specified, ensure the
 	// fee of the given transaction is _reasonable_.
 	if err := checkTxFee(tx.GasPrice(), tx.Gas(), b.RPCTxFeeCap()); err != nil {
-		return common.Hash{}, err
+		return err
 	}
 	if !b.UnprotectedAllowed() && !tx.Protected() {
 		// Ensure only eip155 signed transactions are submitted if EIP155Required is set.
-		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
+		return errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
+	}
+	return nil
+}
+
+// SubmitTransaction is a helper function that submits tx to txPool and logs a message.
+func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (common.Hash, error) {
+	if err := CheckSubmission(b, tx); err != nil {
+		return common.Hash{}, err
 	}
 	if err := b.SendTx(ctx, tx); err != nil {
 		return common.Hash{}, err
@@ -2068,8 +2204,8 @@ func (api *PrivateDebugAPI) ChaindbCompact() error {
 }
 
 // SetHead rewinds the head of the blockchain to a previous block.
-func (api *PrivateDebugAPI) SetHead(number hexutil.Uint64) {
-	api.b.SetHead(uint64(number))
+func (api *PrivateDebugAPI) SetHead(number hexutil.Uint64) error {
+	return api.b.SetHead(uint64(number))
 }
 
 // PublicNetAPI offers network related RPC methods
@@ -0,0 +1,175 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethdb/memorydb"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+var (
+	historicalForwardedMeter = metrics.NewRegisteredMeter("rpc/historical/forwarded", nil)
+	historicalFailedMeter    = metrics.NewRegisteredMeter("rpc/historical/failed", nil)
+	historicalRejectedMeter  = metrics.NewRegisteredMeter("rpc/historical/rejected", nil)
+)
+
+// errInvalidHistoricalProof is returned if a proof served by the historical
+// state provider doesn't verify against the local header.
+var errInvalidHistoricalProof = errors.New("invalid proof from historical state provider")
+
+// HistoricalStateProvider serves the state queries of blocks whose state isn't
+// available locally, typically because it was pruned, so that a pruned node can
+// answer them instead of failing. Queries are forwarded as JSON-RPC requests,
+// pinned to the hash of the locally known block.
+type HistoricalStateProvider interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// remoteStateProvider forwards queries to an archive node over JSON-RPC.
+type remoteStateProvider struct {
+	url    string
+	client *rpc.Client // Dialed on first use
+	lock   sync.Mutex
+}
+
+// NewRemoteStateProvider creates a historical state provider forwarding queries
+// to the archive node at the given endpoint. It returns nil if url is empty.
+func NewRemoteStateProvider(url string) HistoricalStateProvider {
+	if url == "" {
+		return nil
+	}
+	return &remoteStateProvider{url: url}
+}
+
+// CallContext implements HistoricalStateProvider.
+func (p *remoteStateProvider) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	client, err := p.dial(ctx)
+	if err != nil {
+		historicalFailedMeter.Mark(1)
+		return fmt.Errorf("historical state provider unavailable: %v", err)
+	}
+	historicalForwardedMeter.Mark(1)
+	if err := client.CallContext(ctx, result, method, args...); err != nil {
+		historicalFailedMeter.Mark(1)
+		return err
+	}
+	return nil
+}
+
+// dial returns the client of the archive node, connecting if needed.
+func (p *remoteStateProvider) dial(ctx context.Context) (*rpc.Client, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.client == nil {
+		client, err := rpc.DialContext(ctx, p.url)
+		if err != nil {
+			return nil, err
+		}
+		p.client = client
+	}
+	return p.client, nil
+}
+
+// historicalHeader returns the header of the given block if the historical state
+// provider should serve its state, i.e. if the block is known locally but its
+// state isn't. It returns nil otherwise, including if no provider is configured.
+func historicalHeader(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrHash) *types.Header {
+	if b.HistoricalState() == nil {
+		return nil
+	}
+	_, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err == nil || header == nil {
+		return nil
+	}
+	log.Debug("Forwarding query to historical state provider", "number", header.Number, "hash", header.Hash(), "err", err)
+	return header
+}
+
+// verifyAccountResult checks the account and storage proofs of an eth_getProof
+// result against the state root of the block it was requested for, and that
+// the proven values match the returned ones.
+func verifyAccountResult(root common.Hash, res *AccountResult) error {
+	value, err := verifyProof(root, crypto.Keccak256(res.Address[:]), res.AccountProof)
+	if err != nil {
+		return fmt.Errorf("account %x: %v", res.Address, err)
+	}
+	account := types.StateAccount{Balance: new(big.Int), Root: types.EmptyRootHash}
+	if value != nil {
+		if err := rlp.DecodeBytes(value, &account); err != nil {
+			return fmt.Errorf("account %x: %v", res.Address, err)
+		}
+	}
+	switch {
+	case uint64(res.Nonce) != account.Nonce:
+		return fmt.Errorf("account %x: nonce %d, proven %d", res.Address, res.Nonce, account.Nonce)
+	case res.Balance == nil || res.Balance.ToInt().Cmp(account.Balance) != 0:
+		return fmt.Errorf("account %x: balance %v, proven %v", res.Address, res.Balance, account.Balance)
+	case res.StorageHash != account.Root:
+		return fmt.Errorf("account %x: storage hash %x, proven %x", res.Address, res.StorageHash, account.Root)
+	}
+	for _, slot := range res.StorageProof {
+		key := common.HexToHash(slot.Key)
+		value, err := verifyProof(account.Root, crypto.Keccak256(key[:]), slot.Proof)
+		if err != nil {
+			return fmt.Errorf("slot %s: %v", slot.Key, err)
+		}
+		proven := new(big.Int)
+		if value != nil {
+			var content []byte
+			if err := rlp.DecodeBytes(value, &content); err != nil {
+				return fmt.Errorf("slot %s: %v", slot.Key, err)
+			}
+			proven.SetBytes(content)
+		}
+		if slot.Value == nil || slot.Value.ToInt().Cmp(proven) != 0 {
+			return fmt.Errorf("slot %s: value %v, proven %v", slot.Key, slot.Value, proven)
+		}
+	}
+	return nil
+}
+
+// verifyProof checks a Merkle-Patricia proof of the given key, returning the
+// proven value, nil if the key is proven absent.
+func verifyProof(root common.Hash, key []byte, proof []string) ([]byte, error) {
+	if root == types.EmptyRootHash && len(proof) == 0 {
+		return nil, nil
+	}
+	db := memorydb.New()
+	for _, node := range proof {
+		blob, err := hexutil.Decode(node)
+		if err != nil {
+			return nil, err
+		}
+		db.Put(crypto.Keccak256(blob), blob)
+	}
+	return trie.VerifyProof(root, key, db)
+}
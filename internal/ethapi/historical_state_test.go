@@ -0,0 +1,86 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// Tests that proofs served by the historical state provider are verified against
+// the local state root.
+func TestVerifyAccountResult(t *testing.T) {
+	var (
+		addr   = common.Address{0x01}
+		key    = common.Hash{0x02}
+		db     = state.NewDatabase(rawdb.NewMemoryDatabase())
+		sdb, _ = state.New(types.EmptyRootHash, db, nil)
+	)
+	sdb.SetNonce(addr, 3)
+	sdb.SetBalance(addr, big.NewInt(1000))
+	sdb.SetState(addr, key, common.Hash{0x03})
+	root, err := sdb.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	sdb, _ = state.New(root, db, nil)
+
+	newResult := func(addr common.Address, key common.Hash) *AccountResult {
+		accountProof, _ := sdb.GetProof(addr)
+		storageProof, _ := sdb.GetStorageProof(addr, key)
+		storageHash := types.EmptyRootHash
+		if trie := sdb.StorageTrie(addr); trie != nil {
+			storageHash = trie.Hash()
+		}
+		return &AccountResult{
+			Address:      addr,
+			AccountProof: toHexSlice(accountProof),
+			Balance:      (*hexutil.Big)(sdb.GetBalance(addr)),
+			Nonce:        hexutil.Uint64(sdb.GetNonce(addr)),
+			StorageHash:  storageHash,
+			StorageProof: []StorageResult{{key.Hex(), (*hexutil.Big)(sdb.GetState(addr, key).Big()), toHexSlice(storageProof)}},
+		}
+	}
+	// Proofs of existing and missing accounts verify
+	if err := verifyAccountResult(root, newResult(addr, key)); err != nil {
+		t.Fatalf("valid proof rejected: %v", err)
+	}
+	if err := verifyAccountResult(root, newResult(common.Address{0x04}, key)); err != nil {
+		t.Fatalf("valid proof of missing account rejected: %v", err)
+	}
+	// Values not matching the proofs are rejected
+	res := newResult(addr, key)
+	res.Balance = (*hexutil.Big)(big.NewInt(1001))
+	if err := verifyAccountResult(root, res); err == nil {
+		t.Fatalf("tampered balance accepted")
+	}
+	res = newResult(addr, key)
+	res.StorageProof[0].Value = (*hexutil.Big)(big.NewInt(4))
+	if err := verifyAccountResult(root, res); err == nil {
+		t.Fatalf("tampered storage value accepted")
+	}
+	// Proofs against another root are rejected
+	if err := verifyAccountResult(common.Hash{0x05}, newResult(addr, key)); err == nil {
+		t.Fatalf("proof against another root accepted")
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+var (
+	responseCacheHitMeter   = metrics.NewRegisteredMeter("rpc/cache/hit", nil)
+	responseCacheMissMeter  = metrics.NewRegisteredMeter("rpc/cache/miss", nil)
+	responseCacheSizeGauge  = metrics.NewRegisteredGauge("rpc/cache/size", nil)
+	responseCacheItemsGauge = metrics.NewRegisteredGauge("rpc/cache/items", nil)
+)
+
+// ResponseCache caches the responses of RPC queries for immutable chain data,
+// i.e. blocks, receipts and traces at or below the finalized height, which can't
+// be reorged anymore. It's bounded by the JSON encoded size of the responses.
+//
+// Cached responses are shared between callers and must not be modified. A nil
+// cache caches nothing.
+type ResponseCache struct {
+	lock  sync.Mutex
+	items *simplelru.LRU
+	size  uint64 // JSON encoded size of the cached responses
+	limit uint64 // Maximum size of the cached responses
+}
+
+// cachedResponse is a response in the cache, along with its encoded size.
+type cachedResponse struct {
+	value interface{}
+	size  uint64
+}
+
+// NewResponseCache creates a response cache holding at most the given number of
+// bytes of responses. It returns nil if limit is zero.
+func NewResponseCache(limit uint64) *ResponseCache {
+	if limit == 0 {
+		return nil
+	}
+	c := &ResponseCache{limit: limit}
+	c.items, _ = simplelru.NewLRU(math.MaxInt32, func(key, value interface{}) {
+		c.size -= value.(*cachedResponse).size
+	})
+	return c
+}
+
+// ResponseKey returns the cache key of the response of a method to the given
+// arguments. The arguments must be JSON encodable values, not pointers which
+// may change after the call.
+func ResponseKey(method string, args ...interface{}) string {
+	enc, err := json.Marshal(args)
+	if err != nil {
+		return ""
+	}
+	return method + string(enc)
+}
+
+// Get retrieves the cached response with the given key.
+func (c *ResponseCache) Get(key string) (interface{}, bool) {
+	if c == nil || key == "" {
+		return nil, false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if item, ok := c.items.Get(key); ok {
+		responseCacheHitMeter.Mark(1)
+		return item.(*cachedResponse).value, true
+	}
+	responseCacheMissMeter.Mark(1)
+	return nil, false
+}
+
+// Add caches a response with the given key, evicting the least recently used
+// responses to stay within the size limit. Responses larger than a quarter of
+// the limit are not cached, so that a few of them can't flush the cache.
+func (c *ResponseCache) Add(key string, value interface{}) {
+	if c == nil || key == "" {
+		return
+	}
+	enc, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	size := uint64(len(key) + len(enc))
+	if size > c.limit/4 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.items.Contains(key) {
+		return
+	}
+	c.items.Add(key, &cachedResponse{value: value, size: size})
+	c.size += size
+	for c.size > c.limit {
+		c.items.RemoveOldest()
+	}
+	responseCacheSizeGauge.Update(int64(c.size))
+	responseCacheItemsGauge.Update(int64(c.items.Len()))
+}
+
+// HeaderReader retrieves headers by number, implemented by the API backends.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error)
+}
+
+// IsFinalized reports whether the block with the given number is at or below the
+// finalized height of the backend, so that queries about it can be cached.
+func IsFinalized(ctx context.Context, b HeaderReader, number uint64) bool {
+	header, err := b.HeaderByNumber(ctx, rpc.FinalizedBlockNumber)
+	return err == nil && header != nil && header.Number.Uint64() >= number
+}
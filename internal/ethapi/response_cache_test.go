@@ -0,0 +1,65 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests that the response cache evicts the least recently used responses to
+// stay within its size limit, and skips responses too large to cache.
+func TestResponseCacheEviction(t *testing.T) {
+	var (
+		cache = NewResponseCache(1024)
+		value = strings.Repeat("x", 200)
+	)
+	keys := []string{ResponseKey("test", 1), ResponseKey("test", 2), ResponseKey("test", 3), ResponseKey("test", 4)}
+	for _, key := range keys[:3] {
+		cache.Add(key, value)
+	}
+	// Touch the first response, so that the second one is evicted next
+	if _, ok := cache.Get(keys[0]); !ok {
+		t.Fatalf("response missing")
+	}
+	cache.Add(keys[3], value)
+	cache.Add(keys[3], value)
+	cache.Add(ResponseKey("test", 5), value)
+
+	if _, ok := cache.Get(keys[1]); ok {
+		t.Errorf("least recently used response not evicted")
+	}
+	for _, key := range []string{keys[0], keys[2], keys[3]} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("response %s evicted", key)
+		}
+	}
+	if cache.size > cache.limit {
+		t.Errorf("cache size %d above limit %d", cache.size, cache.limit)
+	}
+	// Responses above a quarter of the limit are not cached
+	cache.Add(ResponseKey("test", 6), strings.Repeat("x", 300))
+	if _, ok := cache.Get(ResponseKey("test", 6)); ok {
+		t.Errorf("oversized response cached")
+	}
+	// A nil cache caches nothing
+	var disabled *ResponseCache
+	disabled.Add(keys[0], value)
+	if _, ok := disabled.Get(keys[0]); ok {
+		t.Errorf("disabled cache returned a response")
+	}
+}
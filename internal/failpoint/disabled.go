@@ -0,0 +1,35 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !failpoints
+// +build !failpoints
+
+package failpoint
+
+// Enabled reports whether failpoints are compiled into this build.
+const Enabled = false
+
+// Inject is a no-op in builds without failpoints.
+func Inject(name string) error { return nil }
+
+// Enable fails with ErrDisabled in builds without failpoints.
+func Enable(name, action string) error { return ErrDisabled }
+
+// Disable fails with ErrDisabled in builds without failpoints.
+func Disable(name string) error { return ErrDisabled }
+
+// List returns no failpoints in builds without failpoints.
+func List() map[string]string { return nil }
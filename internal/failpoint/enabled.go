@@ -0,0 +1,132 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build failpoints
+// +build failpoints
+
+package failpoint
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// Enabled reports whether failpoints are compiled into this build.
+const Enabled = true
+
+// action is the fault injected when reaching an armed failpoint.
+type action struct {
+	spec  string        // Action as configured, for listing
+	kind  string        // One of panic, sleep, error or partial
+	delay time.Duration // Delay of sleep actions
+}
+
+var (
+	lock   sync.RWMutex
+	points = make(map[string]*action)
+)
+
+// known is the set of failpoints that can be armed.
+var known = map[string]bool{
+	ProcessBlock:  true,
+	StateCommit:   true,
+	FreezerCommit: true,
+}
+
+// parseAction parses an action specification.
+func parseAction(spec string) (*action, error) {
+	switch {
+	case spec == "panic", spec == "error", spec == "partial":
+		return &action{spec: spec, kind: spec}, nil
+	case strings.HasPrefix(spec, "sleep(") && strings.HasSuffix(spec, ")"):
+		delay, err := time.ParseDuration(spec[len("sleep(") : len(spec)-1])
+		if err != nil || delay < 0 {
+			return nil, fmt.Errorf("invalid failpoint delay %q", spec)
+		}
+		return &action{spec: spec, kind: "sleep", delay: delay}, nil
+	}
+	return nil, fmt.Errorf("unknown failpoint action %q", spec)
+}
+
+// Inject executes the action armed at the named failpoint, if any. Panics and
+// delays happen inside Inject, while the error and partial actions are returned
+// for the caller to fail accordingly.
+func Inject(name string) error {
+	lock.RLock()
+	act := points[name]
+	lock.RUnlock()
+
+	if act == nil {
+		return nil
+	}
+	log.Warn("Failpoint triggered", "name", name, "action", act.spec)
+	switch act.kind {
+	case "panic":
+		panic(fmt.Sprintf("failpoint %s triggered", name))
+	case "sleep":
+		time.Sleep(act.delay)
+	case "error":
+		return ErrInjected
+	case "partial":
+		return ErrPartialWrite
+	}
+	return nil
+}
+
+// Enable arms the named failpoint with the given action.
+func Enable(name, spec string) error {
+	if !known[name] {
+		return fmt.Errorf("unknown failpoint %q", name)
+	}
+	act, err := parseAction(spec)
+	if err != nil {
+		return err
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
+	points[name] = act
+	log.Warn("Failpoint armed", "name", name, "action", spec)
+	return nil
+}
+
+// Disable disarms the named failpoint.
+func Disable(name string) error {
+	if !known[name] {
+		return fmt.Errorf("unknown failpoint %q", name)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
+	delete(points, name)
+	return nil
+}
+
+// List returns the armed failpoints and their actions.
+func List() map[string]string {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	list := make(map[string]string, len(points))
+	for name, act := range points {
+		list[name] = act.spec
+	}
+	return list
+}
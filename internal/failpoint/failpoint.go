@@ -0,0 +1,52 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package failpoint implements fault injection hooks for chaos testing the
+// block commit pipeline.
+//
+// Failpoints are named sites in the code calling Inject. They are compiled in
+// only when building with the "failpoints" tag, otherwise Inject is a no-op the
+// compiler removes. In test builds, each failpoint can be armed with one of the
+// following actions, e.g. through the admin_setFailpoint RPC method:
+//
+//	panic         panic at the failpoint, simulating a crash
+//	sleep(<dur>)  delay execution for the given duration, e.g. sleep(500ms)
+//	error         fail the operation with ErrInjected
+//	partial       fail a write after persisting only part of its data
+//
+// Sites that cannot partially complete treat partial like error.
+package failpoint
+
+import "errors"
+
+var (
+	// ErrInjected is returned by Inject for failpoints armed with "error".
+	ErrInjected = errors.New("failpoint: injected error")
+
+	// ErrPartialWrite is returned by Inject for failpoints armed with "partial".
+	ErrPartialWrite = errors.New("failpoint: injected partial write")
+
+	// ErrDisabled is returned when arming failpoints in builds without the
+	// failpoints tag.
+	ErrDisabled = errors.New("failpoint: not supported in this build, rebuild with -tags failpoints")
+)
+
+// Failpoints in the block commit pipeline.
+const (
+	ProcessBlock  = "core/process-block"   // Before executing the transactions of a block
+	StateCommit   = "core/state-commit"    // Before committing the post state of a block
+	FreezerCommit = "rawdb/freezer-commit" // Before flushing a batch of freezer table writes
+)
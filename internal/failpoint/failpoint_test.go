@@ -0,0 +1,76 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build failpoints
+// +build failpoints
+
+package failpoint
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailpoints(t *testing.T) {
+	defer Disable(StateCommit)
+
+	if err := Inject(StateCommit); err != nil {
+		t.Fatalf("disarmed failpoint failed: %v", err)
+	}
+	if err := Enable("unknown", "panic"); err == nil {
+		t.Fatalf("unknown failpoint armed")
+	}
+	for _, spec := range []string{"", "crash", "sleep(forever)", "sleep(-1s)"} {
+		if err := Enable(StateCommit, spec); err == nil {
+			t.Errorf("invalid action %q accepted", spec)
+		}
+	}
+	// Errors are returned to the caller
+	for spec, want := range map[string]error{"error": ErrInjected, "partial": ErrPartialWrite} {
+		if err := Enable(StateCommit, spec); err != nil {
+			t.Fatalf("failed to arm failpoint with %q: %v", spec, err)
+		}
+		if err := Inject(StateCommit); err != want {
+			t.Errorf("action %q: error mismatch: have %v, want %v", spec, err, want)
+		}
+	}
+	// Delays block the caller
+	if err := Enable(StateCommit, "sleep(50ms)"); err != nil {
+		t.Fatalf("failed to arm failpoint: %v", err)
+	}
+	if start := time.Now(); Inject(StateCommit) != nil || time.Since(start) < 50*time.Millisecond {
+		t.Errorf("delay not injected")
+	}
+	// Panics unwind the caller
+	if err := Enable(StateCommit, "panic"); err != nil {
+		t.Fatalf("failed to arm failpoint: %v", err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("panic not injected")
+			}
+		}()
+		Inject(StateCommit)
+	}()
+	if list := List(); len(list) != 1 || list[StateCommit] != "panic" {
+		t.Errorf("armed failpoints mismatch: have %v", list)
+	}
+	Disable(StateCommit)
+	if err := Inject(StateCommit); err != nil {
+		t.Fatalf("disarmed failpoint failed: %v", err)
+	}
+}
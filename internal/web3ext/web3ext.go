@@ -30,6 +30,7 @@ var Modules = map[string]string{
 	"txpool":   TxpoolJs,
 	"les":      LESJs,
 	"vflux":    VfluxJs,
+	"scroll":   ScrollJs,
 }
 
 const CliqueJs = `
@@ -189,6 +190,21 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'pauseTxs',
+			call: 'admin_pauseTxs',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'unpauseTxs',
+			call: 'admin_unpauseTxs',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'closeRPCConnection',
+			call: 'admin_closeRPCConnection',
+			params: 1
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -203,6 +219,22 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'snapshotProgress',
+			getter: 'admin_snapshotProgress'
+		}),
+		new web3._extend.Property({
+			name: 'pausedTxs',
+			getter: 'admin_pausedTxs'
+		}),
+		new web3._extend.Property({
+			name: 'nodeConfig',
+			getter: 'admin_nodeConfig'
+		}),
+		new web3._extend.Property({
+			name: 'rpcPeers',
+			getter: 'admin_rpcPeers'
+		}),
 	]
 });
 `
@@ -448,6 +480,27 @@ web3._extend({
 			call: 'debug_storageRangeAt',
 			params: 5,
 		}),
+		new web3._extend.Method({
+			name: 'getBlockAccessList',
+			call: 'debug_getBlockAccessList',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter],
+		}),
+		new web3._extend.Method({
+			name: 'profileBlockImport',
+			call: 'debug_profileBlockImport',
+			params: 2,
+			inputFormatter: [null, null],
+		}),
+		new web3._extend.Method({
+			name: 'stateAccessStats',
+			call: 'debug_stateAccessStats',
+		}),
+		new web3._extend.Method({
+			name: 'getStaleState',
+			call: 'debug_getStaleState',
+			params: 3,
+		}),
 		new web3._extend.Method({
 			name: 'getModifiedAccountsByNumber',
 			call: 'debug_getModifiedAccountsByNumber',
@@ -545,6 +598,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, function (val) { return !!val; }]
 		}),
+		new web3._extend.Method({
+			name: 'getLogsPaginated',
+			call: 'eth_getLogsPaginated',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransaction',
 			call: 'eth_getRawTransactionByHash',
@@ -564,6 +623,17 @@ web3._extend({
 			params: 3,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getCodeHashes',
+			call: 'eth_getCodeHashes',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getCodeByHash',
+			call: 'eth_getCodeByHash',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'createAccessList',
 			call: 'eth_createAccessList',
@@ -613,6 +683,11 @@ web3._extend({
 			name: 'stop',
 			call: 'miner_stop'
 		}),
+		new web3._extend.Method({
+			name: 'addL1FeeRefunds',
+			call: 'miner_addL1FeeRefunds',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'setEtherbase',
 			call: 'miner_setEtherbase',
@@ -636,6 +711,12 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.utils.fromDecimal]
 		}),
+		new web3._extend.Method({
+			name: 'setGasLimitRamp',
+			call: 'miner_setGasLimitRamp',
+			params: 2,
+			inputFormatter: [web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
 		new web3._extend.Method({
 			name: 'setRecommitInterval',
 			call: 'miner_setRecommitInterval',
@@ -848,3 +929,146 @@ web3._extend({
 	]
 });
 `
+
+const ScrollJs = `
+web3._extend({
+	property: 'scroll',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'getBlockTraceByNumberOrHash',
+			call: 'scroll_getBlockTraceByNumberOrHash',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'getInternalTransactions',
+			call: 'scroll_getInternalTransactions',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getBlockCommitments',
+			call: 'scroll_getBlockCommitments',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getWithdrawalProof',
+			call: 'scroll_getWithdrawalProof',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'getL2BlocksByRange',
+			call: 'scroll_getL2BlocksByRange',
+			params: 2,
+			inputFormatter: [web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'getBlockStats',
+			call: 'scroll_getBlockStats',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getStateSize',
+			call: 'scroll_getStateSize',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getStateMigration',
+			call: 'scroll_getStateMigration',
+		}),
+		new web3._extend.Method({
+			name: 'getEquivocationEvidence',
+			call: 'scroll_getEquivocationEvidence',
+			params: 2,
+			inputFormatter: [web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'getL1Origin',
+			call: 'scroll_getL1Origin',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getExtraData',
+			call: 'scroll_getExtraData',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getTransactionStatus',
+			call: 'scroll_getTransactionStatus',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getNonceGaps',
+			call: 'scroll_getNonceGaps',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'sendRawTransactions',
+			call: 'scroll_sendRawTransactions',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'sendRawTransactionWithEstimate',
+			call: 'scroll_sendRawTransactionWithEstimate',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'txIndexProgress',
+			call: 'scroll_txIndexProgress',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getContractCreation',
+			call: 'scroll_getContractCreation',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getNonceKey',
+			call: 'scroll_getNonceKey',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getChainSpec',
+			call: 'scroll_getChainSpec',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'multicall',
+			call: 'scroll_multicall',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'getProofs',
+			call: 'scroll_getProofs',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'feeStats',
+			call: 'scroll_feeStats',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'topGasConsumers',
+			call: 'scroll_topGasConsumers',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'stateGrowthByContract',
+			call: 'scroll_stateGrowthByContract',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+	],
+	properties: []
+});
+`
@@ -287,6 +287,10 @@ func (s *LightDummyAPI) Mining() bool {
 func (s *LightEthereum) APIs() []rpc.API {
 	apis := ethapi.GetAPIs(s.ApiBackend)
 	apis = append(apis, s.engine.APIs(s.BlockChain().HeaderChain())...)
+	limits := filters.LogQueryLimits{
+		BlockRange: s.config.RPCLogQueryRange,
+		Results:    s.config.RPCLogQueryLimit,
+	}
 	return append(apis, []rpc.API{
 		{
 			Namespace: "eth",
@@ -301,7 +305,7 @@ func (s *LightEthereum) APIs() []rpc.API {
 		}, {
 			Namespace: "eth",
 			Version:   "1.0",
-			Service:   filters.NewPublicFilterAPI(s.ApiBackend, true, 5*time.Minute),
+			Service:   filters.NewPublicFilterAPI(s.ApiBackend, true, 5*time.Minute, limits),
 			Public:    true,
 		}, {
 			Namespace: "net",
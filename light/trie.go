@@ -93,6 +93,10 @@ func (db *odrDatabase) TrieDB() *trie.Database {
 	return nil
 }
 
+func (db *odrDatabase) Commitment() state.Commitment {
+	return state.MPTCommitment
+}
+
 type odrTrie struct {
 	db   *odrDatabase
 	id   *TrieID
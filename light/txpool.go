@@ -70,6 +70,8 @@ type TxPool struct {
 
 	istanbul bool // Fork indicator whether we are in the istanbul stage.
 	eip2718  bool // Fork indicator whether we are in the eip2718 stage.
+
+	compressedL1Fee bool // Fork indicator whether the L1 fee is charged by the compressed size.
 }
 
 // TxRelayBackend provides an interface to the mechanism that forwards transacions
@@ -319,6 +321,7 @@ func (pool *TxPool) setNewHead(head *types.Header) {
 	next := new(big.Int).Add(head.Number, big.NewInt(1))
 	pool.istanbul = pool.config.IsIstanbul(next)
 	pool.eip2718 = pool.config.IsBerlin(next)
+	pool.compressedL1Fee = pool.config.Scroll.IsCompressedL1Fee(next)
 }
 
 // Stop stops the light transaction pool
@@ -380,8 +383,8 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 	}
 
 	// Transactor should have enough funds to cover the costs
-	// cost == V + GP * GL
-	if b := currentState.GetBalance(from); b.Cmp(tx.Cost()) < 0 {
+	// cost == V + GP * GL, or V if sponsored
+	if b := currentState.GetBalance(from); b.Cmp(tx.SenderCost()) < 0 {
 		return core.ErrInsufficientFunds
 	}
 
@@ -406,7 +409,7 @@ func (pool *TxPool) add(ctx context.Context, tx *types.Transaction) error {
 	}
 
 	if pool.config.Scroll.FeeVaultEnabled() {
-		if err := fees.VerifyFee(pool.signer, tx, pool.currentState(ctx)); err != nil {
+		if err := fees.VerifyFee(pool.signer, tx, pool.currentState(ctx), pool.compressedL1Fee); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,118 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common/ntp"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+const (
+	clockCheckInterval = 5 * time.Minute // Time between two NTP drift measurements
+	clockChecks        = 3               // Number of measurements to do against the NTP server
+)
+
+var (
+	clockDriftGauge      = metrics.NewRegisteredGauge("miner/clock/drift", nil)
+	clockCheckFailMeter  = metrics.NewRegisteredMeter("miner/clock/check/fail", nil)
+	clockSkewRefuseMeter = metrics.NewRegisteredMeter("miner/clock/skew/refused", nil)
+)
+
+// clockMonitor keeps track of the local clock drift against an NTP server so
+// that the sealer can refuse to assemble blocks with timestamps that the rest
+// of the network would consider wrong.
+type clockMonitor struct {
+	maxSkew time.Duration
+	server  string
+
+	drift int64 // Last measured drift of the local clock in nanoseconds (atomic)
+
+	measure func() (time.Duration, error) // Drift measurement, replaceable in tests
+	now     func() time.Time              // Local wall clock, replaceable in tests
+}
+
+// newClockMonitor creates a clock monitor tolerating the given skew. If server
+// is empty no drift measurements are done and the local clock is trusted.
+func newClockMonitor(maxSkew time.Duration, server string) *clockMonitor {
+	m := &clockMonitor{
+		maxSkew: maxSkew,
+		server:  server,
+		now:     time.Now,
+	}
+	m.measure = func() (time.Duration, error) { return ntp.Drift(m.server, clockChecks) }
+	return m
+}
+
+// loop periodically measures the local clock drift until exit is closed.
+func (m *clockMonitor) loop(exit chan struct{}) {
+	ticker := time.NewTicker(clockCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		m.check()
+		select {
+		case <-ticker.C:
+		case <-exit:
+			return
+		}
+	}
+}
+
+// check measures the local clock drift once and records the result.
+func (m *clockMonitor) check() {
+	drift, err := m.measure()
+	if err != nil {
+		clockCheckFailMeter.Mark(1)
+		log.Debug("Failed to measure clock drift", "server", m.server, "err", err)
+		return
+	}
+	atomic.StoreInt64(&m.drift, int64(drift))
+	clockDriftGauge.Update(drift.Milliseconds())
+
+	if drift < -m.maxSkew || drift > m.maxSkew {
+		log.Warn("System clock drift exceeds the allowed skew, block production halted", "drift", drift, "allowed", m.maxSkew)
+	} else {
+		log.Debug("Clock drift check done", "drift", drift)
+	}
+}
+
+// lastDrift returns the last measured drift of the local clock.
+func (m *clockMonitor) lastDrift() time.Duration {
+	return time.Duration(atomic.LoadInt64(&m.drift))
+}
+
+// verify checks that a block with the given timestamp may be assembled, i.e.
+// that both the local clock and the timestamp are within the allowed skew of
+// the drift corrected wall clock.
+func (m *clockMonitor) verify(timestamp uint64) error {
+	drift := m.lastDrift()
+	if drift < -m.maxSkew || drift > m.maxSkew {
+		clockSkewRefuseMeter.Mark(1)
+		return fmt.Errorf("local clock drift %v exceeds allowed skew %v", drift, m.maxSkew)
+	}
+	skew := time.Unix(int64(timestamp), 0).Sub(m.now().Add(-drift).Truncate(time.Second))
+	if skew < -m.maxSkew || skew > m.maxSkew {
+		clockSkewRefuseMeter.Mark(1)
+		return fmt.Errorf("block timestamp %d is %v off wall clock, allowed skew %v", timestamp, skew, m.maxSkew)
+	}
+	return nil
+}
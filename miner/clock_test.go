@@ -0,0 +1,62 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClockMonitorVerify(t *testing.T) {
+	now := time.Unix(1000, 500)
+	m := newClockMonitor(2*time.Second, "test")
+	m.now = func() time.Time { return now }
+
+	check := func(drift time.Duration, timestamp uint64, ok bool) {
+		t.Helper()
+		m.measure = func() (time.Duration, error) { return drift, nil }
+		m.check()
+		if err := m.verify(timestamp); ok && err != nil {
+			t.Errorf("drift %v, timestamp %d: unexpected error: %v", drift, timestamp, err)
+		} else if !ok && err == nil {
+			t.Errorf("drift %v, timestamp %d: expected error", drift, timestamp)
+		}
+	}
+	// Healthy clock, timestamps around wall clock
+	check(0, 1000, true)
+	check(0, 1002, true)
+	check(0, 998, true)
+	check(0, 1003, false)
+	check(0, 997, false)
+
+	// Drifting clock, timestamps are checked against the corrected time
+	check(time.Second, 998, true)
+	check(time.Second, 1002, false)
+	check(-time.Second, 1002, true)
+
+	// Clock drift beyond the allowed skew halts block production
+	check(3*time.Second, 997, false)
+	check(-3*time.Second, 1003, false)
+
+	// Failed measurements keep the last known drift
+	m.measure = func() (time.Duration, error) { return 0, errors.New("timeout") }
+	m.check()
+	if drift := m.lastDrift(); drift != -3*time.Second {
+		t.Errorf("drift mismatch after failed check: have %v, want %v", drift, -3*time.Second)
+	}
+}
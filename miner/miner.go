@@ -52,6 +52,13 @@ type Config struct {
 	GasPrice   *big.Int       // Minimum gas price for mining a transaction
 	Recommit   time.Duration  // The time interval for miner to re-create mining work.
 	Noverify   bool           // Disable remote mining solution verification(only useful in ethash).
+
+	GasLimitDelta   uint64        // Maximum gas limit change between blocks while moving towards the gas ceiling (0 = consensus bound)
+	PayloadRecommit time.Duration // Interval to improve the blocks built through the consensus API with new transactions (0 = disabled)
+	PendingRecommit time.Duration // Interval to re-execute the pending block on head or pool changes (0 = disabled)
+
+	MaxClockSkew time.Duration // Maximum allowed distance between block timestamps and wall clock (0 = unchecked).
+	NTPServer    string        // NTP server used to measure local clock drift (empty = trust the local clock).
 }
 
 // Miner creates blocks and searches for proof-of-work values.
@@ -181,6 +188,32 @@ func (miner *Miner) SetExtra(extra []byte) error {
 	return nil
 }
 
+// SystemTxSource supplies the system transactions to insert at the top of the
+// block with the given header, e.g. an update of the L1 base fee.
+type SystemTxSource func(header *types.Header) types.Transactions
+
+// AddSystemTxSource adds a source of the system transactions the sequencer
+// inserts at the top of its blocks.
+func (miner *Miner) AddSystemTxSource(source SystemTxSource) {
+	miner.worker.addSystemTxSource(source)
+}
+
+// SystemTxs returns the system transactions the sources supply for the block
+// with the given header, for blocks assembled outside of the miner.
+func (miner *Miner) SystemTxs(header *types.Header) types.Transactions {
+	miner.worker.mu.RLock()
+	sources := miner.worker.systemTxs
+	miner.worker.mu.RUnlock()
+
+	return systemTxsOf(miner.worker.chainConfig, header, sources)
+}
+
+// VerifyTimestamp checks that a block with the given header may be assembled on
+// top of parent with the local clock.
+func (miner *Miner) VerifyTimestamp(parent, header *types.Header) error {
+	return miner.worker.verifyTimestamp(parent, header)
+}
+
 // SetRecommitInterval sets the interval for sealing work resubmitting.
 func (miner *Miner) SetRecommitInterval(interval time.Duration) {
 	miner.worker.setRecommitInterval(interval)
@@ -216,6 +249,22 @@ func (miner *Miner) SetGasCeil(ceil uint64) {
 	miner.worker.setGasCeil(ceil)
 }
 
+// SetGasLimitRamp sets the gaslimit to strive for when mining blocks, moving
+// towards it by at most the given delta per block (0 = consensus bound). A gas
+// limit schedule of the chain config takes precedence over the target.
+func (miner *Miner) SetGasLimitRamp(ceil uint64, delta uint64) {
+	miner.worker.setGasLimitRamp(ceil, delta)
+}
+
+// GasLimitRamp returns the gaslimit to strive for when mining blocks and the
+// maximum change of the gas limit between blocks while moving towards it.
+func (miner *Miner) GasLimitRamp() (uint64, uint64) {
+	miner.worker.mu.RLock()
+	defer miner.worker.mu.RUnlock()
+
+	return miner.worker.config.GasCeil, miner.worker.config.GasLimitDelta
+}
+
 // EnablePreseal turns on the preseal mining feature. It's enabled by default.
 // Note this function shouldn't be exposed to API, it's unnecessary for users
 // (miners) to actually know the underlying detail. It's only for outside project
@@ -238,3 +287,15 @@ func (miner *Miner) DisablePreseal() {
 func (miner *Miner) SubscribePendingLogs(ch chan<- []*types.Log) event.Subscription {
 	return miner.worker.pendingLogsFeed.Subscribe(ch)
 }
+
+// SubscribeAssembledTxs starts delivering every transaction applied to a block
+// being assembled, before the block is sealed.
+func (miner *Miner) SubscribeAssembledTxs(ch chan<- core.AssembledTxEvent) event.Subscription {
+	return miner.worker.assembledTxFeed.Subscribe(ch)
+}
+
+// PostAssembledTx delivers a transaction applied to a block being assembled
+// outside of the miner, like through the consensus API, to the subscribers.
+func (miner *Miner) PostAssembledTx(ev core.AssembledTxEvent) {
+	miner.worker.assembledTxFeed.Send(ev)
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+// minPendingRecommit is the minimal interval of re-executing the pending block.
+const minPendingRecommit = 100 * time.Millisecond
+
+var pendingMaterializeTimer = metrics.NewRegisteredTimer("miner/pending/materialize", nil)
+
+// pendingLoop is a standalone goroutine materializing the pending state. The
+// sealing work only picks new transactions up at the recommit interval, and is
+// not updated at all if it would be empty or once the block is being sealed, so
+// the pending block and state served to the RPC APIs lag the head and the pool.
+// Instead, the candidate next block is re-executed in the background with all
+// executable pool transactions whenever the head or the pool changed, at most
+// once per interval.
+func (w *worker) pendingLoop(interval time.Duration) {
+	defer w.wg.Done()
+
+	txsCh := make(chan core.NewTxsEvent, txChanSize)
+	txsSub := w.eth.TxPool().SubscribeNewTxsEvent(txsCh)
+	defer txsSub.Unsubscribe()
+
+	headCh := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	headSub := w.chain.SubscribeChainHeadEvent(headCh)
+	defer headSub.Unsubscribe()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	dirty := true
+	for {
+		select {
+		case <-txsCh:
+			dirty = true
+		case <-headCh:
+			dirty = true
+		case <-ticker.C:
+			if dirty {
+				w.materializePending()
+				dirty = false
+			}
+		case <-w.exitCh:
+			return
+		case <-txsSub.Err():
+			return
+		case <-headSub.Err():
+			return
+		}
+	}
+}
+
+// materializePending executes the candidate next block on top of the current
+// head with all executable pool transactions, publishing it as the pending block
+// and state unless the head changed in the meantime.
+func (w *worker) materializePending() {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	start := time.Now()
+	parent := w.chain.CurrentBlock()
+	header := w.makeHeader(parent, start.Unix())
+	if w.isRunning() {
+		header.Coinbase = w.coinbase
+	}
+	if err := w.engine.Prepare(w.chain, header); err != nil {
+		log.Debug("Failed to prepare pending block", "err", err)
+		return
+	}
+	env, err := w.makeEnv(parent, header)
+	if err != nil {
+		log.Debug("Failed to create pending block context", "err", err)
+		return
+	}
+	env.materialized = true
+	w.commitSystemTxs(env, w.systemTxs, header.Coinbase)
+	w.commitPending(env, w.eth.TxPool().Pending(true), header.Coinbase, nil)
+
+	// A pending block of a stale head would overwrite the pending block of the
+	// new one the sealing work already published
+	w.snapshotMu.RLock()
+	stale := w.chain.CurrentBlock().Hash() != parent.Hash() ||
+		(w.snapshotBlock != nil && w.snapshotBlock.NumberU64() > header.Number.Uint64())
+	w.snapshotMu.RUnlock()
+	if stale {
+		return
+	}
+	w.updateSnapshot(env)
+	pendingMaterializeTimer.UpdateSince(start)
+	log.Trace("Materialized pending block", "number", header.Number, "txs", env.tcount, "gas", header.GasUsed, "elapsed", common.PrettyDuration(time.Since(start)))
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/event"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/l1origin"
 	"github.com/scroll-tech/go-ethereum/trie"
 )
 
@@ -92,6 +93,8 @@ type environment struct {
 	header   *types.Header
 	txs      []*types.Transaction
 	receipts []*types.Receipt
+
+	materialized bool // Whether the block only materializes the pending state, without announcing logs
 }
 
 // task contains all information for consensus engine sealing and result submitting.
@@ -132,6 +135,7 @@ type worker struct {
 
 	// Feeds
 	pendingLogsFeed event.Feed
+	assembledTxFeed event.Feed // Transactions applied to the blocks being sealed
 
 	// Subscriptions
 	mux          *event.TypeMux
@@ -157,10 +161,12 @@ type worker struct {
 	localUncles  map[common.Hash]*types.Block // A set of side blocks generated locally as the possible uncle blocks.
 	remoteUncles map[common.Hash]*types.Block // A set of side blocks as the possible uncle blocks.
 	unconfirmed  *unconfirmedBlocks           // A set of locally mined blocks pending canonicalness confirmations.
+	clock        *clockMonitor                // Clock skew guard for assembled blocks (nil = disabled).
 
-	mu       sync.RWMutex // The lock used to protect the coinbase and extra fields
-	coinbase common.Address
-	extra    []byte
+	mu        sync.RWMutex // The lock used to protect the coinbase and extra fields
+	coinbase  common.Address
+	extra     []byte
+	systemTxs []SystemTxSource // Sources of the system transactions to insert
 
 	pendingMu    sync.RWMutex
 	pendingTasks map[common.Hash]*task
@@ -228,12 +234,34 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		recommit = minRecommitInterval
 	}
 
+	// Guard block timestamps against local clock skew if requested.
+	if config.MaxClockSkew > 0 {
+		worker.clock = newClockMonitor(config.MaxClockSkew, config.NTPServer)
+		if config.NTPServer != "" {
+			worker.wg.Add(1)
+			go func() {
+				defer worker.wg.Done()
+				worker.clock.loop(worker.exitCh)
+			}()
+		}
+	}
+
 	worker.wg.Add(4)
 	go worker.mainLoop()
 	go worker.newWorkLoop(recommit)
 	go worker.resultLoop()
 	go worker.taskLoop()
 
+	// Materialize the pending state in the background while sealing if requested.
+	if interval := config.PendingRecommit; interval > 0 {
+		if interval < minPendingRecommit {
+			log.Warn("Sanitizing miner pending recommit interval", "provided", interval, "updated", minPendingRecommit)
+			interval = minPendingRecommit
+		}
+		worker.wg.Add(1)
+		go worker.pendingLoop(interval)
+	}
+
 	// Submit first work to initialize pending state.
 	if init {
 		worker.startCh <- struct{}{}
@@ -254,6 +282,15 @@ func (w *worker) setGasCeil(ceil uint64) {
 	w.config.GasCeil = ceil
 }
 
+// setGasLimitRamp sets the gas limit to ramp to and the maximum change of the
+// gas limit between blocks while ramping.
+func (w *worker) setGasLimitRamp(ceil uint64, delta uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.config.GasCeil = ceil
+	w.config.GasLimitDelta = delta
+}
+
 // setExtra sets the content used to initialize the block extra field.
 func (w *worker) setExtra(extra []byte) {
 	w.mu.Lock()
@@ -261,6 +298,14 @@ func (w *worker) setExtra(extra []byte) {
 	w.extra = extra
 }
 
+// addSystemTxSource adds a source of the system transactions inserted at the
+// top of the blocks.
+func (w *worker) addSystemTxSource(source SystemTxSource) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.systemTxs = append(w.systemTxs, source)
+}
+
 // setRecommitInterval updates the interval for miner sealing work recommitting.
 func (w *worker) setRecommitInterval(interval time.Duration) {
 	w.resubmitIntervalCh <- interval
@@ -526,11 +571,11 @@ func (w *worker) mainLoop() {
 				}
 				txset := types.NewTransactionsByPriceAndNonce(w.current.signer, txs, w.current.header.BaseFee)
 				tcount := w.current.tcount
-				w.commitTransactions(txset, coinbase, nil)
+				w.commitTransactions(w.current, txset, coinbase, nil)
 				// Only update the snapshot if any new transactons were added
 				// to the pending block
 				if tcount != w.current.tcount {
-					w.updateSnapshot()
+					w.updateSnapshot(w.current)
 				}
 			} else {
 				// Special case, if the consensus engine is 0 period clique(dev mode),
@@ -681,14 +726,32 @@ func (w *worker) resultLoop() {
 
 // makeCurrent creates a new environment for the current cycle.
 func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
-	// Retrieve the parent state to execute on top and start a prefetcher for
-	// the miner to speed block sealing up a bit
-	state, err := w.chain.StateAt(parent.Root())
+	env, err := w.makeEnv(parent, header)
 	if err != nil {
 		return err
 	}
-	state.StartPrefetcher("miner")
+	// Start a prefetcher for the miner to speed block sealing up a bit
+	env.state.StartPrefetcher("miner")
+
+	// Swap out the old work with the new one, terminating any leftover prefetcher
+	// processes in the mean time and starting a new one.
+	if w.current != nil && w.current.state != nil {
+		w.current.state.StopPrefetcher()
+	}
+	w.current = env
+	return nil
+}
 
+// makeEnv creates a new environment executing a block on top of the given parent.
+func (w *worker) makeEnv(parent *types.Block, header *types.Header) (*environment, error) {
+	// Retrieve the parent state to execute on top
+	state, err := w.chain.StateAtChild(parent.Header())
+	if err != nil {
+		return nil, err
+	}
+	if w.chain.CacheConfig().RecordStateSize {
+		state.StartSizeTracking()
+	}
 	env := &environment{
 		signer:    types.MakeSigner(w.chainConfig, header.Number),
 		state:     state,
@@ -707,14 +770,7 @@ func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
 	}
 	// Keep track of transactions which return errors so they can be removed
 	env.tcount = 0
-
-	// Swap out the old work with the new one, terminating any leftover prefetcher
-	// processes in the mean time and starting a new one.
-	if w.current != nil && w.current.state != nil {
-		w.current.state.StopPrefetcher()
-	}
-	w.current = env
-	return nil
+	return env, nil
 }
 
 // commitUncle adds the given block to uncle block set, returns error if failed to add.
@@ -737,13 +793,13 @@ func (w *worker) commitUncle(env *environment, uncle *types.Header) error {
 }
 
 // updateSnapshot updates pending snapshot block and state.
-// Note this function assumes the current variable is thread safe.
-func (w *worker) updateSnapshot() {
+// Note this function assumes the given environment is thread safe.
+func (w *worker) updateSnapshot(env *environment) {
 	w.snapshotMu.Lock()
 	defer w.snapshotMu.Unlock()
 
 	var uncles []*types.Header
-	w.current.uncles.Each(func(item interface{}) bool {
+	env.uncles.Each(func(item interface{}) bool {
 		hash, ok := item.(common.Hash)
 		if !ok {
 			return false
@@ -760,39 +816,96 @@ func (w *worker) updateSnapshot() {
 	})
 
 	w.snapshotBlock = types.NewBlock(
-		w.current.header,
-		w.current.txs,
+		env.header,
+		env.txs,
 		uncles,
-		w.current.receipts,
+		env.receipts,
 		trie.NewStackTrie(nil),
 	)
-	w.snapshotReceipts = copyReceipts(w.current.receipts)
-	w.snapshotState = w.current.state.Copy()
+	w.snapshotReceipts = copyReceipts(env.receipts)
+	w.snapshotState = env.state.Copy()
 }
 
-func (w *worker) commitTransaction(tx *types.Transaction, coinbase common.Address) ([]*types.Log, error) {
-	snap := w.current.state.Snapshot()
+func (w *worker) commitTransaction(env *environment, tx *types.Transaction, coinbase common.Address) ([]*types.Log, error) {
+	snap := env.state.Snapshot()
 
-	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &coinbase, w.current.gasPool, w.current.state, w.current.header, tx, &w.current.header.GasUsed, *w.chain.GetVMConfig())
+	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed, *w.chain.GetVMConfig())
 	if err != nil {
-		w.current.state.RevertToSnapshot(snap)
+		env.state.RevertToSnapshot(snap)
 		return nil, err
 	}
-	w.current.txs = append(w.current.txs, tx)
-	w.current.receipts = append(w.current.receipts, receipt)
+	env.txs = append(env.txs, tx)
+	env.receipts = append(env.receipts, receipt)
 
+	// Pending blocks of non-producing nodes are of no interest to the stream
+	if w.isRunning() {
+		w.assembledTxFeed.Send(core.AssembledTxEvent{
+			Number:     env.header.Number.Uint64(),
+			ParentHash: env.header.ParentHash,
+			Index:      len(env.txs) - 1,
+			Tx:         tx,
+			Receipt:    receipt,
+		})
+	}
 	return receipt.Logs, nil
 }
 
-func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coinbase common.Address, interrupt *int32) bool {
-	// Short circuit if current is nil
-	if w.current == nil {
+// systemTxsOf returns the valid system transactions the sources supply for the
+// block with the given header.
+func systemTxsOf(config *params.ChainConfig, header *types.Header, sources []SystemTxSource) types.Transactions {
+	if len(sources) == 0 || !config.Scroll.IsSystemTx(header.Number) {
+		return nil
+	}
+	var txs types.Transactions
+	for _, source := range sources {
+		for _, tx := range source(header) {
+			if !tx.IsSystemTx() {
+				log.Error("Skipping non-system transaction", "hash", tx.Hash(), "type", tx.Type())
+				continue
+			}
+			if err := types.VerifySystemTxs(config, header.Number, types.Transactions{tx}); err != nil {
+				log.Error("Skipping invalid system transaction", "hash", tx.Hash(), "err", err)
+				continue
+			}
+			txs = append(txs, tx)
+		}
+	}
+	return txs
+}
+
+// commitSystemTxs inserts the system transactions supplied by the sources at the
+// top of the given block. Transactions followers would reject are skipped.
+func (w *worker) commitSystemTxs(env *environment, sources []SystemTxSource, coinbase common.Address) {
+	txs := systemTxsOf(w.chainConfig, env.header, sources)
+	if len(txs) == 0 {
+		return
+	}
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	for _, tx := range txs {
+		if !w.chainConfig.Scroll.IsValidTxCount(env.tcount + 1) {
+			log.Error("Transaction count limit reached by system transactions", "have", env.tcount)
+			return
+		}
+		env.state.Prepare(tx.Hash(), env.tcount)
+		if _, err := w.commitTransaction(env, tx, coinbase); err != nil {
+			log.Error("Failed to apply system transaction", "hash", tx.Hash(), "err", err)
+			continue
+		}
+		env.tcount++
+	}
+}
+
+func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByPriceAndNonce, coinbase common.Address, interrupt *int32) bool {
+	// Short circuit if the environment is nil
+	if env == nil {
 		return true
 	}
 
-	gasLimit := w.current.header.GasLimit
-	if w.current.gasPool == nil {
-		w.current.gasPool = new(core.GasPool).AddGas(gasLimit)
+	gasLimit := env.header.GasLimit
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(gasLimit)
 	}
 
 	var coalescedLogs []*types.Log
@@ -807,7 +920,7 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 		if interrupt != nil && atomic.LoadInt32(interrupt) != commitInterruptNone {
 			// Notify resubmit loop to increase resubmitting interval due to too frequent commits.
 			if atomic.LoadInt32(interrupt) == commitInterruptResubmit {
-				ratio := float64(gasLimit-w.current.gasPool.Gas()) / float64(gasLimit)
+				ratio := float64(gasLimit-env.gasPool.Gas()) / float64(gasLimit)
 				if ratio < 0.1 {
 					ratio = 0.1
 				}
@@ -819,13 +932,13 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 			return atomic.LoadInt32(interrupt) == commitInterruptNewHead
 		}
 		// If we have collected enough transactions then we're done
-		if !w.chainConfig.Scroll.IsValidTxCount(w.current.tcount + 1) {
-			log.Trace("Transaction count limit reached", "have", w.current.tcount, "want", w.chainConfig.Scroll.MaxTxPerBlock)
+		if !w.chainConfig.Scroll.IsValidTxCount(env.tcount + 1) {
+			log.Trace("Transaction count limit reached", "have", env.tcount, "want", w.chainConfig.Scroll.MaxTxPerBlock)
 			break
 		}
 		// If we don't have enough gas for any further transactions then we're done
-		if w.current.gasPool.Gas() < params.TxGas {
-			log.Trace("Not enough gas for further transactions", "have", w.current.gasPool, "want", params.TxGas)
+		if env.gasPool.Gas() < params.TxGas {
+			log.Trace("Not enough gas for further transactions", "have", env.gasPool, "want", params.TxGas)
 			break
 		}
 		// Retrieve the next transaction and abort if all done
@@ -837,19 +950,27 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 		// during transaction acceptance is the transaction pool.
 		//
 		// We use the eip155 signer regardless of the current hf.
-		from, _ := types.Sender(w.current.signer, tx)
+		from, _ := types.Sender(env.signer, tx)
 		// Check whether the tx is replay protected. If we're not in the EIP155 hf
 		// phase, start ignoring the sender until we do.
-		if tx.Protected() && !w.chainConfig.IsEIP155(w.current.header.Number) {
+		if tx.Protected() && !w.chainConfig.IsEIP155(env.header.Number) {
 			log.Trace("Ignoring reply protected transaction", "hash", tx.Hash(), "eip155", w.chainConfig.EIP155Block)
 
 			txs.Pop()
 			continue
 		}
+		// Skip the transaction classes paused by the operator, along with the
+		// later transactions of the sender depending on it
+		if w.eth.TxPool().Paused(tx) {
+			log.Trace("Skipping paused transaction", "hash", tx.Hash(), "sender", from)
+
+			txs.Pop()
+			continue
+		}
 		// Start executing the transaction
-		w.current.state.Prepare(tx.Hash(), w.current.tcount)
+		env.state.Prepare(tx.Hash(), env.tcount)
 
-		logs, err := w.commitTransaction(tx, coinbase)
+		logs, err := w.commitTransaction(env, tx, coinbase)
 		switch {
 		case errors.Is(err, core.ErrGasLimitReached):
 			// Pop the current out-of-gas transaction without shifting in the next from the account
@@ -869,7 +990,7 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 		case errors.Is(err, nil):
 			// Everything ok, collect the logs and shift in the next transaction from the same account
 			coalescedLogs = append(coalescedLogs, logs...)
-			w.current.tcount++
+			env.tcount++
 			txs.Shift()
 
 		case errors.Is(err, core.ErrTxTypeNotSupported):
@@ -885,7 +1006,7 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 		}
 	}
 
-	if !w.isRunning() && len(coalescedLogs) > 0 {
+	if !w.isRunning() && !env.materialized && len(coalescedLogs) > 0 {
 		// We don't push the pendingLogsEvent while we are mining. The reason is that
 		// when we are mining, the worker will regenerate a mining block every 3 seconds.
 		// In order to avoid pushing the repeated pendingLog, we disable the pending log pushing.
@@ -908,14 +1029,11 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 	return false
 }
 
-// commitNewWork generates several new sealing tasks based on the parent block.
-func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64) {
-	w.mu.RLock()
-	defer w.mu.RUnlock()
-
-	tstart := time.Now()
-	parent := w.chain.CurrentBlock()
-
+// makeHeader creates the header of the next block on top of the given parent,
+// to be completed with the coinbase and prepared by the consensus engine.
+//
+// Note, this method assumes the worker lock is held.
+func (w *worker) makeHeader(parent *types.Block, timestamp int64) *types.Header {
 	if parent.Time() >= uint64(timestamp) {
 		timestamp = int64(parent.Time() + 1)
 	}
@@ -923,10 +1041,21 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 	header := &types.Header{
 		ParentHash: parent.Hash(),
 		Number:     num.Add(num, common.Big1),
-		GasLimit:   core.CalcGasLimit(parent.GasLimit(), w.config.GasCeil),
 		Extra:      w.extra,
 		Time:       uint64(timestamp),
 	}
+	header.GasLimit = misc.CalcGasLimit(w.chainConfig, header.Number, parent.GasLimit(), w.config.GasCeil, w.config.GasLimitDelta)
+	// Respect the minimum block time if the chain enforces one
+	if min := parent.Time() + w.chainConfig.Scroll.MinBlockTime(header.Number); header.Time < min {
+		header.Time = min
+	}
+	// Commit the transaction shuffle seed in place of the extra-data vanity,
+	// or its leading bytes in the structured extra-data
+	misc.PrepareExtra(w.chainConfig, header)
+	if w.chainConfig.Scroll.IsTxShuffle(header.Number) && !w.chainConfig.Scroll.IsExtraSchema(header.Number) {
+		seed := types.TxShuffleSeed(header.ParentHash)
+		header.Extra = common.CopyBytes(seed[:])
+	}
 	// Set baseFee and GasLimit if we are on an EIP-1559 chain
 	if w.chainConfig.IsLondon(header.Number) {
 		if w.chainConfig.Scroll.BaseFeeEnabled() {
@@ -939,9 +1068,21 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		}
 		if !w.chainConfig.IsLondon(parent.Number()) {
 			parentGasLimit := parent.GasLimit() * params.ElasticityMultiplier
-			header.GasLimit = core.CalcGasLimit(parentGasLimit, w.config.GasCeil)
+			header.GasLimit = misc.CalcGasLimit(w.chainConfig, header.Number, parentGasLimit, w.config.GasCeil, w.config.GasLimitDelta)
 		}
 	}
+	return header
+}
+
+// commitNewWork generates several new sealing tasks based on the parent block.
+func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	tstart := time.Now()
+	parent := w.chain.CurrentBlock()
+	header := w.makeHeader(parent, timestamp)
+
 	// Only set the coinbase if our consensus engine is running (avoid spurious block rewards)
 	if w.isRunning() {
 		if w.coinbase == (common.Address{}) {
@@ -949,11 +1090,22 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 			return
 		}
 		header.Coinbase = w.coinbase
+
+		// Only propose blocks in turn if multiple sequencers rotate
+		if err := misc.SetProposer(w.chainConfig, header, w.coinbase); err != nil {
+			log.Debug("Skipping block proposal", "err", err)
+			return
+		}
 	}
 	if err := w.engine.Prepare(w.chain, header); err != nil {
 		log.Error("Failed to prepare header for mining", "err", err)
 		return
 	}
+	// Refuse to assemble blocks the network would consider badly timed
+	if err := w.verifyTimestamp(parent.Header(), header); err != nil {
+		log.Error("Refusing to assemble block", "number", header.Number, "err", err)
+		return
+	}
 	// If we are care about TheDAO hard-fork check whether to override the extra-data or not
 	if daoBlock := w.chainConfig.DAOForkBlock; daoBlock != nil {
 		// Check whether the block is among the fork extra-override range
@@ -978,6 +1130,16 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 	if w.chainConfig.DAOForkSupport && w.chainConfig.DAOForkBlock != nil && w.chainConfig.DAOForkBlock.Cmp(header.Number) == 0 {
 		misc.ApplyDAOHardFork(env.state)
 	}
+	// Insert the protocol's system transactions ahead of everything else
+	w.commitSystemTxs(env, w.systemTxs, w.coinbase)
+	systemTxs := w.current.tcount
+
+	// Refuse to assemble blocks the rollup contract would reject with their batch
+	if err := l1origin.VerifyTime(w.chainConfig, header, env.txs); err != nil {
+		log.Error("Refusing to assemble block", "number", header.Number, "err", err)
+		return
+	}
+
 	// Accumulate the uncles for the current block
 	uncles := make([]*types.Header, 0, 2)
 	commitUncles := func(blocks map[common.Hash]*types.Block) {
@@ -1015,9 +1177,33 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 	// But if we disable empty precommit already, ignore it. Since
 	// empty block is necessary to keep the liveness of the network.
 	if len(pending) == 0 && atomic.LoadUint32(&w.noempty) == 0 {
-		w.updateSnapshot()
+		w.updateSnapshot(env)
+		return
+	}
+	if w.commitPending(env, pending, w.coinbase, interrupt) {
+		return
+	}
+
+	// do not produce empty blocks
+	if w.current.tcount == systemTxs {
 		return
 	}
+
+	w.commit(uncles, w.fullTaskHook, true, tstart)
+}
+
+// commitPending executes the given pending transactions into the environment,
+// in the order the chain requires, returning whether the execution was aborted
+// by a new head.
+func (w *worker) commitPending(env *environment, pending map[common.Address]types.Transactions, coinbase common.Address, interrupt *int32) bool {
+	header := env.header
+	if w.chainConfig.Scroll.IsTxShuffle(header.Number) {
+		// In shuffled ordering mode locals get no priority, all pending transactions
+		// are ordered together so that followers can verify the ordering.
+		seed := types.TxShuffleSeed(header.ParentHash)
+		txs := types.NewTransactionsByShuffledPriceAndNonce(env.signer, pending, header.BaseFee, seed, w.chainConfig.Scroll.TxShuffleFeeBand())
+		return w.commitTransactions(env, txs, coinbase, interrupt)
+	}
 	// Split the pending transactions into locals and remotes
 	localTxs, remoteTxs := make(map[common.Address]types.Transactions), pending
 	for _, account := range w.eth.TxPool().Locals() {
@@ -1027,24 +1213,29 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		}
 	}
 	if len(localTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, localTxs, header.BaseFee)
-		if w.commitTransactions(txs, w.coinbase, interrupt) {
-			return
+		txs := types.NewTransactionsByPriceAndNonce(env.signer, localTxs, header.BaseFee)
+		if w.commitTransactions(env, txs, coinbase, interrupt) {
+			return true
 		}
 	}
 	if len(remoteTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, remoteTxs, header.BaseFee)
-		if w.commitTransactions(txs, w.coinbase, interrupt) {
-			return
+		txs := types.NewTransactionsByPriceAndNonce(env.signer, remoteTxs, header.BaseFee)
+		if w.commitTransactions(env, txs, coinbase, interrupt) {
+			return true
 		}
 	}
+	return false
+}
 
-	// do not produce empty blocks
-	if w.current.tcount == 0 {
-		return
+// verifyTimestamp checks that the timestamp of the header is close enough to the
+// wall clock if the clock skew guard is enabled, or to the parent's otherwise.
+func (w *worker) verifyTimestamp(parent, header *types.Header) error {
+	// A jump the wall clock vouches for is the sequencer catching up after an
+	// outage rather than a faulty local clock
+	if w.clock != nil {
+		return w.clock.verify(header.Time)
 	}
-
-	w.commit(uncles, w.fullTaskHook, true, tstart)
+	return misc.VerifyTimeJump(w.chainConfig, parent, header)
 }
 
 // commit runs any post-transaction state modifications, assembles the final block
@@ -1074,7 +1265,7 @@ func (w *worker) commit(uncles []*types.Header, interval func(), update bool, st
 		}
 	}
 	if update {
-		w.updateSnapshot()
+		w.updateSnapshot(w.current)
 	}
 	return nil
 }
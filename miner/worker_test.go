@@ -525,3 +525,102 @@ func testAdjustInterval(t *testing.T, chainConfig *params.ChainConfig, engine co
 		t.Error("interval reset timeout")
 	}
 }
+
+// Tests that the worker skips the pooled transactions of a paused class until
+// the pause is lifted.
+func TestPausedTransactions(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	id, err := b.txPool.PauseTxs(core.TxPauseRule{To: &testUserAddress, Expiry: uint64(time.Now().Add(time.Hour).Unix())})
+	if err != nil {
+		t.Fatalf("failed to pause transactions: %v", err)
+	}
+	taskCh := make(chan int, 8)
+	w.newTaskHook = func(task *task) {
+		if task.block.NumberU64() == 1 {
+			taskCh <- len(task.receipts)
+		}
+	}
+	w.skipSealHook = func(task *task) bool { return true }
+	w.start()
+
+	// Only empty blocks are built while paused
+	timeout := time.NewTimer(500 * time.Millisecond)
+	defer timeout.Stop()
+paused:
+	for {
+		select {
+		case receipts := <-taskCh:
+			if receipts != 0 {
+				t.Fatalf("paused transaction included")
+			}
+		case <-timeout.C:
+			break paused
+		}
+	}
+	// The transactions are included once unpaused, as soon as new ones arrive
+	// to trigger a recommit
+	b.txPool.UnpauseTxs(id)
+	b.txPool.AddLocals(newTxs)
+
+	timeout.Reset(5 * time.Second)
+	for {
+		select {
+		case receipts := <-taskCh:
+			if receipts == 2 {
+				return
+			}
+		case <-timeout.C:
+			t.Fatal("unpaused transaction not included")
+		}
+	}
+}
+
+// Tests that the pending state is materialized with the transactions arriving
+// while a block is sealed, which the sealing work only picks up on recommit.
+func TestMaterializedPendingState(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	b.txPool.AddLocals(pendingTxs)
+
+	config := *testConfig
+	config.Recommit = time.Hour
+	config.PendingRecommit = minPendingRecommit
+	w := newWorker(&config, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	w.setEtherbase(testBankAddress)
+	defer w.close()
+
+	taskCh := make(chan struct{}, 8)
+	w.newTaskHook = func(task *task) { taskCh <- struct{}{} }
+	w.skipSealHook = func(task *task) bool { return true }
+	w.start()
+
+	select {
+	case <-taskCh:
+	case <-time.After(3 * time.Second):
+		t.Fatal("new task timeout")
+	}
+	b.txPool.AddLocals(newTxs)
+
+	timeout := time.After(3 * time.Second)
+	for {
+		block, state := w.pending()
+		if block != nil && len(block.Transactions()) == 2 {
+			if balance := state.GetBalance(testUserAddress); balance.Cmp(big.NewInt(2000)) != 0 {
+				t.Fatalf("pending balance mismatch: have %v, want %v", balance, 2000)
+			}
+			return
+		}
+		select {
+		case <-timeout:
+			t.Fatal("pending state not materialized")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
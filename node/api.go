@@ -185,6 +185,8 @@ func (api *privateAdminAPI) StartHTTP(host *string, port *int, cors *string, api
 		CorsAllowedOrigins: api.node.config.HTTPCors,
 		Vhosts:             api.node.config.HTTPVirtualHosts,
 		Modules:            api.node.config.HTTPModules,
+		scheduler:          api.node.rpcScheduler,
+		access:             api.node.rpcAccess,
 	}
 	if cors != nil {
 		config.CorsAllowedOrigins = nil
@@ -256,8 +258,11 @@ func (api *privateAdminAPI) StartWS(host *string, port *int, allowedOrigins *str
 
 	// Determine config.
 	config := wsConfig{
-		Modules: api.node.config.WSModules,
-		Origins: api.node.config.WSOrigins,
+		Modules:   api.node.config.WSModules,
+		Origins:   api.node.config.WSOrigins,
+		scheduler: api.node.rpcScheduler,
+		notify:    api.node.config.notifyLimits(),
+		access:    api.node.rpcAccess,
 		// ExposeAll: api.node.config.WSExposeAll,
 	}
 	if apis != nil {
@@ -295,6 +300,27 @@ func (api *privateAdminAPI) StopWS() (bool, error) {
 	return true, nil
 }
 
+// RpcPeers retrieves the HTTP and WebSocket connections currently served, along
+// with their statistics. HTTP connections are only listed while serving a request.
+func (api *privateAdminAPI) RpcPeers() []*rpc.ConnInfo {
+	conns := make([]*rpc.ConnInfo, 0)
+	for _, server := range api.node.rpcServers() {
+		conns = append(conns, server.Conns()...)
+	}
+	return conns
+}
+
+// CloseRPCConnection closes the HTTP or WebSocket connection with the given ID,
+// as listed by RpcPeers, canceling its running calls.
+func (api *privateAdminAPI) CloseRPCConnection(id string) (bool, error) {
+	for _, server := range api.node.rpcServers() {
+		if server.CloseConn(id) {
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("RPC connection %s not found", id)
+}
+
 // publicAdminAPI is the collection of administrative API methods exposed over
 // both secure and unsecure RPC channels.
 type publicAdminAPI struct {
@@ -0,0 +1,39 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build failpoints
+// +build failpoints
+
+package node
+
+import "github.com/scroll-tech/go-ethereum/internal/failpoint"
+
+// SetFailpoint arms the named failpoint with the given action (panic, error,
+// partial or sleep(<duration>)). Only available in builds with the failpoints
+// tag.
+func (api *privateAdminAPI) SetFailpoint(name string, action string) error {
+	return failpoint.Enable(name, action)
+}
+
+// ClearFailpoint disarms the named failpoint.
+func (api *privateAdminAPI) ClearFailpoint(name string) error {
+	return failpoint.Disable(name)
+}
+
+// Failpoints returns the armed failpoints and their actions.
+func (api *privateAdminAPI) Failpoints() map[string]string {
+	return failpoint.List()
+}
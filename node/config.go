@@ -167,6 +167,16 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// WSNotifyBuffer is the maximum number of notifications queued per websocket
+	// subscription whose subscriber doesn't keep up. Zero writes notifications
+	// right away, blocking the notifying service on slow subscribers.
+	WSNotifyBuffer int `toml:",omitempty"`
+
+	// WSNotifyPolicy is what happens to a subscription whose notification queue
+	// is full: "disconnect" closes the connection of the subscriber, "drop" drops
+	// the oldest queued notification.
+	WSNotifyPolicy string `toml:",omitempty"`
+
 	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
 	// clients. Please be aware that CORS is a browser enforced security, it's fully
 	// useless for custom HTTP clients.
@@ -190,6 +200,53 @@ type Config struct {
 
 	// AllowUnprotectedTxs allows non EIP-155 protected transactions to be send over RPC.
 	AllowUnprotectedTxs bool `toml:",omitempty"`
+
+	// DBEngine is the database engine to use ("leveldb" or "pebble"). If empty,
+	// the engine of an existing database is used, or leveldb for a new one.
+	DBEngine string `toml:",omitempty"`
+
+	// AncientRemote is the object store (s3://bucket/prefix or gs://bucket/prefix)
+	// to offload sealed ancient data files to. If empty, they stay on local disk.
+	AncientRemote string `toml:",omitempty"`
+
+	// AncientRemoteCache is the size in megabytes of the local read cache of the
+	// ancient data offloaded to AncientRemote.
+	AncientRemoteCache int `toml:",omitempty"`
+
+	// RPCWorkers is the maximum number of calls served concurrently over HTTP and
+	// websocket, excluding the calls of the RPCPriorityModules which run on a
+	// dedicated pool. Zero means unbounded.
+	RPCWorkers int `toml:",omitempty"`
+
+	// RPCPriorityModules is the list of API modules whose calls are never queued
+	// behind other calls, for the engine API driving the node.
+	RPCPriorityModules []string `toml:",omitempty"`
+
+	// RPCBatchItems is the maximum number of calls executed from a batch over
+	// HTTP and websocket, the calls beyond it are answered with an error. Zero
+	// means unbounded.
+	RPCBatchItems int `toml:",omitempty"`
+
+	// RPCBatchCost is the maximum cumulative cost of the calls executed from a
+	// batch, where expensive calls like eth_call or traces cost more than one.
+	// Zero means unbounded.
+	RPCBatchCost int `toml:",omitempty"`
+
+	// RPCConnCalls is the maximum number of calls of a single connection served
+	// concurrently, so that a client can't take all the RPCWorkers. Zero means
+	// unbounded.
+	RPCConnCalls int `toml:",omitempty"`
+
+	// RPCAccessRules are the access rules of the calls served over HTTP and
+	// websocket, keyed by the API key or source network of the clients. The first
+	// rule matching a client and listing a method decides whether it's allowed.
+	RPCAccessRules []rpc.AccessRule `toml:",omitempty"`
+}
+
+// notifyLimits returns the limits of the notifications queued per websocket
+// subscription.
+func (c *Config) notifyLimits() rpc.NotifyLimits {
+	return rpc.NotifyLimits{Buffer: c.WSNotifyBuffer, Drop: c.WSNotifyPolicy == "drop"}
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
@@ -45,7 +45,10 @@ var DefaultConfig = Config{
 	HTTPTimeouts:        rpc.DefaultHTTPTimeouts,
 	WSPort:              DefaultWSPort,
 	WSModules:           []string{"net", "web3"},
+	WSNotifyBuffer:      1024,
+	WSNotifyPolicy:      "disconnect",
 	GraphQLVirtualHosts: []string{"localhost"},
+	RPCPriorityModules:  []string{"consensus", "engine"},
 	P2P: p2p.Config{
 		ListenAddr: ":30303",
 		MaxPeers:   50,
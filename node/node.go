@@ -52,12 +52,14 @@ type Node struct {
 	state         int               // Tracks state of node lifecycle
 
 	lock          sync.Mutex
-	lifecycles    []Lifecycle // All registered backends, services, and auxiliary services that have a lifecycle
-	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
-	http          *httpServer //
-	ws            *httpServer //
-	ipc           *ipcServer  // Stores information about the ipc http server
-	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+	lifecycles    []Lifecycle    // All registered backends, services, and auxiliary services that have a lifecycle
+	rpcAPIs       []rpc.API      // List of APIs currently provided by the node
+	http          *httpServer    //
+	ws            *httpServer    //
+	ipc           *ipcServer     // Stores information about the ipc http server
+	inprocHandler *rpc.Server    // In-process RPC request handler to process the API requests
+	rpcScheduler  *rpc.Scheduler // Scheduler of the calls served over HTTP and websocket
+	rpcAccess     *rpc.AccessControl
 
 	databases map[*closeTrackingDB]struct{} // All open databases
 }
@@ -96,10 +98,26 @@ func New(conf *Config) (*Node, error) {
 	if strings.HasSuffix(conf.Name, ".ipc") {
 		return nil, errors.New(`Config.Name cannot end in ".ipc"`)
 	}
+	access, err := rpc.NewAccessControl(conf.RPCAccessRules)
+	if err != nil {
+		return nil, err
+	}
+	switch conf.WSNotifyPolicy {
+	case "", "drop", "disconnect":
+	default:
+		return nil, fmt.Errorf("invalid websocket notification policy %q", conf.WSNotifyPolicy)
+	}
 
+	limits := rpc.BatchLimits{
+		Items:     conf.RPCBatchItems,
+		Cost:      conf.RPCBatchCost,
+		ConnCalls: conf.RPCConnCalls,
+	}
 	node := &Node{
 		config:        conf,
 		inprocHandler: rpc.NewServer(),
+		rpcScheduler:  rpc.NewScheduler(conf.RPCWorkers, conf.RPCPriorityModules, limits),
+		rpcAccess:     access,
 		eventmux:      new(event.TypeMux),
 		log:           conf.Logger,
 		stop:          make(chan struct{}),
@@ -358,6 +376,8 @@ func (n *Node) startRPC() error {
 			Vhosts:             n.config.HTTPVirtualHosts,
 			Modules:            n.config.HTTPModules,
 			prefix:             n.config.HTTPPathPrefix,
+			scheduler:          n.rpcScheduler,
+			access:             n.rpcAccess,
 		}
 		if err := n.http.setListenAddr(n.config.HTTPHost, n.config.HTTPPort); err != nil {
 			return err
@@ -371,9 +391,12 @@ func (n *Node) startRPC() error {
 	if n.config.WSHost != "" {
 		server := n.wsServerForPort(n.config.WSPort)
 		config := wsConfig{
-			Modules: n.config.WSModules,
-			Origins: n.config.WSOrigins,
-			prefix:  n.config.WSPathPrefix,
+			Modules:   n.config.WSModules,
+			Origins:   n.config.WSOrigins,
+			prefix:    n.config.WSPathPrefix,
+			scheduler: n.rpcScheduler,
+			notify:    n.config.notifyLimits(),
+			access:    n.rpcAccess,
 		}
 		if err := server.setListenAddr(n.config.WSHost, n.config.WSPort); err != nil {
 			return err
@@ -532,6 +555,15 @@ func (n *Node) IPCEndpoint() string {
 	return n.ipc.endpoint
 }
 
+// rpcServers returns the JSON-RPC servers serving HTTP and WebSocket connections.
+func (n *Node) rpcServers() []*rpc.Server {
+	servers := n.http.rpcServers()
+	if n.ws != n.http {
+		servers = append(servers, n.ws.rpcServers()...)
+	}
+	return servers
+}
+
 // HTTPEndpoint returns the URL of the HTTP server. Note that this URL does not
 // contain the JSON-RPC path prefix set by HTTPPathPrefix.
 func (n *Node) HTTPEndpoint() string {
@@ -567,7 +599,14 @@ func (n *Node) OpenDatabase(name string, cache, handles int, namespace string, r
 	if n.config.DataDir == "" {
 		db = rawdb.NewMemoryDatabase()
 	} else {
-		db, err = rawdb.NewLevelDBDatabase(n.ResolvePath(name), cache, handles, namespace, readonly)
+		db, err = rawdb.Open(rawdb.OpenOptions{
+			Type:      n.config.DBEngine,
+			Directory: n.ResolvePath(name),
+			Namespace: namespace,
+			Cache:     cache,
+			Handles:   handles,
+			ReadOnly:  readonly,
+		})
 	}
 
 	if err == nil {
@@ -600,7 +639,17 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, freezer,
 		case !filepath.IsAbs(freezer):
 			freezer = n.ResolvePath(freezer)
 		}
-		db, err = rawdb.NewLevelDBDatabaseWithFreezer(root, cache, handles, freezer, namespace, readonly)
+		db, err = rawdb.Open(rawdb.OpenOptions{
+			Type:              n.config.DBEngine,
+			Directory:         root,
+			AncientsDirectory: freezer,
+			Namespace:         namespace,
+			Cache:             cache,
+			Handles:           handles,
+			ReadOnly:          readonly,
+			AncientsRemote:    n.config.AncientRemote,
+			AncientsCache:     n.config.AncientRemoteCache,
+		})
 	}
 
 	if err == nil {
@@ -40,14 +40,19 @@ type httpConfig struct {
 	Modules            []string
 	CorsAllowedOrigins []string
 	Vhosts             []string
-	prefix             string // path prefix on which to mount http handler
+	prefix             string         // path prefix on which to mount http handler
+	scheduler          *rpc.Scheduler // scheduler of the served calls, nil if unbounded
+	access             *rpc.AccessControl
 }
 
 // wsConfig is the JSON-RPC/Websocket configuration
 type wsConfig struct {
-	Origins []string
-	Modules []string
-	prefix  string // path prefix on which to mount ws handler
+	Origins   []string
+	Modules   []string
+	prefix    string         // path prefix on which to mount ws handler
+	scheduler *rpc.Scheduler // scheduler of the served calls, nil if unbounded
+	notify    rpc.NotifyLimits
+	access    *rpc.AccessControl
 }
 
 type rpcHandler struct {
@@ -181,6 +186,18 @@ func (h *httpServer) start() error {
 	return nil
 }
 
+// rpcServers returns the JSON-RPC servers enabled on the server.
+func (h *httpServer) rpcServers() []*rpc.Server {
+	var servers []*rpc.Server
+	if handler := h.httpHandler.Load().(*rpcHandler); handler != nil {
+		servers = append(servers, handler.server)
+	}
+	if handler := h.wsHandler.Load().(*rpcHandler); handler != nil {
+		servers = append(servers, handler.server)
+	}
+	return servers
+}
+
 func (h *httpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// check if ws request and serve if ws enabled
 	ws := h.wsHandler.Load().(*rpcHandler)
@@ -281,6 +298,8 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
+	srv.SetScheduler(config.scheduler)
+	srv.SetAccessControl(config.access)
 	if err := RegisterApis(apis, config.Modules, srv, false); err != nil {
 		return err
 	}
@@ -313,6 +332,9 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
+	srv.SetScheduler(config.scheduler)
+	srv.SetAccessControl(config.access)
+	srv.SetNotifyLimits(config.notify)
 	if err := RegisterApis(apis, config.Modules, srv, false); err != nil {
 		return err
 	}
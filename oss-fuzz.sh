@@ -122,3 +122,4 @@ compile_fuzzer tests/fuzzers/bls12381  FuzzCrossPairing fuzz_cross_pairing
 
 #TODO: move this to tests/fuzzers, if possible
 compile_fuzzer crypto/blake2b  Fuzz      fuzzBlake2b
+compile_fuzzer eth/catalyst   FuzzPayload fuzzCatalystPayload
@@ -18,6 +18,7 @@ package params
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -439,6 +440,194 @@ type ScrollConfig struct {
 
 	// Enable EIP-1559 in tx pool, EnableEIP2718 should be true too [optional]
 	EnableEIP1559 bool `json:"enableEIP1559,omitempty"`
+
+	// Deterministic transaction shuffling within fee bands [optional]
+	TxShuffle *TxShuffleConfig `json:"txShuffle,omitempty"`
+
+	// Minimum and target spacing between block timestamps [optional]
+	BlockTime *BlockTimeConfig `json:"blockTime,omitempty"`
+
+	// Round-robin rotation of multiple sequencers [optional]
+	ProposerRotation *ProposerRotationConfig `json:"proposerRotation,omitempty"`
+
+	// Rollup contract on L1 the chain is settled on [optional]
+	L1Config *L1Config `json:"l1Config,omitempty"`
+
+	// Fee-free protocol calls inserted by the sequencer [optional]
+	SystemTx *SystemTxConfig `json:"systemTx,omitempty"`
+
+	// Charge the L1 fee by the compressed transaction size from this block on [optional]
+	CompressedL1FeeBlock *big.Int `json:"compressedL1FeeBlock,omitempty"`
+
+	// Structured header extra-data layout from this block on [optional]
+	ExtraSchemaBlock *big.Int `json:"extraSchemaBlock,omitempty"`
+
+	// Accept transactions whose fees are paid by a sponsor from this block on [optional]
+	SponsoredTxBlock *big.Int `json:"sponsoredTxBlock,omitempty"`
+
+	// Accept transactions using parallel nonce keys from this block on [optional]
+	NonceKeyBlock *big.Int `json:"nonceKeyBlock,omitempty"`
+
+	// Accept transactions delegating the code of accounts (EIP-7702) from this block on [optional]
+	SetCodeBlock *big.Int `json:"setCodeBlock,omitempty"`
+
+	// Opcode gas cost overrides, each active from its block on [optional]
+	GasSchedules []GasScheduleConfig `json:"gasSchedules,omitempty"`
+
+	// Contract code and init code size limits [optional]
+	CodeSize *CodeSizeConfig `json:"codeSize,omitempty"`
+
+	// On-chain registry of the addresses transactions may not be sent to [optional]
+	Denylist *DenylistConfig `json:"denylist,omitempty"`
+
+	// Enable the precompile reading L1 storage from this block on [optional]
+	L1SloadBlock *big.Int `json:"l1SloadBlock,omitempty"`
+
+	// Enable the precompile verifying Merkle proofs from this block on [optional]
+	MerkleProofBlock *big.Int `json:"merkleProofBlock,omitempty"`
+
+	// Bounds of block timestamps relative to their L1 origin [optional]
+	L1Time *L1TimeConfig `json:"l1Time,omitempty"`
+
+	// Gas limits block producers ramp to, each from its block on [optional]
+	GasLimitSchedule []GasLimitScheduleConfig `json:"gasLimitSchedule,omitempty"`
+
+	// Commit to the state with zktrie instead of MPT from this block on [optional]
+	ZktrieCutoverBlock *big.Int `json:"zktrieCutoverBlock,omitempty"`
+
+	// Bounds of the block parameter swings between consecutive blocks [optional]
+	ParamGuards *ParamGuardConfig `json:"paramGuards,omitempty"`
+}
+
+// TxShuffleConfig configures the per-block transaction ordering mode where
+// transactions within the same fee band are shuffled using a seed derived
+// from the parent hash. Blocks commit to the seed in the leading extra-data
+// bytes, until the structured extra-data layout replaces them: the layout only
+// has room for the leading bytes of the seed within the extra-data size limit,
+// so from then on blocks commit to those. Inclusion
+// lists aren't enforced in this mode, as the shuffled order leaves no room to
+// force transactions in.
+type TxShuffleConfig struct {
+	Block   *big.Int `json:"block,omitempty"`   // Activation block (nil = disabled)
+	FeeBand *big.Int `json:"feeBand,omitempty"` // Width of a fee band in wei (nil or 0 = every distinct tip is its own band)
+}
+
+// BlockTimeConfig configures the block timestamp rules enforced during header
+// verification. Once active, every block must be strictly newer than its parent
+// by at least MinBlockTime seconds, while block producers aim for
+// TargetBlockTime seconds between blocks.
+type BlockTimeConfig struct {
+	Block           *big.Int `json:"block,omitempty"`           // Activation block (nil = disabled)
+	MinBlockTime    uint64   `json:"minBlockTime,omitempty"`    // Minimum seconds between a block and its parent (0 = strictly increasing)
+	TargetBlockTime uint64   `json:"targetBlockTime,omitempty"` // Seconds between blocks block producers aim for (0 = engine default)
+}
+
+// ProposerRotationConfig configures multiple sequencers proposing blocks in
+// turns. Once active, every block commits to the index of its proposer in the
+// leading extra-data bytes, which must match the deterministic round-robin
+// schedule. It can't be combined with the transaction shuffling.
+type ProposerRotationConfig struct {
+	Block     *big.Int         `json:"block,omitempty"`     // Activation block (nil = disabled)
+	Proposers []common.Address `json:"proposers,omitempty"` // Active set of proposers in rotation order
+	Turn      uint64           `json:"turn,omitempty"`      // Consecutive blocks proposed by each proposer (0 = 1)
+}
+
+// L1Config configures the L1 chain the rollup is settled on, whose batch
+// finalization events determine the finalized L2 blocks.
+type L1Config struct {
+	L1ChainId          uint64         `json:"l1ChainId,omitempty"`          // Chain ID of the L1 network
+	ScrollChainAddress common.Address `json:"scrollChainAddress,omitempty"` // Rollup contract committing and finalizing batches
+
+	InclusionListAddress *common.Address `json:"inclusionListAddress,omitempty"` // Contract requiring transactions to be included (nil = none)
+}
+
+// SystemTxConfig configures the system transactions the sequencer inserts at
+// the top of a block to perform protocol operations, like updating the L1 base
+// fee. Once active, system transactions may only call the listed contracts.
+type SystemTxConfig struct {
+	Block     *big.Int         `json:"block,omitempty"`     // Activation block (nil = disabled)
+	Contracts []common.Address `json:"contracts,omitempty"` // System contracts callable by system transactions
+}
+
+// L1TimeConfig configures the bounds of block timestamps relative to the L1
+// origin the block is built on, mirroring the checks of the rollup contract
+// when batches are committed. Once active, every block must set its L1 origin
+// through a system transaction, and its timestamp may neither precede the
+// origin nor exceed it by more than MaxDrift seconds.
+type L1TimeConfig struct {
+	Block    *big.Int `json:"block,omitempty"`    // Activation block (nil = disabled)
+	MaxDrift uint64   `json:"maxDrift,omitempty"` // Maximum seconds a block timestamp may exceed its L1 origin timestamp by
+}
+
+// GasLimitScheduleConfig schedules a gas limit change from a block on. Once
+// active, block producers move the gas limit towards GasLimit, regardless of
+// their local gas ceiling, and the gas limit may change between blocks by up to
+// MaxDelta instead of the default bound.
+type GasLimitScheduleConfig struct {
+	Block    *big.Int `json:"block"`              // Activation block
+	GasLimit uint64   `json:"gasLimit"`           // Gas limit to ramp to
+	MaxDelta uint64   `json:"maxDelta,omitempty"` // Maximum gas limit change between blocks (0 = default bound)
+}
+
+// ParamGuardConfig configures sanity bounds on how far the base fee and gas
+// limit may move between a block and its parent, even if the move is valid on
+// its own, protecting followers from a compromised sequencer pushing extreme
+// parameter swings. Blocks legitimately exceeding the bounds can be exempted by
+// number. The timestamp bound is only enforced by the sequencer when assembling
+// blocks, as followers rejecting the first block after an outage would halt the
+// chain.
+type ParamGuardConfig struct {
+	Block             *big.Int   `json:"block,omitempty"`             // Activation block (nil = disabled)
+	MaxBaseFeeChange  uint64     `json:"maxBaseFeeChange,omitempty"`  // Maximum base fee change relative to the parent, in percent (0 = unbounded)
+	MaxGasLimitChange uint64     `json:"maxGasLimitChange,omitempty"` // Maximum gas limit change relative to the parent, in percent (0 = unbounded)
+	MaxTimeJump       uint64     `json:"maxTimeJump,omitempty"`       // Maximum seconds between an assembled block and its parent (0 = unbounded)
+	Overrides         []*big.Int `json:"overrides,omitempty"`         // Block numbers exempt from the guards
+}
+
+// GasScheduleConfig reprices opcodes from a block on, to make their gas reflect
+// their proving cost. The constant gas of each listed opcode, named like in
+// assembly (e.g. "KECCAK256", "SLOAD"), is replaced by the given cost, charged
+// on top of any dynamic gas of the opcode.
+type GasScheduleConfig struct {
+	Block   *big.Int          `json:"block"`   // Activation block
+	Opcodes map[string]uint64 `json:"opcodes"` // Constant gas by opcode name
+}
+
+// CodeSizeConfig configures the size limits of contract code (EIP-170) and of
+// contract creation code (EIP-3860), which the circuits may need to differ from
+// the Ethereum ones. Once active, both limits are enforced by the EVM, and the
+// init code limit also for contract creation transactions.
+type CodeSizeConfig struct {
+	Block           *big.Int `json:"block,omitempty"`           // Activation block (nil = disabled)
+	MaxCodeSize     uint64   `json:"maxCodeSize,omitempty"`     // Maximum bytecode size of a contract (0 = MaxCodeSize)
+	MaxInitCodeSize uint64   `json:"maxInitCodeSize,omitempty"` // Maximum creation code size (0 = twice the maximum bytecode size)
+}
+
+// DenylistConfig configures the on-chain registry of denied addresses, storing
+// a mapping(address => bool) at the given slot. Once active, transactions sent
+// to an address flagged in the registry at the start of the transaction are
+// invalid.
+type DenylistConfig struct {
+	Block    *big.Int       `json:"block,omitempty"`    // Activation block (nil = disabled)
+	Registry common.Address `json:"registry,omitempty"` // Registry contract
+	Slot     uint64         `json:"slot,omitempty"`     // Storage slot of the mapping in the registry
+}
+
+// IsGasSchedule returns whether the gas schedule with the given index is active
+// at the block with the given number.
+func (s ScrollConfig) IsGasSchedule(index int, num *big.Int) bool {
+	return index < len(s.GasSchedules) && isForked(s.GasSchedules[index].Block, num)
+}
+
+// ActiveGasLimitSchedule returns the gas limit schedule active at the block with the
+// given number, or nil if there is none.
+func (s ScrollConfig) ActiveGasLimitSchedule(num *big.Int) *GasLimitScheduleConfig {
+	for i := len(s.GasLimitSchedule) - 1; i >= 0; i-- {
+		if isForked(s.GasLimitSchedule[i].Block, num) {
+			return &s.GasLimitSchedule[i]
+		}
+	}
+	return nil
 }
 
 func (s ScrollConfig) BaseFeeEnabled() bool {
@@ -453,6 +642,262 @@ func (s ScrollConfig) ZktrieEnabled() bool {
 	return s.UseZktrie
 }
 
+// IsTxShuffle returns whether transactions in the block with the given number
+// must be ordered by the shuffled fee band rule.
+func (s ScrollConfig) IsTxShuffle(num *big.Int) bool {
+	return s.TxShuffle != nil && isForked(s.TxShuffle.Block, num)
+}
+
+// TxShuffleFeeBand returns the configured fee band width, or nil if shuffling
+// is not configured.
+func (s ScrollConfig) TxShuffleFeeBand() *big.Int {
+	if s.TxShuffle == nil {
+		return nil
+	}
+	return s.TxShuffle.FeeBand
+}
+
+func (s ScrollConfig) txShuffleBlock() *big.Int {
+	if s.TxShuffle == nil {
+		return nil
+	}
+	return s.TxShuffle.Block
+}
+
+// IsBlockTime returns whether the block time rules apply to the block with the
+// given number.
+func (s ScrollConfig) IsBlockTime(num *big.Int) bool {
+	return s.BlockTime != nil && isForked(s.BlockTime.Block, num)
+}
+
+// MinBlockTime returns the minimum timestamp delta between the block with the
+// given number and its parent, or 0 if the block time rules are not active.
+func (s ScrollConfig) MinBlockTime(num *big.Int) uint64 {
+	if !s.IsBlockTime(num) {
+		return 0
+	}
+	return s.BlockTime.MinBlockTime
+}
+
+// TargetBlockTime returns the timestamp delta block producers should aim for
+// when building the block with the given number, or 0 if unset.
+func (s ScrollConfig) TargetBlockTime(num *big.Int) uint64 {
+	if !s.IsBlockTime(num) {
+		return 0
+	}
+	return s.BlockTime.TargetBlockTime
+}
+
+func (s ScrollConfig) blockTimeBlock() *big.Int {
+	if s.BlockTime == nil {
+		return nil
+	}
+	return s.BlockTime.Block
+}
+
+// IsProposerRotation returns whether the block with the given number must be
+// proposed according to the proposer rotation schedule.
+func (s ScrollConfig) IsProposerRotation(num *big.Int) bool {
+	return s.ProposerRotation != nil && len(s.ProposerRotation.Proposers) > 0 && isForked(s.ProposerRotation.Block, num)
+}
+
+// ScheduledProposer returns the index and address of the proposer in turn for
+// the block with the given number. It must only be called if the proposer
+// rotation is active for the block.
+func (s ScrollConfig) ScheduledProposer(num *big.Int) (uint64, common.Address) {
+	turn := s.ProposerRotation.Turn
+	if turn == 0 {
+		turn = 1
+	}
+	round := new(big.Int).Sub(num, s.ProposerRotation.Block).Uint64() / turn
+	index := round % uint64(len(s.ProposerRotation.Proposers))
+	return index, s.ProposerRotation.Proposers[index]
+}
+
+func (s ScrollConfig) proposerRotationBlock() *big.Int {
+	if s.ProposerRotation == nil {
+		return nil
+	}
+	return s.ProposerRotation.Block
+}
+
+// IsSystemTx returns whether the block with the given number may contain system
+// transactions.
+func (s ScrollConfig) IsSystemTx(num *big.Int) bool {
+	return s.SystemTx != nil && isForked(s.SystemTx.Block, num)
+}
+
+// IsSystemContract returns whether system transactions may call the given
+// address.
+func (s ScrollConfig) IsSystemContract(addr common.Address) bool {
+	if s.SystemTx == nil {
+		return false
+	}
+	for _, contract := range s.SystemTx.Contracts {
+		if contract == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCompressedL1Fee returns whether the L1 fee of the transactions in the block
+// with the given number is charged by their estimated compressed size.
+func (s ScrollConfig) IsCompressedL1Fee(num *big.Int) bool {
+	return isForked(s.CompressedL1FeeBlock, num)
+}
+
+// IsExtraSchema returns whether the extra-data of the header with the given
+// number must follow the structured layout (see types.L2Extra). The layout
+// commits to the leading bytes of the transaction shuffle seed in place of the
+// full seed.
+func (s ScrollConfig) IsExtraSchema(num *big.Int) bool {
+	return isForked(s.ExtraSchemaBlock, num)
+}
+
+// IsSponsoredTx returns whether the block with the given number may contain
+// sponsored transactions, whose fees are paid by a separate fee payer.
+func (s ScrollConfig) IsSponsoredTx(num *big.Int) bool {
+	return isForked(s.SponsoredTxBlock, num)
+}
+
+// IsNonceKey returns whether the block with the given number may contain
+// transactions using the nonce of a nonce key other than the account nonce.
+func (s ScrollConfig) IsNonceKey(num *big.Int) bool {
+	return isForked(s.NonceKeyBlock, num)
+}
+
+// IsSetCode returns whether the block with the given number may contain set-code
+// transactions, and executes the code accounts delegate to.
+func (s ScrollConfig) IsSetCode(num *big.Int) bool {
+	return isForked(s.SetCodeBlock, num)
+}
+
+// IsL1Sload returns whether the precompile reading L1 storage is enabled in the
+// block with the given number.
+func (s ScrollConfig) IsL1Sload(num *big.Int) bool {
+	return isForked(s.L1SloadBlock, num)
+}
+
+// IsMerkleProof returns whether the precompile verifying Merkle proofs is
+// enabled in the block with the given number.
+func (s ScrollConfig) IsMerkleProof(num *big.Int) bool {
+	return isForked(s.MerkleProofBlock, num)
+}
+
+// IsZktrieCutover returns whether the state root of the block with the given
+// number commits to the state with zktrie after the cutover from MPT. Chains
+// using zktrie from genesis on have no cutover.
+func (s ScrollConfig) IsZktrieCutover(num *big.Int) bool {
+	return !s.UseZktrie && isForked(s.ZktrieCutoverBlock, num)
+}
+
+// IsCodeSize returns whether the configured code size limits apply to the block
+// with the given number.
+func (s ScrollConfig) IsCodeSize(num *big.Int) bool {
+	return s.CodeSize != nil && isForked(s.CodeSize.Block, num)
+}
+
+// MaxCodeSize returns the maximum bytecode size of contracts deployed in the
+// block with the given number.
+func (s ScrollConfig) MaxCodeSize(num *big.Int) uint64 {
+	if !s.IsCodeSize(num) || s.CodeSize.MaxCodeSize == 0 {
+		return MaxCodeSize
+	}
+	return s.CodeSize.MaxCodeSize
+}
+
+// MaxInitCodeSize returns the maximum creation code size of contracts deployed
+// in the block with the given number, or 0 if the init code size is unlimited.
+func (s ScrollConfig) MaxInitCodeSize(num *big.Int) uint64 {
+	if !s.IsCodeSize(num) {
+		return 0
+	}
+	if s.CodeSize.MaxInitCodeSize == 0 {
+		return 2 * s.MaxCodeSize(num)
+	}
+	return s.CodeSize.MaxInitCodeSize
+}
+
+// IsDenylist returns whether transactions to the addresses of the denylist
+// registry are invalid in the block with the given number.
+func (s ScrollConfig) IsDenylist(num *big.Int) bool {
+	return s.Denylist != nil && isForked(s.Denylist.Block, num)
+}
+
+func (s ScrollConfig) denylistBlock() *big.Int {
+	if s.Denylist == nil {
+		return nil
+	}
+	return s.Denylist.Block
+}
+
+func (s ScrollConfig) codeSizeBlock() *big.Int {
+	if s.CodeSize == nil {
+		return nil
+	}
+	return s.CodeSize.Block
+}
+
+func (s ScrollConfig) systemTxBlock() *big.Int {
+	if s.SystemTx == nil {
+		return nil
+	}
+	return s.SystemTx.Block
+}
+
+// IsL1Time returns whether the timestamp of the block with the given number is
+// bounded by its L1 origin.
+func (s ScrollConfig) IsL1Time(num *big.Int) bool {
+	return s.L1Time != nil && isForked(s.L1Time.Block, num)
+}
+
+// MaxL1TimeDrift returns the maximum seconds the timestamp of the block with
+// the given number may exceed its L1 origin timestamp by.
+func (s ScrollConfig) MaxL1TimeDrift(num *big.Int) uint64 {
+	if !s.IsL1Time(num) {
+		return 0
+	}
+	return s.L1Time.MaxDrift
+}
+
+func (s ScrollConfig) l1TimeBlock() *big.Int {
+	if s.L1Time == nil {
+		return nil
+	}
+	return s.L1Time.Block
+}
+
+// IsParamGuard returns whether the parameter swing guards apply to the block
+// with the given number.
+func (s ScrollConfig) IsParamGuard(num *big.Int) bool {
+	if s.ParamGuards == nil || !isForked(s.ParamGuards.Block, num) {
+		return false
+	}
+	for _, override := range s.ParamGuards.Overrides {
+		if configNumEqual(override, num) {
+			return false
+		}
+	}
+	return true
+}
+
+// MaxTimeJump returns the maximum number of seconds the sequencer may assemble
+// the block with the given number after its parent, or 0 if unbounded.
+func (s ScrollConfig) MaxTimeJump(num *big.Int) uint64 {
+	if !s.IsParamGuard(num) {
+		return 0
+	}
+	return s.ParamGuards.MaxTimeJump
+}
+
+func (s ScrollConfig) paramGuardBlock() *big.Int {
+	if s.ParamGuards == nil {
+		return nil
+	}
+	return s.ParamGuards.Block
+}
+
 // IsValidTxCount returns whether the given block's transaction count is below the limit.
 func (s ScrollConfig) IsValidTxCount(count int) bool {
 	return s.MaxTxPerBlock == nil || count <= *s.MaxTxPerBlock
@@ -643,6 +1088,54 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	// Both the shuffle seed and the proposer index are committed in the leading
+	// extra-data bytes, so the two can't be enabled together unless the extra-data
+	// follows the structured layout from the start
+	if shuffle, rotation := c.Scroll.txShuffleBlock(), c.Scroll.proposerRotationBlock(); shuffle != nil && rotation != nil {
+		if schema := c.Scroll.ExtraSchemaBlock; schema == nil || schema.Cmp(shuffle) > 0 || schema.Cmp(rotation) > 0 {
+			return errors.New("unsupported scroll config: txShuffle and proposerRotation are mutually exclusive before extraSchemaBlock")
+		}
+	}
+	// Sponsored transactions carry dynamic fees, which are only signed for by
+	// the London signer
+	if sponsored := c.Scroll.SponsoredTxBlock; sponsored != nil && (c.LondonBlock == nil || c.LondonBlock.Cmp(sponsored) > 0) {
+		return fmt.Errorf("unsupported scroll config: sponsoredTxBlock %v before londonBlock %v", sponsored, c.LondonBlock)
+	}
+	if nonceKey := c.Scroll.NonceKeyBlock; nonceKey != nil && (c.LondonBlock == nil || c.LondonBlock.Cmp(nonceKey) > 0) {
+		return fmt.Errorf("unsupported scroll config: nonceKeyBlock %v before londonBlock %v", nonceKey, c.LondonBlock)
+	}
+	if setCode := c.Scroll.SetCodeBlock; setCode != nil && (c.LondonBlock == nil || c.LondonBlock.Cmp(setCode) > 0) {
+		return fmt.Errorf("unsupported scroll config: setCodeBlock %v before londonBlock %v", setCode, c.LondonBlock)
+	}
+	if l1Sload := c.Scroll.L1SloadBlock; l1Sload != nil && (c.BerlinBlock == nil || c.BerlinBlock.Cmp(l1Sload) > 0) {
+		return fmt.Errorf("unsupported scroll config: l1SloadBlock %v before berlinBlock %v", l1Sload, c.BerlinBlock)
+	}
+	if c.Scroll.Denylist != nil && c.Scroll.Denylist.Block != nil && c.Scroll.Denylist.Registry == (common.Address{}) {
+		return errors.New("unsupported scroll config: denylist without registry")
+	}
+	// The L1 origin is set by a system transaction
+	if l1Time, systemTx := c.Scroll.l1TimeBlock(), c.Scroll.systemTxBlock(); l1Time != nil && (systemTx == nil || systemTx.Cmp(l1Time) > 0) {
+		return fmt.Errorf("unsupported scroll config: l1Time block %v before systemTx block %v", l1Time, systemTx)
+	}
+	for i, schedule := range c.Scroll.GasSchedules {
+		if schedule.Block == nil {
+			return fmt.Errorf("unsupported scroll config: gasSchedules[%d] without block", i)
+		}
+		if i > 0 && c.Scroll.GasSchedules[i-1].Block.Cmp(schedule.Block) >= 0 {
+			return fmt.Errorf("unsupported scroll config: gasSchedules[%d] block %v not after block %v", i, schedule.Block, c.Scroll.GasSchedules[i-1].Block)
+		}
+	}
+	for i, schedule := range c.Scroll.GasLimitSchedule {
+		if schedule.Block == nil {
+			return fmt.Errorf("unsupported scroll config: gasLimitSchedule[%d] without block", i)
+		}
+		if i > 0 && c.Scroll.GasLimitSchedule[i-1].Block.Cmp(schedule.Block) >= 0 {
+			return fmt.Errorf("unsupported scroll config: gasLimitSchedule[%d] block %v not after block %v", i, schedule.Block, c.Scroll.GasLimitSchedule[i-1].Block)
+		}
+		if schedule.GasLimit < MinGasLimit {
+			return fmt.Errorf("unsupported scroll config: gasLimitSchedule[%d] gas limit %d below %d", i, schedule.GasLimit, MinGasLimit)
+		}
+	}
 	return nil
 }
 
@@ -696,9 +1189,162 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.ArrowGlacierBlock, newcfg.ArrowGlacierBlock, head) {
 		return newCompatError("Arrow Glacier fork block", c.ArrowGlacierBlock, newcfg.ArrowGlacierBlock)
 	}
+	if isForkIncompatible(c.Scroll.txShuffleBlock(), newcfg.Scroll.txShuffleBlock(), head) {
+		return newCompatError("Tx shuffle fork block", c.Scroll.txShuffleBlock(), newcfg.Scroll.txShuffleBlock())
+	}
+	if isForkIncompatible(c.Scroll.blockTimeBlock(), newcfg.Scroll.blockTimeBlock(), head) {
+		return newCompatError("Block time fork block", c.Scroll.blockTimeBlock(), newcfg.Scroll.blockTimeBlock())
+	}
+	if isForkIncompatible(c.Scroll.proposerRotationBlock(), newcfg.Scroll.proposerRotationBlock(), head) {
+		return newCompatError("Proposer rotation fork block", c.Scroll.proposerRotationBlock(), newcfg.Scroll.proposerRotationBlock())
+	}
+	if isForkIncompatible(c.Scroll.CompressedL1FeeBlock, newcfg.Scroll.CompressedL1FeeBlock, head) {
+		return newCompatError("Compressed L1 fee fork block", c.Scroll.CompressedL1FeeBlock, newcfg.Scroll.CompressedL1FeeBlock)
+	}
+	if isForkIncompatible(c.Scroll.systemTxBlock(), newcfg.Scroll.systemTxBlock(), head) {
+		return newCompatError("System transaction fork block", c.Scroll.systemTxBlock(), newcfg.Scroll.systemTxBlock())
+	}
+	if isForkIncompatible(c.Scroll.ExtraSchemaBlock, newcfg.Scroll.ExtraSchemaBlock, head) {
+		return newCompatError("Extra-data schema fork block", c.Scroll.ExtraSchemaBlock, newcfg.Scroll.ExtraSchemaBlock)
+	}
+	if isForkIncompatible(c.Scroll.SponsoredTxBlock, newcfg.Scroll.SponsoredTxBlock, head) {
+		return newCompatError("Sponsored transaction fork block", c.Scroll.SponsoredTxBlock, newcfg.Scroll.SponsoredTxBlock)
+	}
+	if isForkIncompatible(c.Scroll.NonceKeyBlock, newcfg.Scroll.NonceKeyBlock, head) {
+		return newCompatError("Nonce key fork block", c.Scroll.NonceKeyBlock, newcfg.Scroll.NonceKeyBlock)
+	}
+	if isForkIncompatible(c.Scroll.SetCodeBlock, newcfg.Scroll.SetCodeBlock, head) {
+		return newCompatError("Set-code fork block", c.Scroll.SetCodeBlock, newcfg.Scroll.SetCodeBlock)
+	}
+	if isForkIncompatible(c.Scroll.L1SloadBlock, newcfg.Scroll.L1SloadBlock, head) {
+		return newCompatError("L1SLOAD fork block", c.Scroll.L1SloadBlock, newcfg.Scroll.L1SloadBlock)
+	}
+	if isForkIncompatible(c.Scroll.MerkleProofBlock, newcfg.Scroll.MerkleProofBlock, head) {
+		return newCompatError("Merkle proof fork block", c.Scroll.MerkleProofBlock, newcfg.Scroll.MerkleProofBlock)
+	}
+	if isForkIncompatible(c.Scroll.ZktrieCutoverBlock, newcfg.Scroll.ZktrieCutoverBlock, head) {
+		return newCompatError("Zktrie cutover fork block", c.Scroll.ZktrieCutoverBlock, newcfg.Scroll.ZktrieCutoverBlock)
+	}
+	if err := checkGasSchedulesCompatible(c.Scroll.GasSchedules, newcfg.Scroll.GasSchedules, head); err != nil {
+		return err
+	}
+	if err := checkGasLimitScheduleCompatible(c.Scroll.GasLimitSchedule, newcfg.Scroll.GasLimitSchedule, head); err != nil {
+		return err
+	}
+	if isForkIncompatible(c.Scroll.denylistBlock(), newcfg.Scroll.denylistBlock(), head) {
+		return newCompatError("Denylist fork block", c.Scroll.denylistBlock(), newcfg.Scroll.denylistBlock())
+	}
+	// The registry can't move once active either
+	if c.Scroll.IsDenylist(head) && (c.Scroll.Denylist.Registry != newcfg.Scroll.Denylist.Registry || c.Scroll.Denylist.Slot != newcfg.Scroll.Denylist.Slot) {
+		return newCompatError("Denylist fork block", c.Scroll.denylistBlock(), newcfg.Scroll.denylistBlock())
+	}
+	if isForkIncompatible(c.Scroll.codeSizeBlock(), newcfg.Scroll.codeSizeBlock(), head) {
+		return newCompatError("Code size fork block", c.Scroll.codeSizeBlock(), newcfg.Scroll.codeSizeBlock())
+	}
+	// The limits can't change once active either
+	if c.Scroll.IsCodeSize(head) && (c.Scroll.MaxCodeSize(head) != newcfg.Scroll.MaxCodeSize(head) || c.Scroll.MaxInitCodeSize(head) != newcfg.Scroll.MaxInitCodeSize(head)) {
+		return newCompatError("Code size fork block", c.Scroll.codeSizeBlock(), newcfg.Scroll.codeSizeBlock())
+	}
+	if isForkIncompatible(c.Scroll.l1TimeBlock(), newcfg.Scroll.l1TimeBlock(), head) {
+		return newCompatError("L1 time fork block", c.Scroll.l1TimeBlock(), newcfg.Scroll.l1TimeBlock())
+	}
+	// The drift can't change once active either
+	if c.Scroll.IsL1Time(head) && c.Scroll.MaxL1TimeDrift(head) != newcfg.Scroll.MaxL1TimeDrift(head) {
+		return newCompatError("L1 time fork block", c.Scroll.l1TimeBlock(), newcfg.Scroll.l1TimeBlock())
+	}
+	if isForkIncompatible(c.Scroll.paramGuardBlock(), newcfg.Scroll.paramGuardBlock(), head) {
+		return newCompatError("Parameter guard fork block", c.Scroll.paramGuardBlock(), newcfg.Scroll.paramGuardBlock())
+	}
+	// Neither can the bounds change once active, nor the exemptions of past blocks
+	if isForked(c.Scroll.paramGuardBlock(), head) && !equalParamGuards(c.Scroll.ParamGuards, newcfg.Scroll.ParamGuards, head) {
+		return newCompatError("Parameter guard fork block", c.Scroll.paramGuardBlock(), newcfg.Scroll.paramGuardBlock())
+	}
+	return nil
+}
+
+// equalParamGuards returns whether the consensus bounds of both guards are the
+// same, and they exempt the same blocks up to head.
+func equalParamGuards(a, b *ParamGuardConfig, head *big.Int) bool {
+	if a.MaxBaseFeeChange != b.MaxBaseFeeChange || a.MaxGasLimitChange != b.MaxGasLimitChange {
+		return false
+	}
+	exempted := func(guards *ParamGuardConfig) map[uint64]struct{} {
+		set := make(map[uint64]struct{})
+		for _, num := range guards.Overrides {
+			if isForked(num, head) {
+				set[num.Uint64()] = struct{}{}
+			}
+		}
+		return set
+	}
+	setA, setB := exempted(a), exempted(b)
+	if len(setA) != len(setB) {
+		return false
+	}
+	for num := range setA {
+		if _, ok := setB[num]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// checkGasSchedulesCompatible checks that the gas schedules already active at
+// head are neither rescheduled nor repriced.
+func checkGasSchedulesCompatible(stored, next []GasScheduleConfig, head *big.Int) *ConfigCompatError {
+	for i := 0; i < len(stored) || i < len(next); i++ {
+		var s1, s2 GasScheduleConfig
+		if i < len(stored) {
+			s1 = stored[i]
+		}
+		if i < len(next) {
+			s2 = next[i]
+		}
+		what := fmt.Sprintf("Gas schedule %d fork block", i)
+		if isForkIncompatible(s1.Block, s2.Block, head) {
+			return newCompatError(what, s1.Block, s2.Block)
+		}
+		if isForked(s1.Block, head) && !equalGasOverrides(s1.Opcodes, s2.Opcodes) {
+			return newCompatError(what, s1.Block, s2.Block)
+		}
+	}
+	return nil
+}
+
+// checkGasLimitScheduleCompatible checks that the gas limit changes already
+// active at head are neither rescheduled nor altered.
+func checkGasLimitScheduleCompatible(stored, next []GasLimitScheduleConfig, head *big.Int) *ConfigCompatError {
+	for i := 0; i < len(stored) || i < len(next); i++ {
+		var s1, s2 GasLimitScheduleConfig
+		if i < len(stored) {
+			s1 = stored[i]
+		}
+		if i < len(next) {
+			s2 = next[i]
+		}
+		what := fmt.Sprintf("Gas limit schedule %d fork block", i)
+		if isForkIncompatible(s1.Block, s2.Block, head) {
+			return newCompatError(what, s1.Block, s2.Block)
+		}
+		if isForked(s1.Block, head) && (s1.GasLimit != s2.GasLimit || s1.MaxDelta != s2.MaxDelta) {
+			return newCompatError(what, s1.Block, s2.Block)
+		}
+	}
 	return nil
 }
 
+func equalGasOverrides(a, b map[string]uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for op, gas := range a {
+		if have, ok := b[op]; !ok || have != gas {
+			return false
+		}
+	}
+	return true
+}
+
 // isForkIncompatible returns true if a fork scheduled at s1 cannot be rescheduled to
 // block s2 because head is already past the fork.
 func isForkIncompatible(s1, s2, head *big.Int) bool {
@@ -764,6 +1410,7 @@ type Rules struct {
 	IsHomestead, IsEIP150, IsEIP155, IsEIP158               bool
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
 	IsBerlin, IsLondon                                      bool
+	IsSetCode, IsL1Sload, IsMerkleProof                     bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -784,5 +1431,8 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 		IsIstanbul:       c.IsIstanbul(num),
 		IsBerlin:         c.IsBerlin(num),
 		IsLondon:         c.IsLondon(num),
+		IsSetCode:        c.Scroll.IsSetCode(num),
+		IsL1Sload:        c.Scroll.IsL1Sload(num),
+		IsMerkleProof:    c.Scroll.IsMerkleProof(num),
 	}
 }
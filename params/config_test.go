@@ -87,6 +87,89 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     30,
 			},
 		},
+		{
+			stored:  &ChainConfig{Scroll: ScrollConfig{GasSchedules: []GasScheduleConfig{{Block: big.NewInt(10), Opcodes: map[string]uint64{"SLOAD": 50}}}}},
+			new:     &ChainConfig{Scroll: ScrollConfig{GasSchedules: []GasScheduleConfig{{Block: big.NewInt(10), Opcodes: map[string]uint64{"SLOAD": 60}}}}},
+			head:    5,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Scroll: ScrollConfig{GasSchedules: []GasScheduleConfig{{Block: big.NewInt(10), Opcodes: map[string]uint64{"SLOAD": 50}}}}},
+			new:    &ChainConfig{Scroll: ScrollConfig{GasSchedules: []GasScheduleConfig{{Block: big.NewInt(10), Opcodes: map[string]uint64{"SLOAD": 60}}}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "Gas schedule 0 fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{Scroll: ScrollConfig{GasSchedules: []GasScheduleConfig{{Block: big.NewInt(10), Opcodes: map[string]uint64{"SLOAD": 50}}}}},
+			new:     &ChainConfig{Scroll: ScrollConfig{GasSchedules: []GasScheduleConfig{{Block: big.NewInt(10), Opcodes: map[string]uint64{"SLOAD": 50}}, {Block: big.NewInt(30), Opcodes: map[string]uint64{"SHA3": 100}}}}},
+			head:    20,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Scroll: ScrollConfig{CodeSize: &CodeSizeConfig{Block: big.NewInt(10), MaxCodeSize: 100}}},
+			new:    &ChainConfig{Scroll: ScrollConfig{CodeSize: &CodeSizeConfig{Block: big.NewInt(10), MaxCodeSize: 200}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "Code size fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Scroll: ScrollConfig{GasLimitSchedule: []GasLimitScheduleConfig{{Block: big.NewInt(10), GasLimit: 20_000_000}}}},
+			new:    &ChainConfig{Scroll: ScrollConfig{GasLimitSchedule: []GasLimitScheduleConfig{{Block: big.NewInt(10), GasLimit: 30_000_000}}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "Gas limit schedule 0 fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Scroll: ScrollConfig{L1Time: &L1TimeConfig{Block: big.NewInt(10), MaxDrift: 600}}},
+			new:    &ChainConfig{Scroll: ScrollConfig{L1Time: &L1TimeConfig{Block: big.NewInt(10), MaxDrift: 300}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "L1 time fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Scroll: ScrollConfig{ParamGuards: &ParamGuardConfig{Block: big.NewInt(10), MaxBaseFeeChange: 50}}},
+			new:    &ChainConfig{Scroll: ScrollConfig{ParamGuards: &ParamGuardConfig{Block: big.NewInt(30), MaxBaseFeeChange: 50}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "Parameter guard fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(30),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Scroll: ScrollConfig{ParamGuards: &ParamGuardConfig{Block: big.NewInt(10), MaxBaseFeeChange: 50}}},
+			new:    &ChainConfig{Scroll: ScrollConfig{ParamGuards: &ParamGuardConfig{Block: big.NewInt(10), MaxBaseFeeChange: 50, Overrides: []*big.Int{big.NewInt(15)}}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "Parameter guard fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Scroll: ScrollConfig{ParamGuards: &ParamGuardConfig{Block: big.NewInt(10), MaxBaseFeeChange: 50, MaxTimeJump: 60}}},
+			new:    &ChainConfig{Scroll: ScrollConfig{ParamGuards: &ParamGuardConfig{Block: big.NewInt(10), MaxBaseFeeChange: 50, MaxTimeJump: 600, Overrides: []*big.Int{big.NewInt(25)}}}},
+			head:   20,
+		},
 	}
 
 	for _, test := range tests {
@@ -96,3 +179,26 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestCodeSizeLimits(t *testing.T) {
+	tests := []struct {
+		config           *CodeSizeConfig
+		num              int64
+		maxCode, maxInit uint64
+	}{
+		{config: nil, num: 10, maxCode: MaxCodeSize, maxInit: 0},
+		{config: &CodeSizeConfig{Block: big.NewInt(10)}, num: 9, maxCode: MaxCodeSize, maxInit: 0},
+		{config: &CodeSizeConfig{Block: big.NewInt(10)}, num: 10, maxCode: MaxCodeSize, maxInit: 2 * MaxCodeSize},
+		{config: &CodeSizeConfig{Block: big.NewInt(10), MaxCodeSize: 100}, num: 10, maxCode: 100, maxInit: 200},
+		{config: &CodeSizeConfig{Block: big.NewInt(10), MaxCodeSize: 100, MaxInitCodeSize: 150}, num: 10, maxCode: 100, maxInit: 150},
+	}
+	for i, test := range tests {
+		scroll := ScrollConfig{CodeSize: test.config}
+		if have := scroll.MaxCodeSize(big.NewInt(test.num)); have != test.maxCode {
+			t.Errorf("test %d: max code size mismatch: have %d, want %d", i, have, test.maxCode)
+		}
+		if have := scroll.MaxInitCodeSize(big.NewInt(test.num)); have != test.maxInit {
+			t.Errorf("test %d: max init code size mismatch: have %d, want %d", i, have, test.maxInit)
+		}
+	}
+}
@@ -83,10 +83,12 @@ const (
 	SelfdestructRefundGas uint64 = 24000 // Refunded following a selfdestruct operation.
 	MemoryGas             uint64 = 3     // Times the address of the (highest referenced byte in memory + 1). NOTE: referencing happens on read, write and in instructions such as RETURN and CALL.
 
-	TxDataNonZeroGasFrontier  uint64 = 68   // Per byte of data attached to a transaction that is not equal to zero. NOTE: Not payable on data of calls between transactions.
-	TxDataNonZeroGasEIP2028   uint64 = 16   // Per byte of non zero data attached to a transaction after EIP 2028 (part in Istanbul)
-	TxAccessListAddressGas    uint64 = 2400 // Per address specified in EIP 2930 access list
-	TxAccessListStorageKeyGas uint64 = 1900 // Per storage key specified in EIP 2930 access list
+	TxDataNonZeroGasFrontier  uint64 = 68    // Per byte of data attached to a transaction that is not equal to zero. NOTE: Not payable on data of calls between transactions.
+	TxDataNonZeroGasEIP2028   uint64 = 16    // Per byte of non zero data attached to a transaction after EIP 2028 (part in Istanbul)
+	TxAccessListAddressGas    uint64 = 2400  // Per address specified in EIP 2930 access list
+	TxAccessListStorageKeyGas uint64 = 1900  // Per storage key specified in EIP 2930 access list
+	TxAuthTupleGas            uint64 = 12500 // Per authorization of an EIP 7702 set-code transaction, if its authority exists
+	TxAuthEmptyAccountGas     uint64 = 25000 // Per authorization of an EIP 7702 set-code transaction, charged upfront
 
 	// These have been changed during the course of the chain
 	CallGasFrontier              uint64 = 40  // Once per CALL operation & message call transaction.
@@ -156,6 +158,18 @@ const (
 	// up to half the consumed gas could be refunded. Redefined as 1/5th in EIP-3529
 	RefundQuotient        uint64 = 2
 	RefundQuotientEIP3529 uint64 = 5
+
+	// SystemTxGas is the gas allowance of a system transaction. It is neither paid
+	// for nor counted towards the gas used by the block.
+	SystemTxGas uint64 = 1000000
+
+	L1SloadGas    uint64 = 2000 // Price of an L1 storage read by the L1SLOAD precompile
+	L1SloadWindow uint64 = 64   // Number of L1 blocks up to the L1 origin the L1SLOAD precompile may read
+
+	MerkleProofBaseGas           uint64 = 100 // Base price of a Merkle proof verification
+	MerkleProofKeccakPerLevelGas uint64 = 42  // Per-level price of a Merkle proof verification hashing with keccak256
+	MerkleProofSha256PerLevelGas uint64 = 84  // Per-level price of a Merkle proof verification hashing with sha256
+	MerkleProofMaxDepth          uint64 = 64  // Maximum depth of the Merkle proofs verified by the precompile
 )
 
 // Gas discount table for BLS12-381 G1 and G2 multi exponentiation operations
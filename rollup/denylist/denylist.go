@@ -0,0 +1,53 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package denylist reads the on-chain registry of the addresses transactions may
+// not be sent to, for operators required to refuse them.
+//
+// The registry is a contract storing a
+//
+//	mapping(address => bool)
+//
+// at a configured slot, maintained by its owner. From the denylist fork on,
+// transactions sent to a flagged address are invalid, so that followers reject
+// the blocks including them and every node agrees on the registry contents as
+// of the start of each transaction.
+package denylist
+
+import (
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// StateReader is the view of the state needed to read the registry.
+type StateReader interface {
+	GetState(addr common.Address, key common.Hash) common.Hash
+}
+
+// Slot returns the storage slot of the registry flag of the given address, for
+// the mapping stored at the given slot.
+func Slot(mappingSlot uint64, addr common.Address) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(addr.Bytes(), common.HashLength), common.BigToHash(new(big.Int).SetUint64(mappingSlot)).Bytes())
+}
+
+// IsDenied returns whether the given address is flagged in the registry of the
+// config.
+func IsDenied(config *params.DenylistConfig, state StateReader, addr common.Address) bool {
+	return state.GetState(config.Registry, Slot(config.Slot, addr)) != (common.Hash{})
+}
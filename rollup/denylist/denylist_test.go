@@ -0,0 +1,48 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package denylist
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+type testState map[common.Hash]common.Hash
+
+func (s testState) GetState(addr common.Address, key common.Hash) common.Hash {
+	return s[key]
+}
+
+// Tests that the registry flags are read from the Solidity mapping layout.
+func TestIsDenied(t *testing.T) {
+	// keccak256(abi.encode(address(1), uint256(0)))
+	want := common.HexToHash("0xada5013122d395ba3c54772283fb069b10426056ef8ca54750cb9bb552a59e7d")
+	if slot := Slot(0, common.BytesToAddress([]byte{0x01})); slot != want {
+		t.Fatalf("slot mismatch: have %x, want %x", slot, want)
+	}
+	config := &params.DenylistConfig{Registry: common.Address{0xaa}, Slot: 3}
+	state := testState{Slot(3, common.Address{0x01}): common.BigToHash(common.Big1)}
+
+	if !IsDenied(config, state, common.Address{0x01}) {
+		t.Errorf("flagged address not denied")
+	}
+	if IsDenied(config, state, common.Address{0x02}) {
+		t.Errorf("unflagged address denied")
+	}
+}
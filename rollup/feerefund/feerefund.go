@@ -0,0 +1,121 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package feerefund issues the refunds of the L1 data fee overcharged to
+// transactions. Transactions are charged at the uncompressed upper estimate
+// during execution. Once their batch is compressed, the rollup relayer submits
+// the difference to the actual share of each transaction, which is queued here
+// and paid back by a system transaction in the next blocks produced locally.
+package feerefund
+
+import (
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+// maxRefundsPerBlock is the maximum number of refunds issued by a single block.
+const maxRefundsPerBlock = 256
+
+// blockChain is the view of the local chain needed to verify refunds.
+type blockChain interface {
+	CurrentBlock() *types.Block
+	VerifyL1FeeRefund(refund *types.L1FeeRefund, number uint64) error
+}
+
+// Queue keeps the L1 fee refunds to be issued until they are included in the
+// canonical chain.
+type Queue struct {
+	chain   blockChain
+	pending []*types.L1FeeRefund
+	lock    sync.Mutex
+}
+
+// NewQueue creates an empty refund queue.
+func NewQueue(chain blockChain) *Queue {
+	return &Queue{chain: chain}
+}
+
+// Add verifies and queues refunds to be issued. Either all refunds are queued,
+// or none if any of them is invalid.
+func (q *Queue) Add(refunds []*types.L1FeeRefund) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	queued := make(map[common.Hash]struct{}, len(q.pending)+len(refunds))
+	for _, refund := range q.pending {
+		queued[refund.TxHash] = struct{}{}
+	}
+	var (
+		number = q.chain.CurrentBlock().NumberU64() + 1
+		added  []*types.L1FeeRefund
+	)
+	for _, refund := range refunds {
+		if _, ok := queued[refund.TxHash]; ok {
+			continue
+		}
+		if err := q.chain.VerifyL1FeeRefund(refund, number); err != nil {
+			return err
+		}
+		queued[refund.TxHash] = struct{}{}
+		added = append(added, refund)
+	}
+	q.pending = append(q.pending, added...)
+	return nil
+}
+
+// Pending returns the number of refunds waiting to be issued.
+func (q *Queue) Pending() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return len(q.pending)
+}
+
+// SystemTxs returns the system transaction issuing the pending refunds in the
+// block with the given header. Refunds already issued by the canonical chain,
+// or no longer valid, are dropped. It's meant to be a system transaction source
+// of the miner.
+func (q *Queue) SystemTxs(header *types.Header) types.Transactions {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	number := header.Number.Uint64()
+	pending := q.pending[:0]
+	for _, refund := range q.pending {
+		if err := q.chain.VerifyL1FeeRefund(refund, number); err != nil {
+			log.Debug("Dropping L1 fee refund", "hash", refund.TxHash, "err", err)
+			continue
+		}
+		pending = append(pending, refund)
+	}
+	q.pending = pending
+	if len(pending) == 0 {
+		return nil
+	}
+	if len(pending) > maxRefundsPerBlock {
+		pending = pending[:maxRefundsPerBlock]
+	}
+	data, err := types.EncodeL1FeeRefunds(pending)
+	if err != nil {
+		log.Error("Failed to encode L1 fee refunds", "err", err)
+		return nil
+	}
+	return types.Transactions{types.NewSystemTx(number, rcfg.L1FeeRefundAddress, data)}
+}
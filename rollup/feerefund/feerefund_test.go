@@ -0,0 +1,82 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package feerefund
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+var errRefunded = errors.New("already refunded")
+
+// testChain is a fake chain rejecting the refunds of transactions in its
+// refunded set.
+type testChain struct {
+	refunded map[common.Hash]bool
+}
+
+func (c *testChain) CurrentBlock() *types.Block {
+	return types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10)})
+}
+
+func (c *testChain) VerifyL1FeeRefund(refund *types.L1FeeRefund, number uint64) error {
+	if c.refunded[refund.TxHash] {
+		return errRefunded
+	}
+	return nil
+}
+
+// Tests that queued refunds are issued until included in the chain.
+func TestQueue(t *testing.T) {
+	chain := &testChain{refunded: make(map[common.Hash]bool)}
+	queue := NewQueue(chain)
+
+	a := &types.L1FeeRefund{TxHash: common.Hash{0x01}, Recipient: common.Address{0x01}, Amount: big.NewInt(1)}
+	b := &types.L1FeeRefund{TxHash: common.Hash{0x02}, Recipient: common.Address{0x02}, Amount: big.NewInt(2)}
+
+	// Invalid refunds reject the whole submission
+	chain.refunded[b.TxHash] = true
+	if err := queue.Add([]*types.L1FeeRefund{a, b}); err != errRefunded || queue.Pending() != 0 {
+		t.Fatalf("invalid submission mismatch: err %v, pending %d", err, queue.Pending())
+	}
+	delete(chain.refunded, b.TxHash)
+	if err := queue.Add([]*types.L1FeeRefund{a, b, a}); err != nil {
+		t.Fatalf("failed to queue refunds: %v", err)
+	}
+	if err := queue.Add([]*types.L1FeeRefund{b}); err != nil || queue.Pending() != 2 {
+		t.Fatalf("pending refunds mismatch: err %v, have %d, want 2", err, queue.Pending())
+	}
+	txs := queue.SystemTxs(&types.Header{Number: big.NewInt(11)})
+	if len(txs) != 1 || txs[0].Nonce() != 11 || *txs[0].To() != rcfg.L1FeeRefundAddress {
+		t.Fatalf("system transactions mismatch: %v", txs)
+	}
+	refunds, err := types.DecodeL1FeeRefunds(txs[0].Data())
+	if err != nil || len(refunds) != 2 || refunds[0].TxHash != a.TxHash || refunds[1].TxHash != b.TxHash {
+		t.Fatalf("issued refunds mismatch: %v (%v)", refunds, err)
+	}
+	// Refunds included in the chain are dropped
+	chain.refunded[a.TxHash] = true
+	chain.refunded[b.TxHash] = true
+	if txs := queue.SystemTxs(&types.Header{Number: big.NewInt(12)}); len(txs) != 0 || queue.Pending() != 0 {
+		t.Fatalf("issued refunds not dropped: %d pending", queue.Pending())
+	}
+}
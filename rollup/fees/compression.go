@@ -0,0 +1,37 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package fees
+
+import (
+	"github.com/klauspost/compress/zstd"
+)
+
+// encoder compresses transaction payloads the way the batch submitter does on
+// L1. Its options are fixed, and together with the pinned library version they
+// keep the compressed sizes, and thus the L1 fees, deterministic across nodes.
+var encoder, _ = zstd.NewWriter(nil,
+	zstd.WithEncoderLevel(zstd.SpeedDefault),
+	zstd.WithEncoderConcurrency(1),
+	zstd.WithLowerEncoderMem(true),
+	zstd.WithZeroFrames(true),
+)
+
+// CompressedSize returns the estimated size of the data once compressed into
+// a batch. It's also meant for packing batches by their compressed size.
+func CompressedSize(data []byte) uint64 {
+	return uint64(len(encoder.EncodeAll(data, nil)))
+}
@@ -32,6 +32,12 @@ type Message interface {
 	Data() []byte
 }
 
+// encodedMessage is implemented by the messages carrying the signed encoding of
+// their transaction when its L1 fee is charged on it, such as types.Message.
+type encodedMessage interface {
+	Encoded() []byte
+}
+
 // StateDB represents the StateDB interface
 // required to compute the L1 fee
 type StateDB interface {
@@ -42,15 +48,19 @@ type StateDB interface {
 // CalculateL1MsgFee computes the L1 portion of the fee given
 // a Message and a StateDB
 // Reference: https://github.com/ethereum-optimism/optimism/blob/develop/l2geth/rollup/fees/rollup_fee.go
-func CalculateL1MsgFee(msg Message, state StateDB) (*big.Int, error) {
-	tx := asTransaction(msg)
-	raw, err := rlpEncode(tx)
-	if err != nil {
-		return nil, err
+func CalculateL1MsgFee(msg Message, state StateDB, compressed bool) (*big.Int, error) {
+	var raw []byte
+	if encoded, ok := msg.(encodedMessage); ok && encoded.Encoded() != nil {
+		raw = encoded.Encoded()
+	} else {
+		var err error
+		if raw, err = rlpEncode(asTransaction(msg)); err != nil {
+			return nil, err
+		}
 	}
 
 	l1BaseFee, overhead, scalar := readGPOStorageSlots(rcfg.L1GasPriceOracleAddress, state)
-	l1Fee := CalculateL1Fee(raw, overhead, l1BaseFee, scalar)
+	l1Fee := CalculateL1Fee(raw, overhead, l1BaseFee, scalar, compressed)
 	return l1Fee, nil
 }
 
@@ -101,9 +111,15 @@ func readGPOStorageSlots(addr common.Address, state StateDB) (*big.Int, *big.Int
 	return l1BaseFee.Big(), overhead.Big(), scalar.Big()
 }
 
-// CalculateL1Fee computes the L1 fee
-func CalculateL1Fee(data []byte, overhead, l1GasPrice *big.Int, scalar *big.Int) *big.Int {
-	l1GasUsed := CalculateL1GasUsed(data, overhead)
+// CalculateL1Fee computes the L1 fee, by the compressed size of the data if
+// compressed is set
+func CalculateL1Fee(data []byte, overhead, l1GasPrice *big.Int, scalar *big.Int, compressed bool) *big.Int {
+	var l1GasUsed *big.Int
+	if compressed {
+		l1GasUsed = CalculateCompressedL1GasUsed(data, overhead)
+	} else {
+		l1GasUsed = CalculateL1GasUsed(data, overhead)
+	}
 	l1Fee := new(big.Int).Mul(l1GasUsed, l1GasPrice)
 	return mulAndScale(l1Fee, scalar, rcfg.Precision)
 }
@@ -120,6 +136,19 @@ func CalculateL1GasUsed(data []byte, overhead *big.Int) *big.Int {
 	return new(big.Int).Add(l1Gas, overhead)
 }
 
+// CalculateCompressedL1GasUsed computes the L1 gas used based on the estimated
+// compressed size of the calldata and the constant sized overhead. Compressed
+// bytes are charged as non-zero bytes, and the result never exceeds the gas of
+// the raw calldata, so incompressible payloads don't pay for the compression.
+func CalculateCompressedL1GasUsed(data []byte, overhead *big.Int) *big.Int {
+	l1Gas := new(big.Int).SetUint64((CompressedSize(data) + 68) * params.TxDataNonZeroGasEIP2028)
+	l1Gas.Add(l1Gas, overhead)
+	if raw := CalculateL1GasUsed(data, overhead); raw.Cmp(l1Gas) < 0 {
+		return raw
+	}
+	return l1Gas
+}
+
 // zeroesAndOnes counts the number of 0 bytes and non 0 bytes in a byte slice
 func zeroesAndOnes(data []byte) (uint64, uint64) {
 	var zeroes uint64
@@ -162,15 +191,24 @@ func copyTransaction(tx *types.Transaction) *types.Transaction {
 	)
 }
 
-func CalculateFees(tx *types.Transaction, state StateDB) (*big.Int, *big.Int, *big.Int, error) {
-	unsigned := copyTransaction(tx)
-	raw, err := rlpEncode(unsigned)
+// l1FeeData returns the data the L1 fee of the transaction is charged on: its
+// signed encoding for the transaction types introduced by the rollup, and the
+// unsigned legacy encoding of its fields otherwise.
+func l1FeeData(tx *types.Transaction) ([]byte, error) {
+	if tx.L1FeeEncoded() {
+		return tx.MarshalBinary()
+	}
+	return rlpEncode(copyTransaction(tx))
+}
+
+func CalculateFees(tx *types.Transaction, state StateDB, compressed bool) (*big.Int, *big.Int, *big.Int, error) {
+	raw, err := l1FeeData(tx)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
 	l1BaseFee, overhead, scalar := readGPOStorageSlots(rcfg.L1GasPriceOracleAddress, state)
-	l1Fee := CalculateL1Fee(raw, overhead, l1BaseFee, scalar)
+	l1Fee := CalculateL1Fee(raw, overhead, l1BaseFee, scalar, compressed)
 
 	l2GasLimit := new(big.Int).SetUint64(tx.Gas())
 	l2Fee := new(big.Int).Mul(tx.GasPrice(), l2GasLimit)
@@ -178,7 +216,7 @@ func CalculateFees(tx *types.Transaction, state StateDB) (*big.Int, *big.Int, *b
 	return l1Fee, l2Fee, fee, nil
 }
 
-func VerifyFee(signer types.Signer, tx *types.Transaction, state StateDB) error {
+func VerifyFee(signer types.Signer, tx *types.Transaction, state StateDB, compressed bool) error {
 	from, err := types.Sender(signer, tx)
 	if err != nil {
 		return errors.New("invalid transaction: invalid sender")
@@ -186,11 +224,29 @@ func VerifyFee(signer types.Signer, tx *types.Transaction, state StateDB) error
 
 	balance := state.GetBalance(from)
 
-	l1Fee, l2Fee, _, err := CalculateFees(tx, state)
+	l1Fee, l2Fee, _, err := CalculateFees(tx, state, compressed)
 	if err != nil {
 		return fmt.Errorf("invalid transaction: %w", err)
 	}
 
+	// The fees of sponsored transactions are paid by their fee payer
+	if tx.Type() == types.SponsoredTxType {
+		payer, err := types.FeePayer(signer, tx)
+		if err != nil {
+			return errors.New("invalid transaction: invalid fee payer")
+		}
+		if payer != from {
+			if balance.Cmp(tx.Value()) < 0 {
+				return errors.New("invalid transaction: insufficient funds for value")
+			}
+			balance = state.GetBalance(payer)
+			if balance.Cmp(new(big.Int).Add(l1Fee, l2Fee)) < 0 {
+				return errors.New("invalid transaction: insufficient fee payer funds for l1fee + gas * price")
+			}
+			return nil
+		}
+	}
+
 	cost := tx.Value()
 	cost = cost.Add(cost, l2Fee)
 	if balance.Cmp(cost) < 0 {
@@ -1,10 +1,16 @@
 package fees
 
 import (
+	"bytes"
 	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
 )
 
 func TestCalculateL1Fee(t *testing.T) {
@@ -15,6 +21,65 @@ func TestCalculateL1Fee(t *testing.T) {
 	scalar := new(big.Int).SetUint64(10)
 
 	expected := new(big.Int).SetUint64(184) // 184.2
-	actual := CalculateL1Fee(data, overhead, l1BaseFee, scalar)
+	actual := CalculateL1Fee(data, overhead, l1BaseFee, scalar, false)
 	assert.Equal(t, expected, actual)
 }
+
+func TestCalculateCompressedL1Fee(t *testing.T) {
+	l1BaseFee := new(big.Int).SetUint64(15000000)
+	overhead := new(big.Int).SetUint64(100)
+	scalar := new(big.Int).SetUint64(10)
+
+	// Repetitive payloads are charged less once compressed
+	data := bytes.Repeat([]byte{0xde, 0xad, 0xbe, 0xef}, 256)
+	raw := CalculateL1Fee(data, overhead, l1BaseFee, scalar, false)
+	compressed := CalculateL1Fee(data, overhead, l1BaseFee, scalar, true)
+	assert.Equal(t, -1, compressed.Cmp(raw))
+
+	// Incompressible payloads are never charged more than the raw calldata
+	data = []byte{0, 10, 1, 0}
+	raw = CalculateL1Fee(data, overhead, l1BaseFee, scalar, false)
+	compressed = CalculateL1Fee(data, overhead, l1BaseFee, scalar, true)
+	assert.Equal(t, raw, compressed)
+}
+
+// testState is a StateDB holding the gas price oracle slots.
+type testState map[common.Hash]common.Hash
+
+func (s testState) GetState(addr common.Address, slot common.Hash) common.Hash { return s[slot] }
+func (s testState) GetBalance(addr common.Address) *big.Int                    { return new(big.Int) }
+
+func TestCalculateEncodedL1Fee(t *testing.T) {
+	state := testState{
+		rcfg.L1BaseFeeSlot: common.BigToHash(big.NewInt(15000000)),
+		rcfg.OverheadSlot:  common.BigToHash(big.NewInt(100)),
+		rcfg.ScalarSlot:    common.BigToHash(big.NewInt(1000000000)),
+	}
+	key, _ := crypto.GenerateKey()
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	tx := types.MustSignNewTx(key, signer, &types.NonceKeyTx{
+		ChainID:   big.NewInt(1),
+		NonceKey:  7,
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+		Gas:       21000,
+		To:        &common.Address{0x01},
+		Value:     big.NewInt(1),
+	})
+	// The L1 fee of the rollup transaction types is charged on their encoding,
+	// alike for the transaction and the message derived from it
+	enc, err := tx.MarshalBinary()
+	assert.NoError(t, err)
+	want := CalculateL1Fee(enc, big.NewInt(100), big.NewInt(15000000), big.NewInt(1000000000), false)
+
+	l1Fee, _, _, err := CalculateFees(tx, state, false)
+	assert.NoError(t, err)
+	assert.Equal(t, want, l1Fee)
+
+	msg, err := tx.AsMessage(signer, nil)
+	assert.NoError(t, err)
+	l1Fee, err = CalculateL1MsgFee(msg, state, false)
+	assert.NoError(t, err)
+	assert.Equal(t, want, l1Fee)
+}
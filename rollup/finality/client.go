@@ -0,0 +1,82 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package finality
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// rpcClient is an L1Client on top of the JSON-RPC API of an L1 node. The eth
+// client package can't be used, its tests depend on the eth backend.
+type rpcClient struct {
+	c *rpc.Client
+}
+
+// Dial connects to the JSON-RPC API of an L1 node.
+func Dial(url string) (L1Client, error) {
+	c, err := rpc.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return &rpcClient{c: c}, nil
+}
+
+// ChainID retrieves the chain ID of the L1 network.
+func (c *rpcClient) ChainID(ctx context.Context) (*big.Int, error) {
+	var id hexutil.Big
+	if err := c.c.CallContext(ctx, &id, "eth_chainId"); err != nil {
+		return nil, err
+	}
+	return (*big.Int)(&id), nil
+}
+
+// HeaderByNumber retrieves a header by number or block tag.
+func (c *rpcClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var head *types.Header
+	if err := c.c.CallContext(ctx, &head, "eth_getBlockByNumber", rpc.BlockNumber(number.Int64()), false); err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, ethereum.NotFound
+	}
+	return head, nil
+}
+
+// FilterLogs retrieves the logs of a block range matching the query.
+func (c *rpcClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if q.FromBlock == nil || q.ToBlock == nil {
+		return nil, errors.New("unbounded log query")
+	}
+	arg := map[string]interface{}{
+		"address":   q.Addresses,
+		"topics":    q.Topics,
+		"fromBlock": (*hexutil.Big)(q.FromBlock),
+		"toBlock":   (*hexutil.Big)(q.ToBlock),
+	}
+	var logs []types.Log
+	if err := c.c.CallContext(ctx, &logs, "eth_getLogs", arg); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -0,0 +1,397 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package finality follows the batch finalization on the rollup contract on L1
+// and advances the finalized block of the local chain accordingly.
+//
+// Once a FinalizeBatch event is itself final on L1, the last L2 block of the
+// batch, identified by the state root the batch was finalized with, becomes the
+// finalized block: it's served as the "finalized" block tag and the chain
+// refuses to reorg below it.
+//
+// The finalized batches are recorded along with the L1 block finalizing them.
+// Should one of these blocks be reorged away on L1 nonetheless, the batches are
+// unwound, a ReorgEvent is posted and the scan resumes from the last batch still
+// canonical on L1.
+package finality
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+const (
+	// syncInterval is the frequency to check L1 for newly finalized batches.
+	syncInterval = 12 * time.Second
+
+	// maxLogRange is the maximum number of L1 blocks to query logs for at once.
+	maxLogRange = 1000
+
+	// requestTimeout is the timeout of a single L1 request.
+	requestTimeout = 30 * time.Second
+)
+
+var (
+	// finalizeBatchTopic is the topic of the FinalizeBatch event emitted by the
+	// rollup contract, with the state and withdraw roots as data.
+	finalizeBatchTopic = crypto.Keccak256Hash([]byte("FinalizeBatch(uint256,bytes32,bytes32,bytes32)"))
+
+	l1SyncedGauge   = metrics.NewRegisteredGauge("rollup/finality/l1", nil)
+	finalizedGauge  = metrics.NewRegisteredGauge("rollup/finality/l2", nil)
+	batchesMeter    = metrics.NewRegisteredMeter("rollup/finality/batches", nil)
+	syncFailedMeter = metrics.NewRegisteredMeter("rollup/finality/failed", nil)
+	unwoundMeter    = metrics.NewRegisteredMeter("rollup/finality/unwound", nil)
+)
+
+var (
+	// errUnknownBatch is returned if the last block of a finalized batch isn't
+	// known locally yet.
+	errUnknownBatch = errors.New("finalized batch not imported yet")
+
+	// errChainIDMismatch is returned if the L1 endpoint serves a different chain
+	// than configured.
+	errChainIDMismatch = errors.New("L1 chain ID mismatch")
+)
+
+// ReorgEvent is posted when batches recorded as finalized were reorged away on
+// L1 and got unwound.
+type ReorgEvent struct {
+	Batches []*types.RollupBatch // Unwound batches, newest first
+}
+
+// L1Client is the view of the L1 node needed to follow batch finalization.
+type L1Client interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// blockChain is the view of the local chain needed to finalize blocks.
+type blockChain interface {
+	CurrentBlock() *types.Block
+	CurrentFinalizedBlock() *types.Block
+	GetBlockByNumber(number uint64) *types.Block
+	SetFinalized(block *types.Block) error
+}
+
+// Service advances the finalized block of the local chain as batches get
+// finalized on L1.
+type Service struct {
+	client L1Client
+	config *params.L1Config
+	chain  blockChain
+	db     ethdb.KeyValueStore
+
+	reorgFeed event.Feed
+	scope     event.SubscriptionScope
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a service following the batch finalization on L1 and registers
+// its lifecycle with the node.
+func New(stack *node.Node, client L1Client, config *params.L1Config, chain blockChain, db ethdb.KeyValueStore) *Service {
+	s := &Service{
+		client: client,
+		config: config,
+		chain:  chain,
+		db:     db,
+		quit:   make(chan struct{}),
+	}
+	stack.RegisterLifecycle(s)
+	return s
+}
+
+// Start implements node.Lifecycle, starting to follow L1.
+func (s *Service) Start() error {
+	s.wg.Add(1)
+	go s.loop()
+
+	log.Info("Started following L1 batch finalization", "contract", s.config.ScrollChainAddress)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the service.
+func (s *Service) Stop() error {
+	close(s.quit)
+	s.wg.Wait()
+	s.scope.Close()
+
+	log.Info("Stopped following L1 batch finalization")
+	return nil
+}
+
+// SubscribeReorgEvent registers a subscription of ReorgEvent.
+func (s *Service) SubscribeReorgEvent(ch chan<- ReorgEvent) event.Subscription {
+	return s.scope.Track(s.reorgFeed.Subscribe(ch))
+}
+
+// loop checks L1 for newly finalized batches until termination.
+func (s *Service) loop() {
+	defer s.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	checked := false
+	for {
+		select {
+		case <-timer.C:
+		case <-s.quit:
+			return
+		}
+		// Refuse to follow the wrong L1 network, it can't recover by retrying
+		if !checked {
+			if err := s.checkChainID(); err != nil {
+				if errors.Is(err, errChainIDMismatch) {
+					log.Error("Not following L1 batch finalization", "err", err)
+					return
+				}
+				log.Warn("Failed to query L1 chain ID", "err", err)
+				timer.Reset(syncInterval)
+				continue
+			}
+			checked = true
+		}
+		if err := s.sync(); err != nil {
+			syncFailedMeter.Mark(1)
+			if errors.Is(err, errUnknownBatch) {
+				log.Debug("Waiting for finalized batch", "err", err)
+			} else {
+				log.Warn("Failed to follow L1 batch finalization", "err", err)
+			}
+		}
+		timer.Reset(syncInterval)
+	}
+}
+
+// checkChainID verifies that the L1 endpoint serves the configured chain.
+func (s *Service) checkChainID() error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	id, err := s.client.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	if id.Uint64() != s.config.L1ChainId {
+		return fmt.Errorf("%w: have %d, want %d", errChainIDMismatch, id, s.config.L1ChainId)
+	}
+	return nil
+}
+
+// sync scans the L1 blocks finalized since the last run for batch finalization
+// events and finalizes the local blocks they cover. Progress is persisted after
+// every range of blocks fully processed.
+func (s *Service) sync() error {
+	if err := s.unwindReorged(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	head, err := s.client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	cancel()
+	if err != nil {
+		return err
+	}
+	// Start from the current L1 finality if never synced before, the first
+	// finalized batch covers all the blocks before it anyway
+	from := head.Number.Uint64()
+	if synced := rawdb.ReadRollupSyncedL1Block(s.db); synced != nil {
+		from = *synced + 1
+	}
+	for from <= head.Number.Uint64() {
+		to := from + maxLogRange - 1
+		if to > head.Number.Uint64() {
+			to = head.Number.Uint64()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		logs, err := s.client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{s.config.ScrollChainAddress},
+			Topics:    [][]common.Hash{{finalizeBatchTopic}},
+		})
+		cancel()
+		if err != nil {
+			return err
+		}
+		for _, l := range logs {
+			if l.Removed || len(l.Topics) < 2 || len(l.Data) < 2*common.HashLength {
+				continue
+			}
+			batch := &types.RollupBatch{
+				Index:        new(big.Int).SetBytes(l.Topics[1].Bytes()).Uint64(),
+				StateRoot:    common.BytesToHash(l.Data[:common.HashLength]),
+				WithdrawRoot: common.BytesToHash(l.Data[common.HashLength : 2*common.HashLength]),
+				L1Block:      l.BlockNumber,
+				L1Hash:       l.BlockHash,
+			}
+			block, err := s.finalize(batch.StateRoot)
+			if err != nil {
+				// Keep the progress up to the preceding L1 block, it gets retried
+				if l.BlockNumber > from {
+					rawdb.WriteRollupSyncedL1Block(s.db, l.BlockNumber-1)
+					l1SyncedGauge.Update(int64(l.BlockNumber - 1))
+				}
+				return err
+			}
+			batch.L2Block = block.NumberU64()
+			rawdb.WriteRollupBatch(s.db, batch)
+			rawdb.WriteLastRollupBatch(s.db, batch.Index)
+			batchesMeter.Mark(1)
+		}
+		rawdb.WriteRollupSyncedL1Block(s.db, to)
+		l1SyncedGauge.Update(int64(to))
+
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		from = to + 1
+	}
+	return nil
+}
+
+// unwindReorged verifies that the L1 blocks finalizing the recorded batches are
+// still canonical. The batches reorged away on L1 are deleted, newest first, and
+// the scan restarts after the last batch still canonical.
+func (s *Service) unwindReorged() error {
+	var (
+		unwound []*types.RollupBatch
+		valid   *types.RollupBatch
+		hashes  = make(map[uint64]common.Hash)
+	)
+	for last := rawdb.ReadLastRollupBatch(s.db); last != nil; {
+		batch := rawdb.ReadRollupBatch(s.db, *last)
+		if batch == nil {
+			break
+		}
+		hash, ok := hashes[batch.L1Block]
+		if !ok {
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			header, err := s.client.HeaderByNumber(ctx, new(big.Int).SetUint64(batch.L1Block))
+			cancel()
+			if err != nil {
+				return err
+			}
+			hash = header.Hash()
+			hashes[batch.L1Block] = hash
+		}
+		if hash == batch.L1Hash {
+			valid = batch
+			break
+		}
+		unwound = append(unwound, batch)
+		if batch.Index == 0 {
+			break
+		}
+		prev := batch.Index - 1
+		last = &prev
+	}
+	if len(unwound) == 0 {
+		return nil
+	}
+	dbBatch := s.db.NewBatch()
+	for _, batch := range unwound {
+		rawdb.DeleteRollupBatch(dbBatch, batch.Index)
+	}
+	if valid != nil {
+		rawdb.WriteLastRollupBatch(dbBatch, valid.Index)
+		rawdb.WriteRollupSyncedL1Block(dbBatch, valid.L1Block)
+	} else {
+		rawdb.DeleteLastRollupBatch(dbBatch)
+		rawdb.DeleteRollupSyncedL1Block(dbBatch)
+	}
+	if err := dbBatch.Write(); err != nil {
+		return err
+	}
+	unwoundMeter.Mark(int64(len(unwound)))
+	log.Warn("Unwound batches reorged on L1", "count", len(unwound), "from", unwound[len(unwound)-1].Index, "to", unwound[0].Index, "l1block", unwound[len(unwound)-1].L1Block)
+
+	// Finalized blocks are never reverted, the operator must sort this out
+	if finalized := s.chain.CurrentFinalizedBlock(); finalized != nil {
+		for _, batch := range unwound {
+			if batch.L2Block <= finalized.NumberU64() {
+				log.Error("Finalized block reorged on L1", "batch", batch.Index, "number", batch.L2Block, "finalized", finalized.Number())
+				break
+			}
+		}
+	}
+	s.reorgFeed.Send(ReorgEvent{Batches: unwound})
+	return nil
+}
+
+// finalize marks the last block of a batch finalized on L1 as finalized. The
+// block is the first canonical one after the current finalized block with the
+// state root the batch was finalized with.
+func (s *Service) finalize(root common.Hash) (*types.Block, error) {
+	var start uint64
+	if finalized := s.chain.CurrentFinalizedBlock(); finalized != nil {
+		// Batches finalized again after an L1 reorg unwound them cover blocks
+		// which are final already, above the last batch still recorded
+		lower := finalized.NumberU64()
+		if last := rawdb.ReadLastRollupBatch(s.db); last != nil {
+			if batch := rawdb.ReadRollupBatch(s.db, *last); batch != nil && batch.L2Block < lower {
+				lower = batch.L2Block + 1
+			}
+		}
+		for number := finalized.NumberU64(); number >= lower; number-- {
+			if block := s.chain.GetBlockByNumber(number); block != nil && block.Root() == root {
+				return block, nil
+			}
+			if number == 0 {
+				break
+			}
+		}
+		start = finalized.NumberU64() + 1
+	}
+	head := s.chain.CurrentBlock().NumberU64()
+	for number := start; number <= head; number++ {
+		block := s.chain.GetBlockByNumber(number)
+		if block == nil {
+			break
+		}
+		if block.Root() != root {
+			continue
+		}
+		if err := s.chain.SetFinalized(block); err != nil {
+			return nil, err
+		}
+		finalizedGauge.Update(int64(number))
+		log.Info("Finalized block on L1", "number", number, "hash", block.Hash(), "root", root)
+		return block, nil
+	}
+	return nil, fmt.Errorf("%w: state root %x", errUnknownBatch, root)
+}
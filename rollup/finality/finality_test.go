@@ -0,0 +1,231 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package finality
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// testL1 is an L1 node serving the given logs, with a movable finalized head.
+// The hashes of its blocks from the fork block on depend on the fork it's on.
+type testL1 struct {
+	head   uint64
+	fork   byte
+	forkAt uint64
+	logs   []types.Log
+	calls  int
+}
+
+func (l *testL1) header(number uint64) *types.Header {
+	header := &types.Header{Number: new(big.Int).SetUint64(number)}
+	if l.fork != 0 && number >= l.forkAt {
+		header.Extra = []byte{l.fork}
+	}
+	return header
+}
+
+func (l *testL1) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (l *testL1) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number.Sign() < 0 {
+		return l.header(l.head), nil
+	}
+	return l.header(number.Uint64()), nil
+}
+
+func (l *testL1) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	l.calls++
+	var logs []types.Log
+	for _, log := range l.logs {
+		if log.BlockNumber >= q.FromBlock.Uint64() && log.BlockNumber <= q.ToBlock.Uint64() && log.Address == q.Addresses[0] {
+			log.BlockHash = l.header(log.BlockNumber).Hash()
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func finalizeBatchLog(contract common.Address, number uint64, index uint64, root common.Hash) types.Log {
+	return types.Log{
+		Address:     contract,
+		Topics:      []common.Hash{finalizeBatchTopic, common.BigToHash(new(big.Int).SetUint64(index)), {}},
+		Data:        append(root.Bytes(), make([]byte, common.HashLength)...),
+		BlockNumber: number,
+	}
+}
+
+func newTestService(t *testing.T, blocks int) (*Service, *testL1, *core.BlockChain, []*types.Block) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &core.Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+		engine  = ethash.NewFaker()
+	)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	generated, _ := core.GenerateChain(gspec.Config, genesis, engine, db, blocks, nil)
+
+	l1 := &testL1{head: 100}
+	s := &Service{
+		client: l1,
+		config: &params.L1Config{L1ChainId: 1, ScrollChainAddress: common.Address{0xff}},
+		chain:  chain,
+		db:     db,
+		quit:   make(chan struct{}),
+	}
+	return s, l1, chain, generated
+}
+
+func TestFinalizeBatches(t *testing.T) {
+	s, l1, chain, blocks := newTestService(t, 10)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks[:6]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	db, config := s.db, s.config
+
+	// The first run starts at the current L1 finality
+	if err := s.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if synced := rawdb.ReadRollupSyncedL1Block(db); synced == nil || *synced != 100 {
+		t.Fatalf("synced L1 block mismatch: have %v, want 100", synced)
+	}
+	// Batches finalized on L1 finalize their last block, other contracts and
+	// batches not yet final on L1 are ignored. Logs are queried in ranges.
+	l1.logs = []types.Log{
+		finalizeBatchLog(config.ScrollChainAddress, 150, 1, blocks[2].Root()),
+		finalizeBatchLog(common.Address{0xee}, 1200, 2, blocks[4].Root()),
+		finalizeBatchLog(config.ScrollChainAddress, 1300, 2, blocks[8].Root()),
+	}
+	l1.head = 1250
+	if err := s.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if have := chain.CurrentFinalizedBlock(); have == nil || have.Hash() != blocks[2].Hash() {
+		t.Fatalf("finalized block mismatch: have %v, want #%d", have, blocks[2].NumberU64())
+	}
+	if l1.calls != 3 {
+		t.Fatalf("log queries mismatch: have %d, want 3", l1.calls)
+	}
+	// A batch not imported locally yet is retried
+	l1.head = 1400
+	if err := s.sync(); err == nil {
+		t.Fatalf("finalized unknown batch")
+	}
+	if synced := rawdb.ReadRollupSyncedL1Block(db); synced == nil || *synced != 1299 {
+		t.Fatalf("synced L1 block mismatch: have %v, want 1299", synced)
+	}
+	if _, err := chain.InsertChain(blocks[6:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if err := s.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if have := chain.CurrentFinalizedBlock(); have == nil || have.Hash() != blocks[8].Hash() {
+		t.Fatalf("finalized block mismatch: have %v, want #%d", have, blocks[8].NumberU64())
+	}
+	if synced := rawdb.ReadRollupSyncedL1Block(db); synced == nil || *synced != 1400 {
+		t.Fatalf("synced L1 block mismatch: have %v, want 1400", synced)
+	}
+}
+
+// Tests that batches finalized in L1 blocks which got reorged are unwound and
+// synced again from the new L1 chain.
+func TestUnwindReorgedBatches(t *testing.T) {
+	s, l1, chain, blocks := newTestService(t, 10)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	events := make(chan ReorgEvent, 1)
+	sub := s.SubscribeReorgEvent(events)
+	defer sub.Unsubscribe()
+
+	contract := s.config.ScrollChainAddress
+	l1.head = 99
+	if err := s.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	l1.head = 400
+	l1.logs = []types.Log{
+		finalizeBatchLog(contract, 150, 1, blocks[2].Root()),
+		finalizeBatchLog(contract, 300, 2, blocks[5].Root()),
+	}
+	if err := s.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if batch := rawdb.ReadRollupBatch(s.db, 2); batch == nil || batch.L2Block != blocks[5].NumberU64() || batch.L1Block != 300 {
+		t.Fatalf("batch record mismatch: have %+v", batch)
+	}
+	// Reorg L1 below the second batch, which gets finalized later again
+	l1.fork, l1.forkAt = 1, 200
+	l1.logs = []types.Log{
+		finalizeBatchLog(contract, 150, 1, blocks[2].Root()),
+		finalizeBatchLog(contract, 320, 2, blocks[5].Root()),
+		finalizeBatchLog(contract, 380, 3, blocks[8].Root()),
+	}
+	if err := s.sync(); err != nil {
+		t.Fatalf("failed to sync after reorg: %v", err)
+	}
+	select {
+	case ev := <-events:
+		if len(ev.Batches) != 1 || ev.Batches[0].Index != 2 || ev.Batches[0].L1Block != 300 {
+			t.Fatalf("unwound batches mismatch: have %v", ev.Batches)
+		}
+	default:
+		t.Fatal("reorg event missing")
+	}
+	if batch := rawdb.ReadRollupBatch(s.db, 1); batch == nil || batch.L1Block != 150 {
+		t.Fatalf("canonical batch record mismatch: have %+v", batch)
+	}
+	if batch := rawdb.ReadRollupBatch(s.db, 2); batch == nil || batch.L2Block != blocks[5].NumberU64() || batch.L1Block != 320 {
+		t.Fatalf("resynced batch record mismatch: have %+v", batch)
+	}
+	if last := rawdb.ReadLastRollupBatch(s.db); last == nil || *last != 3 {
+		t.Fatalf("last batch mismatch: have %v, want 3", last)
+	}
+	if have := chain.CurrentFinalizedBlock(); have == nil || have.Hash() != blocks[8].Hash() {
+		t.Fatalf("finalized block mismatch: have %v, want #%d", have, blocks[8].NumberU64())
+	}
+	// Without further reorgs, nothing is unwound
+	if err := s.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected reorg event: %v", ev.Batches)
+	default:
+	}
+}
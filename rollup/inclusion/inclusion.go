@@ -0,0 +1,192 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package inclusion enforces inclusion lists, the transactions the sequencer
+// must include within a window of blocks once they're required, as a building
+// block for censorship resistance.
+//
+// Lists are required at a block, either through the consensus API or from the
+// L1 inclusion list contract. The sequencer echoes the lists it requires in the
+// blocks it produces, so that followers require the same ones and reject the
+// blocks in which a required transaction becomes overdue.
+//
+// Since lists only hold transaction hashes, their providers must only require
+// transactions which remain valid, as the chain can't progress past the window
+// of a transaction that can't be included.
+package inclusion
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+// DefaultWindow is the default number of blocks a required transaction must be
+// included within.
+const DefaultWindow = 16
+
+var (
+	requiredMeter = metrics.NewRegisteredMeter("rollup/inclusion/required", nil)
+	includedMeter = metrics.NewRegisteredMeter("rollup/inclusion/included", nil)
+	pendingGauge  = metrics.NewRegisteredGauge("rollup/inclusion/pending", nil)
+
+	// ErrNotIncluded is returned if a block doesn't include a required
+	// transaction by the end of its window.
+	ErrNotIncluded = errors.New("required transaction not included")
+)
+
+// chainReader is the view of the chain needed to find included transactions.
+type chainReader interface {
+	GetTransactionLookup(hash common.Hash) *rawdb.LegacyTxLookupEntry
+}
+
+// List tracks the transactions required to be included in the chain until they
+// are, along with the lists queued to be required by the next block produced.
+type List struct {
+	window  uint64
+	pending map[common.Hash]uint64 // Required transactions and the last block to include them
+	queued  []common.Hash          // Transactions to require in the next block produced
+	lock    sync.Mutex
+}
+
+// NewList creates an empty inclusion list whose transactions must be included
+// within the given number of blocks.
+func NewList(window uint64) *List {
+	if window == 0 {
+		window = DefaultWindow
+	}
+	return &List{
+		window:  window,
+		pending: make(map[common.Hash]uint64),
+	}
+}
+
+// Queue queues transactions to be required by the next block produced.
+func (l *List) Queue(hashes []common.Hash) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.queued = append(l.queued, hashes...)
+}
+
+// Queued returns the transactions queued to be required by the next block
+// produced. They stay queued until a block requiring them is accepted.
+func (l *List) Queued() []common.Hash {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return append([]common.Hash{}, l.queued...)
+}
+
+// Require requires the given transactions to be included at most window blocks
+// after the block with the given number, that block included.
+func (l *List) Require(hashes []common.Hash, number uint64) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	required := make(map[common.Hash]bool, len(hashes))
+	for _, hash := range hashes {
+		required[hash] = true
+		if _, ok := l.pending[hash]; ok {
+			continue
+		}
+		l.pending[hash] = number + l.window - 1
+		requiredMeter.Mark(1)
+	}
+	queued := l.queued[:0]
+	for _, hash := range l.queued {
+		if !required[hash] {
+			queued = append(queued, hash)
+		}
+	}
+	l.queued = queued
+	pendingGauge.Update(int64(len(l.pending)))
+}
+
+// Pending returns the required transactions not included yet, the most urgent
+// first.
+func (l *List) Pending() []common.Hash {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	hashes := make([]common.Hash, 0, len(l.pending))
+	for hash := range l.pending {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		if l.pending[hashes[i]] != l.pending[hashes[j]] {
+			return l.pending[hashes[i]] < l.pending[hashes[j]]
+		}
+		return hashes[i].Hex() < hashes[j].Hex()
+	})
+	return hashes
+}
+
+// Verify checks that the block includes the required transactions whose window
+// ends with it, unless already included by the chain before it. The block may
+// require new transactions itself, which count as required.
+func (l *List) Verify(chain chainReader, block *types.Block, required []common.Hash) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	number := block.NumberU64()
+	check := func(hash common.Hash, deadline uint64) error {
+		if deadline > number || block.Transaction(hash) != nil {
+			return nil
+		}
+		if lookup := chain.GetTransactionLookup(hash); lookup != nil && lookup.BlockIndex < number {
+			return nil
+		}
+		return fmt.Errorf("%w: %x due by block %d", ErrNotIncluded, hash, deadline)
+	}
+	for hash, deadline := range l.pending {
+		if err := check(hash, deadline); err != nil {
+			return err
+		}
+	}
+	for _, hash := range required {
+		if _, ok := l.pending[hash]; !ok {
+			if err := check(hash, number+l.window-1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Included drops the required transactions included by an accepted block, and
+// those whose window ended with it.
+func (l *List) Included(block *types.Block) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	number := block.NumberU64()
+	for hash, deadline := range l.pending {
+		if block.Transaction(hash) != nil {
+			delete(l.pending, hash)
+			includedMeter.Mark(1)
+		} else if deadline <= number {
+			delete(l.pending, hash)
+		}
+	}
+	pendingGauge.Update(int64(len(l.pending)))
+}
@@ -0,0 +1,181 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package inclusion
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// testChain is a chain whose transactions were included at the given blocks.
+type testChain map[common.Hash]uint64
+
+func (c testChain) GetTransactionLookup(hash common.Hash) *rawdb.LegacyTxLookupEntry {
+	if number, ok := c[hash]; ok {
+		return &rawdb.LegacyTxLookupEntry{BlockIndex: number}
+	}
+	return nil
+}
+
+// testBlock creates a block with the given number including the transactions.
+func testBlock(number uint64, txs ...*types.Transaction) *types.Block {
+	header := &types.Header{Number: new(big.Int).SetUint64(number)}
+	return types.NewBlockWithHeader(header).WithBody(txs, nil)
+}
+
+func testTx(nonce uint64) *types.Transaction {
+	return types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+}
+
+func TestListWindow(t *testing.T) {
+	var (
+		list  = NewList(3)
+		chain = testChain{}
+		tx1   = testTx(1)
+		tx2   = testTx(2)
+	)
+	list.Queue([]common.Hash{tx1.Hash(), tx2.Hash()})
+	list.Require([]common.Hash{tx1.Hash()}, 10)
+	if queued := list.Queued(); !reflect.DeepEqual(queued, []common.Hash{tx2.Hash()}) {
+		t.Fatalf("queued mismatch: have %x, want %x", queued, tx2.Hash())
+	}
+	// Blocks before the end of the window don't need to include the transaction
+	for number := uint64(10); number < 12; number++ {
+		block := testBlock(number)
+		if err := list.Verify(chain, block, nil); err != nil {
+			t.Fatalf("block %d rejected: %v", number, err)
+		}
+		list.Included(block)
+	}
+	// The last block of the window must include it
+	if err := list.Verify(chain, testBlock(12), nil); !errors.Is(err, ErrNotIncluded) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrNotIncluded)
+	}
+	block := testBlock(12, tx1)
+	if err := list.Verify(chain, block, nil); err != nil {
+		t.Fatalf("including block rejected: %v", err)
+	}
+	list.Included(block)
+	if pending := list.Pending(); len(pending) != 0 {
+		t.Fatalf("included transaction still pending: %x", pending)
+	}
+}
+
+func TestListIncludedBefore(t *testing.T) {
+	var (
+		list  = NewList(1)
+		tx    = testTx(1)
+		chain = testChain{tx.Hash(): 4}
+	)
+	// Transactions included before being required are satisfied
+	list.Require([]common.Hash{tx.Hash()}, 5)
+	if err := list.Verify(chain, testBlock(5), nil); err != nil {
+		t.Fatalf("block rejected: %v", err)
+	}
+	// Transactions required by the block itself with a window of one must be
+	// included by it
+	other := testTx(2)
+	if err := list.Verify(chain, testBlock(5), []common.Hash{other.Hash()}); !errors.Is(err, ErrNotIncluded) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrNotIncluded)
+	}
+	if err := list.Verify(chain, testBlock(5, other), []common.Hash{other.Hash()}); err != nil {
+		t.Fatalf("including block rejected: %v", err)
+	}
+}
+
+func TestListPending(t *testing.T) {
+	var (
+		list = NewList(4)
+		tx1  = testTx(1)
+		tx2  = testTx(2)
+	)
+	list.Require([]common.Hash{tx1.Hash()}, 8)
+	list.Require([]common.Hash{tx2.Hash()}, 6)
+	list.Require([]common.Hash{tx2.Hash()}, 9) // Doesn't extend the window
+
+	if pending := list.Pending(); !reflect.DeepEqual(pending, []common.Hash{tx2.Hash(), tx1.Hash()}) {
+		t.Fatalf("pending mismatch: have %x", pending)
+	}
+	// Transactions whose window ended are dropped
+	list.Included(testBlock(9))
+	if pending := list.Pending(); !reflect.DeepEqual(pending, []common.Hash{tx1.Hash()}) {
+		t.Fatalf("pending mismatch after window: have %x", pending)
+	}
+}
+
+// testL1 is an L1 node serving the given logs below its finalized head.
+type testL1 struct {
+	head uint64
+	logs []types.Log
+}
+
+func (l *testL1) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: new(big.Int).SetUint64(l.head)}, nil
+}
+
+func (l *testL1) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	for _, log := range l.logs {
+		if log.BlockNumber >= q.FromBlock.Uint64() && log.BlockNumber <= q.ToBlock.Uint64() {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func TestL1Source(t *testing.T) {
+	var (
+		contract = common.Address{0x01}
+		hash1    = common.Hash{0x01}
+		hash2    = common.Hash{0x02}
+		l1       = &testL1{head: 100}
+		list     = NewList(0)
+		source   = &L1Source{client: l1, contract: contract, list: list}
+	)
+	l1.logs = []types.Log{
+		{BlockNumber: 90, Topics: []common.Hash{transactionRequiredTopic, {0x09}}},
+		{BlockNumber: 101, Topics: []common.Hash{transactionRequiredTopic, hash1}},
+		{BlockNumber: 102, Topics: []common.Hash{transactionRequiredTopic, hash2}, Removed: true},
+		{BlockNumber: 103, Topics: []common.Hash{transactionRequiredTopic, hash2}},
+	}
+	// The first sync only records the finalized head
+	if err := source.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if queued := list.Queued(); len(queued) != 0 {
+		t.Fatalf("transactions required before start queued: %x", queued)
+	}
+	l1.head = 102
+	if err := source.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	l1.head = 103
+	if err := source.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if queued := list.Queued(); !reflect.DeepEqual(queued, []common.Hash{hash1, hash2}) {
+		t.Fatalf("queued mismatch: have %x, want %x", queued, []common.Hash{hash1, hash2})
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package inclusion
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+const (
+	// pollInterval is the frequency to check L1 for new inclusion lists.
+	pollInterval = 12 * time.Second
+
+	// requestTimeout is the timeout of a single L1 request.
+	requestTimeout = 30 * time.Second
+
+	// maxLogRange is the maximum number of L1 blocks to query logs for at once.
+	maxLogRange = 1000
+)
+
+// transactionRequiredTopic is the topic of the event of the L1 inclusion list
+// contract requiring a transaction, TransactionRequired(bytes32 indexed hash).
+var transactionRequiredTopic = crypto.Keccak256Hash([]byte("TransactionRequired(bytes32)"))
+
+// L1Client is the view of the L1 node needed to follow the inclusion lists.
+type L1Client interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// L1Source follows the transactions required by the L1 inclusion list contract
+// in finalized L1 blocks, queueing them to be required by the next block the
+// sequencer produces. Transactions required before the node started are not
+// followed.
+type L1Source struct {
+	client   L1Client
+	contract common.Address
+	list     *List
+	synced   uint64 // Last L1 block scanned for inclusion lists, 0 until first sync
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewL1Source creates a source of the inclusion lists of the given L1 contract
+// and registers its lifecycle with the node.
+func NewL1Source(stack *node.Node, client L1Client, contract common.Address, list *List) *L1Source {
+	s := &L1Source{
+		client:   client,
+		contract: contract,
+		list:     list,
+		quit:     make(chan struct{}),
+	}
+	stack.RegisterLifecycle(s)
+	return s
+}
+
+// Start implements node.Lifecycle, starting to follow the inclusion lists.
+func (s *L1Source) Start() error {
+	s.wg.Add(1)
+	go s.loop()
+
+	log.Info("Started following L1 inclusion lists", "contract", s.contract)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the source.
+func (s *L1Source) Stop() error {
+	close(s.quit)
+	s.wg.Wait()
+
+	log.Info("Stopped following L1 inclusion lists")
+	return nil
+}
+
+// loop syncs the inclusion lists until termination.
+func (s *L1Source) loop() {
+	defer s.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+		case <-s.quit:
+			return
+		}
+		if err := s.sync(); err != nil {
+			log.Warn("Failed to sync L1 inclusion lists", "err", err)
+		}
+		timer.Reset(pollInterval)
+	}
+}
+
+// sync scans the L1 blocks finalized since the last run for required
+// transactions and queues them.
+func (s *L1Source) sync() error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	head, err := s.client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	cancel()
+	if err != nil {
+		return err
+	}
+	if s.synced == 0 {
+		s.synced = head.Number.Uint64()
+		return nil
+	}
+	for from := s.synced + 1; from <= head.Number.Uint64(); from = s.synced + 1 {
+		to := from + maxLogRange - 1
+		if to > head.Number.Uint64() {
+			to = head.Number.Uint64()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		logs, err := s.client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{s.contract},
+			Topics:    [][]common.Hash{{transactionRequiredTopic}},
+		})
+		cancel()
+		if err != nil {
+			return err
+		}
+		var hashes []common.Hash
+		for _, l := range logs {
+			if l.Removed || len(l.Topics) < 2 {
+				continue
+			}
+			hashes = append(hashes, l.Topics[1])
+		}
+		if len(hashes) > 0 {
+			s.list.Queue(hashes)
+			log.Info("Queued L1 inclusion list", "txs", len(hashes), "from", from, "to", to)
+		}
+		s.synced = to
+	}
+	return nil
+}
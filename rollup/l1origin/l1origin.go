@@ -0,0 +1,324 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package l1origin tracks the L1 block the sequencer builds on and writes it
+// into the L1Block system contract at the top of every L2 block, so that L2
+// contracts and indexers can reason about the L1 context.
+//
+// The origin trails the L1 head by a few confirmations, keeping shallow L1
+// reorgs from invalidating it. It's written by a system transaction calling
+//
+//	setL1BlockValues(uint64 number, uint64 timestamp, uint256 baseFee, bytes32 hash)
+//
+// which also lets the origin of any block be recovered from the block alone.
+package l1origin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+const (
+	// pollInterval is the frequency to check L1 for a new origin.
+	pollInterval = 12 * time.Second
+
+	// requestTimeout is the timeout of a single L1 request.
+	requestTimeout = 30 * time.Second
+
+	// confirmations is the number of blocks the origin trails the L1 head by.
+	confirmations = 6
+)
+
+var (
+	// setL1BlockValuesSelector is the selector of the L1Block contract method
+	// setting the L1 origin.
+	setL1BlockValuesSelector = crypto.Keccak256([]byte("setL1BlockValues(uint64,uint64,uint256,bytes32)"))[:4]
+
+	// errInvalidCalldata is returned if the calldata of an L1 origin update can't
+	// be decoded.
+	errInvalidCalldata = errors.New("invalid L1 origin calldata")
+
+	// ErrMissingOrigin is returned if a block bounded by its L1 origin doesn't
+	// set one.
+	ErrMissingOrigin = errors.New("missing L1 origin")
+
+	// ErrTimeBeforeOrigin is returned if the timestamp of a block precedes the
+	// timestamp of its L1 origin.
+	ErrTimeBeforeOrigin = errors.New("timestamp before L1 origin")
+
+	// ErrTimeExceedsDrift is returned if the timestamp of a block exceeds the
+	// timestamp of its L1 origin by more than the allowed drift.
+	ErrTimeExceedsDrift = errors.New("timestamp exceeds L1 origin drift")
+
+	// ErrInvalidOrigin is returned if the L1 origin set by a block can't be
+	// decoded, is set twice or moves back from the origin of its parent.
+	ErrInvalidOrigin = errors.New("invalid L1 origin")
+)
+
+// Origin is the L1 block an L2 block was built on.
+type Origin struct {
+	Number  uint64
+	Time    uint64
+	BaseFee *big.Int
+	Hash    common.Hash
+}
+
+// Calldata returns the calldata of the L1Block contract call setting the origin.
+func (o *Origin) Calldata() []byte {
+	data := make([]byte, 4+4*common.HashLength)
+	copy(data, setL1BlockValuesSelector)
+	new(big.Int).SetUint64(o.Number).FillBytes(data[4:36])
+	new(big.Int).SetUint64(o.Time).FillBytes(data[36:68])
+	if o.BaseFee != nil {
+		o.BaseFee.FillBytes(data[68:100])
+	}
+	copy(data[100:], o.Hash[:])
+	return data
+}
+
+// DecodeCalldata decodes the origin set by an L1Block contract call.
+func DecodeCalldata(data []byte) (*Origin, error) {
+	if len(data) != 4+4*common.HashLength || !bytes.Equal(data[:4], setL1BlockValuesSelector) {
+		return nil, errInvalidCalldata
+	}
+	number, timestamp := new(big.Int).SetBytes(data[4:36]), new(big.Int).SetBytes(data[36:68])
+	if !number.IsUint64() || !timestamp.IsUint64() {
+		return nil, errInvalidCalldata
+	}
+	return &Origin{
+		Number:  number.Uint64(),
+		Time:    timestamp.Uint64(),
+		BaseFee: new(big.Int).SetBytes(data[68:100]),
+		Hash:    common.BytesToHash(data[100:]),
+	}, nil
+}
+
+// FromBlock returns the origin written by the system transactions of a block,
+// or nil if the block doesn't set one.
+func FromBlock(block *types.Block) *Origin {
+	return fromTxs(block.Transactions())
+}
+
+// fromTxs returns the origin written by the leading system transactions of the
+// given block transactions, or nil if they don't set one.
+func fromTxs(txs types.Transactions) *Origin {
+	for _, tx := range txs[:types.SystemTxCount(txs)] {
+		if *tx.To() != rcfg.L1BlockAddress {
+			continue
+		}
+		if origin, err := DecodeCalldata(tx.Data()); err == nil {
+			return origin
+		}
+	}
+	return nil
+}
+
+// VerifyOrigin verifies that the L1 origin updates among the system transactions
+// of the block with the given header decode, that there's at most one, and that
+// the origin it sets is consistent with the header and with the given origin of
+// its parent: it never follows the block timestamp and never moves back, an
+// unchanged origin number keeping the same L1 block.
+func VerifyOrigin(header *types.Header, txs types.Transactions, parent *Origin) error {
+	var origin *Origin
+	for _, tx := range txs[:types.SystemTxCount(txs)] {
+		if *tx.To() != rcfg.L1BlockAddress {
+			continue
+		}
+		decoded, err := DecodeCalldata(tx.Data())
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidOrigin, err)
+		}
+		if origin != nil {
+			return fmt.Errorf("%w: set twice", ErrInvalidOrigin)
+		}
+		origin = decoded
+	}
+	if origin == nil {
+		return nil
+	}
+	if header.Time < origin.Time {
+		return fmt.Errorf("%w: have %d, origin %d (#%d)", ErrTimeBeforeOrigin, header.Time, origin.Time, origin.Number)
+	}
+	if parent == nil {
+		return nil
+	}
+	switch {
+	case origin.Number < parent.Number:
+		return fmt.Errorf("%w: moved back from #%d to #%d", ErrInvalidOrigin, parent.Number, origin.Number)
+	case origin.Number == parent.Number && (origin.Time != parent.Time || origin.Hash != parent.Hash || origin.BaseFee.Cmp(parent.BaseFee) != 0):
+		return fmt.Errorf("%w: changed #%d", ErrInvalidOrigin, origin.Number)
+	case origin.Time < parent.Time:
+		return fmt.Errorf("%w: moved back from time %d to %d", ErrInvalidOrigin, parent.Time, origin.Time)
+	}
+	return nil
+}
+
+// VerifyTime verifies that the timestamp of the block with the given header and
+// transactions lies within the window the rollup contract accepts, which starts
+// at the timestamp of the L1 origin the block sets and spans the configured
+// drift. The L1 origin trails the L1 head, so the window ends before the L1
+// time the batch of the block is committed at.
+func VerifyTime(config *params.ChainConfig, header *types.Header, txs types.Transactions) error {
+	if !config.Scroll.IsL1Time(header.Number) {
+		return nil
+	}
+	origin := fromTxs(txs)
+	if origin == nil {
+		return ErrMissingOrigin
+	}
+	if header.Time < origin.Time {
+		return fmt.Errorf("%w: have %d, origin %d (#%d)", ErrTimeBeforeOrigin, header.Time, origin.Time, origin.Number)
+	}
+	if drift := config.Scroll.MaxL1TimeDrift(header.Number); header.Time-origin.Time > drift {
+		return fmt.Errorf("%w: have %d, origin %d (#%d), max drift %d", ErrTimeExceedsDrift, header.Time, origin.Time, origin.Number, drift)
+	}
+	return nil
+}
+
+// L1Client is the view of the L1 node needed to track the origin.
+type L1Client interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Tracker follows the L1 chain and supplies the system transactions writing
+// the current origin into the blocks produced locally.
+type Tracker struct {
+	client L1Client
+	origin atomic.Value // Current *Origin, unset until first retrieved
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a tracker of the L1 origin and registers its lifecycle with the
+// node.
+func New(stack *node.Node, client L1Client) *Tracker {
+	t := &Tracker{
+		client: client,
+		quit:   make(chan struct{}),
+	}
+	stack.RegisterLifecycle(t)
+	return t
+}
+
+// Start implements node.Lifecycle, starting to track the L1 origin.
+func (t *Tracker) Start() error {
+	t.wg.Add(1)
+	go t.loop()
+
+	log.Info("Started tracking L1 origin", "contract", rcfg.L1BlockAddress)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the tracker.
+func (t *Tracker) Stop() error {
+	close(t.quit)
+	t.wg.Wait()
+
+	log.Info("Stopped tracking L1 origin")
+	return nil
+}
+
+// Origin returns the current L1 origin, or nil if not known yet.
+func (t *Tracker) Origin() *Origin {
+	if origin, ok := t.origin.Load().(*Origin); ok {
+		return origin
+	}
+	return nil
+}
+
+// SystemTxs returns the system transaction writing the current L1 origin into
+// the block with the given header. It's meant to be the system transaction
+// source of the miner.
+func (t *Tracker) SystemTxs(header *types.Header) types.Transactions {
+	origin := t.Origin()
+	if origin == nil {
+		return nil
+	}
+	return types.Transactions{types.NewSystemTx(header.Number.Uint64(), rcfg.L1BlockAddress, origin.Calldata())}
+}
+
+// loop updates the origin until termination.
+func (t *Tracker) loop() {
+	defer t.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+		case <-t.quit:
+			return
+		}
+		if err := t.update(); err != nil {
+			log.Warn("Failed to update L1 origin", "err", err)
+		}
+		timer.Reset(pollInterval)
+	}
+}
+
+// update retrieves the L1 block the configured number of confirmations below
+// the L1 head and makes it the current origin. The origin never moves back.
+func (t *Tracker) update() error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	head, err := t.client.HeaderByNumber(ctx, big.NewInt(int64(rpc.LatestBlockNumber)))
+	if err != nil {
+		return err
+	}
+	number := head.Number.Uint64()
+	if number < confirmations {
+		return nil
+	}
+	number -= confirmations
+	if current := t.Origin(); current != nil && current.Number >= number {
+		return nil
+	}
+	header, err := t.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return err
+	}
+	origin := &Origin{
+		Number:  header.Number.Uint64(),
+		Time:    header.Time,
+		BaseFee: new(big.Int),
+		Hash:    header.Hash(),
+	}
+	if header.BaseFee != nil {
+		origin.BaseFee.Set(header.BaseFee)
+	}
+	t.origin.Store(origin)
+
+	log.Debug("Updated L1 origin", "number", origin.Number, "hash", origin.Hash)
+	return nil
+}
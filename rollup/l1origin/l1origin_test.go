@@ -0,0 +1,140 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package l1origin
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+// testL1 is a fake L1 chain of the given length.
+type testL1 struct {
+	head uint64
+}
+
+func (l *testL1) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number.Int64() == int64(rpc.LatestBlockNumber) {
+		number = new(big.Int).SetUint64(l.head)
+	}
+	return &types.Header{Number: number, Time: 1000 + number.Uint64(), BaseFee: big.NewInt(7)}, nil
+}
+
+// Tests that the tracker follows the L1 chain behind the confirmations and that
+// the origin it writes can be recovered from the block.
+func TestTracker(t *testing.T) {
+	l1 := &testL1{head: confirmations - 1}
+	tracker := &Tracker{client: l1}
+
+	header := &types.Header{Number: big.NewInt(5)}
+	if err := tracker.update(); err != nil || tracker.SystemTxs(header) != nil {
+		t.Fatalf("origin set before enough L1 confirmations: %v", err)
+	}
+	l1.head = 100
+	if err := tracker.update(); err != nil {
+		t.Fatalf("failed to update origin: %v", err)
+	}
+	origin := tracker.Origin()
+	if origin == nil || origin.Number != 100-confirmations || origin.Time != 1100-confirmations || origin.BaseFee.Uint64() != 7 {
+		t.Fatalf("origin mismatch: have %+v", origin)
+	}
+	// The origin never moves back, even if the L1 node lags behind
+	l1.head = 90
+	if err := tracker.update(); err != nil || tracker.Origin() != origin {
+		t.Fatalf("origin moved back: have %+v, want %+v", tracker.Origin(), origin)
+	}
+	txs := tracker.SystemTxs(header)
+	if len(txs) != 1 || !txs[0].IsSystemTx() || txs[0].Nonce() != 5 || *txs[0].To() != rcfg.L1BlockAddress {
+		t.Fatalf("system transactions mismatch: %v", txs)
+	}
+	block := types.NewBlock(header, txs, nil, nil, trie.NewStackTrie(nil))
+	if have := FromBlock(block); have == nil || have.Number != origin.Number || have.Time != origin.Time || have.Hash != origin.Hash || have.BaseFee.Cmp(origin.BaseFee) != 0 {
+		t.Fatalf("recovered origin mismatch: have %+v, want %+v", have, origin)
+	}
+	if have := FromBlock(types.NewBlockWithHeader(header)); have != nil {
+		t.Fatalf("origin recovered from block without one: %+v", have)
+	}
+}
+
+// Tests that block timestamps are bounded by the L1 origin the block sets.
+func TestVerifyTime(t *testing.T) {
+	config := &params.ChainConfig{Scroll: params.ScrollConfig{
+		SystemTx: &params.SystemTxConfig{Block: big.NewInt(0), Contracts: []common.Address{rcfg.L1BlockAddress}},
+		L1Time:   &params.L1TimeConfig{Block: big.NewInt(2), MaxDrift: 60},
+	}}
+	origin := &Origin{Number: 100, Time: 1000, BaseFee: big.NewInt(7)}
+
+	for i, tt := range []struct {
+		number uint64
+		time   uint64
+		origin *Origin
+		err    error
+	}{
+		{1, 900, nil, nil}, // Before the fork
+		{2, 1000, nil, ErrMissingOrigin},
+		{2, 999, origin, ErrTimeBeforeOrigin},
+		{2, 1000, origin, nil},
+		{2, 1060, origin, nil},
+		{2, 1061, origin, ErrTimeExceedsDrift},
+	} {
+		header := &types.Header{Number: new(big.Int).SetUint64(tt.number), Time: tt.time}
+		var txs types.Transactions
+		if tt.origin != nil {
+			txs = types.Transactions{types.NewSystemTx(tt.number, rcfg.L1BlockAddress, tt.origin.Calldata())}
+		}
+		if err := VerifyTime(config, header, txs); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that the L1 origin a block sets decodes, is set once and never moves
+// back from the origin of its parent.
+func TestVerifyOrigin(t *testing.T) {
+	parent := &Origin{Number: 100, Time: 1000, BaseFee: big.NewInt(7), Hash: common.Hash{0x01}}
+	update := func(origin *Origin) *types.Transaction {
+		return types.NewSystemTx(2, rcfg.L1BlockAddress, origin.Calldata())
+	}
+	for i, tt := range []struct {
+		txs    types.Transactions
+		parent *Origin
+		err    error
+	}{
+		{nil, parent, nil},
+		{types.Transactions{update(parent)}, parent, nil},
+		{types.Transactions{update(&Origin{Number: 101, Time: 1012, BaseFee: big.NewInt(8)})}, parent, nil},
+		{types.Transactions{update(&Origin{Number: 99, Time: 1000, BaseFee: big.NewInt(7)})}, parent, ErrInvalidOrigin},
+		{types.Transactions{update(&Origin{Number: 100, Time: 1000, BaseFee: big.NewInt(7), Hash: common.Hash{0x02}})}, parent, ErrInvalidOrigin},
+		{types.Transactions{update(&Origin{Number: 101, Time: 999, BaseFee: big.NewInt(7)})}, parent, ErrInvalidOrigin},
+		{types.Transactions{update(&Origin{Number: 101, Time: 1021, BaseFee: big.NewInt(7)})}, nil, ErrTimeBeforeOrigin},
+		{types.Transactions{update(parent), update(parent)}, nil, ErrInvalidOrigin},
+		{types.Transactions{types.NewSystemTx(2, rcfg.L1BlockAddress, []byte{0x01})}, nil, ErrInvalidOrigin},
+	} {
+		header := &types.Header{Number: big.NewInt(2), Time: 1020}
+		if err := VerifyOrigin(header, tt.txs, tt.parent); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
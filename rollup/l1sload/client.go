@@ -0,0 +1,66 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package l1sload
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rpc"
+)
+
+// rpcClient is an L1Client on top of the JSON-RPC API of an L1 node.
+type rpcClient struct {
+	c *rpc.Client
+}
+
+// Dial connects to the JSON-RPC API of an L1 node.
+func Dial(url string) (L1Client, error) {
+	c, err := rpc.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return &rpcClient{c: c}, nil
+}
+
+// HeaderByNumber retrieves a header by number.
+func (c *rpcClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var head *types.Header
+	if err := c.c.CallContext(ctx, &head, "eth_getBlockByNumber", rpc.BlockNumber(number.Int64()), false); err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, ethereum.NotFound
+	}
+	return head, nil
+}
+
+// GetProof retrieves the Merkle proof of an account and some of its storage
+// slots as of the given block.
+func (c *rpcClient) GetProof(ctx context.Context, addr common.Address, slots []common.Hash, number *big.Int) (*AccountProof, error) {
+	var proof *AccountProof
+	if err := c.c.CallContext(ctx, &proof, "eth_getProof", addr, slots, rpc.BlockNumber(number.Int64())); err != nil {
+		return nil, err
+	}
+	if proof == nil {
+		return nil, ethereum.NotFound
+	}
+	return proof, nil
+}
@@ -0,0 +1,182 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package l1sload supplies the L1SLOAD precompile with L1 storage values. They
+// are read from an L1 node and verified against the L1 origin of the executing
+// L2 block, so that a faulty L1 node can't make the local node diverge.
+//
+// The header chain is verified from the origin, whose hash is committed to in
+// the L2 state, back to the block read, and the storage value is verified by
+// its Merkle proof against the state root of that block. Like for the origin
+// tracker, header hashes are computed over the fields of the header type.
+package l1sload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethdb/memorydb"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+const (
+	// requestTimeout is the timeout of a storage read, including the retrieval
+	// of the headers.
+	requestTimeout = 10 * time.Second
+
+	// headerCacheSize is the number of verified L1 headers cached, covering the
+	// readable window of a few origins.
+	headerCacheSize = 4 * params.L1SloadWindow
+)
+
+var (
+	errHeaderMismatch = errors.New("L1 header not matching the origin chain")
+	errProofMismatch  = errors.New("L1 storage proof not matching the request")
+)
+
+// AccountProof is the Merkle proof of an L1 account and of some of its storage
+// slots, as returned by eth_getProof.
+type AccountProof struct {
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	StorageProof []StorageProof  `json:"storageProof"`
+}
+
+// StorageProof is the Merkle proof of an L1 storage slot.
+type StorageProof struct {
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// L1Client is the view of the L1 node needed to read its storage.
+type L1Client interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	GetProof(ctx context.Context, addr common.Address, slots []common.Hash, number *big.Int) (*AccountProof, error)
+}
+
+// Reader reads proven L1 storage values. It implements vm.L1StateReader.
+type Reader struct {
+	client  L1Client
+	headers *lru.Cache // Verified L1 headers by hash
+}
+
+// New creates a reader of the L1 storage on top of the given L1 node.
+func New(client L1Client) *Reader {
+	headers, _ := lru.New(int(headerCacheSize))
+	return &Reader{client: client, headers: headers}
+}
+
+// StorageAt returns the value of a storage slot of an L1 account as of the
+// given L1 block, proven against the header chain ending at the L1 origin block
+// with the given number and hash.
+func (r *Reader) StorageAt(origin uint64, originHash common.Hash, number uint64, addr common.Address, slot common.Hash) (common.Hash, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	header, err := r.header(ctx, origin, originHash, number)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	proof, err := r.client.GetProof(ctx, addr, []common.Hash{slot}, header.Number)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return VerifyStorage(header.Root, addr, slot, proof)
+}
+
+// header returns the L1 header with the given number, verified to be an
+// ancestor of the given origin block.
+func (r *Reader) header(ctx context.Context, origin uint64, originHash common.Hash, number uint64) (*types.Header, error) {
+	if number > origin {
+		return nil, fmt.Errorf("L1 block %d after origin %d", number, origin)
+	}
+	hash := originHash
+	for n := origin; ; n-- {
+		var header *types.Header
+		if cached, ok := r.headers.Get(hash); ok {
+			header = cached.(*types.Header)
+		} else {
+			var err error
+			if header, err = r.client.HeaderByNumber(ctx, new(big.Int).SetUint64(n)); err != nil {
+				return nil, err
+			}
+			if header.Hash() != hash || header.Number.Uint64() != n {
+				return nil, fmt.Errorf("%w: block %d, have %v, want %v", errHeaderMismatch, n, header.Hash(), hash)
+			}
+			r.headers.Add(hash, header)
+		}
+		if n == number {
+			return header, nil
+		}
+		hash = header.ParentHash
+	}
+}
+
+// VerifyStorage returns the value of a storage slot of an L1 account proven by
+// the given proof against an L1 state root. Absent accounts and slots are
+// proven to hold zero.
+func VerifyStorage(root common.Hash, addr common.Address, slot common.Hash, proof *AccountProof) (common.Hash, error) {
+	if len(proof.StorageProof) != 1 {
+		return common.Hash{}, errProofMismatch
+	}
+	enc, err := trie.VerifyProof(root, crypto.Keccak256(addr.Bytes()), proofDB(proof.AccountProof))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid L1 account proof: %v", err)
+	}
+	if enc == nil {
+		return common.Hash{}, nil
+	}
+	// L1 accounts are encoded as (nonce, balance, storage root, code hash)
+	var account struct {
+		Nonce    uint64
+		Balance  *big.Int
+		Root     common.Hash
+		CodeHash []byte
+	}
+	if err := rlp.DecodeBytes(enc, &account); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid L1 account: %v", err)
+	}
+	enc, err = trie.VerifyProof(account.Root, crypto.Keccak256(slot.Bytes()), proofDB(proof.StorageProof[0].Proof))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid L1 storage proof: %v", err)
+	}
+	if enc == nil {
+		return common.Hash{}, nil
+	}
+	_, value, _, err := rlp.Split(enc)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid L1 storage value: %v", err)
+	}
+	return common.BytesToHash(value), nil
+}
+
+// proofDB returns a database of the trie nodes of a proof, keyed by hash.
+func proofDB(nodes []hexutil.Bytes) *memorydb.Database {
+	db := memorydb.New()
+	for _, node := range nodes {
+		db.Put(crypto.Keccak256(node), node)
+	}
+	return db
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package l1sload
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethdb/memorydb"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+var (
+	testAccount = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testSlot    = common.HexToHash("0x02")
+	testValue   = common.HexToHash("0x2a")
+)
+
+// testL1 is an L1 chain whose state holds a single storage value.
+type testL1 struct {
+	headers     []*types.Header
+	account     *trie.Trie
+	storage     *trie.Trie
+	headerCalls int
+}
+
+func newTestL1(t *testing.T, blocks int) *testL1 {
+	storage, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	value, _ := rlp.EncodeToBytes(common.TrimLeftZeroes(testValue.Bytes()))
+	storage.Update(crypto.Keccak256(testSlot.Bytes()), value)
+
+	account, _ := rlp.EncodeToBytes([]interface{}{uint64(1), big.NewInt(0), storage.Hash(), crypto.Keccak256(nil)})
+	state, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	state.Update(crypto.Keccak256(testAccount.Bytes()), account)
+
+	l1 := &testL1{account: state, storage: storage}
+	for i := 0; i < blocks; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Root: state.Hash(), Difficulty: common.Big0}
+		if i > 0 {
+			header.ParentHash = l1.headers[i-1].Hash()
+		}
+		l1.headers = append(l1.headers, header)
+	}
+	return l1
+}
+
+func (l1 *testL1) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	l1.headerCalls++
+	if number.Uint64() >= uint64(len(l1.headers)) {
+		return nil, ethereum.NotFound
+	}
+	return l1.headers[number.Uint64()], nil
+}
+
+func (l1 *testL1) GetProof(ctx context.Context, addr common.Address, slots []common.Hash, number *big.Int) (*AccountProof, error) {
+	proof := new(AccountProof)
+	db := memorydb.New()
+	if err := l1.account.Prove(crypto.Keccak256(addr.Bytes()), 0, db); err != nil {
+		return nil, err
+	}
+	proof.AccountProof = proofNodes(db)
+	for _, slot := range slots {
+		db := memorydb.New()
+		if err := l1.storage.Prove(crypto.Keccak256(slot.Bytes()), 0, db); err != nil {
+			return nil, err
+		}
+		proof.StorageProof = append(proof.StorageProof, StorageProof{Proof: proofNodes(db)})
+	}
+	return proof, nil
+}
+
+func proofNodes(db *memorydb.Database) []hexutil.Bytes {
+	var nodes []hexutil.Bytes
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		nodes = append(nodes, common.CopyBytes(it.Value()))
+	}
+	return nodes
+}
+
+func TestStorageAt(t *testing.T) {
+	l1 := newTestL1(t, 10)
+	reader := New(l1)
+	origin := l1.headers[9]
+
+	// Values are proven at the origin and its ancestors
+	for _, number := range []uint64{9, 5} {
+		value, err := reader.StorageAt(9, origin.Hash(), number, testAccount, testSlot)
+		if err != nil {
+			t.Fatalf("block %d: failed to read storage: %v", number, err)
+		}
+		if value != testValue {
+			t.Errorf("block %d: value mismatch: have %v, want %v", number, value, testValue)
+		}
+	}
+	// The verified headers are cached
+	calls := l1.headerCalls
+	if _, err := reader.StorageAt(9, origin.Hash(), 7, testAccount, testSlot); err != nil {
+		t.Fatalf("failed to read storage: %v", err)
+	}
+	if l1.headerCalls != calls {
+		t.Errorf("headers retrieved again: %d calls", l1.headerCalls-calls)
+	}
+	// Absent slots and accounts hold zero
+	if value, err := reader.StorageAt(9, origin.Hash(), 9, testAccount, common.HexToHash("0x03")); err != nil || value != (common.Hash{}) {
+		t.Errorf("absent slot: have %v, %v, want zero", value, err)
+	}
+	if value, err := reader.StorageAt(9, origin.Hash(), 9, common.HexToAddress("0x02"), testSlot); err != nil || value != (common.Hash{}) {
+		t.Errorf("absent account: have %v, %v, want zero", value, err)
+	}
+	// Headers off the origin chain are rejected
+	if _, err := New(l1).StorageAt(9, common.HexToHash("0x01"), 5, testAccount, testSlot); !errors.Is(err, errHeaderMismatch) {
+		t.Errorf("unknown origin: have %v, want %v", err, errHeaderMismatch)
+	}
+}
+
+func TestVerifyStorage(t *testing.T) {
+	l1 := newTestL1(t, 1)
+	proof, err := l1.GetProof(context.Background(), testAccount, []common.Hash{testSlot}, common.Big0)
+	if err != nil {
+		t.Fatalf("failed to prove storage: %v", err)
+	}
+	if value, err := VerifyStorage(l1.headers[0].Root, testAccount, testSlot, proof); err != nil || value != testValue {
+		t.Errorf("valid proof: have %v, %v, want %v", value, err, testValue)
+	}
+	// Proofs of other slots or against other roots are rejected
+	if _, err := VerifyStorage(common.HexToHash("0x01"), testAccount, testSlot, proof); err == nil {
+		t.Error("proof against wrong root accepted")
+	}
+	if value, err := VerifyStorage(l1.headers[0].Root, testAccount, common.HexToHash("0x03"), proof); err == nil && value == testValue {
+		t.Error("proof of another slot accepted")
+	}
+	if _, err := VerifyStorage(l1.headers[0].Root, testAccount, testSlot, &AccountProof{AccountProof: proof.AccountProof}); err != errProofMismatch {
+		t.Errorf("missing storage proof: have %v, want %v", err, errProofMismatch)
+	}
+}
@@ -0,0 +1,491 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package migration migrates the state of the chain from MPT to zktrie in the
+// background, ahead of the zktrie cutover.
+//
+// The state of the head block is first converted account by account, resuming
+// across restarts. The state diff of every following block is then applied to
+// the converted state, recording the zktrie root of the state of every block
+// along the way. The first block after the cutover executes on the migrated
+// state of its parent and commits to the state with zktrie from then on.
+//
+// The MPT states are left untouched: the chain can be rolled back below the
+// cutover, and cross it again once the migration caught up.
+package migration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto/codehash"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/event"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/metrics"
+	"github.com/scroll-tech/go-ethereum/node"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/scroll-tech/go-ethereum/trie"
+)
+
+const (
+	// commitThreshold is the number of accounts and slots converted between two
+	// commits of the converted state, which are the points to resume from.
+	commitThreshold = 100_000
+
+	// maxRewind is the maximum number of blocks to walk back from the head to
+	// find a migrated state to apply the diffs on, the state is converted anew
+	// beyond.
+	maxRewind = core.TriesInMemory
+)
+
+var (
+	migratedGauge = metrics.NewRegisteredGauge("rollup/migration/block", nil)
+	accountsMeter = metrics.NewRegisteredMeter("rollup/migration/accounts", nil)
+	slotsMeter    = metrics.NewRegisteredMeter("rollup/migration/slots", nil)
+	failedMeter   = metrics.NewRegisteredMeter("rollup/migration/failed", nil)
+)
+
+var (
+	// errInterrupted is returned if the migration is interrupted by the shutdown.
+	errInterrupted = errors.New("state migration interrupted")
+
+	// errMissingPreimage is returned if the address or slot of a trie key isn't
+	// known, the keys of a zktrie being the plain addresses and slots.
+	errMissingPreimage = errors.New("missing trie key preimage")
+)
+
+// blockChain is the view of the local chain needed to migrate its state.
+type blockChain interface {
+	Config() *params.ChainConfig
+	CurrentBlock() *types.Block
+	GetHeader(hash common.Hash, number uint64) *types.Header
+	StateCache() state.Database
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// Service migrates the state of the chain to zktrie as the chain advances,
+// until the zktrie cutover.
+type Service struct {
+	chain  blockChain
+	config *params.ChainConfig
+	db     ethdb.Database
+	zkdb   state.Database // Zktrie state database the state is migrated into
+
+	lock sync.RWMutex
+	err  error // Last migration failure, nil once it succeeds again
+
+	wake chan struct{}
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a service migrating the state of the chain to zktrie and
+// registers its lifecycle with the node.
+func New(stack *node.Node, chain blockChain, db ethdb.Database) *Service {
+	s := newService(chain, db)
+	stack.RegisterLifecycle(s)
+	return s
+}
+
+func newService(chain blockChain, db ethdb.Database) *Service {
+	return &Service{
+		chain:  chain,
+		config: chain.Config(),
+		db:     db,
+		zkdb:   state.NewDatabaseWithConfig(db, &trie.Config{Zktrie: true}),
+		wake:   make(chan struct{}, 1),
+		quit:   make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, starting to migrate the state.
+func (s *Service) Start() error {
+	s.wg.Add(2)
+	go s.loop()
+	go s.migrateLoop()
+
+	log.Info("Started migrating the state to zktrie", "cutover", s.config.Scroll.ZktrieCutoverBlock)
+	return nil
+}
+
+// Stop implements node.Lifecycle, interrupting the migration.
+func (s *Service) Stop() error {
+	close(s.quit)
+	s.wg.Wait()
+	return nil
+}
+
+// Progress returns the progress of the migration, or nil if it hasn't started.
+func (s *Service) Progress() *types.StateMigration {
+	return rawdb.ReadStateMigration(s.db)
+}
+
+// Err returns the error the last migration attempt failed with, if any.
+func (s *Service) Err() error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.err
+}
+
+// loop wakes the migration up on every new head. The migration runs apart, not
+// to hold up the chain head feed during the conversion.
+func (s *Service) loop() {
+	defer s.wg.Done()
+
+	heads := make(chan core.ChainHeadEvent, 16)
+	sub := s.chain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-heads:
+			select {
+			case s.wake <- struct{}{}:
+			default:
+			}
+		case <-sub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// migrateLoop migrates the state up to the head whenever woken up.
+func (s *Service) migrateLoop() {
+	defer s.wg.Done()
+
+	for {
+		err := s.migrate()
+		if errors.Is(err, errInterrupted) {
+			return
+		}
+		if err != nil {
+			log.Warn("Failed to migrate the state to zktrie", "err", err)
+			failedMeter.Mark(1)
+		}
+		s.lock.Lock()
+		s.err = err
+		s.lock.Unlock()
+
+		select {
+		case <-s.wake:
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// migrate migrates the state of the head block, or of the last block before
+// the cutover if the chain is past it already.
+func (s *Service) migrate() error {
+	var (
+		cutover = s.config.Scroll.ZktrieCutoverBlock
+		head    = s.chain.CurrentBlock().Header()
+	)
+	if head.Number.Cmp(cutover) >= 0 {
+		// The chain is past the cutover and commits to the zktrie state itself.
+		// The state stays migrated up to the cutover in case of a rollback.
+		return nil
+	}
+	// Finish the interrupted conversion if its state is still available
+	if progress := rawdb.ReadStateMigration(s.db); progress != nil && !progress.Converted {
+		if _, err := trie.NewSecure(progress.Root, s.chain.StateCache().TrieDB()); err == nil {
+			log.Info("Resuming state conversion to zktrie", "number", progress.Number, "root", progress.Root, "accounts", progress.Accounts)
+			return s.convert(progress)
+		}
+		log.Warn("State conversion to zktrie lost its state, restarting", "number", progress.Number, "root", progress.Root)
+	}
+	// Apply the state diffs of the blocks after the last one migrated, walking
+	// back from the head for a reorg might have happened
+	var (
+		headers []*types.Header
+		header  = head
+	)
+	for {
+		if _, migrated := rawdb.ReadZktrieRoot(s.db, header.Root); migrated {
+			break
+		}
+		headers = append(headers, header)
+		if header.Number.Sign() == 0 || len(headers) > maxRewind {
+			log.Info("Converting state to zktrie", "number", head.Number, "root", head.Root)
+			return s.convert(&types.StateMigration{Number: head.Number.Uint64(), Hash: head.Hash(), Root: head.Root})
+		}
+		parent := s.chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+		if parent == nil {
+			return fmt.Errorf("missing header #%d [%x..]", header.Number.Uint64()-1, header.ParentHash[:4])
+		}
+		header = parent
+	}
+	for i := len(headers) - 1; i >= 0; i-- {
+		if err := s.apply(header, headers[i]); err != nil {
+			return err
+		}
+		header = headers[i]
+
+		select {
+		case <-s.quit:
+			return errInterrupted
+		default:
+		}
+	}
+	return nil
+}
+
+// convert converts the state of a block account by account, committing the
+// converted state regularly to resume from.
+func (s *Service) convert(progress *types.StateMigration) error {
+	// Keep the state alive in the chain's trie database while converting
+	triedb := s.chain.StateCache().TrieDB()
+	triedb.Reference(progress.Root, common.Hash{})
+	defer triedb.Dereference(progress.Root)
+
+	tr, err := trie.NewSecure(progress.Root, triedb)
+	if err != nil {
+		return err
+	}
+	statedb, err := state.New(progress.ZkRoot, s.zkdb, nil)
+	if err != nil {
+		return err
+	}
+	var (
+		start   = time.Now()
+		logged  = time.Now()
+		pending int
+		it      = trie.NewIterator(tr.NodeIterator(progress.Marker))
+	)
+	for it.Next() {
+		if progress.Marker != nil && bytes.Compare(it.Key, progress.Marker) <= 0 {
+			continue
+		}
+		addr := tr.GetKey(it.Key)
+		if addr == nil {
+			return fmt.Errorf("%w: account %x", errMissingPreimage, it.Key)
+		}
+		slots, err := s.migrateAccount(statedb, common.BytesToAddress(addr), nil, tr)
+		if err != nil {
+			return err
+		}
+		progress.Accounts++
+		progress.Slots += uint64(slots)
+		progress.Marker = common.CopyBytes(it.Key)
+		accountsMeter.Mark(1)
+		slotsMeter.Mark(int64(slots))
+
+		if pending += 1 + slots; pending >= commitThreshold {
+			if err := s.commit(statedb, progress); err != nil {
+				return err
+			}
+			if statedb, err = state.New(progress.ZkRoot, s.zkdb, nil); err != nil {
+				return err
+			}
+			pending = 0
+
+			if time.Since(logged) > 8*time.Second {
+				log.Info("Converting state to zktrie", "number", progress.Number, "accounts", progress.Accounts, "slots", progress.Slots, "at", common.BytesToHash(progress.Marker), "elapsed", common.PrettyDuration(time.Since(start)))
+				logged = time.Now()
+			}
+			select {
+			case <-s.quit:
+				return errInterrupted
+			default:
+			}
+		}
+	}
+	if it.Err != nil {
+		return it.Err
+	}
+	progress.Converted, progress.Marker = true, nil
+	if err := s.commit(statedb, progress); err != nil {
+		return err
+	}
+	log.Info("Converted state to zktrie", "number", progress.Number, "root", progress.Root, "zkroot", progress.ZkRoot, "accounts", progress.Accounts, "slots", progress.Slots, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// apply applies the state diff of a block to the migrated state of its parent.
+func (s *Service) apply(parent, header *types.Header) error {
+	zkRoot, _ := rawdb.ReadZktrieRoot(s.db, parent.Root)
+
+	triedb := s.chain.StateCache().TrieDB()
+	oldTr, err := trie.NewSecure(parent.Root, triedb)
+	if err != nil {
+		return err
+	}
+	newTr, err := trie.NewSecure(header.Root, triedb)
+	if err != nil {
+		return err
+	}
+	statedb, err := state.New(zkRoot, s.zkdb, nil)
+	if err != nil {
+		return err
+	}
+	err = forEachDiff(oldTr, newTr, func(key []byte) error {
+		addr := newTr.GetKey(key)
+		if addr == nil {
+			return fmt.Errorf("%w: account %x", errMissingPreimage, key)
+		}
+		_, err := s.migrateAccount(statedb, common.BytesToAddress(addr), oldTr, newTr)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	progress := rawdb.ReadStateMigration(s.db)
+	if progress == nil {
+		progress = new(types.StateMigration)
+	}
+	progress.Number, progress.Hash, progress.Root = header.Number.Uint64(), header.Hash(), header.Root
+	progress.Converted, progress.Marker = true, nil
+	return s.commit(statedb, progress)
+}
+
+// migrateAccount sets an account of the migrated state to its value in a new
+// MPT state, updating the storage slots changed since an old MPT state (nil if
+// converting the whole account). It returns the number of slots set.
+func (s *Service) migrateAccount(statedb *state.StateDB, addr common.Address, oldTr, newTr *trie.SecureTrie) (int, error) {
+	account, err := readAccount(newTr, addr)
+	if err != nil {
+		return 0, err
+	}
+	if account == nil {
+		statedb.Suicide(addr)
+		return 0, nil
+	}
+	statedb.SetNonce(addr, account.Nonce)
+	statedb.SetBalance(addr, account.Balance)
+	if codeHash := common.BytesToHash(account.KeccakCodeHash); statedb.GetKeccakCodeHash(addr) != codeHash {
+		code := rawdb.ReadCode(s.db, codeHash)
+		if len(code) == 0 && codeHash != codehash.EmptyKeccakCodeHash {
+			return 0, fmt.Errorf("missing code %x of account %x", codeHash, addr)
+		}
+		statedb.SetCode(addr, code)
+	}
+	// Set the slots changed since the old state
+	oldRoot := types.EmptyRootHash
+	if oldTr != nil {
+		old, err := readAccount(oldTr, addr)
+		if err != nil {
+			return 0, err
+		}
+		if old != nil {
+			oldRoot = old.Root
+		}
+	}
+	if oldRoot == account.Root {
+		return 0, nil
+	}
+	triedb := s.chain.StateCache().TrieDB()
+	oldStorage, err := trie.NewSecure(oldRoot, triedb)
+	if err != nil {
+		return 0, err
+	}
+	newStorage, err := trie.NewSecure(account.Root, triedb)
+	if err != nil {
+		return 0, err
+	}
+	var slots int
+	err = forEachDiff(oldStorage, newStorage, func(key []byte) error {
+		slot := newStorage.GetKey(key)
+		if slot == nil {
+			return fmt.Errorf("%w: slot %x of account %x", errMissingPreimage, key, addr)
+		}
+		enc, err := newStorage.TryGet(slot)
+		if err != nil {
+			return err
+		}
+		value, err := state.MPTCommitment.DecodeStorage(enc)
+		if err != nil {
+			return err
+		}
+		statedb.SetState(addr, common.BytesToHash(slot), value)
+		slots++
+		return nil
+	})
+	return slots, err
+}
+
+// commit commits the migrated state and records the progress along with it.
+func (s *Service) commit(statedb *state.StateDB, progress *types.StateMigration) error {
+	root, err := statedb.Commit(false)
+	if err != nil {
+		return err
+	}
+	if err := s.zkdb.TrieDB().Commit(root, false, nil); err != nil {
+		return err
+	}
+	progress.ZkRoot = root
+
+	batch := s.db.NewBatch()
+	if progress.Converted {
+		rawdb.WriteZktrieRoot(batch, progress.Root, root)
+		migratedGauge.Update(int64(progress.Number))
+	}
+	rawdb.WriteStateMigration(batch, progress)
+	return batch.Write()
+}
+
+// readAccount reads an account from an MPT state, or nil if it doesn't exist.
+func readAccount(tr *trie.SecureTrie, addr common.Address) (*types.StateAccount, error) {
+	enc, err := tr.TryGet(addr.Bytes())
+	if err != nil || len(enc) == 0 {
+		return nil, err
+	}
+	return state.MPTCommitment.DecodeAccount(enc)
+}
+
+// forEachDiff calls fn once with the hashed key of every leaf added, changed or
+// removed between two tries.
+func forEachDiff(oldTr, newTr *trie.SecureTrie, fn func(key []byte) error) error {
+	// All leaves are new if there was no trie before
+	if oldTr.Hash() == types.EmptyRootHash {
+		it := trie.NewIterator(newTr.NodeIterator(nil))
+		for it.Next() {
+			if err := fn(it.Key); err != nil {
+				return err
+			}
+		}
+		return it.Err
+	}
+	seen := make(map[string]struct{})
+	for _, tries := range [][2]*trie.SecureTrie{{oldTr, newTr}, {newTr, oldTr}} {
+		diff, _ := trie.NewDifferenceIterator(tries[0].NodeIterator(nil), tries[1].NodeIterator(nil))
+		it := trie.NewIterator(diff)
+		for it.Next() {
+			if _, ok := seen[string(it.Key)]; ok {
+				continue
+			}
+			seen[string(it.Key)] = struct{}{}
+			if err := fn(it.Key); err != nil {
+				return err
+			}
+		}
+		if it.Err != nil {
+			return it.Err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/consensus/ethash"
+	"github.com/scroll-tech/go-ethereum/core"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/state"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/core/vm"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+)
+
+// Tests that the state is converted to zktrie, kept in step with the chain
+// block by block, and that the chain crosses the cutover on the migrated state
+// and can be rolled back below it.
+func TestStateMigration(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Scroll.MaxTxPerBlock = nil
+	config.Scroll.ZktrieCutoverBlock = big.NewInt(6)
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		storer = common.Address{0x51} // Sets the slot of the block number, clears the one of its parent
+		db     = rawdb.NewMemoryDatabase()
+		gspec  = &core.Genesis{Config: &config, Alloc: core.GenesisAlloc{
+			addr:   {Balance: big.NewInt(params.Ether)},
+			storer: {Balance: common.Big0, Code: common.FromHex("600143556000600143035500")},
+		}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(&config)
+	)
+	chain, err := core.NewBlockChain(db, &core.CacheConfig{TrieDirtyDisabled: true, Preimages: true}, &config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	generate := func(parent *types.Block, n int) []*types.Block {
+		blocks, _ := core.GenerateChain(&config, parent, ethash.NewFaker(), db, n, func(i int, b *core.BlockGen) {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), storer, common.Big1, 50_000, b.BaseFee(), nil), signer, key)
+			b.AddTx(tx)
+			tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(addr), common.BigToAddress(b.Number()), common.Big1, 21_000, b.BaseFee(), nil), signer, key)
+			b.AddTx(tx)
+		})
+		if _, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("failed to insert blocks: %v", err)
+		}
+		return blocks
+	}
+	s := newService(chain, db)
+
+	// Convert the state of the head, then follow the chain
+	blocks := generate(genesis, 3)
+	if err := s.migrate(); err != nil {
+		t.Fatalf("failed to convert state: %v", err)
+	}
+	progress := s.Progress()
+	if progress == nil || !progress.Converted || progress.Hash != blocks[2].Hash() {
+		t.Fatalf("conversion progress mismatch: have %+v, want block #3 converted", progress)
+	}
+	blocks = append(blocks, generate(blocks[2], 2)...)
+	if err := s.migrate(); err != nil {
+		t.Fatalf("failed to migrate state: %v", err)
+	}
+	zkRoot, migrated := rawdb.ReadZktrieRoot(db, blocks[4].Root())
+	if !migrated {
+		t.Fatalf("state of block #5 not migrated")
+	}
+	// The diffs applied must yield the same state as a conversion
+	fresh := &types.StateMigration{Number: 5, Hash: blocks[4].Hash(), Root: blocks[4].Root()}
+	if err := s.convert(fresh); err != nil {
+		t.Fatalf("failed to convert state: %v", err)
+	}
+	if fresh.ZkRoot != zkRoot {
+		t.Fatalf("migrated state mismatch: have %x, want %x", zkRoot, fresh.ZkRoot)
+	}
+	// Cross the cutover, the blocks committing to the zktrie state
+	cutover := generate(blocks[4], 3)
+	statedb, err := chain.State()
+	if err != nil {
+		t.Fatalf("failed to open state past the cutover: %v", err)
+	}
+	if statedb.Database().Commitment() != state.ZktrieCommitment {
+		t.Fatalf("state past the cutover not in zktrie")
+	}
+	if statedb.GetState(storer, common.BigToHash(big.NewInt(8))) != common.BigToHash(common.Big1) || statedb.GetState(storer, common.BigToHash(big.NewInt(7))) != (common.Hash{}) {
+		t.Fatalf("storage mismatch past the cutover")
+	}
+	if err := s.migrate(); err != nil {
+		t.Fatalf("migration failed past the cutover: %v", err)
+	}
+	// Roll back below the cutover and cross it again
+	if err := chain.SetHead(4); err != nil {
+		t.Fatalf("failed to roll back: %v", err)
+	}
+	if head := chain.CurrentBlock().NumberU64(); head != 4 {
+		t.Fatalf("head mismatch after rollback: have #%d, want #4", head)
+	}
+	if _, err := chain.State(); err != nil {
+		t.Fatalf("MPT state missing after rollback: %v", err)
+	}
+	if _, err := chain.InsertChain(append(blocks[4:], cutover...)); err != nil {
+		t.Fatalf("failed to cross the cutover again: %v", err)
+	}
+	if head := chain.CurrentBlock().Hash(); head != cutover[2].Hash() {
+		t.Fatalf("head mismatch after crossing again: have %x, want %x", head, cutover[2].Hash())
+	}
+}
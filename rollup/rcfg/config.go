@@ -24,4 +24,18 @@ var (
 	L1BaseFeeSlot           = common.BigToHash(big.NewInt(1))
 	OverheadSlot            = common.BigToHash(big.NewInt(2))
 	ScalarSlot              = common.BigToHash(big.NewInt(3))
+
+	// L1BlockAddress is the address of the L1Block system contract, which
+	// the sequencer keeps informed about the L1 origin of every block
+	L1BlockAddress    = common.HexToAddress("0x5300000000000000000000000000000000000006")
+	L1BlockNumberSlot = common.BigToHash(big.NewInt(0))
+	L1BlockHashSlot   = common.BigToHash(big.NewInt(3))
+
+	// L1FeeRefundAddress is the system address the sequencer issues L1 fee
+	// refunds to, which are paid natively out of the fee vault
+	L1FeeRefundAddress = common.HexToAddress("0x5300000000000000000000000000000000000007")
+
+	// NonceManagerAddress is the system address in whose storage the nonces of
+	// the nonce keys of the accounts are tracked
+	NonceManagerAddress = common.HexToAddress("0x5300000000000000000000000000000000000008")
 )
@@ -0,0 +1,198 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package withdrawtrie
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/rollup/rcfg"
+)
+
+// MaxHeight is the maximum height of the withdraw trie, matching the
+// AppendOnlyMerkleTree of the L2MessageQueue predeploy.
+const MaxHeight = 40
+
+var (
+	// AppendMessageEventTopic is the topic of the AppendMessage(uint256,bytes32)
+	// event emitted by the L2MessageQueue predeploy for every L2 to L1 message.
+	AppendMessageEventTopic = crypto.Keccak256Hash([]byte("AppendMessage(uint256,bytes32)"))
+
+	// ErrUnknownMessage is returned if a proof is requested for a message which
+	// is not part of the tree.
+	ErrUnknownMessage = errors.New("unknown withdraw message")
+
+	// ErrUntracked is returned if the withdraw trie was not maintained from
+	// genesis and thus cannot serve proofs.
+	ErrUntracked = errors.New("withdraw trie not tracked")
+
+	zeroHashes [MaxHeight]common.Hash
+)
+
+func init() {
+	for i := 1; i < MaxHeight; i++ {
+		zeroHashes[i] = crypto.Keccak256Hash(zeroHashes[i-1][:], zeroHashes[i-1][:])
+	}
+}
+
+type nodeKey struct {
+	level uint8
+	index uint64
+}
+
+// Tree is the node side replica of the append-only Merkle tree of L2 to L1
+// messages maintained by the L2MessageQueue predeploy, whose root is stored in
+// its WithdrawTrieRootSlot. Unlike the contract, all nodes are persisted so that
+// proofs can be generated for any message.
+type Tree struct {
+	db    ethdb.KeyValueReader
+	count uint64
+	dirty map[nodeKey]common.Hash
+}
+
+// OpenTree opens the tree containing the first count messages stored in the
+// database. Any messages above count are discarded.
+func OpenTree(db ethdb.KeyValueReader, count uint64) *Tree {
+	t := &Tree{db: db, count: count, dirty: make(map[nodeKey]common.Hash)}
+	if count > 0 {
+		t.update(count - 1)
+	}
+	return t
+}
+
+// Count returns the number of messages in the tree.
+func (t *Tree) Count() uint64 {
+	return t.count
+}
+
+// height returns the number of levels above the leaves.
+func (t *Tree) height() int {
+	if t.count == 0 {
+		return 0
+	}
+	return bits.Len64(t.count - 1)
+}
+
+// node returns the node at the given position, or the zero hash of the level if
+// the node is beyond the last message.
+func (t *Tree) node(level int, index uint64) common.Hash {
+	if t.count == 0 || index > (t.count-1)>>level {
+		return zeroHashes[level]
+	}
+	if hash, ok := t.dirty[nodeKey{uint8(level), index}]; ok {
+		return hash
+	}
+	return rawdb.ReadWithdrawTrieNode(t.db, uint8(level), index)
+}
+
+// update recomputes all ancestors of the given leaf.
+func (t *Tree) update(index uint64) {
+	for level := 0; level < t.height(); level++ {
+		parent := index >> (level + 1)
+		left, right := t.node(level, parent<<1), t.node(level, parent<<1|1)
+		t.dirty[nodeKey{uint8(level + 1), parent}] = crypto.Keccak256Hash(left[:], right[:])
+	}
+}
+
+// Append adds a message hash to the tree.
+func (t *Tree) Append(hash common.Hash) {
+	index := t.count
+	t.count++
+	t.dirty[nodeKey{0, index}] = hash
+	t.update(index)
+}
+
+// Root returns the root of the tree, which is the zero hash for an empty tree.
+func (t *Tree) Root() common.Hash {
+	if t.count == 0 {
+		return common.Hash{}
+	}
+	return t.node(t.height(), 0)
+}
+
+// Proof is the Merkle proof of a message against the root of the tree.
+type Proof struct {
+	Index       uint64
+	MessageHash common.Hash
+	Root        common.Hash
+	Siblings    []common.Hash // Sibling hashes from the leaf level upwards
+}
+
+// Verify checks that the proof is valid.
+func (p *Proof) Verify() bool {
+	return VerifyProof(p.MessageHash, p.Index, p.Siblings, p.Root)
+}
+
+// Prove returns the proof of the message at the given index.
+func (t *Tree) Prove(index uint64) (*Proof, error) {
+	if index >= t.count {
+		return nil, ErrUnknownMessage
+	}
+	siblings := make([]common.Hash, t.height())
+	for level := range siblings {
+		siblings[level] = t.node(level, (index>>level)^1)
+	}
+	return &Proof{Index: index, MessageHash: t.node(0, index), Root: t.Root(), Siblings: siblings}, nil
+}
+
+// Commit writes all modified nodes into the given database.
+func (t *Tree) Commit(db ethdb.KeyValueWriter) {
+	for key, hash := range t.dirty {
+		rawdb.WriteWithdrawTrieNode(db, key.level, key.index, hash)
+	}
+	t.dirty = make(map[nodeKey]common.Hash)
+}
+
+// VerifyProof checks that the given message hash is at the given index of the
+// tree with the given root.
+func VerifyProof(hash common.Hash, index uint64, proof []common.Hash, root common.Hash) bool {
+	for level, sibling := range proof {
+		if (index>>level)&1 == 0 {
+			hash = crypto.Keccak256Hash(hash[:], sibling[:])
+		} else {
+			hash = crypto.Keccak256Hash(sibling[:], hash[:])
+		}
+	}
+	return hash == root
+}
+
+// MessageHashes extracts the L2 to L1 message hashes appended to the tree by
+// the given receipts, along with the index of the first message.
+func MessageHashes(receipts types.Receipts) (uint64, []common.Hash) {
+	var (
+		first  uint64
+		hashes []common.Hash
+	)
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			if l.Address != rcfg.L2MessageQueueAddress || len(l.Topics) == 0 || l.Topics[0] != AppendMessageEventTopic || len(l.Data) != 64 {
+				continue
+			}
+			if len(hashes) == 0 {
+				first = new(big.Int).SetBytes(l.Data[:32]).Uint64()
+			}
+			hashes = append(hashes, common.BytesToHash(l.Data[32:]))
+		}
+	}
+	return first, hashes
+}
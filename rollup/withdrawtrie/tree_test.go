@@ -0,0 +1,119 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package withdrawtrie
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+// contractTree mirrors the AppendOnlyMerkleTree of the L2MessageQueue predeploy.
+type contractTree struct {
+	next     uint64
+	branches [MaxHeight]common.Hash
+	root     common.Hash
+}
+
+func (c *contractTree) append(hash common.Hash) {
+	index, height := c.next, 0
+	for index != 0 {
+		if index%2 == 0 {
+			c.branches[height] = hash
+			hash = crypto.Keccak256Hash(hash[:], zeroHashes[height][:])
+		} else {
+			hash = crypto.Keccak256Hash(c.branches[height][:], hash[:])
+		}
+		height++
+		index >>= 1
+	}
+	c.branches[height] = hash
+	c.root = hash
+	c.next++
+}
+
+func messageHash(i int) common.Hash {
+	return crypto.Keccak256Hash([]byte{byte(i), byte(i >> 8)})
+}
+
+func TestTreeMatchesContract(t *testing.T) {
+	var (
+		db       = rawdb.NewMemoryDatabase()
+		contract = new(contractTree)
+	)
+	for i := 0; i < 70; i++ {
+		// Commit in uneven chunks to exercise reopening the tree
+		tree := OpenTree(db, uint64(i))
+		tree.Append(messageHash(i))
+		tree.Commit(db)
+
+		contract.append(messageHash(i))
+		if root := tree.Root(); root != contract.root {
+			t.Fatalf("message %d: root mismatch: have %x, want %x", i, root, contract.root)
+		}
+	}
+	tree := OpenTree(db, 70)
+	for i := uint64(0); i < 70; i++ {
+		proof, err := tree.Prove(i)
+		if err != nil {
+			t.Fatalf("message %d: failed to prove: %v", i, err)
+		}
+		if proof.MessageHash != messageHash(int(i)) || !proof.Verify() {
+			t.Fatalf("message %d: invalid proof", i)
+		}
+	}
+	if _, err := tree.Prove(70); err != ErrUnknownMessage {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrUnknownMessage)
+	}
+}
+
+func TestTreeRewind(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+
+	tree := OpenTree(db, 0)
+	for i := 0; i < 13; i++ {
+		tree.Append(messageHash(i))
+	}
+	tree.Commit(db)
+
+	// Rewind and append a different set of messages, as done on reorgs
+	contract := new(contractTree)
+	for i := 0; i < 5; i++ {
+		contract.append(messageHash(i))
+	}
+	tree = OpenTree(db, 5)
+	if root := tree.Root(); root != contract.root {
+		t.Fatalf("rewound root mismatch: have %x, want %x", root, contract.root)
+	}
+	for i := 100; i < 103; i++ {
+		tree.Append(messageHash(i))
+		contract.append(messageHash(i))
+	}
+	tree.Commit(db)
+
+	tree = OpenTree(db, 8)
+	if root := tree.Root(); root != contract.root {
+		t.Fatalf("root mismatch: have %x, want %x", root, contract.root)
+	}
+	for i := uint64(0); i < 8; i++ {
+		if proof, err := tree.Prove(i); err != nil || !proof.Verify() {
+			t.Fatalf("message %d: invalid proof: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader is the HTTP header carrying the API key of a client. WebSocket
+// clients which can't set headers may pass it in the apikey query parameter.
+const APIKeyHeader = "X-Api-Key"
+
+// AccessRule allows or denies methods to the clients it matches: those sending
+// one of its API keys, or connecting from one of its networks. A rule without
+// keys and networks matches all clients.
+//
+// Methods are listed by name (e.g. "debug_traceTransaction"), by namespace (e.g.
+// "debug") or as "*" for all of them.
+type AccessRule struct {
+	Keys     []string `toml:",omitempty"` // API keys of the clients
+	Networks []string `toml:",omitempty"` // Source networks of the clients in CIDR notation
+	Allow    []string `toml:",omitempty"` // Methods allowed to the clients
+	Deny     []string `toml:",omitempty"` // Methods denied to the clients, taking precedence over Allow
+}
+
+// AccessControl enforces access rules on the calls served. The first rule
+// matching the client and listing the method decides whether the call is
+// allowed, calls not listed by any rule are allowed.
+//
+// A nil access control allows all calls.
+type AccessControl struct {
+	rules []accessRule
+}
+
+// accessRule is a parsed AccessRule.
+type accessRule struct {
+	keys     map[string]bool
+	networks []*net.IPNet
+	allow    []string
+	deny     []string
+}
+
+// peerInfo identifies the client of a connection for access control.
+type peerInfo struct {
+	ip     net.IP // Source address, nil if unknown
+	apiKey string // API key sent by the client, empty if none
+}
+
+// peerCodec is implemented by the codecs identifying the client of their
+// connection.
+type peerCodec interface {
+	peerInfo() peerInfo
+}
+
+// newPeerInfo identifies the client sending the given HTTP request. Forwarding
+// headers set by proxies are not trusted.
+func newPeerInfo(r *http.Request) peerInfo {
+	peer := peerInfo{apiKey: r.Header.Get(APIKeyHeader)}
+	if peer.apiKey == "" {
+		peer.apiKey = r.URL.Query().Get("apikey")
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer.ip = net.ParseIP(host)
+	return peer
+}
+
+// NewAccessControl creates an access control enforcing the given rules. It
+// returns nil if there are no rules, as all calls are allowed then.
+func NewAccessControl(rules []AccessRule) (*AccessControl, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	ac := new(AccessControl)
+	for i, rule := range rules {
+		parsed := accessRule{
+			keys:  make(map[string]bool),
+			allow: rule.Allow,
+			deny:  rule.Deny,
+		}
+		for _, key := range rule.Keys {
+			parsed.keys[key] = true
+		}
+		for _, network := range rule.Networks {
+			_, ipnet, err := net.ParseCIDR(network)
+			if err != nil {
+				return nil, fmt.Errorf("access rule %d: invalid network %q: %v", i, network, err)
+			}
+			parsed.networks = append(parsed.networks, ipnet)
+		}
+		ac.rules = append(ac.rules, parsed)
+	}
+	return ac, nil
+}
+
+// matches reports whether the rule applies to the given client.
+func (r *accessRule) matches(peer peerInfo) bool {
+	if len(r.keys) == 0 && len(r.networks) == 0 {
+		return true
+	}
+	if peer.apiKey != "" && r.keys[peer.apiKey] {
+		return true
+	}
+	if peer.ip != nil {
+		for _, network := range r.networks {
+			if network.Contains(peer.ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// listsMethod reports whether the given method is in the list of methods.
+func listsMethod(list []string, method string) bool {
+	namespace := method
+	if i := strings.Index(method, serviceMethodSeparator); i >= 0 {
+		namespace = method[:i]
+	}
+	for _, entry := range list {
+		if entry == "*" || entry == method || entry == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns an error if the given client isn't allowed to call the method.
+func (ac *AccessControl) check(peer peerInfo, method string) error {
+	if ac == nil {
+		return nil
+	}
+	for i := range ac.rules {
+		rule := &ac.rules[i]
+		if !rule.matches(peer) {
+			continue
+		}
+		if listsMethod(rule.deny, method) {
+			return &accessDeniedError{method: method}
+		}
+		if listsMethod(rule.allow, method) {
+			return nil
+		}
+	}
+	return nil
+}
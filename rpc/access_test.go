@@ -0,0 +1,100 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests that the first rule matching a client and listing a method decides
+// whether the call is allowed.
+func TestAccessControl(t *testing.T) {
+	ac, err := NewAccessControl([]AccessRule{
+		{Keys: []string{"secret"}, Allow: []string{"*"}},
+		{Networks: []string{"10.0.0.0/8"}, Allow: []string{"debug"}, Deny: []string{"debug_setHead"}},
+		{Deny: []string{"debug", "admin", "eth_sendTransaction"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		public   = peerInfo{ip: net.ParseIP("1.2.3.4")}
+		internal = peerInfo{ip: net.ParseIP("10.1.2.3")}
+		keyed    = peerInfo{ip: net.ParseIP("1.2.3.4"), apiKey: "secret"}
+	)
+	tests := []struct {
+		peer    peerInfo
+		method  string
+		allowed bool
+	}{
+		{public, "eth_blockNumber", true},
+		{public, "debug_traceTransaction", false},
+		{public, "admin_peers", false},
+		{public, "eth_sendTransaction", false},
+		{internal, "debug_traceTransaction", true},
+		{internal, "debug_setHead", false},
+		{internal, "admin_peers", false},
+		{keyed, "admin_peers", true},
+		{keyed, "debug_setHead", true},
+		{peerInfo{apiKey: "wrong"}, "admin_peers", false},
+	}
+	for i, test := range tests {
+		err := ac.check(test.peer, test.method)
+		if allowed := err == nil; allowed != test.allowed {
+			t.Errorf("test %d: %s allowed %v, want %v", i, test.method, allowed, test.allowed)
+		}
+	}
+	if _, err := NewAccessControl([]AccessRule{{Networks: []string{"10.0.0.0"}}}); err == nil {
+		t.Error("invalid network accepted")
+	}
+	if ac, _ := NewAccessControl(nil); ac.check(public, "admin_peers") != nil {
+		t.Error("call denied without rules")
+	}
+}
+
+// Tests that access rules are enforced on the calls served over HTTP, with the
+// API key sent in the request headers.
+func TestAccessControlHTTP(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	ac, _ := NewAccessControl([]AccessRule{
+		{Keys: []string{"secret"}, Allow: []string{"test"}},
+		{Deny: []string{"test_echo"}},
+	})
+	server.SetAccessControl(ac)
+
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	client, err := DialHTTP(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var result echoResult
+	err = client.Call(&result, "test_echo", "x", 1)
+	if rpcErr, ok := err.(Error); !ok || rpcErr.ErrorCode() != -32006 {
+		t.Fatalf("wrong error for denied call: %v", err)
+	}
+	client.SetHeader(APIKeyHeader, "secret")
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatalf("call with API key denied: %v", err)
+	}
+}
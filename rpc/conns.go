@@ -0,0 +1,161 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// connCounter numbers the connections served by all servers.
+var connCounter uint64
+
+// connStats are the statistics of a connection, kept by its codec.
+type connStats struct {
+	id        uint64
+	transport string // Set by the server before tracking the connection
+	connected time.Time
+
+	bytesIn  uint64 // Accessed atomically
+	bytesOut uint64 // Accessed atomically
+	subs     int64  // Accessed atomically
+
+	lock    sync.Mutex
+	methods map[string]uint64 // Number of calls per method
+}
+
+func newConnStats() *connStats {
+	return &connStats{
+		id:        atomic.AddUint64(&connCounter, 1),
+		connected: time.Now(),
+		methods:   make(map[string]uint64),
+	}
+}
+
+// read counts a message of the given size read from the connection, along with
+// the calls it holds.
+func (s *connStats) read(size int, msgs []*jsonrpcMessage) {
+	atomic.AddUint64(&s.bytesIn, uint64(size))
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, msg := range msgs {
+		if msg.isCall() || msg.isNotification() {
+			s.methods[msg.Method]++
+		}
+	}
+}
+
+// setSubscriptions sets the number of active subscriptions of the connection.
+func (s *connStats) setSubscriptions(n int) {
+	if s != nil {
+		atomic.StoreInt64(&s.subs, int64(n))
+	}
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n *uint64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	atomic.AddUint64(cw.n, uint64(n))
+	return n, err
+}
+
+// codecTransport returns the transport of a connection served by ServeCodec.
+func codecTransport(codec ServerCodec) string {
+	if _, ok := codec.(*websocketCodec); ok {
+		return wsScheme
+	}
+	return ipcScheme
+}
+
+// statsCodec is implemented by the codecs keeping statistics of their connection.
+type statsCodec interface {
+	connStats() *connStats
+}
+
+// ConnInfo describes a connection served by a server.
+type ConnInfo struct {
+	ID            string            `json:"id"`
+	Transport     string            `json:"transport"`
+	RemoteAddr    string            `json:"remoteAddress"`
+	Connected     time.Time         `json:"connected"`
+	Methods       map[string]uint64 `json:"methods"`
+	BytesIn       uint64            `json:"bytesIn"`
+	BytesOut      uint64            `json:"bytesOut"`
+	Subscriptions int               `json:"subscriptions"`
+}
+
+// Conns returns the connections currently served, along with their statistics.
+// HTTP connections are only listed while serving a request.
+func (s *Server) Conns() []*ConnInfo {
+	var conns []*ConnInfo
+	s.codecs.Each(func(c interface{}) bool {
+		codec := c.(ServerCodec)
+		sc, ok := codec.(statsCodec)
+		if !ok {
+			return false
+		}
+		stats := sc.connStats()
+		info := &ConnInfo{
+			ID:            strconv.FormatUint(stats.id, 10),
+			Transport:     stats.transport,
+			RemoteAddr:    codec.remoteAddr(),
+			Connected:     stats.connected,
+			Methods:       make(map[string]uint64),
+			BytesIn:       atomic.LoadUint64(&stats.bytesIn),
+			BytesOut:      atomic.LoadUint64(&stats.bytesOut),
+			Subscriptions: int(atomic.LoadInt64(&stats.subs)),
+		}
+		stats.lock.Lock()
+		for method, calls := range stats.methods {
+			info.Methods[method] = calls
+		}
+		stats.lock.Unlock()
+
+		conns = append(conns, info)
+		return false
+	})
+	return conns
+}
+
+// CloseConn closes the served connection with the given ID, returning false if
+// there is no such connection.
+func (s *Server) CloseConn(id string) bool {
+	var conn ServerCodec
+	s.codecs.Each(func(c interface{}) bool {
+		codec := c.(ServerCodec)
+		if sc, ok := codec.(statsCodec); ok && strconv.FormatUint(sc.connStats().id, 10) == id {
+			conn = codec
+			return true
+		}
+		return false
+	})
+	if conn == nil {
+		return false
+	}
+	conn.close()
+	return true
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Tests that the connections served are listed with their statistics, and can
+// be closed individually.
+func TestServerConns(t *testing.T) {
+	var (
+		srv     = newTestServer()
+		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	)
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	client, err := DialWebsocket(context.Background(), wsURL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var result echoResult
+	for i := 0; i < 3; i++ {
+		if err := client.Call(&result, "test_echo", "x", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conns := srv.Conns()
+	if len(conns) != 1 {
+		t.Fatalf("wrong number of connections: have %d, want 1", len(conns))
+	}
+	conn := conns[0]
+	if conn.Transport != wsScheme {
+		t.Errorf("wrong transport: have %s, want %s", conn.Transport, wsScheme)
+	}
+	if conn.Methods["test_echo"] != 3 {
+		t.Errorf("wrong number of calls: have %d, want 3", conn.Methods["test_echo"])
+	}
+	if conn.BytesIn == 0 || conn.BytesOut == 0 {
+		t.Errorf("traffic not counted: in %d, out %d", conn.BytesIn, conn.BytesOut)
+	}
+	// Closing the connection disconnects the client
+	if srv.CloseConn("unknown") {
+		t.Error("unknown connection closed")
+	}
+	if !srv.CloseConn(conn.ID) {
+		t.Fatal("connection not closed")
+	}
+	if err := client.Call(&result, "test_echo", "x", 1); err == nil {
+		t.Error("call succeeded on closed connection")
+	}
+}
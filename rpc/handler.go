@@ -60,6 +60,13 @@ type handler struct {
 	conn           jsonWriter                     // where responses will be sent
 	log            log.Logger
 	allowSubscribe bool
+	sched          *Scheduler    // bounds the concurrently running calls, nil if unbounded
+	connSlot       chan struct{} // bounds the concurrently running calls of the connection
+	notify         NotifyLimits  // bounds the notifications queued per subscription
+	stats          *connStats    // statistics of the connection, nil if not kept
+
+	access *AccessControl // access rules of the calls, nil if all are allowed
+	peer   peerInfo       // client of the connection for access control
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
@@ -70,7 +77,7 @@ type callProc struct {
 	notifiers []*Notifier
 }
 
-func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry) *handler {
+func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry, conf connConfig) *handler {
 	rootCtx, cancelRoot := context.WithCancel(connCtx)
 	h := &handler{
 		reg:            reg,
@@ -83,10 +90,20 @@ func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *
 		allowSubscribe: true,
 		serverSubs:     make(map[ID]*Subscription),
 		log:            log.Root(),
+		sched:          conf.sched,
+		connSlot:       conf.sched.connSlots(),
+		notify:         conf.notify,
+		access:         conf.access,
 	}
 	if conn.remoteAddr() != "" {
 		h.log = h.log.New("conn", conn.remoteAddr())
 	}
+	if sc, ok := conn.(statsCodec); ok {
+		h.stats = sc.connStats()
+	}
+	if pc, ok := conn.(peerCodec); ok {
+		h.peer = pc.peerInfo()
+	}
 	h.unsubscribeCb = newCallback(reflect.Value{}, reflect.ValueOf(h.unsubscribe))
 	return h
 }
@@ -113,8 +130,24 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	}
 	// Process calls on a goroutine because they may block indefinitely:
 	h.startCallProc(func(cp *callProc) {
+		release, ok := h.sched.acquire(cp.ctx, calls, h.connSlot)
+		if !ok {
+			return
+		}
+		defer release()
+
+		// Calls beyond the batch limits are answered with an error, the rest of
+		// the batch is still executed
+		budget := h.sched.newBatchBudget(calls)
 		answers := make([]*jsonrpcMessage, 0, len(msgs))
 		for _, msg := range calls {
+			if err := budget.spend(msg); err != nil {
+				rpcBatchLimitedMeter.Mark(1)
+				if msg.isCall() {
+					answers = append(answers, msg.errorResponse(err))
+				}
+				continue
+			}
 			if answer := h.handleCallMsg(cp, msg); answer != nil {
 				answers = append(answers, answer)
 			}
@@ -135,6 +168,12 @@ func (h *handler) handleMsg(msg *jsonrpcMessage) {
 		return
 	}
 	h.startCallProc(func(cp *callProc) {
+		release, ok := h.sched.acquire(cp.ctx, []*jsonrpcMessage{msg}, h.connSlot)
+		if !ok {
+			return
+		}
+		defer release()
+
 		answer := h.handleCallMsg(cp, msg)
 		h.addSubscriptions(cp.notifiers)
 		if answer != nil {
@@ -201,6 +240,7 @@ func (h *handler) addSubscriptions(nn []*Notifier) {
 			h.serverSubs[sub.ID] = sub
 		}
 	}
+	h.stats.setSubscriptions(len(h.serverSubs))
 }
 
 // cancelServerSubscriptions removes all subscriptions and closes their error channels.
@@ -213,6 +253,7 @@ func (h *handler) cancelServerSubscriptions(err error) {
 		close(s.err)
 		delete(h.serverSubs, id)
 	}
+	h.stats.setSubscriptions(0)
 }
 
 // startCallProc runs fn in a new goroutine and starts tracking it in the h.calls wait group.
@@ -316,6 +357,9 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if err := h.access.check(h.peer, msg.Method); err != nil {
+		return msg.errorResponse(err)
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -403,6 +447,7 @@ func (h *handler) unsubscribe(ctx context.Context, id ID) (bool, error) {
 	}
 	close(s.err)
 	delete(h.serverSubs, id)
+	h.stats.setSubscriptions(len(h.serverSubs))
 	return true, nil
 }
 
@@ -171,6 +171,8 @@ type jsonCodec struct {
 	encMu   sync.Mutex                // guards the encoder
 	encode  func(v interface{}) error // encoder to allow multiple transports
 	conn    deadlineCloser
+	stats   *connStats
+	peer    peerInfo
 }
 
 // NewFuncCodec creates a codec which uses the given functions to read and write. If conn
@@ -182,6 +184,7 @@ func NewFuncCodec(conn deadlineCloser, encode, decode func(v interface{}) error)
 		encode:  encode,
 		decode:  decode,
 		conn:    conn,
+		stats:   newConnStats(),
 	}
 	if ra, ok := conn.(ConnRemoteAddr); ok {
 		codec.remote = ra.RemoteAddr()
@@ -192,16 +195,25 @@ func NewFuncCodec(conn deadlineCloser, encode, decode func(v interface{}) error)
 // NewCodec creates a codec on the given connection. If conn implements ConnRemoteAddr, log
 // messages will use it to include the remote address of the connection.
 func NewCodec(conn Conn) ServerCodec {
-	enc := json.NewEncoder(conn)
 	dec := json.NewDecoder(conn)
 	dec.UseNumber()
-	return NewFuncCodec(conn, enc.Encode, dec.Decode)
+	codec := NewFuncCodec(conn, nil, dec.Decode).(*jsonCodec)
+	codec.encode = json.NewEncoder(&countingWriter{w: conn, n: &codec.stats.bytesOut}).Encode
+	return codec
 }
 
 func (c *jsonCodec) remoteAddr() string {
 	return c.remote
 }
 
+func (c *jsonCodec) connStats() *connStats {
+	return c.stats
+}
+
+func (c *jsonCodec) peerInfo() peerInfo {
+	return c.peer
+}
+
 func (c *jsonCodec) readBatch() (messages []*jsonrpcMessage, batch bool, err error) {
 	// Decode the next JSON object in the input stream.
 	// This verifies basic syntax, etc.
@@ -217,6 +229,7 @@ func (c *jsonCodec) readBatch() (messages []*jsonrpcMessage, batch bool, err err
 			messages[i] = new(jsonrpcMessage)
 		}
 	}
+	c.stats.read(len(rawmsg), messages)
 	return messages, batch, nil
 }
 
@@ -27,6 +27,18 @@ var (
 	successfulRequestGauge = metrics.NewRegisteredGauge("rpc/success", nil)
 	failedReqeustGauge     = metrics.NewRegisteredGauge("rpc/failure", nil)
 	rpcServingTimer        = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	rpcQueuedCounter         = metrics.NewRegisteredCounter("rpc/queue/regular/pending", nil)
+	rpcQueueTimer            = metrics.NewRegisteredTimer("rpc/queue/regular/delay", nil)
+	rpcPriorityQueuedCounter = metrics.NewRegisteredCounter("rpc/queue/priority/pending", nil)
+	rpcPriorityQueueTimer    = metrics.NewRegisteredTimer("rpc/queue/priority/delay", nil)
+	rpcConnQueuedCounter     = metrics.NewRegisteredCounter("rpc/queue/conn/pending", nil)
+	rpcConnQueueTimer        = metrics.NewRegisteredTimer("rpc/queue/conn/delay", nil)
+	rpcBatchLimitedMeter     = metrics.NewRegisteredMeter("rpc/batch/limited", nil)
+
+	rpcNotifyQueuedCounter    = metrics.NewRegisteredCounter("rpc/notify/queued", nil)
+	rpcNotifyDroppedMeter     = metrics.NewRegisteredMeter("rpc/notify/dropped", nil)
+	rpcNotifyDisconnectsMeter = metrics.NewRegisteredMeter("rpc/notify/disconnects", nil)
 )
 
 func newRPCServingTimer(method string, valid bool) metrics.Timer {
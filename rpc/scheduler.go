@@ -0,0 +1,199 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/metrics"
+)
+
+// priorityWorkers is the number of calls of priority namespaces allowed to run
+// concurrently. Engine calls are mostly sequential, so it's only a safety net.
+const priorityWorkers = 8
+
+// callCosts are the costs of expensive methods counted against the cost limit
+// of batches. All other calls cost 1.
+var callCosts = map[string]int{
+	"eth_call":                           10,
+	"eth_estimateGas":                    10,
+	"eth_createAccessList":               10,
+	"eth_getLogs":                        10,
+	"eth_getProof":                       10,
+	"debug_traceCall":                    50,
+	"debug_traceTransaction":             50,
+	"debug_traceBlockByNumber":           100,
+	"debug_traceBlockByHash":             100,
+	"scroll_getBlockTraceByNumberOrHash": 100,
+}
+
+// callCost returns the cost of a call counted against the cost limit of batches.
+func callCost(method string) int {
+	if cost, ok := callCosts[method]; ok {
+		return cost
+	}
+	return 1
+}
+
+// BatchLimits bounds the work a single client can cause, so that it can't
+// monopolize the workers of a scheduler. Zero values mean unlimited.
+type BatchLimits struct {
+	Items     int // Maximum number of calls executed from a batch
+	Cost      int // Maximum cumulative cost of the calls executed from a batch
+	ConnCalls int // Maximum number of calls executing concurrently per connection
+}
+
+// Scheduler bounds the number of calls executing concurrently across all the
+// servers it's attached to, running the calls of priority namespaces (e.g. the
+// engine API driving the node) on a dedicated pool of workers. This way they're
+// never queued behind heavy public traffic like eth_getLogs or debug traces.
+// It also enforces the batch limits on the other calls: calls of a batch beyond
+// them are answered with an error instead of being executed, and the calls of a
+// connection beyond its share are queued.
+//
+// A nil scheduler runs every call right away.
+type Scheduler struct {
+	priority map[string]bool // Namespaces whose calls run on the priority pool
+	prioSlot chan struct{}   // Slots of the priority pool
+	slot     chan struct{}   // Slots of the pool of all other calls, nil if unbounded
+	limits   BatchLimits     // Limits of the work of a single client
+}
+
+// NewScheduler creates a scheduler running at most workers calls concurrently,
+// plus a separate pool for the calls of the given priority namespaces, and
+// enforcing the given batch limits. It returns nil if neither workers nor any
+// limit is set, as calls are not bounded then.
+func NewScheduler(workers int, priority []string, limits BatchLimits) *Scheduler {
+	if workers <= 0 && limits == (BatchLimits{}) {
+		return nil
+	}
+	s := &Scheduler{
+		priority: make(map[string]bool),
+		prioSlot: make(chan struct{}, priorityWorkers),
+		limits:   limits,
+	}
+	if workers > 0 {
+		s.slot = make(chan struct{}, workers)
+	}
+	for _, namespace := range priority {
+		s.priority[namespace] = true
+	}
+	return s
+}
+
+// connSlots creates the slots of the calls of a single connection, nil if the
+// number of calls per connection is unbounded.
+func (s *Scheduler) connSlots() chan struct{} {
+	if s == nil || s.limits.ConnCalls <= 0 {
+		return nil
+	}
+	return make(chan struct{}, s.limits.ConnCalls)
+}
+
+// batchBudget tracks the calls of a batch executed so far against the limits.
+type batchBudget struct {
+	limits BatchLimits
+	items  int
+	cost   int
+}
+
+// newBatchBudget creates the budget of a batch of the given calls. Batches of
+// priority calls are not limited.
+func (s *Scheduler) newBatchBudget(msgs []*jsonrpcMessage) *batchBudget {
+	if s == nil || s.isPriority(msgs) {
+		return &batchBudget{}
+	}
+	return &batchBudget{limits: s.limits}
+}
+
+// spend charges the call to the budget, returning an error if it exceeds the
+// limits of the batch and must not be executed.
+func (b *batchBudget) spend(msg *jsonrpcMessage) error {
+	if b.limits.Items > 0 && b.items >= b.limits.Items {
+		return &limitExceededError{fmt.Sprintf("batch limit of %d calls exceeded", b.limits.Items)}
+	}
+	cost := callCost(msg.Method)
+	if b.limits.Cost > 0 && b.cost+cost > b.limits.Cost {
+		return &limitExceededError{fmt.Sprintf("batch cost limit of %d exceeded", b.limits.Cost)}
+	}
+	b.items++
+	b.cost += cost
+	return nil
+}
+
+// isPriority reports whether all the given calls belong to priority namespaces.
+func (s *Scheduler) isPriority(msgs []*jsonrpcMessage) bool {
+	for _, msg := range msgs {
+		if !s.priority[msg.namespace()] {
+			return false
+		}
+	}
+	return len(msgs) > 0
+}
+
+// acquire waits for a worker of the right pool to become available for running
+// the given calls, which are handled together. It returns a function to release
+// the worker, or false if the context was canceled while waiting.
+func (s *Scheduler) acquire(ctx context.Context, msgs []*jsonrpcMessage, conn chan struct{}) (func(), bool) {
+	if s == nil {
+		return func() {}, true
+	}
+	slot, queue, wait := s.slot, rpcQueuedCounter, rpcQueueTimer
+	if s.isPriority(msgs) {
+		slot, queue, wait = s.prioSlot, rpcPriorityQueuedCounter, rpcPriorityQueueTimer
+	} else if conn != nil {
+		// Wait for a slot of the connection first, so that its calls beyond its
+		// share don't hold workers other connections could use
+		release, ok := acquireSlot(ctx, conn, rpcConnQueuedCounter, rpcConnQueueTimer)
+		if !ok {
+			return nil, false
+		}
+		releaseWorker, ok := acquireSlot(ctx, slot, queue, wait)
+		if !ok {
+			release()
+			return nil, false
+		}
+		return func() { releaseWorker(); release() }, true
+	}
+	return acquireSlot(ctx, slot, queue, wait)
+}
+
+// acquireSlot waits for a slot to become available, recording the wait in the
+// given metrics. It returns a function to release the slot, or false if the
+// context was canceled while waiting. A nil slot channel is unbounded.
+func acquireSlot(ctx context.Context, slot chan struct{}, queue metrics.Counter, wait metrics.Timer) (func(), bool) {
+	if slot == nil {
+		return func() {}, true
+	}
+	start := time.Now()
+	select {
+	case slot <- struct{}{}:
+	default:
+		queue.Inc(1)
+		defer queue.Dec(1)
+
+		select {
+		case slot <- struct{}{}:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+	wait.UpdateSince(start)
+	return func() { <-slot }, true
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Tests that calls beyond the worker limit are queued, while the calls of the
+// priority namespaces run on their own pool.
+func TestSchedulerPriority(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.RegisterName("prio", new(testService)); err != nil {
+		t.Fatal(err)
+	}
+	server.SetScheduler(NewScheduler(1, []string{"prio"}, BatchLimits{}))
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	// Occupy the only regular worker
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Call(nil, "test_sleep", 500*time.Millisecond)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	var result echoResult
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := client.CallContext(ctx, &result, "test_echo", "x", 1); err == nil {
+		t.Fatal("regular call not queued behind the running one")
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := client.CallContext(ctx, &result, "prio_echo", "x", 1); err != nil {
+		t.Fatalf("priority call queued: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("sleep call failed: %v", err)
+	}
+	// With the worker released, regular calls are served again
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatalf("regular call failed: %v", err)
+	}
+	// Batches only run on the priority pool if all their calls are prioritized
+	if !NewScheduler(1, []string{"prio"}, BatchLimits{}).isPriority([]*jsonrpcMessage{{Method: "prio_echo"}}) {
+		t.Error("priority call not prioritized")
+	}
+	if NewScheduler(1, []string{"prio"}, BatchLimits{}).isPriority([]*jsonrpcMessage{{Method: "prio_echo"}, {Method: "test_echo"}}) {
+		t.Error("mixed batch prioritized")
+	}
+}
+
+// Tests that the calls of a batch beyond its limits are answered with an error,
+// while the rest of the batch is executed.
+func TestSchedulerBatchLimits(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetScheduler(NewScheduler(0, nil, BatchLimits{Items: 3, Cost: 10}))
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	// The item limit cuts the batch after the first three calls
+	batch := make([]BatchElem, 5)
+	for i := range batch {
+		batch[i] = BatchElem{Method: "test_echo", Args: []interface{}{"x", i}, Result: new(echoResult)}
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	for i, elem := range batch {
+		if i < 3 && elem.Error != nil {
+			t.Errorf("call %d failed: %v", i, elem.Error)
+		}
+		if i >= 3 {
+			if err, ok := elem.Error.(Error); !ok || err.ErrorCode() != -32005 {
+				t.Errorf("call %d: wrong error %v", i, elem.Error)
+			}
+		}
+	}
+	// The cost limit rejects the expensive call exceeding it, but not the
+	// cheaper calls after it
+	batch = []BatchElem{
+		{Method: "test_echo", Args: []interface{}{"x", 1}, Result: new(echoResult)},
+		{Method: "eth_call", Args: []interface{}{}, Result: new(interface{})},
+		{Method: "test_echo", Args: []interface{}{"x", 2}, Result: new(echoResult)},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch[0].Error != nil || batch[2].Error != nil {
+		t.Errorf("cheap calls failed: %v, %v", batch[0].Error, batch[2].Error)
+	}
+	if err, ok := batch[1].Error.(Error); !ok || err.ErrorCode() != -32005 {
+		t.Errorf("expensive call: wrong error %v", batch[1].Error)
+	}
+}
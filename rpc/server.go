@@ -51,6 +51,16 @@ type Server struct {
 	codecs   mapset.Set
 	// Add compressionLevel inorder to enable set it when open websocket server.
 	compressionLevel int
+	sched            *Scheduler
+	notify           NotifyLimits
+	access           *AccessControl
+}
+
+// connConfig holds the settings of a server applied to each connection.
+type connConfig struct {
+	sched  *Scheduler     // bounds the concurrently running calls, nil if unbounded
+	notify NotifyLimits   // bounds the notifications queued per subscription
+	access *AccessControl // access rules of the calls, nil if all are allowed
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -85,10 +95,13 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	}
 
 	// Add the codec to the set so it can be closed by Stop.
+	if sc, ok := codec.(statsCodec); ok {
+		sc.connStats().transport = codecTransport(codec)
+	}
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
-	c := initClient(codec, s.idgen, &s.services)
+	c := initClient(codec, s.idgen, &s.services, s.connConfig())
 	<-codec.closed()
 	c.Close()
 }
@@ -102,6 +115,29 @@ func (s *Server) SetCompressionLevel(level int) error {
 	return nil
 }
 
+// SetScheduler sets the scheduler bounding the concurrently running calls. It
+// must be called before the server starts serving.
+func (s *Server) SetScheduler(sched *Scheduler) {
+	s.sched = sched
+}
+
+// SetNotifyLimits sets the limits of the notifications queued per subscription.
+// It must be called before the server starts serving.
+func (s *Server) SetNotifyLimits(limits NotifyLimits) {
+	s.notify = limits
+}
+
+// SetAccessControl sets the access rules of the calls served to clients over
+// HTTP and WebSocket. It must be called before the server starts serving.
+func (s *Server) SetAccessControl(access *AccessControl) {
+	s.access = access
+}
+
+// connConfig returns the settings applied to the connections served.
+func (s *Server) connConfig() connConfig {
+	return connConfig{sched: s.sched, notify: s.notify, access: s.access}
+}
+
 // serveSingleRequest reads and processes a single RPC request from the given codec. This
 // is used to serve HTTP connections. Subscriptions and reverse calls are not allowed in
 // this mode.
@@ -111,7 +147,24 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 		return
 	}
 
-	h := newHandler(ctx, codec, s.idgen, &s.services)
+	// Track the request as a connection, which can be closed to cancel it
+	if sc, ok := codec.(statsCodec); ok {
+		sc.connStats().transport = httpScheme
+	}
+	s.codecs.Add(codec)
+	defer s.codecs.Remove(codec)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-codec.closed():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	h := newHandler(ctx, codec, s.idgen, &s.services, s.connConfig())
 	h.allowSubscribe = false
 	defer h.close(io.EOF, nil)
 
@@ -36,8 +36,19 @@ var (
 	ErrNotificationsUnsupported = errors.New("notifications not supported")
 	// ErrNotificationNotFound is returned when the notification for the given id is not found
 	ErrSubscriptionNotFound = errors.New("subscription not found")
+	// ErrSubscriptionOverflow is returned when a subscriber is disconnected for
+	// not keeping up with its notifications
+	ErrSubscriptionOverflow = errors.New("subscription notification queue overflow")
 )
 
+// NotifyLimits bounds the memory held by the notifications of a subscription
+// whose subscriber doesn't keep up with them. Without a buffer, notifications
+// are written right away, blocking the notifying service on slow subscribers.
+type NotifyLimits struct {
+	Buffer int  // Maximum number of notifications queued per subscription, 0 to write them right away
+	Drop   bool // Whether to drop the oldest queued notification on overflow, instead of disconnecting
+}
+
 var globalGen = randomIDGenerator()
 
 // ID defines a pseudo random number that is used to identify RPC subscriptions.
@@ -99,6 +110,10 @@ type Notifier struct {
 	buffer       []json.RawMessage
 	callReturned bool
 	activated    bool
+
+	queue      []json.RawMessage // Notifications waiting to be written, if buffered
+	sending    bool              // Whether the queue is being written
+	overflowed bool              // Whether the subscriber was disconnected for overflowing
 }
 
 // CreateSubscription returns a new subscription that is coupled to the
@@ -135,6 +150,9 @@ func (n *Notifier) Notify(id ID, data interface{}) error {
 		panic("Notify with wrong ID")
 	}
 	if n.activated {
+		if n.h.notify.Buffer > 0 {
+			return n.enqueue(enc)
+		}
 		return n.send(n.sub, enc)
 	}
 	n.buffer = append(n.buffer, enc)
@@ -163,15 +181,87 @@ func (n *Notifier) activate() error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
+	n.activated = true
+	if n.h.notify.Buffer > 0 {
+		for _, data := range n.buffer {
+			if err := n.enqueue(data); err != nil {
+				return err
+			}
+		}
+		n.buffer = nil
+		return nil
+	}
 	for _, data := range n.buffer {
 		if err := n.send(n.sub, data); err != nil {
 			return err
 		}
 	}
-	n.activated = true
 	return nil
 }
 
+// enqueue queues a notification to be written to the subscriber in the
+// background, so that a slow subscriber doesn't block the notifying service.
+// If the queue is full, the oldest notification is dropped or the subscriber
+// is disconnected, depending on the policy. It must be called with n.mu held.
+func (n *Notifier) enqueue(data json.RawMessage) error {
+	if n.overflowed {
+		return ErrSubscriptionOverflow
+	}
+	if len(n.queue) >= n.h.notify.Buffer {
+		if !n.h.notify.Drop {
+			n.h.log.Warn("Disconnecting slow subscriber", "id", n.sub.ID, "queued", len(n.queue))
+			rpcNotifyDisconnectsMeter.Mark(1)
+
+			n.overflowed = true
+			rpcNotifyQueuedCounter.Dec(int64(len(n.queue)))
+			n.queue = nil
+			if codec, ok := n.h.conn.(ServerCodec); ok {
+				codec.close()
+			}
+			return ErrSubscriptionOverflow
+		}
+		rpcNotifyDroppedMeter.Mark(1)
+		rpcNotifyQueuedCounter.Dec(1)
+		n.queue[0] = nil
+		n.queue = n.queue[1:]
+	}
+	n.queue = append(n.queue, data)
+	rpcNotifyQueuedCounter.Inc(1)
+
+	if !n.sending {
+		n.sending = true
+		go n.sendQueue()
+	}
+	return nil
+}
+
+// sendQueue writes the queued notifications to the subscriber until the queue
+// is empty, or the connection fails.
+func (n *Notifier) sendQueue() {
+	for {
+		n.mu.Lock()
+		if len(n.queue) == 0 || n.overflowed {
+			n.sending = false
+			n.mu.Unlock()
+			return
+		}
+		data := n.queue[0]
+		n.queue[0] = nil
+		n.queue = n.queue[1:]
+		rpcNotifyQueuedCounter.Dec(1)
+		n.mu.Unlock()
+
+		if err := n.send(n.sub, data); err != nil {
+			n.mu.Lock()
+			rpcNotifyQueuedCounter.Dec(int64(len(n.queue)))
+			n.queue = nil
+			n.sending = false
+			n.mu.Unlock()
+			return
+		}
+	}
+}
+
 func (n *Notifier) send(sub *Subscription, data json.RawMessage) error {
 	params, _ := json.Marshal(&subscriptionResult{ID: string(sub.ID), Result: data})
 	ctx := context.Background()
@@ -218,3 +218,63 @@ func readAndValidateMessage(in *json.Decoder) (*subConfirmation, *subscriptionRe
 		return nil, nil, fmt.Errorf("unrecognized message: %v", msg)
 	}
 }
+
+// This test checks that notifications for a subscriber which doesn't keep up are
+// queued up to the limit, after which they're dropped or the subscriber is
+// disconnected.
+func TestSlowSubscriber(t *testing.T) {
+	for _, drop := range []bool{true, false} {
+		p1, p2 := net.Pipe()
+
+		server := newTestServer()
+		service := &notificationTestService{unsubscribed: make(chan string, 1)}
+		server.RegisterName("nftest", service)
+		server.SetNotifyLimits(NotifyLimits{Buffer: 4, Drop: drop})
+		go server.ServeCodec(NewCodec(p1), 0)
+
+		// Subscribe and receive the subscription ID, but don't read the notifications
+		p2.SetDeadline(time.Now().Add(10 * time.Second))
+		p2.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"nftest_subscribe","params":["someSubscription",100,0]}`))
+
+		in := json.NewDecoder(p2)
+		if resp, _, err := readAndValidateMessage(in); err != nil || resp == nil {
+			t.Fatalf("drop %v: no subscription ID: %v", drop, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+
+		if drop {
+			// The notifications beyond the queue are dropped, the rest are delivered
+			var values []int
+			for len(values) == 0 || values[len(values)-1] != 99 {
+				_, notification, err := readAndValidateMessage(in)
+				if err != nil {
+					t.Fatalf("drop %v: %v", drop, err)
+				}
+				var value int
+				json.Unmarshal(notification.Result, &value)
+				values = append(values, value)
+			}
+			if len(values) > 6 {
+				t.Errorf("drop %v: too many notifications delivered: %v", drop, values)
+			}
+		} else {
+			// The subscriber is disconnected after the queued notifications
+			p2.SetDeadline(time.Now().Add(time.Second))
+			for i := 0; ; i++ {
+				_, _, err := readAndValidateMessage(in)
+				if err == nil {
+					if i > 5 {
+						t.Fatalf("drop %v: too many notifications delivered", drop)
+					}
+					continue
+				}
+				if strings.Contains(err.Error(), "timeout") {
+					t.Fatalf("drop %v: slow subscriber not disconnected", drop)
+				}
+				break
+			}
+		}
+		p2.Close()
+		server.Stop()
+	}
+}
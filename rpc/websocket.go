@@ -20,6 +20,7 @@ import (
 	"compress/flate"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -68,6 +69,7 @@ func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			_ = conn.SetCompressionLevel(s.compressionLevel)
 		}
 		codec := newWebsocketCodec(conn)
+		codec.(*websocketCodec).peer = newPeerInfo(r)
 		s.ServeCodec(codec, 0)
 	})
 }
@@ -254,10 +256,23 @@ func newWebsocketCodec(conn *websocket.Conn) ServerCodec {
 		return nil
 	})
 	wc := &websocketCodec{
-		jsonCodec: NewFuncCodec(conn, conn.WriteJSON, conn.ReadJSON).(*jsonCodec),
+		jsonCodec: NewFuncCodec(conn, nil, conn.ReadJSON).(*jsonCodec),
 		conn:      conn,
 		pingReset: make(chan struct{}, 1),
 	}
+	wc.encode = func(v interface{}) error {
+		// Same as conn.WriteJSON, counting the written bytes
+		w, err := conn.NextWriter(websocket.TextMessage)
+		if err != nil {
+			return err
+		}
+		err1 := json.NewEncoder(&countingWriter{w: w, n: &wc.stats.bytesOut}).Encode(v)
+		err2 := w.Close()
+		if err1 != nil {
+			return err1
+		}
+		return err2
+	}
 	wc.wg.Add(1)
 	go wc.pingLoop()
 	return wc
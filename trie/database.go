@@ -282,6 +282,9 @@ type Config struct {
 	Journal   string // Journal of clean cache to survive node restarts
 	Preimages bool   // Flag whether the preimage of trie key is recorded
 	Zktrie    bool   // use zktrie
+
+	PreimageKind  string // Kind of trie keys to record the preimages of (PreimagesAll if empty)
+	PreimageLimit uint64 // Maximum number of preimages to store on disk (unlimited if zero)
 }
 
 // NewDatabase creates a new trie database to store ephemeral trie content before
@@ -305,7 +308,7 @@ func NewDatabaseWithConfig(diskdb ethdb.KeyValueStore, config *Config) *Database
 	}
 	var preimage *preimageStore
 	if config != nil && config.Preimages {
-		preimage = newPreimageStore(diskdb)
+		preimage = newPreimageStore(diskdb, config.PreimageKind, config.PreimageLimit)
 	}
 	db := &Database{
 		diskdb: diskdb,
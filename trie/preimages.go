@@ -22,6 +22,14 @@ import (
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/core/rawdb"
 	"github.com/scroll-tech/go-ethereum/ethdb"
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// Kinds of trie keys whose preimages are recorded.
+const (
+	PreimagesAll      = "all"      // Preimages of account and storage trie keys
+	PreimagesAccounts = "accounts" // Preimages of account trie keys (addresses) only
+	PreimagesStorage  = "storage"  // Preimages of storage trie keys (slots) only
 )
 
 // preimageStore is the store for caching preimages of node key.
@@ -30,16 +38,36 @@ type preimageStore struct {
 	disk          ethdb.KeyValueStore
 	preimages     map[common.Hash][]byte // Preimages of nodes from the secure trie
 	preimagesSize common.StorageSize     // Storage size of the preimages cache
+
+	kind  string // Kind of trie keys to record the preimages of, all if empty
+	limit uint64 // Maximum number of preimages to write to disk, unlimited if zero
+	count uint64 // Number of preimages written to disk so far
+	full  bool   // Whether the limit was reached and recording stopped
 }
 
 // newPreimageStore initializes the store for caching preimages.
-func newPreimageStore(disk ethdb.KeyValueStore) *preimageStore {
+func newPreimageStore(disk ethdb.KeyValueStore, kind string, limit uint64) *preimageStore {
 	return &preimageStore{
 		disk:      disk,
 		preimages: make(map[common.Hash][]byte),
+		kind:      kind,
+		limit:     limit,
+		count:     rawdb.ReadPreimageCount(disk),
 	}
 }
 
+// wanted returns whether the preimage is of the kind of trie keys recorded.
+// Account trie keys are addresses, storage trie keys are slot hashes.
+func (store *preimageStore) wanted(preimage []byte) bool {
+	switch store.kind {
+	case PreimagesAccounts:
+		return len(preimage) == common.AddressLength
+	case PreimagesStorage:
+		return len(preimage) == common.HashLength
+	}
+	return true
+}
+
 // insertPreimage writes a new trie node pre-image to the memory database if it's
 // yet unknown. The method will NOT make a copy of the slice, only use if the
 // preimage will NOT be changed later on.
@@ -48,9 +76,16 @@ func (store *preimageStore) insertPreimage(preimages map[common.Hash][]byte) {
 	defer store.lock.Unlock()
 
 	for hash, preimage := range preimages {
-		if _, ok := store.preimages[hash]; ok {
+		if _, ok := store.preimages[hash]; ok || !store.wanted(preimage) {
 			continue
 		}
+		if store.limit > 0 && store.count+uint64(len(store.preimages)) >= store.limit {
+			if !store.full {
+				log.Warn("Preimage limit reached, recording stopped", "limit", store.limit)
+				store.full = true
+			}
+			return
+		}
 		store.preimages[hash] = preimage
 		store.preimagesSize += common.StorageSize(common.HashLength + len(preimage))
 	}
@@ -77,11 +112,15 @@ func (store *preimageStore) commit(force bool) error {
 	if store.preimagesSize <= 4*1024*1024 && !force {
 		return nil
 	}
+	// Preimages already on disk are counted again, so the count is an upper
+	// bound and recording may stop slightly before the limit is reached.
 	batch := store.disk.NewBatch()
 	rawdb.WritePreimages(batch, store.preimages)
+	rawdb.WritePreimageCount(batch, store.count+uint64(len(store.preimages)))
 	if err := batch.Write(); err != nil {
 		return err
 	}
+	store.count += uint64(len(store.preimages))
 	store.preimages, store.preimagesSize = make(map[common.Hash][]byte), 0
 	return nil
 }
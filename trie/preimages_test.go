@@ -0,0 +1,70 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/rawdb"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+// Tests that the preimage store only records the preimages of the configured
+// kind of trie keys, up to the configured limit across restarts.
+func TestPreimageStoreKindAndLimit(t *testing.T) {
+	var (
+		addr1, addr2, addr3 = common.Address{0x01}, common.Address{0x02}, common.Address{0x03}
+		slot                = common.Hash{0x04}
+	)
+	insert := func(store *preimageStore, keys ...[]byte) {
+		preimages := make(map[common.Hash][]byte)
+		for _, key := range keys {
+			preimages[crypto.Keccak256Hash(key)] = key
+		}
+		store.insertPreimage(preimages)
+		if err := store.commit(true); err != nil {
+			t.Fatalf("failed to commit preimages: %v", err)
+		}
+	}
+	stored := func(store *preimageStore, key []byte) bool {
+		return rawdb.ReadPreimage(store.disk, crypto.Keccak256Hash(key)) != nil
+	}
+	db := rawdb.NewMemoryDatabase()
+
+	store := newPreimageStore(db, PreimagesAccounts, 2)
+	insert(store, addr1.Bytes(), slot.Bytes())
+	if !stored(store, addr1.Bytes()) {
+		t.Errorf("account preimage not stored")
+	}
+	if stored(store, slot.Bytes()) {
+		t.Errorf("storage preimage stored")
+	}
+	// Reopen the store, the limit covers the preimages stored before
+	store = newPreimageStore(db, PreimagesAccounts, 2)
+	insert(store, addr2.Bytes())
+	insert(store, addr3.Bytes())
+	if !stored(store, addr2.Bytes()) {
+		t.Errorf("account preimage below limit not stored")
+	}
+	if stored(store, addr3.Bytes()) {
+		t.Errorf("account preimage above limit stored")
+	}
+	if count := rawdb.ReadPreimageCount(db); count != 2 {
+		t.Errorf("preimage count mismatch: have %d, want 2", count)
+	}
+}